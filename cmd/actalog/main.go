@@ -5,7 +5,9 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
 	"path/filepath"
@@ -18,10 +20,13 @@ import (
 	"github.com/johnzastrow/actalog/internal/handler"
 	"github.com/johnzastrow/actalog/internal/repository"
 	"github.com/johnzastrow/actalog/internal/service"
+	"github.com/johnzastrow/actalog/pkg/auth"
 	"github.com/johnzastrow/actalog/pkg/email"
 	"github.com/johnzastrow/actalog/pkg/logger"
 	"github.com/johnzastrow/actalog/pkg/middleware"
+	"github.com/johnzastrow/actalog/pkg/oidc"
 	"github.com/johnzastrow/actalog/pkg/version"
+	"github.com/johnzastrow/actalog/pkg/webauthn"
 	"github.com/joho/godotenv"
 
 	// Database drivers
@@ -103,22 +108,55 @@ func main() {
 	userSettingsRepo := repository.NewSQLiteUserSettingsRepository(db)
 	userWorkoutMovementRepo := repository.NewUserWorkoutMovementRepository(db)
 	userWorkoutWODRepo := repository.NewUserWorkoutWODRepository(db)
+	webhookRepo := repository.NewWebhookRepository(db)
+	emailOutboxRepo := repository.NewEmailOutboxRepository(db)
+	digestRepo := repository.NewDigestRepository(db)
+	notificationRepo := repository.NewNotificationRepository(db)
+	mfaRepo := repository.NewMFARepository(db)
+	passkeyRepo := repository.NewPasskeyRepository(db)
+	oidcRepo := repository.NewOIDCRepository(db)
+	apiTokenRepo := repository.NewAPITokenRepository(db)
+	loginLockoutRepo := repository.NewLoginLockoutRepository(db)
+	signingKeyRepo := repository.NewSigningKeyRepository(db)
+	auditLogRepo := repository.NewAuditLogRepository(db)
+	searchRepo := repository.NewSearchRepository(db)
+	transactor := repository.NewTransactor(db)
+
+	// Load email templates (built-in, optionally overridden from disk)
+	emailRenderer, err := email.NewRenderer(cfg.Email.TemplateDir)
+	if err != nil {
+		appLogger.Fatal("Failed to load email templates: %v", err)
+	}
+	if cfg.Email.TemplateDir != "" {
+		appLogger.Info("Email templates: overrides loaded from %s", cfg.Email.TemplateDir)
+	}
 
-	// Initialize email service
-	var emailService *email.Service
+	// Initialize email service. Emails are queued in the outbox and delivered
+	// by a background worker so requests never block on SMTP.
+	var emailService email.EmailService
+	var emailOutboxService *service.EmailOutboxService
 	if cfg.Email.Enabled && cfg.Email.SMTPHost != "" {
 		// Create a standard logger that writes to our custom logger
 		stdLogger := log.New(appLogger.Writer(), "", 0)
 
-		emailService = email.NewService(email.Config{
+		smtpService := email.NewService(email.Config{
 			SMTPHost:     cfg.Email.SMTPHost,
 			SMTPPort:     cfg.Email.SMTPPort,
 			SMTPUser:     cfg.Email.SMTPUser,
 			SMTPPassword: cfg.Email.SMTPPassword,
 			FromAddress:  cfg.Email.FromAddress,
 			FromName:     cfg.Email.FromName,
-		}, stdLogger)
-		appLogger.Info("Email service: enabled (SMTP: %s:%d)", cfg.Email.SMTPHost, cfg.Email.SMTPPort)
+		}, emailRenderer, stdLogger)
+		emailOutboxService = service.NewEmailOutboxService(
+			emailOutboxRepo,
+			smtpService,
+			emailRenderer,
+			cfg.Email.OutboxMaxAttempts,
+			cfg.Email.OutboxBaseBackoff,
+			cfg.Email.OutboxPollInterval,
+		)
+		emailService = emailOutboxService
+		appLogger.Info("Email service: enabled (SMTP: %s:%d, outbox poll interval: %s)", cfg.Email.SMTPHost, cfg.Email.SMTPPort, cfg.Email.OutboxPollInterval)
 	} else {
 		appLogger.Info("Email service: disabled (password reset emails will not be sent)")
 	}
@@ -146,6 +184,99 @@ func main() {
 		cfg.Email.RequireVerification,
 	)
 
+	// Token signing keys. HS256 signs with JWT_SECRET; RS256 and EdDSA use
+	// rotating key pairs published at /.well-known/jwks.json, stored
+	// encrypted with JWT_KEY_ENCRYPTION_KEY. JWT_SECRET still verifies HS256
+	// tokens issued before switching until JWT_SECRET_VERIFY_UNTIL.
+	tokenKeys := auth.NewHMACKeySet(cfg.JWT.SecretKey)
+	tokenKeys.SetIssuer(cfg.JWT.Issuer)
+	var signingKeyService *service.SigningKeyService
+	if cfg.JWT.Algorithm != auth.AlgorithmHS256 {
+		signingKeyService = service.NewSigningKeyService(signingKeyRepo, tokenKeys, cfg.JWT.Algorithm, cfg.JWT.KeyRotationInterval, cfg.JWT.ExpirationTime)
+		if cfg.JWT.KeyEncryptionKey != "" {
+			keyCipher, err := auth.NewKeyCipher(cfg.JWT.KeyEncryptionKey)
+			if err != nil {
+				appLogger.Fatal("Invalid JWT_KEY_ENCRYPTION_KEY: %v", err)
+			}
+			signingKeyService.SetKeyCipher(keyCipher)
+		} else {
+			appLogger.Warn("JWT_KEY_ENCRYPTION_KEY is not set; token signing keys are stored unencrypted")
+		}
+		secretUntil := cfg.JWT.SecretVerifyUntil
+		if secretUntil.IsZero() {
+			secretUntil = time.Now()
+		}
+		tokenKeys.SetSecretExpiry(secretUntil)
+		if err := signingKeyService.Refresh(context.Background()); err != nil {
+			appLogger.Fatal("Failed to load token signing keys: %v", err)
+		}
+		appLogger.Info("Token signing: %s key pairs (rotated every %s)", cfg.JWT.Algorithm, cfg.JWT.KeyRotationInterval)
+	}
+	userService.SetKeySet(tokenKeys)
+
+	// TOTP two-factor authentication (optional per user)
+	mfaService := service.NewMFAService(mfaRepo, userRepo, cfg.App.Name)
+	userService.SetMFAService(mfaService)
+
+	// Progressive lockout after repeated failed password logins
+	lockoutService := service.NewLockoutService(loginLockoutRepo, userRepo, emailService, appURL, service.LockoutPolicy{
+		Threshold:   cfg.Security.LockoutThreshold,
+		Duration:    cfg.Security.LockoutDuration,
+		MaxDuration: cfg.Security.LockoutMaxDuration,
+	})
+	if cfg.Security.LockoutThreshold > 0 {
+		userService.SetLockoutService(lockoutService)
+		appLogger.Info("Account lockout: enabled (after %d failed logins, %s doubling up to %s)", cfg.Security.LockoutThreshold, cfg.Security.LockoutDuration, cfg.Security.LockoutMaxDuration)
+	} else {
+		appLogger.Info("Account lockout: disabled")
+	}
+
+	// Audit log of security and admin actions
+	auditService := service.NewAuditService(auditLogRepo)
+
+	// Admin management of user accounts
+	adminUserService := service.NewAdminUserService(userRepo, userService)
+
+	// Personal API tokens for scripts and integrations
+	apiTokenService := service.NewAPITokenService(apiTokenRepo, userRepo)
+
+	// Passkey (WebAuthn) login; the relying party defaults to APP_URL's host
+	relyingParty := webauthn.RelyingParty{
+		ID:      cfg.App.WebAuthnRPID,
+		Name:    cfg.App.Name,
+		Origins: cfg.App.WebAuthnOrigins,
+	}
+	if parsedAppURL, err := url.Parse(appURL); err == nil {
+		if relyingParty.ID == "" {
+			relyingParty.ID = parsedAppURL.Hostname()
+		}
+		if len(relyingParty.Origins) == 0 {
+			relyingParty.Origins = []string{parsedAppURL.Scheme + "://" + parsedAppURL.Host}
+		}
+	}
+	passkeyService := service.NewPasskeyService(passkeyRepo, userRepo, relyingParty)
+	userService.SetPasskeyService(passkeyService)
+	appLogger.Info("Passkeys: relying party %s, origins %v", relyingParty.ID, relyingParty.Origins)
+
+	// OpenID Connect single sign-on (optional)
+	if cfg.OIDC.Enabled() {
+		redirectURL := cfg.OIDC.RedirectURL
+		if redirectURL == "" {
+			redirectURL = strings.TrimSuffix(appURL, "/") + "/auth/oidc/callback"
+		}
+		oidcProvider := oidc.NewProvider(oidc.Config{
+			IssuerURL:    cfg.OIDC.IssuerURL,
+			ClientID:     cfg.OIDC.ClientID,
+			ClientSecret: cfg.OIDC.ClientSecret,
+			RedirectURL:  redirectURL,
+			Scopes:       cfg.OIDC.Scopes,
+		}, nil)
+		userService.SetOIDCService(service.NewOIDCService(oidcProvider, oidcRepo, userRepo, cfg.App.AllowRegistration, cfg.OIDC.AllowedDomains))
+		appLogger.Info("Single sign-on: enabled (issuer: %s, redirect: %s)", cfg.OIDC.IssuerURL, redirectURL)
+	} else {
+		appLogger.Info("Single sign-on: disabled")
+	}
+
 	userWorkoutService := service.NewUserWorkoutService(
 		userWorkoutRepo,
 		workoutRepo,
@@ -153,12 +284,36 @@ func main() {
 		userWorkoutMovementRepo,
 		userWorkoutWODRepo,
 		wodRepo,
+		transactor,
+	)
+
+	// Outgoing webhooks for workout and PR events
+	webhookService := service.NewWebhookService(webhookRepo)
+	if err := webhookService.SetAllowedNetworks(cfg.Webhook.AllowedNetworks); err != nil {
+		appLogger.Fatal("Invalid WEBHOOK_ALLOWED_NETWORKS: %v", err)
+	}
+
+	// In-app notification center (PRs, scheduled workout reminders), also by email if enabled
+	notificationService := service.NewNotificationService(
+		notificationRepo,
+		userSettingsRepo,
+		userRepo,
+		userWorkoutRepo,
+		movementRepo,
+		wodRepo,
+		appURL,
+		cfg.App.NotificationReminderInterval,
 	)
+	if emailService != nil {
+		notificationService.SetEmailService(emailService)
+	}
+	userWorkoutService.SetEventPublisher(service.EventPublishers{webhookService, notificationService})
 
 	workoutTemplateService := service.NewWorkoutTemplateService(
 		workoutRepo,
 		workoutMovementRepo,
 		workoutWODRepo,
+		transactor,
 	)
 
 	wodService := service.NewWODService(wodRepo)
@@ -171,149 +326,135 @@ func main() {
 
 	userSettingsService := service.NewUserSettingsService(userSettingsRepo)
 
+	// Weekly digest emails (users opt in via notification preferences)
+	var digestService *service.DigestService
+	if emailService != nil && cfg.Email.DigestEnabled {
+		digestService = service.NewDigestService(
+			userRepo,
+			userSettingsRepo,
+			userWorkoutRepo,
+			userWorkoutMovementRepo,
+			userWorkoutWODRepo,
+			digestRepo,
+			emailService,
+			appURL,
+			cfg.Email.DigestCheckInterval,
+		)
+	}
+
+	// Backups of a SQLite deployment, on a schedule and on demand by admins
+	workDir, _ := os.Getwd()
+	var backupService *service.BackupService
+	if cfg.Database.Driver == "sqlite3" {
+		backupService = service.NewBackupService(
+			repository.NewSnapshotter(db),
+			cfg.Backup.Dir,
+			filepath.Join(workDir, "uploads", "avatars"),
+			cfg.Backup.Retain,
+			cfg.Backup.Interval,
+		)
+		if cfg.Backup.Interval > 0 {
+			appLogger.Info("Backups: every %s to %s, keeping %d", cfg.Backup.Interval, cfg.Backup.Dir, cfg.Backup.Retain)
+		} else {
+			appLogger.Info("Backups: on demand only, to %s", cfg.Backup.Dir)
+		}
+	} else {
+		appLogger.Info("Backups: disabled (use your database's dump tools)")
+	}
+
 	// Initialize handlers
-	authHandler := handler.NewAuthHandler(userService, appLogger)
-	userHandler := handler.NewUserHandler(userService, appLogger)
+	authHandler := handler.NewAuthHandler(userService, auditService, appLogger)
+	userHandler := handler.NewUserHandler(userService, auditService, appLogger)
 	movementHandler := handler.NewMovementHandler(movementRepo, appLogger)
 	workoutTemplateHandler := handler.NewWorkoutTemplateHandler(workoutTemplateService)
 	userWorkoutHandler := handler.NewUserWorkoutHandler(userWorkoutService, appLogger)
 	wodHandler := handler.NewWODHandler(wodService)
 	workoutWODHandler := handler.NewWorkoutWODHandler(workoutWODService)
 	settingsHandler := handler.NewSettingsHandler(userSettingsService, appLogger)
-	prHandler := handler.NewPRHandler(db, appLogger)
-	performanceHandler := handler.NewPerformanceHandler(movementRepo, wodRepo, userWorkoutMovementRepo, userWorkoutWODRepo, appLogger)
-	adminHandler := handler.NewAdminHandler(db, userWorkoutWODRepo, wodRepo, userRepo, appLogger)
-
-	// Set up router
-	r := chi.NewRouter()
-
-	// Middleware
-	r.Use(middleware.LoggingMiddleware(appLogger))
-	r.Use(middleware.CORS(cfg.App.CORSOrigins))
-
-	// Health check endpoint
-	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		fmt.Fprintf(w, `{"status":"healthy","version":"%s"}`, version.Version())
-	})
-
-	// Root endpoint
-	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		fmt.Fprintf(w, `{"message":"Welcome to ActaLog API","version":"%s"}`, version.Version())
-	})
+	prHandler := handler.NewPRHandler(db, workoutMovementRepo, workoutRepo, appLogger)
+	performanceHandler := handler.NewPerformanceHandler(movementRepo, wodRepo, userWorkoutMovementRepo, userWorkoutWODRepo, searchRepo, appLogger)
+	adminHandler := handler.NewAdminHandler(db, userWorkoutWODRepo, wodRepo, userRepo, auditService, appLogger)
+	webhookHandler := handler.NewWebhookHandler(webhookService, appLogger)
+	emailOutboxHandler := handler.NewEmailOutboxHandler(emailOutboxService, appLogger)
+	emailTemplateHandler := handler.NewEmailTemplateHandler(emailRenderer, appLogger)
+	notificationHandler := handler.NewNotificationHandler(notificationService, appLogger)
+	backupHandler := handler.NewBackupHandler(backupService, auditService, appLogger)
+	mfaHandler := handler.NewMFAHandler(mfaService, appLogger)
+	passkeyHandler := handler.NewPasskeyHandler(passkeyService, appLogger)
+	apiTokenHandler := handler.NewAPITokenHandler(apiTokenService, appLogger)
+	adminUserHandler := handler.NewAdminUserHandler(adminUserService, lockoutService, auditService, appLogger)
+	auditHandler := handler.NewAuditHandler(auditService, appLogger)
+	jwksHandler := handler.NewJWKSHandler(tokenKeys)
+
+	// Brute-force protection for unauthenticated endpoints that check
+	// passwords or send email, per client IP and per account. Two-factor
+	// codes are limited per user of the MFA challenge token.
+	authRateLimit := func(next http.Handler) http.Handler { return next }
+	mfaRateLimit := authRateLimit
+	if cfg.Security.RateLimitEnabled {
+		rateLimitStore := middleware.NewMemoryRateLimitStore()
+		accountRate := middleware.Rate{Requests: cfg.Security.RateLimitPerAccount, Per: cfg.Security.RateLimitWindow}
+		perIP := middleware.RateLimit(rateLimitStore, middleware.Rate{Requests: cfg.Security.RateLimitPerIP, Per: cfg.Security.RateLimitWindow}, middleware.RateLimitByIP)
+		perAccount := middleware.RateLimit(rateLimitStore, accountRate, middleware.RateLimitByJSONField("email"))
+		perMFAUser := middleware.RateLimit(rateLimitStore, accountRate, middleware.RateLimitByMFAToken(tokenKeys, "mfa_token"))
+		authRateLimit = func(next http.Handler) http.Handler { return perIP(perAccount(next)) }
+		mfaRateLimit = func(next http.Handler) http.Handler { return perIP(perMFAUser(next)) }
+		appLogger.Info("Auth rate limiting: enabled (%d per IP, %d per account every %s)", cfg.Security.RateLimitPerIP, cfg.Security.RateLimitPerAccount, cfg.Security.RateLimitWindow)
+	}
 
-	// Static file serving for uploads (avatars, etc.)
-	workDir, _ := os.Getwd()
-	uploadsDir := http.Dir(filepath.Join(workDir, "uploads"))
-	FileServer(r, "/uploads", uploadsDir)
-
-	// API routes
-	r.Route("/api", func(r chi.Router) {
-		// Version endpoint (public)
-		r.Get("/version", func(w http.ResponseWriter, r *http.Request) {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusOK)
-			fmt.Fprintf(w, `{"version":"%s","build":%d,"fullVersion":"%s","app":"%s"}`,
-				version.Version(), version.BuildNumber(), version.FullVersion(), cfg.App.Name)
-		})
-
-		// Auth routes (public)
-		r.Post("/auth/register", authHandler.Register)
-		r.Post("/auth/login", authHandler.Login)
-		r.Post("/auth/forgot-password", authHandler.ForgotPassword)
-		r.Post("/auth/reset-password", authHandler.ResetPassword)
-		r.Get("/auth/verify-email", authHandler.VerifyEmail)
-		r.Post("/auth/resend-verification", authHandler.ResendVerification)
-		r.Post("/auth/refresh", authHandler.RefreshToken)
-		r.Post("/auth/revoke", authHandler.RevokeToken)
-
-		// Movement routes (public for browsing)
-		r.Get("/movements", movementHandler.ListAll)
-		r.Get("/movements/search", movementHandler.Search)
-		r.Get("/movements/{id}", movementHandler.GetByID)
-
-		// WOD routes (public for browsing standard WODs)
-		r.Get("/wods", wodHandler.ListWODs)
-		r.Get("/wods/search", wodHandler.SearchWODs)
-		r.Get("/wods/{id}", wodHandler.GetWOD)
-
-		// Template routes (public for browsing standard templates)
-		r.Get("/templates", workoutTemplateHandler.ListStandardTemplates)
-		r.Get("/templates/{id}", workoutTemplateHandler.GetTemplate)
-
-		// Protected routes (require authentication)
-		r.Group(func(r chi.Router) {
-			r.Use(middleware.Auth(cfg.JWT.SecretKey))
-
-			// Movement management (authenticated)
-			r.Post("/movements", movementHandler.Create)
-			r.Put("/movements/{id}", movementHandler.Update)
-			r.Delete("/movements/{id}", movementHandler.Delete)
-
-			// User profile routes (authenticated)
-			r.Get("/users/profile", userHandler.GetProfile)
-			r.Put("/users/profile", userHandler.UpdateProfile)
-			r.Post("/users/avatar", userHandler.UploadAvatar)
-			r.Delete("/users/avatar", userHandler.DeleteAvatar)
-
-			// User settings routes (authenticated)
-			r.Get("/users/settings", settingsHandler.GetSettings)
-			r.Put("/users/settings", settingsHandler.UpdateSettings)
-			r.Put("/users/password", userHandler.ChangePassword)
-
-			// Workout Template routes (authenticated)
-			r.Post("/templates", workoutTemplateHandler.CreateTemplate)
-			r.Get("/workouts/my-templates", workoutTemplateHandler.ListMyTemplates)
-			r.Put("/templates/{id}", workoutTemplateHandler.UpdateTemplate)
-			r.Delete("/templates/{id}", workoutTemplateHandler.DeleteTemplate)
-
-			// User Workout routes (logging workouts) (authenticated)
-			r.Post("/workouts", userWorkoutHandler.LogWorkout)
-			r.Get("/workouts", userWorkoutHandler.ListLoggedWorkouts)
-			r.Get("/workouts/standard", workoutTemplateHandler.ListStandardTemplates)
-			r.Get("/workouts/{id}", userWorkoutHandler.GetLoggedWorkout)
-			r.Put("/workouts/{id}", userWorkoutHandler.UpdateLoggedWorkout)
-			r.Delete("/workouts/{id}", userWorkoutHandler.DeleteLoggedWorkout)
-			r.Get("/workouts/stats/monthly", userWorkoutHandler.GetMonthlyStats)
-			r.Get("/workouts/personal-records", userWorkoutHandler.GetPersonalRecords)
-			r.Post("/workouts/retroactive-flag-prs", userWorkoutHandler.RetroactiveFlagPRs)
-
-			// WOD management (authenticated)
-			r.Post("/wods", wodHandler.CreateWOD)
-			r.Put("/wods/{id}", wodHandler.UpdateWOD)
-			r.Delete("/wods/{id}", wodHandler.DeleteWOD)
-
-			// Workout WOD linking (authenticated)
-			r.Post("/templates/{workout_id}/wods", workoutWODHandler.AddWODToWorkout)
-			r.Get("/templates/{workout_id}/wods", workoutWODHandler.ListWODsForWorkout)
-			r.Put("/templates/wods/{workout_wod_id}", workoutWODHandler.UpdateWorkoutWOD)
-			r.Delete("/templates/wods/{workout_wod_id}", workoutWODHandler.RemoveWODFromWorkout)
-			r.Post("/templates/wods/{workout_wod_id}/toggle-pr", workoutWODHandler.ToggleWODPR)
-
-			// PR tracking routes (authenticated)
-			r.Get("/prs", prHandler.GetPersonalRecords)
-			r.Get("/pr-movements", prHandler.GetPRMovements)
-			r.Post("/movements/toggle-pr", prHandler.ToggleMovementPR)
-
-			// Performance tracking routes (authenticated)
-			r.Get("/performance/search", performanceHandler.UnifiedSearch)
-			r.Get("/performance/movements/{id}", performanceHandler.GetMovementPerformance)
-			r.Get("/performance/wods/{id}", performanceHandler.GetWODPerformance)
-
-			// Admin routes (authenticated + admin role check)
-			r.Route("/admin", func(r chi.Router) {
-				r.Use(middleware.AdminOnly)
-				r.Get("/data-cleanup/wod-mismatches", adminHandler.DetectWODScoreTypeMismatches)
-				r.Delete("/data-cleanup/wod-mismatches", adminHandler.FixWODScoreTypeMismatches)
-				r.Put("/data-cleanup/wod-record/{id}", adminHandler.UpdateWODRecord)
-			})
-		})
-	})
+	// Client addresses reported by the reverse proxy
+	trustedProxies, err := middleware.ParseTrustedProxies(cfg.Security.TrustedProxies)
+	if err != nil {
+		appLogger.Fatal("Invalid TRUSTED_PROXIES: %v", err)
+	}
+	if len(trustedProxies) > 0 {
+		appLogger.Info("Trusted proxies: %v", trustedProxies)
+	}
 
-	// Configure HTTP server
+	// Set up router
+	r := (&routes{
+		appName:        cfg.App.Name,
+		corsOrigins:    cfg.App.CORSOrigins,
+		trustedProxies: trustedProxies,
+		uploadsDir:     http.Dir(filepath.Join(workDir, "uploads")),
+		logger:         appLogger,
+		tokenKeys:      tokenKeys,
+		accounts:       userService,
+		apiTokens:      apiTokenService,
+		authRateLimit:  authRateLimit,
+		mfaRateLimit:   mfaRateLimit,
+		emailOutbox:    emailOutboxService != nil,
+		backups:        backupService != nil,
+		queryTimeout:   cfg.Database.QueryTimeout,
+
+		authHandler:            authHandler,
+		userHandler:            userHandler,
+		movementHandler:        movementHandler,
+		workoutTemplateHandler: workoutTemplateHandler,
+		userWorkoutHandler:     userWorkoutHandler,
+		wodHandler:             wodHandler,
+		workoutWODHandler:      workoutWODHandler,
+		settingsHandler:        settingsHandler,
+		prHandler:              prHandler,
+		performanceHandler:     performanceHandler,
+		adminHandler:           adminHandler,
+		webhookHandler:         webhookHandler,
+		emailOutboxHandler:     emailOutboxHandler,
+		backupHandler:          backupHandler,
+		emailTemplateHandler:   emailTemplateHandler,
+		notificationHandler:    notificationHandler,
+		mfaHandler:             mfaHandler,
+		passkeyHandler:         passkeyHandler,
+		apiTokenHandler:        apiTokenHandler,
+		adminUserHandler:       adminUserHandler,
+		auditHandler:           auditHandler,
+		jwksHandler:            jwksHandler,
+	}).router()
+
+	// Configure HTTP server. Requests' contexts derive from requestCtx, so
+	// canceling it aborts their in-flight queries.
+	requestCtx, cancelRequests := context.WithCancel(context.Background())
+	defer cancelRequests()
 	addr := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port)
 	srv := &http.Server{
 		Addr:         addr,
@@ -321,6 +462,25 @@ func main() {
 		ReadTimeout:  cfg.Server.ReadTimeout,
 		WriteTimeout: cfg.Server.WriteTimeout,
 		IdleTimeout:  cfg.Server.IdleTimeout,
+		BaseContext:  func(net.Listener) context.Context { return requestCtx },
+	}
+
+	// Start background workers
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	if emailOutboxService != nil {
+		go emailOutboxService.Run(workerCtx)
+	}
+	if digestService != nil {
+		go digestService.Run(workerCtx)
+	}
+	go notificationService.Run(workerCtx)
+	go webhookService.Run(workerCtx)
+	if signingKeyService != nil {
+		go signingKeyService.Run(workerCtx)
+	}
+	if backupService != nil {
+		go backupService.Run(workerCtx)
 	}
 
 	// Start server in a goroutine
@@ -344,8 +504,14 @@ func main() {
 
 	if err := srv.Shutdown(ctx); err != nil {
 		appLogger.Error("Server forced to shutdown: %v", err)
+		// Abort the queries of requests that are still running
+		cancelRequests()
 	}
 
+	// Stop background workers and let in-flight webhook deliveries finish
+	stopWorkers()
+	webhookService.Wait()
+
 	appLogger.Info("Server exited")
 }
 
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/netip"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/johnzastrow/actalog/internal/domain"
+	"github.com/johnzastrow/actalog/internal/handler"
+	"github.com/johnzastrow/actalog/internal/policy"
+	"github.com/johnzastrow/actalog/pkg/auth"
+	"github.com/johnzastrow/actalog/pkg/logger"
+	"github.com/johnzastrow/actalog/pkg/middleware"
+	"github.com/johnzastrow/actalog/pkg/version"
+)
+
+// routes holds the handlers and settings the HTTP API is wired to
+type routes struct {
+	appName        string
+	corsOrigins    []string
+	trustedProxies []netip.Prefix
+	uploadsDir     http.FileSystem
+	logger         *logger.Logger
+
+	tokenKeys     *auth.KeySet
+	accounts      middleware.AccountChecker
+	apiTokens     middleware.APITokenValidator
+	authRateLimit func(http.Handler) http.Handler
+	mfaRateLimit  func(http.Handler) http.Handler
+	emailOutbox   bool
+	backups       bool
+	queryTimeout  time.Duration
+
+	authHandler            *handler.AuthHandler
+	userHandler            *handler.UserHandler
+	movementHandler        *handler.MovementHandler
+	workoutTemplateHandler *handler.WorkoutTemplateHandler
+	userWorkoutHandler     *handler.UserWorkoutHandler
+	wodHandler             *handler.WODHandler
+	workoutWODHandler      *handler.WorkoutWODHandler
+	settingsHandler        *handler.SettingsHandler
+	prHandler              *handler.PRHandler
+	performanceHandler     *handler.PerformanceHandler
+	adminHandler           *handler.AdminHandler
+	webhookHandler         *handler.WebhookHandler
+	emailOutboxHandler     *handler.EmailOutboxHandler
+	backupHandler          *handler.BackupHandler
+	emailTemplateHandler   *handler.EmailTemplateHandler
+	notificationHandler    *handler.NotificationHandler
+	mfaHandler             *handler.MFAHandler
+	passkeyHandler         *handler.PasskeyHandler
+	apiTokenHandler        *handler.APITokenHandler
+	adminUserHandler       *handler.AdminUserHandler
+	auditHandler           *handler.AuditHandler
+	jwksHandler            *handler.JWKSHandler
+}
+
+// router builds the HTTP router. Routes only check that the caller is
+// signed in (and, for the admin API, what policy allows); handlers and
+// services check access to the resources they load.
+func (rt *routes) router() chi.Router {
+	r := chi.NewRouter()
+
+	// Middleware
+	r.Use(middleware.RealIP(rt.trustedProxies))
+	r.Use(middleware.RequestIDMiddleware(rt.logger))
+	r.Use(middleware.LoggingMiddleware(rt.logger))
+	r.Use(middleware.CORS(rt.corsOrigins))
+
+	// Health check endpoint
+	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `{"status":"healthy","version":"%s"}`, version.Version())
+	})
+
+	// Public keys for verifying access tokens
+	r.Get("/.well-known/jwks.json", rt.jwksHandler.JWKS)
+
+	// Root endpoint
+	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `{"message":"Welcome to ActaLog API","version":"%s"}`, version.Version())
+	})
+
+	// Static file serving for uploads (avatars, etc.)
+	FileServer(r, "/uploads", rt.uploadsDir)
+
+	// API routes
+	r.Route("/api", func(r chi.Router) {
+		r.Use(middleware.Timeout(rt.queryTimeout))
+
+		// Version endpoint (public)
+		r.Get("/version", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprintf(w, `{"version":"%s","build":%d,"fullVersion":"%s","app":"%s"}`,
+				version.Version(), version.BuildNumber(), version.FullVersion(), rt.appName)
+		})
+
+		// Auth routes (public)
+		r.Post("/auth/register", rt.authHandler.Register)
+		r.With(rt.authRateLimit).Post("/auth/login", rt.authHandler.Login)
+		r.With(rt.mfaRateLimit).Post("/auth/login/mfa", rt.authHandler.LoginMFA)
+		r.Post("/auth/passkey/options", rt.authHandler.BeginPasskeyLogin)
+		r.Post("/auth/passkey/login", rt.authHandler.LoginPasskey)
+		r.Get("/auth/oidc/authorize", rt.authHandler.BeginOIDCLogin)
+		r.Post("/auth/oidc/callback", rt.authHandler.OIDCCallback)
+		r.With(rt.authRateLimit).Post("/auth/forgot-password", rt.authHandler.ForgotPassword)
+		r.Post("/auth/reset-password", rt.authHandler.ResetPassword)
+		r.Get("/auth/verify-email", rt.authHandler.VerifyEmail)
+		r.With(rt.authRateLimit).Post("/auth/resend-verification", rt.authHandler.ResendVerification)
+		r.Post("/auth/refresh", rt.authHandler.RefreshToken)
+		r.Post("/auth/revoke", rt.authHandler.RevokeToken)
+
+		// Movement routes (public for browsing)
+		r.Get("/movements", rt.movementHandler.ListAll)
+		r.Get("/movements/search", rt.movementHandler.Search)
+		r.Get("/movements/{id}", rt.movementHandler.GetByID)
+
+		// WOD routes (public for browsing standard WODs)
+		r.Get("/wods", rt.wodHandler.ListWODs)
+		r.Get("/wods/search", rt.wodHandler.SearchWODs)
+		r.Get("/wods/{id}", rt.wodHandler.GetWOD)
+
+		// Template routes (public for browsing standard templates)
+		r.Get("/templates", rt.workoutTemplateHandler.ListStandardTemplates)
+		r.Get("/templates/{id}", rt.workoutTemplateHandler.GetTemplate)
+
+		// Protected routes (require authentication)
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.Auth(rt.tokenKeys, rt.accounts))
+
+			// Movement management (authenticated)
+			r.Post("/movements", rt.movementHandler.Create)
+			r.Put("/movements/{id}", rt.movementHandler.Update)
+			r.Delete("/movements/{id}", rt.movementHandler.Delete)
+
+			// User profile routes (authenticated)
+			r.Get("/users/profile", rt.userHandler.GetProfile)
+			r.Put("/users/profile", rt.userHandler.UpdateProfile)
+			r.Post("/users/avatar", rt.userHandler.UploadAvatar)
+			r.Delete("/users/avatar", rt.userHandler.DeleteAvatar)
+			r.Get("/users/identities", rt.userHandler.ListIdentities)
+
+			// Remembered sessions (sign out a device, or everywhere)
+			r.Get("/users/sessions", rt.userHandler.ListSessions)
+			r.Delete("/users/sessions", rt.userHandler.RevokeAllSessions)
+			r.Delete("/users/sessions/{id}", rt.userHandler.RevokeSession)
+
+			// User settings routes (authenticated)
+			r.Get("/users/settings", rt.settingsHandler.GetSettings)
+			r.Put("/users/settings", rt.settingsHandler.UpdateSettings)
+
+			// Two-factor authentication routes (authenticated)
+			r.Get("/users/mfa", rt.mfaHandler.GetMFAStatus)
+			r.Post("/users/mfa/totp", rt.mfaHandler.EnrollTOTP)
+			r.Post("/users/mfa/totp/confirm", rt.mfaHandler.ConfirmTOTP)
+			r.Post("/users/mfa/recovery-codes", rt.mfaHandler.RegenerateRecoveryCodes)
+			r.Post("/users/mfa/disable", rt.mfaHandler.DisableMFA)
+
+			// Passkeys
+			r.Get("/users/passkeys", rt.passkeyHandler.ListPasskeys)
+			r.Post("/users/passkeys/options", rt.passkeyHandler.BeginRegistration)
+			r.Post("/users/passkeys", rt.passkeyHandler.FinishRegistration)
+			r.Put("/users/passkeys/{id}", rt.passkeyHandler.RenamePasskey)
+			r.Delete("/users/passkeys/{id}", rt.passkeyHandler.DeletePasskey)
+
+			// Personal API tokens (login session only: a token can't manage tokens)
+			r.Get("/users/tokens", rt.apiTokenHandler.ListAPITokens)
+			r.Post("/users/tokens", rt.apiTokenHandler.CreateAPIToken)
+			r.Delete("/users/tokens/{id}", rt.apiTokenHandler.DeleteAPIToken)
+
+			// Notification center routes (authenticated)
+			r.Get("/notifications", rt.notificationHandler.ListNotifications)
+			r.Get("/notifications/unread-count", rt.notificationHandler.GetUnreadCount)
+			r.Post("/notifications/read-all", rt.notificationHandler.MarkAllNotificationsRead)
+			r.Post("/notifications/{id}/read", rt.notificationHandler.MarkNotificationRead)
+			r.Put("/users/password", rt.userHandler.ChangePassword)
+
+			// Workout Template routes (authenticated)
+			r.Post("/templates", rt.workoutTemplateHandler.CreateTemplate)
+			r.Get("/workouts/my-templates", rt.workoutTemplateHandler.ListMyTemplates)
+			r.Put("/templates/{id}", rt.workoutTemplateHandler.UpdateTemplate)
+			r.Delete("/templates/{id}", rt.workoutTemplateHandler.DeleteTemplate)
+
+			// User Workout routes (authenticated)
+			r.Get("/workouts/standard", rt.workoutTemplateHandler.ListStandardTemplates)
+			r.Post("/workouts/retroactive-flag-prs", rt.userWorkoutHandler.RetroactiveFlagPRs)
+
+			// WOD management (authenticated)
+			r.Post("/wods", rt.wodHandler.CreateWOD)
+			r.Put("/wods/{id}", rt.wodHandler.UpdateWOD)
+			r.Delete("/wods/{id}", rt.wodHandler.DeleteWOD)
+
+			// Workout WOD linking (authenticated)
+			r.Post("/templates/{workout_id}/wods", rt.workoutWODHandler.AddWODToWorkout)
+			r.Get("/templates/{workout_id}/wods", rt.workoutWODHandler.ListWODsForWorkout)
+			r.Put("/templates/wods/{workout_wod_id}", rt.workoutWODHandler.UpdateWorkoutWOD)
+			r.Delete("/templates/wods/{workout_wod_id}", rt.workoutWODHandler.RemoveWODFromWorkout)
+			r.Post("/templates/wods/{workout_wod_id}/toggle-pr", rt.workoutWODHandler.ToggleWODPR)
+
+			// PR tracking routes (authenticated)
+			r.Post("/movements/toggle-pr", rt.prHandler.ToggleMovementPR)
+
+			// Webhook routes (authenticated)
+			r.Get("/webhooks", rt.webhookHandler.ListWebhooks)
+			r.Post("/webhooks", rt.webhookHandler.CreateWebhook)
+			r.Get("/webhooks/{id}", rt.webhookHandler.GetWebhook)
+			r.Put("/webhooks/{id}", rt.webhookHandler.UpdateWebhook)
+			r.Delete("/webhooks/{id}", rt.webhookHandler.DeleteWebhook)
+			r.Post("/webhooks/{id}/rotate-secret", rt.webhookHandler.RotateWebhookSecret)
+			r.Get("/webhooks/{id}/deliveries", rt.webhookHandler.ListWebhookDeliveries)
+			r.Post("/webhooks/{id}/test", rt.webhookHandler.TestWebhook)
+
+			// Admin routes (authenticated + admin role check)
+			r.Route("/admin", func(r chi.Router) {
+				// Data cleanup, email template previews, the email outbox and backups
+				r.Group(func(r chi.Router) {
+					r.Use(policy.Require(policy.KindSystem))
+					r.Get("/data-cleanup/wod-mismatches", rt.adminHandler.DetectWODScoreTypeMismatches)
+					r.Delete("/data-cleanup/wod-mismatches", rt.adminHandler.FixWODScoreTypeMismatches)
+					r.Put("/data-cleanup/wod-record/{id}", rt.adminHandler.UpdateWODRecord)
+					r.Get("/email-templates", rt.emailTemplateHandler.ListEmailTemplates)
+					r.Get("/email-templates/{name}/preview", rt.emailTemplateHandler.PreviewEmailTemplate)
+
+					// Email outbox (only when email is enabled)
+					if rt.emailOutbox {
+						r.Get("/email-outbox", rt.emailOutboxHandler.ListOutbox)
+						r.Post("/email-outbox/{id}/retry", rt.emailOutboxHandler.RetryOutboxEmail)
+					}
+
+					// Backups (only for SQLite), which take as long as the
+					// database is big to write and download
+					if rt.backups {
+						r.Get("/backups", rt.backupHandler.ListBackups)
+						r.With(middleware.WithoutTimeout).Post("/backups", rt.backupHandler.CreateBackup)
+						r.With(middleware.WithoutTimeout).Get("/backups/{name}", rt.backupHandler.DownloadBackup)
+					}
+				})
+
+				// User accounts
+				r.Group(func(r chi.Router) {
+					r.Use(policy.Require(policy.KindUser))
+					r.Get("/users", rt.adminUserHandler.ListUsers)
+					r.Get("/users/{id}", rt.adminUserHandler.GetUser)
+					r.Put("/users/{id}/role", rt.adminUserHandler.SetRole)
+					r.Post("/users/{id}/disable", rt.adminUserHandler.DisableUser)
+					r.Post("/users/{id}/enable", rt.adminUserHandler.EnableUser)
+					r.Post("/users/{id}/force-password-reset", rt.adminUserHandler.ForcePasswordReset)
+					r.Post("/users/{id}/resend-verification", rt.adminUserHandler.ResendVerification)
+					r.Post("/users/{id}/unlock", rt.adminUserHandler.UnlockUser)
+					r.Delete("/users/{id}", rt.adminUserHandler.DeleteUser)
+				})
+
+				// Audit log
+				r.With(policy.Require(policy.KindAuditLog)).Get("/audit", rt.auditHandler.ListAuditLog)
+			})
+		})
+
+		// Routes that also accept personal API tokens, each limited to a scope
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.AuthWithAPITokens(rt.tokenKeys, rt.accounts, rt.apiTokens))
+
+			// User Workout routes (logging workouts)
+			r.With(middleware.RequireScope(domain.ScopeWorkoutsWrite)).Post("/workouts", rt.userWorkoutHandler.LogWorkout)
+			r.With(middleware.RequireScope(domain.ScopeWorkoutsRead)).Get("/workouts", rt.userWorkoutHandler.ListLoggedWorkouts)
+			r.With(middleware.RequireScope(domain.ScopeWorkoutsRead)).Get("/workouts/{id}", rt.userWorkoutHandler.GetLoggedWorkout)
+			r.With(middleware.RequireScope(domain.ScopeWorkoutsWrite)).Put("/workouts/{id}", rt.userWorkoutHandler.UpdateLoggedWorkout)
+			r.With(middleware.RequireScope(domain.ScopeWorkoutsWrite)).Delete("/workouts/{id}", rt.userWorkoutHandler.DeleteLoggedWorkout)
+			r.With(middleware.RequireScope(domain.ScopeWorkoutsRead)).Get("/workouts/stats/monthly", rt.userWorkoutHandler.GetMonthlyStats)
+
+			// Performance tracking routes
+			r.With(middleware.RequireScope(domain.ScopeWorkoutsRead)).Get("/performance/search", rt.performanceHandler.UnifiedSearch)
+			r.With(middleware.RequireScope(domain.ScopeWorkoutsRead)).Get("/performance/movements/{id}", rt.performanceHandler.GetMovementPerformance)
+			r.With(middleware.RequireScope(domain.ScopeWorkoutsRead)).Get("/performance/wods/{id}", rt.performanceHandler.GetWODPerformance)
+
+			// PR tracking routes
+			r.With(middleware.RequireScope(domain.ScopePRsRead)).Get("/prs", rt.prHandler.GetPersonalRecords)
+			r.With(middleware.RequireScope(domain.ScopePRsRead)).Get("/pr-movements", rt.prHandler.GetPRMovements)
+			r.With(middleware.RequireScope(domain.ScopePRsRead)).Get("/workouts/personal-records", rt.userWorkoutHandler.GetPersonalRecords)
+		})
+	})
+
+	return r
+}
@@ -0,0 +1,300 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/johnzastrow/actalog/pkg/auth"
+	"github.com/johnzastrow/actalog/pkg/logger"
+	"github.com/johnzastrow/actalog/pkg/middleware"
+)
+
+// Who a route is open to, before handlers and services check access to the
+// resources it loads
+const (
+	public   = "public"
+	signedIn = "signed in"
+	admin    = "admin"
+)
+
+// routeAccess lists every route main registers. TestRouteAccess fails if a
+// route is added or removed without updating it.
+var routeAccess = []struct {
+	method  string
+	pattern string
+	access  string
+}{
+	{"GET", "/", public},
+	{"GET", "/health", public},
+	{"GET", "/.well-known/jwks.json", public},
+	{"GET", "/uploads", public},
+	{"GET", "/uploads/*", public},
+	{"GET", "/api/version", public},
+
+	// Signing in and account recovery
+	{"POST", "/api/auth/register", public},
+	{"POST", "/api/auth/login", public},
+	{"POST", "/api/auth/login/mfa", public},
+	{"POST", "/api/auth/passkey/options", public},
+	{"POST", "/api/auth/passkey/login", public},
+	{"GET", "/api/auth/oidc/authorize", public},
+	{"POST", "/api/auth/oidc/callback", public},
+	{"POST", "/api/auth/forgot-password", public},
+	{"POST", "/api/auth/reset-password", public},
+	{"GET", "/api/auth/verify-email", public},
+	{"POST", "/api/auth/resend-verification", public},
+	{"POST", "/api/auth/refresh", public},
+	{"POST", "/api/auth/revoke", public},
+
+	// Browsing the catalogue
+	{"GET", "/api/movements", public},
+	{"GET", "/api/movements/search", public},
+	{"GET", "/api/movements/{id}", public},
+	{"GET", "/api/wods", public},
+	{"GET", "/api/wods/search", public},
+	{"GET", "/api/wods/{id}", public},
+	{"GET", "/api/templates", public},
+	{"GET", "/api/templates/{id}", public},
+
+	// Changing the catalogue (owners and admins, checked per resource)
+	{"POST", "/api/movements", signedIn},
+	{"PUT", "/api/movements/{id}", signedIn},
+	{"DELETE", "/api/movements/{id}", signedIn},
+	{"POST", "/api/movements/toggle-pr", signedIn},
+	{"POST", "/api/wods", signedIn},
+	{"PUT", "/api/wods/{id}", signedIn},
+	{"DELETE", "/api/wods/{id}", signedIn},
+	{"POST", "/api/templates", signedIn},
+	{"PUT", "/api/templates/{id}", signedIn},
+	{"DELETE", "/api/templates/{id}", signedIn},
+	{"GET", "/api/workouts/my-templates", signedIn},
+	{"GET", "/api/workouts/standard", signedIn},
+	{"POST", "/api/templates/{workout_id}/wods", signedIn},
+	{"GET", "/api/templates/{workout_id}/wods", signedIn},
+	{"PUT", "/api/templates/wods/{workout_wod_id}", signedIn},
+	{"DELETE", "/api/templates/wods/{workout_wod_id}", signedIn},
+	{"POST", "/api/templates/wods/{workout_wod_id}/toggle-pr", signedIn},
+
+	// The caller's own account
+	{"GET", "/api/users/profile", signedIn},
+	{"PUT", "/api/users/profile", signedIn},
+	{"POST", "/api/users/avatar", signedIn},
+	{"DELETE", "/api/users/avatar", signedIn},
+	{"GET", "/api/users/identities", signedIn},
+	{"PUT", "/api/users/password", signedIn},
+	{"GET", "/api/users/sessions", signedIn},
+	{"DELETE", "/api/users/sessions", signedIn},
+	{"DELETE", "/api/users/sessions/{id}", signedIn},
+	{"GET", "/api/users/settings", signedIn},
+	{"PUT", "/api/users/settings", signedIn},
+	{"GET", "/api/users/mfa", signedIn},
+	{"POST", "/api/users/mfa/totp", signedIn},
+	{"POST", "/api/users/mfa/totp/confirm", signedIn},
+	{"POST", "/api/users/mfa/recovery-codes", signedIn},
+	{"POST", "/api/users/mfa/disable", signedIn},
+	{"GET", "/api/users/passkeys", signedIn},
+	{"POST", "/api/users/passkeys/options", signedIn},
+	{"POST", "/api/users/passkeys", signedIn},
+	{"PUT", "/api/users/passkeys/{id}", signedIn},
+	{"DELETE", "/api/users/passkeys/{id}", signedIn},
+	{"GET", "/api/users/tokens", signedIn},
+	{"POST", "/api/users/tokens", signedIn},
+	{"DELETE", "/api/users/tokens/{id}", signedIn},
+	{"GET", "/api/notifications", signedIn},
+	{"GET", "/api/notifications/unread-count", signedIn},
+	{"POST", "/api/notifications/read-all", signedIn},
+	{"POST", "/api/notifications/{id}/read", signedIn},
+
+	// Webhooks (owners, and admins for all-users webhooks)
+	{"GET", "/api/webhooks", signedIn},
+	{"POST", "/api/webhooks", signedIn},
+	{"GET", "/api/webhooks/{id}", signedIn},
+	{"PUT", "/api/webhooks/{id}", signedIn},
+	{"DELETE", "/api/webhooks/{id}", signedIn},
+	{"POST", "/api/webhooks/{id}/rotate-secret", signedIn},
+	{"GET", "/api/webhooks/{id}/deliveries", signedIn},
+	{"POST", "/api/webhooks/{id}/test", signedIn},
+
+	// The caller's training log
+	{"POST", "/api/workouts/retroactive-flag-prs", signedIn},
+	{"POST", "/api/workouts", signedIn},
+	{"GET", "/api/workouts", signedIn},
+	{"GET", "/api/workouts/{id}", signedIn},
+	{"PUT", "/api/workouts/{id}", signedIn},
+	{"DELETE", "/api/workouts/{id}", signedIn},
+	{"GET", "/api/workouts/stats/monthly", signedIn},
+	{"GET", "/api/workouts/personal-records", signedIn},
+	{"GET", "/api/performance/search", signedIn},
+	{"GET", "/api/performance/movements/{id}", signedIn},
+	{"GET", "/api/performance/wods/{id}", signedIn},
+	{"GET", "/api/prs", signedIn},
+	{"GET", "/api/pr-movements", signedIn},
+
+	// Administration
+	{"GET", "/api/admin/data-cleanup/wod-mismatches", admin},
+	{"DELETE", "/api/admin/data-cleanup/wod-mismatches", admin},
+	{"PUT", "/api/admin/data-cleanup/wod-record/{id}", admin},
+	{"GET", "/api/admin/email-templates", admin},
+	{"GET", "/api/admin/email-templates/{name}/preview", admin},
+	{"GET", "/api/admin/email-outbox", admin},
+	{"POST", "/api/admin/email-outbox/{id}/retry", admin},
+	{"GET", "/api/admin/backups", admin},
+	{"POST", "/api/admin/backups", admin},
+	{"GET", "/api/admin/backups/{name}", admin},
+	{"GET", "/api/admin/users", admin},
+	{"GET", "/api/admin/users/{id}", admin},
+	{"PUT", "/api/admin/users/{id}/role", admin},
+	{"POST", "/api/admin/users/{id}/disable", admin},
+	{"POST", "/api/admin/users/{id}/enable", admin},
+	{"POST", "/api/admin/users/{id}/force-password-reset", admin},
+	{"POST", "/api/admin/users/{id}/resend-verification", admin},
+	{"POST", "/api/admin/users/{id}/unlock", admin},
+	{"DELETE", "/api/admin/users/{id}", admin},
+	{"GET", "/api/admin/audit", admin},
+}
+
+// newTestRouter builds the router without any handlers. Requests that get
+// past the route's middleware panic on the nil handler, which serve turns
+// into a 200.
+func newTestRouter(t *testing.T) (chi.Router, *auth.KeySet) {
+	return newTestRouterWithAccounts(t, nil)
+}
+
+// newTestRouterWithAccounts is newTestRouter with an account checker
+func newTestRouterWithAccounts(t *testing.T, accounts middleware.AccountChecker) (chi.Router, *auth.KeySet) {
+	t.Helper()
+	appLogger, err := logger.New(logger.Config{Level: "error"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := auth.NewHMACKeySet("test-secret-key")
+	rt := &routes{
+		appName:       "ActaLog",
+		uploadsDir:    http.Dir(t.TempDir()),
+		logger:        appLogger,
+		tokenKeys:     keys,
+		accounts:      accounts,
+		authRateLimit: func(next http.Handler) http.Handler { return next },
+		mfaRateLimit:  func(next http.Handler) http.Handler { return next },
+		emailOutbox:   true,
+		backups:       true,
+	}
+	return rt.router(), keys
+}
+
+var urlParam = regexp.MustCompile(`\{[^}]+\}`)
+
+func TestRouteAccess(t *testing.T) {
+	router, keys := newTestRouter(t)
+
+	// Every registered route must be in the table, and vice versa
+	registered := map[string]bool{}
+	err := chi.Walk(router, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		registered[method+" "+route] = true
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	listed := map[string]bool{}
+	for _, rt := range routeAccess {
+		key := rt.method + " " + rt.pattern
+		listed[key] = true
+		if !registered[key] {
+			t.Errorf("%s is listed but not registered", key)
+		}
+	}
+	for key := range registered {
+		if !listed[key] {
+			t.Errorf("%s is registered but missing from routeAccess", key)
+		}
+	}
+
+	userToken, _ := auth.GenerateToken(1, "user@example.com", "user", keys, time.Hour)
+	adminToken, _ := auth.GenerateToken(2, "admin@example.com", "admin", keys, time.Hour)
+	callers := []struct {
+		name  string
+		token string
+		// allowed is the access levels the caller may reach
+		allowed map[string]bool
+		// deniedStatus is the response when it may not
+		deniedStatus int
+	}{
+		{"anonymous", "", map[string]bool{public: true}, http.StatusUnauthorized},
+		{"user", userToken, map[string]bool{public: true, signedIn: true}, http.StatusForbidden},
+		{"admin", adminToken, map[string]bool{public: true, signedIn: true, admin: true}, 0},
+	}
+
+	for _, rt := range routeAccess {
+		for _, caller := range callers {
+			t.Run(fmt.Sprintf("%s %s as %s", rt.method, rt.pattern, caller.name), func(t *testing.T) {
+				path := urlParam.ReplaceAllString(rt.pattern, "1")
+				if path == "/uploads/*" {
+					path = "/uploads/avatar.png"
+				}
+				req := httptest.NewRequest(rt.method, path, nil)
+				if caller.token != "" {
+					req.Header.Set("Authorization", "Bearer "+caller.token)
+				}
+
+				status := serve(router, req)
+				reached := status != http.StatusUnauthorized && status != http.StatusForbidden
+
+				if caller.allowed[rt.access] && !reached {
+					t.Errorf("expected %s to reach a %s route, got %d", caller.name, rt.access, status)
+				}
+				if !caller.allowed[rt.access] && status != caller.deniedStatus {
+					t.Errorf("expected status %d for %s on a %s route, got %d", caller.deniedStatus, caller.name, rt.access, status)
+				}
+			})
+		}
+	}
+}
+
+// serve handles the request and returns the response status, or 200 if a
+// handler panicked
+func serve(router http.Handler, req *http.Request) (status int) {
+	w := httptest.NewRecorder()
+	defer func() {
+		if recover() != nil {
+			status = http.StatusOK
+		}
+	}()
+	router.ServeHTTP(w, req)
+	return w.Code
+}
+
+// roleAccounts is an account checker that reports each user's role
+type roleAccounts map[int64]string
+
+func (a roleAccounts) AccountStatus(ctx context.Context, userID int64) (bool, string, error) {
+	role, ok := a[userID]
+	return ok, role, nil
+}
+
+func TestRouteAccessFollowsRoleChanges(t *testing.T) {
+	accounts := roleAccounts{2: "admin"}
+	router, keys := newTestRouterWithAccounts(t, accounts)
+	token, _ := auth.GenerateToken(2, "admin@example.com", "admin", keys, time.Hour)
+
+	request := func() int {
+		req := httptest.NewRequest("GET", "/api/admin/users", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		return serve(router, req)
+	}
+	if status := request(); status != http.StatusOK {
+		t.Fatalf("expected the admin to reach an admin route, got %d", status)
+	}
+
+	// A demoted admin's access token no longer opens admin routes
+	accounts[2] = "user"
+	if status := request(); status != http.StatusForbidden {
+		t.Errorf("expected 403 after the demotion, got %d", status)
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/johnzastrow/actalog/configs"
+	"github.com/johnzastrow/actalog/internal/domain"
+	"github.com/johnzastrow/actalog/internal/repository"
+	"github.com/johnzastrow/actalog/internal/service"
+	"github.com/joho/godotenv"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const usage = `Usage: backup [-dir DIR] [-avatars DIR] COMMAND
+
+Commands:
+  create        Back up the database and avatars into a new archive; the server can keep running
+  list          List the backup archives, newest first
+  restore FILE  Replace the database and avatars with an archive's; stop the server first
+
+Restore keeps the files it replaces, renamed with a .pre-restore-<time> suffix.
+
+Flags:
+`
+
+func main() {
+	// Load .env file
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found")
+	}
+
+	// Load configuration
+	cfg, err := configs.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	dir := flag.String("dir", cfg.Backup.Dir, "backup archive directory")
+	avatarsDir := flag.String("avatars", "uploads/avatars", "avatar uploads directory")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	args := flag.Args()
+	if len(args) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if cfg.Database.Driver != "sqlite3" {
+		log.Fatal(domain.ErrBackupUnsupported)
+	}
+	dbPath := cfg.Database.Database
+
+	// Open database connection
+	db, err := sql.Open(cfg.Database.Driver, dbPath)
+	if err != nil {
+		log.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	snapshotter := repository.NewSnapshotter(db)
+	backups := service.NewBackupService(snapshotter, *dir, *avatarsDir, cfg.Backup.Retain, 0)
+	ctx := context.Background()
+
+	switch {
+	case args[0] == "create" && len(args) == 1:
+		if _, err := os.Stat(dbPath); err != nil {
+			log.Fatalf("Failed to open database: %v", err)
+		}
+		backup, err := backups.Create(ctx, domain.BackupTriggerCLI)
+		if err != nil {
+			log.Fatalf("Failed to create backup: %v", err)
+		}
+		fmt.Printf("✓ Created %s/%s (%d bytes)\n", *dir, backup.Name, backup.Size)
+
+	case args[0] == "list" && len(args) == 1:
+		list, err := backups.List()
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(list) == 0 {
+			fmt.Printf("No backups in %s\n", *dir)
+		}
+		for _, backup := range list {
+			fmt.Printf("  %s  %s  %d bytes\n", backup.Name, backup.CreatedAt.Format("2006-01-02 15:04:05 MST"), backup.Size)
+		}
+
+	case args[0] == "restore" && len(args) == 2:
+		manifest, setAside, err := service.RestoreBackup(ctx, snapshotter, args[1], dbPath, *avatarsDir)
+		for _, path := range setAside {
+			fmt.Printf("  Kept previous %s\n", path)
+		}
+		if err != nil {
+			log.Fatalf("Failed to restore backup: %v", err)
+		}
+		fmt.Printf("✓ Restored backup of %s from ActaLog v%s (build %d, migration %d, %d avatars)\n",
+			manifest.CreatedAt.Format("2006-01-02 15:04:05 MST"), manifest.AppVersion, manifest.Build, manifest.SchemaVersion, manifest.Avatars)
+		fmt.Println("  Start the server to apply any newer migrations")
+
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
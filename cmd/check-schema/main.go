@@ -1,16 +1,27 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
+	"os"
 
 	"github.com/johnzastrow/actalog/configs"
 	"github.com/johnzastrow/actalog/internal/repository"
 	"github.com/joho/godotenv"
 )
 
+// baselineVersion is the migration a database still on the legacy
+// migrations should match
+const baselineVersion = 1
+
 func main() {
+	reference := flag.String("reference", "", "DSN of an empty database, of the same driver, to build the expected schema in (default: a temporary one)")
+	flag.Parse()
+
 	// Load .env file
 	if err := godotenv.Load(); err != nil {
 		log.Println("No .env file found")
@@ -39,45 +50,105 @@ func main() {
 		log.Fatalf("Failed to open database: %v", err)
 	}
 	defer db.Close()
-
-	// Query tables
-	var query string
-	if cfg.Database.Driver == "sqlite3" {
-		query = "SELECT name FROM sqlite_master WHERE type='table' ORDER BY name"
-	} else if cfg.Database.Driver == "postgres" {
-		query = "SELECT tablename FROM pg_tables WHERE schemaname='public' ORDER BY tablename"
-	} else {
-		query = "SHOW TABLES"
+	if err := db.Ping(); err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
 	}
 
-	rows, err := db.Query(query)
+	differences, err := check(context.Background(), db, cfg.Database.Driver, dsn, *reference)
 	if err != nil {
-		log.Fatalf("Failed to query tables: %v", err)
+		log.Fatal(err)
 	}
-	defer rows.Close()
+	if differences > 0 {
+		fmt.Printf("\n%d difference(s) from the expected schema\n", differences)
+		os.Exit(1)
+	}
+}
 
+// check compares the database's schema with the one its applied migrations
+// build in an empty database, prints the report and returns the number of
+// differences
+func check(ctx context.Context, db *sql.DB, driver, dsn, reference string) (int, error) {
+	actual, err := repository.InspectSchema(ctx, db)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read schema: %w", err)
+	}
 	fmt.Println("=== Database Tables ===")
-	for rows.Next() {
-		var tableName string
-		if err := rows.Scan(&tableName); err != nil {
-			log.Fatalf("Failed to scan row: %v", err)
+	for _, name := range actual.TableNames() {
+		fmt.Printf("  - %s\n", name)
+	}
+
+	version, err := appliedVersion(ctx, db)
+	if err != nil {
+		return 0, err
+	}
+
+	var scratch *sql.DB
+	if reference != "" {
+		scratch, err = sql.Open(driver, reference)
+	} else {
+		var cleanup func()
+		scratch, cleanup, err = repository.OpenScratchDB(ctx, db, dsn)
+		if cleanup != nil {
+			defer cleanup()
 		}
-		fmt.Printf("  - %s\n", tableName)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to open database for the expected schema: %w", err)
+	}
+	defer scratch.Close()
+	expected, err := repository.ExpectedSchema(ctx, scratch, version)
+	if err != nil {
+		return 0, fmt.Errorf("failed to build expected schema: %w", err)
+	}
+
+	fmt.Printf("\n=== Schema Check (migration %d) ===\n", version)
+	drift := repository.DiffSchemas(expected, actual)
+	if len(drift) == 0 {
+		fmt.Println("✓ Schema matches the migrations")
+		return 0, nil
+	}
+	table := ""
+	for _, d := range drift {
+		if d.Table != table {
+			table = d.Table
+			fmt.Printf("  %s\n", table)
+		}
+		fmt.Printf("    ✗ %s\n", d.Problem)
+	}
+	return len(drift), nil
+}
+
+// appliedVersion prints the applied migrations and returns the newest one
+// with migration files, which the schema is checked against
+func appliedVersion(ctx context.Context, db *sql.DB) (int, error) {
+	migrator, err := repository.NewMigrator(db)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load migrations: %w", err)
 	}
 
-	// Check migrations
 	fmt.Println("\n=== Applied Migrations ===")
-	rows2, err := db.Query("SELECT version, description FROM schema_migrations ORDER BY applied_at")
+	statuses, err := migrator.Status(ctx)
+	if errors.Is(err, repository.ErrLegacyMigrations) {
+		// The legacy migrations end at the baseline
+		fmt.Println("  Legacy schema_migrations table; checking against the baseline")
+		return baselineVersion, nil
+	}
 	if err != nil {
-		log.Fatalf("Failed to query migrations: %v", err)
+		return 0, fmt.Errorf("failed to get migration status: %w", err)
 	}
-	defer rows2.Close()
 
-	for rows2.Next() {
-		var version, description string
-		if err := rows2.Scan(&version, &description); err != nil {
-			log.Fatalf("Failed to scan migration: %v", err)
+	version := 0
+	for _, status := range statuses {
+		switch {
+		case status.Unknown:
+			fmt.Printf("  ? %s  no migration files, not checked\n", status.Migration)
+		case status.Changed:
+			fmt.Printf("  ! %s  changed since it was applied\n", status.Migration)
+			version = status.Version
+		case status.Applied:
+			fmt.Printf("  ✓ %s\n", status.Migration)
+			version = status.Version
 		}
-		fmt.Printf("  ✓ %s - %s\n", version, description)
 	}
+	return version, nil
 }
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/johnzastrow/actalog/configs"
+	"github.com/johnzastrow/actalog/internal/repository"
+	"github.com/joho/godotenv"
+)
+
+const usage = `Usage: dbcopy [-overwrite]
+
+Copies every table from the database configured by DB_DRIVER, DB_HOST,
+DB_PORT, DB_USER, DB_PASSWORD, DB_NAME and DB_SSLMODE into the one configured
+by the same variables prefixed with TARGET_, e.g. TARGET_DB_DRIVER=postgres.
+
+The target is migrated first, and must not have any users unless -overwrite
+is given. Stop the server before copying so that no writes are missed.
+
+Flags:
+`
+
+func main() {
+	overwrite := flag.Bool("overwrite", false, "replace the target's data even if it has users")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() > 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// Load .env file
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found")
+	}
+
+	// Load configuration
+	cfg, err := configs.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+	targetCfg := configs.LoadDatabaseConfig("TARGET_")
+
+	sourceDSN := buildDSN(cfg.Database)
+	targetDSN := buildDSN(targetCfg)
+	if cfg.Database.Driver == targetCfg.Driver && sourceDSN == targetDSN {
+		log.Fatal("Source and target are the same database; set the TARGET_DB_* variables")
+	}
+
+	// Open the source as it is; it must already be migrated
+	source, err := sql.Open(cfg.Database.Driver, sourceDSN)
+	if err != nil {
+		log.Fatalf("Failed to open source database: %v", err)
+	}
+	defer source.Close()
+	if err := source.Ping(); err != nil {
+		log.Fatalf("Failed to connect to source database: %v", err)
+	}
+
+	// Create the target's schema
+	fmt.Printf("Preparing %s target %s\n", targetCfg.Driver, targetCfg.Database)
+	target, err := repository.InitDatabase(targetCfg.Driver, targetDSN)
+	if err != nil {
+		log.Fatalf("Failed to initialize target database: %v", err)
+	}
+	defer target.Close()
+
+	fmt.Printf("Copying %s database %s\n", cfg.Database.Driver, cfg.Database.Database)
+	copies, err := repository.CopyDatabase(context.Background(), source, target, repository.CopyOptions{
+		Overwrite: *overwrite,
+		Progress:  os.Stdout,
+	})
+	if errors.Is(err, repository.ErrTargetNotEmpty) {
+		log.Fatalf("Target database already has users; rerun with -overwrite to replace its data")
+	}
+
+	fmt.Println("\n=== Row Counts ===")
+	var total int64
+	for _, table := range copies {
+		mark := "✓"
+		if table.SourceRows != table.TargetRows {
+			mark = "✗"
+		}
+		fmt.Printf("  %s %-32s %8d → %d\n", mark, table.Table, table.SourceRows, table.TargetRows)
+		total += table.TargetRows
+	}
+	if err != nil {
+		log.Fatalf("Failed to copy database: %v", err)
+	}
+	fmt.Printf("\n✓ Copied %d rows in %d tables\n", total, len(copies))
+}
+
+// buildDSN builds the DSN of a database configuration
+func buildDSN(cfg configs.DatabaseConfig) string {
+	return repository.BuildDSN(cfg.Driver, cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.Database, cfg.SSLMode)
+}
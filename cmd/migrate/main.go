@@ -1,15 +1,62 @@
 package main
 
 import (
+	"context"
+	"database/sql"
+	"flag"
 	"fmt"
 	"log"
+	"os"
+	"strconv"
 
 	"github.com/johnzastrow/actalog/configs"
 	"github.com/johnzastrow/actalog/internal/repository"
 	"github.com/joho/godotenv"
 )
 
+const usage = `Usage: migrate [-dir DIR] COMMAND
+
+Commands:
+  up           Apply all pending migrations
+  down N       Revert the last N applied migrations
+  status       List the migrations and whether each is applied
+  goto V       Apply or revert migrations until V is the newest applied (0 reverts all)
+  create NAME  Create empty up and down files for a new migration, for every dialect
+  verify       Check the applied migrations against the migration files
+
+Flags:
+`
+
 func main() {
+	dir := flag.String("dir", "internal/repository/migrations", "migrations directory, for create")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	args := flag.Args()
+	if len(args) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// create works on the source tree and needs no database
+	if args[0] == "create" {
+		if len(args) != 2 {
+			flag.Usage()
+			os.Exit(2)
+		}
+		paths, err := repository.CreateMigrationFiles(*dir, args[1])
+		if err != nil {
+			log.Fatalf("Failed to create migration: %v", err)
+		}
+		fmt.Println("Created migration files:")
+		for _, path := range paths {
+			fmt.Printf("  %s\n", path)
+		}
+		return
+	}
+
 	// Load .env file
 	if err := godotenv.Load(); err != nil {
 		log.Println("No .env file found")
@@ -32,20 +79,82 @@ func main() {
 		cfg.Database.SSLMode,
 	)
 
-	// Initialize database
-	db, err := repository.InitDatabase(cfg.Database.Driver, dsn)
+	// Open database connection
+	db, err := sql.Open(cfg.Database.Driver, dsn)
 	if err != nil {
-		log.Fatalf("Failed to initialize database: %v", err)
+		log.Fatalf("Failed to open database: %v", err)
 	}
 	defer db.Close()
+	if err := db.Ping(); err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
 
-	fmt.Println("Database connected successfully")
-	fmt.Println("Running migrations...")
+	migrator, err := repository.NewMigrator(db)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
 
-	// Run migrations
-	if err := repository.RunMigrations(db, cfg.Database.Driver); err != nil {
-		log.Fatalf("Failed to run migrations: %v", err)
+	if err := run(context.Background(), migrator, args); err != nil {
+		log.Fatal(err)
 	}
+}
+
+// run carries out a command other than create
+func run(ctx context.Context, migrator *repository.Migrator, args []string) error {
+	switch {
+	case args[0] == "up" && len(args) == 1:
+		if err := migrator.Up(ctx); err != nil {
+			return fmt.Errorf("failed to run migrations: %w", err)
+		}
+		fmt.Println("✓ All migrations applied")
+
+	case args[0] == "down" && len(args) == 2:
+		n, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid number of migrations: %s", args[1])
+		}
+		if err := migrator.Down(ctx, n); err != nil {
+			return fmt.Errorf("failed to revert migrations: %w", err)
+		}
+		fmt.Printf("✓ Reverted %d migration(s)\n", n)
 
-	fmt.Println("✓ All migrations completed successfully")
+	case args[0] == "goto" && len(args) == 2:
+		version, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid version: %s", args[1])
+		}
+		if err := migrator.Goto(ctx, version); err != nil {
+			return fmt.Errorf("failed to migrate to version %d: %w", version, err)
+		}
+		fmt.Printf("✓ Migrated to version %d\n", version)
+
+	case args[0] == "status" && len(args) == 1:
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get migration status: %w", err)
+		}
+		for _, status := range statuses {
+			switch {
+			case status.Unknown:
+				fmt.Printf("  ? %s  applied %s, no migration files\n", status.Migration, status.AppliedAt.Format("2006-01-02 15:04:05"))
+			case status.Changed:
+				fmt.Printf("  ! %s  applied %s, changed since\n", status.Migration, status.AppliedAt.Format("2006-01-02 15:04:05"))
+			case status.Applied:
+				fmt.Printf("  ✓ %s  applied %s\n", status.Migration, status.AppliedAt.Format("2006-01-02 15:04:05"))
+			default:
+				fmt.Printf("    %s  pending\n", status.Migration)
+			}
+		}
+
+	case args[0] == "verify" && len(args) == 1:
+		if err := migrator.Verify(ctx); err != nil {
+			return err
+		}
+		fmt.Println("✓ Applied migrations match the migration files")
+
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	return nil
 }
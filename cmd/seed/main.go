@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/johnzastrow/actalog/configs"
+	"github.com/johnzastrow/actalog/internal/repository"
+	"github.com/johnzastrow/actalog/seeds"
+	"github.com/joho/godotenv"
+)
+
+const usage = `Usage: seed [PACK_DIR...]
+
+Loads the standard movements, WODs and workout templates, then each seed
+pack directory given, in order. A pack has any of movements.csv, wods.csv
+and templates.csv, laid out like the files in seeds/.
+
+Seeds are matched by name: new ones are added and changed standard ones
+updated, but a name already used by a user's own movement or WOD is skipped.
+Nothing is deleted, and each pack is loaded in one transaction.
+`
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+	}
+	flag.Parse()
+
+	// Read the packs before touching the database, so a bad file changes nothing
+	standard, err := repository.LoadSeedPack("standard", seeds.Standard)
+	if err != nil {
+		log.Fatalf("Failed to load standard seed pack: %v", err)
+	}
+	packs := []*repository.SeedPack{standard}
+	for _, dir := range flag.Args() {
+		pack, err := repository.LoadSeedPack(dir, os.DirFS(dir))
+		if err != nil {
+			log.Fatalf("Failed to load seed pack %s: %v", dir, err)
+		}
+		packs = append(packs, pack)
+	}
+
+	// Load .env file
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found")
+	}
+
+	// Load configuration
+	cfg, err := configs.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	// Build DSN
+	dsn := repository.BuildDSN(
+		cfg.Database.Driver,
+		cfg.Database.Host,
+		cfg.Database.Port,
+		cfg.Database.User,
+		cfg.Database.Password,
+		cfg.Database.Database,
+		cfg.Database.SSLMode,
+	)
+
+	// Open database connection
+	db, err := sql.Open(cfg.Database.Driver, dsn)
+	if err != nil {
+		log.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+	if err := db.Ping(); err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	ctx := context.Background()
+	migrator, err := repository.NewMigrator(db)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+	if err := migrator.Up(ctx); err != nil {
+		log.Fatalf("Failed to run migrations: %v", err)
+	}
+
+	seeder := repository.NewSeeder(db)
+	for _, pack := range packs {
+		result, err := seeder.Seed(ctx, pack)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("✓ Seeded %s\n", pack.Name)
+		printCounts("Movements", result.Movements)
+		printCounts("WODs", result.WODs)
+		printCounts("Templates", result.Templates)
+	}
+}
+
+func printCounts(kind string, counts repository.SeedCounts) {
+	fmt.Printf("  %-10s %d added, %d updated, %d unchanged", kind+":", counts.Inserted, counts.Updated, counts.Unchanged)
+	if counts.Skipped > 0 {
+		fmt.Printf(", %d skipped (name used by a user's own)", counts.Skipped)
+	}
+	fmt.Println()
+}
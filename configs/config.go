@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -16,6 +17,10 @@ type Config struct {
 	App      AppConfig
 	Logging  LoggingConfig
 	Email    EmailConfig
+	OIDC     OIDCConfig
+	Security SecurityConfig
+	Backup   BackupConfig
+	Webhook  WebhookConfig
 }
 
 // ServerConfig holds server-related configuration
@@ -36,6 +41,8 @@ type DatabaseConfig struct {
 	Password string
 	Database string
 	SSLMode  string
+
+	QueryTimeout time.Duration // How long an API request's queries may run (0 disables the limit)
 }
 
 // JWTConfig holds JWT authentication configuration
@@ -43,16 +50,25 @@ type JWTConfig struct {
 	SecretKey            string
 	ExpirationTime       time.Duration
 	RefreshTokenDuration time.Duration
-	Issuer               string
+	Issuer               string        // iss claim of issued tokens
+	Algorithm            string        // HS256 (shared secret), RS256 or EdDSA
+	KeyRotationInterval  time.Duration // How often RS256/EdDSA signing keys are replaced (0 disables rotation)
+	KeyEncryptionKey     string        // Encrypts RS256/EdDSA private keys stored in the database
+	SecretVerifyUntil    time.Time     // After switching to RS256/EdDSA, when SecretKey stops verifying older HS256 tokens (zero: straight away)
 }
 
 // AppConfig holds application-specific configuration
 type AppConfig struct {
 	Name              string
-	Environment       string   // development, staging, production
-	LogLevel          string   // debug, info, warn, error
+	Environment       string // development, staging, production
+	LogLevel          string // debug, info, warn, error
 	CORSOrigins       []string
 	AllowRegistration bool // Allow new user registration after first user
+
+	NotificationReminderInterval time.Duration // How often to check for scheduled workouts to remind users about
+
+	WebAuthnRPID    string   // Passkey relying party ID (domain); defaults to the APP_URL host
+	WebAuthnOrigins []string // Origins allowed to use passkeys; defaults to the APP_URL origin
 }
 
 // LoggingConfig holds logging configuration
@@ -66,14 +82,61 @@ type LoggingConfig struct {
 
 // EmailConfig holds email/SMTP configuration
 type EmailConfig struct {
-	SMTPHost              string // SMTP server host
-	SMTPPort              int    // SMTP server port (587 for STARTTLS, 465 for TLS, 25 for plain)
-	SMTPUser              string // SMTP username
-	SMTPPassword          string // SMTP password
-	FromAddress           string // From email address
-	FromName              string // From name
-	Enabled               bool   // Enable email sending
-	RequireVerification   bool   // Require email verification for new users
+	SMTPHost            string        // SMTP server host
+	SMTPPort            int           // SMTP server port (587 for STARTTLS, 465 for TLS, 25 for plain)
+	SMTPUser            string        // SMTP username
+	SMTPPassword        string        // SMTP password
+	FromAddress         string        // From email address
+	FromName            string        // From name
+	Enabled             bool          // Enable email sending
+	RequireVerification bool          // Require email verification for new users
+	OutboxMaxAttempts   int           // Delivery attempts before an email is dead-lettered
+	OutboxBaseBackoff   time.Duration // Delay before the first retry (doubles each attempt)
+	OutboxPollInterval  time.Duration // How often the outbox worker checks for due emails
+	TemplateDir         string        // Optional directory whose templates override the built-in ones
+	DigestEnabled       bool          // Send weekly digests to users who opted in
+	DigestCheckInterval time.Duration // How often the digest scheduler checks for unsent digests
+}
+
+// OIDCConfig holds OpenID Connect single sign-on configuration. Single
+// sign-on is enabled when IssuerURL and ClientID are set.
+type OIDCConfig struct {
+	IssuerURL      string   // e.g. https://accounts.google.com
+	ClientID       string   // OAuth client ID registered with the provider
+	ClientSecret   string   // OAuth client secret
+	RedirectURL    string   // Frontend page the provider redirects back to; defaults to APP_URL + /auth/oidc/callback
+	Scopes         []string // Requested scopes
+	AllowedDomains []string // Email domains allowed to sign in; empty allows any
+}
+
+// SecurityConfig holds brute-force protection settings for login and other
+// unauthenticated endpoints
+type SecurityConfig struct {
+	RateLimitEnabled    bool          // Throttle login, password reset and verification requests
+	RateLimitPerIP      int           // Requests allowed per client IP per window
+	RateLimitPerAccount int           // Requests allowed per email address per window
+	RateLimitWindow     time.Duration // Time for an exhausted limit to fully recover
+	LockoutThreshold    int           // Consecutive failed logins that lock an account (0 disables lockout)
+	LockoutDuration     time.Duration // First lockout; doubles with each further lockout
+	LockoutMaxDuration  time.Duration // Longest a single lockout can last
+	TrustedProxies      []string      // Reverse proxy addresses or CIDRs whose X-Forwarded-For / X-Real-IP headers are believed
+}
+
+// BackupConfig holds settings for backups of a SQLite deployment
+type BackupConfig struct {
+	Dir      string        // Directory backup archives are written to
+	Interval time.Duration // Time between scheduled backups (0 disables them)
+	Retain   int           // Archives to keep; older ones are removed (0 keeps all)
+}
+
+// WebhookConfig holds settings for outgoing webhook deliveries
+type WebhookConfig struct {
+	AllowedNetworks []string // CIDR ranges receivers may use even though they are private or loopback addresses
+}
+
+// Enabled reports whether single sign-on is configured
+func (c OIDCConfig) Enabled() bool {
+	return c.IssuerURL != "" && c.ClientID != ""
 }
 
 // Load loads configuration from environment variables with sensible defaults
@@ -86,20 +149,16 @@ func Load() (*Config, error) {
 			WriteTimeout: getEnvDuration("SERVER_WRITE_TIMEOUT", 15*time.Second),
 			IdleTimeout:  getEnvDuration("SERVER_IDLE_TIMEOUT", 60*time.Second),
 		},
-		Database: DatabaseConfig{
-			Driver:   getEnv("DB_DRIVER", "sqlite3"),
-			Host:     getEnv("DB_HOST", "localhost"),
-			Port:     getEnvInt("DB_PORT", 5432),
-			User:     getEnv("DB_USER", "actalog"),
-			Password: getEnv("DB_PASSWORD", ""),
-			Database: getEnv("DB_NAME", "actalog.db"),
-			SSLMode:  getEnv("DB_SSLMODE", "disable"),
-		},
+		Database: LoadDatabaseConfig(""),
 		JWT: JWTConfig{
 			SecretKey:            getEnv("JWT_SECRET", ""), // Must be set in production
 			ExpirationTime:       getEnvDuration("JWT_EXPIRATION", 24*time.Hour),
 			RefreshTokenDuration: getEnvDuration("JWT_REFRESH_DURATION", 30*24*time.Hour), // 30 days
 			Issuer:               getEnv("JWT_ISSUER", "actalog"),
+			Algorithm:            getEnv("JWT_ALGORITHM", "HS256"),
+			KeyRotationInterval:  getEnvDuration("JWT_KEY_ROTATION_INTERVAL", 30*24*time.Hour),
+			KeyEncryptionKey:     getEnv("JWT_KEY_ENCRYPTION_KEY", ""),
+			SecretVerifyUntil:    getEnvTime("JWT_SECRET_VERIFY_UNTIL", time.Time{}),
 		},
 		App: AppConfig{
 			Name:              "ActaLog",
@@ -107,11 +166,16 @@ func Load() (*Config, error) {
 			LogLevel:          getEnv("LOG_LEVEL", "info"),
 			CORSOrigins:       getEnvSlice("CORS_ORIGINS", []string{"http://localhost:8080", "http://localhost:3000"}),
 			AllowRegistration: getEnvBool("ALLOW_REGISTRATION", true), // Allow by default in development
+
+			NotificationReminderInterval: getEnvDuration("NOTIFICATION_REMINDER_INTERVAL", 15*time.Minute),
+
+			WebAuthnRPID:    getEnv("WEBAUTHN_RP_ID", ""),
+			WebAuthnOrigins: getEnvSlice("WEBAUTHN_ORIGINS", nil),
 		},
 		Logging: LoggingConfig{
 			Level:      getEnv("LOG_LEVEL", "info"),
 			EnableFile: getEnvBool("LOG_FILE_ENABLED", false),
-			FilePath:   getEnv("LOG_FILE_PATH", ""),    // Empty = auto-detect (./logs/actalog.log)
+			FilePath:   getEnv("LOG_FILE_PATH", ""),       // Empty = auto-detect (./logs/actalog.log)
 			MaxSizeMB:  getEnvInt("LOG_MAX_SIZE_MB", 100), // 100MB default
 			MaxBackups: getEnvInt("LOG_MAX_BACKUPS", 3),   // Keep 3 old files
 		},
@@ -122,14 +186,55 @@ func Load() (*Config, error) {
 			SMTPPassword:        getEnv("SMTP_PASSWORD", ""),
 			FromAddress:         getEnv("EMAIL_FROM", ""),
 			FromName:            getEnv("EMAIL_FROM_NAME", "ActaLog"),
-			Enabled:             getEnvBool("EMAIL_ENABLED", false), // Disabled by default
+			Enabled:             getEnvBool("EMAIL_ENABLED", false),              // Disabled by default
 			RequireVerification: getEnvBool("REQUIRE_EMAIL_VERIFICATION", false), // Disabled by default for testing
+			OutboxMaxAttempts:   getEnvInt("EMAIL_OUTBOX_MAX_ATTEMPTS", 8),
+			OutboxBaseBackoff:   getEnvDuration("EMAIL_OUTBOX_BACKOFF", 30*time.Second),
+			OutboxPollInterval:  getEnvDuration("EMAIL_OUTBOX_POLL_INTERVAL", 10*time.Second),
+			TemplateDir:         getEnv("EMAIL_TEMPLATE_DIR", ""),
+			DigestEnabled:       getEnvBool("EMAIL_DIGEST_ENABLED", true),
+			DigestCheckInterval: getEnvDuration("EMAIL_DIGEST_CHECK_INTERVAL", time.Hour),
+		},
+		OIDC: OIDCConfig{
+			IssuerURL:      getEnv("OIDC_ISSUER_URL", ""),
+			ClientID:       getEnv("OIDC_CLIENT_ID", ""),
+			ClientSecret:   getEnv("OIDC_CLIENT_SECRET", ""),
+			RedirectURL:    getEnv("OIDC_REDIRECT_URL", ""),
+			Scopes:         strings.Fields(getEnv("OIDC_SCOPES", "openid email profile")),
+			AllowedDomains: getEnvSlice("OIDC_ALLOWED_DOMAINS", nil),
+		},
+		Security: SecurityConfig{
+			RateLimitEnabled:    getEnvBool("RATE_LIMIT_ENABLED", true),
+			RateLimitPerIP:      getEnvInt("RATE_LIMIT_PER_IP", 20),
+			RateLimitPerAccount: getEnvInt("RATE_LIMIT_PER_ACCOUNT", 5),
+			RateLimitWindow:     getEnvDuration("RATE_LIMIT_WINDOW", time.Minute),
+			LockoutThreshold:    getEnvInt("LOCKOUT_THRESHOLD", 5),
+			LockoutDuration:     getEnvDuration("LOCKOUT_DURATION", 15*time.Minute),
+			LockoutMaxDuration:  getEnvDuration("LOCKOUT_MAX_DURATION", 24*time.Hour),
+			TrustedProxies:      getEnvSlice("TRUSTED_PROXIES", nil),
+		},
+		Backup: BackupConfig{
+			Dir:      getEnv("BACKUP_DIR", "backups"),
+			Interval: getEnvDuration("BACKUP_INTERVAL", 24*time.Hour),
+			Retain:   getEnvInt("BACKUP_RETAIN", 7),
+		},
+		Webhook: WebhookConfig{
+			AllowedNetworks: getEnvSlice("WEBHOOK_ALLOWED_NETWORKS", nil),
 		},
 	}
 
 	// Validate critical configuration
-	if cfg.App.Environment == "production" && cfg.JWT.SecretKey == "" {
-		return nil, fmt.Errorf("JWT_SECRET must be set in production environment")
+	switch cfg.JWT.Algorithm {
+	case "HS256":
+		if cfg.App.Environment == "production" && cfg.JWT.SecretKey == "" {
+			return nil, fmt.Errorf("JWT_SECRET must be set in production environment")
+		}
+	case "RS256", "EdDSA":
+		if cfg.App.Environment == "production" && cfg.JWT.KeyEncryptionKey == "" {
+			return nil, fmt.Errorf("JWT_KEY_ENCRYPTION_KEY must be set in production environment when JWT_ALGORITHM is %s", cfg.JWT.Algorithm)
+		}
+	default:
+		return nil, fmt.Errorf("JWT_ALGORITHM must be HS256, RS256 or EdDSA, got %q", cfg.JWT.Algorithm)
 	}
 
 	return cfg, nil
@@ -137,6 +242,23 @@ func Load() (*Config, error) {
 
 // Helper functions for environment variable parsing
 
+// LoadDatabaseConfig loads a database configuration from the DB_*
+// environment variables, each with prefix before it, so that tools can be
+// given a second database with e.g. TARGET_DB_DRIVER
+func LoadDatabaseConfig(prefix string) DatabaseConfig {
+	return DatabaseConfig{
+		Driver:   getEnv(prefix+"DB_DRIVER", "sqlite3"),
+		Host:     getEnv(prefix+"DB_HOST", "localhost"),
+		Port:     getEnvInt(prefix+"DB_PORT", 5432),
+		User:     getEnv(prefix+"DB_USER", "actalog"),
+		Password: getEnv(prefix+"DB_PASSWORD", ""),
+		Database: getEnv(prefix+"DB_NAME", "actalog.db"),
+		SSLMode:  getEnv(prefix+"DB_SSLMODE", "disable"),
+
+		QueryTimeout: getEnvDuration(prefix+"DB_QUERY_TIMEOUT", 10*time.Second),
+	}
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -162,10 +284,28 @@ func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
 	return defaultValue
 }
 
+// getEnvTime parses an RFC 3339 time or a YYYY-MM-DD date (midnight UTC)
+func getEnvTime(key string, defaultValue time.Time) time.Time {
+	if value := os.Getenv(key); value != "" {
+		for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+			if t, err := time.Parse(layout, value); err == nil {
+				return t
+			}
+		}
+	}
+	return defaultValue
+}
+
 func getEnvSlice(key string, defaultValue []string) []string {
 	if value := os.Getenv(key); value != "" {
 		// Simple comma-separated parsing
-		return []string{value}
+		var values []string
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+		return values
 	}
 	return defaultValue
 }
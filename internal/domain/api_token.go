@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// API token scopes
+const (
+	ScopeWorkoutsRead  = "workouts:read"
+	ScopeWorkoutsWrite = "workouts:write"
+	ScopePRsRead       = "prs:read"
+)
+
+// APITokenScopes lists the scopes a personal API token can be granted
+var APITokenScopes = []string{
+	ScopeWorkoutsRead,
+	ScopeWorkoutsWrite,
+	ScopePRsRead,
+}
+
+// APIToken is a long-lived personal access token for scripts and integrations.
+// Only a hash of the token is stored; the token itself is shown once on creation.
+type APIToken struct {
+	ID         int64      `json:"id"`
+	UserID     int64      `json:"user_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"` // Start of the token, to recognise it in lists
+	TokenHash  string     `json:"-"`
+	Scopes     []string   `json:"scopes"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"` // Nil for tokens that don't expire
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// HasScope reports whether the token was granted a scope
+func (t *APIToken) HasScope(scope string) bool {
+	for _, s := range t.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// APITokenRepository defines the interface for personal API token data access
+type APITokenRepository interface {
+	Create(ctx context.Context, token *APIToken) error
+	GetByHash(ctx context.Context, tokenHash string) (*APIToken, error)
+	ListByUser(ctx context.Context, userID int64) ([]*APIToken, error)
+	RecordUse(ctx context.Context, id int64, usedAt time.Time) error
+	// Delete removes a token. Returns false if the user has no such token.
+	Delete(ctx context.Context, id, userID int64) (bool, error)
+}
@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Audit log actions
+const (
+	AuditLogin               = "login"
+	AuditLoginFailed         = "login_failed"
+	AuditPasswordChanged     = "password_changed"
+	AuditPasswordReset       = "password_reset"
+	AuditPasswordResetForced = "password_reset_forced"
+	AuditRoleChanged         = "role_changed"
+	AuditUserDisabled        = "user_disabled"
+	AuditUserEnabled         = "user_enabled"
+	AuditUserUnlocked        = "user_unlocked"
+	AuditUserDeleted         = "user_deleted"
+	AuditWODRecordUpdated    = "wod_record_updated"
+	AuditWODRecordsDeleted   = "wod_records_deleted"
+	AuditBackupCreated       = "backup_created"
+	AuditBackupDownloaded    = "backup_downloaded"
+)
+
+// Audit log target types
+const (
+	AuditTargetUser      = "user"
+	AuditTargetWODRecord = "user_workout_wod"
+)
+
+// AuditEntry records a security-relevant or admin action. Before and After
+// hold the target's state around a change; events that don't change state,
+// such as logins, put their details in After.
+type AuditEntry struct {
+	ID         int64           `json:"id"`
+	Action     string          `json:"action"`
+	ActorID    *int64          `json:"actor_id,omitempty"` // Nil for anonymous requests, e.g. failed logins
+	TargetType string          `json:"target_type,omitempty"`
+	TargetID   *int64          `json:"target_id,omitempty"`
+	IPAddress  string          `json:"ip_address,omitempty"`
+	RequestID  string          `json:"request_id,omitempty"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// AuditFilter narrows a listing of the audit log. Zero values match everything.
+type AuditFilter struct {
+	Action     string
+	ActorID    *int64
+	TargetType string
+	TargetID   *int64
+	Since      *time.Time
+	Until      *time.Time
+	Limit      int
+	Offset     int
+}
+
+// AuditLogRepository defines the interface for audit log data access
+type AuditLogRepository interface {
+	// Create appends an entry to the audit log
+	Create(ctx context.Context, entry *AuditEntry) error
+
+	// List returns a page of entries matching the filter, newest first, and
+	// the total number of matches
+	List(ctx context.Context, filter AuditFilter) ([]*AuditEntry, int64, error)
+}
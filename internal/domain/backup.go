@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrBackupUnsupported is returned when backing up a database other than
+// SQLite, which has its own dump tools
+var ErrBackupUnsupported = errors.New("backups are only supported for SQLite; use pg_dump or mysqldump")
+
+// What started a backup
+const (
+	BackupTriggerScheduled = "scheduled"
+	BackupTriggerAdmin     = "admin"
+	BackupTriggerCLI       = "cli"
+)
+
+// Backup is a backup archive: a snapshot of the database and the uploaded
+// avatars, with a manifest
+type Backup struct {
+	Name      string    `json:"name"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BackupManifest describes what a backup archive holds and which release
+// made it
+type BackupManifest struct {
+	AppVersion    string    `json:"app_version"`
+	Build         int       `json:"build"`
+	Driver        string    `json:"driver"`
+	SchemaVersion int       `json:"schema_version"` // Newest migration applied to the database
+	Avatars       int       `json:"avatars"`        // Number of avatar files
+	Trigger       string    `json:"trigger"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// DatabaseSnapshotter copies a live database into a file and checks such
+// copies before they are restored
+type DatabaseSnapshotter interface {
+	// Driver returns the database/sql driver name of the database
+	Driver() string
+
+	// Snapshot writes a consistent copy of the database to path, which must
+	// not exist, while it stays in use
+	Snapshot(ctx context.Context, path string) error
+
+	// SchemaVersion returns the newest migration applied to the database
+	SchemaVersion(ctx context.Context) (int, error)
+
+	// Verify checks that the database file at path is intact and that this
+	// release's migrations can run on it
+	Verify(ctx context.Context, path string) error
+}
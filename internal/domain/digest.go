@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// WeeklyDigest summarizes a user's training for one week (Monday 00:00 UTC to
+// the following Monday)
+type WeeklyDigest struct {
+	UserID         int64           `json:"user_id"`
+	WeekStart      time.Time       `json:"week_start"`
+	WeekEnd        time.Time       `json:"week_end"` // Exclusive
+	WorkoutsLogged int             `json:"workouts_logged"`
+	TotalVolume    float64         `json:"total_volume"` // Sum of sets × reps × weight
+	WeightUnit     string          `json:"weight_unit"`
+	PRs            []DigestPR      `json:"prs"`
+	WeekStreak     int             `json:"week_streak"` // Consecutive weeks, ending with this one, with at least one workout
+	Upcoming       []DigestSession `json:"upcoming"`    // Workouts scheduled for the coming week
+}
+
+// DigestPR is a personal record set during the digest week
+type DigestPR struct {
+	Name  string    `json:"name"`
+	Score string    `json:"score"`
+	Date  time.Time `json:"date"`
+}
+
+// DigestSession is a workout scheduled on a future date
+type DigestSession struct {
+	Name string    `json:"name"`
+	Date time.Time `json:"date"`
+}
+
+// DigestRepository records which weekly digests have been sent so each user
+// receives at most one per week
+type DigestRepository interface {
+	// Claim records the digest for a user and week. Returns false if it was already recorded.
+	Claim(ctx context.Context, userID int64, weekStart time.Time) (bool, error)
+
+	// Release removes a claim so the digest can be retried
+	Release(ctx context.Context, userID int64, weekStart time.Time) error
+}
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Outbox email statuses
+const (
+	OutboxStatusPending = "pending" // Waiting to be sent (or retried)
+	OutboxStatusSent    = "sent"    // Delivered to the SMTP server
+	OutboxStatusDead    = "dead"    // Gave up after max attempts (dead letter)
+)
+
+// OutboxEmail is an email queued for background delivery
+type OutboxEmail struct {
+	ID            int64      `json:"id"`
+	Recipients    []string   `json:"recipients"` // RFC 5322 addresses, optionally with display names
+	Subject       string     `json:"subject"`
+	Body          string     `json:"body"`
+	TextBody      string     `json:"text_body,omitempty"` // Plain-text alternative for HTML emails
+	IsHTML        bool       `json:"is_html"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	LastError     *string    `json:"last_error,omitempty"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	SentAt        *time.Time `json:"sent_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// EmailOutboxRepository defines the interface for email outbox data access
+type EmailOutboxRepository interface {
+	// Enqueue stores a new pending email
+	Enqueue(ctx context.Context, email *OutboxEmail) error
+
+	// GetByID retrieves an outbox email by ID
+	GetByID(ctx context.Context, id int64) (*OutboxEmail, error)
+
+	// ListDue retrieves pending emails whose next attempt is due, oldest first
+	ListDue(ctx context.Context, now time.Time, limit int) ([]*OutboxEmail, error)
+
+	// ListByStatus retrieves emails with the given status, newest first
+	ListByStatus(ctx context.Context, status string, limit, offset int) ([]*OutboxEmail, error)
+
+	// CountByStatus returns the number of emails in each status
+	CountByStatus(ctx context.Context) (map[string]int, error)
+
+	// Update updates an outbox email's delivery state
+	Update(ctx context.Context, email *OutboxEmail) error
+}
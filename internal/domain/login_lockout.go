@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// LoginLockout tracks an account's recent failed password logins. The record
+// is removed when the user logs in successfully or an admin unlocks them.
+type LoginLockout struct {
+	UserID         int64      `json:"user_id"`
+	FailedAttempts int        `json:"failed_attempts"` // Failures since the last lockout
+	Lockouts       int        `json:"lockouts"`        // Lockouts since the last successful login; each one lasts longer
+	LockedUntil    *time.Time `json:"locked_until,omitempty"`
+	LastFailureAt  time.Time  `json:"last_failure_at"`
+}
+
+// Locked reports whether the account is locked at the given time
+func (l *LoginLockout) Locked(now time.Time) bool {
+	return l != nil && l.LockedUntil != nil && now.Before(*l.LockedUntil)
+}
+
+// LoginLockoutRepository defines the interface for login lockout data access
+type LoginLockoutRepository interface {
+	// Get retrieves a user's lockout record, or nil if there is none
+	Get(ctx context.Context, userID int64) (*LoginLockout, error)
+
+	// Save creates or replaces a user's lockout record
+	Save(ctx context.Context, lockout *LoginLockout) error
+
+	// Delete removes a user's lockout record. Returns false if there was none.
+	Delete(ctx context.Context, userID int64) (bool, error)
+}
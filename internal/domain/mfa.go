@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// TOTPEnrollment is a user's authenticator app registration. It only protects
+// logins once confirmed, i.e. after the user has entered a first valid code.
+type TOTPEnrollment struct {
+	UserID       int64      `json:"user_id"`
+	Secret       string     `json:"-"`
+	ConfirmedAt  *time.Time `json:"confirmed_at,omitempty"`
+	LastUsedStep int64      `json:"-"` // Time step of the last accepted code, so codes can't be replayed
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// Enabled reports whether the enrollment has been confirmed
+func (e *TOTPEnrollment) Enabled() bool {
+	return e != nil && e.ConfirmedAt != nil
+}
+
+// RecoveryCode is a single-use code that replaces a TOTP code when the user
+// has lost their authenticator. Only a bcrypt hash of the code is stored.
+type RecoveryCode struct {
+	ID        int64      `json:"id"`
+	UserID    int64      `json:"user_id"`
+	CodeHash  string     `json:"-"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// MFARepository defines the interface for two-factor authentication data access
+type MFARepository interface {
+	// GetTOTP retrieves a user's TOTP enrollment, or nil if there is none
+	GetTOTP(ctx context.Context, userID int64) (*TOTPEnrollment, error)
+
+	// SaveTOTP creates or replaces a user's TOTP enrollment
+	SaveTOTP(ctx context.Context, enrollment *TOTPEnrollment) error
+
+	// ConfirmTOTP marks a user's enrollment confirmed
+	ConfirmTOTP(ctx context.Context, userID int64, confirmedAt time.Time) error
+
+	// UseTOTPStep records an accepted code's time step. Returns false if that
+	// step or a later one was already used.
+	UseTOTPStep(ctx context.Context, userID, step int64) (bool, error)
+
+	// DeleteTOTP removes a user's TOTP enrollment and recovery codes
+	DeleteTOTP(ctx context.Context, userID int64) error
+
+	// ReplaceRecoveryCodes discards a user's recovery codes and stores new hashes
+	ReplaceRecoveryCodes(ctx context.Context, userID int64, codeHashes []string) error
+
+	// ListUnusedRecoveryCodes retrieves a user's recovery codes that haven't been used
+	ListUnusedRecoveryCodes(ctx context.Context, userID int64) ([]*RecoveryCode, error)
+
+	// UseRecoveryCode marks a recovery code used. Returns false if it was already used.
+	UseRecoveryCode(ctx context.Context, id int64, usedAt time.Time) (bool, error)
+}
@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"context"
 	"time"
 )
 
@@ -50,15 +51,15 @@ type WorkoutMovement struct {
 
 // MovementRepository defines the interface for movement data access
 type MovementRepository interface {
-	Create(movement *Movement) error
-	GetByID(id int64) (*Movement, error)
-	GetByName(name string) (*Movement, error)
-	ListAll() ([]*Movement, error)
-	ListStandard() ([]*Movement, error)
-	ListByUser(userID int64) ([]*Movement, error)
-	Update(movement *Movement) error
-	Delete(id int64) error
-	Search(query string, limit int) ([]*Movement, error)
+	Create(ctx context.Context, movement *Movement) error
+	GetByID(ctx context.Context, id int64) (*Movement, error)
+	GetByName(ctx context.Context, name string) (*Movement, error)
+	ListAll(ctx context.Context) ([]*Movement, error)
+	ListStandard(ctx context.Context) ([]*Movement, error)
+	ListByUser(ctx context.Context, userID int64) ([]*Movement, error)
+	Update(ctx context.Context, movement *Movement) error
+	Delete(ctx context.Context, id int64) error
+	Search(ctx context.Context, query string, limit int) ([]*Movement, error)
 }
 
 // PersonalRecord represents a user's personal record for a movement
@@ -99,48 +100,48 @@ type UserWorkoutMovement struct {
 
 // WorkoutMovementRepository defines the interface for workout movement data access
 type WorkoutMovementRepository interface {
-	Create(wm *WorkoutMovement) error
-	GetByID(id int64) (*WorkoutMovement, error)
-	GetByWorkoutID(workoutID int64) ([]*WorkoutMovement, error)
-	GetByUserIDAndMovementID(userID, movementID int64, limit int) ([]*WorkoutMovement, error)
-	Update(wm *WorkoutMovement) error
-	Delete(id int64) error
-	DeleteByWorkoutID(workoutID int64) error
+	Create(ctx context.Context, wm *WorkoutMovement) error
+	GetByID(ctx context.Context, id int64) (*WorkoutMovement, error)
+	GetByWorkoutID(ctx context.Context, workoutID int64) ([]*WorkoutMovement, error)
+	GetByUserIDAndMovementID(ctx context.Context, userID, movementID int64, limit int) ([]*WorkoutMovement, error)
+	Update(ctx context.Context, wm *WorkoutMovement) error
+	Delete(ctx context.Context, id int64) error
+	DeleteByWorkoutID(ctx context.Context, workoutID int64) error
 	// PR tracking methods
-	GetPersonalRecords(userID int64) ([]*PersonalRecord, error)
-	GetMaxWeightForMovement(userID, movementID int64) (*float64, error)
-	GetPRMovements(userID int64, limit int) ([]*WorkoutMovement, error)
+	GetPersonalRecords(ctx context.Context, userID int64) ([]*PersonalRecord, error)
+	GetMaxWeightForMovement(ctx context.Context, userID, movementID int64) (*float64, error)
+	GetPRMovements(ctx context.Context, userID int64, limit int) ([]*WorkoutMovement, error)
 }
 
 // UserWorkoutMovementRepository defines the interface for user workout movement performance data
 type UserWorkoutMovementRepository interface {
 	// Create creates a new user workout movement performance record
-	Create(uwm *UserWorkoutMovement) error
+	Create(ctx context.Context, uwm *UserWorkoutMovement) error
 
 	// CreateBatch creates multiple user workout movement records at once
-	CreateBatch(movements []*UserWorkoutMovement) error
+	CreateBatch(ctx context.Context, movements []*UserWorkoutMovement) error
 
 	// GetByID retrieves a user workout movement by ID
-	GetByID(id int64) (*UserWorkoutMovement, error)
+	GetByID(ctx context.Context, id int64) (*UserWorkoutMovement, error)
 
 	// GetByUserWorkoutID retrieves all movements for a specific logged workout
-	GetByUserWorkoutID(userWorkoutID int64) ([]*UserWorkoutMovement, error)
+	GetByUserWorkoutID(ctx context.Context, userWorkoutID int64) ([]*UserWorkoutMovement, error)
 
 	// Update updates an existing user workout movement
-	Update(uwm *UserWorkoutMovement) error
+	Update(ctx context.Context, uwm *UserWorkoutMovement) error
 
 	// Delete deletes a user workout movement
-	Delete(id int64) error
+	Delete(ctx context.Context, id int64) error
 
 	// DeleteByUserWorkoutID deletes all movements for a logged workout
-	DeleteByUserWorkoutID(userWorkoutID int64) error
+	DeleteByUserWorkoutID(ctx context.Context, userWorkoutID int64) error
 
 	// GetMaxWeightForMovement retrieves the maximum weight for a specific movement for a user
-	GetMaxWeightForMovement(userID, movementID int64) (*float64, error)
+	GetMaxWeightForMovement(ctx context.Context, userID, movementID int64) (*float64, error)
 
 	// GetPRMovements retrieves recent PR-flagged movements for a user
-	GetPRMovements(userID int64, limit int) ([]*UserWorkoutMovement, error)
+	GetPRMovements(ctx context.Context, userID int64, limit int) ([]*UserWorkoutMovement, error)
 
 	// UpdatePRFlag updates the is_pr flag for a user workout movement
-	UpdatePRFlag(id int64, isPR bool) error
+	UpdatePRFlag(ctx context.Context, id int64, isPR bool) error
 }
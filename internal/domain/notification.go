@@ -0,0 +1,123 @@
+package domain
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Notification event types
+const (
+	NotificationEventPR              = "pr"
+	NotificationEventCoachComment    = "coach_comment"
+	NotificationEventWorkoutReminder = "workout_reminder"
+)
+
+// Notification delivery channels
+const (
+	NotificationChannelInApp = "in_app"
+	NotificationChannelEmail = "email"
+)
+
+// NotificationChannels selects which channels deliver an event type
+type NotificationChannels struct {
+	InApp bool `json:"in_app"`
+	Email bool `json:"email"`
+}
+
+// NotificationPreferences holds a user's per-event channel choices. It is
+// stored as JSON in user_settings.notification_preferences.
+type NotificationPreferences struct {
+	PR              NotificationChannels `json:"pr"`
+	CoachComment    NotificationChannels `json:"coach_comment"`
+	WorkoutReminder NotificationChannels `json:"workout_reminder"`
+	WeeklyDigest    bool                 `json:"weekly_digest"` // Email a weekly training summary
+}
+
+// DefaultNotificationPreferences returns the preferences for users who haven't
+// chosen any: every event in-app, nothing by email
+func DefaultNotificationPreferences() NotificationPreferences {
+	return NotificationPreferences{
+		PR:              NotificationChannels{InApp: true},
+		CoachComment:    NotificationChannels{InApp: true},
+		WorkoutReminder: NotificationChannels{InApp: true},
+	}
+}
+
+// UnmarshalJSON decodes preferences on top of the defaults, so omitted fields
+// keep their default values. Unknown fields are rejected. For compatibility
+// with older clients the preferences may also be a JSON-encoded string.
+func (p *NotificationPreferences) UnmarshalJSON(data []byte) error {
+	var encoded string
+	if err := json.Unmarshal(data, &encoded); err == nil {
+		if encoded == "" {
+			*p = DefaultNotificationPreferences()
+			return nil
+		}
+		data = []byte(encoded)
+	}
+
+	// plain has the same fields but not this method, avoiding recursion
+	type plain NotificationPreferences
+	prefs := plain(DefaultNotificationPreferences())
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&prefs); err != nil {
+		return fmt.Errorf("invalid notification preferences: %w", err)
+	}
+
+	*p = NotificationPreferences(prefs)
+	return nil
+}
+
+// Enabled reports whether an event type is delivered on a channel
+func (p NotificationPreferences) Enabled(eventType, channel string) bool {
+	var channels NotificationChannels
+	switch eventType {
+	case NotificationEventPR:
+		channels = p.PR
+	case NotificationEventCoachComment:
+		channels = p.CoachComment
+	case NotificationEventWorkoutReminder:
+		channels = p.WorkoutReminder
+	default:
+		return false
+	}
+
+	switch channel {
+	case NotificationChannelInApp:
+		return channels.InApp
+	case NotificationChannelEmail:
+		return channels.Email
+	default:
+		return false
+	}
+}
+
+// Notification is an entry in a user's in-app notification center
+type Notification struct {
+	ID        int64      `json:"id"`
+	UserID    int64      `json:"user_id"`
+	Type      string     `json:"type"` // pr, coach_comment, workout_reminder
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	Link      *string    `json:"link,omitempty"` // App route, e.g. /workouts/12
+	DedupeKey *string    `json:"-"`              // Prevents the same event notifying twice
+	ReadAt    *time.Time `json:"read_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// NotificationRepository defines the interface for notification data access
+type NotificationRepository interface {
+	Create(ctx context.Context, notification *Notification) error
+	GetByDedupeKey(ctx context.Context, userID int64, dedupeKey string) (*Notification, error)
+	ListByUser(ctx context.Context, userID int64, unreadOnly bool, limit, offset int) ([]*Notification, error)
+	CountUnread(ctx context.Context, userID int64) (int, error)
+	// MarkRead marks one of the user's notifications read. Returns false if it doesn't exist.
+	MarkRead(ctx context.Context, id, userID int64, at time.Time) (bool, error)
+	// MarkAllRead marks all of the user's unread notifications read and returns how many changed
+	MarkAllRead(ctx context.Context, userID int64, at time.Time) (int64, error)
+}
@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// UserIdentity links a user to an account at an external OpenID Connect provider
+type UserIdentity struct {
+	ID          int64      `json:"id" db:"id"`
+	UserID      int64      `json:"user_id" db:"user_id"`
+	Issuer      string     `json:"issuer" db:"issuer"`
+	Subject     string     `json:"subject" db:"subject"`
+	Email       string     `json:"email" db:"email"` // Email at the provider when linked
+	LastLoginAt *time.Time `json:"last_login_at,omitempty" db:"last_login_at"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+}
+
+// OIDCLoginState is an outstanding OpenID Connect login, kept until the
+// provider redirects back
+type OIDCLoginState struct {
+	ID           int64     `json:"id" db:"id"`
+	State        string    `json:"state" db:"state"`
+	Nonce        string    `json:"-" db:"nonce"`
+	CodeVerifier string    `json:"-" db:"code_verifier"`
+	ExpiresAt    time.Time `json:"expires_at" db:"expires_at"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
+
+// OIDCRepository defines the interface for external identity data access
+type OIDCRepository interface {
+	GetIdentity(ctx context.Context, issuer, subject string) (*UserIdentity, error)
+	CreateIdentity(ctx context.Context, identity *UserIdentity) error
+	ListIdentitiesByUser(ctx context.Context, userID int64) ([]*UserIdentity, error)
+	RecordIdentityLogin(ctx context.Context, id int64, at time.Time) error
+
+	CreateLoginState(ctx context.Context, state *OIDCLoginState) error
+	// ConsumeLoginState deletes and returns an unexpired login state, or nil
+	// if there is none. Each state can be consumed once.
+	ConsumeLoginState(ctx context.Context, state string, now time.Time) (*OIDCLoginState, error)
+	DeleteExpiredLoginStates(ctx context.Context, before time.Time) error
+}
@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Passkey challenge purposes
+const (
+	PasskeyChallengeRegistration   = "registration"
+	PasskeyChallengeAuthentication = "authentication"
+)
+
+// Passkey is a WebAuthn credential registered to a user
+type Passkey struct {
+	ID           int64      `json:"id" db:"id"`
+	UserID       int64      `json:"user_id" db:"user_id"`
+	CredentialID string     `json:"credential_id" db:"credential_id"` // base64url
+	PublicKey    string     `json:"-" db:"public_key"`                // base64url COSE key
+	SignCount    uint32     `json:"-" db:"sign_count"`
+	Transports   []string   `json:"transports,omitempty" db:"transports"`
+	Name         string     `json:"name" db:"name"`
+	LastUsedAt   *time.Time `json:"last_used_at,omitempty" db:"last_used_at"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// PasskeyChallenge is an outstanding WebAuthn challenge. UserID is nil for
+// logins that don't name an account (discoverable passkeys).
+type PasskeyChallenge struct {
+	ID        int64     `json:"id" db:"id"`
+	Challenge string    `json:"challenge" db:"challenge"`
+	Purpose   string    `json:"purpose" db:"purpose"`
+	UserID    *int64    `json:"user_id,omitempty" db:"user_id"`
+	ExpiresAt time.Time `json:"expires_at" db:"expires_at"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// PasskeyRepository defines the interface for passkey data access
+type PasskeyRepository interface {
+	Create(ctx context.Context, passkey *Passkey) error
+	GetByCredentialID(ctx context.Context, credentialID string) (*Passkey, error)
+	ListByUser(ctx context.Context, userID int64) ([]*Passkey, error)
+	Rename(ctx context.Context, id, userID int64, name string) (bool, error)
+	// RecordUse stores the new signature counter and last-used time
+	RecordUse(ctx context.Context, id int64, signCount uint32, usedAt time.Time) error
+	Delete(ctx context.Context, id, userID int64) (bool, error)
+
+	CreateChallenge(ctx context.Context, challenge *PasskeyChallenge) error
+	// ConsumeChallenge deletes and returns an unexpired challenge for the
+	// purpose, or nil if there is none. Each challenge can be consumed once.
+	ConsumeChallenge(ctx context.Context, challenge, purpose string, now time.Time) (*PasskeyChallenge, error)
+	DeleteExpiredChallenges(ctx context.Context, before time.Time) error
+}
@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// What a search result is
+const (
+	SearchTypeMovement = "movement"
+	SearchTypeWOD      = "wod"
+	SearchTypeWorkout  = "workout" // One of the user's logged workouts
+)
+
+// SearchResult is a movement, WOD or logged workout matching a full-text
+// search, with the matches highlighted
+type SearchResult struct {
+	Type      string      `json:"type"`
+	ID        int64       `json:"id"`
+	Name      string      `json:"name"`
+	Category  string      `json:"category,omitempty"` // Movement or WOD type
+	Date      *time.Time  `json:"date,omitempty"`     // When a workout was done
+	Highlight string      `json:"highlight"`          // Name as HTML, with matches in <mark>
+	Snippet   string      `json:"snippet,omitempty"`  // Excerpt of the description or notes as HTML, with matches in <mark>
+	Rank      float64     `json:"rank"`               // Higher is more relevant
+	Data      interface{} `json:"data,omitempty"`     // The movement or WOD
+}
+
+// SearchRepository searches movements, WODs and logged workouts by their
+// text. Only what the user may see is returned: standard movements and
+// WODs, and their own.
+type SearchRepository interface {
+	Search(ctx context.Context, userID int64, query string, types []string, limit int) ([]*SearchResult, error)
+}
@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// SigningKey is a stored key pair that signs access tokens. A key signs from
+// ActivatesAt until the next key activates, and is published for verification
+// from the moment it's created so verifiers learn it before it's used.
+type SigningKey struct {
+	ID          int64     `json:"id"`
+	KeyID       string    `json:"kid"` // Sent in the token's kid header
+	Algorithm   string    `json:"algorithm"`
+	PrivateKey  string    `json:"-"` // PKCS #8 PEM, encrypted when a key encryption key is configured
+	CreatedAt   time.Time `json:"created_at"`
+	ActivatesAt time.Time `json:"activates_at"`
+}
+
+// SigningKeyRepository defines the interface for token signing key data access
+type SigningKeyRepository interface {
+	// Create stores a new signing key
+	Create(ctx context.Context, key *SigningKey) error
+
+	// List retrieves all signing keys, oldest activation first
+	List(ctx context.Context) ([]*SigningKey, error)
+
+	// UpdatePrivateKey replaces the stored private key, e.g. to encrypt it
+	UpdatePrivateKey(ctx context.Context, id int64, privateKey string) error
+
+	// Delete removes a signing key that no longer verifies any valid token
+	Delete(ctx context.Context, id int64) error
+}
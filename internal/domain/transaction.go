@@ -0,0 +1,12 @@
+package domain
+
+import "context"
+
+// Transactor runs several repository calls as one unit of work. Repository
+// methods called with the context passed to fn run in the transaction.
+type Transactor interface {
+	// WithinTransaction calls fn in a transaction, committing it if fn
+	// returns nil and rolling it back otherwise. Calls nested in fn join the
+	// transaction already in progress.
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
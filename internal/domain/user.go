@@ -2,9 +2,13 @@
 package domain
 
 import (
+	"context"
 	"time"
 )
 
+// DefaultLocale is the locale assigned to users who haven't chosen one
+const DefaultLocale = "en"
+
 // User represents a user in the system
 type User struct {
 	ID                          int64      `json:"id" db:"id"`
@@ -14,6 +18,7 @@ type User struct {
 	ProfileImage                *string    `json:"profile_image,omitempty" db:"profile_image"`
 	Birthday                    *time.Time `json:"birthday,omitempty" db:"birthday"`
 	Role                        string     `json:"role" db:"role"` // user, admin
+	Locale                      string     `json:"locale" db:"locale"` // BCP 47 tag used for emails, e.g. "en", "es"
 	EmailVerified               bool       `json:"email_verified" db:"email_verified"`
 	EmailVerifiedAt             *time.Time `json:"email_verified_at,omitempty" db:"email_verified_at"`
 	VerificationToken           *string    `json:"-" db:"verification_token"` // Never serialize verification token
@@ -23,40 +28,79 @@ type User struct {
 	CreatedAt                   time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt                   time.Time  `json:"updated_at" db:"updated_at"`
 	LastLoginAt                 *time.Time `json:"last_login_at,omitempty" db:"last_login_at"`
+	Disabled                    bool       `json:"disabled" db:"disabled"` // Disabled accounts can't log in or use existing tokens
+}
+
+// UserFilter narrows an admin listing of users
+type UserFilter struct {
+	Query    string // Case-insensitive match on name or email
+	Role     string
+	Disabled *bool
+	Limit    int
+	Offset   int
 }
 
-// RefreshToken represents a refresh token for "Remember Me" functionality
+// RefreshToken represents a refresh token for "Remember Me" functionality.
+// Refresh tokens are rotated on every use; the tokens issued for one login
+// form a family, identified by the ID of the first token.
 type RefreshToken struct {
 	ID         int64      `json:"id" db:"id"`
 	UserID     int64      `json:"user_id" db:"user_id"`
+	FamilyID   int64      `json:"family_id" db:"family_id"`
 	Token      string     `json:"token" db:"token"`
 	ExpiresAt  time.Time  `json:"expires_at" db:"expires_at"`
 	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
 	RevokedAt  *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
 	DeviceInfo string     `json:"device_info,omitempty" db:"device_info"`
+	IPAddress  string     `json:"ip_address,omitempty" db:"ip_address"`
+	SignedInAt time.Time  `json:"signed_in_at" db:"signed_in_at"` // When the family's first token was issued
+}
+
+// Session is a remembered login on one device: the active token of a refresh
+// token family, as shown to the user
+type Session struct {
+	ID         int64     `json:"id"` // Refresh token family ID
+	DeviceInfo string    `json:"device_info"`
+	IPAddress  string    `json:"ip_address"`
+	SignedInAt time.Time `json:"signed_in_at"`
+	LastUsedAt time.Time `json:"last_used_at"` // When the token was last rotated
+	ExpiresAt  time.Time `json:"expires_at"`
 }
 
 // UserRepository defines the interface for user data access
 type UserRepository interface {
-	Create(user *User) error
-	GetByID(id int64) (*User, error)
-	GetByEmail(email string) (*User, error)
-	GetByResetToken(token string) (*User, error)
-	GetByVerificationToken(token string) (*User, error)
-	Update(user *User) error
-	UpdatePassword(userID int64, hashedPassword string) error
-	Delete(id int64) error
-	List(limit, offset int) ([]*User, error)
-	Count() (int64, error)
+	Create(ctx context.Context, user *User) error
+	GetByID(ctx context.Context, id int64) (*User, error)
+	GetByEmail(ctx context.Context, email string) (*User, error)
+	GetByResetToken(ctx context.Context, token string) (*User, error)
+	GetByVerificationToken(ctx context.Context, token string) (*User, error)
+	Update(ctx context.Context, user *User) error
+	UpdatePassword(ctx context.Context, userID int64, hashedPassword string) error
+	Delete(ctx context.Context, id int64) error
+	List(ctx context.Context, limit, offset int) ([]*User, error)
+	Count(ctx context.Context) (int64, error)
+	// Search returns a page of users matching the filter, newest first, and
+	// the total number of matches
+	Search(ctx context.Context, filter UserFilter) ([]*User, int64, error)
 }
 
 // RefreshTokenRepository defines the interface for refresh token data access
 type RefreshTokenRepository interface {
-	Create(token *RefreshToken) error
-	GetByToken(token string) (*RefreshToken, error)
-	GetByUserID(userID int64) ([]*RefreshToken, error)
-	Revoke(tokenID int64) error
-	RevokeAllForUser(userID int64) error
-	DeleteExpired() error
-	Delete(tokenID int64) error
+	// Create stores a token. A token without a FamilyID starts a new family.
+	Create(ctx context.Context, token *RefreshToken) error
+	GetByToken(ctx context.Context, token string) (*RefreshToken, error)
+	// GetByTokenIncludingRevoked also returns revoked and expired tokens,
+	// so that replayed tokens can be detected
+	GetByTokenIncludingRevoked(ctx context.Context, token string) (*RefreshToken, error)
+	GetByUserID(ctx context.Context, userID int64) ([]*RefreshToken, error)
+	// Rotate revokes old and stores next in its place. Returns false, storing
+	// nothing, if old was already revoked.
+	Rotate(ctx context.Context, old, next *RefreshToken) (bool, error)
+	Revoke(ctx context.Context, tokenID int64) error
+	// RevokeFamily revokes a user's tokens in a family. Returns false if
+	// none were active.
+	RevokeFamily(ctx context.Context, familyID, userID int64) (bool, error)
+	RevokeAllForUser(ctx context.Context, userID int64) error
+	DeleteExpired(ctx context.Context) error
+	Delete(ctx context.Context, tokenID int64) error
 }
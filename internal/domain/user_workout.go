@@ -1,6 +1,10 @@
 package domain
 
-import "time"
+import (
+	"context"
+	"errors"
+	"time"
+)
 
 // UserWorkout represents a user's logged instance of a workout template or ad-hoc workout
 // This is the junction table between users and workouts, with the date and user-specific data
@@ -28,32 +32,71 @@ type UserWorkoutWithDetails struct {
 	PerformanceWODs      []*UserWorkoutWOD      `json:"performance_wods,omitempty"`      // Actual performance data for WODs
 }
 
+// Orders for a listing of logged workouts
+const (
+	WorkoutSortDateDesc = "date_desc" // Most recent first (the default)
+	WorkoutSortDateAsc  = "date_asc"
+	WorkoutSortNameAsc  = "name_asc"
+	WorkoutSortNameDesc = "name_desc"
+)
+
+// ErrInvalidCursor is returned for a page cursor that is malformed or was
+// issued for a different sort order
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// WorkoutFilter narrows and orders a listing of a user's logged workouts.
+// Zero values match everything.
+type WorkoutFilter struct {
+	StartDate   *time.Time // Dates are inclusive and compared by day
+	EndDate     *time.Time
+	MovementID  *int64 // Performed in the workout, or part of its template
+	WODID       *int64 // Performed in the workout, or part of its template
+	WorkoutType string
+	HasPR       *bool  // Whether any movement or WOD performed in it was a PR
+	TemplateID  *int64
+	Query       string // Case-insensitive match on the workout's name or notes
+	Sort        string // One of the WorkoutSort constants, WorkoutSortDateDesc if empty
+	Cursor      string // The previous page's NextCursor, empty for the first page
+	Offset      int    // Workouts to skip, for clients that page without cursors
+	Limit       int    // Zero for every matching workout, in one page
+}
+
+// WorkoutPage is one page of a listing of logged workouts
+type WorkoutPage struct {
+	Workouts   []*UserWorkoutWithDetails `json:"workouts"`
+	NextCursor string                    `json:"next_cursor,omitempty"` // Empty on the last page
+}
+
 // UserWorkoutRepository defines the interface for user workout data access
 type UserWorkoutRepository interface {
 	// Create creates a new user workout (logs a workout instance)
-	Create(userWorkout *UserWorkout) error
+	Create(ctx context.Context, userWorkout *UserWorkout) error
 
 	// GetByID retrieves a user workout by ID
-	GetByID(id int64) (*UserWorkout, error)
+	GetByID(ctx context.Context, id int64) (*UserWorkout, error)
 
 	// GetByIDWithDetails retrieves a user workout with full details (movements, WODs)
-	GetByIDWithDetails(id int64, userID int64) (*UserWorkoutWithDetails, error)
+	GetByIDWithDetails(ctx context.Context, id int64, userID int64) (*UserWorkoutWithDetails, error)
 
 	// ListByUser retrieves all workouts logged by a specific user
-	ListByUser(userID int64, limit, offset int) ([]*UserWorkout, error)
+	ListByUser(ctx context.Context, userID int64, limit, offset int) ([]*UserWorkout, error)
 
-	// ListByUserWithDetails retrieves all workouts logged by a user with details
-	ListByUserWithDetails(userID int64, limit, offset int) ([]*UserWorkoutWithDetails, error)
+	// ListWithDetails retrieves a page of the workouts logged by a user that
+	// match the filter, with details
+	ListWithDetails(ctx context.Context, userID int64, filter WorkoutFilter) (*WorkoutPage, error)
 
 	// ListByUserAndDateRange retrieves workouts within a date range
-	ListByUserAndDateRange(userID int64, startDate, endDate time.Time) ([]*UserWorkout, error)
+	ListByUserAndDateRange(ctx context.Context, userID int64, startDate, endDate time.Time) ([]*UserWorkout, error)
+
+	// ListByDateRange retrieves all users' workouts within a date range
+	ListByDateRange(ctx context.Context, startDate, endDate time.Time) ([]*UserWorkout, error)
 
 	// Update updates an existing user workout
-	Update(userWorkout *UserWorkout) error
+	Update(ctx context.Context, userWorkout *UserWorkout) error
 
 	// Delete deletes a user workout
-	Delete(id int64, userID int64) error
+	Delete(ctx context.Context, id int64, userID int64) error
 
 	// GetByUserWorkoutDate checks if a user has already logged a specific workout on a date
-	GetByUserWorkoutDate(userID, workoutID int64, date time.Time) (*UserWorkout, error)
+	GetByUserWorkoutDate(ctx context.Context, userID, workoutID int64, date time.Time) (*UserWorkout, error)
 }
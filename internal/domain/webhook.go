@@ -0,0 +1,99 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Webhook event types
+const (
+	WebhookEventWorkoutLogged  = "workout.logged"
+	WebhookEventWorkoutUpdated = "workout.updated"
+	WebhookEventWorkoutDeleted = "workout.deleted"
+	WebhookEventPRSet          = "pr.set"
+	WebhookEventTest           = "webhook.test"
+)
+
+// WebhookEvents lists the event types a webhook can subscribe to
+var WebhookEvents = []string{
+	WebhookEventWorkoutLogged,
+	WebhookEventWorkoutUpdated,
+	WebhookEventWorkoutDeleted,
+	WebhookEventPRSet,
+}
+
+// Webhook represents an outgoing webhook endpoint registered by a user
+type Webhook struct {
+	ID          int64     `json:"id"`
+	UserID      int64     `json:"user_id"`
+	URL         string    `json:"url"`
+	Secret      string    `json:"-"`         // HMAC-SHA256 signing secret, only returned on creation
+	Events      []string  `json:"events"`    // Subscribed event types
+	AllUsers    bool      `json:"all_users"` // Admin-only: receive events for every user
+	Description *string   `json:"description,omitempty"`
+	IsActive    bool      `json:"is_active"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Subscribes reports whether the webhook should receive the given event type
+func (w *Webhook) Subscribes(event string) bool {
+	if event == WebhookEventTest {
+		return true
+	}
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery records a single delivery of an event to a webhook, including retries
+type WebhookDelivery struct {
+	ID            int64      `json:"id"`
+	WebhookID     int64      `json:"webhook_id"`
+	EventType     string     `json:"event_type"`
+	Payload       string     `json:"payload"`
+	Attempts      int        `json:"attempts"`
+	StatusCode    *int       `json:"status_code,omitempty"`
+	Error         *string    `json:"error,omitempty"`
+	Success       bool       `json:"success"`
+	CreatedAt     time.Time  `json:"created_at"`
+	DeliveredAt   *time.Time `json:"delivered_at,omitempty"`
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty"` // When the next attempt is due; nil once delivered or given up
+}
+
+// WebhookRepository defines the interface for webhook data access
+type WebhookRepository interface {
+	// Create creates a new webhook
+	Create(ctx context.Context, webhook *Webhook) error
+
+	// GetByID retrieves a webhook by ID
+	GetByID(ctx context.Context, id int64) (*Webhook, error)
+
+	// ListByUser retrieves all webhooks registered by a user
+	ListByUser(ctx context.Context, userID int64) ([]*Webhook, error)
+
+	// ListActiveForUser retrieves active webhooks that receive events for a user
+	// (the user's own webhooks plus admin webhooks registered for all users)
+	ListActiveForUser(ctx context.Context, userID int64) ([]*Webhook, error)
+
+	// Update updates an existing webhook
+	Update(ctx context.Context, webhook *Webhook) error
+
+	// Delete deletes a webhook and its delivery log
+	Delete(ctx context.Context, id int64) error
+
+	// CreateDelivery records a new delivery
+	CreateDelivery(ctx context.Context, delivery *WebhookDelivery) error
+
+	// UpdateDelivery updates a delivery after an attempt
+	UpdateDelivery(ctx context.Context, delivery *WebhookDelivery) error
+
+	// ListDeliveries retrieves the delivery log for a webhook, newest first
+	ListDeliveries(ctx context.Context, webhookID int64, limit, offset int) ([]*WebhookDelivery, error)
+
+	// ListDueDeliveries retrieves deliveries whose next attempt is due, oldest first
+	ListDueDeliveries(ctx context.Context, now time.Time, limit int) ([]*WebhookDelivery, error)
+}
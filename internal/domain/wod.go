@@ -1,6 +1,9 @@
 package domain
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 // WOD represents a Workout of the Day (CrossFit benchmark workout)
 // WODs are predefined workouts like "Fran", "Murph", "Helen", etc.
@@ -49,65 +52,65 @@ type UserWorkoutWOD struct {
 // WODRepository defines the interface for WOD data access
 type WODRepository interface {
 	// Create creates a new custom WOD
-	Create(wod *WOD) error
+	Create(ctx context.Context, wod *WOD) error
 
 	// GetByID retrieves a WOD by ID
-	GetByID(id int64) (*WOD, error)
+	GetByID(ctx context.Context, id int64) (*WOD, error)
 
 	// GetByName retrieves a WOD by name
-	GetByName(name string) (*WOD, error)
+	GetByName(ctx context.Context, name string) (*WOD, error)
 
 	// List retrieves all WODs with optional filtering and pagination
-	List(filters map[string]interface{}, limit, offset int) ([]*WOD, error)
+	List(ctx context.Context, filters map[string]interface{}, limit, offset int) ([]*WOD, error)
 
 	// ListStandard retrieves all standard (pre-seeded) WODs with pagination
-	ListStandard(limit, offset int) ([]*WOD, error)
+	ListStandard(ctx context.Context, limit, offset int) ([]*WOD, error)
 
 	// ListByUser retrieves all custom WODs created by a specific user with pagination
-	ListByUser(userID int64, limit, offset int) ([]*WOD, error)
+	ListByUser(ctx context.Context, userID int64, limit, offset int) ([]*WOD, error)
 
 	// Update updates an existing WOD (only for user-created WODs)
-	Update(wod *WOD) error
+	Update(ctx context.Context, wod *WOD) error
 
 	// Delete deletes a WOD (only for user-created WODs)
-	Delete(id int64) error
+	Delete(ctx context.Context, id int64) error
 
 	// Search searches WODs by name (partial match) with limit
-	Search(query string, limit int) ([]*WOD, error)
+	Search(ctx context.Context, query string, limit int) ([]*WOD, error)
 }
 
 // UserWorkoutWODRepository defines the interface for user workout WOD performance data
 type UserWorkoutWODRepository interface {
 	// Create creates a new user workout WOD performance record
-	Create(uww *UserWorkoutWOD) error
+	Create(ctx context.Context, uww *UserWorkoutWOD) error
 
 	// CreateBatch creates multiple user workout WOD records at once
-	CreateBatch(wods []*UserWorkoutWOD) error
+	CreateBatch(ctx context.Context, wods []*UserWorkoutWOD) error
 
 	// GetByID retrieves a user workout WOD by ID
-	GetByID(id int64) (*UserWorkoutWOD, error)
+	GetByID(ctx context.Context, id int64) (*UserWorkoutWOD, error)
 
 	// GetByUserWorkoutID retrieves all WODs for a specific logged workout
-	GetByUserWorkoutID(userWorkoutID int64) ([]*UserWorkoutWOD, error)
+	GetByUserWorkoutID(ctx context.Context, userWorkoutID int64) ([]*UserWorkoutWOD, error)
 
 	// Update updates an existing user workout WOD
-	Update(uww *UserWorkoutWOD) error
+	Update(ctx context.Context, uww *UserWorkoutWOD) error
 
 	// Delete deletes a user workout WOD
-	Delete(id int64) error
+	Delete(ctx context.Context, id int64) error
 
 	// DeleteByUserWorkoutID deletes all WODs for a logged workout
-	DeleteByUserWorkoutID(userWorkoutID int64) error
+	DeleteByUserWorkoutID(ctx context.Context, userWorkoutID int64) error
 
 	// GetBestTimeForWOD retrieves the fastest time for a specific WOD for a user
-	GetBestTimeForWOD(userID, wodID int64) (*int, error)
+	GetBestTimeForWOD(ctx context.Context, userID, wodID int64) (*int, error)
 
 	// GetBestRoundsRepsForWOD retrieves the best rounds+reps for a specific WOD for a user
-	GetBestRoundsRepsForWOD(userID, wodID int64) (rounds *int, reps *int, err error)
+	GetBestRoundsRepsForWOD(ctx context.Context, userID, wodID int64) (rounds *int, reps *int, err error)
 
 	// GetPRWODs retrieves recent PR-flagged WODs for a user
-	GetPRWODs(userID int64, limit int) ([]*UserWorkoutWOD, error)
+	GetPRWODs(ctx context.Context, userID int64, limit int) ([]*UserWorkoutWOD, error)
 
 	// UpdatePRFlag updates the is_pr flag for a user workout WOD
-	UpdatePRFlag(id int64, isPR bool) error
+	UpdatePRFlag(ctx context.Context, id int64, isPR bool) error
 }
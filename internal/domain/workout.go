@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"context"
 	"time"
 )
 
@@ -30,35 +31,35 @@ type WorkoutWithUsageStats struct {
 // WorkoutRepository defines the interface for workout template data access
 type WorkoutRepository interface {
 	// Create creates a new workout template
-	Create(workout *Workout) error
+	Create(ctx context.Context, workout *Workout) error
 
 	// GetByID retrieves a workout template by ID
-	GetByID(id int64) (*Workout, error)
+	GetByID(ctx context.Context, id int64) (*Workout, error)
 
 	// GetByIDWithDetails retrieves a workout with movements and WODs
-	GetByIDWithDetails(id int64) (*Workout, error)
+	GetByIDWithDetails(ctx context.Context, id int64) (*Workout, error)
 
 	// List retrieves all workout templates with optional filtering
-	List(filters map[string]interface{}, limit, offset int) ([]*Workout, error)
+	List(ctx context.Context, filters map[string]interface{}, limit, offset int) ([]*Workout, error)
 
 	// ListByUser retrieves all workout templates created by a specific user
-	ListByUser(userID int64, limit, offset int) ([]*Workout, error)
+	ListByUser(ctx context.Context, userID int64, limit, offset int) ([]*Workout, error)
 
 	// ListStandard retrieves all standard (system) workout templates
-	ListStandard(limit, offset int) ([]*Workout, error)
+	ListStandard(ctx context.Context, limit, offset int) ([]*Workout, error)
 
 	// Update updates an existing workout template
-	Update(workout *Workout) error
+	Update(ctx context.Context, workout *Workout) error
 
 	// Delete deletes a workout template
-	Delete(id int64) error
+	Delete(ctx context.Context, id int64) error
 
 	// Search searches workout templates by name
-	Search(query string, limit int) ([]*Workout, error)
+	Search(ctx context.Context, query string, limit int) ([]*Workout, error)
 
 	// Count counts total workout templates (optionally filtered by user)
-	Count(userID *int64) (int64, error)
+	Count(ctx context.Context, userID *int64) (int64, error)
 
 	// GetUsageStats gets usage statistics for a template
-	GetUsageStats(workoutID int64) (*WorkoutWithUsageStats, error)
+	GetUsageStats(ctx context.Context, workoutID int64) (*WorkoutWithUsageStats, error)
 }
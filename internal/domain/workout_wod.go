@@ -1,6 +1,9 @@
 package domain
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 // WorkoutWOD represents the junction between a workout template and a WOD
 // A workout template can contain multiple WODs
@@ -29,26 +32,26 @@ type WorkoutWODWithDetails struct {
 // WorkoutWODRepository defines the interface for workout-WOD junction data access
 type WorkoutWODRepository interface {
 	// Create creates a new workout-WOD association
-	Create(workoutWOD *WorkoutWOD) error
+	Create(ctx context.Context, workoutWOD *WorkoutWOD) error
 
 	// GetByID retrieves a workout-WOD by ID
-	GetByID(id int64) (*WorkoutWOD, error)
+	GetByID(ctx context.Context, id int64) (*WorkoutWOD, error)
 
 	// ListByWorkout retrieves all WODs associated with a workout template
-	ListByWorkout(workoutID int64) ([]*WorkoutWOD, error)
+	ListByWorkout(ctx context.Context, workoutID int64) ([]*WorkoutWOD, error)
 
 	// ListByWorkoutWithDetails retrieves WODs with full WOD details
-	ListByWorkoutWithDetails(workoutID int64) ([]*WorkoutWODWithDetails, error)
+	ListByWorkoutWithDetails(ctx context.Context, workoutID int64) ([]*WorkoutWODWithDetails, error)
 
 	// Update updates an existing workout-WOD association
-	Update(workoutWOD *WorkoutWOD) error
+	Update(ctx context.Context, workoutWOD *WorkoutWOD) error
 
 	// Delete deletes a workout-WOD association
-	Delete(id int64) error
+	Delete(ctx context.Context, id int64) error
 
 	// DeleteByWorkout deletes all WOD associations for a workout
-	DeleteByWorkout(workoutID int64) error
+	DeleteByWorkout(ctx context.Context, workoutID int64) error
 
 	// TogglePR toggles the PR flag for a workout-WOD
-	TogglePR(id int64) error
+	TogglePR(ctx context.Context, id int64) error
 }
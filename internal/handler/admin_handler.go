@@ -9,6 +9,7 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/johnzastrow/actalog/internal/domain"
+	"github.com/johnzastrow/actalog/internal/service"
 	"github.com/johnzastrow/actalog/pkg/logger"
 )
 
@@ -18,6 +19,7 @@ type AdminHandler struct {
 	userWorkoutWODRepo domain.UserWorkoutWODRepository
 	wodRepo            domain.WODRepository
 	userRepo           domain.UserRepository
+	auditService       *service.AuditService
 	logger             *logger.Logger
 }
 
@@ -27,6 +29,7 @@ func NewAdminHandler(
 	userWorkoutWODRepo domain.UserWorkoutWODRepository,
 	wodRepo domain.WODRepository,
 	userRepo domain.UserRepository,
+	auditService *service.AuditService,
 	logger *logger.Logger,
 ) *AdminHandler {
 	return &AdminHandler{
@@ -34,6 +37,7 @@ func NewAdminHandler(
 		userWorkoutWODRepo: userWorkoutWODRepo,
 		wodRepo:            wodRepo,
 		userRepo:           userRepo,
+		auditService:       auditService,
 		logger:             logger,
 	}
 }
@@ -68,9 +72,9 @@ func (h *AdminHandler) DetectWODScoreTypeMismatches(w http.ResponseWriter, r *ht
 		JOIN users u ON uw.user_id = u.id
 		ORDER BY uw.workout_date DESC`
 
-	rows, err := h.db.Query(query)
+	rows, err := h.db.QueryContext(r.Context(), query)
 	if err != nil {
-		h.logger.Error("Failed to query WOD records", "error", err)
+		h.logger.Error("Failed to query WOD records error=%v", err)
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(map[string]string{"message": "Failed to query WOD records"})
 		return
@@ -95,7 +99,7 @@ func (h *AdminHandler) DetectWODScoreTypeMismatches(w http.ResponseWriter, r *ht
 
 		err := rows.Scan(&id, &wodID, &timeSeconds, &rounds, &reps, &weight, &wodName, &scoreType, &userEmail, &workoutDate)
 		if err != nil {
-			h.logger.Error("Failed to scan WOD record", "error", err)
+			h.logger.Error("Failed to scan WOD record error=%v", err)
 			continue
 		}
 
@@ -144,7 +148,7 @@ func (h *AdminHandler) DetectWODScoreTypeMismatches(w http.ResponseWriter, r *ht
 	}
 
 	if err := rows.Err(); err != nil {
-		h.logger.Error("Error iterating WOD records", "error", err)
+		h.logger.Error("Error iterating WOD records error=%v", err)
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(map[string]string{"message": "Error processing WOD records"})
 		return
@@ -166,16 +170,16 @@ func (h *AdminHandler) FixWODScoreTypeMismatches(w http.ResponseWriter, r *http.
 		FROM user_workout_wods uww
 		JOIN wods w ON uww.wod_id = w.id`
 
-	rows, err := h.db.Query(query)
+	rows, err := h.db.QueryContext(r.Context(), query)
 	if err != nil {
-		h.logger.Error("Failed to query WOD records", "error", err)
+		h.logger.Error("Failed to query WOD records error=%v", err)
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(map[string]string{"message": "Failed to query WOD records"})
 		return
 	}
 	defer rows.Close()
 
-	var idsToDelete []int64
+	var toDelete []*domain.UserWorkoutWOD
 
 	for rows.Next() {
 		var (
@@ -190,7 +194,7 @@ func (h *AdminHandler) FixWODScoreTypeMismatches(w http.ResponseWriter, r *http.
 
 		err := rows.Scan(&id, &wodID, &timeSeconds, &rounds, &reps, &weight, &scoreType)
 		if err != nil {
-			h.logger.Error("Failed to scan WOD record", "error", err)
+			h.logger.Error("Failed to scan WOD record error=%v", err)
 			continue
 		}
 
@@ -212,34 +216,49 @@ func (h *AdminHandler) FixWODScoreTypeMismatches(w http.ResponseWriter, r *http.
 		}
 
 		if isMismatch {
-			idsToDelete = append(idsToDelete, id)
+			toDelete = append(toDelete, &domain.UserWorkoutWOD{
+				ID:          id,
+				WODID:       wodID,
+				TimeSeconds: timeSeconds,
+				Rounds:      rounds,
+				Reps:        reps,
+				Weight:      weight,
+			})
 		}
 	}
 
 	if err := rows.Err(); err != nil {
-		h.logger.Error("Error iterating WOD records", "error", err)
+		h.logger.Error("Error iterating WOD records error=%v", err)
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(map[string]string{"message": "Error processing WOD records"})
 		return
 	}
 
 	// Delete mismatched records
-	deletedCount := 0
-	for _, id := range idsToDelete {
-		err := h.userWorkoutWODRepo.Delete(id)
+	var deleted []*domain.UserWorkoutWOD
+	for _, record := range toDelete {
+		err := h.userWorkoutWODRepo.Delete(r.Context(), record.ID)
 		if err != nil {
-			h.logger.Error("Failed to delete WOD record", "id", id, "error", err)
+			h.logger.Error("Failed to delete WOD record id=%v error=%v", record.ID, err)
 			continue
 		}
-		deletedCount++
+		deleted = append(deleted, record)
+	}
+	deletedCount := len(deleted)
+
+	h.logger.Info("Deleted mismatched WOD records count=%v", deletedCount)
+	if deletedCount > 0 {
+		recordAudit(h.auditService, h.logger, r, &domain.AuditEntry{
+			Action:     domain.AuditWODRecordsDeleted,
+			TargetType: domain.AuditTargetWODRecord,
+			Before:     service.AuditState(deleted),
+		})
 	}
-
-	h.logger.Info("Deleted mismatched WOD records", "count", deletedCount)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"deleted_count": deletedCount,
-		"total_found":   len(idsToDelete),
+		"total_found":   len(toDelete),
 	})
 }
 
@@ -258,7 +277,7 @@ func (h *AdminHandler) UpdateWODRecord(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
-		h.logger.Error("Invalid record ID", "id", idStr, "error", err)
+		h.logger.Error("Invalid record ID id=%v error=%v", idStr, err)
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(map[string]string{"message": "Invalid record ID"})
 		return
@@ -267,25 +286,25 @@ func (h *AdminHandler) UpdateWODRecord(w http.ResponseWriter, r *http.Request) {
 	// Parse request body
 	var req UpdateWODRecordRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.logger.Error("Failed to parse request body", "error", err)
+		h.logger.Error("Failed to parse request body error=%v", err)
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(map[string]string{"message": "Invalid request body"})
 		return
 	}
 
 	// Get the existing record to find the WOD ID
-	existingRecord, err := h.userWorkoutWODRepo.GetByID(id)
-	if err != nil {
-		h.logger.Error("Failed to get existing WOD record", "id", id, "error", err)
+	existingRecord, err := h.userWorkoutWODRepo.GetByID(r.Context(), id)
+	if err != nil || existingRecord == nil {
+		h.logger.Error("Failed to get existing WOD record id=%v error=%v", id, err)
 		w.WriteHeader(http.StatusNotFound)
 		json.NewEncoder(w).Encode(map[string]string{"message": "WOD record not found"})
 		return
 	}
 
 	// Get the WOD definition to validate score_type
-	wod, err := h.wodRepo.GetByID(existingRecord.WODID)
+	wod, err := h.wodRepo.GetByID(r.Context(), existingRecord.WODID)
 	if err != nil {
-		h.logger.Error("Failed to get WOD definition", "wod_id", existingRecord.WODID, "error", err)
+		h.logger.Error("Failed to get WOD definition wod_id=%v error=%v", existingRecord.WODID, err)
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(map[string]string{"message": "Failed to get WOD definition"})
 		return
@@ -354,14 +373,21 @@ func (h *AdminHandler) UpdateWODRecord(w http.ResponseWriter, r *http.Request) {
 		OrderIndex:    existingRecord.OrderIndex,
 	}
 
-	if err := h.userWorkoutWODRepo.Update(updatedRecord); err != nil {
-		h.logger.Error("Failed to update WOD record", "id", id, "error", err)
+	if err := h.userWorkoutWODRepo.Update(r.Context(), updatedRecord); err != nil {
+		h.logger.Error("Failed to update WOD record id=%v error=%v", id, err)
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(map[string]string{"message": "Failed to update WOD record"})
 		return
 	}
 
-	h.logger.Info("Updated WOD record", "id", id, "wod_name", wod.Name, "score_type", scoreType)
+	h.logger.Info("Updated WOD record id=%v wod_name=%v score_type=%v", id, wod.Name, scoreType)
+	recordAudit(h.auditService, h.logger, r, &domain.AuditEntry{
+		Action:     domain.AuditWODRecordUpdated,
+		TargetType: domain.AuditTargetWODRecord,
+		TargetID:   &id,
+		Before:     service.AuditState(existingRecord),
+		After:      service.AuditState(updatedRecord),
+	})
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
@@ -0,0 +1,311 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/johnzastrow/actalog/internal/domain"
+	"github.com/johnzastrow/actalog/internal/service"
+	"github.com/johnzastrow/actalog/pkg/logger"
+	"github.com/johnzastrow/actalog/pkg/middleware"
+)
+
+// AdminUserHandler handles admin endpoints for managing user accounts
+type AdminUserHandler struct {
+	adminUserService *service.AdminUserService
+	lockoutService   *service.LockoutService
+	auditService     *service.AuditService
+	logger           *logger.Logger
+}
+
+// NewAdminUserHandler creates a new admin user handler
+func NewAdminUserHandler(
+	adminUserService *service.AdminUserService,
+	lockoutService *service.LockoutService,
+	auditService *service.AuditService,
+	logger *logger.Logger,
+) *AdminUserHandler {
+	return &AdminUserHandler{
+		adminUserService: adminUserService,
+		lockoutService:   lockoutService,
+		auditService:     auditService,
+		logger:           logger,
+	}
+}
+
+// SetRoleRequest represents a request to change a user's role
+type SetRoleRequest struct {
+	Role string `json:"role"`
+}
+
+// ListUsers lists users, newest first. Query parameters: q (matches name or
+// email), role, disabled (true/false), limit (default 50) and offset.
+func (h *AdminUserHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := domain.UserFilter{
+		Query: query.Get("q"),
+		Role:  query.Get("role"),
+		Limit: 50,
+	}
+	if l, err := strconv.Atoi(query.Get("limit")); err == nil && l > 0 {
+		filter.Limit = l
+	}
+	if o, err := strconv.Atoi(query.Get("offset")); err == nil && o >= 0 {
+		filter.Offset = o
+	}
+	if d := query.Get("disabled"); d != "" {
+		disabled, err := strconv.ParseBool(d)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "disabled must be true or false")
+			return
+		}
+		filter.Disabled = &disabled
+	}
+
+	users, total, err := h.adminUserService.List(r.Context(), filter)
+	if err != nil {
+		if h.logger != nil {
+			h.logger.Error("action=list_users outcome=failure error=%v", err)
+		}
+		respondError(w, http.StatusInternalServerError, "Failed to list users")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"users":  users,
+		"total":  total,
+		"limit":  filter.Limit,
+		"offset": filter.Offset,
+	})
+}
+
+// GetUser returns a single user
+func (h *AdminUserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	userID, ok := parseUserID(w, r)
+	if !ok {
+		return
+	}
+
+	user, err := h.adminUserService.Get(r.Context(), userID)
+	if err != nil {
+		h.respondServiceError(w, "get_user", 0, userID, err, "Failed to get user")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, user)
+}
+
+// SetRole changes a user's role
+func (h *AdminUserHandler) SetRole(w http.ResponseWriter, r *http.Request) {
+	adminID, _ := middleware.GetUserID(r.Context())
+	userID, ok := parseUserID(w, r)
+	if !ok {
+		return
+	}
+
+	var req SetRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondErrorWithDetail(w, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	before, _ := h.adminUserService.Get(r.Context(), userID)
+	user, err := h.adminUserService.SetRole(r.Context(), adminID, userID, req.Role)
+	if err != nil {
+		h.respondServiceError(w, "set_user_role", adminID, userID, err, "Failed to change role")
+		return
+	}
+
+	if h.logger != nil {
+		h.logger.Info("action=set_user_role outcome=success admin_id=%d user_id=%d role=%s", adminID, userID, user.Role)
+	}
+	h.audit(r, domain.AuditRoleChanged, userID, before, user)
+
+	respondJSON(w, http.StatusOK, user)
+}
+
+// DisableUser disables a user's account and signs them out everywhere
+func (h *AdminUserHandler) DisableUser(w http.ResponseWriter, r *http.Request) {
+	h.setDisabled(w, r, true)
+}
+
+// EnableUser re-enables a disabled account
+func (h *AdminUserHandler) EnableUser(w http.ResponseWriter, r *http.Request) {
+	h.setDisabled(w, r, false)
+}
+
+func (h *AdminUserHandler) setDisabled(w http.ResponseWriter, r *http.Request, disabled bool) {
+	action, auditAction := "enable_user", domain.AuditUserEnabled
+	if disabled {
+		action, auditAction = "disable_user", domain.AuditUserDisabled
+	}
+
+	adminID, _ := middleware.GetUserID(r.Context())
+	userID, ok := parseUserID(w, r)
+	if !ok {
+		return
+	}
+
+	before, _ := h.adminUserService.Get(r.Context(), userID)
+	user, err := h.adminUserService.SetDisabled(r.Context(), adminID, userID, disabled)
+	if err != nil {
+		h.respondServiceError(w, action, adminID, userID, err, "Failed to update user")
+		return
+	}
+
+	if h.logger != nil {
+		h.logger.Info("action=%s outcome=success admin_id=%d user_id=%d", action, adminID, userID)
+	}
+	h.audit(r, auditAction, userID, before, user)
+
+	respondJSON(w, http.StatusOK, user)
+}
+
+// ForcePasswordReset clears a user's password and emails them a reset link
+func (h *AdminUserHandler) ForcePasswordReset(w http.ResponseWriter, r *http.Request) {
+	adminID, _ := middleware.GetUserID(r.Context())
+	userID, ok := parseUserID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.adminUserService.ForcePasswordReset(r.Context(), userID); err != nil {
+		h.respondServiceError(w, "force_password_reset", adminID, userID, err, "Failed to reset password")
+		return
+	}
+
+	if h.logger != nil {
+		h.logger.Info("action=force_password_reset outcome=success admin_id=%d user_id=%d", adminID, userID)
+	}
+	h.audit(r, domain.AuditPasswordResetForced, userID, nil, nil)
+
+	respondJSON(w, http.StatusOK, map[string]string{
+		"message": "Password reset email sent successfully",
+	})
+}
+
+// ResendVerification emails a user a new verification link
+func (h *AdminUserHandler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	adminID, _ := middleware.GetUserID(r.Context())
+	userID, ok := parseUserID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.adminUserService.ResendVerification(r.Context(), userID); err != nil {
+		h.respondServiceError(w, "resend_verification", adminID, userID, err, "Failed to send verification email")
+		return
+	}
+
+	if h.logger != nil {
+		h.logger.Info("action=resend_verification outcome=success admin_id=%d user_id=%d", adminID, userID)
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{
+		"message": "Verification email sent successfully",
+	})
+}
+
+// DeleteUser deletes a user and all of their data
+func (h *AdminUserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	adminID, _ := middleware.GetUserID(r.Context())
+	userID, ok := parseUserID(w, r)
+	if !ok {
+		return
+	}
+
+	before, _ := h.adminUserService.Get(r.Context(), userID)
+	if err := h.adminUserService.Delete(r.Context(), adminID, userID); err != nil {
+		h.respondServiceError(w, "delete_user", adminID, userID, err, "Failed to delete user")
+		return
+	}
+
+	if h.logger != nil {
+		h.logger.Info("action=delete_user outcome=success admin_id=%d user_id=%d", adminID, userID)
+	}
+	h.audit(r, domain.AuditUserDeleted, userID, before, nil)
+
+	respondJSON(w, http.StatusOK, map[string]string{
+		"message": "User deleted successfully",
+	})
+}
+
+// UnlockUser clears a user's failed logins so they can log in again straight away
+func (h *AdminUserHandler) UnlockUser(w http.ResponseWriter, r *http.Request) {
+	adminID, _ := middleware.GetUserID(r.Context())
+	userID, ok := parseUserID(w, r)
+	if !ok {
+		return
+	}
+
+	wasLocked, err := h.lockoutService.Unlock(r.Context(), userID)
+	if err != nil {
+		h.respondServiceError(w, "unlock_user", adminID, userID, err, "Failed to unlock user")
+		return
+	}
+
+	if h.logger != nil {
+		h.logger.Info("action=unlock_user outcome=success admin_id=%d user_id=%d was_locked=%t", adminID, userID, wasLocked)
+	}
+	recordAudit(h.auditService, h.logger, r, &domain.AuditEntry{
+		Action:     domain.AuditUserUnlocked,
+		TargetType: domain.AuditTargetUser,
+		TargetID:   &userID,
+		After:      service.AuditState(map[string]bool{"was_locked": wasLocked}),
+	})
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"message":    "User unlocked successfully",
+		"was_locked": wasLocked,
+	})
+}
+
+// audit records an admin action on a user. before and after are the user's
+// state around the change; nil leaves them out.
+func (h *AdminUserHandler) audit(r *http.Request, action string, userID int64, before, after *domain.User) {
+	entry := &domain.AuditEntry{
+		Action:     action,
+		TargetType: domain.AuditTargetUser,
+		TargetID:   &userID,
+	}
+	if before != nil {
+		entry.Before = service.AuditState(before)
+	}
+	if after != nil {
+		entry.After = service.AuditState(after)
+	}
+	recordAudit(h.auditService, h.logger, r, entry)
+}
+
+// respondServiceError maps admin user service errors to responses, logging
+// unexpected ones
+func (h *AdminUserHandler) respondServiceError(w http.ResponseWriter, action string, adminID, userID int64, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrUserNotFound):
+		respondError(w, http.StatusNotFound, "User not found")
+	case errors.Is(err, service.ErrInvalidRole):
+		respondError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrCannotModifySelf):
+		respondError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, service.ErrEmailAlreadyVerified):
+		respondError(w, http.StatusConflict, "Email is already verified")
+	default:
+		if h.logger != nil {
+			h.logger.Error("action=%s outcome=failure admin_id=%d user_id=%d error=%v", action, adminID, userID, err)
+		}
+		respondError(w, http.StatusInternalServerError, message)
+	}
+}
+
+// parseUserID reads the {id} URL parameter, responding 400 if it is invalid
+func parseUserID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	userID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid user ID")
+		return 0, false
+	}
+	return userID, true
+}
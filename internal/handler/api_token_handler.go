@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/johnzastrow/actalog/internal/domain"
+	"github.com/johnzastrow/actalog/internal/service"
+	"github.com/johnzastrow/actalog/pkg/logger"
+	"github.com/johnzastrow/actalog/pkg/middleware"
+)
+
+// APITokenHandler handles personal API token management endpoints
+type APITokenHandler struct {
+	apiTokenService *service.APITokenService
+	logger          *logger.Logger
+}
+
+// NewAPITokenHandler creates a new API token handler
+func NewAPITokenHandler(apiTokenService *service.APITokenService, logger *logger.Logger) *APITokenHandler {
+	return &APITokenHandler{
+		apiTokenService: apiTokenService,
+		logger:          logger,
+	}
+}
+
+// CreateAPITokenRequest represents a request to create an API token
+type CreateAPITokenRequest struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` // Omit for a token that doesn't expire
+}
+
+// APITokenSecretResponse includes the token value, which is only returned on creation
+type APITokenSecretResponse struct {
+	*domain.APIToken
+	Token string `json:"token"`
+}
+
+// ListAPITokens lists the caller's API tokens
+func (h *APITokenHandler) ListAPITokens(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	tokens, err := h.apiTokenService.List(r.Context(), userID)
+	if err != nil {
+		if h.logger != nil {
+			h.logger.Error("action=list_api_tokens outcome=failure user_id=%d error=%v", userID, err)
+		}
+		respondError(w, http.StatusInternalServerError, "Failed to list API tokens")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"tokens": tokens,
+		"scopes": domain.APITokenScopes,
+	})
+}
+
+// CreateAPIToken issues a new API token
+func (h *APITokenHandler) CreateAPIToken(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req CreateAPITokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondErrorWithDetail(w, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	token, value, err := h.apiTokenService.Create(r.Context(), userID, req.Name, req.Scopes, req.ExpiresAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidAPITokenName),
+			errors.Is(err, service.ErrInvalidAPITokenScope),
+			errors.Is(err, service.ErrInvalidAPITokenExpiry):
+			respondError(w, http.StatusBadRequest, err.Error())
+		default:
+			if h.logger != nil {
+				h.logger.Error("action=create_api_token outcome=failure user_id=%d error=%v", userID, err)
+			}
+			respondError(w, http.StatusInternalServerError, "Failed to create API token")
+		}
+		return
+	}
+
+	if h.logger != nil {
+		h.logger.Info("action=create_api_token outcome=success user_id=%d token_id=%d scopes=%v", userID, token.ID, token.Scopes)
+	}
+
+	respondJSON(w, http.StatusCreated, APITokenSecretResponse{APIToken: token, Token: value})
+}
+
+// DeleteAPIToken revokes an API token
+func (h *APITokenHandler) DeleteAPIToken(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid token ID")
+		return
+	}
+
+	if err := h.apiTokenService.Delete(r.Context(), id, userID); err != nil {
+		if errors.Is(err, service.ErrAPITokenNotFound) {
+			respondError(w, http.StatusNotFound, "API token not found")
+			return
+		}
+		if h.logger != nil {
+			h.logger.Error("action=delete_api_token outcome=failure user_id=%d token_id=%d error=%v", userID, id, err)
+		}
+		respondError(w, http.StatusInternalServerError, "Failed to delete API token")
+		return
+	}
+
+	if h.logger != nil {
+		h.logger.Info("action=delete_api_token outcome=success user_id=%d token_id=%d", userID, id)
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "API token deleted successfully"})
+}
@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/johnzastrow/actalog/internal/domain"
+	"github.com/johnzastrow/actalog/internal/service"
+	"github.com/johnzastrow/actalog/pkg/logger"
+	"github.com/johnzastrow/actalog/pkg/middleware"
+)
+
+// AuditHandler handles the admin audit log endpoint
+type AuditHandler struct {
+	auditService *service.AuditService
+	logger       *logger.Logger
+}
+
+// NewAuditHandler creates a new audit handler
+func NewAuditHandler(auditService *service.AuditService, logger *logger.Logger) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+		logger:       logger,
+	}
+}
+
+// ListAuditLog lists audit entries, newest first. Query parameters: action,
+// actor_id, target_type, target_id, since and until (RFC 3339), limit
+// (default 50) and offset.
+func (h *AuditHandler) ListAuditLog(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := domain.AuditFilter{
+		Action:     query.Get("action"),
+		TargetType: query.Get("target_type"),
+		Limit:      50,
+	}
+	if l, err := strconv.Atoi(query.Get("limit")); err == nil && l > 0 {
+		filter.Limit = l
+	}
+	if o, err := strconv.Atoi(query.Get("offset")); err == nil && o >= 0 {
+		filter.Offset = o
+	}
+
+	if v := query.Get("actor_id"); v != "" {
+		actorID, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid actor_id")
+			return
+		}
+		filter.ActorID = &actorID
+	}
+	if v := query.Get("target_id"); v != "" {
+		targetID, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid target_id")
+			return
+		}
+		filter.TargetID = &targetID
+	}
+	if v := query.Get("since"); v != "" {
+		since, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "since must be an RFC 3339 timestamp")
+			return
+		}
+		filter.Since = &since
+	}
+	if v := query.Get("until"); v != "" {
+		until, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "until must be an RFC 3339 timestamp")
+			return
+		}
+		filter.Until = &until
+	}
+
+	entries, total, err := h.auditService.List(r.Context(), filter)
+	if err != nil {
+		if h.logger != nil {
+			h.logger.Error("action=list_audit_log outcome=failure error=%v", err)
+		}
+		respondError(w, http.StatusInternalServerError, "Failed to list audit log")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"entries": entries,
+		"total":   total,
+		"limit":   filter.Limit,
+		"offset":  filter.Offset,
+	})
+}
+
+// recordAudit fills in the request's actor (if not already set), client IP
+// and request ID and records the entry. The action has already happened, so
+// failures are logged instead of failing the request. auditService may be nil.
+func recordAudit(auditService *service.AuditService, l *logger.Logger, r *http.Request, entry *domain.AuditEntry) {
+	if auditService == nil {
+		return
+	}
+
+	if entry.ActorID == nil {
+		if userID, ok := middleware.GetUserID(r.Context()); ok {
+			entry.ActorID = &userID
+		}
+	}
+	entry.IPAddress = clientIP(r)
+	entry.RequestID, _ = middleware.GetRequestID(r.Context())
+
+	// The action has happened, so it's recorded even if the client has gone
+	if err := auditService.Record(context.WithoutCancel(r.Context()), entry); err != nil && l != nil {
+		l.Error("action=record_audit outcome=failure audit_action=%s error=%v", entry.Action, err)
+	}
+}
@@ -2,23 +2,32 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"net"
 	"net/http"
+	"strconv"
+	"time"
 
+	"github.com/johnzastrow/actalog/internal/domain"
 	"github.com/johnzastrow/actalog/internal/service"
 	"github.com/johnzastrow/actalog/pkg/logger"
+	"github.com/johnzastrow/actalog/pkg/webauthn"
 )
 
 // AuthHandler handles authentication endpoints
 type AuthHandler struct {
-	userService *service.UserService
-	logger      *logger.Logger
+	userService  *service.UserService
+	auditService *service.AuditService
+	logger       *logger.Logger
 }
 
-// NewAuthHandler creates a new auth handler
-func NewAuthHandler(userService *service.UserService, l *logger.Logger) *AuthHandler {
+// NewAuthHandler creates a new auth handler. auditService may be nil, in
+// which case logins aren't recorded in the audit log.
+func NewAuthHandler(userService *service.UserService, auditService *service.AuditService, l *logger.Logger) *AuthHandler {
 	return &AuthHandler{
-		userService: userService,
-		logger:      l,
+		userService:  userService,
+		auditService: auditService,
+		logger:       l,
 	}
 }
 
@@ -36,6 +45,43 @@ type LoginRequest struct {
 	RememberMe bool   `json:"remember_me,omitempty"`
 }
 
+// LoginMFARequest completes a two-factor login
+type LoginMFARequest struct {
+	MFAToken   string `json:"mfa_token"`
+	Code       string `json:"code"` // TOTP code or recovery code
+	RememberMe bool   `json:"remember_me,omitempty"`
+}
+
+// MFAChallengeResponse is returned by login when a second factor is required
+type MFAChallengeResponse struct {
+	MFARequired bool   `json:"mfa_required"`
+	MFAToken    string `json:"mfa_token"`
+}
+
+// PasskeyLoginOptionsRequest starts a passkey login. Email is optional.
+type PasskeyLoginOptionsRequest struct {
+	Email string `json:"email,omitempty"`
+}
+
+// PasskeyLoginRequest completes a passkey login
+type PasskeyLoginRequest struct {
+	Credential *webauthn.AuthenticationResponse `json:"credential"`
+	RememberMe bool                             `json:"remember_me,omitempty"`
+}
+
+// OIDCCallbackRequest completes a single sign-on login with the parameters
+// the identity provider redirected back with
+type OIDCCallbackRequest struct {
+	Code       string `json:"code"`
+	State      string `json:"state"`
+	RememberMe bool   `json:"remember_me,omitempty"`
+}
+
+// OIDCAuthorizeResponse holds the identity provider URL to navigate to
+type OIDCAuthorizeResponse struct {
+	AuthorizationURL string `json:"authorization_url"`
+}
+
 // AuthResponse represents an authentication response
 type AuthResponse struct {
 	Token        string      `json:"token"`
@@ -69,7 +115,7 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Register user
-	user, token, err := h.userService.Register(req.Name, req.Email, req.Password)
+	user, token, err := h.userService.Register(r.Context(), req.Name, req.Email, req.Password)
 	if err != nil {
 		switch err {
 		case service.ErrEmailAlreadyExists:
@@ -120,14 +166,36 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Login user
-	user, token, err := h.userService.Login(req.Email, req.Password)
+	user, token, err := h.userService.Login(r.Context(), req.Email, req.Password)
 	if err != nil {
-		if err == service.ErrInvalidCredentials {
+		var mfaErr *service.MFARequiredError
+		var lockedErr *service.AccountLockedError
+		switch {
+		case errors.As(err, &mfaErr):
+			if h.logger != nil {
+				h.logger.Info("action=login outcome=mfa_required email=%s", req.Email)
+			}
+			respondJSON(w, http.StatusOK, MFAChallengeResponse{MFARequired: true, MFAToken: mfaErr.Token})
+		case errors.As(err, &lockedErr):
+			if h.logger != nil {
+				h.logger.Warn("action=login outcome=failure email=%s reason=account_locked locked_until=%s remote=%s", req.Email, lockedErr.LockedUntil.Format(time.RFC3339), r.RemoteAddr)
+			}
+			w.Header().Set("Retry-After", strconv.Itoa(int(time.Until(lockedErr.LockedUntil).Seconds())+1))
+			h.auditLoginFailed(r, "password", req.Email, "account_locked")
+			respondError(w, http.StatusLocked, "Account temporarily locked after too many failed logins. Try again later or reset your password.")
+		case errors.Is(err, service.ErrAccountDisabled):
+			if h.logger != nil {
+				h.logger.Warn("action=login outcome=failure email=%s reason=account_disabled", req.Email)
+			}
+			h.auditLoginFailed(r, "password", req.Email, "account_disabled")
+			respondError(w, http.StatusForbidden, "Account is disabled. Please contact an administrator.")
+		case err == service.ErrInvalidCredentials:
 			if h.logger != nil {
 				h.logger.Warn("action=login outcome=failure email=%s reason=invalid_credentials", req.Email)
 			}
+			h.auditLoginFailed(r, "password", req.Email, "invalid_credentials")
 			respondError(w, http.StatusUnauthorized, "Invalid email or password")
-		} else {
+		default:
 			if h.logger != nil {
 				h.logger.Error("action=login outcome=failure email=%s error=%v", req.Email, err)
 			}
@@ -136,15 +204,218 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	h.respondLoggedIn(w, r, user, token, "password", req.RememberMe)
+}
+
+// LoginMFA completes a two-factor login with the challenge token returned by
+// Login and a TOTP or recovery code
+func (h *AuthHandler) LoginMFA(w http.ResponseWriter, r *http.Request) {
+	var req LoginMFARequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondErrorWithDetail(w, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	if req.MFAToken == "" || req.Code == "" {
+		respondError(w, http.StatusBadRequest, "MFA token and code are required")
+		return
+	}
+
+	user, token, err := h.userService.CompleteMFALogin(r.Context(), req.MFAToken, req.Code)
+	if err != nil {
+		var lockedErr *service.AccountLockedError
+		switch {
+		case errors.As(err, &lockedErr):
+			if h.logger != nil {
+				h.logger.Warn("action=login_mfa outcome=failure reason=account_locked locked_until=%s remote=%s", lockedErr.LockedUntil.Format(time.RFC3339), r.RemoteAddr)
+			}
+			w.Header().Set("Retry-After", strconv.Itoa(int(time.Until(lockedErr.LockedUntil).Seconds())+1))
+			h.auditLoginFailed(r, "mfa", "", "account_locked")
+			respondError(w, http.StatusLocked, "Account temporarily locked after too many failed logins. Try again later or reset your password.")
+		case errors.Is(err, service.ErrInvalidMFAToken):
+			if h.logger != nil {
+				h.logger.Warn("action=login_mfa outcome=failure reason=invalid_token remote=%s", r.RemoteAddr)
+			}
+			respondError(w, http.StatusUnauthorized, "MFA token is invalid or expired; log in again")
+		case errors.Is(err, service.ErrInvalidMFACode), errors.Is(err, service.ErrMFANotEnabled):
+			if h.logger != nil {
+				h.logger.Warn("action=login_mfa outcome=failure reason=invalid_code remote=%s", r.RemoteAddr)
+			}
+			h.auditLoginFailed(r, "mfa", "", "invalid_code")
+			respondError(w, http.StatusUnauthorized, "Invalid authentication code")
+		case errors.Is(err, service.ErrAccountDisabled):
+			h.auditLoginFailed(r, "mfa", "", "account_disabled")
+			respondError(w, http.StatusForbidden, "Account is disabled. Please contact an administrator.")
+		default:
+			if h.logger != nil {
+				h.logger.Error("action=login_mfa outcome=failure error=%v", err)
+			}
+			respondError(w, http.StatusInternalServerError, "Failed to login")
+		}
+		return
+	}
+
+	h.respondLoggedIn(w, r, user, token, "mfa", req.RememberMe)
+}
+
+// BeginPasskeyLogin returns options for navigator.credentials.get()
+func (h *AuthHandler) BeginPasskeyLogin(w http.ResponseWriter, r *http.Request) {
+	var req PasskeyLoginOptionsRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondErrorWithDetail(w, http.StatusBadRequest, "Invalid request body", err.Error())
+			return
+		}
+	}
+
+	options, err := h.userService.BeginPasskeyLogin(r.Context(), req.Email)
+	if err != nil {
+		if errors.Is(err, service.ErrPasskeysUnavailable) {
+			respondError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		if h.logger != nil {
+			h.logger.Error("action=begin_passkey_login outcome=failure error=%v", err)
+		}
+		respondError(w, http.StatusInternalServerError, "Failed to start passkey login")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, options)
+}
+
+// LoginPasskey completes a passkey login with the authenticator's response
+func (h *AuthHandler) LoginPasskey(w http.ResponseWriter, r *http.Request) {
+	var req PasskeyLoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondErrorWithDetail(w, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+	if req.Credential == nil {
+		respondError(w, http.StatusBadRequest, "Credential is required")
+		return
+	}
+
+	user, token, err := h.userService.LoginWithPasskey(r.Context(), req.Credential)
+	if err != nil {
+		var mfaErr *service.MFARequiredError
+		switch {
+		case errors.As(err, &mfaErr):
+			if h.logger != nil {
+				h.logger.Info("action=login_passkey outcome=mfa_required remote=%s", r.RemoteAddr)
+			}
+			respondJSON(w, http.StatusOK, MFAChallengeResponse{MFARequired: true, MFAToken: mfaErr.Token})
+		case errors.Is(err, service.ErrInvalidPasskeyChallenge):
+			respondError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, service.ErrInvalidPasskey):
+			if h.logger != nil {
+				h.logger.Warn("action=login_passkey outcome=failure remote=%s error=%v", r.RemoteAddr, err)
+			}
+			h.auditLoginFailed(r, "passkey", "", "invalid_passkey")
+			respondError(w, http.StatusUnauthorized, "Passkey verification failed")
+		case errors.Is(err, service.ErrAccountDisabled):
+			h.auditLoginFailed(r, "passkey", "", "account_disabled")
+			respondError(w, http.StatusForbidden, "Account is disabled. Please contact an administrator.")
+		case errors.Is(err, service.ErrPasskeysUnavailable):
+			respondError(w, http.StatusNotFound, err.Error())
+		default:
+			if h.logger != nil {
+				h.logger.Error("action=login_passkey outcome=failure error=%v", err)
+			}
+			respondError(w, http.StatusInternalServerError, "Failed to login")
+		}
+		return
+	}
+
+	h.respondLoggedIn(w, r, user, token, "passkey", req.RememberMe)
+}
+
+// BeginOIDCLogin returns the identity provider URL for single sign-on. The
+// frontend should keep the URL's state parameter and only complete logins
+// that return with the same state.
+func (h *AuthHandler) BeginOIDCLogin(w http.ResponseWriter, r *http.Request) {
+	authURL, err := h.userService.BeginOIDCLogin(r.Context())
+	if err != nil {
+		if errors.Is(err, service.ErrOIDCUnavailable) {
+			respondError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		if h.logger != nil {
+			h.logger.Error("action=begin_oidc_login outcome=failure error=%v", err)
+		}
+		respondError(w, http.StatusBadGateway, "Failed to contact the identity provider")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, OIDCAuthorizeResponse{AuthorizationURL: authURL})
+}
+
+// OIDCCallback completes a single sign-on login
+func (h *AuthHandler) OIDCCallback(w http.ResponseWriter, r *http.Request) {
+	var req OIDCCallbackRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondErrorWithDetail(w, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+	if req.Code == "" || req.State == "" {
+		respondError(w, http.StatusBadRequest, "Code and state are required")
+		return
+	}
+
+	user, token, err := h.userService.LoginWithOIDC(r.Context(), req.Code, req.State)
+	if err != nil {
+		var mfaErr *service.MFARequiredError
+		switch {
+		case errors.As(err, &mfaErr):
+			if h.logger != nil {
+				h.logger.Info("action=login_oidc outcome=mfa_required remote=%s", r.RemoteAddr)
+			}
+			respondJSON(w, http.StatusOK, MFAChallengeResponse{MFARequired: true, MFAToken: mfaErr.Token})
+		case errors.Is(err, service.ErrInvalidOIDCState):
+			respondError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, service.ErrOIDCEmailNotVerified), errors.Is(err, service.ErrOIDCDomainNotAllowed):
+			if h.logger != nil {
+				h.logger.Warn("action=login_oidc outcome=failure remote=%s reason=%v", r.RemoteAddr, err)
+			}
+			respondError(w, http.StatusForbidden, err.Error())
+		case errors.Is(err, service.ErrRegistrationClosed):
+			respondError(w, http.StatusForbidden, "Registration is closed. Please contact an administrator.")
+		case errors.Is(err, service.ErrAccountDisabled):
+			h.auditLoginFailed(r, "oidc", "", "account_disabled")
+			respondError(w, http.StatusForbidden, "Account is disabled. Please contact an administrator.")
+		case errors.Is(err, service.ErrOIDCLoginFailed):
+			if h.logger != nil {
+				h.logger.Warn("action=login_oidc outcome=failure remote=%s error=%v", r.RemoteAddr, err)
+			}
+			h.auditLoginFailed(r, "oidc", "", "sso_failed")
+			respondError(w, http.StatusUnauthorized, "Single sign-on failed")
+		case errors.Is(err, service.ErrOIDCUnavailable):
+			respondError(w, http.StatusNotFound, err.Error())
+		default:
+			if h.logger != nil {
+				h.logger.Error("action=login_oidc outcome=failure error=%v", err)
+			}
+			respondError(w, http.StatusInternalServerError, "Failed to login")
+		}
+		return
+	}
+
+	h.respondLoggedIn(w, r, user, token, "oidc", req.RememberMe)
+}
+
+// respondLoggedIn writes the response for a completed login, creating a
+// refresh token if the user asked to be remembered. method (password, mfa,
+// passkey or oidc) is recorded in the audit log.
+func (h *AuthHandler) respondLoggedIn(w http.ResponseWriter, r *http.Request, user *domain.User, token, method string, rememberMe bool) {
 	response := AuthResponse{
 		Token: token,
 		User:  user,
 	}
 
 	// Create refresh token if remember_me is true
-	if req.RememberMe {
+	if rememberMe {
 		deviceInfo := r.UserAgent() // Get browser/device info from User-Agent header
-		refreshToken, err := h.userService.CreateRefreshToken(user.ID, deviceInfo)
+		refreshToken, err := h.userService.CreateRefreshToken(r.Context(), user.ID, deviceInfo, clientIP(r))
 		if err != nil {
 			// Log error but don't fail the login
 			if h.logger != nil {
@@ -159,10 +430,31 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	if h.logger != nil {
 		h.logger.Info("action=login outcome=success user_id=%d email=%s", user.ID, user.Email)
 	}
+	recordAudit(h.auditService, h.logger, r, &domain.AuditEntry{
+		Action:     domain.AuditLogin,
+		ActorID:    &user.ID,
+		TargetType: domain.AuditTargetUser,
+		TargetID:   &user.ID,
+		After:      service.AuditState(map[string]interface{}{"method": method, "remember_me": rememberMe}),
+	})
 
 	respondJSON(w, http.StatusOK, response)
 }
 
+// auditLoginFailed records a failed login. email is empty for methods that
+// don't identify the account before failing.
+func (h *AuthHandler) auditLoginFailed(r *http.Request, method, email, reason string) {
+	details := map[string]string{"method": method, "reason": reason}
+	if email != "" {
+		details["email"] = email
+	}
+	recordAudit(h.auditService, h.logger, r, &domain.AuditEntry{
+		Action:     domain.AuditLoginFailed,
+		TargetType: domain.AuditTargetUser,
+		After:      service.AuditState(details),
+	})
+}
+
 // ForgotPasswordRequest represents a forgot password request
 type ForgotPasswordRequest struct {
 	Email string `json:"email"`
@@ -194,7 +486,7 @@ func (h *AuthHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Request password reset (always succeeds for security)
-	err := h.userService.RequestPasswordReset(req.Email)
+	err := h.userService.RequestPasswordReset(r.Context(), req.Email)
 	if err != nil {
 		// Log error but don't reveal to user
 		// In production, this should use proper logging
@@ -229,7 +521,7 @@ func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Reset password
-	err := h.userService.ResetPassword(req.Token, req.NewPassword)
+	userID, err := h.userService.ResetPassword(r.Context(), req.Token, req.NewPassword)
 	if err != nil {
 		switch err {
 		case service.ErrInvalidResetToken:
@@ -242,6 +534,13 @@ func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordAudit(h.auditService, h.logger, r, &domain.AuditEntry{
+		Action:     domain.AuditPasswordReset,
+		ActorID:    &userID,
+		TargetType: domain.AuditTargetUser,
+		TargetID:   &userID,
+	})
+
 	respondJSON(w, http.StatusOK, MessageResponse{
 		Message: "Password has been reset successfully. You can now login with your new password",
 	})
@@ -262,7 +561,7 @@ func (h *AuthHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Verify email
-	err := h.userService.VerifyEmail(token)
+	err := h.userService.VerifyEmail(r.Context(), token)
 	if err != nil {
 		switch err {
 		case service.ErrInvalidVerificationToken:
@@ -302,7 +601,7 @@ func (h *AuthHandler) ResendVerification(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Resend verification email
-	err := h.userService.ResendVerificationEmail(req.Email)
+	err := h.userService.ResendVerificationEmail(r.Context(), req.Email)
 	if err != nil {
 		if err == service.ErrEmailAlreadyVerified {
 			respondError(w, http.StatusBadRequest, "Email is already verified")
@@ -337,20 +636,29 @@ func (h *AuthHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Refresh access token
-	user, newAccessToken, err := h.userService.RefreshAccessToken(req.RefreshToken)
+	// Refresh access token; the refresh token is replaced with a new one
+	user, newAccessToken, newRefreshToken, err := h.userService.RefreshAccessToken(r.Context(), req.RefreshToken, clientIP(r))
 	if err != nil {
-		if err == service.ErrInvalidRefreshToken {
+		switch {
+		case errors.Is(err, service.ErrRefreshTokenReused):
+			if h.logger != nil {
+				h.logger.Warn("action=refresh_token outcome=failure reason=reused remote=%s ua=%s", r.RemoteAddr, r.UserAgent())
+			}
 			respondError(w, http.StatusUnauthorized, "Invalid or expired refresh token")
-		} else {
+		case errors.Is(err, service.ErrInvalidRefreshToken), errors.Is(err, service.ErrUserNotFound):
+			respondError(w, http.StatusUnauthorized, "Invalid or expired refresh token")
+		case errors.Is(err, service.ErrAccountDisabled):
+			respondError(w, http.StatusForbidden, "Account is disabled. Please contact an administrator.")
+		default:
 			respondError(w, http.StatusInternalServerError, "Failed to refresh token")
 		}
 		return
 	}
 
 	respondJSON(w, http.StatusOK, AuthResponse{
-		Token: newAccessToken,
-		User:  user,
+		Token:        newAccessToken,
+		RefreshToken: newRefreshToken,
+		User:         user,
 	})
 }
 
@@ -374,7 +682,7 @@ func (h *AuthHandler) RevokeToken(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Revoke token
-	err := h.userService.RevokeRefreshToken(req.RefreshToken)
+	err := h.userService.RevokeRefreshToken(r.Context(), req.RefreshToken)
 	if err != nil {
 		if err == service.ErrInvalidRefreshToken {
 			respondError(w, http.StatusNotFound, "Refresh token not found")
@@ -391,6 +699,15 @@ func (h *AuthHandler) RevokeToken(w http.ResponseWriter, r *http.Request) {
 
 // Helper functions
 
+// clientIP returns the address of the client that sent the request
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
 func respondJSON(w http.ResponseWriter, status int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/johnzastrow/actalog/internal/domain"
+	"github.com/johnzastrow/actalog/internal/service"
+	"github.com/johnzastrow/actalog/pkg/logger"
+	"github.com/johnzastrow/actalog/pkg/middleware"
+)
+
+// BackupHandler handles admin endpoints for backups
+type BackupHandler struct {
+	backupService *service.BackupService
+	auditService  *service.AuditService
+	logger        *logger.Logger
+}
+
+// NewBackupHandler creates a new backup handler
+func NewBackupHandler(backupService *service.BackupService, auditService *service.AuditService, logger *logger.Logger) *BackupHandler {
+	return &BackupHandler{
+		backupService: backupService,
+		auditService:  auditService,
+		logger:        logger,
+	}
+}
+
+// ListBackups lists the backup archives, newest first
+func (h *BackupHandler) ListBackups(w http.ResponseWriter, r *http.Request) {
+	backups, err := h.backupService.List()
+	if err != nil {
+		if h.logger != nil {
+			h.logger.Error("action=list_backups outcome=failure error=%v", err)
+		}
+		respondError(w, http.StatusInternalServerError, "Failed to list backups")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"backups": backups,
+	})
+}
+
+// CreateBackup takes a backup now
+func (h *BackupHandler) CreateBackup(w http.ResponseWriter, r *http.Request) {
+	adminID, _ := middleware.GetUserID(r.Context())
+
+	backup, err := h.backupService.Create(r.Context(), domain.BackupTriggerAdmin)
+	if err != nil {
+		if h.logger != nil {
+			h.logger.Error("action=create_backup outcome=failure admin_id=%d error=%v", adminID, err)
+		}
+		respondError(w, http.StatusInternalServerError, "Failed to create backup")
+		return
+	}
+
+	if h.logger != nil {
+		h.logger.Info("action=create_backup outcome=success admin_id=%d backup=%s size=%d", adminID, backup.Name, backup.Size)
+	}
+	recordAudit(h.auditService, h.logger, r, &domain.AuditEntry{
+		Action: domain.AuditBackupCreated,
+		After:  service.AuditState(backup),
+	})
+
+	respondJSON(w, http.StatusCreated, backup)
+}
+
+// DownloadBackup sends a backup archive. Archives hold every account's
+// data, so downloads are audited.
+func (h *BackupHandler) DownloadBackup(w http.ResponseWriter, r *http.Request) {
+	adminID, _ := middleware.GetUserID(r.Context())
+	name := chi.URLParam(r, "name")
+
+	file, err := h.backupService.Open(name)
+	if err != nil {
+		if errors.Is(err, service.ErrBackupNotFound) {
+			respondError(w, http.StatusNotFound, "Backup not found")
+			return
+		}
+		if h.logger != nil {
+			h.logger.Error("action=download_backup outcome=failure admin_id=%d backup=%s error=%v", adminID, name, err)
+		}
+		respondError(w, http.StatusInternalServerError, "Failed to open backup")
+		return
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		if h.logger != nil {
+			h.logger.Error("action=download_backup outcome=failure admin_id=%d backup=%s error=%v", adminID, name, err)
+		}
+		respondError(w, http.StatusInternalServerError, "Failed to open backup")
+		return
+	}
+
+	if h.logger != nil {
+		h.logger.Info("action=download_backup outcome=success admin_id=%d backup=%s", adminID, name)
+	}
+	recordAudit(h.auditService, h.logger, r, &domain.AuditEntry{
+		Action: domain.AuditBackupDownloaded,
+		After:  service.AuditState(map[string]string{"name": name}),
+	})
+
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+name+`"`)
+	http.ServeContent(w, r, name, info.ModTime(), file)
+}
@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/johnzastrow/actalog/internal/domain"
+	"github.com/johnzastrow/actalog/internal/service"
+	"github.com/johnzastrow/actalog/pkg/logger"
+	"github.com/johnzastrow/actalog/pkg/middleware"
+)
+
+// EmailOutboxHandler handles admin endpoints for the email outbox
+type EmailOutboxHandler struct {
+	outboxService *service.EmailOutboxService
+	logger        *logger.Logger
+}
+
+// NewEmailOutboxHandler creates a new email outbox handler
+func NewEmailOutboxHandler(outboxService *service.EmailOutboxService, logger *logger.Logger) *EmailOutboxHandler {
+	return &EmailOutboxHandler{
+		outboxService: outboxService,
+		logger:        logger,
+	}
+}
+
+// ListOutbox lists outbox emails by status (default: dead) along with per-status counts
+func (h *EmailOutboxHandler) ListOutbox(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		status = domain.OutboxStatusDead
+	}
+	if status != domain.OutboxStatusPending && status != domain.OutboxStatusSent && status != domain.OutboxStatusDead {
+		respondError(w, http.StatusBadRequest, "Invalid status (must be pending, sent or dead)")
+		return
+	}
+
+	limit := 50
+	offset := 0
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 {
+		limit = l
+	}
+	if o, err := strconv.Atoi(r.URL.Query().Get("offset")); err == nil && o >= 0 {
+		offset = o
+	}
+
+	emails, err := h.outboxService.ListByStatus(r.Context(), status, limit, offset)
+	if err != nil {
+		if h.logger != nil {
+			h.logger.Error("action=list_email_outbox outcome=failure status=%s error=%v", status, err)
+		}
+		respondError(w, http.StatusInternalServerError, "Failed to list outbox emails")
+		return
+	}
+
+	stats, err := h.outboxService.Stats(r.Context())
+	if err != nil {
+		if h.logger != nil {
+			h.logger.Error("action=list_email_outbox outcome=failure status=%s error=%v", status, err)
+		}
+		respondError(w, http.StatusInternalServerError, "Failed to count outbox emails")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"emails": emails,
+		"stats":  stats,
+		"status": status,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+// RetryOutboxEmail re-queues a dead-lettered email
+func (h *EmailOutboxHandler) RetryOutboxEmail(w http.ResponseWriter, r *http.Request) {
+	adminID, _ := middleware.GetUserID(r.Context())
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid email ID")
+		return
+	}
+
+	outboxEmail, err := h.outboxService.Retry(r.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrOutboxEmailNotFound):
+			respondError(w, http.StatusNotFound, "Outbox email not found")
+		case errors.Is(err, service.ErrOutboxEmailNotDead):
+			respondError(w, http.StatusConflict, err.Error())
+		default:
+			if h.logger != nil {
+				h.logger.Error("action=retry_outbox_email outcome=failure admin_id=%d email_id=%d error=%v", adminID, id, err)
+			}
+			respondError(w, http.StatusInternalServerError, "Failed to retry email")
+		}
+		return
+	}
+
+	if h.logger != nil {
+		h.logger.Info("action=retry_outbox_email outcome=success admin_id=%d email_id=%d", adminID, id)
+	}
+
+	respondJSON(w, http.StatusOK, outboxEmail)
+}
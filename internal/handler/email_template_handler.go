@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/johnzastrow/actalog/pkg/email"
+	"github.com/johnzastrow/actalog/pkg/logger"
+)
+
+// EmailTemplateHandler handles admin endpoints for previewing email templates
+type EmailTemplateHandler struct {
+	renderer *email.Renderer
+	logger   *logger.Logger
+}
+
+// NewEmailTemplateHandler creates a new email template handler
+func NewEmailTemplateHandler(renderer *email.Renderer, logger *logger.Logger) *EmailTemplateHandler {
+	return &EmailTemplateHandler{
+		renderer: renderer,
+		logger:   logger,
+	}
+}
+
+// ListEmailTemplates lists the available email templates and their locales
+func (h *EmailTemplateHandler) ListEmailTemplates(w http.ResponseWriter, r *http.Request) {
+	templates, err := h.renderer.Templates()
+	if err != nil {
+		if h.logger != nil {
+			h.logger.Error("action=list_email_templates outcome=failure error=%v", err)
+		}
+		respondError(w, http.StatusInternalServerError, "Failed to list email templates")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"templates": templates,
+	})
+}
+
+// PreviewEmailTemplate renders a template with sample data.
+// Query params: locale (default "en"), format ("json" (default), "html" or "text").
+func (h *EmailTemplateHandler) PreviewEmailTemplate(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	locale := r.URL.Query().Get("locale")
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "html" && format != "text" {
+		respondError(w, http.StatusBadRequest, "Invalid format (must be json, html or text)")
+		return
+	}
+
+	rendered, err := h.renderer.Preview(name, locale)
+	if err != nil {
+		if errors.Is(err, email.ErrTemplateNotFound) {
+			respondError(w, http.StatusNotFound, "Email template not found")
+			return
+		}
+		if h.logger != nil {
+			h.logger.Error("action=preview_email_template outcome=failure template=%s locale=%s error=%v", name, locale, err)
+		}
+		respondErrorWithDetail(w, http.StatusInternalServerError, "Failed to render email template", err.Error())
+		return
+	}
+
+	switch format {
+	case "html":
+		if rendered.HTML == "" {
+			respondError(w, http.StatusNotFound, "Email template has no HTML version")
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(rendered.HTML))
+	case "text":
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(rendered.Text))
+	default:
+		respondJSON(w, http.StatusOK, rendered)
+	}
+}
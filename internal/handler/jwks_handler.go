@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/johnzastrow/actalog/pkg/auth"
+)
+
+// JWKSHandler publishes the public keys that verify access tokens
+type JWKSHandler struct {
+	keys *auth.KeySet
+}
+
+// NewJWKSHandler creates a new JWKS handler
+func NewJWKSHandler(keys *auth.KeySet) *JWKSHandler {
+	return &JWKSHandler{keys: keys}
+}
+
+// JWKS returns the JSON Web Key Set. It is empty when tokens are signed with
+// the shared HS256 secret.
+func (h *JWKSHandler) JWKS(w http.ResponseWriter, r *http.Request) {
+	// New keys are published well before they sign anything, so verifiers
+	// can cache the set for a few minutes
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	respondJSON(w, http.StatusOK, h.keys.JWKS())
+}
@@ -0,0 +1,205 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/johnzastrow/actalog/internal/service"
+	"github.com/johnzastrow/actalog/pkg/logger"
+	"github.com/johnzastrow/actalog/pkg/middleware"
+)
+
+// MFAHandler handles two-factor authentication enrollment endpoints
+type MFAHandler struct {
+	mfaService *service.MFAService
+	logger     *logger.Logger
+}
+
+// NewMFAHandler creates a new MFA handler
+func NewMFAHandler(mfaService *service.MFAService, logger *logger.Logger) *MFAHandler {
+	return &MFAHandler{
+		mfaService: mfaService,
+		logger:     logger,
+	}
+}
+
+// MFACodeRequest carries a TOTP or recovery code
+type MFACodeRequest struct {
+	Code string `json:"code"`
+}
+
+// DisableMFARequest represents a request to turn off two-factor authentication
+type DisableMFARequest struct {
+	Password string `json:"password"`
+	Code     string `json:"code"`
+}
+
+// RecoveryCodesResponse lists newly generated recovery codes
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// GetMFAStatus returns the user's two-factor authentication status
+func (h *MFAHandler) GetMFAStatus(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	status, err := h.mfaService.Status(r.Context(), userID)
+	if err != nil {
+		if h.logger != nil {
+			h.logger.Error("action=get_mfa_status outcome=failure user_id=%d error=%v", userID, err)
+		}
+		respondError(w, http.StatusInternalServerError, "Failed to get two-factor status")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, status)
+}
+
+// EnrollTOTP starts authenticator app enrollment and returns the secret and
+// otpauth:// provisioning URI to show as a QR code
+func (h *MFAHandler) EnrollTOTP(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	setup, err := h.mfaService.BeginTOTPEnrollment(r.Context(), userID)
+	if err != nil {
+		if errors.Is(err, service.ErrMFAAlreadyEnabled) {
+			respondError(w, http.StatusConflict, err.Error())
+			return
+		}
+		if h.logger != nil {
+			h.logger.Error("action=enroll_totp outcome=failure user_id=%d error=%v", userID, err)
+		}
+		respondError(w, http.StatusInternalServerError, "Failed to start two-factor enrollment")
+		return
+	}
+
+	if h.logger != nil {
+		h.logger.Info("action=enroll_totp outcome=started user_id=%d", userID)
+	}
+
+	respondJSON(w, http.StatusOK, setup)
+}
+
+// ConfirmTOTP enables two-factor authentication with a code from the
+// authenticator app and returns the recovery codes
+func (h *MFAHandler) ConfirmTOTP(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req MFACodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondErrorWithDetail(w, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	codes, err := h.mfaService.ConfirmTOTPEnrollment(r.Context(), userID, req.Code)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidMFACode):
+			respondError(w, http.StatusBadRequest, "Invalid authentication code")
+		case errors.Is(err, service.ErrMFANotEnrolled):
+			respondError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, service.ErrMFAAlreadyEnabled):
+			respondError(w, http.StatusConflict, err.Error())
+		default:
+			if h.logger != nil {
+				h.logger.Error("action=confirm_totp outcome=failure user_id=%d error=%v", userID, err)
+			}
+			respondError(w, http.StatusInternalServerError, "Failed to enable two-factor authentication")
+		}
+		return
+	}
+
+	if h.logger != nil {
+		h.logger.Info("action=confirm_totp outcome=success user_id=%d", userID)
+	}
+
+	respondJSON(w, http.StatusOK, RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// RegenerateRecoveryCodes replaces the user's recovery codes
+func (h *MFAHandler) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req MFACodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondErrorWithDetail(w, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	codes, err := h.mfaService.RegenerateRecoveryCodes(r.Context(), userID, req.Code)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidMFACode):
+			respondError(w, http.StatusBadRequest, "Invalid authentication code")
+		case errors.Is(err, service.ErrMFANotEnabled):
+			respondError(w, http.StatusBadRequest, err.Error())
+		default:
+			if h.logger != nil {
+				h.logger.Error("action=regenerate_recovery_codes outcome=failure user_id=%d error=%v", userID, err)
+			}
+			respondError(w, http.StatusInternalServerError, "Failed to regenerate recovery codes")
+		}
+		return
+	}
+
+	if h.logger != nil {
+		h.logger.Info("action=regenerate_recovery_codes outcome=success user_id=%d", userID)
+	}
+
+	respondJSON(w, http.StatusOK, RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// DisableMFA turns off two-factor authentication
+func (h *MFAHandler) DisableMFA(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req DisableMFARequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondErrorWithDetail(w, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	if err := h.mfaService.Disable(r.Context(), userID, req.Password, req.Code); err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidCredentials):
+			respondError(w, http.StatusUnauthorized, "Invalid password")
+		case errors.Is(err, service.ErrInvalidMFACode):
+			respondError(w, http.StatusBadRequest, "Invalid authentication code")
+		case errors.Is(err, service.ErrMFANotEnabled):
+			respondError(w, http.StatusBadRequest, err.Error())
+		default:
+			if h.logger != nil {
+				h.logger.Error("action=disable_mfa outcome=failure user_id=%d error=%v", userID, err)
+			}
+			respondError(w, http.StatusInternalServerError, "Failed to disable two-factor authentication")
+		}
+		return
+	}
+
+	if h.logger != nil {
+		h.logger.Info("action=disable_mfa outcome=success user_id=%d", userID)
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Two-factor authentication disabled successfully"})
+}
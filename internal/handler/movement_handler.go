@@ -2,11 +2,13 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/johnzastrow/actalog/internal/domain"
+	"github.com/johnzastrow/actalog/internal/policy"
 	"github.com/johnzastrow/actalog/pkg/logger"
 )
 
@@ -26,7 +28,7 @@ func NewMovementHandler(movementRepo domain.MovementRepository, l *logger.Logger
 
 // ListAll returns all movements (both standard and custom)
 func (h *MovementHandler) ListAll(w http.ResponseWriter, r *http.Request) {
-	movements, err := h.movementRepo.ListAll()
+	movements, err := h.movementRepo.ListAll(r.Context())
 	if err != nil {
 		if h.logger != nil {
 			h.logger.Error("action=list_all_movements outcome=failure error=%v", err)
@@ -42,7 +44,7 @@ func (h *MovementHandler) ListAll(w http.ResponseWriter, r *http.Request) {
 
 // ListStandard returns all standard movements
 func (h *MovementHandler) ListStandard(w http.ResponseWriter, r *http.Request) {
-	movements, err := h.movementRepo.ListStandard()
+	movements, err := h.movementRepo.ListStandard(r.Context())
 	if err != nil {
 		if h.logger != nil {
 			h.logger.Error("action=list_movements outcome=failure error=%v", err)
@@ -76,7 +78,7 @@ func (h *MovementHandler) Search(w http.ResponseWriter, r *http.Request) {
 		h.logger.Info("action=search_movements query=%s limit=%d", query, limit)
 	}
 
-	movements, err := h.movementRepo.Search(query, limit)
+	movements, err := h.movementRepo.Search(r.Context(), query, limit)
 	if err != nil {
 		if h.logger != nil {
 			h.logger.Error("action=search_movements outcome=failure query=%s error=%v", query, err)
@@ -99,7 +101,7 @@ func (h *MovementHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	movement, err := h.movementRepo.GetByID(id)
+	movement, err := h.movementRepo.GetByID(r.Context(), id)
 	if err != nil {
 		if h.logger != nil {
 			h.logger.Error("action=get_movement outcome=failure id=%d error=%v", id, err)
@@ -134,20 +136,25 @@ func (h *MovementHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// TODO: Get user ID from context when auth middleware is added
-	// For now, custom movements without user ID
+	subject := policy.SubjectFromContext(r.Context())
+	if !subject.Authenticated() {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
 	movement := &domain.Movement{
 		Name:        req.Name,
 		Description: req.Description,
 		Type:        domain.MovementType(req.Type),
 		IsStandard:  false,
+		CreatedBy:   &subject.UserID,
 	}
 
 	if h.logger != nil {
 		h.logger.Info("action=create_movement_attempt name=%s type=%s", req.Name, req.Type)
 	}
 
-	if err := h.movementRepo.Create(movement); err != nil {
+	if err := h.movementRepo.Create(r.Context(), movement); err != nil {
 		if h.logger != nil {
 			h.logger.Error("action=create_movement outcome=failure name=%s error=%v", req.Name, err)
 		}
@@ -187,19 +194,19 @@ func (h *MovementHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	movement := &domain.Movement{
-		ID:          id,
-		Name:        req.Name,
-		Description: req.Description,
-		Type:        domain.MovementType(req.Type),
-		IsStandard:  false,
-	}
-
 	if h.logger != nil {
 		h.logger.Info("action=update_movement_attempt id=%d name=%s", id, req.Name)
 	}
 
-	if err := h.movementRepo.Update(movement); err != nil {
+	movement, ok := h.authorizedMovement(w, r, "update_movement", id, policy.ActionUpdate)
+	if !ok {
+		return
+	}
+	movement.Name = req.Name
+	movement.Description = req.Description
+	movement.Type = domain.MovementType(req.Type)
+
+	if err := h.movementRepo.Update(r.Context(), movement); err != nil {
 		if h.logger != nil {
 			h.logger.Error("action=update_movement outcome=failure id=%d error=%v", id, err)
 		}
@@ -227,7 +234,11 @@ func (h *MovementHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		h.logger.Info("action=delete_movement_attempt id=%d", id)
 	}
 
-	if err := h.movementRepo.Delete(id); err != nil {
+	if _, ok := h.authorizedMovement(w, r, "delete_movement", id, policy.ActionDelete); !ok {
+		return
+	}
+
+	if err := h.movementRepo.Delete(r.Context(), id); err != nil {
 		if h.logger != nil {
 			h.logger.Error("action=delete_movement outcome=failure id=%d error=%v", id, err)
 		}
@@ -243,3 +254,37 @@ func (h *MovementHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		"message": "Movement deleted successfully",
 	})
 }
+
+// authorizedMovement loads a movement and checks the caller may perform the
+// action on it. Only the creator (or an admin) may change a custom movement,
+// and nobody may change a standard one. It responds and returns false if the
+// movement is missing or the caller isn't allowed.
+func (h *MovementHandler) authorizedMovement(w http.ResponseWriter, r *http.Request, action string, id int64, policyAction policy.Action) (*domain.Movement, bool) {
+	movement, err := h.movementRepo.GetByID(r.Context(), id)
+	if err != nil {
+		if h.logger != nil {
+			h.logger.Error("action=%s outcome=failure id=%d error=%v", action, id, err)
+		}
+		respondError(w, http.StatusInternalServerError, "Failed to retrieve movement")
+		return nil, false
+	}
+	if movement == nil {
+		respondError(w, http.StatusNotFound, "Movement not found")
+		return nil, false
+	}
+
+	subject := policy.SubjectFromContext(r.Context())
+	if err := policy.Authorize(subject, policyAction, policy.Movement(movement)); err != nil {
+		if h.logger != nil {
+			h.logger.Warn("action=%s outcome=failure id=%d user_id=%d reason=forbidden", action, id, subject.UserID)
+		}
+		if errors.Is(err, policy.ErrSharedResource) {
+			respondError(w, http.StatusForbidden, "Standard movements can't be changed")
+		} else {
+			respondError(w, http.StatusForbidden, "You don't have permission to change this movement")
+		}
+		return nil, false
+	}
+
+	return movement, true
+}
@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/johnzastrow/actalog/internal/service"
+	"github.com/johnzastrow/actalog/pkg/logger"
+	"github.com/johnzastrow/actalog/pkg/middleware"
+)
+
+// NotificationHandler handles the in-app notification center endpoints
+type NotificationHandler struct {
+	notificationService *service.NotificationService
+	logger              *logger.Logger
+}
+
+// NewNotificationHandler creates a new notification handler
+func NewNotificationHandler(notificationService *service.NotificationService, logger *logger.Logger) *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: notificationService,
+		logger:              logger,
+	}
+}
+
+// ListNotifications lists the user's notifications, newest first, with the unread count.
+// Pass unread=true to list only unread notifications.
+func (h *NotificationHandler) ListNotifications(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	limit := 20
+	offset := 0
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 {
+		limit = l
+	}
+	if o, err := strconv.Atoi(r.URL.Query().Get("offset")); err == nil && o >= 0 {
+		offset = o
+	}
+	unreadOnly := r.URL.Query().Get("unread") == "true"
+
+	notifications, err := h.notificationService.List(r.Context(), userID, unreadOnly, limit, offset)
+	if err != nil {
+		if h.logger != nil {
+			h.logger.Error("action=list_notifications outcome=failure user_id=%d error=%v", userID, err)
+		}
+		respondError(w, http.StatusInternalServerError, "Failed to list notifications")
+		return
+	}
+
+	unread, err := h.notificationService.UnreadCount(r.Context(), userID)
+	if err != nil {
+		if h.logger != nil {
+			h.logger.Error("action=list_notifications outcome=failure user_id=%d error=%v", userID, err)
+		}
+		respondError(w, http.StatusInternalServerError, "Failed to count notifications")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"notifications": notifications,
+		"unread_count":  unread,
+		"limit":         limit,
+		"offset":        offset,
+	})
+}
+
+// GetUnreadCount returns the number of unread notifications
+func (h *NotificationHandler) GetUnreadCount(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	unread, err := h.notificationService.UnreadCount(r.Context(), userID)
+	if err != nil {
+		if h.logger != nil {
+			h.logger.Error("action=count_notifications outcome=failure user_id=%d error=%v", userID, err)
+		}
+		respondError(w, http.StatusInternalServerError, "Failed to count notifications")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]int{"unread_count": unread})
+}
+
+// MarkNotificationRead marks a notification read
+func (h *NotificationHandler) MarkNotificationRead(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid notification ID")
+		return
+	}
+
+	if err := h.notificationService.MarkRead(r.Context(), id, userID); err != nil {
+		if errors.Is(err, service.ErrNotificationNotFound) {
+			respondError(w, http.StatusNotFound, "Notification not found")
+			return
+		}
+		if h.logger != nil {
+			h.logger.Error("action=mark_notification_read outcome=failure user_id=%d notification_id=%d error=%v", userID, id, err)
+		}
+		respondError(w, http.StatusInternalServerError, "Failed to mark notification read")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Notification marked read successfully"})
+}
+
+// MarkAllNotificationsRead marks all of the user's notifications read
+func (h *NotificationHandler) MarkAllNotificationsRead(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	count, err := h.notificationService.MarkAllRead(r.Context(), userID)
+	if err != nil {
+		if h.logger != nil {
+			h.logger.Error("action=mark_all_notifications_read outcome=failure user_id=%d error=%v", userID, err)
+		}
+		respondError(w, http.StatusInternalServerError, "Failed to mark notifications read")
+		return
+	}
+
+	if h.logger != nil {
+		h.logger.Info("action=mark_all_notifications_read outcome=success user_id=%d count=%d", userID, count)
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"message": "Notifications marked read successfully",
+		"count":   count,
+	})
+}
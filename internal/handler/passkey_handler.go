@@ -0,0 +1,200 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/johnzastrow/actalog/internal/service"
+	"github.com/johnzastrow/actalog/pkg/logger"
+	"github.com/johnzastrow/actalog/pkg/middleware"
+	"github.com/johnzastrow/actalog/pkg/webauthn"
+)
+
+// PasskeyHandler handles passkey registration and management endpoints
+type PasskeyHandler struct {
+	passkeyService *service.PasskeyService
+	logger         *logger.Logger
+}
+
+// NewPasskeyHandler creates a new passkey handler
+func NewPasskeyHandler(passkeyService *service.PasskeyService, logger *logger.Logger) *PasskeyHandler {
+	return &PasskeyHandler{
+		passkeyService: passkeyService,
+		logger:         logger,
+	}
+}
+
+// RegisterPasskeyRequest completes passkey registration
+type RegisterPasskeyRequest struct {
+	Name       string                         `json:"name"` // e.g. "Gym tablet"; defaults to "Passkey"
+	Credential *webauthn.RegistrationResponse `json:"credential"`
+}
+
+// RenamePasskeyRequest renames a passkey
+type RenamePasskeyRequest struct {
+	Name string `json:"name"`
+}
+
+// ListPasskeys returns the user's passkeys
+func (h *PasskeyHandler) ListPasskeys(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	passkeys, err := h.passkeyService.List(r.Context(), userID)
+	if err != nil {
+		if h.logger != nil {
+			h.logger.Error("action=list_passkeys outcome=failure user_id=%d error=%v", userID, err)
+		}
+		respondError(w, http.StatusInternalServerError, "Failed to list passkeys")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, passkeys)
+}
+
+// BeginRegistration returns options for navigator.credentials.create()
+func (h *PasskeyHandler) BeginRegistration(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	options, err := h.passkeyService.BeginRegistration(r.Context(), userID)
+	if err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			respondError(w, http.StatusNotFound, "User not found")
+			return
+		}
+		if h.logger != nil {
+			h.logger.Error("action=begin_passkey_registration outcome=failure user_id=%d error=%v", userID, err)
+		}
+		respondError(w, http.StatusInternalServerError, "Failed to start passkey registration")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, options)
+}
+
+// FinishRegistration verifies the authenticator's response and stores the passkey
+func (h *PasskeyHandler) FinishRegistration(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req RegisterPasskeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondErrorWithDetail(w, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+	if req.Credential == nil {
+		respondError(w, http.StatusBadRequest, "Credential is required")
+		return
+	}
+
+	passkey, err := h.passkeyService.FinishRegistration(r.Context(), userID, req.Name, req.Credential)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidPasskeyName), errors.Is(err, service.ErrInvalidPasskeyChallenge):
+			respondError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, service.ErrInvalidPasskey):
+			if h.logger != nil {
+				h.logger.Warn("action=register_passkey outcome=failure user_id=%d error=%v", userID, err)
+			}
+			respondErrorWithDetail(w, http.StatusBadRequest, "Passkey verification failed", err.Error())
+		case errors.Is(err, service.ErrPasskeyAlreadyRegistered):
+			respondError(w, http.StatusConflict, err.Error())
+		default:
+			if h.logger != nil {
+				h.logger.Error("action=register_passkey outcome=failure user_id=%d error=%v", userID, err)
+			}
+			respondError(w, http.StatusInternalServerError, "Failed to register passkey")
+		}
+		return
+	}
+
+	if h.logger != nil {
+		h.logger.Info("action=register_passkey outcome=success user_id=%d passkey_id=%d", userID, passkey.ID)
+	}
+
+	respondJSON(w, http.StatusCreated, passkey)
+}
+
+// RenamePasskey changes a passkey's name
+func (h *PasskeyHandler) RenamePasskey(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid passkey ID")
+		return
+	}
+
+	var req RenamePasskeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondErrorWithDetail(w, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	if err := h.passkeyService.Rename(r.Context(), id, userID, req.Name); err != nil {
+		switch {
+		case errors.Is(err, service.ErrPasskeyNotFound):
+			respondError(w, http.StatusNotFound, "Passkey not found")
+		case errors.Is(err, service.ErrInvalidPasskeyName):
+			respondError(w, http.StatusBadRequest, err.Error())
+		default:
+			if h.logger != nil {
+				h.logger.Error("action=rename_passkey outcome=failure user_id=%d passkey_id=%d error=%v", userID, id, err)
+			}
+			respondError(w, http.StatusInternalServerError, "Failed to rename passkey")
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Passkey renamed successfully"})
+}
+
+// DeletePasskey removes a passkey
+func (h *PasskeyHandler) DeletePasskey(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid passkey ID")
+		return
+	}
+
+	if err := h.passkeyService.Delete(r.Context(), id, userID); err != nil {
+		if errors.Is(err, service.ErrPasskeyNotFound) {
+			respondError(w, http.StatusNotFound, "Passkey not found")
+			return
+		}
+		if h.logger != nil {
+			h.logger.Error("action=delete_passkey outcome=failure user_id=%d passkey_id=%d error=%v", userID, id, err)
+		}
+		respondError(w, http.StatusInternalServerError, "Failed to delete passkey")
+		return
+	}
+
+	if h.logger != nil {
+		h.logger.Info("action=delete_passkey outcome=success user_id=%d passkey_id=%d", userID, id)
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Passkey deleted successfully"})
+}
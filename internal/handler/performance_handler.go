@@ -3,19 +3,25 @@ package handler
 import (
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/johnzastrow/actalog/internal/domain"
 	"github.com/johnzastrow/actalog/internal/repository"
 	"github.com/johnzastrow/actalog/pkg/logger"
 	"github.com/johnzastrow/actalog/pkg/middleware"
 )
 
+// maxSearchResults caps how many results a search returns
+const maxSearchResults = 50
+
 // PerformanceHandler handles performance tracking endpoints
 type PerformanceHandler struct {
 	movementRepo            *repository.MovementRepository
 	wodRepo                 *repository.WODRepository
 	userWorkoutMovementRepo *repository.UserWorkoutMovementRepository
 	userWorkoutWODRepo      *repository.UserWorkoutWODRepository
+	searchRepo              *repository.SearchRepository
 	logger                  *logger.Logger
 }
 
@@ -25,6 +31,7 @@ func NewPerformanceHandler(
 	wodRepo *repository.WODRepository,
 	userWorkoutMovementRepo *repository.UserWorkoutMovementRepository,
 	userWorkoutWODRepo      *repository.UserWorkoutWODRepository,
+	searchRepo *repository.SearchRepository,
 	logger *logger.Logger,
 ) *PerformanceHandler {
 	return &PerformanceHandler{
@@ -32,11 +39,14 @@ func NewPerformanceHandler(
 		wodRepo:                 wodRepo,
 		userWorkoutMovementRepo: userWorkoutMovementRepo,
 		userWorkoutWODRepo:      userWorkoutWODRepo,
+		searchRepo:              searchRepo,
 		logger:                  logger,
 	}
 }
 
-// UnifiedSearch searches both movements and WODs
+// UnifiedSearch searches the text of movements, WODs and the user's logged
+// workouts, returning ranked results with the matching words highlighted.
+// The types parameter narrows it to some of "movement", "wod" and "workout".
 func (h *PerformanceHandler) UnifiedSearch(w http.ResponseWriter, r *http.Request) {
 	// Extract user ID from context (for authorization)
 	userID, ok := middleware.GetUserID(r.Context())
@@ -58,61 +68,39 @@ func (h *PerformanceHandler) UnifiedSearch(w http.ResponseWriter, r *http.Reques
 			limit = l
 		}
 	}
-
-	if h.logger != nil {
-		h.logger.Info("action=unified_search user_id=%d query=%s limit=%d", userID, query, limit)
+	if limit > maxSearchResults {
+		limit = maxSearchResults
+	}
+
+	var types []string
+	if typesStr := r.URL.Query().Get("types"); typesStr != "" {
+		for _, t := range strings.Split(typesStr, ",") {
+			t = strings.TrimSpace(t)
+			switch t {
+			case domain.SearchTypeMovement, domain.SearchTypeWOD, domain.SearchTypeWorkout:
+				types = append(types, t)
+			default:
+				respondError(w, http.StatusBadRequest, "Invalid search type: "+t)
+				return
+			}
+		}
 	}
 
-	// Search movements
-	movements, err := h.movementRepo.Search(query, limit)
-	if err != nil {
-		if h.logger != nil {
-			h.logger.Error("action=unified_search outcome=failure user_id=%d error=%v", userID, err)
-		}
-		respondError(w, http.StatusInternalServerError, "Failed to search movements")
-		return
+	if h.logger != nil {
+		h.logger.Info("action=unified_search user_id=%d query=%s limit=%d types=%v", userID, query, limit, types)
 	}
 
-	// Search WODs
-	wods, err := h.wodRepo.Search(query, limit)
+	results, err := h.searchRepo.Search(r.Context(), userID, query, types, limit)
 	if err != nil {
 		if h.logger != nil {
 			h.logger.Error("action=unified_search outcome=failure user_id=%d error=%v", userID, err)
 		}
-		respondError(w, http.StatusInternalServerError, "Failed to search WODs")
+		respondError(w, http.StatusInternalServerError, "Failed to search")
 		return
 	}
 
-	// Format results
-	type SearchResult struct {
-		Type string      `json:"type"` // "movement" or "wod"
-		ID   int64       `json:"id"`
-		Name string      `json:"name"`
-		Data interface{} `json:"data"` // Full movement or WOD object
-	}
-
-	results := []SearchResult{}
-
-	for _, m := range movements {
-		results = append(results, SearchResult{
-			Type: "movement",
-			ID:   m.ID,
-			Name: m.Name,
-			Data: m,
-		})
-	}
-
-	for _, w := range wods {
-		results = append(results, SearchResult{
-			Type: "wod",
-			ID:   w.ID,
-			Name: w.Name,
-			Data: w,
-		})
-	}
-
 	if h.logger != nil {
-		h.logger.Info("action=unified_search outcome=success user_id=%d movements=%d wods=%d", userID, len(movements), len(wods))
+		h.logger.Info("action=unified_search outcome=success user_id=%d results=%d", userID, len(results))
 	}
 
 	respondJSON(w, http.StatusOK, map[string]interface{}{
@@ -141,7 +129,7 @@ func (h *PerformanceHandler) GetMovementPerformance(w http.ResponseWriter, r *ht
 	}
 
 	// Get all performance records for this movement
-	performances, err := h.userWorkoutMovementRepo.GetByUserIDAndMovementID(userID, movementID, 1000)
+	performances, err := h.userWorkoutMovementRepo.GetByUserIDAndMovementID(r.Context(), userID, movementID, 1000)
 	if err != nil {
 		if h.logger != nil {
 			h.logger.Error("action=get_movement_performance outcome=failure user_id=%d error=%v", userID, err)
@@ -180,7 +168,7 @@ func (h *PerformanceHandler) GetWODPerformance(w http.ResponseWriter, r *http.Re
 	}
 
 	// Get all performance records for this WOD
-	performances, err := h.userWorkoutWODRepo.GetByUserIDAndWODID(userID, wodID, 1000)
+	performances, err := h.userWorkoutWODRepo.GetByUserIDAndWODID(r.Context(), userID, wodID, 1000)
 	if err != nil {
 		if h.logger != nil {
 			h.logger.Error("action=get_wod_performance outcome=failure user_id=%d error=%v", userID, err)
@@ -2,9 +2,13 @@ package handler
 
 import (
 	"database/sql"
+	"errors"
 	"net/http"
 	"strconv"
 
+	"github.com/johnzastrow/actalog/internal/domain"
+	"github.com/johnzastrow/actalog/internal/policy"
+	"github.com/johnzastrow/actalog/internal/repository"
 	"github.com/johnzastrow/actalog/pkg/logger"
 	"github.com/johnzastrow/actalog/pkg/middleware"
 	"github.com/johnzastrow/actalog/pkg/prmath"
@@ -12,15 +16,19 @@ import (
 
 // PRHandler handles PR (Personal Record) endpoints
 type PRHandler struct {
-	db     *sql.DB
-	logger *logger.Logger
+	db                  *repository.DB
+	workoutMovementRepo domain.WorkoutMovementRepository
+	workoutRepo         domain.WorkoutRepository
+	logger              *logger.Logger
 }
 
 // NewPRHandler creates a new PR handler
-func NewPRHandler(db *sql.DB, l *logger.Logger) *PRHandler {
+func NewPRHandler(db *sql.DB, workoutMovementRepo domain.WorkoutMovementRepository, workoutRepo domain.WorkoutRepository, l *logger.Logger) *PRHandler {
 	return &PRHandler{
-		db:     db,
-		logger: l,
+		db:                  repository.NewDB(db),
+		workoutMovementRepo: workoutMovementRepo,
+		workoutRepo:         workoutRepo,
+		logger:              l,
 	}
 }
 
@@ -105,7 +113,7 @@ func (h *PRHandler) GetPersonalRecords(w http.ResponseWriter, r *http.Request) {
 		JOIN workouts w ON wm.workout_id = w.id
 		JOIN user_workouts uw ON uw.workout_id = w.id
 		JOIN movements m ON wm.movement_id = m.id
-		WHERE uw.user_id = ? AND wm.is_pr = 1
+		WHERE uw.user_id = ? AND wm.is_pr = ?
 		ORDER BY uw.workout_date DESC
 		LIMIT ?
 	`
@@ -132,7 +140,7 @@ func (h *PRHandler) GetPersonalRecords(w http.ResponseWriter, r *http.Request) {
 		JOIN workouts w ON ww.workout_id = w.id
 		JOIN user_workouts uw ON uw.workout_id = w.id
 		JOIN wods wod ON ww.wod_id = wod.id
-		WHERE uw.user_id = ? AND ww.is_pr = 1
+		WHERE uw.user_id = ? AND ww.is_pr = ?
 		ORDER BY uw.workout_date DESC
 		LIMIT ?
 	`
@@ -141,7 +149,7 @@ func (h *PRHandler) GetPersonalRecords(w http.ResponseWriter, r *http.Request) {
 	var prs []PersonalRecord
 
 	// Get movement PRs
-	movementRows, err := h.db.Query(movementQuery, userID, limit)
+	movementRows, err := h.db.QueryContext(r.Context(), movementQuery, userID, true, limit)
 	if err != nil {
 		if h.logger != nil {
 			h.logger.Error("action=get_prs outcome=failure user_id=%d error=query_movements: %v", userID, err)
@@ -189,7 +197,7 @@ func (h *PRHandler) GetPersonalRecords(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get WOD PRs
-	wodRows, err := h.db.Query(wodQuery, userID, limit)
+	wodRows, err := h.db.QueryContext(r.Context(), wodQuery, userID, true, limit)
 	if err != nil {
 		if h.logger != nil {
 			h.logger.Error("action=get_prs outcome=failure user_id=%d error=query_wods: %v", userID, err)
@@ -274,13 +282,13 @@ func (h *PRHandler) GetPRMovements(w http.ResponseWriter, r *http.Request) {
 		JOIN workouts w ON wm.workout_id = w.id
 		JOIN user_workouts uw ON uw.workout_id = w.id
 		JOIN movements m ON wm.movement_id = m.id
-		WHERE uw.user_id = ? AND wm.is_pr = 1
+		WHERE uw.user_id = ? AND wm.is_pr = ?
 		GROUP BY m.id, m.name, m.type
 		ORDER BY pr_count DESC, last_pr_date DESC
 		LIMIT ?
 	`
 
-	rows, err := h.db.Query(query, userID, limit)
+	rows, err := h.db.QueryContext(r.Context(), query, userID, true, limit)
 	if err != nil {
 		if h.logger != nil {
 			h.logger.Error("action=get_pr_movements outcome=failure user_id=%d error=%v", userID, err)
@@ -355,65 +363,56 @@ func (h *PRHandler) ToggleMovementPR(w http.ResponseWriter, r *http.Request) {
 		h.logger.Info("action=toggle_movement_pr_attempt user_id=%d movement_id=%d", userID, movementID)
 	}
 
-	// Verify user owns this workout
-	verifyQuery := `
-		SELECT 1
-		FROM workout_movements wm
-		JOIN workouts w ON wm.workout_id = w.id
-		JOIN user_workouts uw ON uw.workout_id = w.id
-		WHERE wm.id = ? AND uw.user_id = ?
-	`
-
-	var exists int
-	err = h.db.QueryRow(verifyQuery, movementID, userID).Scan(&exists)
+	wm, err := h.workoutMovementRepo.GetByID(r.Context(), movementID)
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if h.logger != nil {
+			h.logger.Error("action=toggle_movement_pr outcome=failure user_id=%d movement_id=%d error=%v", userID, movementID, err)
+		}
+		respondError(w, http.StatusInternalServerError, "Failed to retrieve movement")
+		return
+	}
+	var workout *domain.Workout
+	if wm != nil {
+		workout, err = h.workoutRepo.GetByID(r.Context(), wm.WorkoutID)
+		if err != nil {
 			if h.logger != nil {
-				h.logger.Warn("action=toggle_movement_pr outcome=failure user_id=%d movement_id=%d reason=not_found", userID, movementID)
+				h.logger.Error("action=toggle_movement_pr outcome=failure user_id=%d movement_id=%d error=%v", userID, movementID, err)
 			}
-			respondError(w, http.StatusNotFound, "Movement not found")
+			respondError(w, http.StatusInternalServerError, "Failed to retrieve workout template")
 			return
 		}
+	}
+	if workout == nil {
 		if h.logger != nil {
-			h.logger.Error("action=toggle_movement_pr outcome=failure user_id=%d movement_id=%d error=%v", userID, movementID, err)
+			h.logger.Warn("action=toggle_movement_pr outcome=failure user_id=%d movement_id=%d reason=not_found", userID, movementID)
 		}
-		respondError(w, http.StatusInternalServerError, "Failed to verify ownership")
+		respondError(w, http.StatusNotFound, "Movement not found")
 		return
 	}
 
-	// Toggle the PR flag
-	toggleQuery := `
-		UPDATE workout_movements
-		SET is_pr = CASE WHEN is_pr = 1 THEN 0 ELSE 1 END,
-		    updated_at = CURRENT_TIMESTAMP
-		WHERE id = ?
-	`
-
-	result, err := h.db.Exec(toggleQuery, movementID)
-	if err != nil {
+	// Only the template's creator (or an admin) can change it
+	if err := policy.Authorize(policy.SubjectFromContext(r.Context()), policy.ActionUpdate, policy.Template(workout)); err != nil {
 		if h.logger != nil {
-			h.logger.Error("action=toggle_movement_pr outcome=failure user_id=%d movement_id=%d error=%v", userID, movementID, err)
+			h.logger.Warn("action=toggle_movement_pr outcome=failure user_id=%d movement_id=%d reason=forbidden", userID, movementID)
+		}
+		if errors.Is(err, policy.ErrSharedResource) {
+			respondError(w, http.StatusForbidden, "Standard templates can't be changed")
+		} else {
+			respondError(w, http.StatusForbidden, "You don't have permission to change this template")
 		}
-		respondError(w, http.StatusInternalServerError, "Failed to toggle PR flag")
-		return
-	}
-
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		respondError(w, http.StatusNotFound, "Movement not found")
 		return
 	}
 
-	// Get the new state
-	var newState bool
-	err = h.db.QueryRow("SELECT is_pr FROM workout_movements WHERE id = ?", movementID).Scan(&newState)
-	if err != nil {
+	// Toggle the PR flag
+	wm.IsPR = !wm.IsPR
+	if err := h.workoutMovementRepo.Update(r.Context(), wm); err != nil {
 		if h.logger != nil {
-			h.logger.Error("action=toggle_movement_pr outcome=failure user_id=%d movement_id=%d error=get_state: %v", userID, movementID, err)
+			h.logger.Error("action=toggle_movement_pr outcome=failure user_id=%d movement_id=%d error=%v", userID, movementID, err)
 		}
-		respondError(w, http.StatusInternalServerError, "Failed to get PR state")
+		respondError(w, http.StatusInternalServerError, "Failed to toggle PR flag")
 		return
 	}
+	newState := wm.IsPR
 
 	if h.logger != nil {
 		h.logger.Info("action=toggle_movement_pr outcome=success user_id=%d movement_id=%d new_state=%t", userID, movementID, newState)
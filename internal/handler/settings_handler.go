@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"net/http"
 
-	"github.com/johnzastrow/actalog/internal/domain"
 	"github.com/johnzastrow/actalog/internal/service"
 	"github.com/johnzastrow/actalog/pkg/logger"
 	"github.com/johnzastrow/actalog/pkg/middleware"
@@ -36,7 +35,7 @@ func (h *SettingsHandler) GetSettings(w http.ResponseWriter, r *http.Request) {
 		h.logger.Info("action=get_settings user_id=%d", userID)
 	}
 
-	settings, err := h.settingsService.GetSettings(userID)
+	settings, err := h.settingsService.GetSettings(r.Context(), userID)
 	if err != nil {
 		if h.logger != nil {
 			h.logger.Error("action=get_settings outcome=failure user_id=%d error=%v", userID, err)
@@ -56,9 +55,19 @@ func (h *SettingsHandler) UpdateSettings(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	var req domain.UserSettings
+	// Decode over the current settings so omitted fields keep their values
+	current, err := h.settingsService.GetSettings(r.Context(), userID)
+	if err != nil {
+		if h.logger != nil {
+			h.logger.Error("action=update_settings outcome=failure user_id=%d error=%v", userID, err)
+		}
+		respondError(w, http.StatusInternalServerError, "Failed to update settings")
+		return
+	}
+
+	req := *current
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		respondError(w, http.StatusBadRequest, "Invalid request body")
+		respondErrorWithDetail(w, http.StatusBadRequest, "Invalid request body", err.Error())
 		return
 	}
 
@@ -66,7 +75,7 @@ func (h *SettingsHandler) UpdateSettings(w http.ResponseWriter, r *http.Request)
 		h.logger.Info("action=update_settings_attempt user_id=%d", userID)
 	}
 
-	settings, err := h.settingsService.UpdateSettings(userID, &req)
+	settings, err := h.settingsService.UpdateSettings(r.Context(), userID, &req)
 	if err != nil {
 		if h.logger != nil {
 			h.logger.Error("action=update_settings outcome=failure user_id=%d error=%v", userID, err)
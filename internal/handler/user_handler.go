@@ -2,14 +2,18 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/go-chi/chi/v5"
+	"github.com/johnzastrow/actalog/internal/domain"
 	"github.com/johnzastrow/actalog/internal/service"
 	"github.com/johnzastrow/actalog/pkg/logger"
 	"github.com/johnzastrow/actalog/pkg/middleware"
@@ -17,15 +21,18 @@ import (
 
 // UserHandler handles user profile endpoints
 type UserHandler struct {
-	userService *service.UserService
-	logger      *logger.Logger
+	userService  *service.UserService
+	auditService *service.AuditService
+	logger       *logger.Logger
 }
 
-// NewUserHandler creates a new user handler
-func NewUserHandler(userService *service.UserService, l *logger.Logger) *UserHandler {
+// NewUserHandler creates a new user handler. auditService may be nil, in
+// which case password changes aren't recorded in the audit log.
+func NewUserHandler(userService *service.UserService, auditService *service.AuditService, l *logger.Logger) *UserHandler {
 	return &UserHandler{
-		userService: userService,
-		logger:      l,
+		userService:  userService,
+		auditService: auditService,
+		logger:       l,
 	}
 }
 
@@ -34,6 +41,7 @@ type UpdateProfileRequest struct {
 	Name     string `json:"name,omitempty"`
 	Email    string `json:"email,omitempty"`
 	Birthday string `json:"birthday,omitempty"` // Format: "YYYY-MM-DD" or empty
+	Locale   string `json:"locale,omitempty"`   // Language for emails, e.g. "en", "es"
 }
 
 // ProfileResponse represents a profile response
@@ -72,9 +80,11 @@ func (h *UserHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Update profile
-	user, err := h.userService.UpdateProfile(userID, req.Name, req.Email, birthday)
+	user, err := h.userService.UpdateProfile(r.Context(), userID, req.Name, req.Email, birthday, req.Locale)
 	if err != nil {
 		switch err {
+		case service.ErrInvalidLocale:
+			respondError(w, http.StatusBadRequest, "Invalid locale")
 		case service.ErrEmailAlreadyExists:
 			if h.logger != nil {
 				h.logger.Warn("action=update_profile outcome=failure user_id=%d reason=email_exists email=%s", userID, req.Email)
@@ -117,7 +127,7 @@ func (h *UserHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get user from service
-	user, err := h.userService.GetByID(userID)
+	user, err := h.userService.GetByID(r.Context(), userID)
 	if err != nil {
 		if err == service.ErrUserNotFound {
 			if h.logger != nil {
@@ -138,6 +148,102 @@ func (h *UserHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// ListIdentities returns the single sign-on accounts linked to the current user
+func (h *UserHandler) ListIdentities(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	identities, err := h.userService.ListIdentities(r.Context(), userID)
+	if err != nil {
+		if h.logger != nil {
+			h.logger.Error("action=list_identities outcome=failure user_id=%d error=%v", userID, err)
+		}
+		respondError(w, http.StatusInternalServerError, "Failed to list linked accounts")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, identities)
+}
+
+// ListSessions returns the devices where the current user is signed in with "remember me"
+func (h *UserHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	sessions, err := h.userService.ListSessions(r.Context(), userID)
+	if err != nil {
+		if h.logger != nil {
+			h.logger.Error("action=list_sessions outcome=failure user_id=%d error=%v", userID, err)
+		}
+		respondError(w, http.StatusInternalServerError, "Failed to list sessions")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, sessions)
+}
+
+// RevokeSession signs the current user out of one session
+func (h *UserHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid session ID")
+		return
+	}
+
+	if err := h.userService.RevokeSession(r.Context(), userID, id); err != nil {
+		if errors.Is(err, service.ErrSessionNotFound) {
+			respondError(w, http.StatusNotFound, "Session not found")
+			return
+		}
+		if h.logger != nil {
+			h.logger.Error("action=revoke_session outcome=failure user_id=%d session_id=%d error=%v", userID, id, err)
+		}
+		respondError(w, http.StatusInternalServerError, "Failed to revoke session")
+		return
+	}
+
+	if h.logger != nil {
+		h.logger.Info("action=revoke_session outcome=success user_id=%d session_id=%d", userID, id)
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Session revoked successfully"})
+}
+
+// RevokeAllSessions signs the current user out everywhere
+func (h *UserHandler) RevokeAllSessions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if err := h.userService.RevokeAllRefreshTokens(r.Context(), userID); err != nil {
+		if h.logger != nil {
+			h.logger.Error("action=revoke_all_sessions outcome=failure user_id=%d error=%v", userID, err)
+		}
+		respondError(w, http.StatusInternalServerError, "Failed to revoke sessions")
+		return
+	}
+
+	if h.logger != nil {
+		h.logger.Info("action=revoke_all_sessions outcome=success user_id=%d", userID)
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "All sessions revoked successfully"})
+}
+
 // UploadAvatar handles avatar image uploads
 func (h *UserHandler) UploadAvatar(w http.ResponseWriter, r *http.Request) {
 	// Get user ID from context
@@ -204,7 +310,7 @@ func (h *UserHandler) UploadAvatar(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get current user to check for old avatar
-	user, err := h.userService.GetByID(userID)
+	user, err := h.userService.GetByID(r.Context(), userID)
 	if err != nil {
 		if h.logger != nil {
 			h.logger.Error("action=upload_avatar outcome=failure user_id=%d error=failed_to_get_user: %v", userID, err)
@@ -230,7 +336,7 @@ func (h *UserHandler) UploadAvatar(w http.ResponseWriter, r *http.Request) {
 	avatarURL := "/uploads/avatars/" + filename
 	user.ProfileImage = &avatarURL
 
-	if err := h.userService.UpdateAvatar(userID, avatarURL); err != nil {
+	if err := h.userService.UpdateAvatar(r.Context(), userID, avatarURL); err != nil {
 		if h.logger != nil {
 			h.logger.Error("action=upload_avatar outcome=failure user_id=%d error=failed_to_update_avatar: %v", userID, err)
 		}
@@ -259,7 +365,7 @@ func (h *UserHandler) DeleteAvatar(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get current user
-	user, err := h.userService.GetByID(userID)
+	user, err := h.userService.GetByID(r.Context(), userID)
 	if err != nil {
 		if h.logger != nil {
 			h.logger.Error("action=delete_avatar outcome=failure user_id=%d error=failed_to_get_user: %v", userID, err)
@@ -282,7 +388,7 @@ func (h *UserHandler) DeleteAvatar(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Update user profile to remove avatar
-	if err := h.userService.UpdateAvatar(userID, ""); err != nil {
+	if err := h.userService.UpdateAvatar(r.Context(), userID, ""); err != nil {
 		if h.logger != nil {
 			h.logger.Error("action=delete_avatar outcome=failure user_id=%d error=failed_to_update_profile: %v", userID, err)
 		}
@@ -333,7 +439,7 @@ func (h *UserHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
 		h.logger.Info("action=change_password_attempt user_id=%d", userID)
 	}
 
-	if err := h.userService.ChangePassword(userID, req.OldPassword, req.NewPassword); err != nil {
+	if err := h.userService.ChangePassword(r.Context(), userID, req.OldPassword, req.NewPassword); err != nil {
 		if err == service.ErrInvalidCredentials {
 			if h.logger != nil {
 				h.logger.Warn("action=change_password outcome=failure user_id=%d reason=invalid_old_password", userID)
@@ -351,6 +457,11 @@ func (h *UserHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
 	if h.logger != nil {
 		h.logger.Info("action=change_password outcome=success user_id=%d", userID)
 	}
+	recordAudit(h.auditService, h.logger, r, &domain.AuditEntry{
+		Action:     domain.AuditPasswordChanged,
+		TargetType: domain.AuditTargetUser,
+		TargetID:   &userID,
+	})
 
 	respondJSON(w, http.StatusOK, map[string]string{
 		"message": "Password changed successfully",
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
@@ -13,6 +14,9 @@ import (
 	"github.com/johnzastrow/actalog/pkg/middleware"
 )
 
+// maxWorkoutPageSize caps how many workouts a page of the history holds
+const maxWorkoutPageSize = 100
+
 // UserWorkoutHandler handles logging workout instances
 type UserWorkoutHandler struct {
 	userWorkoutService *service.UserWorkoutService
@@ -178,13 +182,14 @@ func (h *UserWorkoutHandler) LogWorkout(w http.ResponseWriter, r *http.Request)
 
 		// Log workout with performance data
 		userWorkout, err = h.userWorkoutService.LogWorkoutWithPerformance(
+			r.Context(),
 			userID, req.WorkoutID, req.WorkoutName, workoutDate,
 			req.Notes, req.TotalTime, req.WorkoutType,
 			movements, wods,
 		)
 	} else {
 		// Log workout without performance data
-		userWorkout, err = h.userWorkoutService.LogWorkout(userID, req.WorkoutID, req.WorkoutName, workoutDate, req.Notes, req.TotalTime, req.WorkoutType)
+		userWorkout, err = h.userWorkoutService.LogWorkout(r.Context(), userID, req.WorkoutID, req.WorkoutName, workoutDate, req.Notes, req.TotalTime, req.WorkoutType)
 	}
 
 	if err != nil {
@@ -196,7 +201,7 @@ func (h *UserWorkoutHandler) LogWorkout(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Retrieve logged workout with details
-	logged, err := h.userWorkoutService.GetLoggedWorkout(userWorkout.ID, userID)
+	logged, err := h.userWorkoutService.GetLoggedWorkout(r.Context(), userWorkout.ID, userID)
 	if err != nil {
 		if h.logger != nil {
 			h.logger.Error("action=log_workout outcome=failure user_id=%d workout_id=%d error=retrieval_failed %v", userID, req.WorkoutID, err)
@@ -250,7 +255,7 @@ func (h *UserWorkoutHandler) GetLoggedWorkout(w http.ResponseWriter, r *http.Req
 		h.logger.Info("action=get_workout user_id=%d workout_id=%d", userID, id)
 	}
 
-	logged, err := h.userWorkoutService.GetLoggedWorkout(id, userID)
+	logged, err := h.userWorkoutService.GetLoggedWorkout(r.Context(), id, userID)
 	if err != nil {
 		if h.logger != nil {
 			h.logger.Error("action=get_workout outcome=failure user_id=%d workout_id=%d error=%v", userID, id, err)
@@ -280,7 +285,12 @@ func (h *UserWorkoutHandler) GetLoggedWorkout(w http.ResponseWriter, r *http.Req
 	respondJSON(w, http.StatusOK, response)
 }
 
-// ListLoggedWorkouts retrieves all workouts logged by the user
+// ListLoggedWorkouts retrieves a page of the workouts logged by the user.
+// Query parameters: start_date and end_date (YYYY-MM-DD), movement_id,
+// wod_id, template_id, workout_type, has_pr, q (text in the name or notes),
+// sort (date_desc, date_asc, name_asc or name_desc), limit (default 20, or
+// every workout in a date range given without one), and either cursor, the
+// next_cursor of the previous page, or offset.
 func (h *UserWorkoutHandler) ListLoggedWorkouts(w http.ResponseWriter, r *http.Request) {
 	// Extract user ID from JWT token in context
 	userID, ok := middleware.GetUserID(r.Context())
@@ -289,105 +299,131 @@ func (h *UserWorkoutHandler) ListLoggedWorkouts(w http.ResponseWriter, r *http.R
 		return
 	}
 
-	// Parse pagination parameters
-	limitStr := r.URL.Query().Get("limit")
-	offsetStr := r.URL.Query().Get("offset")
-
-	limit := 20 // default
-	offset := 0
-
-	if limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
-			limit = l
-		}
+	query := r.URL.Query()
+	filter := domain.WorkoutFilter{
+		WorkoutType: query.Get("workout_type"),
+		Query:       query.Get("q"),
+		Sort:        query.Get("sort"),
+		Cursor:      query.Get("cursor"),
+		Limit:       20, // default
+	}
+	if l, err := strconv.Atoi(query.Get("limit")); err == nil && l > 0 {
+		filter.Limit = l
+	} else if query.Get("start_date") != "" && query.Get("end_date") != "" {
+		// Date ranges have always been listed whole
+		filter.Limit = 0
+	}
+	if filter.Limit > maxWorkoutPageSize {
+		filter.Limit = maxWorkoutPageSize
 	}
 
-	if offsetStr != "" {
-		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
-			offset = o
+	if v := query.Get("offset"); v != "" {
+		offset, err := strconv.Atoi(v)
+		if err != nil || offset < 0 {
+			respondError(w, http.StatusBadRequest, "Invalid offset")
+			return
 		}
+		if filter.Cursor != "" && offset > 0 {
+			respondError(w, http.StatusBadRequest, "Use either cursor or offset, not both")
+			return
+		}
+		filter.Offset = offset
 	}
 
-	// Check for date range filtering
-	startDateStr := r.URL.Query().Get("start_date")
-	endDateStr := r.URL.Query().Get("end_date")
-
-	var workouts []*domain.UserWorkoutWithDetails
-	var err error
+	switch filter.Sort {
+	case "", domain.WorkoutSortDateDesc, domain.WorkoutSortDateAsc, domain.WorkoutSortNameAsc, domain.WorkoutSortNameDesc:
+	default:
+		respondError(w, http.StatusBadRequest, "Invalid sort. Use date_desc, date_asc, name_asc or name_desc")
+		return
+	}
 
-	if startDateStr != "" && endDateStr != "" {
-		startDate, err1 := time.Parse("2006-01-02", startDateStr)
-		endDate, err2 := time.Parse("2006-01-02", endDateStr)
+	for _, param := range []struct {
+		name string
+		dest **time.Time
+	}{{"start_date", &filter.StartDate}, {"end_date", &filter.EndDate}} {
+		if v := query.Get(param.name); v != "" {
+			date, err := time.Parse("2006-01-02", v)
+			if err != nil {
+				if h.logger != nil {
+					h.logger.Warn("action=list_workouts outcome=failure user_id=%d reason=invalid_date %s=%s", userID, param.name, v)
+				}
+				respondError(w, http.StatusBadRequest, "Invalid date format. Use YYYY-MM-DD")
+				return
+			}
+			*param.dest = &date
+		}
+	}
 
-		if err1 != nil || err2 != nil {
-			if h.logger != nil {
-				h.logger.Warn("action=list_workouts outcome=failure user_id=%d reason=invalid_date_range start=%s end=%s", userID, startDateStr, endDateStr)
+	for _, param := range []struct {
+		name string
+		dest **int64
+	}{{"movement_id", &filter.MovementID}, {"wod_id", &filter.WODID}, {"template_id", &filter.TemplateID}} {
+		if v := query.Get(param.name); v != "" {
+			id, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				respondError(w, http.StatusBadRequest, "Invalid "+param.name)
+				return
 			}
-			respondError(w, http.StatusBadRequest, "Invalid date format. Use YYYY-MM-DD")
-			return
+			*param.dest = &id
 		}
+	}
 
-		// Get basic workouts in range
-		basicWorkouts, err := h.userWorkoutService.ListLoggedWorkoutsByDateRange(userID, startDate, endDate)
+	if v := query.Get("has_pr"); v != "" {
+		hasPR, err := strconv.ParseBool(v)
 		if err != nil {
-			if h.logger != nil {
-				h.logger.Error("action=list_workouts outcome=failure user_id=%d error=%v", userID, err)
-			}
-			respondError(w, http.StatusInternalServerError, "Failed to retrieve logged workouts")
+			respondError(w, http.StatusBadRequest, "Invalid has_pr")
 			return
 		}
+		filter.HasPR = &hasPR
+	}
 
-		// Get details for each
-		for _, uw := range basicWorkouts {
-			detailed, err := h.userWorkoutService.GetLoggedWorkout(uw.ID, userID)
-			if err != nil {
-				continue // Skip if error getting details
-			}
-			workouts = append(workouts, detailed)
-		}
-	} else {
-		if h.logger != nil {
-			h.logger.Info("action=list_workouts_attempt user_id=%d limit=%d offset=%d", userID, limit, offset)
-		}
+	if h.logger != nil {
+		h.logger.Info("action=list_workouts_attempt user_id=%d limit=%d offset=%d sort=%s", userID, filter.Limit, filter.Offset, filter.Sort)
+	}
 
-		workouts, err = h.userWorkoutService.ListLoggedWorkouts(userID, limit, offset)
-		if err != nil {
-			if h.logger != nil {
-				h.logger.Error("action=list_workouts outcome=failure user_id=%d error=%v", userID, err)
-			}
-			respondError(w, http.StatusInternalServerError, "Failed to retrieve logged workouts")
+	page, err := h.userWorkoutService.ListLoggedWorkouts(r.Context(), userID, filter)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidCursor) {
+			respondError(w, http.StatusBadRequest, "Invalid cursor")
 			return
 		}
 		if h.logger != nil {
-			h.logger.Info("action=list_workouts outcome=success user_id=%d returned=%d", userID, len(workouts))
+			h.logger.Error("action=list_workouts outcome=failure user_id=%d error=%v", userID, err)
 		}
+		respondError(w, http.StatusInternalServerError, "Failed to retrieve logged workouts")
+		return
+	}
+	if h.logger != nil {
+		h.logger.Info("action=list_workouts outcome=success user_id=%d returned=%d", userID, len(page.Workouts))
 	}
 
 	// Build response
-	var responses []UserWorkoutResponse
-	for _, logged := range workouts {
+	responses := []UserWorkoutResponse{}
+	for _, logged := range page.Workouts {
 		response := UserWorkoutResponse{
-			ID:           logged.ID,
-			UserID:       logged.UserID,
-			WorkoutID:    logged.WorkoutID,
-			WorkoutName:  logged.WorkoutName,
-			WorkoutDate:  logged.WorkoutDate.Format("2006-01-02"),
-			WorkoutType:  logged.WorkoutType,
-			TotalTime:    logged.TotalTime,
-			Notes:        logged.Notes,
-			CreatedAt:    logged.CreatedAt.Format("2006-01-02T15:04:05Z"),
-			UpdatedAt:    logged.UpdatedAt.Format("2006-01-02T15:04:05Z"),
-			Movements:    logged.Movements,
-			WODs:         logged.WODs,
-			WorkoutNotes: logged.WorkoutDescription,
+			ID:                   logged.ID,
+			UserID:               logged.UserID,
+			WorkoutID:            logged.WorkoutID,
+			WorkoutName:          logged.WorkoutName,
+			WorkoutDate:          logged.WorkoutDate.Format("2006-01-02"),
+			WorkoutType:          logged.WorkoutType,
+			TotalTime:            logged.TotalTime,
+			Notes:                logged.Notes,
+			CreatedAt:            logged.CreatedAt.Format("2006-01-02T15:04:05Z"),
+			UpdatedAt:            logged.UpdatedAt.Format("2006-01-02T15:04:05Z"),
+			Movements:            logged.Movements,
+			WODs:                 logged.WODs,
+			PerformanceMovements: logged.PerformanceMovements,
+			PerformanceWODs:      logged.PerformanceWODs,
+			WorkoutNotes:         logged.WorkoutDescription,
 		}
 		responses = append(responses, response)
 	}
 
 	respondJSON(w, http.StatusOK, map[string]interface{}{
-		"workouts": responses,
-		"limit":    limit,
-		"offset":   offset,
+		"workouts":    responses,
+		"limit":       filter.Limit,
+		"next_cursor": page.NextCursor,
 	})
 }
 
@@ -413,19 +449,49 @@ func (h *UserWorkoutHandler) UpdateLoggedWorkout(w http.ResponseWriter, r *http.
 		return
 	}
 
-	// Update logged workout with individual fields
+	// Convert request movements and WODs to domain ones
+	movements := make([]*domain.UserWorkoutMovement, len(req.Movements))
+	for i, m := range req.Movements {
+		movements[i] = &domain.UserWorkoutMovement{
+			MovementID: m.MovementID,
+			Sets:       m.Sets,
+			Reps:       m.Reps,
+			Weight:     m.Weight,
+			Time:       m.Time,
+			Distance:   m.Distance,
+			Notes:      m.Notes,
+			OrderIndex: m.OrderIndex,
+		}
+	}
+	wods := make([]*domain.UserWorkoutWOD, len(req.WODs))
+	for i, w := range req.WODs {
+		wods[i] = &domain.UserWorkoutWOD{
+			WODID:       w.WODID,
+			ScoreType:   w.ScoreType,
+			ScoreValue:  w.ScoreValue,
+			TimeSeconds: w.TimeSeconds,
+			Rounds:      w.Rounds,
+			Reps:        w.Reps,
+			Weight:      w.Weight,
+			Notes:       w.Notes,
+			OrderIndex:  w.OrderIndex,
+		}
+	}
+
+	// Update the workout, its movements and its WODs together
 	if h.logger != nil {
-		h.logger.Info("action=update_workout_attempt user_id=%d workout_id=%d", userID, id)
+		h.logger.Info("action=update_workout_attempt user_id=%d workout_id=%d movements=%d wods=%d", userID, id, len(movements), len(wods))
 	}
 
-	if err := h.userWorkoutService.UpdateLoggedWorkout(id, userID, req.WorkoutName, req.Notes, req.TotalTime, req.WorkoutType); err != nil {
-		switch err {
-		case service.ErrUserWorkoutNotFound:
+	_, err = h.userWorkoutService.UpdateLoggedWorkoutWithPerformance(r.Context(), id, userID, req.WorkoutName, req.Notes, req.TotalTime, req.WorkoutType, movements, wods)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrUserWorkoutNotFound):
 			if h.logger != nil {
 				h.logger.Warn("action=update_workout outcome=failure user_id=%d workout_id=%d reason=not_found", userID, id)
 			}
 			respondError(w, http.StatusNotFound, "Logged workout not found")
-		case service.ErrUnauthorizedWorkoutAccess:
+		case errors.Is(err, service.ErrUnauthorizedWorkoutAccess):
 			if h.logger != nil {
 				h.logger.Warn("action=update_workout outcome=failure user_id=%d workout_id=%d reason=unauthorized", userID, id)
 			}
@@ -439,61 +505,8 @@ func (h *UserWorkoutHandler) UpdateLoggedWorkout(w http.ResponseWriter, r *http.
 		return
 	}
 
-	// Update movements if provided
-	if len(req.Movements) > 0 {
-		// Convert request movements to domain movements
-		movements := make([]domain.UserWorkoutMovement, len(req.Movements))
-		for i, m := range req.Movements {
-			movements[i] = domain.UserWorkoutMovement{
-				MovementID: m.MovementID,
-				Sets:       m.Sets,
-				Reps:       m.Reps,
-				Weight:     m.Weight,
-				Time:       m.Time,
-				Distance:   m.Distance,
-				Notes:      m.Notes,
-				OrderIndex: m.OrderIndex,
-			}
-		}
-
-		if err := h.userWorkoutService.UpdateWorkoutMovements(id, userID, movements); err != nil {
-			if h.logger != nil {
-				h.logger.Error("action=update_workout_movements outcome=failure user_id=%d workout_id=%d error=%v", userID, id, err)
-			}
-			respondError(w, http.StatusInternalServerError, "Failed to update workout movements")
-			return
-		}
-	}
-
-	// Update WODs if provided
-	if len(req.WODs) > 0 {
-		// Convert request WODs to domain WODs
-		wods := make([]domain.UserWorkoutWOD, len(req.WODs))
-		for i, w := range req.WODs {
-			wods[i] = domain.UserWorkoutWOD{
-				WODID:       w.WODID,
-				ScoreType:   w.ScoreType,
-				ScoreValue:  w.ScoreValue,
-				TimeSeconds: w.TimeSeconds,
-				Rounds:      w.Rounds,
-				Reps:        w.Reps,
-				Weight:      w.Weight,
-				Notes:       w.Notes,
-				OrderIndex:  w.OrderIndex,
-			}
-		}
-
-		if err := h.userWorkoutService.UpdateWorkoutWODs(id, userID, wods); err != nil {
-			if h.logger != nil {
-				h.logger.Error("action=update_workout_wods outcome=failure user_id=%d workout_id=%d error=%v", userID, id, err)
-			}
-			respondError(w, http.StatusInternalServerError, "Failed to update workout WODs")
-			return
-		}
-	}
-
 	// Retrieve updated logged workout
-	logged, err := h.userWorkoutService.GetLoggedWorkout(id, userID)
+	logged, err := h.userWorkoutService.GetLoggedWorkout(r.Context(), id, userID)
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, "Failed to retrieve updated workout")
 		return
@@ -540,7 +553,7 @@ func (h *UserWorkoutHandler) DeleteLoggedWorkout(w http.ResponseWriter, r *http.
 		h.logger.Info("action=delete_workout_attempt user_id=%d workout_id=%d", userID, id)
 	}
 
-	if err := h.userWorkoutService.DeleteLoggedWorkout(id, userID); err != nil {
+	if err := h.userWorkoutService.DeleteLoggedWorkout(r.Context(), id, userID); err != nil {
 		if err == service.ErrUnauthorized {
 			if h.logger != nil {
 				h.logger.Warn("action=delete_workout outcome=failure user_id=%d workout_id=%d reason=unauthorized", userID, id)
@@ -588,7 +601,7 @@ func (h *UserWorkoutHandler) GetMonthlyStats(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	count, err := h.userWorkoutService.GetWorkoutStatsForMonth(userID, year, month)
+	count, err := h.userWorkoutService.GetWorkoutStatsForMonth(r.Context(), userID, year, month)
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, "Failed to retrieve monthly stats")
 		return
@@ -624,7 +637,7 @@ func (h *UserWorkoutHandler) GetPersonalRecords(w http.ResponseWriter, r *http.R
 	}
 
 	// Get PR movements
-	prMovements, err := h.userWorkoutService.GetPRMovements(userID, limit)
+	prMovements, err := h.userWorkoutService.GetPRMovements(r.Context(), userID, limit)
 	if err != nil {
 		if h.logger != nil {
 			h.logger.Error("action=get_personal_records outcome=failure user_id=%d error=%v", userID, err)
@@ -634,7 +647,7 @@ func (h *UserWorkoutHandler) GetPersonalRecords(w http.ResponseWriter, r *http.R
 	}
 
 	// Get PR WODs
-	prWODs, err := h.userWorkoutService.GetPRWODs(userID, limit)
+	prWODs, err := h.userWorkoutService.GetPRWODs(r.Context(), userID, limit)
 	if err != nil {
 		if h.logger != nil {
 			h.logger.Error("action=get_personal_records outcome=failure user_id=%d error=%v", userID, err)
@@ -667,7 +680,7 @@ func (h *UserWorkoutHandler) RetroactiveFlagPRs(w http.ResponseWriter, r *http.R
 	}
 
 	// Run retroactive PR flagging
-	movementPRCount, wodPRCount, err := h.userWorkoutService.RetroactivelyFlagPRs(userID)
+	movementPRCount, wodPRCount, err := h.userWorkoutService.RetroactivelyFlagPRs(r.Context(), userID)
 	if err != nil {
 		if h.logger != nil {
 			h.logger.Error("action=retroactive_flag_prs outcome=failure user_id=%d error=%v", userID, err)
@@ -0,0 +1,307 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/johnzastrow/actalog/internal/domain"
+	"github.com/johnzastrow/actalog/internal/policy"
+	"github.com/johnzastrow/actalog/internal/service"
+	"github.com/johnzastrow/actalog/pkg/logger"
+)
+
+// WebhookHandler handles outgoing webhook management endpoints
+type WebhookHandler struct {
+	webhookService *service.WebhookService
+	logger         *logger.Logger
+}
+
+// NewWebhookHandler creates a new webhook handler
+func NewWebhookHandler(webhookService *service.WebhookService, logger *logger.Logger) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+		logger:         logger,
+	}
+}
+
+// WebhookRequest represents a request to create or update a webhook
+type WebhookRequest struct {
+	URL         string   `json:"url"`
+	Events      []string `json:"events"`
+	AllUsers    bool     `json:"all_users"`
+	Description *string  `json:"description,omitempty"`
+	IsActive    *bool    `json:"is_active,omitempty"`
+}
+
+// WebhookSecretResponse includes the signing secret, which is only returned on creation and rotation
+type WebhookSecretResponse struct {
+	*domain.Webhook
+	Secret string `json:"secret"`
+}
+
+// webhookCaller returns the signed-in caller from the request context
+func webhookCaller(r *http.Request) (policy.Subject, bool) {
+	subject := policy.SubjectFromContext(r.Context())
+	return subject, subject.Authenticated()
+}
+
+// respondWebhookError maps webhook service errors to HTTP responses
+func (h *WebhookHandler) respondWebhookError(w http.ResponseWriter, action string, userID int64, err error) {
+	switch {
+	case errors.Is(err, service.ErrWebhookNotFound):
+		respondError(w, http.StatusNotFound, "Webhook not found")
+	case errors.Is(err, policy.ErrForbidden):
+		if h.logger != nil {
+			h.logger.Warn("action=%s outcome=failure user_id=%d reason=forbidden", action, userID)
+		}
+		respondError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, service.ErrInvalidWebhookURL), errors.Is(err, service.ErrInvalidWebhookEvent), errors.Is(err, service.ErrWebhookAddressBlocked):
+		respondError(w, http.StatusBadRequest, err.Error())
+	default:
+		if h.logger != nil {
+			h.logger.Error("action=%s outcome=failure user_id=%d error=%v", action, userID, err)
+		}
+		respondError(w, http.StatusInternalServerError, "Failed to process webhook request")
+	}
+}
+
+// ListWebhooks lists the caller's webhooks
+func (h *WebhookHandler) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	subject, ok := webhookCaller(r)
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	webhooks, err := h.webhookService.List(r.Context(), subject.UserID)
+	if err != nil {
+		h.respondWebhookError(w, "list_webhooks", subject.UserID, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"webhooks": webhooks,
+		"events":   domain.WebhookEvents,
+	})
+}
+
+// CreateWebhook registers a new webhook
+func (h *WebhookHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	subject, ok := webhookCaller(r)
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req WebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	webhook := &domain.Webhook{
+		URL:         req.URL,
+		Events:      req.Events,
+		AllUsers:    req.AllUsers,
+		Description: req.Description,
+	}
+
+	if err := h.webhookService.Create(r.Context(), webhook, subject); err != nil {
+		h.respondWebhookError(w, "create_webhook", subject.UserID, err)
+		return
+	}
+
+	if h.logger != nil {
+		h.logger.Info("action=create_webhook outcome=success user_id=%d webhook_id=%d all_users=%t", subject.UserID, webhook.ID, webhook.AllUsers)
+	}
+
+	respondJSON(w, http.StatusCreated, WebhookSecretResponse{Webhook: webhook, Secret: webhook.Secret})
+}
+
+// GetWebhook retrieves a single webhook
+func (h *WebhookHandler) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	subject, ok := webhookCaller(r)
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid webhook ID")
+		return
+	}
+
+	webhook, err := h.webhookService.Get(r.Context(), id, subject)
+	if err != nil {
+		h.respondWebhookError(w, "get_webhook", subject.UserID, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, webhook)
+}
+
+// UpdateWebhook updates a webhook
+func (h *WebhookHandler) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	subject, ok := webhookCaller(r)
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid webhook ID")
+		return
+	}
+
+	var req WebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	isActive := true
+	if req.IsActive != nil {
+		isActive = *req.IsActive
+	}
+
+	webhook := &domain.Webhook{
+		ID:          id,
+		URL:         req.URL,
+		Events:      req.Events,
+		AllUsers:    req.AllUsers,
+		Description: req.Description,
+		IsActive:    isActive,
+	}
+
+	if err := h.webhookService.Update(r.Context(), webhook, subject); err != nil {
+		h.respondWebhookError(w, "update_webhook", subject.UserID, err)
+		return
+	}
+
+	if h.logger != nil {
+		h.logger.Info("action=update_webhook outcome=success user_id=%d webhook_id=%d", subject.UserID, id)
+	}
+
+	respondJSON(w, http.StatusOK, webhook)
+}
+
+// DeleteWebhook deletes a webhook
+func (h *WebhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	subject, ok := webhookCaller(r)
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid webhook ID")
+		return
+	}
+
+	if err := h.webhookService.Delete(r.Context(), id, subject); err != nil {
+		h.respondWebhookError(w, "delete_webhook", subject.UserID, err)
+		return
+	}
+
+	if h.logger != nil {
+		h.logger.Info("action=delete_webhook outcome=success user_id=%d webhook_id=%d", subject.UserID, id)
+	}
+
+	respondJSON(w, http.StatusOK, map[string]string{"message": "Webhook deleted successfully"})
+}
+
+// RotateWebhookSecret generates a new signing secret for a webhook
+func (h *WebhookHandler) RotateWebhookSecret(w http.ResponseWriter, r *http.Request) {
+	subject, ok := webhookCaller(r)
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid webhook ID")
+		return
+	}
+
+	webhook, err := h.webhookService.RotateSecret(r.Context(), id, subject)
+	if err != nil {
+		h.respondWebhookError(w, "rotate_webhook_secret", subject.UserID, err)
+		return
+	}
+
+	if h.logger != nil {
+		h.logger.Info("action=rotate_webhook_secret outcome=success user_id=%d webhook_id=%d", subject.UserID, id)
+	}
+
+	respondJSON(w, http.StatusOK, WebhookSecretResponse{Webhook: webhook, Secret: webhook.Secret})
+}
+
+// ListWebhookDeliveries returns the delivery log for a webhook
+func (h *WebhookHandler) ListWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	subject, ok := webhookCaller(r)
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid webhook ID")
+		return
+	}
+
+	limit := 50
+	offset := 0
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 {
+		limit = l
+	}
+	if o, err := strconv.Atoi(r.URL.Query().Get("offset")); err == nil && o >= 0 {
+		offset = o
+	}
+
+	deliveries, err := h.webhookService.ListDeliveries(r.Context(), id, subject, limit, offset)
+	if err != nil {
+		h.respondWebhookError(w, "list_webhook_deliveries", subject.UserID, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"deliveries": deliveries,
+		"limit":      limit,
+		"offset":     offset,
+	})
+}
+
+// TestWebhook sends a test event to a webhook and returns the delivery result
+func (h *WebhookHandler) TestWebhook(w http.ResponseWriter, r *http.Request) {
+	subject, ok := webhookCaller(r)
+	if !ok {
+		respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid webhook ID")
+		return
+	}
+
+	delivery, err := h.webhookService.SendTest(r.Context(), id, subject)
+	if err != nil {
+		h.respondWebhookError(w, "test_webhook", subject.UserID, err)
+		return
+	}
+
+	if h.logger != nil {
+		h.logger.Info("action=test_webhook user_id=%d webhook_id=%d success=%t", subject.UserID, id, delivery.Success)
+	}
+
+	respondJSON(w, http.StatusOK, delivery)
+}
@@ -2,11 +2,13 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/johnzastrow/actalog/internal/domain"
+	"github.com/johnzastrow/actalog/internal/policy"
 	"github.com/johnzastrow/actalog/internal/service"
 	"github.com/johnzastrow/actalog/pkg/middleware"
 )
@@ -97,13 +99,13 @@ func (h *WODHandler) CreateWOD(w http.ResponseWriter, r *http.Request) {
 		Notes:       req.Notes,
 	}
 
-	if err := h.wodService.Create(wod, userID); err != nil {
+	if err := h.wodService.Create(r.Context(), wod, userID); err != nil {
 		respondError(w, http.StatusInternalServerError, "Failed to create WOD: "+err.Error())
 		return
 	}
 
 	// Retrieve created WOD
-	created, err := h.wodService.GetByID(wod.ID)
+	created, err := h.wodService.GetByID(r.Context(), wod.ID)
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, "Failed to retrieve created WOD")
 		return
@@ -137,7 +139,7 @@ func (h *WODHandler) GetWOD(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	wod, err := h.wodService.GetByID(id)
+	wod, err := h.wodService.GetByID(r.Context(), id)
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, "Failed to retrieve WOD: "+err.Error())
 		return
@@ -189,12 +191,12 @@ func (h *WODHandler) ListWODs(w http.ResponseWriter, r *http.Request) {
 	standardOnly := r.URL.Query().Get("standard") == "true"
 
 	if standardOnly {
-		wods, err = h.wodService.ListStandard(limit, offset)
+		wods, err = h.wodService.ListStandard(r.Context(), limit, offset)
 	} else if ok {
 		userIDPtr := &userID
-		wods, err = h.wodService.ListAll(userIDPtr, limit, offset)
+		wods, err = h.wodService.ListAll(r.Context(), userIDPtr, limit, offset)
 	} else {
-		wods, err = h.wodService.ListStandard(limit, offset)
+		wods, err = h.wodService.ListStandard(r.Context(), limit, offset)
 	}
 
 	if err != nil {
@@ -236,7 +238,7 @@ func (h *WODHandler) SearchWODs(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	wods, err := h.wodService.Search(query, 20)
+	wods, err := h.wodService.Search(r.Context(), query, 20)
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, "Failed to search WODs")
 		return
@@ -271,8 +273,8 @@ func (h *WODHandler) SearchWODs(w http.ResponseWriter, r *http.Request) {
 // UpdateWOD updates a custom WOD
 func (h *WODHandler) UpdateWOD(w http.ResponseWriter, r *http.Request) {
 	// Extract user ID from JWT token in context
-	userID, ok := middleware.GetUserID(r.Context())
-	if !ok {
+	subject := policy.SubjectFromContext(r.Context())
+	if !subject.Authenticated() {
 		respondError(w, http.StatusUnauthorized, "Unauthorized")
 		return
 	}
@@ -303,9 +305,11 @@ func (h *WODHandler) UpdateWOD(w http.ResponseWriter, r *http.Request) {
 		Notes:       req.Notes,
 	}
 
-	if err := h.wodService.Update(wod, userID); err != nil {
-		if err == service.ErrUnauthorized {
+	if err := h.wodService.Update(r.Context(), wod, subject); err != nil {
+		if errors.Is(err, policy.ErrForbidden) {
 			respondError(w, http.StatusForbidden, "You don't have permission to update this WOD")
+		} else if errors.Is(err, service.ErrWODNotFound) {
+			respondError(w, http.StatusNotFound, "WOD not found")
 		} else {
 			respondError(w, http.StatusInternalServerError, "Failed to update WOD: "+err.Error())
 		}
@@ -313,7 +317,7 @@ func (h *WODHandler) UpdateWOD(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Retrieve updated WOD
-	updated, err := h.wodService.GetByID(id)
+	updated, err := h.wodService.GetByID(r.Context(), id)
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, "Failed to retrieve updated WOD")
 		return
@@ -341,8 +345,8 @@ func (h *WODHandler) UpdateWOD(w http.ResponseWriter, r *http.Request) {
 // DeleteWOD deletes a custom WOD
 func (h *WODHandler) DeleteWOD(w http.ResponseWriter, r *http.Request) {
 	// Extract user ID from JWT token in context
-	userID, ok := middleware.GetUserID(r.Context())
-	if !ok {
+	subject := policy.SubjectFromContext(r.Context())
+	if !subject.Authenticated() {
 		respondError(w, http.StatusUnauthorized, "Unauthorized")
 		return
 	}
@@ -354,9 +358,11 @@ func (h *WODHandler) DeleteWOD(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.wodService.Delete(id, userID); err != nil {
-		if err == service.ErrUnauthorized {
+	if err := h.wodService.Delete(r.Context(), id, subject); err != nil {
+		if errors.Is(err, policy.ErrForbidden) {
 			respondError(w, http.StatusForbidden, "You don't have permission to delete this WOD")
+		} else if errors.Is(err, service.ErrWODNotFound) {
+			respondError(w, http.StatusNotFound, "WOD not found")
 		} else {
 			respondError(w, http.StatusInternalServerError, "Failed to delete WOD: "+err.Error())
 		}
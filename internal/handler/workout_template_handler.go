@@ -1,23 +1,26 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/johnzastrow/actalog/internal/domain"
+	"github.com/johnzastrow/actalog/internal/policy"
 	"github.com/johnzastrow/actalog/pkg/middleware"
 )
 
 type WorkoutTemplateService interface {
-	Create(userID int64, name string, notes *string, movements []domain.WorkoutMovement, wods []domain.WorkoutWOD) (*domain.Workout, error)
-	GetByID(id int64) (*domain.Workout, error)
-	GetByIDWithDetails(id int64) (*domain.Workout, error)
-	ListByUser(userID int64, limit, offset int) ([]*domain.Workout, error)
-	ListStandard(limit, offset int) ([]*domain.Workout, error)
-	Update(id, userID int64, name string, notes *string, movements []domain.WorkoutMovement, wods []domain.WorkoutWOD) (*domain.Workout, error)
-	Delete(id, userID int64) error
+	Create(ctx context.Context, userID int64, name string, notes *string, movements []domain.WorkoutMovement, wods []domain.WorkoutWOD) (*domain.Workout, error)
+	GetByID(ctx context.Context, id int64) (*domain.Workout, error)
+	GetByIDWithDetails(ctx context.Context, id int64) (*domain.Workout, error)
+	ListByUser(ctx context.Context, userID int64, limit, offset int) ([]*domain.Workout, error)
+	ListStandard(ctx context.Context, limit, offset int) ([]*domain.Workout, error)
+	Update(ctx context.Context, id int64, subject policy.Subject, name string, notes *string, movements []domain.WorkoutMovement, wods []domain.WorkoutWOD) (*domain.Workout, error)
+	Delete(ctx context.Context, id int64, subject policy.Subject) error
 }
 
 type WorkoutTemplateHandler struct {
@@ -91,7 +94,7 @@ func (h *WorkoutTemplateHandler) CreateTemplate(w http.ResponseWriter, r *http.R
 		}
 	}
 
-	template, err := h.service.Create(userID, req.Name, req.Description, movements, wods)
+	template, err := h.service.Create(r.Context(), userID, req.Name, req.Description, movements, wods)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -113,7 +116,7 @@ func (h *WorkoutTemplateHandler) GetTemplate(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	template, err := h.service.GetByIDWithDetails(id)
+	template, err := h.service.GetByIDWithDetails(r.Context(), id)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusNotFound)
 		return
@@ -148,7 +151,7 @@ func (h *WorkoutTemplateHandler) ListMyTemplates(w http.ResponseWriter, r *http.
 		}
 	}
 
-	templates, err := h.service.ListByUser(userID, limit, offset)
+	templates, err := h.service.ListByUser(r.Context(), userID, limit, offset)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -182,7 +185,7 @@ func (h *WorkoutTemplateHandler) ListStandardTemplates(w http.ResponseWriter, r
 		}
 	}
 
-	templates, err := h.service.ListStandard(limit, offset)
+	templates, err := h.service.ListStandard(r.Context(), limit, offset)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -201,8 +204,8 @@ func (h *WorkoutTemplateHandler) ListStandardTemplates(w http.ResponseWriter, r
 
 // UpdateTemplate handles PUT /api/templates/{id}
 func (h *WorkoutTemplateHandler) UpdateTemplate(w http.ResponseWriter, r *http.Request) {
-	userID, ok := middleware.GetUserID(r.Context())
-	if !ok {
+	subject := policy.SubjectFromContext(r.Context())
+	if !subject.Authenticated() {
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
@@ -269,7 +272,11 @@ func (h *WorkoutTemplateHandler) UpdateTemplate(w http.ResponseWriter, r *http.R
 		}
 	}
 
-	template, err := h.service.Update(id, userID, req.Name, req.Description, movements, wods)
+	template, err := h.service.Update(r.Context(), id, subject, req.Name, req.Description, movements, wods)
+	if errors.Is(err, policy.ErrForbidden) {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -283,8 +290,8 @@ func (h *WorkoutTemplateHandler) UpdateTemplate(w http.ResponseWriter, r *http.R
 
 // DeleteTemplate handles DELETE /api/templates/{id}
 func (h *WorkoutTemplateHandler) DeleteTemplate(w http.ResponseWriter, r *http.Request) {
-	userID, ok := middleware.GetUserID(r.Context())
-	if !ok {
+	subject := policy.SubjectFromContext(r.Context())
+	if !subject.Authenticated() {
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
@@ -296,7 +303,7 @@ func (h *WorkoutTemplateHandler) DeleteTemplate(w http.ResponseWriter, r *http.R
 		return
 	}
 
-	if err := h.service.Delete(id, userID); err != nil {
+	if err := h.service.Delete(r.Context(), id, subject); err != nil {
 		http.Error(w, err.Error(), http.StatusForbidden)
 		return
 	}
@@ -2,12 +2,13 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/johnzastrow/actalog/internal/policy"
 	"github.com/johnzastrow/actalog/internal/service"
-	"github.com/johnzastrow/actalog/pkg/middleware"
 )
 
 // WorkoutWODHandler handles linking WODs to workout templates
@@ -38,8 +39,8 @@ type UpdateWorkoutWODRequest struct {
 // AddWODToWorkout adds a WOD to a workout template
 func (h *WorkoutWODHandler) AddWODToWorkout(w http.ResponseWriter, r *http.Request) {
 	// Extract user ID from JWT token in context
-	userID, ok := middleware.GetUserID(r.Context())
-	if !ok {
+	subject := policy.SubjectFromContext(r.Context())
+	if !subject.Authenticated() {
 		respondError(w, http.StatusUnauthorized, "Unauthorized")
 		return
 	}
@@ -65,9 +66,9 @@ func (h *WorkoutWODHandler) AddWODToWorkout(w http.ResponseWriter, r *http.Reque
 	}
 
 	// Add WOD to workout
-	workoutWOD, err := h.workoutWODService.AddWODToWorkout(workoutID, req.WODID, userID, req.OrderIndex, req.Division)
+	workoutWOD, err := h.workoutWODService.AddWODToWorkout(r.Context(), workoutID, req.WODID, subject, req.OrderIndex, req.Division)
 	if err != nil {
-		if err == service.ErrUnauthorized {
+		if errors.Is(err, policy.ErrForbidden) {
 			respondError(w, http.StatusForbidden, "You don't have permission to modify this workout")
 		} else {
 			respondError(w, http.StatusInternalServerError, "Failed to add WOD to workout: "+err.Error())
@@ -81,8 +82,8 @@ func (h *WorkoutWODHandler) AddWODToWorkout(w http.ResponseWriter, r *http.Reque
 // RemoveWODFromWorkout removes a WOD from a workout template
 func (h *WorkoutWODHandler) RemoveWODFromWorkout(w http.ResponseWriter, r *http.Request) {
 	// Extract user ID from JWT token in context
-	userID, ok := middleware.GetUserID(r.Context())
-	if !ok {
+	subject := policy.SubjectFromContext(r.Context())
+	if !subject.Authenticated() {
 		respondError(w, http.StatusUnauthorized, "Unauthorized")
 		return
 	}
@@ -96,8 +97,8 @@ func (h *WorkoutWODHandler) RemoveWODFromWorkout(w http.ResponseWriter, r *http.
 	}
 
 	// Remove WOD from workout
-	if err := h.workoutWODService.RemoveWODFromWorkout(workoutWODID, userID); err != nil {
-		if err == service.ErrUnauthorized {
+	if err := h.workoutWODService.RemoveWODFromWorkout(r.Context(), workoutWODID, subject); err != nil {
+		if errors.Is(err, policy.ErrForbidden) {
 			respondError(w, http.StatusForbidden, "You don't have permission to modify this workout")
 		} else {
 			respondError(w, http.StatusInternalServerError, "Failed to remove WOD from workout: "+err.Error())
@@ -111,8 +112,8 @@ func (h *WorkoutWODHandler) RemoveWODFromWorkout(w http.ResponseWriter, r *http.
 // UpdateWorkoutWOD updates a WOD in a workout template
 func (h *WorkoutWODHandler) UpdateWorkoutWOD(w http.ResponseWriter, r *http.Request) {
 	// Extract user ID from JWT token in context
-	userID, ok := middleware.GetUserID(r.Context())
-	if !ok {
+	subject := policy.SubjectFromContext(r.Context())
+	if !subject.Authenticated() {
 		respondError(w, http.StatusUnauthorized, "Unauthorized")
 		return
 	}
@@ -132,8 +133,8 @@ func (h *WorkoutWODHandler) UpdateWorkoutWOD(w http.ResponseWriter, r *http.Requ
 	}
 
 	// Update workout WOD
-	if err := h.workoutWODService.UpdateWorkoutWOD(workoutWODID, userID, req.ScoreValue, req.Division); err != nil {
-		if err == service.ErrUnauthorized {
+	if err := h.workoutWODService.UpdateWorkoutWOD(r.Context(), workoutWODID, subject, req.ScoreValue, req.Division); err != nil {
+		if errors.Is(err, policy.ErrForbidden) {
 			respondError(w, http.StatusForbidden, "You don't have permission to modify this workout")
 		} else {
 			respondError(w, http.StatusInternalServerError, "Failed to update workout WOD: "+err.Error())
@@ -147,8 +148,8 @@ func (h *WorkoutWODHandler) UpdateWorkoutWOD(w http.ResponseWriter, r *http.Requ
 // ToggleWODPR toggles the PR flag on a WOD in a workout
 func (h *WorkoutWODHandler) ToggleWODPR(w http.ResponseWriter, r *http.Request) {
 	// Extract user ID from JWT token in context
-	userID, ok := middleware.GetUserID(r.Context())
-	if !ok {
+	subject := policy.SubjectFromContext(r.Context())
+	if !subject.Authenticated() {
 		respondError(w, http.StatusUnauthorized, "Unauthorized")
 		return
 	}
@@ -162,8 +163,8 @@ func (h *WorkoutWODHandler) ToggleWODPR(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Toggle PR flag
-	if err := h.workoutWODService.ToggleWODPR(workoutWODID, userID); err != nil {
-		if err == service.ErrUnauthorized {
+	if err := h.workoutWODService.ToggleWODPR(r.Context(), workoutWODID, subject); err != nil {
+		if errors.Is(err, policy.ErrForbidden) {
 			respondError(w, http.StatusForbidden, "You don't have permission to modify this workout")
 		} else {
 			respondError(w, http.StatusInternalServerError, "Failed to toggle WOD PR: "+err.Error())
@@ -184,7 +185,7 @@ func (h *WorkoutWODHandler) ListWODsForWorkout(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	wods, err := h.workoutWODService.ListWODsForWorkout(workoutID)
+	wods, err := h.workoutWODService.ListWODsForWorkout(r.Context(), workoutID)
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, "Failed to retrieve WODs for workout")
 		return
@@ -0,0 +1,57 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/johnzastrow/actalog/pkg/middleware"
+)
+
+// SubjectFromContext returns the signed-in user set by the auth middleware,
+// or the anonymous subject
+func SubjectFromContext(ctx context.Context) Subject {
+	userID, ok := middleware.GetUserID(ctx)
+	if !ok {
+		return Subject{}
+	}
+	role, _ := middleware.GetUserRole(ctx)
+	return User(userID, role)
+}
+
+// ActionForMethod maps an HTTP method to the action it performs
+func ActionForMethod(method string) Action {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return ActionRead
+	case http.MethodPut, http.MethodPatch:
+		return ActionUpdate
+	case http.MethodDelete:
+		return ActionDelete
+	default:
+		return ActionCreate
+	}
+}
+
+// Require is a middleware that checks the caller may perform the request's
+// action (from its method) on resources of a kind. It goes after the auth
+// middleware and guards routes that don't concern a resource the caller
+// owns, such as the admin API; per-resource checks belong in the handler or
+// service that loads the resource.
+func Require(kind Kind) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			err := Authorize(SubjectFromContext(r.Context()), ActionForMethod(r.Method), OfKind(kind))
+			switch {
+			case errors.Is(err, ErrUnauthenticated):
+				http.Error(w, `{"message":"Unauthorized: no user context found"}`, http.StatusUnauthorized)
+				return
+			case err != nil:
+				http.Error(w, `{"message":"Forbidden: you don't have access to this resource"}`, http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,229 @@
+// Package policy decides who may do what to which resource. Handlers and
+// services describe the caller as a Subject and the thing being accessed as
+// a Resource and ask Authorize, instead of comparing user IDs and roles
+// themselves.
+package policy
+
+import (
+	"errors"
+
+	"github.com/johnzastrow/actalog/internal/domain"
+)
+
+// Roles a user can have
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+
+	// RoleCoach and RoleGymOwner are reserved for coaches and gyms. They have
+	// no grants yet, so they can do exactly what regular users can; their
+	// rules will be added to grants once coach–athlete links and gym
+	// membership exist.
+	RoleCoach    = "coach"
+	RoleGymOwner = "gym_owner"
+)
+
+// Action is something a subject does to a resource
+type Action string
+
+// Actions
+const (
+	ActionRead   Action = "read"
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+	// ActionUse is building on a resource without changing it, such as
+	// logging a workout from a template
+	ActionUse Action = "use"
+)
+
+// Kind is a type of resource
+type Kind string
+
+// Resource kinds
+const (
+	// KindMovement, KindWOD and KindTemplate make up the catalogue, which
+	// anyone may browse
+	KindMovement Kind = "movement"
+	KindWOD      Kind = "wod"
+	KindTemplate Kind = "template"
+	// KindLoggedWorkout covers a user's training log: logged workouts, their
+	// performance data and PRs. Only the athlete may see it.
+	KindLoggedWorkout Kind = "logged_workout"
+	KindWebhook       Kind = "webhook"
+	// KindUser is a user account and everything hanging off it: profile,
+	// settings, sessions, MFA, passkeys, API tokens and notifications
+	KindUser     Kind = "user"
+	KindAuditLog Kind = "audit_log"
+	// KindSystem covers instance-wide administration such as data cleanup
+	// and the email outbox
+	KindSystem Kind = "system"
+	// KindSession is signing in, signing up and recovering an account, which
+	// anyone may do
+	KindSession Kind = "session"
+)
+
+var (
+	// ErrForbidden is matched (with errors.Is) by every denial, including
+	// the resource-specific errors services create with Denied
+	ErrForbidden = errors.New("forbidden")
+	// ErrUnauthenticated is returned when an anonymous subject needs to sign in
+	ErrUnauthenticated = errors.New("authentication required")
+	// ErrNotOwner is returned when the subject doesn't own the resource
+	ErrNotOwner = Denied("forbidden: not the owner of this resource")
+	// ErrSharedResource is returned when the subject tries to change a
+	// resource shared by all users, such as a standard movement
+	ErrSharedResource = Denied("forbidden: shared resources can't be changed")
+)
+
+type deniedError struct {
+	message string
+}
+
+func (e *deniedError) Error() string {
+	return e.message
+}
+
+func (e *deniedError) Is(target error) bool {
+	return target == ErrForbidden
+}
+
+// Denied returns a new error with the given message that matches
+// ErrForbidden, so services can keep their own sentinel errors while
+// handlers map every denial to 403 the same way
+func Denied(message string) error {
+	return &deniedError{message: message}
+}
+
+// Subject is the user making a request. The zero Subject is anonymous, and a
+// subject without a role is treated as a regular user.
+type Subject struct {
+	UserID int64
+	Role   string
+}
+
+// User returns a subject for a signed-in user
+func User(userID int64, role string) Subject {
+	return Subject{UserID: userID, Role: role}
+}
+
+// Authenticated reports whether the subject is signed in
+func (s Subject) Authenticated() bool {
+	return s.UserID != 0
+}
+
+// Resource is the thing being accessed. OwnerID is nil for resources nobody
+// owns, such as standard catalogue entries and instance-wide data. Shared
+// resources belong to every user: anyone may read and use them, but only
+// roles granted it may change them.
+type Resource struct {
+	Kind    Kind
+	OwnerID *int64
+	Shared  bool
+}
+
+// OfKind returns a resource standing for every resource of a kind, for
+// checks that don't concern a particular one, such as listing the audit log
+func OfKind(kind Kind) Resource {
+	return Resource{Kind: kind}
+}
+
+// OwnedBy returns a resource of a kind owned by a user. Use it when creating
+// a resource for the caller and for parts of the caller's own account.
+func OwnedBy(kind Kind, userID int64) Resource {
+	return Resource{Kind: kind, OwnerID: &userID}
+}
+
+// Movement returns the resource for a movement
+func Movement(m *domain.Movement) Resource {
+	return Resource{Kind: KindMovement, OwnerID: m.CreatedBy, Shared: m.IsStandard}
+}
+
+// WOD returns the resource for a WOD
+func WOD(w *domain.WOD) Resource {
+	return Resource{Kind: KindWOD, OwnerID: w.CreatedBy, Shared: w.IsStandard}
+}
+
+// Template returns the resource for a workout template. Templates without a
+// creator are standard templates.
+func Template(w *domain.Workout) Resource {
+	return Resource{Kind: KindTemplate, OwnerID: w.CreatedBy, Shared: w.CreatedBy == nil}
+}
+
+// LoggedWorkout returns the resource for a logged workout
+func LoggedWorkout(w *domain.UserWorkout) Resource {
+	return OwnedBy(KindLoggedWorkout, w.UserID)
+}
+
+// Webhook returns the resource for a webhook. Webhooks for all users are
+// shared.
+func Webhook(w *domain.Webhook) Resource {
+	return Resource{Kind: KindWebhook, OwnerID: &w.UserID, Shared: w.AllUsers}
+}
+
+// grant lets a role do some actions to resources of a kind whoever owns them
+type grant struct {
+	actions []Action
+	// shared extends the grant to shared resources
+	shared bool
+}
+
+var allActions = []Action{ActionRead, ActionCreate, ActionUpdate, ActionDelete, ActionUse}
+
+// grants lists what each role may do beyond what owners may do with their
+// own resources. Admins look after other users' custom catalogue entries but
+// not the standard catalogue, and can't see anyone's training log.
+var grants = map[string]map[Kind]grant{
+	RoleAdmin: {
+		KindMovement: {actions: allActions},
+		KindWOD:      {actions: allActions},
+		KindTemplate: {actions: allActions},
+		KindWebhook:  {actions: allActions, shared: true},
+		KindUser:     {actions: allActions},
+		KindAuditLog: {actions: []Action{ActionRead}},
+		KindSystem:   {actions: allActions},
+	},
+}
+
+// Authorize decides whether the subject may perform the action on the
+// resource. It returns nil if so, ErrUnauthenticated if an anonymous subject
+// needs to sign in, and otherwise an error matching ErrForbidden.
+//
+// Anyone may sign in and browse the catalogue, and signed-in users may use
+// shared resources. Owners may do anything with their own resources unless
+// they are shared. Anything else needs a grant for the subject's role.
+func Authorize(s Subject, action Action, r Resource) error {
+	if r.Kind == KindSession || isCatalogue(r.Kind) && action == ActionRead {
+		return nil
+	}
+	if !s.Authenticated() {
+		return ErrUnauthenticated
+	}
+	if r.Shared && action == ActionUse {
+		return nil
+	}
+	if !r.Shared && r.OwnerID != nil && *r.OwnerID == s.UserID {
+		return nil
+	}
+
+	if g, ok := grants[s.Role][r.Kind]; ok && g.allows(action) && (g.shared || !r.Shared) {
+		return nil
+	}
+	if r.Shared {
+		return ErrSharedResource
+	}
+	return ErrNotOwner
+}
+
+func (g grant) allows(action Action) bool {
+	for _, a := range g.actions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+func isCatalogue(kind Kind) bool {
+	return kind == KindMovement || kind == KindWOD || kind == KindTemplate
+}
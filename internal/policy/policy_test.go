@@ -0,0 +1,169 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/johnzastrow/actalog/internal/domain"
+	"github.com/johnzastrow/actalog/pkg/middleware"
+)
+
+func TestAuthorize(t *testing.T) {
+	owner := int64(1)
+	anonymous := Subject{}
+	athlete := User(owner, RoleUser)
+	other := User(2, RoleUser)
+	roleless := User(2, "")
+	admin := User(3, RoleAdmin)
+	coach := User(4, RoleCoach)
+	gymOwner := User(5, RoleGymOwner)
+
+	customMovement := Movement(&domain.Movement{CreatedBy: &owner})
+	standardMovement := Movement(&domain.Movement{IsStandard: true})
+	legacyMovement := Movement(&domain.Movement{})
+	customWOD := WOD(&domain.WOD{CreatedBy: &owner})
+	standardWOD := WOD(&domain.WOD{IsStandard: true, CreatedBy: &owner})
+	customTemplate := Template(&domain.Workout{CreatedBy: &owner})
+	standardTemplate := Template(&domain.Workout{})
+	loggedWorkout := LoggedWorkout(&domain.UserWorkout{UserID: owner})
+	webhook := Webhook(&domain.Webhook{UserID: owner})
+	allUsersWebhook := Webhook(&domain.Webhook{UserID: owner, AllUsers: true})
+
+	tests := []struct {
+		name     string
+		subject  Subject
+		action   Action
+		resource Resource
+		wantErr  error
+	}{
+		// The catalogue is public to browse
+		{"anonymous reads a custom movement", anonymous, ActionRead, customMovement, nil},
+		{"anonymous reads a standard WOD", anonymous, ActionRead, standardWOD, nil},
+		{"anonymous reads a standard template", anonymous, ActionRead, standardTemplate, nil},
+		{"anonymous signs in", anonymous, ActionCreate, OfKind(KindSession), nil},
+		{"anonymous creates a movement", anonymous, ActionCreate, OwnedBy(KindMovement, 0), ErrUnauthenticated},
+		{"anonymous reads a logged workout", anonymous, ActionRead, loggedWorkout, ErrUnauthenticated},
+		{"anonymous uses a standard template", anonymous, ActionUse, standardTemplate, ErrUnauthenticated},
+
+		// Owners manage their own resources
+		{"user creates a movement", athlete, ActionCreate, OwnedBy(KindMovement, owner), nil},
+		{"owner updates a custom movement", athlete, ActionUpdate, customMovement, nil},
+		{"owner deletes a custom WOD", athlete, ActionDelete, customWOD, nil},
+		{"owner updates a template", athlete, ActionUpdate, customTemplate, nil},
+		{"owner uses a template", athlete, ActionUse, customTemplate, nil},
+		{"owner reads a logged workout", athlete, ActionRead, loggedWorkout, nil},
+		{"owner deletes a webhook", athlete, ActionDelete, webhook, nil},
+		{"user manages their own account", athlete, ActionUpdate, OwnedBy(KindUser, owner), nil},
+
+		// Other users can't
+		{"other user updates a custom movement", other, ActionUpdate, customMovement, ErrNotOwner},
+		{"other user deletes a custom WOD", other, ActionDelete, customWOD, ErrNotOwner},
+		{"other user uses a custom template", other, ActionUse, customTemplate, ErrNotOwner},
+		{"other user reads a logged workout", other, ActionRead, loggedWorkout, ErrNotOwner},
+		{"other user reads a webhook", other, ActionRead, webhook, ErrNotOwner},
+		{"user without a role updates a custom movement", roleless, ActionUpdate, customMovement, ErrNotOwner},
+		{"user lists all users", athlete, ActionRead, OfKind(KindUser), ErrNotOwner},
+		{"user reads the audit log", athlete, ActionRead, OfKind(KindAuditLog), ErrNotOwner},
+		{"user runs data cleanup", athlete, ActionDelete, OfKind(KindSystem), ErrNotOwner},
+
+		// Shared resources can be used but not changed
+		{"user uses a standard template", other, ActionUse, standardTemplate, nil},
+		{"user deletes a standard movement", athlete, ActionDelete, standardMovement, ErrSharedResource},
+		{"creator updates a standard WOD", athlete, ActionUpdate, standardWOD, ErrSharedResource},
+		{"user updates a standard template", athlete, ActionUpdate, standardTemplate, ErrSharedResource},
+		{"user creates an all-users webhook", athlete, ActionCreate, allUsersWebhook, ErrSharedResource},
+
+		// Admins
+		{"admin updates another user's movement", admin, ActionUpdate, customMovement, nil},
+		{"admin deletes an unowned custom movement", admin, ActionDelete, legacyMovement, nil},
+		{"admin deletes another user's WOD", admin, ActionDelete, customWOD, nil},
+		{"admin updates another user's template", admin, ActionUpdate, customTemplate, nil},
+		{"admin deletes a standard movement", admin, ActionDelete, standardMovement, ErrSharedResource},
+		{"admin updates a standard WOD", admin, ActionUpdate, standardWOD, ErrSharedResource},
+		{"admin reads another user's logged workout", admin, ActionRead, loggedWorkout, ErrNotOwner},
+		{"admin creates an all-users webhook", admin, ActionCreate, allUsersWebhook, nil},
+		{"admin reads another user's webhook", admin, ActionRead, webhook, nil},
+		{"admin manages users", admin, ActionDelete, OfKind(KindUser), nil},
+		{"admin reads the audit log", admin, ActionRead, OfKind(KindAuditLog), nil},
+		{"admin can't change the audit log", admin, ActionDelete, OfKind(KindAuditLog), ErrNotOwner},
+		{"admin runs data cleanup", admin, ActionDelete, OfKind(KindSystem), nil},
+
+		// Coaches and gym owners have no extra grants yet
+		{"coach updates own template", coach, ActionUpdate, OwnedBy(KindTemplate, coach.UserID), nil},
+		{"coach reads an athlete's logged workout", coach, ActionRead, loggedWorkout, ErrNotOwner},
+		{"gym owner deletes a custom movement", gymOwner, ActionDelete, customMovement, ErrNotOwner},
+		{"gym owner lists users", gymOwner, ActionRead, OfKind(KindUser), ErrNotOwner},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Authorize(tt.subject, tt.action, tt.resource)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("expected access, got %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr != ErrUnauthenticated && !errors.Is(err, ErrForbidden) {
+				t.Errorf("expected %v to match ErrForbidden", err)
+			}
+		})
+	}
+}
+
+func TestDenied(t *testing.T) {
+	err := Denied("not yours")
+	if !errors.Is(err, ErrForbidden) {
+		t.Error("expected a denial to match ErrForbidden")
+	}
+	if errors.Is(err, ErrNotOwner) {
+		t.Error("expected a denial to only match itself and ErrForbidden")
+	}
+	if err.Error() != "not yours" {
+		t.Errorf("expected the message to be kept, got %q", err.Error())
+	}
+}
+
+func TestRequire(t *testing.T) {
+	tests := []struct {
+		name       string
+		kind       Kind
+		method     string
+		userID     int64
+		role       string
+		wantStatus int
+	}{
+		{"anonymous", KindUser, http.MethodGet, 0, "", http.StatusUnauthorized},
+		{"user", KindUser, http.MethodGet, 1, RoleUser, http.StatusForbidden},
+		{"admin", KindUser, http.MethodDelete, 1, RoleAdmin, http.StatusOK},
+		{"admin reads the audit log", KindAuditLog, http.MethodGet, 1, RoleAdmin, http.StatusOK},
+		{"admin writes the audit log", KindAuditLog, http.MethodPost, 1, RoleAdmin, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := Require(tt.kind)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req := httptest.NewRequest(tt.method, "/", nil)
+			if tt.userID != 0 {
+				ctx := context.WithValue(req.Context(), middleware.UserIDKey, tt.userID)
+				ctx = context.WithValue(ctx, middleware.UserRoleKey, tt.role)
+				req = req.WithContext(ctx)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+}
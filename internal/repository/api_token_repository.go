@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/johnzastrow/actalog/internal/domain"
+)
+
+// APITokenRepository implements domain.APITokenRepository
+type APITokenRepository struct {
+	db *DB
+}
+
+// NewAPITokenRepository creates a new API token repository
+func NewAPITokenRepository(db *sql.DB) *APITokenRepository {
+	return &APITokenRepository{db: NewDB(db)}
+}
+
+const apiTokenColumns = `id, user_id, name, prefix, token_hash, scopes, last_used_at, expires_at, created_at`
+
+// Create stores a new API token
+func (r *APITokenRepository) Create(ctx context.Context, token *domain.APIToken) error {
+	token.CreatedAt = time.Now()
+
+	query := `INSERT INTO api_tokens (user_id, name, prefix, token_hash, scopes, expires_at, created_at)
+	          VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+	id, err := r.db.InsertContext(ctx, query,
+		token.UserID,
+		token.Name,
+		token.Prefix,
+		token.TokenHash,
+		strings.Join(token.Scopes, ","),
+		token.ExpiresAt,
+		token.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create API token: %w", err)
+	}
+
+	token.ID = id
+	return nil
+}
+
+// GetByHash retrieves an API token by the hash of its value
+func (r *APITokenRepository) GetByHash(ctx context.Context, tokenHash string) (*domain.APIToken, error) {
+	query := `SELECT ` + apiTokenColumns + ` FROM api_tokens WHERE token_hash = ?`
+
+	token, err := scanAPIToken(r.db.QueryRowContext(ctx, query, tokenHash))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API token: %w", err)
+	}
+	return token, nil
+}
+
+// ListByUser retrieves a user's API tokens, oldest first
+func (r *APITokenRepository) ListByUser(ctx context.Context, userID int64) ([]*domain.APIToken, error) {
+	query := `SELECT ` + apiTokenColumns + ` FROM api_tokens WHERE user_id = ? ORDER BY created_at, id`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API tokens: %w", err)
+	}
+	defer rows.Close()
+
+	tokens := []*domain.APIToken{}
+	for rows.Next() {
+		token, err := scanAPIToken(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan API token: %w", err)
+		}
+		tokens = append(tokens, token)
+	}
+
+	return tokens, rows.Err()
+}
+
+// RecordUse stores the time a token was last used
+func (r *APITokenRepository) RecordUse(ctx context.Context, id int64, usedAt time.Time) error {
+	if _, err := r.db.ExecContext(ctx, `UPDATE api_tokens SET last_used_at = ? WHERE id = ?`, usedAt, id); err != nil {
+		return fmt.Errorf("failed to record API token use: %w", err)
+	}
+	return nil
+}
+
+// Delete removes an API token. Returns false if the user has no such token.
+func (r *APITokenRepository) Delete(ctx context.Context, id, userID int64) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM api_tokens WHERE id = ? AND user_id = ?`, id, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete API token: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rows > 0, nil
+}
+
+// scanAPIToken scans an API token row selected with apiTokenColumns
+func scanAPIToken(scanner interface{ Scan(...interface{}) error }) (*domain.APIToken, error) {
+	token := &domain.APIToken{}
+	var scopes string
+	var lastUsedAt, expiresAt sql.NullTime
+	err := scanner.Scan(
+		&token.ID,
+		&token.UserID,
+		&token.Name,
+		&token.Prefix,
+		&token.TokenHash,
+		&scopes,
+		&lastUsedAt,
+		&expiresAt,
+		&token.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	token.Scopes = []string{}
+	if scopes != "" {
+		token.Scopes = strings.Split(scopes, ",")
+	}
+	if lastUsedAt.Valid {
+		token.LastUsedAt = &lastUsedAt.Time
+	}
+	if expiresAt.Valid {
+		token.ExpiresAt = &expiresAt.Time
+	}
+	return token, nil
+}
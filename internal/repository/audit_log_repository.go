@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/johnzastrow/actalog/internal/domain"
+)
+
+// AuditLogRepository implements domain.AuditLogRepository
+type AuditLogRepository struct {
+	db *DB
+}
+
+// NewAuditLogRepository creates a new audit log repository
+func NewAuditLogRepository(db *sql.DB) *AuditLogRepository {
+	return &AuditLogRepository{db: NewDB(db)}
+}
+
+// Create appends an entry to the audit log
+func (r *AuditLogRepository) Create(ctx context.Context, entry *domain.AuditEntry) error {
+	query := `INSERT INTO audit_log (action, actor_id, target_type, target_id, ip_address, request_id, before_data, after_data, created_at)
+	          VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	var actorID, targetID, before, after interface{}
+	if entry.ActorID != nil {
+		actorID = *entry.ActorID
+	}
+	if entry.TargetID != nil {
+		targetID = *entry.TargetID
+	}
+	if len(entry.Before) > 0 {
+		before = string(entry.Before)
+	}
+	if len(entry.After) > 0 {
+		after = string(entry.After)
+	}
+
+	id, err := r.db.InsertContext(ctx, query,
+		entry.Action,
+		actorID,
+		entry.TargetType,
+		targetID,
+		entry.IPAddress,
+		entry.RequestID,
+		before,
+		after,
+		entry.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create audit entry: %w", err)
+	}
+	entry.ID = id
+	return nil
+}
+
+// List returns a page of entries matching the filter, newest first, and the
+// total number of matches
+func (r *AuditLogRepository) List(ctx context.Context, filter domain.AuditFilter) ([]*domain.AuditEntry, int64, error) {
+	var conditions []string
+	var args []interface{}
+
+	if filter.Action != "" {
+		conditions = append(conditions, `action = ?`)
+		args = append(args, filter.Action)
+	}
+	if filter.ActorID != nil {
+		conditions = append(conditions, `actor_id = ?`)
+		args = append(args, *filter.ActorID)
+	}
+	if filter.TargetType != "" {
+		conditions = append(conditions, `target_type = ?`)
+		args = append(args, filter.TargetType)
+	}
+	if filter.TargetID != nil {
+		conditions = append(conditions, `target_id = ?`)
+		args = append(args, *filter.TargetID)
+	}
+	if filter.Since != nil {
+		conditions = append(conditions, `created_at >= ?`)
+		args = append(args, *filter.Since)
+	}
+	if filter.Until != nil {
+		conditions = append(conditions, `created_at < ?`)
+		args = append(args, *filter.Until)
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+
+	var total int64
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM audit_log`+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count audit entries: %w", err)
+	}
+
+	query := `SELECT id, action, actor_id, target_type, target_id, ip_address, request_id, before_data, after_data, created_at
+	          FROM audit_log` + where + `
+	          ORDER BY created_at DESC, id DESC
+	          LIMIT ? OFFSET ?`
+	rows, err := r.db.QueryContext(ctx, query, append(args, filter.Limit, filter.Offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list audit entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []*domain.AuditEntry{}
+	for rows.Next() {
+		entry := &domain.AuditEntry{}
+		var actorID, targetID sql.NullInt64
+		var before, after sql.NullString
+		if err := rows.Scan(
+			&entry.ID,
+			&entry.Action,
+			&actorID,
+			&entry.TargetType,
+			&targetID,
+			&entry.IPAddress,
+			&entry.RequestID,
+			&before,
+			&after,
+			&entry.CreatedAt,
+		); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan audit entry: %w", err)
+		}
+
+		if actorID.Valid {
+			entry.ActorID = &actorID.Int64
+		}
+		if targetID.Valid {
+			entry.TargetID = &targetID.Int64
+		}
+		if before.Valid {
+			entry.Before = json.RawMessage(before.String)
+		}
+		if after.Valid {
+			entry.After = json.RawMessage(after.String)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, total, rows.Err()
+}
@@ -0,0 +1,366 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// defaultCopyBatchSize is how many rows go into each INSERT
+const defaultCopyBatchSize = 100
+
+// ErrTargetNotEmpty is returned when copying into a database that already
+// has accounts, which the copy would replace
+var ErrTargetNotEmpty = errors.New("target database already has users; pass overwrite to replace its data")
+
+// CopyOptions controls CopyDatabase
+type CopyOptions struct {
+	Overwrite bool      // Replace the target's data even if it has users
+	BatchSize int       // Rows per INSERT (default 100)
+	Progress  io.Writer // Where progress is printed (nil prints nothing)
+}
+
+// TableCopy is the outcome of copying one table
+type TableCopy struct {
+	Table      string
+	SourceRows int64
+	TargetRows int64
+}
+
+// CopyDatabase copies every table from source into target, which may use a
+// different dialect, and returns the row counts of each. Both databases
+// must be migrated to the same version; the target is usually fresh from
+// InitDatabase, whose seed data is replaced.
+//
+// Tables are copied parents first, so foreign keys hold throughout, keeping
+// their IDs. Values are converted to the target's column types: booleans
+// stored as integers become booleans, timestamps stored as text become
+// times, and bytes become text. PostgreSQL's sequences are then moved past
+// the copied IDs. The source is read in one transaction, so it may stay in
+// use, and the target is written in one transaction, so a failed copy
+// leaves it as it was. The row counts are checked once it is committed.
+func CopyDatabase(ctx context.Context, source, target *sql.DB, opts CopyOptions) ([]TableCopy, error) {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultCopyBatchSize
+	}
+	if opts.Progress == nil {
+		opts.Progress = io.Discard
+	}
+	src, dst := NewDB(source), NewDB(target)
+
+	sourceVersion, err := appliedVersion(ctx, source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read source migrations: %w", err)
+	}
+	targetVersion, err := appliedVersion(ctx, target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read target migrations: %w", err)
+	}
+	if sourceVersion != targetVersion {
+		return nil, fmt.Errorf("source is at migration %d but target is at %d; migrate both to the same version first", sourceVersion, targetVersion)
+	}
+
+	sourceSchema, err := InspectSchema(ctx, source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read source schema: %w", err)
+	}
+	targetSchema, err := InspectSchema(ctx, target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read target schema: %w", err)
+	}
+	tables, err := copyOrder(targetSchema)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range sourceSchema.TableNames() {
+		if _, ok := targetSchema.Tables[name]; !ok {
+			return nil, fmt.Errorf("target has no table %s", name)
+		}
+	}
+
+	if !opts.Overwrite {
+		if _, ok := targetSchema.Tables["users"]; ok {
+			var users int64
+			if err := dst.QueryRowContext(ctx, `SELECT COUNT(*) FROM users`).Scan(&users); err != nil {
+				return nil, fmt.Errorf("failed to count target users: %w", err)
+			}
+			if users > 0 {
+				return nil, ErrTargetNotEmpty
+			}
+		}
+	}
+
+	// A repeatable read transaction sees one snapshot of the source; SQLite
+	// transactions always do
+	readOpts := &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}
+	if src.Dialect == DialectSQLite {
+		readOpts = nil
+	}
+	readTx, err := src.DB.BeginTx(ctx, readOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin source transaction: %w", err)
+	}
+	defer readTx.Rollback()
+	writeTx, err := dst.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin target transaction: %w", err)
+	}
+	defer writeTx.Rollback()
+	reader := &Tx{Tx: readTx, Dialect: src.Dialect}
+	writer := &Tx{Tx: writeTx, Dialect: dst.Dialect}
+
+	// Children go first when clearing the target
+	for i := len(tables) - 1; i >= 0; i-- {
+		if _, err := writer.ExecContext(ctx, "DELETE FROM "+quoteIdent(dst.Dialect, tables[i])); err != nil {
+			return nil, fmt.Errorf("failed to clear target table %s: %w", tables[i], err)
+		}
+	}
+
+	copies := make([]TableCopy, 0, len(tables))
+	for _, name := range tables {
+		sourceTable, ok := sourceSchema.Tables[name]
+		if !ok {
+			// A table the source never had stays empty
+			copies = append(copies, TableCopy{Table: name})
+			continue
+		}
+		rows, err := copyTable(ctx, reader, writer, sourceTable, targetSchema.Tables[name], opts.BatchSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to copy %s: %w", name, err)
+		}
+		fmt.Fprintf(opts.Progress, "Copied %d rows of %s\n", rows, name)
+		copies = append(copies, TableCopy{Table: name, SourceRows: rows})
+	}
+
+	if dst.Dialect == DialectPostgres {
+		if err := resetSequences(ctx, writer, targetSchema, tables); err != nil {
+			return nil, err
+		}
+	}
+	if err := writeTx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit target transaction: %w", err)
+	}
+
+	// Verify against the snapshot that was copied
+	var mismatched []string
+	for i := range copies {
+		table := quoteIdent(dst.Dialect, copies[i].Table)
+		if err := dst.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+table).Scan(&copies[i].TargetRows); err != nil {
+			return copies, fmt.Errorf("failed to count rows of %s: %w", copies[i].Table, err)
+		}
+		if copies[i].TargetRows != copies[i].SourceRows {
+			mismatched = append(mismatched, fmt.Sprintf("%s has %d rows, expected %d", copies[i].Table, copies[i].TargetRows, copies[i].SourceRows))
+		}
+	}
+	if len(mismatched) > 0 {
+		return copies, fmt.Errorf("row counts differ after copying: %s", strings.Join(mismatched, "; "))
+	}
+	return copies, nil
+}
+
+// copyOrder sorts a schema's tables so that every table comes after the
+// tables its foreign keys reference, and otherwise by name
+func copyOrder(schema *Schema) ([]string, error) {
+	var order []string
+	done := make(map[string]bool)
+	visiting := make(map[string]bool)
+
+	var visit func(name string) error
+	visit = func(name string) error {
+		if done[name] {
+			return nil
+		}
+		if visiting[name] {
+			return fmt.Errorf("foreign keys of %s form a cycle; it can't be copied parents first", name)
+		}
+		visiting[name] = true
+		for _, key := range schema.Tables[name].ForeignKeys {
+			if key.RefTable == name {
+				// Rows are copied in ID order, so parents come first
+				continue
+			}
+			if _, ok := schema.Tables[key.RefTable]; ok {
+				if err := visit(key.RefTable); err != nil {
+					return err
+				}
+			}
+		}
+		visiting[name] = false
+		done[name] = true
+		order = append(order, name)
+		return nil
+	}
+
+	for _, name := range schema.TableNames() {
+		if err := visit(name); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// copyTable copies the rows of one table in batches and returns how many
+// there were
+func copyTable(ctx context.Context, reader, writer *Tx, source, target *TableSchema, batchSize int) (int64, error) {
+	var columns, orderBy []string
+	for _, column := range sortedColumns(target) {
+		if _, ok := source.Columns[column.Name]; ok {
+			columns = append(columns, column.Name)
+		}
+		if column.PrimaryKey {
+			orderBy = append(orderBy, quoteIdent(reader.Dialect, column.Name))
+		}
+	}
+	for name := range source.Columns {
+		if _, ok := target.Columns[name]; !ok {
+			return 0, fmt.Errorf("target has no column %s", name)
+		}
+	}
+
+	selectList := make([]string, len(columns))
+	insertList := make([]string, len(columns))
+	for i, column := range columns {
+		selectList[i] = quoteIdent(reader.Dialect, column)
+		insertList[i] = quoteIdent(writer.Dialect, column)
+	}
+	query := fmt.Sprintf("SELECT %s FROM %s", strings.Join(selectList, ", "), quoteIdent(reader.Dialect, source.Name))
+	if len(orderBy) > 0 {
+		query += " ORDER BY " + strings.Join(orderBy, ", ")
+	}
+	rows, err := reader.QueryContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	placeholders := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ") + ")"
+	insert := func(batch []interface{}, n int) error {
+		values := strings.TrimSuffix(strings.Repeat(placeholders+", ", n), ", ")
+		_, err := writer.ExecContext(ctx, fmt.Sprintf("INSERT INTO %s (%s) VALUES %s",
+			quoteIdent(writer.Dialect, target.Name), strings.Join(insertList, ", "), values), batch...)
+		return err
+	}
+
+	var count int64
+	batch := make([]interface{}, 0, batchSize*len(columns))
+	n := 0
+	values := make([]interface{}, len(columns))
+	pointers := make([]interface{}, len(columns))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(pointers...); err != nil {
+			return count, err
+		}
+		for i, column := range columns {
+			value, err := convertValue(values[i], target.Columns[column].Type)
+			if err != nil {
+				return count, fmt.Errorf("column %s: %w", column, err)
+			}
+			batch = append(batch, value)
+		}
+		n++
+		count++
+		if n == batchSize {
+			if err := insert(batch, n); err != nil {
+				return count, err
+			}
+			batch, n = batch[:0], 0
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return count, err
+	}
+	if n > 0 {
+		if err := insert(batch, n); err != nil {
+			return count, err
+		}
+	}
+	return count, nil
+}
+
+// convertValue converts a value read from the source for a column of the
+// target's type
+func convertValue(value interface{}, columnType string) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	columnType = strings.ToLower(columnType)
+	if b, ok := value.([]byte); ok && !isBinaryType(columnType) {
+		// MySQL returns text as bytes
+		value = string(b)
+	}
+
+	switch {
+	case columnType == "boolean" || columnType == "bool" || columnType == "tinyint(1)":
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case int64:
+			return v != 0, nil
+		case string:
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid boolean %q", v)
+			}
+			return b, nil
+		}
+	case strings.Contains(columnType, "timestamp") || strings.Contains(columnType, "datetime") || columnType == "date":
+		if v, ok := value.(string); ok {
+			return parseTimestamp(v)
+		}
+	}
+	return value, nil
+}
+
+// isBinaryType reports whether a column type holds bytes rather than text
+func isBinaryType(columnType string) bool {
+	return strings.Contains(columnType, "blob") || strings.Contains(columnType, "bytea") || strings.Contains(columnType, "binary")
+}
+
+// parseTimestamp parses a timestamp stored as text, in any of the formats
+// the SQLite driver reads and writes
+func parseTimestamp(value string) (time.Time, error) {
+	value = strings.TrimSuffix(value, "Z")
+	for _, format := range sqlite3.SQLiteTimestampFormats {
+		if t, err := time.ParseInLocation(format, value, time.UTC); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid timestamp %q", value)
+}
+
+// resetSequences moves each PostgreSQL sequence that fills in a copied
+// column past the largest value copied, so new rows don't collide with
+// copied IDs
+func resetSequences(ctx context.Context, tx *Tx, schema *Schema, tables []string) error {
+	for _, name := range tables {
+		for _, column := range sortedColumns(schema.Tables[name]) {
+			if !column.Default.Valid || !strings.HasPrefix(column.Default.String, "nextval(") {
+				continue
+			}
+			query := fmt.Sprintf(`SELECT setval(pg_get_serial_sequence(?, ?), COALESCE(MAX(%[1]s), 0) + 1, false) FROM %[2]s`,
+				quoteIdent(DialectPostgres, column.Name), quoteIdent(DialectPostgres, name))
+			if _, err := tx.ExecContext(ctx, query, name, column.Name); err != nil {
+				return fmt.Errorf("failed to reset sequence of %s.%s: %w", name, column.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// quoteIdent quotes a table or column name for a dialect
+func quoteIdent(dialect Dialect, name string) string {
+	if dialect == DialectMySQL {
+		return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
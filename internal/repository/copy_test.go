@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCopyDatabase(t *testing.T) {
+	dir := t.TempDir()
+	source, err := InitDatabase("sqlite3", filepath.Join(dir, "source.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer source.Close()
+	target, err := InitDatabase("sqlite3", filepath.Join(dir, "target.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer target.Close()
+	ctx := context.Background()
+
+	// IDs with gaps, so that preserving them is checked
+	now := time.Date(2025, 10, 6, 7, 30, 0, 0, time.UTC)
+	for _, query := range []string{
+		`INSERT INTO users (id, email, password_hash, name, role, email_verified, created_at, updated_at) VALUES (7, 'a@example.com', 'x', 'A', 'admin', 1, ?, ?)`,
+		`INSERT INTO workouts (id, name, created_by, created_at, updated_at) VALUES (42, 'Monday', 7, ?, ?)`,
+		`INSERT INTO user_workouts (id, user_id, workout_id, workout_date, created_at, updated_at) VALUES (9, 7, 42, DATE('2025-10-06'), ?, ?)`,
+	} {
+		if _, err := source.Exec(query, now, now); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := source.Exec(`DELETE FROM movements WHERE id = (SELECT MIN(id) FROM movements)`); err != nil {
+		t.Fatal(err)
+	}
+
+	copies, err := CopyDatabase(ctx, source, target, CopyOptions{BatchSize: 7})
+	if err != nil {
+		t.Fatalf("CopyDatabase failed: %v", err)
+	}
+
+	// Parents are copied before their children
+	position := make(map[string]int)
+	for i, table := range copies {
+		position[table.Table] = i
+		if table.SourceRows != table.TargetRows {
+			t.Errorf("%s: copied %d rows of %d", table.Table, table.TargetRows, table.SourceRows)
+		}
+	}
+	if !(position["users"] < position["workouts"] && position["workouts"] < position["user_workouts"]) {
+		t.Errorf("expected users, workouts and user_workouts in dependency order, got %v", copies)
+	}
+
+	// The target's own seed data is replaced by the source's
+	for _, table := range []string{"movements", "wods", "workouts", "user_workouts"} {
+		var want, got int
+		source.QueryRow(`SELECT COUNT(*) FROM ` + table).Scan(&want)
+		target.QueryRow(`SELECT COUNT(*) FROM ` + table).Scan(&got)
+		if got != want {
+			t.Errorf("%s: expected %d rows, got %d", table, want, got)
+		}
+	}
+
+	var (
+		name      string
+		createdBy int64
+		createdAt time.Time
+		verified  bool
+	)
+	if err := target.QueryRow(`SELECT w.name, w.created_by, w.created_at, u.email_verified FROM user_workouts uw JOIN workouts w ON w.id = uw.workout_id JOIN users u ON u.id = uw.user_id WHERE uw.id = 9`).Scan(&name, &createdBy, &createdAt, &verified); err != nil {
+		t.Fatalf("expected the copied rows to keep their IDs: %v", err)
+	}
+	if name != "Monday" || createdBy != 7 || !createdAt.Equal(now) || !verified {
+		t.Errorf("unexpected copied workout %q by %d at %v (verified %t)", name, createdBy, createdAt, verified)
+	}
+
+	// New rows continue after the copied IDs
+	id, err := NewDB(target).Insert(`INSERT INTO users (email, password_hash, name, role, created_at, updated_at) VALUES ('b@example.com', 'x', 'B', 'user', ?, ?)`, now, now)
+	if err != nil || id <= 7 {
+		t.Errorf("expected a new user after ID 7, got %d (%v)", id, err)
+	}
+
+	// A target with accounts is left alone unless overwriting
+	if _, err := CopyDatabase(ctx, source, target, CopyOptions{}); !errors.Is(err, ErrTargetNotEmpty) {
+		t.Errorf("expected ErrTargetNotEmpty, got %v", err)
+	}
+	if _, err := CopyDatabase(ctx, source, target, CopyOptions{Overwrite: true}); err != nil {
+		t.Errorf("expected an overwriting copy to succeed, got %v", err)
+	}
+	var users int
+	target.QueryRow(`SELECT COUNT(*) FROM users`).Scan(&users)
+	if users != 1 {
+		t.Errorf("expected the overwritten target to have the source's user, got %d users", users)
+	}
+
+	// Databases at different migrations can't be copied
+	behind, err := sql.Open("sqlite3", filepath.Join(dir, "behind.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer behind.Close()
+	migrator, err := NewMigrator(behind)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.Goto(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := CopyDatabase(ctx, behind, target, CopyOptions{Overwrite: true}); err == nil {
+		t.Error("expected databases at different migrations to be refused")
+	}
+}
+
+func TestConvertValue(t *testing.T) {
+	want := time.Date(2025, 10, 6, 7, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		value      interface{}
+		columnType string
+		expected   interface{}
+	}{
+		{"integer to boolean", int64(1), "boolean", true},
+		{"integer to MySQL boolean", int64(0), "tinyint(1)", false},
+		{"text to boolean", []byte("1"), "boolean", true},
+		{"boolean to integer", true, "INTEGER", true},
+		{"text to timestamp", "2025-10-06 07:30:00", "timestamp without time zone", want},
+		{"ISO text to datetime", "2025-10-06T07:30:00Z", "datetime", want},
+		{"bytes to text", []byte("Fran"), "text", "Fran"},
+		{"bytes stay bytes", []byte("key"), "bytea", []byte("key")},
+		{"null", nil, "boolean", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := convertValue(tt.value, tt.columnType)
+			if err != nil {
+				t.Fatal(err)
+			}
+			switch expected := tt.expected.(type) {
+			case time.Time:
+				if got, ok := got.(time.Time); !ok || !got.Equal(expected) {
+					t.Errorf("expected %v, got %v", expected, got)
+				}
+			case []byte:
+				if got, ok := got.([]byte); !ok || string(got) != string(expected) {
+					t.Errorf("expected %v, got %v", expected, got)
+				}
+			default:
+				if got != tt.expected {
+					t.Errorf("expected %v (%T), got %v (%T)", tt.expected, tt.expected, got, got)
+				}
+			}
+		})
+	}
+
+	if _, err := convertValue("yes please", "boolean"); err == nil {
+		t.Error("expected an invalid boolean to be rejected")
+	}
+}
@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+)
+
+// DB is a connection that speaks its database's dialect. It embeds *sql.DB
+// and rebinds the ? placeholders of every query it runs, so repositories can
+// write one query for all three databases. Queries run with a context that
+// carries a Transactor's transaction on the same connection run in it.
+type DB struct {
+	*sql.DB
+	Dialect Dialect
+}
+
+// NewDB wraps a connection, detecting its dialect from the driver
+func NewDB(db *sql.DB) *DB {
+	return &DB{DB: db, Dialect: DialectOf(db)}
+}
+
+// Exec executes a query without returning any rows
+func (db *DB) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return db.DB.Exec(db.Dialect.Rebind(query), args...)
+}
+
+// ExecContext executes a query without returning any rows
+func (db *DB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	if tx := txFrom(ctx, db.DB); tx != nil {
+		return tx.ExecContext(ctx, db.Dialect.Rebind(query), args...)
+	}
+	return db.DB.ExecContext(ctx, db.Dialect.Rebind(query), args...)
+}
+
+// Query executes a query that returns rows
+func (db *DB) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return db.DB.Query(db.Dialect.Rebind(query), args...)
+}
+
+// QueryContext executes a query that returns rows
+func (db *DB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	if tx := txFrom(ctx, db.DB); tx != nil {
+		return tx.QueryContext(ctx, db.Dialect.Rebind(query), args...)
+	}
+	return db.DB.QueryContext(ctx, db.Dialect.Rebind(query), args...)
+}
+
+// QueryRow executes a query that returns at most one row
+func (db *DB) QueryRow(query string, args ...interface{}) *sql.Row {
+	return db.DB.QueryRow(db.Dialect.Rebind(query), args...)
+}
+
+// QueryRowContext executes a query that returns at most one row
+func (db *DB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	if tx := txFrom(ctx, db.DB); tx != nil {
+		return tx.QueryRowContext(ctx, db.Dialect.Rebind(query), args...)
+	}
+	return db.DB.QueryRowContext(ctx, db.Dialect.Rebind(query), args...)
+}
+
+// Prepare creates a prepared statement
+func (db *DB) Prepare(query string) (*sql.Stmt, error) {
+	return db.DB.Prepare(db.Dialect.Rebind(query))
+}
+
+// PrepareContext creates a prepared statement
+func (db *DB) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	if tx := txFrom(ctx, db.DB); tx != nil {
+		return tx.PrepareContext(ctx, db.Dialect.Rebind(query))
+	}
+	return db.DB.PrepareContext(ctx, db.Dialect.Rebind(query))
+}
+
+// Begin starts a transaction
+func (db *DB) Begin() (*Tx, error) {
+	return db.BeginTx(context.Background(), nil)
+}
+
+// BeginTx starts a transaction. If ctx carries a transaction on the same
+// connection, the returned Tx joins it instead, and leaves committing or
+// rolling back to its owner.
+func (db *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
+	if tx := txFrom(ctx, db.DB); tx != nil {
+		return &Tx{Tx: tx, Dialect: db.Dialect, joined: true}, nil
+	}
+	tx, err := db.DB.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{Tx: tx, Dialect: db.Dialect}, nil
+}
+
+// Insert executes an INSERT into a table with an id primary key and returns
+// the new row's ID
+func (db *DB) Insert(query string, args ...interface{}) (int64, error) {
+	return db.InsertContext(context.Background(), query, args...)
+}
+
+// InsertContext executes an INSERT into a table with an id primary key and
+// returns the new row's ID
+func (db *DB) InsertContext(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	return insert(ctx, db.Dialect, db.ExecContext, db.QueryRowContext, query, args...)
+}
+
+// Tx is a transaction that speaks its database's dialect, like DB
+type Tx struct {
+	*sql.Tx
+	Dialect Dialect
+
+	// joined is set when the transaction belongs to an enclosing Transactor
+	joined bool
+}
+
+// Commit commits the transaction, unless it was joined
+func (tx *Tx) Commit() error {
+	if tx.joined {
+		return nil
+	}
+	return tx.Tx.Commit()
+}
+
+// Rollback aborts the transaction, unless it was joined. A joined
+// transaction is rolled back by its owner when the error that made the
+// caller give up reaches it.
+func (tx *Tx) Rollback() error {
+	if tx.joined {
+		return nil
+	}
+	return tx.Tx.Rollback()
+}
+
+// Exec executes a query without returning any rows
+func (tx *Tx) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return tx.Tx.Exec(tx.Dialect.Rebind(query), args...)
+}
+
+// ExecContext executes a query without returning any rows
+func (tx *Tx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return tx.Tx.ExecContext(ctx, tx.Dialect.Rebind(query), args...)
+}
+
+// Query executes a query that returns rows
+func (tx *Tx) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return tx.Tx.Query(tx.Dialect.Rebind(query), args...)
+}
+
+// QueryContext executes a query that returns rows
+func (tx *Tx) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return tx.Tx.QueryContext(ctx, tx.Dialect.Rebind(query), args...)
+}
+
+// QueryRow executes a query that returns at most one row
+func (tx *Tx) QueryRow(query string, args ...interface{}) *sql.Row {
+	return tx.Tx.QueryRow(tx.Dialect.Rebind(query), args...)
+}
+
+// QueryRowContext executes a query that returns at most one row
+func (tx *Tx) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return tx.Tx.QueryRowContext(ctx, tx.Dialect.Rebind(query), args...)
+}
+
+// Prepare creates a prepared statement for use within the transaction
+func (tx *Tx) Prepare(query string) (*sql.Stmt, error) {
+	return tx.Tx.Prepare(tx.Dialect.Rebind(query))
+}
+
+// PrepareContext creates a prepared statement for use within the transaction
+func (tx *Tx) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return tx.Tx.PrepareContext(ctx, tx.Dialect.Rebind(query))
+}
+
+// Insert executes an INSERT into a table with an id primary key and returns
+// the new row's ID
+func (tx *Tx) Insert(query string, args ...interface{}) (int64, error) {
+	return tx.InsertContext(context.Background(), query, args...)
+}
+
+// InsertContext executes an INSERT into a table with an id primary key and
+// returns the new row's ID
+func (tx *Tx) InsertContext(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	return insert(ctx, tx.Dialect, tx.ExecContext, tx.QueryRowContext, query, args...)
+}
+
+// insert runs an INSERT with functions that rebind it and returns the new
+// row's ID, from RETURNING id where the dialect needs it and LastInsertId
+// otherwise
+func insert(
+	ctx context.Context,
+	d Dialect,
+	exec func(context.Context, string, ...interface{}) (sql.Result, error),
+	queryRow func(context.Context, string, ...interface{}) *sql.Row,
+	query string,
+	args ...interface{},
+) (int64, error) {
+	if d.ReturningID() {
+		var id int64
+		err := queryRow(ctx, query+" RETURNING id", args...).Scan(&id)
+		return id, err
+	}
+
+	result, err := exec(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+)
+
+// Dialect is the flavour of SQL a database speaks. Repositories write their
+// queries for SQLite, with ? placeholders, and use the dialect for the few
+// places where PostgreSQL and MySQL differ.
+type Dialect string
+
+// Supported dialects, named after their database/sql drivers
+const (
+	DialectSQLite   Dialect = "sqlite3"
+	DialectPostgres Dialect = "postgres"
+	DialectMySQL    Dialect = "mysql"
+)
+
+// DialectOf returns the dialect of the driver behind a connection
+func DialectOf(db *sql.DB) Dialect {
+	switch db.Driver().(type) {
+	case *pq.Driver:
+		return DialectPostgres
+	case *mysql.MySQLDriver:
+		return DialectMySQL
+	default:
+		return DialectSQLite
+	}
+}
+
+// Rebind rewrites the ? placeholders in a query into the dialect's bind
+// variables. PostgreSQL numbers them ($1, $2, ...); SQLite and MySQL take the
+// query as is. Question marks inside quoted strings and identifiers are left
+// alone.
+func (d Dialect) Rebind(query string) string {
+	if d != DialectPostgres || !strings.Contains(query, "?") {
+		return query
+	}
+
+	var b strings.Builder
+	b.Grow(len(query) + 8)
+	n := 0
+	var quote byte
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '?':
+			n++
+			b.WriteByte('$')
+			b.WriteString(strconv.Itoa(n))
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// Bool returns the literal for a boolean value. SQLite stores booleans as
+// integers; PostgreSQL won't compare a BOOLEAN column with one.
+func (d Dialect) Bool(v bool) string {
+	switch {
+	case d == DialectSQLite && v:
+		return "1"
+	case d == DialectSQLite:
+		return "0"
+	case v:
+		return "TRUE"
+	default:
+		return "FALSE"
+	}
+}
+
+// Now returns the expression for the current timestamp
+func (d Dialect) Now() string {
+	switch d {
+	case DialectSQLite:
+		return "datetime('now')"
+	case DialectMySQL:
+		return "NOW()"
+	default:
+		return "CURRENT_TIMESTAMP"
+	}
+}
+
+// Limit returns a LIMIT/OFFSET clause, with a leading space, for a page of
+// results. A limit of zero or less means no limit, and an offset of zero or
+// less means none; SQLite and MySQL can't take an OFFSET without a LIMIT, so
+// they get the largest one they accept.
+func (d Dialect) Limit(limit, offset int) string {
+	var clause string
+	switch {
+	case limit > 0:
+		clause = " LIMIT " + strconv.Itoa(limit)
+	case offset <= 0:
+		return ""
+	case d == DialectSQLite:
+		clause = " LIMIT -1"
+	case d == DialectMySQL:
+		clause = " LIMIT 18446744073709551615"
+	default:
+		clause = " LIMIT ALL"
+	}
+	if offset > 0 {
+		clause += " OFFSET " + strconv.Itoa(offset)
+	}
+	return clause
+}
+
+// containsPattern returns a LIKE pattern matching text anywhere in a value,
+// with the % and _ in text escaped by !. Use it with ESCAPE '!', which every
+// dialect reads the same way, unlike a backslash in MySQL.
+func containsPattern(text string) string {
+	return "%" + likeEscaper.Replace(text) + "%"
+}
+
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+// Upsert returns the clause that turns an INSERT into an update of the given
+// columns when a row with the same conflict columns (a primary key or unique
+// index) already exists. It goes at the end of the INSERT.
+func (d Dialect) Upsert(conflict []string, update ...string) string {
+	sets := make([]string, len(update))
+	for i, col := range update {
+		if d == DialectMySQL {
+			sets[i] = fmt.Sprintf("%s = VALUES(%s)", col, col)
+		} else {
+			sets[i] = fmt.Sprintf("%s = excluded.%s", col, col)
+		}
+	}
+
+	if d == DialectMySQL {
+		return " ON DUPLICATE KEY UPDATE " + strings.Join(sets, ", ")
+	}
+	return fmt.Sprintf(" ON CONFLICT (%s) DO UPDATE SET %s", strings.Join(conflict, ", "), strings.Join(sets, ", "))
+}
+
+// ReturningID reports whether the dialect reports a new row's ID with
+// INSERT ... RETURNING id instead of through sql.Result.LastInsertId, which
+// lib/pq doesn't support
+func (d Dialect) ReturningID() bool {
+	return d == DialectPostgres
+}
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/johnzastrow/actalog/internal/domain"
+)
+
+func TestRebind(t *testing.T) {
+	query := `SELECT id FROM users WHERE email = ? AND name <> '?' AND "col?" = ? LIMIT ?`
+
+	tests := []struct {
+		dialect Dialect
+		want    string
+	}{
+		{DialectSQLite, query},
+		{DialectMySQL, query},
+		{DialectPostgres, `SELECT id FROM users WHERE email = $1 AND name <> '?' AND "col?" = $2 LIMIT $3`},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.dialect), func(t *testing.T) {
+			if got := tt.dialect.Rebind(query); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestDialectClauses(t *testing.T) {
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"sqlite true", DialectSQLite.Bool(true), "1"},
+		{"postgres false", DialectPostgres.Bool(false), "FALSE"},
+		{"mysql true", DialectMySQL.Bool(true), "TRUE"},
+
+		{"no limit", DialectSQLite.Limit(0, 0), ""},
+		{"limit", DialectPostgres.Limit(10, 0), " LIMIT 10"},
+		{"limit and offset", DialectMySQL.Limit(10, 20), " LIMIT 10 OFFSET 20"},
+		{"sqlite offset only", DialectSQLite.Limit(0, 20), " LIMIT -1 OFFSET 20"},
+		{"postgres offset only", DialectPostgres.Limit(0, 20), " LIMIT ALL OFFSET 20"},
+		{"mysql offset only", DialectMySQL.Limit(0, 20), " LIMIT 18446744073709551615 OFFSET 20"},
+
+		{"sqlite upsert", DialectSQLite.Upsert([]string{"user_id"}, "a", "b"), " ON CONFLICT (user_id) DO UPDATE SET a = excluded.a, b = excluded.b"},
+		{"postgres upsert", DialectPostgres.Upsert([]string{"user_id", "kind"}, "a"), " ON CONFLICT (user_id, kind) DO UPDATE SET a = excluded.a"},
+		{"mysql upsert", DialectMySQL.Upsert([]string{"user_id"}, "a", "b"), " ON DUPLICATE KEY UPDATE a = VALUES(a), b = VALUES(b)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, tt.got)
+			}
+		})
+	}
+}
+
+func TestDB(t *testing.T) {
+	sqlDB, err := InitDatabase("sqlite3", t.TempDir()+"/actalog.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sqlDB.Close()
+
+	db := NewDB(sqlDB)
+	if db.Dialect != DialectSQLite {
+		t.Fatalf("expected the sqlite3 driver to be detected, got %q", db.Dialect)
+	}
+
+	userRepo := NewSQLiteUserRepository(sqlDB)
+	first := &domain.User{Email: "first@example.com", Name: "First", Role: "user"}
+	second := &domain.User{Email: "second@example.com", Name: "Second", Role: "user"}
+	for _, u := range []*domain.User{first, second} {
+		if err := userRepo.Create(context.Background(), u); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if first.ID == 0 || second.ID != first.ID+1 {
+		t.Errorf("expected Insert to return consecutive IDs, got %d and %d", first.ID, second.ID)
+	}
+
+	// Saving a lockout twice updates the row instead of adding another
+	lockouts := NewLoginLockoutRepository(sqlDB)
+	now := time.Now()
+	for attempts := 1; attempts <= 2; attempts++ {
+		err := lockouts.Save(context.Background(), &domain.LoginLockout{UserID: first.ID, FailedAttempts: attempts, LastFailureAt: now})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	saved, err := lockouts.Get(context.Background(), first.ID)
+	if err != nil || saved == nil || saved.FailedAttempts != 2 {
+		t.Errorf("expected the second save to win, got %+v (%v)", saved, err)
+	}
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM login_lockouts WHERE user_id = ?`, first.ID).Scan(&count); err != nil || count != 1 {
+		t.Errorf("expected one lockout row, got %d (%v)", count, err)
+	}
+
+	// Standard WODs can be paged past without a limit
+	wods := NewWODRepository(sqlDB)
+	all, err := wods.ListStandard(context.Background(), 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rest, err := wods.ListStandard(context.Background(), 0, 1)
+	if err != nil {
+		t.Fatalf("expected an offset without a limit to work, got %v", err)
+	}
+	if len(rest) != len(all)-1 {
+		t.Errorf("expected %d WODs after the first, got %d", len(all)-1, len(rest))
+	}
+
+	// Queries stop when their context is canceled
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := wods.ListStandard(canceled, 0, 0); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected a canceled context to cancel the query, got %v", err)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	id, err := tx.Insert(`INSERT INTO workouts (name, created_at, updated_at) VALUES (?, ?, ?)`, "In a transaction", now, now)
+	if err != nil || id == 0 {
+		t.Fatalf("expected an ID from Insert in a transaction, got %d (%v)", id, err)
+	}
+	if err := tx.QueryRow(`SELECT id FROM workouts WHERE name = ?`, "In a transaction").Scan(&id); err == sql.ErrNoRows {
+		t.Error("expected the row to be visible in its transaction")
+	}
+}
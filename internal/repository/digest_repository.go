@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// DigestRepository implements domain.DigestRepository
+type DigestRepository struct {
+	db *DB
+}
+
+// NewDigestRepository creates a new digest repository
+func NewDigestRepository(db *sql.DB) *DigestRepository {
+	return &DigestRepository{db: NewDB(db)}
+}
+
+// digestWeekKey formats a week start as a driver-independent DATE string
+func digestWeekKey(weekStart time.Time) string {
+	return weekStart.UTC().Format("2006-01-02")
+}
+
+// Claim records the digest for a user and week. Returns false if it was already recorded.
+func (r *DigestRepository) Claim(ctx context.Context, userID int64, weekStart time.Time) (bool, error) {
+	week := digestWeekKey(weekStart)
+
+	var count int
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM digest_deliveries WHERE user_id = ? AND week_start = ?`, userID, week).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("failed to check digest delivery: %w", err)
+	}
+	if count > 0 {
+		return false, nil
+	}
+
+	_, err = r.db.ExecContext(ctx, `INSERT INTO digest_deliveries (user_id, week_start, created_at) VALUES (?, ?, ?)`, userID, week, time.Now())
+	if err != nil {
+		// Another process may have claimed it between the check and the insert;
+		// the unique index rejects the duplicate.
+		if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM digest_deliveries WHERE user_id = ? AND week_start = ?`, userID, week).Scan(&count); err == nil && count > 0 {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to record digest delivery: %w", err)
+	}
+
+	return true, nil
+}
+
+// Release removes a claim so the digest can be retried
+func (r *DigestRepository) Release(ctx context.Context, userID int64, weekStart time.Time) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM digest_deliveries WHERE user_id = ? AND week_start = ?`, userID, digestWeekKey(weekStart))
+	if err != nil {
+		return fmt.Errorf("failed to release digest delivery: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/johnzastrow/actalog/internal/domain"
+)
+
+// EmailOutboxRepository implements domain.EmailOutboxRepository
+type EmailOutboxRepository struct {
+	db *DB
+}
+
+// NewEmailOutboxRepository creates a new email outbox repository
+func NewEmailOutboxRepository(db *sql.DB) *EmailOutboxRepository {
+	return &EmailOutboxRepository{db: NewDB(db)}
+}
+
+const outboxColumns = `id, recipients, subject, body, text_body, is_html, status, attempts, last_error, next_attempt_at, sent_at, created_at, updated_at`
+
+// scanOutboxEmail scans an email_outbox row into a domain.OutboxEmail
+func scanOutboxEmail(scanner interface{ Scan(...interface{}) error }) (*domain.OutboxEmail, error) {
+	email := &domain.OutboxEmail{}
+	var recipients string
+	var textBody, lastError sql.NullString
+	var sentAt sql.NullTime
+	err := scanner.Scan(
+		&email.ID,
+		&recipients,
+		&email.Subject,
+		&email.Body,
+		&textBody,
+		&email.IsHTML,
+		&email.Status,
+		&email.Attempts,
+		&lastError,
+		&email.NextAttemptAt,
+		&sentAt,
+		&email.CreatedAt,
+		&email.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	email.Recipients = splitRecipients(recipients)
+	email.TextBody = textBody.String
+	if lastError.Valid {
+		email.LastError = &lastError.String
+	}
+	if sentAt.Valid {
+		email.SentAt = &sentAt.Time
+	}
+	return email, nil
+}
+
+// splitRecipients parses a stored recipient list. Display names may contain
+// commas, so the list is parsed as RFC 5322 addresses rather than split.
+func splitRecipients(recipients string) []string {
+	addrs, err := mail.ParseAddressList(recipients)
+	if err != nil {
+		return strings.Split(recipients, ",")
+	}
+
+	list := make([]string, len(addrs))
+	for i, addr := range addrs {
+		list[i] = addr.String()
+	}
+	return list
+}
+
+// Enqueue stores a new pending email
+func (r *EmailOutboxRepository) Enqueue(ctx context.Context, email *domain.OutboxEmail) error {
+	now := time.Now()
+	email.CreatedAt = now
+	email.UpdatedAt = now
+	if email.Status == "" {
+		email.Status = domain.OutboxStatusPending
+	}
+	if email.NextAttemptAt.IsZero() {
+		email.NextAttemptAt = now
+	}
+
+	query := `INSERT INTO email_outbox (recipients, subject, body, text_body, is_html, status, attempts, last_error, next_attempt_at, sent_at, created_at, updated_at)
+	          VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	id, err := r.db.InsertContext(ctx, query,
+		strings.Join(email.Recipients, ", "),
+		email.Subject,
+		email.Body,
+		email.TextBody,
+		email.IsHTML,
+		email.Status,
+		email.Attempts,
+		email.LastError,
+		email.NextAttemptAt,
+		email.SentAt,
+		email.CreatedAt,
+		email.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue email: %w", err)
+	}
+
+	email.ID = id
+	return nil
+}
+
+// GetByID retrieves an outbox email by ID
+func (r *EmailOutboxRepository) GetByID(ctx context.Context, id int64) (*domain.OutboxEmail, error) {
+	query := `SELECT ` + outboxColumns + ` FROM email_outbox WHERE id = ?`
+
+	email, err := scanOutboxEmail(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get outbox email: %w", err)
+	}
+
+	return email, nil
+}
+
+// ListDue retrieves pending emails whose next attempt is due, oldest first
+func (r *EmailOutboxRepository) ListDue(ctx context.Context, now time.Time, limit int) ([]*domain.OutboxEmail, error) {
+	query := `SELECT ` + outboxColumns + ` FROM email_outbox
+	          WHERE status = ? AND next_attempt_at <= ?
+	          ORDER BY next_attempt_at, id
+	          LIMIT ?`
+	return r.list(ctx, query, domain.OutboxStatusPending, now, limit)
+}
+
+// ListByStatus retrieves emails with the given status, newest first
+func (r *EmailOutboxRepository) ListByStatus(ctx context.Context, status string, limit, offset int) ([]*domain.OutboxEmail, error) {
+	query := `SELECT ` + outboxColumns + ` FROM email_outbox
+	          WHERE status = ?
+	          ORDER BY created_at DESC, id DESC
+	          LIMIT ? OFFSET ?`
+	return r.list(ctx, query, status, limit, offset)
+}
+
+func (r *EmailOutboxRepository) list(ctx context.Context, query string, args ...interface{}) ([]*domain.OutboxEmail, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list outbox emails: %w", err)
+	}
+	defer rows.Close()
+
+	emails := []*domain.OutboxEmail{}
+	for rows.Next() {
+		email, err := scanOutboxEmail(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan outbox email: %w", err)
+		}
+		emails = append(emails, email)
+	}
+
+	return emails, rows.Err()
+}
+
+// CountByStatus returns the number of emails in each status
+func (r *EmailOutboxRepository) CountByStatus(ctx context.Context) (map[string]int, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT status, COUNT(*) FROM email_outbox GROUP BY status`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count outbox emails: %w", err)
+	}
+	defer rows.Close()
+
+	counts := map[string]int{
+		domain.OutboxStatusPending: 0,
+		domain.OutboxStatusSent:    0,
+		domain.OutboxStatusDead:    0,
+	}
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan outbox count: %w", err)
+		}
+		counts[status] = count
+	}
+
+	return counts, rows.Err()
+}
+
+// Update updates an outbox email's delivery state
+func (r *EmailOutboxRepository) Update(ctx context.Context, email *domain.OutboxEmail) error {
+	email.UpdatedAt = time.Now()
+
+	query := `UPDATE email_outbox
+	          SET status = ?, attempts = ?, last_error = ?, next_attempt_at = ?, sent_at = ?, updated_at = ?
+	          WHERE id = ?`
+
+	_, err := r.db.ExecContext(ctx, query,
+		email.Status,
+		email.Attempts,
+		email.LastError,
+		email.NextAttemptAt,
+		email.SentAt,
+		email.UpdatedAt,
+		email.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update outbox email: %w", err)
+	}
+
+	return nil
+}
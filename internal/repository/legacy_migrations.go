@@ -0,0 +1,1620 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// legacyMigration is a schema change from before the SQL migration files.
+// The legacy migrations are frozen: they only bring databases created by
+// older releases up to the baseline, and new changes go in migrations/.
+type legacyMigration struct {
+	Version     string
+	Description string
+	Up          func(*sql.DB, string) error // Takes db and driver
+}
+
+// legacyMigrations holds the legacy migrations in order. The schema they end
+// at is the 000001_baseline migration.
+var legacyMigrations = []legacyMigration{
+	{
+		Version:     "0.4.0",
+		Description: "Baseline schema with template-based workouts, WODs, and all features",
+		Up: func(db *sql.DB, driver string) error {
+			// The tables were created by the schema older releases set up
+			// on startup:
+			// - users (with password reset, email verification, birthday fields)
+			// - workouts (template-based with name, created_by)
+			// - wods (WOD definitions)
+			// - workout_wods (junction table)
+			// - user_workouts (junction table for workout instances)
+			// - movements
+			// - workout_movements (with is_pr field)
+			// - refresh_tokens
+			// - user_settings
+			return nil
+		},
+	},
+	{
+		Version:     "0.4.1",
+		Description: "Add score_value, division, and is_pr columns to workout_wods table",
+		Up: func(db *sql.DB, driver string) error {
+			switch driver {
+			case "sqlite3":
+				// SQLite: Check if columns exist before adding
+				// Query to check if column exists
+				var count int
+				err := db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('workout_wods') WHERE name='score_value'`).Scan(&count)
+				if err != nil {
+					return fmt.Errorf("failed to check for score_value column: %w", err)
+				}
+
+				// Only add columns if they don't exist
+				if count == 0 {
+					queries := []string{
+						`ALTER TABLE workout_wods ADD COLUMN score_value TEXT`,
+						`ALTER TABLE workout_wods ADD COLUMN division TEXT`,
+						`ALTER TABLE workout_wods ADD COLUMN is_pr INTEGER NOT NULL DEFAULT 0`,
+					}
+					for _, query := range queries {
+						if _, err := db.Exec(query); err != nil {
+							return fmt.Errorf("failed to execute query: %w", err)
+						}
+					}
+				}
+				return nil
+
+			case "postgres":
+				// PostgreSQL: Add columns with ALTER TABLE (IF NOT EXISTS supported)
+				queries := []string{
+					`ALTER TABLE workout_wods ADD COLUMN IF NOT EXISTS score_value TEXT`,
+					`ALTER TABLE workout_wods ADD COLUMN IF NOT EXISTS division TEXT`,
+					`ALTER TABLE workout_wods ADD COLUMN IF NOT EXISTS is_pr BOOLEAN NOT NULL DEFAULT false`,
+				}
+				for _, query := range queries {
+					if _, err := db.Exec(query); err != nil {
+						return fmt.Errorf("failed to execute query: %w", err)
+					}
+				}
+				return nil
+
+			case "mysql":
+				// MySQL: Check if columns exist before adding
+				var count int
+				err := db.QueryRow(`SELECT COUNT(*) FROM information_schema.COLUMNS
+					WHERE TABLE_SCHEMA = DATABASE()
+					AND TABLE_NAME = 'workout_wods'
+					AND COLUMN_NAME = 'score_value'`).Scan(&count)
+				if err != nil {
+					return fmt.Errorf("failed to check for score_value column: %w", err)
+				}
+
+				// Only add columns if they don't exist
+				if count == 0 {
+					queries := []string{
+						`ALTER TABLE workout_wods ADD COLUMN score_value TEXT`,
+						`ALTER TABLE workout_wods ADD COLUMN division TEXT`,
+						`ALTER TABLE workout_wods ADD COLUMN is_pr BOOLEAN NOT NULL DEFAULT 0`,
+					}
+					for _, query := range queries {
+						if _, err := db.Exec(query); err != nil {
+							return fmt.Errorf("failed to execute query: %w", err)
+						}
+					}
+				}
+				return nil
+
+			default:
+				return fmt.Errorf("unsupported database driver: %s", driver)
+			}
+		},
+	},
+	{
+		Version:     "0.4.2",
+		Description: "Add user_workout_movements and user_workout_wods tables for performance tracking",
+		Up: func(db *sql.DB, driver string) error {
+			switch driver {
+			case "sqlite3":
+				queries := []string{
+					`CREATE TABLE IF NOT EXISTS user_workout_movements (
+						id INTEGER PRIMARY KEY AUTOINCREMENT,
+						user_workout_id INTEGER NOT NULL,
+						movement_id INTEGER NOT NULL,
+						sets INTEGER,
+						reps INTEGER,
+						weight REAL,
+						time INTEGER,
+						distance REAL,
+						notes TEXT,
+						order_index INTEGER NOT NULL DEFAULT 0,
+						created_at DATETIME NOT NULL,
+						updated_at DATETIME NOT NULL,
+						FOREIGN KEY (user_workout_id) REFERENCES user_workouts(id) ON DELETE CASCADE,
+						FOREIGN KEY (movement_id) REFERENCES movements(id) ON DELETE RESTRICT
+					)`,
+					`CREATE INDEX IF NOT EXISTS idx_user_workout_movements_user_workout_id ON user_workout_movements(user_workout_id)`,
+					`CREATE INDEX IF NOT EXISTS idx_user_workout_movements_movement_id ON user_workout_movements(movement_id)`,
+					`CREATE TABLE IF NOT EXISTS user_workout_wods (
+						id INTEGER PRIMARY KEY AUTOINCREMENT,
+						user_workout_id INTEGER NOT NULL,
+						wod_id INTEGER NOT NULL,
+						score_type TEXT,
+						score_value TEXT,
+						time_seconds INTEGER,
+						rounds INTEGER,
+						reps INTEGER,
+						weight REAL,
+						notes TEXT,
+						order_index INTEGER NOT NULL DEFAULT 0,
+						created_at DATETIME NOT NULL,
+						updated_at DATETIME NOT NULL,
+						FOREIGN KEY (user_workout_id) REFERENCES user_workouts(id) ON DELETE CASCADE,
+						FOREIGN KEY (wod_id) REFERENCES wods(id) ON DELETE RESTRICT
+					)`,
+					`CREATE INDEX IF NOT EXISTS idx_user_workout_wods_user_workout_id ON user_workout_wods(user_workout_id)`,
+					`CREATE INDEX IF NOT EXISTS idx_user_workout_wods_wod_id ON user_workout_wods(wod_id)`,
+				}
+				for _, query := range queries {
+					if _, err := db.Exec(query); err != nil {
+						return fmt.Errorf("failed to execute query: %w", err)
+					}
+				}
+				return nil
+
+			case "postgres":
+				queries := []string{
+					`CREATE TABLE IF NOT EXISTS user_workout_movements (
+						id BIGSERIAL PRIMARY KEY,
+						user_workout_id BIGINT NOT NULL,
+						movement_id BIGINT NOT NULL,
+						sets INTEGER,
+						reps INTEGER,
+						weight DECIMAL(10,2),
+						time INTEGER,
+						distance DECIMAL(10,2),
+						notes TEXT,
+						order_index INTEGER NOT NULL DEFAULT 0,
+						created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+						updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+						FOREIGN KEY (user_workout_id) REFERENCES user_workouts(id) ON DELETE CASCADE,
+						FOREIGN KEY (movement_id) REFERENCES movements(id) ON DELETE RESTRICT
+					)`,
+					`CREATE INDEX IF NOT EXISTS idx_user_workout_movements_user_workout_id ON user_workout_movements(user_workout_id)`,
+					`CREATE INDEX IF NOT EXISTS idx_user_workout_movements_movement_id ON user_workout_movements(movement_id)`,
+					`CREATE TABLE IF NOT EXISTS user_workout_wods (
+						id BIGSERIAL PRIMARY KEY,
+						user_workout_id BIGINT NOT NULL,
+						wod_id BIGINT NOT NULL,
+						score_type VARCHAR(50),
+						score_value TEXT,
+						time_seconds INTEGER,
+						rounds INTEGER,
+						reps INTEGER,
+						weight DECIMAL(10,2),
+						notes TEXT,
+						order_index INTEGER NOT NULL DEFAULT 0,
+						created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+						updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+						FOREIGN KEY (user_workout_id) REFERENCES user_workouts(id) ON DELETE CASCADE,
+						FOREIGN KEY (wod_id) REFERENCES wods(id) ON DELETE RESTRICT
+					)`,
+					`CREATE INDEX IF NOT EXISTS idx_user_workout_wods_user_workout_id ON user_workout_wods(user_workout_id)`,
+					`CREATE INDEX IF NOT EXISTS idx_user_workout_wods_wod_id ON user_workout_wods(wod_id)`,
+				}
+				for _, query := range queries {
+					if _, err := db.Exec(query); err != nil {
+						return fmt.Errorf("failed to execute query: %w", err)
+					}
+				}
+				return nil
+
+			case "mysql":
+				queries := []string{
+					`CREATE TABLE IF NOT EXISTS user_workout_movements (
+						id BIGINT AUTO_INCREMENT PRIMARY KEY,
+						user_workout_id BIGINT NOT NULL,
+						movement_id BIGINT NOT NULL,
+						sets INT,
+						reps INT,
+						weight DECIMAL(10,2),
+						time INT,
+						distance DECIMAL(10,2),
+						notes TEXT,
+						order_index INT NOT NULL DEFAULT 0,
+						created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+						updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+						FOREIGN KEY (user_workout_id) REFERENCES user_workouts(id) ON DELETE CASCADE,
+						FOREIGN KEY (movement_id) REFERENCES movements(id) ON DELETE RESTRICT,
+						INDEX idx_user_workout_movements_user_workout_id (user_workout_id),
+						INDEX idx_user_workout_movements_movement_id (movement_id)
+					) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+					`CREATE TABLE IF NOT EXISTS user_workout_wods (
+						id BIGINT AUTO_INCREMENT PRIMARY KEY,
+						user_workout_id BIGINT NOT NULL,
+						wod_id BIGINT NOT NULL,
+						score_type VARCHAR(50),
+						score_value TEXT,
+						time_seconds INT,
+						rounds INT,
+						reps INT,
+						weight DECIMAL(10,2),
+						notes TEXT,
+						order_index INT NOT NULL DEFAULT 0,
+						created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+						updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+						FOREIGN KEY (user_workout_id) REFERENCES user_workouts(id) ON DELETE CASCADE,
+						FOREIGN KEY (wod_id) REFERENCES wods(id) ON DELETE RESTRICT,
+						INDEX idx_user_workout_wods_user_workout_id (user_workout_id),
+						INDEX idx_user_workout_wods_wod_id (wod_id)
+					) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+				}
+				for _, query := range queries {
+					if _, err := db.Exec(query); err != nil {
+						return fmt.Errorf("failed to execute query: %w", err)
+					}
+				}
+				return nil
+
+			default:
+				return fmt.Errorf("unsupported database driver: %s", driver)
+			}
+		},
+	},
+	{
+		Version:     "0.4.3",
+		Description: "Add is_pr column to user_workout_movements and user_workout_wods tables",
+		Up: func(db *sql.DB, driver string) error {
+			switch driver {
+			case "sqlite3":
+				// SQLite: Check if column exists before adding
+				var count int
+				err := db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('user_workout_movements') WHERE name='is_pr'`).Scan(&count)
+				if err != nil {
+					return fmt.Errorf("failed to check for is_pr column: %w", err)
+				}
+
+				// Only add columns if they don't exist
+				if count == 0 {
+					queries := []string{
+						`ALTER TABLE user_workout_movements ADD COLUMN is_pr INTEGER NOT NULL DEFAULT 0`,
+						`CREATE INDEX IF NOT EXISTS idx_user_workout_movements_pr ON user_workout_movements(is_pr)`,
+						`ALTER TABLE user_workout_wods ADD COLUMN is_pr INTEGER NOT NULL DEFAULT 0`,
+						`CREATE INDEX IF NOT EXISTS idx_user_workout_wods_pr ON user_workout_wods(is_pr)`,
+					}
+					for _, query := range queries {
+						if _, err := db.Exec(query); err != nil {
+							return fmt.Errorf("failed to execute query: %w", err)
+						}
+					}
+				}
+				return nil
+
+			case "postgres":
+				// PostgreSQL: Add columns with ALTER TABLE (IF NOT EXISTS supported)
+				queries := []string{
+					`ALTER TABLE user_workout_movements ADD COLUMN IF NOT EXISTS is_pr BOOLEAN NOT NULL DEFAULT false`,
+					`CREATE INDEX IF NOT EXISTS idx_user_workout_movements_pr ON user_workout_movements(is_pr)`,
+					`ALTER TABLE user_workout_wods ADD COLUMN IF NOT EXISTS is_pr BOOLEAN NOT NULL DEFAULT false`,
+					`CREATE INDEX IF NOT EXISTS idx_user_workout_wods_pr ON user_workout_wods(is_pr)`,
+				}
+				for _, query := range queries {
+					if _, err := db.Exec(query); err != nil {
+						return fmt.Errorf("failed to execute query: %w", err)
+					}
+				}
+				return nil
+
+			case "mysql":
+				// MySQL: Check if columns exist before adding
+				var count int
+				err := db.QueryRow(`SELECT COUNT(*) FROM information_schema.COLUMNS
+					WHERE TABLE_SCHEMA = DATABASE()
+					AND TABLE_NAME = 'user_workout_movements'
+					AND COLUMN_NAME = 'is_pr'`).Scan(&count)
+				if err != nil {
+					return fmt.Errorf("failed to check for is_pr column: %w", err)
+				}
+
+				// Only add columns if they don't exist
+				if count == 0 {
+					queries := []string{
+						`ALTER TABLE user_workout_movements ADD COLUMN is_pr BOOLEAN NOT NULL DEFAULT 0`,
+						`CREATE INDEX idx_user_workout_movements_pr ON user_workout_movements(is_pr)`,
+						`ALTER TABLE user_workout_wods ADD COLUMN is_pr BOOLEAN NOT NULL DEFAULT 0`,
+						`CREATE INDEX idx_user_workout_wods_pr ON user_workout_wods(is_pr)`,
+					}
+					for _, query := range queries {
+						if _, err := db.Exec(query); err != nil {
+							return fmt.Errorf("failed to execute query: %w", err)
+						}
+					}
+				}
+				return nil
+
+			default:
+				return fmt.Errorf("unsupported database driver: %s", driver)
+			}
+		},
+	},
+	{
+		Version:     "0.4.4",
+		Description: "Add workout_name to user_workouts and create refresh_tokens and user_settings tables",
+		Up: func(db *sql.DB, driver string) error {
+			var queries []string
+
+			switch driver {
+			case "sqlite3":
+				var count int
+				err := db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('user_workouts') WHERE name='workout_name'`).Scan(&count)
+				if err != nil {
+					return fmt.Errorf("failed to check for workout_name column: %w", err)
+				}
+				if count == 0 {
+					queries = append(queries, `ALTER TABLE user_workouts ADD COLUMN workout_name TEXT`)
+				}
+				queries = append(queries,
+					`CREATE TABLE IF NOT EXISTS refresh_tokens (
+						id INTEGER PRIMARY KEY AUTOINCREMENT,
+						user_id INTEGER NOT NULL,
+						token TEXT UNIQUE NOT NULL,
+						expires_at DATETIME NOT NULL,
+						created_at DATETIME NOT NULL,
+						revoked_at DATETIME,
+						device_info TEXT,
+						FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+					)`,
+					`CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user_id ON refresh_tokens(user_id)`,
+					`CREATE TABLE IF NOT EXISTS user_settings (
+						id INTEGER PRIMARY KEY AUTOINCREMENT,
+						user_id INTEGER UNIQUE NOT NULL,
+						notification_preferences TEXT NOT NULL DEFAULT '{}',
+						data_export_format TEXT NOT NULL DEFAULT 'json',
+						theme TEXT NOT NULL DEFAULT 'light',
+						weight_unit TEXT NOT NULL DEFAULT 'lbs',
+						distance_unit TEXT NOT NULL DEFAULT 'miles',
+						created_at DATETIME NOT NULL,
+						updated_at DATETIME NOT NULL,
+						FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+					)`,
+				)
+
+			case "postgres":
+				queries = []string{
+					`ALTER TABLE user_workouts ADD COLUMN IF NOT EXISTS workout_name VARCHAR(255)`,
+					`ALTER TABLE user_workouts ALTER COLUMN workout_id DROP NOT NULL`,
+					`CREATE TABLE IF NOT EXISTS refresh_tokens (
+						id BIGSERIAL PRIMARY KEY,
+						user_id BIGINT NOT NULL,
+						token VARCHAR(255) UNIQUE NOT NULL,
+						expires_at TIMESTAMP NOT NULL,
+						created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+						revoked_at TIMESTAMP,
+						device_info TEXT,
+						FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+					)`,
+					`CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user_id ON refresh_tokens(user_id)`,
+					`CREATE TABLE IF NOT EXISTS user_settings (
+						id BIGSERIAL PRIMARY KEY,
+						user_id BIGINT UNIQUE NOT NULL,
+						notification_preferences TEXT NOT NULL DEFAULT '{}',
+						data_export_format VARCHAR(20) NOT NULL DEFAULT 'json',
+						theme VARCHAR(20) NOT NULL DEFAULT 'light',
+						weight_unit VARCHAR(10) NOT NULL DEFAULT 'lbs',
+						distance_unit VARCHAR(10) NOT NULL DEFAULT 'miles',
+						created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+						updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+						FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+					)`,
+				}
+
+			case "mysql":
+				var count int
+				err := db.QueryRow(`SELECT COUNT(*) FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = 'user_workouts' AND column_name = 'workout_name'`).Scan(&count)
+				if err != nil {
+					return fmt.Errorf("failed to check for workout_name column: %w", err)
+				}
+				if count == 0 {
+					queries = append(queries, `ALTER TABLE user_workouts ADD COLUMN workout_name VARCHAR(255)`)
+				}
+				queries = append(queries,
+					`ALTER TABLE user_workouts MODIFY workout_id BIGINT NULL`,
+					`CREATE TABLE IF NOT EXISTS refresh_tokens (
+						id BIGINT AUTO_INCREMENT PRIMARY KEY,
+						user_id BIGINT NOT NULL,
+						token VARCHAR(255) UNIQUE NOT NULL,
+						expires_at DATETIME NOT NULL,
+						created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+						revoked_at DATETIME,
+						device_info TEXT,
+						FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+						INDEX idx_refresh_tokens_user_id (user_id)
+					) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+					`CREATE TABLE IF NOT EXISTS user_settings (
+						id BIGINT AUTO_INCREMENT PRIMARY KEY,
+						user_id BIGINT UNIQUE NOT NULL,
+						notification_preferences TEXT NOT NULL,
+						data_export_format VARCHAR(20) NOT NULL DEFAULT 'json',
+						theme VARCHAR(20) NOT NULL DEFAULT 'light',
+						weight_unit VARCHAR(10) NOT NULL DEFAULT 'lbs',
+						distance_unit VARCHAR(10) NOT NULL DEFAULT 'miles',
+						created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+						updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+						FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+					) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+				)
+
+			default:
+				return fmt.Errorf("unsupported database driver: %s", driver)
+			}
+
+			for _, query := range queries {
+				if _, err := db.Exec(query); err != nil {
+					return fmt.Errorf("failed to execute query: %w", err)
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version:     "0.4.5",
+		Description: "Add webhooks and webhook_deliveries tables for outgoing event notifications",
+		Up: func(db *sql.DB, driver string) error {
+			var queries []string
+
+			switch driver {
+			case "sqlite3":
+				queries = []string{
+					`CREATE TABLE IF NOT EXISTS webhooks (
+						id INTEGER PRIMARY KEY AUTOINCREMENT,
+						user_id INTEGER NOT NULL,
+						url TEXT NOT NULL,
+						secret TEXT NOT NULL,
+						events TEXT NOT NULL,
+						all_users INTEGER NOT NULL DEFAULT 0,
+						description TEXT,
+						is_active INTEGER NOT NULL DEFAULT 1,
+						created_at DATETIME NOT NULL,
+						updated_at DATETIME NOT NULL,
+						FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+					)`,
+					`CREATE INDEX IF NOT EXISTS idx_webhooks_user_id ON webhooks(user_id)`,
+					`CREATE TABLE IF NOT EXISTS webhook_deliveries (
+						id INTEGER PRIMARY KEY AUTOINCREMENT,
+						webhook_id INTEGER NOT NULL,
+						event_type TEXT NOT NULL,
+						payload TEXT NOT NULL,
+						attempts INTEGER NOT NULL DEFAULT 0,
+						status_code INTEGER,
+						response_body TEXT,
+						error TEXT,
+						success INTEGER NOT NULL DEFAULT 0,
+						created_at DATETIME NOT NULL,
+						delivered_at DATETIME,
+						FOREIGN KEY (webhook_id) REFERENCES webhooks(id) ON DELETE CASCADE
+					)`,
+					`CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook_id ON webhook_deliveries(webhook_id, created_at)`,
+				}
+
+			case "postgres":
+				queries = []string{
+					`CREATE TABLE IF NOT EXISTS webhooks (
+						id BIGSERIAL PRIMARY KEY,
+						user_id BIGINT NOT NULL,
+						url TEXT NOT NULL,
+						secret VARCHAR(255) NOT NULL,
+						events TEXT NOT NULL,
+						all_users BOOLEAN NOT NULL DEFAULT FALSE,
+						description TEXT,
+						is_active BOOLEAN NOT NULL DEFAULT TRUE,
+						created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+						updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+						FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+					)`,
+					`CREATE INDEX IF NOT EXISTS idx_webhooks_user_id ON webhooks(user_id)`,
+					`CREATE TABLE IF NOT EXISTS webhook_deliveries (
+						id BIGSERIAL PRIMARY KEY,
+						webhook_id BIGINT NOT NULL,
+						event_type VARCHAR(50) NOT NULL,
+						payload TEXT NOT NULL,
+						attempts INTEGER NOT NULL DEFAULT 0,
+						status_code INTEGER,
+						response_body TEXT,
+						error TEXT,
+						success BOOLEAN NOT NULL DEFAULT FALSE,
+						created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+						delivered_at TIMESTAMP,
+						FOREIGN KEY (webhook_id) REFERENCES webhooks(id) ON DELETE CASCADE
+					)`,
+					`CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook_id ON webhook_deliveries(webhook_id, created_at)`,
+				}
+
+			case "mysql":
+				queries = []string{
+					`CREATE TABLE IF NOT EXISTS webhooks (
+						id BIGINT AUTO_INCREMENT PRIMARY KEY,
+						user_id BIGINT NOT NULL,
+						url TEXT NOT NULL,
+						secret VARCHAR(255) NOT NULL,
+						events TEXT NOT NULL,
+						all_users BOOLEAN NOT NULL DEFAULT FALSE,
+						description TEXT,
+						is_active BOOLEAN NOT NULL DEFAULT TRUE,
+						created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+						updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+						FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+						INDEX idx_webhooks_user_id (user_id)
+					) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+					`CREATE TABLE IF NOT EXISTS webhook_deliveries (
+						id BIGINT AUTO_INCREMENT PRIMARY KEY,
+						webhook_id BIGINT NOT NULL,
+						event_type VARCHAR(50) NOT NULL,
+						payload MEDIUMTEXT NOT NULL,
+						attempts INT NOT NULL DEFAULT 0,
+						status_code INT,
+						response_body TEXT,
+						error TEXT,
+						success BOOLEAN NOT NULL DEFAULT FALSE,
+						created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+						delivered_at DATETIME,
+						FOREIGN KEY (webhook_id) REFERENCES webhooks(id) ON DELETE CASCADE,
+						INDEX idx_webhook_deliveries_webhook_id (webhook_id, created_at)
+					) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+				}
+
+			default:
+				return fmt.Errorf("unsupported database driver: %s", driver)
+			}
+
+			for _, query := range queries {
+				if _, err := db.Exec(query); err != nil {
+					return fmt.Errorf("failed to execute query: %w", err)
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version:     "0.4.6",
+		Description: "Add email_outbox table for background email delivery",
+		Up: func(db *sql.DB, driver string) error {
+			var queries []string
+
+			switch driver {
+			case "sqlite3":
+				queries = []string{
+					`CREATE TABLE IF NOT EXISTS email_outbox (
+						id INTEGER PRIMARY KEY AUTOINCREMENT,
+						recipients TEXT NOT NULL,
+						subject TEXT NOT NULL,
+						body TEXT NOT NULL,
+						is_html INTEGER NOT NULL DEFAULT 0,
+						status TEXT NOT NULL DEFAULT 'pending',
+						attempts INTEGER NOT NULL DEFAULT 0,
+						last_error TEXT,
+						next_attempt_at DATETIME NOT NULL,
+						sent_at DATETIME,
+						created_at DATETIME NOT NULL,
+						updated_at DATETIME NOT NULL
+					)`,
+					`CREATE INDEX IF NOT EXISTS idx_email_outbox_status_next ON email_outbox(status, next_attempt_at)`,
+				}
+
+			case "postgres":
+				queries = []string{
+					`CREATE TABLE IF NOT EXISTS email_outbox (
+						id BIGSERIAL PRIMARY KEY,
+						recipients TEXT NOT NULL,
+						subject TEXT NOT NULL,
+						body TEXT NOT NULL,
+						is_html BOOLEAN NOT NULL DEFAULT FALSE,
+						status VARCHAR(20) NOT NULL DEFAULT 'pending',
+						attempts INTEGER NOT NULL DEFAULT 0,
+						last_error TEXT,
+						next_attempt_at TIMESTAMP NOT NULL,
+						sent_at TIMESTAMP,
+						created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+						updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+					)`,
+					`CREATE INDEX IF NOT EXISTS idx_email_outbox_status_next ON email_outbox(status, next_attempt_at)`,
+				}
+
+			case "mysql":
+				queries = []string{
+					`CREATE TABLE IF NOT EXISTS email_outbox (
+						id BIGINT AUTO_INCREMENT PRIMARY KEY,
+						recipients TEXT NOT NULL,
+						subject VARCHAR(998) NOT NULL,
+						body MEDIUMTEXT NOT NULL,
+						is_html BOOLEAN NOT NULL DEFAULT FALSE,
+						status VARCHAR(20) NOT NULL DEFAULT 'pending',
+						attempts INT NOT NULL DEFAULT 0,
+						last_error TEXT,
+						next_attempt_at DATETIME NOT NULL,
+						sent_at DATETIME,
+						created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+						updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+						INDEX idx_email_outbox_status_next (status, next_attempt_at)
+					) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+				}
+
+			default:
+				return fmt.Errorf("unsupported database driver: %s", driver)
+			}
+
+			for _, query := range queries {
+				if _, err := db.Exec(query); err != nil {
+					return fmt.Errorf("failed to execute query: %w", err)
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version:     "0.4.7",
+		Description: "Add locale to users and text_body to email_outbox for templated multipart emails",
+		Up: func(db *sql.DB, driver string) error {
+			var queries []string
+
+			switch driver {
+			case "sqlite3":
+				for _, col := range []struct{ table, name, def string }{
+					{"users", "locale", `ALTER TABLE users ADD COLUMN locale TEXT NOT NULL DEFAULT 'en'`},
+					{"email_outbox", "text_body", `ALTER TABLE email_outbox ADD COLUMN text_body TEXT`},
+				} {
+					var count int
+					err := db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('`+col.table+`') WHERE name=?`, col.name).Scan(&count)
+					if err != nil {
+						return fmt.Errorf("failed to check for %s column: %w", col.name, err)
+					}
+					if count == 0 {
+						queries = append(queries, col.def)
+					}
+				}
+
+			case "postgres":
+				queries = []string{
+					`ALTER TABLE users ADD COLUMN IF NOT EXISTS locale VARCHAR(35) NOT NULL DEFAULT 'en'`,
+					`ALTER TABLE email_outbox ADD COLUMN IF NOT EXISTS text_body TEXT`,
+				}
+
+			case "mysql":
+				for _, col := range []struct{ table, name, def string }{
+					{"users", "locale", `ALTER TABLE users ADD COLUMN locale VARCHAR(35) NOT NULL DEFAULT 'en'`},
+					{"email_outbox", "text_body", `ALTER TABLE email_outbox ADD COLUMN text_body MEDIUMTEXT`},
+				} {
+					var count int
+					err := db.QueryRow(`SELECT COUNT(*) FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = ? AND column_name = ?`, col.table, col.name).Scan(&count)
+					if err != nil {
+						return fmt.Errorf("failed to check for %s column: %w", col.name, err)
+					}
+					if count == 0 {
+						queries = append(queries, col.def)
+					}
+				}
+
+			default:
+				return fmt.Errorf("unsupported database driver: %s", driver)
+			}
+
+			for _, query := range queries {
+				if _, err := db.Exec(query); err != nil {
+					return fmt.Errorf("failed to execute query: %w", err)
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version:     "0.4.8",
+		Description: "Add digest_deliveries table for weekly digest emails",
+		Up: func(db *sql.DB, driver string) error {
+			var queries []string
+
+			switch driver {
+			case "sqlite3":
+				queries = []string{
+					`CREATE TABLE IF NOT EXISTS digest_deliveries (
+						id INTEGER PRIMARY KEY AUTOINCREMENT,
+						user_id INTEGER NOT NULL,
+						week_start TEXT NOT NULL,
+						created_at DATETIME NOT NULL,
+						FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+					)`,
+					`CREATE UNIQUE INDEX IF NOT EXISTS idx_digest_deliveries_user_week ON digest_deliveries(user_id, week_start)`,
+				}
+
+			case "postgres":
+				queries = []string{
+					`CREATE TABLE IF NOT EXISTS digest_deliveries (
+						id BIGSERIAL PRIMARY KEY,
+						user_id BIGINT NOT NULL,
+						week_start VARCHAR(10) NOT NULL,
+						created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+						FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+					)`,
+					`CREATE UNIQUE INDEX IF NOT EXISTS idx_digest_deliveries_user_week ON digest_deliveries(user_id, week_start)`,
+				}
+
+			case "mysql":
+				queries = []string{
+					`CREATE TABLE IF NOT EXISTS digest_deliveries (
+						id BIGINT AUTO_INCREMENT PRIMARY KEY,
+						user_id BIGINT NOT NULL,
+						week_start VARCHAR(10) NOT NULL,
+						created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+						FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+						UNIQUE INDEX idx_digest_deliveries_user_week (user_id, week_start)
+					) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+				}
+
+			default:
+				return fmt.Errorf("unsupported database driver: %s", driver)
+			}
+
+			for _, query := range queries {
+				if _, err := db.Exec(query); err != nil {
+					return fmt.Errorf("failed to execute query: %w", err)
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version:     "0.4.9",
+		Description: "Add notifications table for the in-app notification center",
+		Up: func(db *sql.DB, driver string) error {
+			var queries []string
+
+			switch driver {
+			case "sqlite3":
+				queries = []string{
+					`CREATE TABLE IF NOT EXISTS notifications (
+						id INTEGER PRIMARY KEY AUTOINCREMENT,
+						user_id INTEGER NOT NULL,
+						type TEXT NOT NULL,
+						title TEXT NOT NULL,
+						body TEXT NOT NULL DEFAULT '',
+						link TEXT,
+						dedupe_key TEXT,
+						read_at DATETIME,
+						created_at DATETIME NOT NULL,
+						FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+					)`,
+					`CREATE INDEX IF NOT EXISTS idx_notifications_user_created ON notifications(user_id, created_at)`,
+					`CREATE INDEX IF NOT EXISTS idx_notifications_user_read ON notifications(user_id, read_at)`,
+					`CREATE UNIQUE INDEX IF NOT EXISTS idx_notifications_user_dedupe ON notifications(user_id, dedupe_key)`,
+				}
+
+			case "postgres":
+				queries = []string{
+					`CREATE TABLE IF NOT EXISTS notifications (
+						id BIGSERIAL PRIMARY KEY,
+						user_id BIGINT NOT NULL,
+						type VARCHAR(50) NOT NULL,
+						title VARCHAR(255) NOT NULL,
+						body TEXT NOT NULL DEFAULT '',
+						link VARCHAR(255),
+						dedupe_key VARCHAR(191),
+						read_at TIMESTAMP,
+						created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+						FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+					)`,
+					`CREATE INDEX IF NOT EXISTS idx_notifications_user_created ON notifications(user_id, created_at)`,
+					`CREATE INDEX IF NOT EXISTS idx_notifications_user_read ON notifications(user_id, read_at)`,
+					`CREATE UNIQUE INDEX IF NOT EXISTS idx_notifications_user_dedupe ON notifications(user_id, dedupe_key)`,
+				}
+
+			case "mysql":
+				queries = []string{
+					`CREATE TABLE IF NOT EXISTS notifications (
+						id BIGINT AUTO_INCREMENT PRIMARY KEY,
+						user_id BIGINT NOT NULL,
+						type VARCHAR(50) NOT NULL,
+						title VARCHAR(255) NOT NULL,
+						body TEXT NOT NULL,
+						link VARCHAR(255),
+						dedupe_key VARCHAR(191),
+						read_at DATETIME,
+						created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+						FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+						INDEX idx_notifications_user_created (user_id, created_at),
+						INDEX idx_notifications_user_read (user_id, read_at),
+						UNIQUE INDEX idx_notifications_user_dedupe (user_id, dedupe_key)
+					) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+				}
+
+			default:
+				return fmt.Errorf("unsupported database driver: %s", driver)
+			}
+
+			for _, query := range queries {
+				if _, err := db.Exec(query); err != nil {
+					return fmt.Errorf("failed to execute query: %w", err)
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version:     "0.4.10",
+		Description: "Add user_totp and user_recovery_codes tables for two-factor authentication",
+		Up: func(db *sql.DB, driver string) error {
+			var queries []string
+
+			switch driver {
+			case "sqlite3":
+				queries = []string{
+					`CREATE TABLE IF NOT EXISTS user_totp (
+						user_id INTEGER PRIMARY KEY,
+						secret TEXT NOT NULL,
+						confirmed_at DATETIME,
+						last_used_step INTEGER NOT NULL DEFAULT 0,
+						created_at DATETIME NOT NULL,
+						updated_at DATETIME NOT NULL,
+						FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+					)`,
+					`CREATE TABLE IF NOT EXISTS user_recovery_codes (
+						id INTEGER PRIMARY KEY AUTOINCREMENT,
+						user_id INTEGER NOT NULL,
+						code_hash TEXT NOT NULL,
+						used_at DATETIME,
+						created_at DATETIME NOT NULL,
+						FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+					)`,
+					`CREATE INDEX IF NOT EXISTS idx_user_recovery_codes_user_id ON user_recovery_codes(user_id)`,
+				}
+
+			case "postgres":
+				queries = []string{
+					`CREATE TABLE IF NOT EXISTS user_totp (
+						user_id BIGINT PRIMARY KEY,
+						secret VARCHAR(64) NOT NULL,
+						confirmed_at TIMESTAMP,
+						last_used_step BIGINT NOT NULL DEFAULT 0,
+						created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+						updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+						FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+					)`,
+					`CREATE TABLE IF NOT EXISTS user_recovery_codes (
+						id BIGSERIAL PRIMARY KEY,
+						user_id BIGINT NOT NULL,
+						code_hash VARCHAR(255) NOT NULL,
+						used_at TIMESTAMP,
+						created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+						FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+					)`,
+					`CREATE INDEX IF NOT EXISTS idx_user_recovery_codes_user_id ON user_recovery_codes(user_id)`,
+				}
+
+			case "mysql":
+				queries = []string{
+					`CREATE TABLE IF NOT EXISTS user_totp (
+						user_id BIGINT PRIMARY KEY,
+						secret VARCHAR(64) NOT NULL,
+						confirmed_at DATETIME,
+						last_used_step BIGINT NOT NULL DEFAULT 0,
+						created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+						updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+						FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+					) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+					`CREATE TABLE IF NOT EXISTS user_recovery_codes (
+						id BIGINT AUTO_INCREMENT PRIMARY KEY,
+						user_id BIGINT NOT NULL,
+						code_hash VARCHAR(255) NOT NULL,
+						used_at DATETIME,
+						created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+						FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+						INDEX idx_user_recovery_codes_user_id (user_id)
+					) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+				}
+
+			default:
+				return fmt.Errorf("unsupported database driver: %s", driver)
+			}
+
+			for _, query := range queries {
+				if _, err := db.Exec(query); err != nil {
+					return fmt.Errorf("failed to execute query: %w", err)
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version:     "0.4.11",
+		Description: "Add passkeys and passkey_challenges tables for WebAuthn login",
+		Up: func(db *sql.DB, driver string) error {
+			var queries []string
+
+			switch driver {
+			case "sqlite3":
+				queries = []string{
+					`CREATE TABLE IF NOT EXISTS passkeys (
+						id INTEGER PRIMARY KEY AUTOINCREMENT,
+						user_id INTEGER NOT NULL,
+						credential_id TEXT NOT NULL UNIQUE,
+						public_key TEXT NOT NULL,
+						sign_count INTEGER NOT NULL DEFAULT 0,
+						transports TEXT NOT NULL DEFAULT '',
+						name TEXT NOT NULL,
+						last_used_at DATETIME,
+						created_at DATETIME NOT NULL,
+						updated_at DATETIME NOT NULL,
+						FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+					)`,
+					`CREATE INDEX IF NOT EXISTS idx_passkeys_user_id ON passkeys(user_id)`,
+					`CREATE TABLE IF NOT EXISTS passkey_challenges (
+						id INTEGER PRIMARY KEY AUTOINCREMENT,
+						challenge TEXT NOT NULL UNIQUE,
+						purpose TEXT NOT NULL,
+						user_id INTEGER,
+						expires_at DATETIME NOT NULL,
+						created_at DATETIME NOT NULL,
+						FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+					)`,
+				}
+
+			case "postgres":
+				queries = []string{
+					`CREATE TABLE IF NOT EXISTS passkeys (
+						id BIGSERIAL PRIMARY KEY,
+						user_id BIGINT NOT NULL,
+						credential_id VARCHAR(1400) NOT NULL UNIQUE,
+						public_key TEXT NOT NULL,
+						sign_count BIGINT NOT NULL DEFAULT 0,
+						transports VARCHAR(255) NOT NULL DEFAULT '',
+						name VARCHAR(100) NOT NULL,
+						last_used_at TIMESTAMP,
+						created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+						updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+						FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+					)`,
+					`CREATE INDEX IF NOT EXISTS idx_passkeys_user_id ON passkeys(user_id)`,
+					`CREATE TABLE IF NOT EXISTS passkey_challenges (
+						id BIGSERIAL PRIMARY KEY,
+						challenge VARCHAR(64) NOT NULL UNIQUE,
+						purpose VARCHAR(20) NOT NULL,
+						user_id BIGINT,
+						expires_at TIMESTAMP NOT NULL,
+						created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+						FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+					)`,
+				}
+
+			case "mysql":
+				queries = []string{
+					`CREATE TABLE IF NOT EXISTS passkeys (
+						id BIGINT AUTO_INCREMENT PRIMARY KEY,
+						user_id BIGINT NOT NULL,
+						credential_id VARCHAR(1400) NOT NULL,
+						public_key TEXT NOT NULL,
+						sign_count BIGINT NOT NULL DEFAULT 0,
+						transports VARCHAR(255) NOT NULL DEFAULT '',
+						name VARCHAR(100) NOT NULL,
+						last_used_at DATETIME,
+						created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+						updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+						FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+						UNIQUE KEY uq_passkeys_credential_id (credential_id(255)),
+						INDEX idx_passkeys_user_id (user_id)
+					) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+					`CREATE TABLE IF NOT EXISTS passkey_challenges (
+						id BIGINT AUTO_INCREMENT PRIMARY KEY,
+						challenge VARCHAR(64) NOT NULL UNIQUE,
+						purpose VARCHAR(20) NOT NULL,
+						user_id BIGINT,
+						expires_at DATETIME NOT NULL,
+						created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+						FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+					) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+				}
+
+			default:
+				return fmt.Errorf("unsupported database driver: %s", driver)
+			}
+
+			for _, query := range queries {
+				if _, err := db.Exec(query); err != nil {
+					return fmt.Errorf("failed to execute query: %w", err)
+				}
+			}
+
+			return nil
+		},
+	},
+	{
+		Version:     "0.4.12",
+		Description: "Add user_identities and oidc_login_states tables for OpenID Connect login",
+		Up: func(db *sql.DB, driver string) error {
+			var queries []string
+
+			switch driver {
+			case "sqlite3":
+				queries = []string{
+					`CREATE TABLE IF NOT EXISTS user_identities (
+						id INTEGER PRIMARY KEY AUTOINCREMENT,
+						user_id INTEGER NOT NULL,
+						issuer TEXT NOT NULL,
+						subject TEXT NOT NULL,
+						email TEXT NOT NULL DEFAULT '',
+						last_login_at DATETIME,
+						created_at DATETIME NOT NULL,
+						FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+						UNIQUE (issuer, subject)
+					)`,
+					`CREATE INDEX IF NOT EXISTS idx_user_identities_user_id ON user_identities(user_id)`,
+					`CREATE TABLE IF NOT EXISTS oidc_login_states (
+						id INTEGER PRIMARY KEY AUTOINCREMENT,
+						state TEXT NOT NULL UNIQUE,
+						nonce TEXT NOT NULL,
+						code_verifier TEXT NOT NULL,
+						expires_at DATETIME NOT NULL,
+						created_at DATETIME NOT NULL
+					)`,
+				}
+
+			case "postgres":
+				queries = []string{
+					`CREATE TABLE IF NOT EXISTS user_identities (
+						id BIGSERIAL PRIMARY KEY,
+						user_id BIGINT NOT NULL,
+						issuer VARCHAR(255) NOT NULL,
+						subject VARCHAR(255) NOT NULL,
+						email VARCHAR(255) NOT NULL DEFAULT '',
+						last_login_at TIMESTAMP,
+						created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+						FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+						UNIQUE (issuer, subject)
+					)`,
+					`CREATE INDEX IF NOT EXISTS idx_user_identities_user_id ON user_identities(user_id)`,
+					`CREATE TABLE IF NOT EXISTS oidc_login_states (
+						id BIGSERIAL PRIMARY KEY,
+						state VARCHAR(64) NOT NULL UNIQUE,
+						nonce VARCHAR(64) NOT NULL,
+						code_verifier VARCHAR(128) NOT NULL,
+						expires_at TIMESTAMP NOT NULL,
+						created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+					)`,
+				}
+
+			case "mysql":
+				queries = []string{
+					`CREATE TABLE IF NOT EXISTS user_identities (
+						id BIGINT AUTO_INCREMENT PRIMARY KEY,
+						user_id BIGINT NOT NULL,
+						issuer VARCHAR(255) NOT NULL,
+						subject VARCHAR(255) NOT NULL,
+						email VARCHAR(255) NOT NULL DEFAULT '',
+						last_login_at DATETIME,
+						created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+						FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+						UNIQUE KEY uq_user_identities_issuer_subject (issuer(191), subject(191)),
+						INDEX idx_user_identities_user_id (user_id)
+					) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+					`CREATE TABLE IF NOT EXISTS oidc_login_states (
+						id BIGINT AUTO_INCREMENT PRIMARY KEY,
+						state VARCHAR(64) NOT NULL UNIQUE,
+						nonce VARCHAR(64) NOT NULL,
+						code_verifier VARCHAR(128) NOT NULL,
+						expires_at DATETIME NOT NULL,
+						created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+					) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+				}
+
+			default:
+				return fmt.Errorf("unsupported database driver: %s", driver)
+			}
+
+			for _, query := range queries {
+				if _, err := db.Exec(query); err != nil {
+					return fmt.Errorf("failed to execute query: %w", err)
+				}
+			}
+
+			return nil
+		},
+	},
+	{
+		Version:     "0.4.13",
+		Description: "Add api_tokens table for personal API tokens",
+		Up: func(db *sql.DB, driver string) error {
+			var queries []string
+
+			switch driver {
+			case "sqlite3":
+				queries = []string{
+					`CREATE TABLE IF NOT EXISTS api_tokens (
+						id INTEGER PRIMARY KEY AUTOINCREMENT,
+						user_id INTEGER NOT NULL,
+						name TEXT NOT NULL,
+						prefix TEXT NOT NULL,
+						token_hash TEXT NOT NULL UNIQUE,
+						scopes TEXT NOT NULL,
+						last_used_at DATETIME,
+						expires_at DATETIME,
+						created_at DATETIME NOT NULL,
+						FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+					)`,
+					`CREATE INDEX IF NOT EXISTS idx_api_tokens_user_id ON api_tokens(user_id)`,
+				}
+
+			case "postgres":
+				queries = []string{
+					`CREATE TABLE IF NOT EXISTS api_tokens (
+						id BIGSERIAL PRIMARY KEY,
+						user_id BIGINT NOT NULL,
+						name VARCHAR(100) NOT NULL,
+						prefix VARCHAR(32) NOT NULL,
+						token_hash VARCHAR(64) NOT NULL UNIQUE,
+						scopes VARCHAR(255) NOT NULL,
+						last_used_at TIMESTAMP,
+						expires_at TIMESTAMP,
+						created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+						FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+					)`,
+					`CREATE INDEX IF NOT EXISTS idx_api_tokens_user_id ON api_tokens(user_id)`,
+				}
+
+			case "mysql":
+				queries = []string{
+					`CREATE TABLE IF NOT EXISTS api_tokens (
+						id BIGINT AUTO_INCREMENT PRIMARY KEY,
+						user_id BIGINT NOT NULL,
+						name VARCHAR(100) NOT NULL,
+						prefix VARCHAR(32) NOT NULL,
+						token_hash VARCHAR(64) NOT NULL UNIQUE,
+						scopes VARCHAR(255) NOT NULL,
+						last_used_at DATETIME,
+						expires_at DATETIME,
+						created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+						FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+						INDEX idx_api_tokens_user_id (user_id)
+					) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+				}
+
+			default:
+				return fmt.Errorf("unsupported database driver: %s", driver)
+			}
+
+			for _, query := range queries {
+				if _, err := db.Exec(query); err != nil {
+					return fmt.Errorf("failed to execute query: %w", err)
+				}
+			}
+
+			return nil
+		},
+	},
+	{
+		Version:     "0.4.14",
+		Description: "Add family_id, ip_address and signed_in_at to refresh_tokens for session management",
+		Up: func(db *sql.DB, driver string) error {
+			var queries []string
+
+			switch driver {
+			case "sqlite3":
+				for _, col := range []struct{ name, def string }{
+					{"family_id", `ALTER TABLE refresh_tokens ADD COLUMN family_id INTEGER`},
+					{"ip_address", `ALTER TABLE refresh_tokens ADD COLUMN ip_address TEXT`},
+					{"signed_in_at", `ALTER TABLE refresh_tokens ADD COLUMN signed_in_at DATETIME`},
+				} {
+					var count int
+					err := db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('refresh_tokens') WHERE name=?`, col.name).Scan(&count)
+					if err != nil {
+						return fmt.Errorf("failed to check for %s column: %w", col.name, err)
+					}
+					if count == 0 {
+						queries = append(queries, col.def)
+					}
+				}
+				queries = append(queries, `CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family_id ON refresh_tokens(family_id)`)
+
+			case "postgres":
+				queries = []string{
+					`ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS family_id BIGINT`,
+					`ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS ip_address VARCHAR(45)`,
+					`ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS signed_in_at TIMESTAMP`,
+					`CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family_id ON refresh_tokens(family_id)`,
+				}
+
+			case "mysql":
+				for _, col := range []struct{ name, def string }{
+					{"family_id", `ALTER TABLE refresh_tokens ADD COLUMN family_id BIGINT, ADD INDEX idx_refresh_tokens_family_id (family_id)`},
+					{"ip_address", `ALTER TABLE refresh_tokens ADD COLUMN ip_address VARCHAR(45)`},
+					{"signed_in_at", `ALTER TABLE refresh_tokens ADD COLUMN signed_in_at DATETIME`},
+				} {
+					var count int
+					err := db.QueryRow(`SELECT COUNT(*) FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = 'refresh_tokens' AND column_name = ?`, col.name).Scan(&count)
+					if err != nil {
+						return fmt.Errorf("failed to check for %s column: %w", col.name, err)
+					}
+					if count == 0 {
+						queries = append(queries, col.def)
+					}
+				}
+
+			default:
+				return fmt.Errorf("unsupported database driver: %s", driver)
+			}
+
+			// Existing tokens each become their own session
+			queries = append(queries,
+				`UPDATE refresh_tokens SET family_id = id WHERE family_id IS NULL`,
+				`UPDATE refresh_tokens SET signed_in_at = created_at WHERE signed_in_at IS NULL`,
+			)
+
+			for _, query := range queries {
+				if _, err := db.Exec(query); err != nil {
+					return fmt.Errorf("failed to execute query: %w", err)
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version:     "0.4.15",
+		Description: "Add login_lockouts table for progressive account lockout",
+		Up: func(db *sql.DB, driver string) error {
+			var query string
+
+			switch driver {
+			case "sqlite3":
+				query = `CREATE TABLE IF NOT EXISTS login_lockouts (
+					user_id INTEGER PRIMARY KEY,
+					failed_attempts INTEGER NOT NULL DEFAULT 0,
+					lockouts INTEGER NOT NULL DEFAULT 0,
+					locked_until DATETIME,
+					last_failure_at DATETIME NOT NULL,
+					FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+				)`
+
+			case "postgres":
+				query = `CREATE TABLE IF NOT EXISTS login_lockouts (
+					user_id BIGINT PRIMARY KEY,
+					failed_attempts INTEGER NOT NULL DEFAULT 0,
+					lockouts INTEGER NOT NULL DEFAULT 0,
+					locked_until TIMESTAMP,
+					last_failure_at TIMESTAMP NOT NULL,
+					FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+				)`
+
+			case "mysql":
+				query = `CREATE TABLE IF NOT EXISTS login_lockouts (
+					user_id BIGINT PRIMARY KEY,
+					failed_attempts INT NOT NULL DEFAULT 0,
+					lockouts INT NOT NULL DEFAULT 0,
+					locked_until DATETIME,
+					last_failure_at DATETIME NOT NULL,
+					FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+				) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`
+
+			default:
+				return fmt.Errorf("unsupported database driver: %s", driver)
+			}
+
+			if _, err := db.Exec(query); err != nil {
+				return fmt.Errorf("failed to execute query: %w", err)
+			}
+			return nil
+		},
+	},
+	{
+		Version:     "0.4.16",
+		Description: "Add jwt_signing_keys table for asymmetric token signing",
+		Up: func(db *sql.DB, driver string) error {
+			var query string
+
+			switch driver {
+			case "sqlite3":
+				query = `CREATE TABLE IF NOT EXISTS jwt_signing_keys (
+					id INTEGER PRIMARY KEY AUTOINCREMENT,
+					kid TEXT NOT NULL UNIQUE,
+					algorithm TEXT NOT NULL,
+					private_key TEXT NOT NULL,
+					created_at DATETIME NOT NULL,
+					activates_at DATETIME NOT NULL
+				)`
+
+			case "postgres":
+				query = `CREATE TABLE IF NOT EXISTS jwt_signing_keys (
+					id BIGSERIAL PRIMARY KEY,
+					kid VARCHAR(64) NOT NULL UNIQUE,
+					algorithm VARCHAR(16) NOT NULL,
+					private_key TEXT NOT NULL,
+					created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+					activates_at TIMESTAMP NOT NULL
+				)`
+
+			case "mysql":
+				query = `CREATE TABLE IF NOT EXISTS jwt_signing_keys (
+					id BIGINT AUTO_INCREMENT PRIMARY KEY,
+					kid VARCHAR(64) NOT NULL UNIQUE,
+					algorithm VARCHAR(16) NOT NULL,
+					private_key TEXT NOT NULL,
+					created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+					activates_at DATETIME NOT NULL
+				) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`
+
+			default:
+				return fmt.Errorf("unsupported database driver: %s", driver)
+			}
+
+			if _, err := db.Exec(query); err != nil {
+				return fmt.Errorf("failed to execute query: %w", err)
+			}
+			return nil
+		},
+	},
+	{
+		Version:     "0.4.17",
+		Description: "Add disabled flag and password reset / email verification token columns to users",
+		Up: func(db *sql.DB, driver string) error {
+			var queries []string
+
+			switch driver {
+			case "sqlite3":
+				for _, col := range []struct{ name, def string }{
+					{"disabled", `ALTER TABLE users ADD COLUMN disabled INTEGER NOT NULL DEFAULT 0`},
+					{"reset_token", `ALTER TABLE users ADD COLUMN reset_token TEXT`},
+					{"reset_token_expires_at", `ALTER TABLE users ADD COLUMN reset_token_expires_at DATETIME`},
+					{"verification_token", `ALTER TABLE users ADD COLUMN verification_token TEXT`},
+					{"verification_token_expires_at", `ALTER TABLE users ADD COLUMN verification_token_expires_at DATETIME`},
+				} {
+					var count int
+					err := db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('users') WHERE name=?`, col.name).Scan(&count)
+					if err != nil {
+						return fmt.Errorf("failed to check for %s column: %w", col.name, err)
+					}
+					if count == 0 {
+						queries = append(queries, col.def)
+					}
+				}
+				queries = append(queries,
+					`CREATE INDEX IF NOT EXISTS idx_users_reset_token ON users(reset_token)`,
+					`CREATE INDEX IF NOT EXISTS idx_users_verification_token ON users(verification_token)`,
+				)
+
+			case "postgres":
+				queries = []string{
+					`ALTER TABLE users ADD COLUMN IF NOT EXISTS disabled BOOLEAN NOT NULL DEFAULT FALSE`,
+					`ALTER TABLE users ADD COLUMN IF NOT EXISTS reset_token VARCHAR(64)`,
+					`ALTER TABLE users ADD COLUMN IF NOT EXISTS reset_token_expires_at TIMESTAMP`,
+					`ALTER TABLE users ADD COLUMN IF NOT EXISTS verification_token VARCHAR(64)`,
+					`ALTER TABLE users ADD COLUMN IF NOT EXISTS verification_token_expires_at TIMESTAMP`,
+					`CREATE INDEX IF NOT EXISTS idx_users_reset_token ON users(reset_token)`,
+					`CREATE INDEX IF NOT EXISTS idx_users_verification_token ON users(verification_token)`,
+				}
+
+			case "mysql":
+				for _, col := range []struct{ name, def string }{
+					{"disabled", `ALTER TABLE users ADD COLUMN disabled BOOLEAN NOT NULL DEFAULT FALSE`},
+					{"reset_token", `ALTER TABLE users ADD COLUMN reset_token VARCHAR(64), ADD INDEX idx_users_reset_token (reset_token)`},
+					{"reset_token_expires_at", `ALTER TABLE users ADD COLUMN reset_token_expires_at DATETIME`},
+					{"verification_token", `ALTER TABLE users ADD COLUMN verification_token VARCHAR(64), ADD INDEX idx_users_verification_token (verification_token)`},
+					{"verification_token_expires_at", `ALTER TABLE users ADD COLUMN verification_token_expires_at DATETIME`},
+				} {
+					var count int
+					err := db.QueryRow(`SELECT COUNT(*) FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = 'users' AND column_name = ?`, col.name).Scan(&count)
+					if err != nil {
+						return fmt.Errorf("failed to check for %s column: %w", col.name, err)
+					}
+					if count == 0 {
+						queries = append(queries, col.def)
+					}
+				}
+
+			default:
+				return fmt.Errorf("unsupported database driver: %s", driver)
+			}
+
+			for _, query := range queries {
+				if _, err := db.Exec(query); err != nil {
+					return fmt.Errorf("failed to execute query: %w", err)
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version:     "0.4.18",
+		Description: "Add audit_log table for security and admin actions",
+		Up: func(db *sql.DB, driver string) error {
+			var queries []string
+
+			// actor_id and target_id deliberately have no foreign keys so
+			// entries outlive the users they mention
+			switch driver {
+			case "sqlite3":
+				queries = []string{
+					`CREATE TABLE IF NOT EXISTS audit_log (
+						id INTEGER PRIMARY KEY AUTOINCREMENT,
+						action TEXT NOT NULL,
+						actor_id INTEGER,
+						target_type TEXT NOT NULL DEFAULT '',
+						target_id INTEGER,
+						ip_address TEXT NOT NULL DEFAULT '',
+						request_id TEXT NOT NULL DEFAULT '',
+						before_data TEXT,
+						after_data TEXT,
+						created_at DATETIME NOT NULL
+					)`,
+				}
+
+			case "postgres":
+				queries = []string{
+					`CREATE TABLE IF NOT EXISTS audit_log (
+						id BIGSERIAL PRIMARY KEY,
+						action VARCHAR(64) NOT NULL,
+						actor_id BIGINT,
+						target_type VARCHAR(64) NOT NULL DEFAULT '',
+						target_id BIGINT,
+						ip_address VARCHAR(64) NOT NULL DEFAULT '',
+						request_id VARCHAR(64) NOT NULL DEFAULT '',
+						before_data TEXT,
+						after_data TEXT,
+						created_at TIMESTAMP NOT NULL
+					)`,
+				}
+
+			case "mysql":
+				queries = []string{
+					`CREATE TABLE IF NOT EXISTS audit_log (
+						id BIGINT AUTO_INCREMENT PRIMARY KEY,
+						action VARCHAR(64) NOT NULL,
+						actor_id BIGINT,
+						target_type VARCHAR(64) NOT NULL DEFAULT '',
+						target_id BIGINT,
+						ip_address VARCHAR(64) NOT NULL DEFAULT '',
+						request_id VARCHAR(64) NOT NULL DEFAULT '',
+						before_data TEXT,
+						after_data TEXT,
+						created_at DATETIME NOT NULL,
+						INDEX idx_audit_log_created_at (created_at),
+						INDEX idx_audit_log_action (action, created_at),
+						INDEX idx_audit_log_actor (actor_id, created_at),
+						INDEX idx_audit_log_target (target_type, target_id, created_at)
+					) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`,
+				}
+
+			default:
+				return fmt.Errorf("unsupported database driver: %s", driver)
+			}
+
+			if driver != "mysql" {
+				queries = append(queries,
+					`CREATE INDEX IF NOT EXISTS idx_audit_log_created_at ON audit_log(created_at)`,
+					`CREATE INDEX IF NOT EXISTS idx_audit_log_action ON audit_log(action, created_at)`,
+					`CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log(actor_id, created_at)`,
+					`CREATE INDEX IF NOT EXISTS idx_audit_log_target ON audit_log(target_type, target_id, created_at)`,
+				)
+			}
+
+			for _, query := range queries {
+				if _, err := db.Exec(query); err != nil {
+					return fmt.Errorf("failed to execute query: %w", err)
+				}
+			}
+			return nil
+		},
+	},
+}
+
+// runLegacyMigrations brings a database created before the SQL migration
+// files up to the baseline, running the legacy migrations it's missing
+func runLegacyMigrations(db *sql.DB, driver string) error {
+	// Create migrations table if it doesn't exist
+	if err := createLegacyMigrationsTable(db, driver); err != nil {
+		return err
+	}
+
+	// Get applied migrations
+	appliedMigrations, err := getLegacyMigrations(db)
+	if err != nil {
+		return err
+	}
+
+	// Run pending migrations
+	for _, migration := range legacyMigrations {
+		if appliedMigrations[migration.Version] {
+			continue
+		}
+
+		fmt.Printf("Applying legacy migration %s: %s\n", migration.Version, migration.Description)
+
+		// Run the migration
+		if err := migration.Up(db, driver); err != nil {
+			return fmt.Errorf("failed to apply migration %s: %w", migration.Version, err)
+		}
+
+		// Record the migration
+		if err := recordLegacyMigration(db, driver, migration.Version, migration.Description); err != nil {
+			return fmt.Errorf("failed to record migration %s: %w", migration.Version, err)
+		}
+
+		fmt.Printf("✓ Migration %s applied successfully\n", migration.Version)
+	}
+
+	return nil
+}
+
+// createLegacyMigrationsTable creates the legacy schema_migrations table with database-specific syntax
+func createLegacyMigrationsTable(db *sql.DB, driver string) error {
+	var query string
+
+	switch driver {
+	case "sqlite3":
+		query = `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			version TEXT UNIQUE NOT NULL,
+			description TEXT NOT NULL,
+			applied_at DATETIME NOT NULL
+		)`
+
+	case "postgres":
+		query = `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			id BIGSERIAL PRIMARY KEY,
+			version VARCHAR(50) UNIQUE NOT NULL,
+			description TEXT NOT NULL,
+			applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)`
+
+	case "mysql":
+		query = `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			id BIGINT AUTO_INCREMENT PRIMARY KEY,
+			version VARCHAR(50) UNIQUE NOT NULL,
+			description TEXT NOT NULL,
+			applied_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`
+
+	default:
+		return fmt.Errorf("unsupported database driver: %s", driver)
+	}
+
+	_, err := db.Exec(query)
+	return err
+}
+
+// getLegacyMigrations returns the versions of the applied legacy migrations
+func getLegacyMigrations(db *sql.DB) (map[string]bool, error) {
+	query := `SELECT version FROM schema_migrations ORDER BY applied_at`
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[string]bool)
+	for rows.Next() {
+		var version string
+		if err := rows.Scan(&version); err != nil {
+			return nil, err
+		}
+		applied[version] = true
+	}
+
+	return applied, rows.Err()
+}
+
+// recordLegacyMigration records a legacy migration as applied with database-specific syntax
+func recordLegacyMigration(db *sql.DB, driver, version, description string) error {
+	var query string
+
+	switch driver {
+	case "sqlite3", "mysql":
+		query = `INSERT INTO schema_migrations (version, description, applied_at) VALUES (?, ?, ?)`
+		_, err := db.Exec(query, version, description, time.Now())
+		return err
+
+	case "postgres":
+		query = `INSERT INTO schema_migrations (version, description, applied_at) VALUES ($1, $2, $3)`
+		_, err := db.Exec(query, version, description, time.Now())
+		return err
+
+	default:
+		return fmt.Errorf("unsupported database driver: %s", driver)
+	}
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/johnzastrow/actalog/internal/domain"
+)
+
+// LoginLockoutRepository implements domain.LoginLockoutRepository
+type LoginLockoutRepository struct {
+	db *DB
+}
+
+// NewLoginLockoutRepository creates a new login lockout repository
+func NewLoginLockoutRepository(db *sql.DB) *LoginLockoutRepository {
+	return &LoginLockoutRepository{db: NewDB(db)}
+}
+
+// Get retrieves a user's lockout record, or nil if there is none
+func (r *LoginLockoutRepository) Get(ctx context.Context, userID int64) (*domain.LoginLockout, error) {
+	query := `SELECT user_id, failed_attempts, lockouts, locked_until, last_failure_at FROM login_lockouts WHERE user_id = ?`
+
+	lockout := &domain.LoginLockout{}
+	var lockedUntil sql.NullTime
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(
+		&lockout.UserID,
+		&lockout.FailedAttempts,
+		&lockout.Lockouts,
+		&lockedUntil,
+		&lockout.LastFailureAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get login lockout: %w", err)
+	}
+
+	if lockedUntil.Valid {
+		lockout.LockedUntil = &lockedUntil.Time
+	}
+	return lockout, nil
+}
+
+// Save creates or replaces a user's lockout record
+func (r *LoginLockoutRepository) Save(ctx context.Context, lockout *domain.LoginLockout) error {
+	query := `INSERT INTO login_lockouts (user_id, failed_attempts, lockouts, locked_until, last_failure_at)
+	          VALUES (?, ?, ?, ?, ?)` +
+		r.db.Dialect.Upsert([]string{"user_id"}, "failed_attempts", "lockouts", "locked_until", "last_failure_at")
+
+	_, err := r.db.ExecContext(ctx, query,
+		lockout.UserID,
+		lockout.FailedAttempts,
+		lockout.Lockouts,
+		lockout.LockedUntil,
+		lockout.LastFailureAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save login lockout: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes a user's lockout record. Returns false if there was none.
+func (r *LoginLockoutRepository) Delete(ctx context.Context, userID int64) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM login_lockouts WHERE user_id = ?`, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete login lockout: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rows > 0, nil
+}
@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/johnzastrow/actalog/internal/domain"
+)
+
+// MFARepository implements domain.MFARepository
+type MFARepository struct {
+	db *DB
+}
+
+// NewMFARepository creates a new MFA repository
+func NewMFARepository(db *sql.DB) *MFARepository {
+	return &MFARepository{db: NewDB(db)}
+}
+
+// GetTOTP retrieves a user's TOTP enrollment, or nil if there is none
+func (r *MFARepository) GetTOTP(ctx context.Context, userID int64) (*domain.TOTPEnrollment, error) {
+	query := `SELECT user_id, secret, confirmed_at, last_used_step, created_at, updated_at FROM user_totp WHERE user_id = ?`
+
+	enrollment := &domain.TOTPEnrollment{}
+	var confirmedAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(
+		&enrollment.UserID,
+		&enrollment.Secret,
+		&confirmedAt,
+		&enrollment.LastUsedStep,
+		&enrollment.CreatedAt,
+		&enrollment.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get TOTP enrollment: %w", err)
+	}
+
+	if confirmedAt.Valid {
+		enrollment.ConfirmedAt = &confirmedAt.Time
+	}
+	return enrollment, nil
+}
+
+// SaveTOTP creates or replaces a user's TOTP enrollment
+func (r *MFARepository) SaveTOTP(ctx context.Context, enrollment *domain.TOTPEnrollment) error {
+	now := time.Now()
+	enrollment.CreatedAt = now
+	enrollment.UpdatedAt = now
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_totp WHERE user_id = ?`, enrollment.UserID); err != nil {
+		return fmt.Errorf("failed to replace TOTP enrollment: %w", err)
+	}
+
+	query := `INSERT INTO user_totp (user_id, secret, confirmed_at, last_used_step, created_at, updated_at)
+	          VALUES (?, ?, ?, ?, ?, ?)`
+	_, err = tx.ExecContext(ctx, query,
+		enrollment.UserID,
+		enrollment.Secret,
+		enrollment.ConfirmedAt,
+		enrollment.LastUsedStep,
+		enrollment.CreatedAt,
+		enrollment.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save TOTP enrollment: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// ConfirmTOTP marks a user's enrollment confirmed
+func (r *MFARepository) ConfirmTOTP(ctx context.Context, userID int64, confirmedAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE user_totp SET confirmed_at = ?, updated_at = ? WHERE user_id = ?`, confirmedAt, time.Now(), userID)
+	if err != nil {
+		return fmt.Errorf("failed to confirm TOTP enrollment: %w", err)
+	}
+	return nil
+}
+
+// UseTOTPStep records an accepted code's time step. Returns false if that step
+// or a later one was already used.
+func (r *MFARepository) UseTOTPStep(ctx context.Context, userID, step int64) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `UPDATE user_totp SET last_used_step = ?, updated_at = ? WHERE user_id = ? AND last_used_step < ?`, step, time.Now(), userID, step)
+	if err != nil {
+		return false, fmt.Errorf("failed to record TOTP use: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rows > 0, nil
+}
+
+// DeleteTOTP removes a user's TOTP enrollment and recovery codes
+func (r *MFARepository) DeleteTOTP(ctx context.Context, userID int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_recovery_codes WHERE user_id = ?`, userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_totp WHERE user_id = ?`, userID); err != nil {
+		return fmt.Errorf("failed to delete TOTP enrollment: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// ReplaceRecoveryCodes discards a user's recovery codes and stores new hashes
+func (r *MFARepository) ReplaceRecoveryCodes(ctx context.Context, userID int64, codeHashes []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_recovery_codes WHERE user_id = ?`, userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	now := time.Now()
+	for _, hash := range codeHashes {
+		_, err := tx.ExecContext(ctx, `INSERT INTO user_recovery_codes (user_id, code_hash, created_at) VALUES (?, ?, ?)`, userID, hash, now)
+		if err != nil {
+			return fmt.Errorf("failed to create recovery code: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ListUnusedRecoveryCodes retrieves a user's recovery codes that haven't been used
+func (r *MFARepository) ListUnusedRecoveryCodes(ctx context.Context, userID int64) ([]*domain.RecoveryCode, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id, user_id, code_hash, created_at FROM user_recovery_codes WHERE user_id = ? AND used_at IS NULL ORDER BY id`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recovery codes: %w", err)
+	}
+	defer rows.Close()
+
+	codes := []*domain.RecoveryCode{}
+	for rows.Next() {
+		code := &domain.RecoveryCode{}
+		if err := rows.Scan(&code.ID, &code.UserID, &code.CodeHash, &code.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan recovery code: %w", err)
+		}
+		codes = append(codes, code)
+	}
+
+	return codes, rows.Err()
+}
+
+// UseRecoveryCode marks a recovery code used. Returns false if it was already used.
+func (r *MFARepository) UseRecoveryCode(ctx context.Context, id int64, usedAt time.Time) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `UPDATE user_recovery_codes SET used_at = ? WHERE id = ? AND used_at IS NULL`, usedAt, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to use recovery code: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rows > 0, nil
+}
@@ -0,0 +1,394 @@
+-- Baseline schema: everything up to and including the v0.4.18 migrations
+
+CREATE TABLE users (
+	id BIGINT AUTO_INCREMENT PRIMARY KEY,
+	email VARCHAR(255) UNIQUE NOT NULL,
+	password_hash VARCHAR(255) NOT NULL,
+	name VARCHAR(255) NOT NULL,
+	profile_image TEXT,
+	birthday DATE,
+	role VARCHAR(50) NOT NULL DEFAULT 'user',
+	email_verified BOOLEAN NOT NULL DEFAULT FALSE,
+	email_verified_at DATETIME,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+	last_login_at DATETIME,
+	locale VARCHAR(35) NOT NULL DEFAULT 'en',
+	disabled BOOLEAN NOT NULL DEFAULT FALSE,
+	reset_token VARCHAR(64),
+	reset_token_expires_at DATETIME,
+	verification_token VARCHAR(64),
+	verification_token_expires_at DATETIME,
+	INDEX idx_users_email (email),
+	INDEX idx_users_role (role),
+	INDEX idx_users_reset_token (reset_token),
+	INDEX idx_users_verification_token (verification_token)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE workouts (
+	id BIGINT AUTO_INCREMENT PRIMARY KEY,
+	name VARCHAR(255) NOT NULL,
+	notes TEXT,
+	created_by BIGINT,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+	FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL,
+	INDEX idx_workouts_created_by (created_by),
+	INDEX idx_workouts_name (name)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE movements (
+	id BIGINT AUTO_INCREMENT PRIMARY KEY,
+	name VARCHAR(255) UNIQUE NOT NULL,
+	description TEXT,
+	type VARCHAR(50) NOT NULL,
+	is_standard BOOLEAN NOT NULL DEFAULT FALSE,
+	created_by BIGINT,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+	FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL,
+	INDEX idx_movements_name (name),
+	INDEX idx_movements_type (type),
+	INDEX idx_movements_standard (is_standard)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE wods (
+	id BIGINT AUTO_INCREMENT PRIMARY KEY,
+	name VARCHAR(255) UNIQUE NOT NULL,
+	source VARCHAR(255),
+	type VARCHAR(255),
+	regime VARCHAR(255),
+	score_type VARCHAR(255),
+	description TEXT,
+	url TEXT,
+	notes TEXT,
+	is_standard BOOLEAN NOT NULL DEFAULT FALSE,
+	created_by BIGINT,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+	FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL,
+	INDEX idx_wods_name (name),
+	INDEX idx_wods_type (type),
+	INDEX idx_wods_is_standard (is_standard)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE workout_wods (
+	id BIGINT AUTO_INCREMENT PRIMARY KEY,
+	workout_id BIGINT NOT NULL,
+	wod_id BIGINT NOT NULL,
+	score_value TEXT,
+	division TEXT,
+	is_pr BOOLEAN NOT NULL DEFAULT 0,
+	order_index INTEGER NOT NULL DEFAULT 0,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+	FOREIGN KEY (workout_id) REFERENCES workouts(id) ON DELETE CASCADE,
+	FOREIGN KEY (wod_id) REFERENCES wods(id) ON DELETE RESTRICT,
+	INDEX idx_workout_wods_workout_id (workout_id),
+	INDEX idx_workout_wods_wod_id (wod_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- workout_id is NULL for ad-hoc workouts, which are named by workout_name
+CREATE TABLE user_workouts (
+	id BIGINT AUTO_INCREMENT PRIMARY KEY,
+	user_id BIGINT NOT NULL,
+	workout_id BIGINT NULL,
+	workout_date DATE NOT NULL,
+	workout_type VARCHAR(255),
+	total_time INTEGER,
+	notes TEXT,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+	workout_name VARCHAR(255),
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+	FOREIGN KEY (workout_id) REFERENCES workouts(id) ON DELETE RESTRICT,
+	INDEX idx_user_workouts_user_id (user_id),
+	INDEX idx_user_workouts_workout_date (workout_date),
+	INDEX idx_user_workouts_user_date (user_id, workout_date DESC)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE workout_movements (
+	id BIGINT AUTO_INCREMENT PRIMARY KEY,
+	workout_id BIGINT NOT NULL,
+	movement_id BIGINT NOT NULL,
+	weight DOUBLE,
+	sets INTEGER,
+	reps INTEGER,
+	time INTEGER,
+	distance DOUBLE,
+	is_rx BOOLEAN NOT NULL DEFAULT FALSE,
+	is_pr BOOLEAN NOT NULL DEFAULT FALSE,
+	notes TEXT,
+	order_index INTEGER NOT NULL DEFAULT 0,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+	FOREIGN KEY (workout_id) REFERENCES workouts(id) ON DELETE CASCADE,
+	FOREIGN KEY (movement_id) REFERENCES movements(id) ON DELETE RESTRICT,
+	INDEX idx_wm_workout_id (workout_id),
+	INDEX idx_wm_movement_id (movement_id),
+	INDEX idx_wm_workout_order (workout_id, order_index)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE user_workout_movements (
+	id BIGINT AUTO_INCREMENT PRIMARY KEY,
+	user_workout_id BIGINT NOT NULL,
+	movement_id BIGINT NOT NULL,
+	sets INT,
+	reps INT,
+	weight DECIMAL(10,2),
+	time INT,
+	distance DECIMAL(10,2),
+	notes TEXT,
+	order_index INT NOT NULL DEFAULT 0,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+	is_pr BOOLEAN NOT NULL DEFAULT 0,
+	FOREIGN KEY (user_workout_id) REFERENCES user_workouts(id) ON DELETE CASCADE,
+	FOREIGN KEY (movement_id) REFERENCES movements(id) ON DELETE RESTRICT,
+	INDEX idx_user_workout_movements_user_workout_id (user_workout_id),
+	INDEX idx_user_workout_movements_movement_id (movement_id),
+	INDEX idx_user_workout_movements_pr (is_pr)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE user_workout_wods (
+	id BIGINT AUTO_INCREMENT PRIMARY KEY,
+	user_workout_id BIGINT NOT NULL,
+	wod_id BIGINT NOT NULL,
+	score_type VARCHAR(50),
+	score_value TEXT,
+	time_seconds INT,
+	rounds INT,
+	reps INT,
+	weight DECIMAL(10,2),
+	notes TEXT,
+	order_index INT NOT NULL DEFAULT 0,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+	is_pr BOOLEAN NOT NULL DEFAULT 0,
+	FOREIGN KEY (user_workout_id) REFERENCES user_workouts(id) ON DELETE CASCADE,
+	FOREIGN KEY (wod_id) REFERENCES wods(id) ON DELETE RESTRICT,
+	INDEX idx_user_workout_wods_user_workout_id (user_workout_id),
+	INDEX idx_user_workout_wods_wod_id (wod_id),
+	INDEX idx_user_workout_wods_pr (is_pr)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE refresh_tokens (
+	id BIGINT AUTO_INCREMENT PRIMARY KEY,
+	user_id BIGINT NOT NULL,
+	token VARCHAR(255) UNIQUE NOT NULL,
+	expires_at DATETIME NOT NULL,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	revoked_at DATETIME,
+	device_info TEXT,
+	family_id BIGINT,
+	ip_address VARCHAR(45),
+	signed_in_at DATETIME,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+	INDEX idx_refresh_tokens_user_id (user_id),
+	INDEX idx_refresh_tokens_family_id (family_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE user_settings (
+	id BIGINT AUTO_INCREMENT PRIMARY KEY,
+	user_id BIGINT UNIQUE NOT NULL,
+	notification_preferences TEXT NOT NULL,
+	data_export_format VARCHAR(20) NOT NULL DEFAULT 'json',
+	theme VARCHAR(20) NOT NULL DEFAULT 'light',
+	weight_unit VARCHAR(10) NOT NULL DEFAULT 'lbs',
+	distance_unit VARCHAR(10) NOT NULL DEFAULT 'miles',
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE webhooks (
+	id BIGINT AUTO_INCREMENT PRIMARY KEY,
+	user_id BIGINT NOT NULL,
+	url TEXT NOT NULL,
+	secret VARCHAR(255) NOT NULL,
+	events TEXT NOT NULL,
+	all_users BOOLEAN NOT NULL DEFAULT FALSE,
+	description TEXT,
+	is_active BOOLEAN NOT NULL DEFAULT TRUE,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+	INDEX idx_webhooks_user_id (user_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE webhook_deliveries (
+	id BIGINT AUTO_INCREMENT PRIMARY KEY,
+	webhook_id BIGINT NOT NULL,
+	event_type VARCHAR(50) NOT NULL,
+	payload MEDIUMTEXT NOT NULL,
+	attempts INT NOT NULL DEFAULT 0,
+	status_code INT,
+	response_body TEXT,
+	error TEXT,
+	success BOOLEAN NOT NULL DEFAULT FALSE,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	delivered_at DATETIME,
+	FOREIGN KEY (webhook_id) REFERENCES webhooks(id) ON DELETE CASCADE,
+	INDEX idx_webhook_deliveries_webhook_id (webhook_id, created_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE email_outbox (
+	id BIGINT AUTO_INCREMENT PRIMARY KEY,
+	recipients TEXT NOT NULL,
+	subject VARCHAR(998) NOT NULL,
+	body MEDIUMTEXT NOT NULL,
+	is_html BOOLEAN NOT NULL DEFAULT FALSE,
+	status VARCHAR(20) NOT NULL DEFAULT 'pending',
+	attempts INT NOT NULL DEFAULT 0,
+	last_error TEXT,
+	next_attempt_at DATETIME NOT NULL,
+	sent_at DATETIME,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+	text_body MEDIUMTEXT,
+	INDEX idx_email_outbox_status_next (status, next_attempt_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE digest_deliveries (
+	id BIGINT AUTO_INCREMENT PRIMARY KEY,
+	user_id BIGINT NOT NULL,
+	week_start VARCHAR(10) NOT NULL,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+	UNIQUE INDEX idx_digest_deliveries_user_week (user_id, week_start)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE notifications (
+	id BIGINT AUTO_INCREMENT PRIMARY KEY,
+	user_id BIGINT NOT NULL,
+	type VARCHAR(50) NOT NULL,
+	title VARCHAR(255) NOT NULL,
+	body TEXT NOT NULL,
+	link VARCHAR(255),
+	dedupe_key VARCHAR(191),
+	read_at DATETIME,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+	INDEX idx_notifications_user_created (user_id, created_at),
+	INDEX idx_notifications_user_read (user_id, read_at),
+	UNIQUE INDEX idx_notifications_user_dedupe (user_id, dedupe_key)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE user_totp (
+	user_id BIGINT PRIMARY KEY,
+	secret VARCHAR(64) NOT NULL,
+	confirmed_at DATETIME,
+	last_used_step BIGINT NOT NULL DEFAULT 0,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE user_recovery_codes (
+	id BIGINT AUTO_INCREMENT PRIMARY KEY,
+	user_id BIGINT NOT NULL,
+	code_hash VARCHAR(255) NOT NULL,
+	used_at DATETIME,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+	INDEX idx_user_recovery_codes_user_id (user_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE passkeys (
+	id BIGINT AUTO_INCREMENT PRIMARY KEY,
+	user_id BIGINT NOT NULL,
+	credential_id VARCHAR(1400) NOT NULL,
+	public_key TEXT NOT NULL,
+	sign_count BIGINT NOT NULL DEFAULT 0,
+	transports VARCHAR(255) NOT NULL DEFAULT '',
+	name VARCHAR(100) NOT NULL,
+	last_used_at DATETIME,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+	UNIQUE KEY uq_passkeys_credential_id (credential_id(255)),
+	INDEX idx_passkeys_user_id (user_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE passkey_challenges (
+	id BIGINT AUTO_INCREMENT PRIMARY KEY,
+	challenge VARCHAR(64) NOT NULL UNIQUE,
+	purpose VARCHAR(20) NOT NULL,
+	user_id BIGINT,
+	expires_at DATETIME NOT NULL,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE user_identities (
+	id BIGINT AUTO_INCREMENT PRIMARY KEY,
+	user_id BIGINT NOT NULL,
+	issuer VARCHAR(255) NOT NULL,
+	subject VARCHAR(255) NOT NULL,
+	email VARCHAR(255) NOT NULL DEFAULT '',
+	last_login_at DATETIME,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+	UNIQUE KEY uq_user_identities_issuer_subject (issuer(191), subject(191)),
+	INDEX idx_user_identities_user_id (user_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE oidc_login_states (
+	id BIGINT AUTO_INCREMENT PRIMARY KEY,
+	state VARCHAR(64) NOT NULL UNIQUE,
+	nonce VARCHAR(64) NOT NULL,
+	code_verifier VARCHAR(128) NOT NULL,
+	expires_at DATETIME NOT NULL,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE api_tokens (
+	id BIGINT AUTO_INCREMENT PRIMARY KEY,
+	user_id BIGINT NOT NULL,
+	name VARCHAR(100) NOT NULL,
+	prefix VARCHAR(32) NOT NULL,
+	token_hash VARCHAR(64) NOT NULL UNIQUE,
+	scopes VARCHAR(255) NOT NULL,
+	last_used_at DATETIME,
+	expires_at DATETIME,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+	INDEX idx_api_tokens_user_id (user_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE login_lockouts (
+	user_id BIGINT PRIMARY KEY,
+	failed_attempts INT NOT NULL DEFAULT 0,
+	lockouts INT NOT NULL DEFAULT 0,
+	locked_until DATETIME,
+	last_failure_at DATETIME NOT NULL,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE jwt_signing_keys (
+	id BIGINT AUTO_INCREMENT PRIMARY KEY,
+	kid VARCHAR(64) NOT NULL UNIQUE,
+	algorithm VARCHAR(16) NOT NULL,
+	private_key TEXT NOT NULL,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	activates_at DATETIME NOT NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- actor_id and target_id deliberately have no foreign keys so entries
+-- outlive the users they mention
+CREATE TABLE audit_log (
+	id BIGINT AUTO_INCREMENT PRIMARY KEY,
+	action VARCHAR(64) NOT NULL,
+	actor_id BIGINT,
+	target_type VARCHAR(64) NOT NULL DEFAULT '',
+	target_id BIGINT,
+	ip_address VARCHAR(64) NOT NULL DEFAULT '',
+	request_id VARCHAR(64) NOT NULL DEFAULT '',
+	before_data TEXT,
+	after_data TEXT,
+	created_at DATETIME NOT NULL,
+	INDEX idx_audit_log_created_at (created_at),
+	INDEX idx_audit_log_action (action, created_at),
+	INDEX idx_audit_log_actor (actor_id, created_at),
+	INDEX idx_audit_log_target (target_type, target_id, created_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,4 @@
+ALTER TABLE workouts DROP INDEX idx_workouts_search;
+ALTER TABLE user_workouts DROP INDEX idx_user_workouts_search;
+ALTER TABLE wods DROP INDEX idx_wods_search;
+ALTER TABLE movements DROP INDEX idx_movements_search;
@@ -0,0 +1,8 @@
+-- Full-text search over movements, WODs and logged workouts. MATCH() must
+-- name exactly the columns of one of these indexes. A logged workout matches
+-- on its own name and notes or on its template's name.
+
+ALTER TABLE movements ADD FULLTEXT INDEX idx_movements_search (name, description);
+ALTER TABLE wods ADD FULLTEXT INDEX idx_wods_search (name, description, notes);
+ALTER TABLE user_workouts ADD FULLTEXT INDEX idx_user_workouts_search (workout_name, notes);
+ALTER TABLE workouts ADD FULLTEXT INDEX idx_workouts_search (name);
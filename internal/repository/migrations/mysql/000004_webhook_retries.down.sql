@@ -0,0 +1,4 @@
+DROP INDEX idx_webhook_deliveries_due ON webhook_deliveries;
+
+ALTER TABLE webhook_deliveries ADD COLUMN response_body TEXT;
+ALTER TABLE webhook_deliveries DROP COLUMN next_attempt_at;
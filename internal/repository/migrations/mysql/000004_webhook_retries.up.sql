@@ -0,0 +1,9 @@
+-- Webhook deliveries are retried by a background worker instead of sleeping
+-- goroutines, so retries survive a restart. next_attempt_at is when a
+-- delivery is next due, and NULL once it succeeded or gave up. Receivers'
+-- response bodies are no longer kept.
+
+ALTER TABLE webhook_deliveries ADD COLUMN next_attempt_at DATETIME;
+ALTER TABLE webhook_deliveries DROP COLUMN response_body;
+
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries(next_attempt_at);
@@ -0,0 +1,413 @@
+-- Baseline schema: everything up to and including the v0.4.18 migrations
+
+CREATE TABLE users (
+	id BIGSERIAL PRIMARY KEY,
+	email VARCHAR(255) UNIQUE NOT NULL,
+	password_hash VARCHAR(255) NOT NULL,
+	name VARCHAR(255) NOT NULL,
+	profile_image TEXT,
+	birthday DATE,
+	role VARCHAR(50) NOT NULL DEFAULT 'user',
+	email_verified BOOLEAN NOT NULL DEFAULT FALSE,
+	email_verified_at TIMESTAMP,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	last_login_at TIMESTAMP,
+	locale VARCHAR(35) NOT NULL DEFAULT 'en',
+	disabled BOOLEAN NOT NULL DEFAULT FALSE,
+	reset_token VARCHAR(64),
+	reset_token_expires_at TIMESTAMP,
+	verification_token VARCHAR(64),
+	verification_token_expires_at TIMESTAMP
+);
+
+CREATE INDEX idx_users_email ON users(email);
+CREATE INDEX idx_users_role ON users(role);
+CREATE INDEX idx_users_reset_token ON users(reset_token);
+CREATE INDEX idx_users_verification_token ON users(verification_token);
+
+CREATE TABLE workouts (
+	id BIGSERIAL PRIMARY KEY,
+	name VARCHAR(255) NOT NULL,
+	notes TEXT,
+	created_by BIGINT,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_workouts_created_by ON workouts(created_by);
+CREATE INDEX idx_workouts_name ON workouts(name);
+
+CREATE TABLE movements (
+	id BIGSERIAL PRIMARY KEY,
+	name VARCHAR(255) UNIQUE NOT NULL,
+	description TEXT,
+	type VARCHAR(50) NOT NULL,
+	is_standard BOOLEAN NOT NULL DEFAULT FALSE,
+	created_by BIGINT,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_movements_name ON movements(name);
+CREATE INDEX idx_movements_type ON movements(type);
+CREATE INDEX idx_movements_standard ON movements(is_standard);
+
+CREATE TABLE wods (
+	id BIGSERIAL PRIMARY KEY,
+	name VARCHAR(255) UNIQUE NOT NULL,
+	source VARCHAR(255),
+	type VARCHAR(255),
+	regime VARCHAR(255),
+	score_type VARCHAR(255),
+	description TEXT,
+	url TEXT,
+	notes TEXT,
+	is_standard BOOLEAN NOT NULL DEFAULT FALSE,
+	created_by BIGINT,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_wods_name ON wods(name);
+CREATE INDEX idx_wods_type ON wods(type);
+CREATE INDEX idx_wods_is_standard ON wods(is_standard);
+
+CREATE TABLE workout_wods (
+	id BIGSERIAL PRIMARY KEY,
+	workout_id BIGINT NOT NULL,
+	wod_id BIGINT NOT NULL,
+	score_value TEXT,
+	division TEXT,
+	is_pr BOOLEAN NOT NULL DEFAULT FALSE,
+	order_index INTEGER NOT NULL DEFAULT 0,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (workout_id) REFERENCES workouts(id) ON DELETE CASCADE,
+	FOREIGN KEY (wod_id) REFERENCES wods(id) ON DELETE RESTRICT
+);
+
+CREATE INDEX idx_workout_wods_workout_id ON workout_wods(workout_id);
+CREATE INDEX idx_workout_wods_wod_id ON workout_wods(wod_id);
+
+-- workout_id is NULL for ad-hoc workouts, which are named by workout_name
+CREATE TABLE user_workouts (
+	id BIGSERIAL PRIMARY KEY,
+	user_id BIGINT NOT NULL,
+	workout_id BIGINT,
+	workout_date DATE NOT NULL,
+	workout_type VARCHAR(255),
+	total_time INTEGER,
+	notes TEXT,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	workout_name VARCHAR(255),
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+	FOREIGN KEY (workout_id) REFERENCES workouts(id) ON DELETE RESTRICT
+);
+
+CREATE INDEX idx_user_workouts_user_id ON user_workouts(user_id);
+CREATE INDEX idx_user_workouts_workout_date ON user_workouts(workout_date);
+CREATE INDEX idx_user_workouts_user_date ON user_workouts(user_id, workout_date DESC);
+
+CREATE TABLE workout_movements (
+	id BIGSERIAL PRIMARY KEY,
+	workout_id BIGINT NOT NULL,
+	movement_id BIGINT NOT NULL,
+	weight DOUBLE PRECISION,
+	sets INTEGER,
+	reps INTEGER,
+	time INTEGER,
+	distance DOUBLE PRECISION,
+	is_rx BOOLEAN NOT NULL DEFAULT FALSE,
+	is_pr BOOLEAN NOT NULL DEFAULT FALSE,
+	notes TEXT,
+	order_index INTEGER NOT NULL DEFAULT 0,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (workout_id) REFERENCES workouts(id) ON DELETE CASCADE,
+	FOREIGN KEY (movement_id) REFERENCES movements(id) ON DELETE RESTRICT
+);
+
+CREATE INDEX idx_wm_workout_id ON workout_movements(workout_id);
+CREATE INDEX idx_wm_movement_id ON workout_movements(movement_id);
+CREATE INDEX idx_wm_workout_order ON workout_movements(workout_id, order_index);
+
+CREATE TABLE user_workout_movements (
+	id BIGSERIAL PRIMARY KEY,
+	user_workout_id BIGINT NOT NULL,
+	movement_id BIGINT NOT NULL,
+	sets INTEGER,
+	reps INTEGER,
+	weight DECIMAL(10,2),
+	time INTEGER,
+	distance DECIMAL(10,2),
+	notes TEXT,
+	order_index INTEGER NOT NULL DEFAULT 0,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	is_pr BOOLEAN NOT NULL DEFAULT FALSE,
+	FOREIGN KEY (user_workout_id) REFERENCES user_workouts(id) ON DELETE CASCADE,
+	FOREIGN KEY (movement_id) REFERENCES movements(id) ON DELETE RESTRICT
+);
+
+CREATE INDEX idx_user_workout_movements_user_workout_id ON user_workout_movements(user_workout_id);
+CREATE INDEX idx_user_workout_movements_movement_id ON user_workout_movements(movement_id);
+CREATE INDEX idx_user_workout_movements_pr ON user_workout_movements(is_pr);
+
+CREATE TABLE user_workout_wods (
+	id BIGSERIAL PRIMARY KEY,
+	user_workout_id BIGINT NOT NULL,
+	wod_id BIGINT NOT NULL,
+	score_type VARCHAR(50),
+	score_value TEXT,
+	time_seconds INTEGER,
+	rounds INTEGER,
+	reps INTEGER,
+	weight DECIMAL(10,2),
+	notes TEXT,
+	order_index INTEGER NOT NULL DEFAULT 0,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	is_pr BOOLEAN NOT NULL DEFAULT FALSE,
+	FOREIGN KEY (user_workout_id) REFERENCES user_workouts(id) ON DELETE CASCADE,
+	FOREIGN KEY (wod_id) REFERENCES wods(id) ON DELETE RESTRICT
+);
+
+CREATE INDEX idx_user_workout_wods_user_workout_id ON user_workout_wods(user_workout_id);
+CREATE INDEX idx_user_workout_wods_wod_id ON user_workout_wods(wod_id);
+CREATE INDEX idx_user_workout_wods_pr ON user_workout_wods(is_pr);
+
+CREATE TABLE refresh_tokens (
+	id BIGSERIAL PRIMARY KEY,
+	user_id BIGINT NOT NULL,
+	token VARCHAR(255) UNIQUE NOT NULL,
+	expires_at TIMESTAMP NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	revoked_at TIMESTAMP,
+	device_info TEXT,
+	family_id BIGINT,
+	ip_address VARCHAR(45),
+	signed_in_at TIMESTAMP,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_refresh_tokens_user_id ON refresh_tokens(user_id);
+CREATE INDEX idx_refresh_tokens_family_id ON refresh_tokens(family_id);
+
+CREATE TABLE user_settings (
+	id BIGSERIAL PRIMARY KEY,
+	user_id BIGINT UNIQUE NOT NULL,
+	notification_preferences TEXT NOT NULL DEFAULT '{}',
+	data_export_format VARCHAR(20) NOT NULL DEFAULT 'json',
+	theme VARCHAR(20) NOT NULL DEFAULT 'light',
+	weight_unit VARCHAR(10) NOT NULL DEFAULT 'lbs',
+	distance_unit VARCHAR(10) NOT NULL DEFAULT 'miles',
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE webhooks (
+	id BIGSERIAL PRIMARY KEY,
+	user_id BIGINT NOT NULL,
+	url TEXT NOT NULL,
+	secret VARCHAR(255) NOT NULL,
+	events TEXT NOT NULL,
+	all_users BOOLEAN NOT NULL DEFAULT FALSE,
+	description TEXT,
+	is_active BOOLEAN NOT NULL DEFAULT TRUE,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_webhooks_user_id ON webhooks(user_id);
+
+CREATE TABLE webhook_deliveries (
+	id BIGSERIAL PRIMARY KEY,
+	webhook_id BIGINT NOT NULL,
+	event_type VARCHAR(50) NOT NULL,
+	payload TEXT NOT NULL,
+	attempts INTEGER NOT NULL DEFAULT 0,
+	status_code INTEGER,
+	response_body TEXT,
+	error TEXT,
+	success BOOLEAN NOT NULL DEFAULT FALSE,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	delivered_at TIMESTAMP,
+	FOREIGN KEY (webhook_id) REFERENCES webhooks(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_webhook_deliveries_webhook_id ON webhook_deliveries(webhook_id, created_at);
+
+CREATE TABLE email_outbox (
+	id BIGSERIAL PRIMARY KEY,
+	recipients TEXT NOT NULL,
+	subject TEXT NOT NULL,
+	body TEXT NOT NULL,
+	is_html BOOLEAN NOT NULL DEFAULT FALSE,
+	status VARCHAR(20) NOT NULL DEFAULT 'pending',
+	attempts INTEGER NOT NULL DEFAULT 0,
+	last_error TEXT,
+	next_attempt_at TIMESTAMP NOT NULL,
+	sent_at TIMESTAMP,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	text_body TEXT
+);
+
+CREATE INDEX idx_email_outbox_status_next ON email_outbox(status, next_attempt_at);
+
+CREATE TABLE digest_deliveries (
+	id BIGSERIAL PRIMARY KEY,
+	user_id BIGINT NOT NULL,
+	week_start VARCHAR(10) NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_digest_deliveries_user_week ON digest_deliveries(user_id, week_start);
+
+CREATE TABLE notifications (
+	id BIGSERIAL PRIMARY KEY,
+	user_id BIGINT NOT NULL,
+	type VARCHAR(50) NOT NULL,
+	title VARCHAR(255) NOT NULL,
+	body TEXT NOT NULL DEFAULT '',
+	link VARCHAR(255),
+	dedupe_key VARCHAR(191),
+	read_at TIMESTAMP,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_notifications_user_created ON notifications(user_id, created_at);
+CREATE INDEX idx_notifications_user_read ON notifications(user_id, read_at);
+CREATE UNIQUE INDEX idx_notifications_user_dedupe ON notifications(user_id, dedupe_key);
+
+CREATE TABLE user_totp (
+	user_id BIGINT PRIMARY KEY,
+	secret VARCHAR(64) NOT NULL,
+	confirmed_at TIMESTAMP,
+	last_used_step BIGINT NOT NULL DEFAULT 0,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE user_recovery_codes (
+	id BIGSERIAL PRIMARY KEY,
+	user_id BIGINT NOT NULL,
+	code_hash VARCHAR(255) NOT NULL,
+	used_at TIMESTAMP,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_user_recovery_codes_user_id ON user_recovery_codes(user_id);
+
+CREATE TABLE passkeys (
+	id BIGSERIAL PRIMARY KEY,
+	user_id BIGINT NOT NULL,
+	credential_id VARCHAR(1400) NOT NULL UNIQUE,
+	public_key TEXT NOT NULL,
+	sign_count BIGINT NOT NULL DEFAULT 0,
+	transports VARCHAR(255) NOT NULL DEFAULT '',
+	name VARCHAR(100) NOT NULL,
+	last_used_at TIMESTAMP,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_passkeys_user_id ON passkeys(user_id);
+
+CREATE TABLE passkey_challenges (
+	id BIGSERIAL PRIMARY KEY,
+	challenge VARCHAR(64) NOT NULL UNIQUE,
+	purpose VARCHAR(20) NOT NULL,
+	user_id BIGINT,
+	expires_at TIMESTAMP NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE user_identities (
+	id BIGSERIAL PRIMARY KEY,
+	user_id BIGINT NOT NULL,
+	issuer VARCHAR(255) NOT NULL,
+	subject VARCHAR(255) NOT NULL,
+	email VARCHAR(255) NOT NULL DEFAULT '',
+	last_login_at TIMESTAMP,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+	UNIQUE (issuer, subject)
+);
+
+CREATE INDEX idx_user_identities_user_id ON user_identities(user_id);
+
+CREATE TABLE oidc_login_states (
+	id BIGSERIAL PRIMARY KEY,
+	state VARCHAR(64) NOT NULL UNIQUE,
+	nonce VARCHAR(64) NOT NULL,
+	code_verifier VARCHAR(128) NOT NULL,
+	expires_at TIMESTAMP NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE api_tokens (
+	id BIGSERIAL PRIMARY KEY,
+	user_id BIGINT NOT NULL,
+	name VARCHAR(100) NOT NULL,
+	prefix VARCHAR(32) NOT NULL,
+	token_hash VARCHAR(64) NOT NULL UNIQUE,
+	scopes VARCHAR(255) NOT NULL,
+	last_used_at TIMESTAMP,
+	expires_at TIMESTAMP,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_api_tokens_user_id ON api_tokens(user_id);
+
+CREATE TABLE login_lockouts (
+	user_id BIGINT PRIMARY KEY,
+	failed_attempts INTEGER NOT NULL DEFAULT 0,
+	lockouts INTEGER NOT NULL DEFAULT 0,
+	locked_until TIMESTAMP,
+	last_failure_at TIMESTAMP NOT NULL,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE jwt_signing_keys (
+	id BIGSERIAL PRIMARY KEY,
+	kid VARCHAR(64) NOT NULL UNIQUE,
+	algorithm VARCHAR(16) NOT NULL,
+	private_key TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	activates_at TIMESTAMP NOT NULL
+);
+
+-- actor_id and target_id deliberately have no foreign keys so entries
+-- outlive the users they mention
+CREATE TABLE audit_log (
+	id BIGSERIAL PRIMARY KEY,
+	action VARCHAR(64) NOT NULL,
+	actor_id BIGINT,
+	target_type VARCHAR(64) NOT NULL DEFAULT '',
+	target_id BIGINT,
+	ip_address VARCHAR(64) NOT NULL DEFAULT '',
+	request_id VARCHAR(64) NOT NULL DEFAULT '',
+	before_data TEXT,
+	after_data TEXT,
+	created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_audit_log_created_at ON audit_log(created_at);
+CREATE INDEX idx_audit_log_action ON audit_log(action, created_at);
+CREATE INDEX idx_audit_log_actor ON audit_log(actor_id, created_at);
+CREATE INDEX idx_audit_log_target ON audit_log(target_type, target_id, created_at);
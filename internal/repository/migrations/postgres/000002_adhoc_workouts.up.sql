@@ -0,0 +1,2 @@
+-- user_workouts.workout_id has allowed NULL on PostgreSQL since the baseline;
+-- only SQLite needed its table rebuilt for ad-hoc workouts.
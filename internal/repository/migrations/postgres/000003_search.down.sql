@@ -0,0 +1,2 @@
+DROP INDEX idx_wods_search;
+DROP INDEX idx_movements_search;
@@ -0,0 +1,15 @@
+-- Full-text search over movements, WODs and logged workouts. The indexes are
+-- on expressions, which SearchRepository's queries repeat exactly so that
+-- they're used. A logged workout's vector also takes its template's name,
+-- which no index can cover; those searches are narrowed by user first.
+
+CREATE INDEX idx_movements_search ON movements USING GIN ((
+	setweight(to_tsvector('english', COALESCE(name, '')), 'A') ||
+	setweight(to_tsvector('english', COALESCE(description, '')), 'B')
+));
+
+CREATE INDEX idx_wods_search ON wods USING GIN ((
+	setweight(to_tsvector('english', COALESCE(name, '')), 'A') ||
+	setweight(to_tsvector('english', COALESCE(description, '')), 'B') ||
+	setweight(to_tsvector('english', COALESCE(notes, '')), 'C')
+));
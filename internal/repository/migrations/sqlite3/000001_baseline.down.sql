@@ -0,0 +1,28 @@
+-- Drops everything the baseline created, children before their parents
+
+DROP TABLE audit_log;
+DROP TABLE jwt_signing_keys;
+DROP TABLE login_lockouts;
+DROP TABLE api_tokens;
+DROP TABLE oidc_login_states;
+DROP TABLE user_identities;
+DROP TABLE passkey_challenges;
+DROP TABLE passkeys;
+DROP TABLE user_recovery_codes;
+DROP TABLE user_totp;
+DROP TABLE notifications;
+DROP TABLE digest_deliveries;
+DROP TABLE email_outbox;
+DROP TABLE webhook_deliveries;
+DROP TABLE webhooks;
+DROP TABLE user_settings;
+DROP TABLE refresh_tokens;
+DROP TABLE user_workout_wods;
+DROP TABLE user_workout_movements;
+DROP TABLE workout_movements;
+DROP TABLE user_workouts;
+DROP TABLE workout_wods;
+DROP TABLE wods;
+DROP TABLE movements;
+DROP TABLE workouts;
+DROP TABLE users;
@@ -0,0 +1,412 @@
+-- Baseline schema: everything up to and including the v0.4.18 migrations
+
+CREATE TABLE users (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	email TEXT UNIQUE NOT NULL,
+	password_hash TEXT NOT NULL,
+	name TEXT NOT NULL,
+	profile_image TEXT,
+	birthday DATE,
+	role TEXT NOT NULL DEFAULT 'user',
+	email_verified INTEGER NOT NULL DEFAULT 0,
+	email_verified_at DATETIME,
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL,
+	last_login_at DATETIME,
+	locale TEXT NOT NULL DEFAULT 'en',
+	disabled INTEGER NOT NULL DEFAULT 0,
+	reset_token TEXT,
+	reset_token_expires_at DATETIME,
+	verification_token TEXT,
+	verification_token_expires_at DATETIME
+);
+
+CREATE INDEX idx_users_email ON users(email);
+CREATE INDEX idx_users_role ON users(role);
+CREATE INDEX idx_users_reset_token ON users(reset_token);
+CREATE INDEX idx_users_verification_token ON users(verification_token);
+
+CREATE TABLE workouts (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	notes TEXT,
+	created_by INTEGER,
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL,
+	FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_workouts_created_by ON workouts(created_by);
+CREATE INDEX idx_workouts_name ON workouts(name);
+
+CREATE TABLE movements (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT UNIQUE NOT NULL,
+	description TEXT,
+	type TEXT NOT NULL,
+	is_standard INTEGER NOT NULL DEFAULT 0,
+	created_by INTEGER,
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL,
+	FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_movements_name ON movements(name);
+CREATE INDEX idx_movements_type ON movements(type);
+CREATE INDEX idx_movements_standard ON movements(is_standard);
+
+CREATE TABLE wods (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT UNIQUE NOT NULL,
+	source TEXT,
+	type TEXT,
+	regime TEXT,
+	score_type TEXT,
+	description TEXT,
+	url TEXT,
+	notes TEXT,
+	is_standard INTEGER NOT NULL DEFAULT 0,
+	created_by INTEGER,
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL,
+	FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_wods_name ON wods(name);
+CREATE INDEX idx_wods_type ON wods(type);
+CREATE INDEX idx_wods_is_standard ON wods(is_standard);
+
+CREATE TABLE workout_wods (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	workout_id INTEGER NOT NULL,
+	wod_id INTEGER NOT NULL,
+	score_value TEXT,
+	division TEXT,
+	is_pr INTEGER NOT NULL DEFAULT 0,
+	order_index INTEGER NOT NULL DEFAULT 0,
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL,
+	FOREIGN KEY (workout_id) REFERENCES workouts(id) ON DELETE CASCADE,
+	FOREIGN KEY (wod_id) REFERENCES wods(id) ON DELETE RESTRICT
+);
+
+CREATE INDEX idx_workout_wods_workout_id ON workout_wods(workout_id);
+CREATE INDEX idx_workout_wods_wod_id ON workout_wods(wod_id);
+
+CREATE TABLE user_workouts (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL,
+	workout_id INTEGER NOT NULL,
+	workout_date DATE NOT NULL,
+	workout_type TEXT,
+	total_time INTEGER,
+	notes TEXT,
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL,
+	workout_name TEXT,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+	FOREIGN KEY (workout_id) REFERENCES workouts(id) ON DELETE RESTRICT
+);
+
+CREATE INDEX idx_user_workouts_user_id ON user_workouts(user_id);
+CREATE INDEX idx_user_workouts_workout_date ON user_workouts(workout_date);
+CREATE INDEX idx_user_workouts_user_date ON user_workouts(user_id, workout_date DESC);
+
+CREATE TABLE workout_movements (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	workout_id INTEGER NOT NULL,
+	movement_id INTEGER NOT NULL,
+	weight REAL,
+	sets INTEGER,
+	reps INTEGER,
+	time INTEGER,
+	distance REAL,
+	is_rx INTEGER NOT NULL DEFAULT 0,
+	is_pr INTEGER NOT NULL DEFAULT 0,
+	notes TEXT,
+	order_index INTEGER NOT NULL DEFAULT 0,
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL,
+	FOREIGN KEY (workout_id) REFERENCES workouts(id) ON DELETE CASCADE,
+	FOREIGN KEY (movement_id) REFERENCES movements(id) ON DELETE RESTRICT
+);
+
+CREATE INDEX idx_wm_workout_id ON workout_movements(workout_id);
+CREATE INDEX idx_wm_movement_id ON workout_movements(movement_id);
+CREATE INDEX idx_wm_workout_order ON workout_movements(workout_id, order_index);
+
+CREATE TABLE user_workout_movements (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_workout_id INTEGER NOT NULL,
+	movement_id INTEGER NOT NULL,
+	sets INTEGER,
+	reps INTEGER,
+	weight REAL,
+	time INTEGER,
+	distance REAL,
+	notes TEXT,
+	order_index INTEGER NOT NULL DEFAULT 0,
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL,
+	is_pr INTEGER NOT NULL DEFAULT 0,
+	FOREIGN KEY (user_workout_id) REFERENCES user_workouts(id) ON DELETE CASCADE,
+	FOREIGN KEY (movement_id) REFERENCES movements(id) ON DELETE RESTRICT
+);
+
+CREATE INDEX idx_user_workout_movements_user_workout_id ON user_workout_movements(user_workout_id);
+CREATE INDEX idx_user_workout_movements_movement_id ON user_workout_movements(movement_id);
+CREATE INDEX idx_user_workout_movements_pr ON user_workout_movements(is_pr);
+
+CREATE TABLE user_workout_wods (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_workout_id INTEGER NOT NULL,
+	wod_id INTEGER NOT NULL,
+	score_type TEXT,
+	score_value TEXT,
+	time_seconds INTEGER,
+	rounds INTEGER,
+	reps INTEGER,
+	weight REAL,
+	notes TEXT,
+	order_index INTEGER NOT NULL DEFAULT 0,
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL,
+	is_pr INTEGER NOT NULL DEFAULT 0,
+	FOREIGN KEY (user_workout_id) REFERENCES user_workouts(id) ON DELETE CASCADE,
+	FOREIGN KEY (wod_id) REFERENCES wods(id) ON DELETE RESTRICT
+);
+
+CREATE INDEX idx_user_workout_wods_user_workout_id ON user_workout_wods(user_workout_id);
+CREATE INDEX idx_user_workout_wods_wod_id ON user_workout_wods(wod_id);
+CREATE INDEX idx_user_workout_wods_pr ON user_workout_wods(is_pr);
+
+CREATE TABLE refresh_tokens (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL,
+	token TEXT UNIQUE NOT NULL,
+	expires_at DATETIME NOT NULL,
+	created_at DATETIME NOT NULL,
+	revoked_at DATETIME,
+	device_info TEXT,
+	family_id INTEGER,
+	ip_address TEXT,
+	signed_in_at DATETIME,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_refresh_tokens_user_id ON refresh_tokens(user_id);
+CREATE INDEX idx_refresh_tokens_family_id ON refresh_tokens(family_id);
+
+CREATE TABLE user_settings (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER UNIQUE NOT NULL,
+	notification_preferences TEXT NOT NULL DEFAULT '{}',
+	data_export_format TEXT NOT NULL DEFAULT 'json',
+	theme TEXT NOT NULL DEFAULT 'light',
+	weight_unit TEXT NOT NULL DEFAULT 'lbs',
+	distance_unit TEXT NOT NULL DEFAULT 'miles',
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE webhooks (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL,
+	url TEXT NOT NULL,
+	secret TEXT NOT NULL,
+	events TEXT NOT NULL,
+	all_users INTEGER NOT NULL DEFAULT 0,
+	description TEXT,
+	is_active INTEGER NOT NULL DEFAULT 1,
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_webhooks_user_id ON webhooks(user_id);
+
+CREATE TABLE webhook_deliveries (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	webhook_id INTEGER NOT NULL,
+	event_type TEXT NOT NULL,
+	payload TEXT NOT NULL,
+	attempts INTEGER NOT NULL DEFAULT 0,
+	status_code INTEGER,
+	response_body TEXT,
+	error TEXT,
+	success INTEGER NOT NULL DEFAULT 0,
+	created_at DATETIME NOT NULL,
+	delivered_at DATETIME,
+	FOREIGN KEY (webhook_id) REFERENCES webhooks(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_webhook_deliveries_webhook_id ON webhook_deliveries(webhook_id, created_at);
+
+CREATE TABLE email_outbox (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	recipients TEXT NOT NULL,
+	subject TEXT NOT NULL,
+	body TEXT NOT NULL,
+	is_html INTEGER NOT NULL DEFAULT 0,
+	status TEXT NOT NULL DEFAULT 'pending',
+	attempts INTEGER NOT NULL DEFAULT 0,
+	last_error TEXT,
+	next_attempt_at DATETIME NOT NULL,
+	sent_at DATETIME,
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL,
+	text_body TEXT
+);
+
+CREATE INDEX idx_email_outbox_status_next ON email_outbox(status, next_attempt_at);
+
+CREATE TABLE digest_deliveries (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL,
+	week_start TEXT NOT NULL,
+	created_at DATETIME NOT NULL,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_digest_deliveries_user_week ON digest_deliveries(user_id, week_start);
+
+CREATE TABLE notifications (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL,
+	type TEXT NOT NULL,
+	title TEXT NOT NULL,
+	body TEXT NOT NULL DEFAULT '',
+	link TEXT,
+	dedupe_key TEXT,
+	read_at DATETIME,
+	created_at DATETIME NOT NULL,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_notifications_user_created ON notifications(user_id, created_at);
+CREATE INDEX idx_notifications_user_read ON notifications(user_id, read_at);
+CREATE UNIQUE INDEX idx_notifications_user_dedupe ON notifications(user_id, dedupe_key);
+
+CREATE TABLE user_totp (
+	user_id INTEGER PRIMARY KEY,
+	secret TEXT NOT NULL,
+	confirmed_at DATETIME,
+	last_used_step INTEGER NOT NULL DEFAULT 0,
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE user_recovery_codes (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL,
+	code_hash TEXT NOT NULL,
+	used_at DATETIME,
+	created_at DATETIME NOT NULL,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_user_recovery_codes_user_id ON user_recovery_codes(user_id);
+
+CREATE TABLE passkeys (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL,
+	credential_id TEXT NOT NULL UNIQUE,
+	public_key TEXT NOT NULL,
+	sign_count INTEGER NOT NULL DEFAULT 0,
+	transports TEXT NOT NULL DEFAULT '',
+	name TEXT NOT NULL,
+	last_used_at DATETIME,
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_passkeys_user_id ON passkeys(user_id);
+
+CREATE TABLE passkey_challenges (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	challenge TEXT NOT NULL UNIQUE,
+	purpose TEXT NOT NULL,
+	user_id INTEGER,
+	expires_at DATETIME NOT NULL,
+	created_at DATETIME NOT NULL,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE user_identities (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL,
+	issuer TEXT NOT NULL,
+	subject TEXT NOT NULL,
+	email TEXT NOT NULL DEFAULT '',
+	last_login_at DATETIME,
+	created_at DATETIME NOT NULL,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+	UNIQUE (issuer, subject)
+);
+
+CREATE INDEX idx_user_identities_user_id ON user_identities(user_id);
+
+CREATE TABLE oidc_login_states (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	state TEXT NOT NULL UNIQUE,
+	nonce TEXT NOT NULL,
+	code_verifier TEXT NOT NULL,
+	expires_at DATETIME NOT NULL,
+	created_at DATETIME NOT NULL
+);
+
+CREATE TABLE api_tokens (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL,
+	name TEXT NOT NULL,
+	prefix TEXT NOT NULL,
+	token_hash TEXT NOT NULL UNIQUE,
+	scopes TEXT NOT NULL,
+	last_used_at DATETIME,
+	expires_at DATETIME,
+	created_at DATETIME NOT NULL,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_api_tokens_user_id ON api_tokens(user_id);
+
+CREATE TABLE login_lockouts (
+	user_id INTEGER PRIMARY KEY,
+	failed_attempts INTEGER NOT NULL DEFAULT 0,
+	lockouts INTEGER NOT NULL DEFAULT 0,
+	locked_until DATETIME,
+	last_failure_at DATETIME NOT NULL,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE jwt_signing_keys (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	kid TEXT NOT NULL UNIQUE,
+	algorithm TEXT NOT NULL,
+	private_key TEXT NOT NULL,
+	created_at DATETIME NOT NULL,
+	activates_at DATETIME NOT NULL
+);
+
+-- actor_id and target_id deliberately have no foreign keys so entries
+-- outlive the users they mention
+CREATE TABLE audit_log (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	action TEXT NOT NULL,
+	actor_id INTEGER,
+	target_type TEXT NOT NULL DEFAULT '',
+	target_id INTEGER,
+	ip_address TEXT NOT NULL DEFAULT '',
+	request_id TEXT NOT NULL DEFAULT '',
+	before_data TEXT,
+	after_data TEXT,
+	created_at DATETIME NOT NULL
+);
+
+CREATE INDEX idx_audit_log_created_at ON audit_log(created_at);
+CREATE INDEX idx_audit_log_action ON audit_log(action, created_at);
+CREATE INDEX idx_audit_log_actor ON audit_log(actor_id, created_at);
+CREATE INDEX idx_audit_log_target ON audit_log(target_type, target_id, created_at);
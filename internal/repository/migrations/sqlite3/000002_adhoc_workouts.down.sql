@@ -0,0 +1,29 @@
+-- Puts back the NOT NULL constraint on user_workouts.workout_id. Ad-hoc
+-- workouts have no template to point at and are deleted.
+
+CREATE TABLE user_workouts_old (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL,
+	workout_id INTEGER NOT NULL,
+	workout_date DATE NOT NULL,
+	workout_type TEXT,
+	total_time INTEGER,
+	notes TEXT,
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL,
+	workout_name TEXT,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+	FOREIGN KEY (workout_id) REFERENCES workouts(id) ON DELETE RESTRICT
+);
+
+INSERT INTO user_workouts_old (id, user_id, workout_id, workout_date, workout_type, total_time, notes, created_at, updated_at, workout_name)
+SELECT id, user_id, workout_id, workout_date, workout_type, total_time, notes, created_at, updated_at, workout_name
+FROM user_workouts
+WHERE workout_id IS NOT NULL;
+
+DROP TABLE user_workouts;
+ALTER TABLE user_workouts_old RENAME TO user_workouts;
+
+CREATE INDEX idx_user_workouts_user_id ON user_workouts(user_id);
+CREATE INDEX idx_user_workouts_workout_date ON user_workouts(workout_date);
+CREATE INDEX idx_user_workouts_user_date ON user_workouts(user_id, workout_date DESC);
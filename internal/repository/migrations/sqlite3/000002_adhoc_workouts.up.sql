@@ -0,0 +1,29 @@
+-- Ad-hoc workouts are logged without a template, so user_workouts.workout_id
+-- has to allow NULL. SQLite can't alter a column's constraints, so the table
+-- is rebuilt.
+
+CREATE TABLE user_workouts_new (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL,
+	workout_id INTEGER,
+	workout_date DATE NOT NULL,
+	workout_type TEXT,
+	total_time INTEGER,
+	notes TEXT,
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL,
+	workout_name TEXT,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+	FOREIGN KEY (workout_id) REFERENCES workouts(id) ON DELETE RESTRICT
+);
+
+INSERT INTO user_workouts_new (id, user_id, workout_id, workout_date, workout_type, total_time, notes, created_at, updated_at, workout_name)
+SELECT id, user_id, workout_id, workout_date, workout_type, total_time, notes, created_at, updated_at, workout_name
+FROM user_workouts;
+
+DROP TABLE user_workouts;
+ALTER TABLE user_workouts_new RENAME TO user_workouts;
+
+CREATE INDEX idx_user_workouts_user_id ON user_workouts(user_id);
+CREATE INDEX idx_user_workouts_workout_date ON user_workouts(workout_date);
+CREATE INDEX idx_user_workouts_user_date ON user_workouts(user_id, workout_date DESC);
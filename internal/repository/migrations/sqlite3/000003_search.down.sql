@@ -0,0 +1,15 @@
+DROP TRIGGER user_workouts_fts_rename;
+DROP TRIGGER user_workouts_fts_delete;
+DROP TRIGGER user_workouts_fts_update;
+DROP TRIGGER user_workouts_fts_insert;
+DROP TABLE user_workouts_fts;
+
+DROP TRIGGER wods_fts_delete;
+DROP TRIGGER wods_fts_update;
+DROP TRIGGER wods_fts_insert;
+DROP TABLE wods_fts;
+
+DROP TRIGGER movements_fts_delete;
+DROP TRIGGER movements_fts_update;
+DROP TRIGGER movements_fts_insert;
+DROP TABLE movements_fts;
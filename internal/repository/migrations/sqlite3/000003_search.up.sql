@@ -0,0 +1,71 @@
+-- Full-text search over movements, WODs and logged workouts. Each FTS4
+-- table holds its own copy of the searched text, keyed by docid = the row's
+-- id, and is kept up to date by triggers. FTS4 is used because FTS5 needs
+-- the sqlite_fts5 build tag.
+
+CREATE VIRTUAL TABLE movements_fts USING fts4(name, description, tokenize=porter);
+
+INSERT INTO movements_fts (docid, name, description)
+SELECT id, name, description FROM movements;
+
+CREATE TRIGGER movements_fts_insert AFTER INSERT ON movements BEGIN
+	INSERT INTO movements_fts (docid, name, description) VALUES (new.id, new.name, new.description);
+END;
+
+CREATE TRIGGER movements_fts_update AFTER UPDATE OF name, description ON movements BEGIN
+	DELETE FROM movements_fts WHERE docid = old.id;
+	INSERT INTO movements_fts (docid, name, description) VALUES (new.id, new.name, new.description);
+END;
+
+CREATE TRIGGER movements_fts_delete AFTER DELETE ON movements BEGIN
+	DELETE FROM movements_fts WHERE docid = old.id;
+END;
+
+CREATE VIRTUAL TABLE wods_fts USING fts4(name, description, notes, tokenize=porter);
+
+INSERT INTO wods_fts (docid, name, description, notes)
+SELECT id, name, description, notes FROM wods;
+
+CREATE TRIGGER wods_fts_insert AFTER INSERT ON wods BEGIN
+	INSERT INTO wods_fts (docid, name, description, notes) VALUES (new.id, new.name, new.description, new.notes);
+END;
+
+CREATE TRIGGER wods_fts_update AFTER UPDATE OF name, description, notes ON wods BEGIN
+	DELETE FROM wods_fts WHERE docid = old.id;
+	INSERT INTO wods_fts (docid, name, description, notes) VALUES (new.id, new.name, new.description, new.notes);
+END;
+
+CREATE TRIGGER wods_fts_delete AFTER DELETE ON wods BEGIN
+	DELETE FROM wods_fts WHERE docid = old.id;
+END;
+
+-- A logged workout is named by its template unless it's ad hoc, so renaming
+-- a template renames the workouts logged from it
+CREATE VIRTUAL TABLE user_workouts_fts USING fts4(name, notes, tokenize=porter);
+
+INSERT INTO user_workouts_fts (docid, name, notes)
+SELECT uw.id, COALESCE(uw.workout_name, w.name), uw.notes
+FROM user_workouts uw
+LEFT JOIN workouts w ON w.id = uw.workout_id;
+
+CREATE TRIGGER user_workouts_fts_insert AFTER INSERT ON user_workouts BEGIN
+	INSERT INTO user_workouts_fts (docid, name, notes)
+	VALUES (new.id, COALESCE(new.workout_name, (SELECT name FROM workouts WHERE id = new.workout_id)), new.notes);
+END;
+
+CREATE TRIGGER user_workouts_fts_update AFTER UPDATE OF workout_id, workout_name, notes ON user_workouts BEGIN
+	DELETE FROM user_workouts_fts WHERE docid = old.id;
+	INSERT INTO user_workouts_fts (docid, name, notes)
+	VALUES (new.id, COALESCE(new.workout_name, (SELECT name FROM workouts WHERE id = new.workout_id)), new.notes);
+END;
+
+CREATE TRIGGER user_workouts_fts_delete AFTER DELETE ON user_workouts BEGIN
+	DELETE FROM user_workouts_fts WHERE docid = old.id;
+END;
+
+CREATE TRIGGER user_workouts_fts_rename AFTER UPDATE OF name ON workouts BEGIN
+	DELETE FROM user_workouts_fts
+	WHERE docid IN (SELECT id FROM user_workouts WHERE workout_id = new.id AND workout_name IS NULL);
+	INSERT INTO user_workouts_fts (docid, name, notes)
+	SELECT id, new.name, notes FROM user_workouts WHERE workout_id = new.id AND workout_name IS NULL;
+END;
@@ -0,0 +1,705 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// migrationFiles holds the SQL migrations, in a directory per dialect
+//
+//go:embed migrations
+var migrationFiles embed.FS
+
+// migrationDialects are the dialects every migration is written for
+var migrationDialects = []Dialect{DialectSQLite, DialectPostgres, DialectMySQL}
+
+const (
+	// baselineVersion is the migration whose schema the legacy migrations
+	// end at
+	baselineVersion = 1
+
+	// migrationLockTimeout is how long a runner waits for another to finish
+	migrationLockTimeout = 5 * time.Minute
+
+	// migrationLockHeartbeat is how often a SQLite runner refreshes its
+	// lock row, and migrationLockStale how old a row must be before other
+	// runners take it as left behind by a runner that crashed
+	migrationLockHeartbeat = 10 * time.Second
+	migrationLockStale     = 6 * migrationLockHeartbeat
+
+	// migrationLockID and migrationLockName name the advisory lock on
+	// PostgreSQL and MySQL
+	migrationLockID   = 7417238502
+	migrationLockName = "actalog_schema_migrations"
+)
+
+var (
+	// ErrMigrationsChanged is returned when the applied migrations no longer
+	// match the migration files
+	ErrMigrationsChanged = errors.New("applied migrations don't match the migration files")
+
+	// ErrLegacyMigrations is returned when reading the migrations of a
+	// database that hasn't been migrated since before the migration files
+	ErrLegacyMigrations = errors.New("database uses the legacy schema_migrations table; run the up migrations first")
+
+	errMigrationsLocked = errors.New("timed out waiting for another migration runner")
+)
+
+var (
+	// migrationFileName matches NNNNNN_name.up.sql and NNNNNN_name.down.sql
+	migrationFileName = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+	// migrationNameSeparators are the runs of characters a new migration's
+	// name can't have, which become underscores
+	migrationNameSeparators = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// Migration is a numbered schema change, read from a pair of SQL files:
+// NNNNNN_name.up.sql applies it and NNNNNN_name.down.sql reverts it
+type Migration struct {
+	Version  int
+	Name     string
+	Up       string
+	Down     string
+	Checksum string // SHA-256 of Up, recorded when the migration is applied
+}
+
+// String returns the migration's file name stem, e.g. 000001_baseline
+func (m Migration) String() string {
+	return fmt.Sprintf("%06d_%s", m.Version, m.Name)
+}
+
+// MigrationStatus is a migration and whether the database has it
+type MigrationStatus struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+	Changed   bool // the up file has been edited since the migration was applied
+	Unknown   bool // applied, but there are no files for it, e.g. from a newer release
+}
+
+// appliedMigration is a row of schema_migrations
+type appliedMigration struct {
+	Version   int
+	Name      string
+	Checksum  string
+	AppliedAt time.Time
+}
+
+// Migrator applies and reverts the SQL migrations for a database's dialect,
+// recording each in schema_migrations with the checksum of its up file.
+// Runners wait for each other: PostgreSQL and MySQL hold an advisory lock
+// while migrating, and SQLite a row in schema_migrations_lock.
+//
+// Each migration runs in a transaction with its schema_migrations row.
+// MySQL commits DDL as it goes, so a migration that fails partway there
+// leaves the statements before the failure applied.
+type Migrator struct {
+	db         *DB
+	migrations []Migration
+	out        io.Writer // where progress is printed
+}
+
+// NewMigrator creates a migrator with the migrations embedded for the
+// connection's dialect
+func NewMigrator(db *sql.DB) (*Migrator, error) {
+	conn := NewDB(db)
+	fsys, err := fs.Sub(migrationFiles, "migrations/"+string(conn.Dialect))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open migrations: %w", err)
+	}
+	return newMigrator(conn, fsys)
+}
+
+// newMigrator creates a migrator with the migrations in fsys
+func newMigrator(db *DB, fsys fs.FS) (*Migrator, error) {
+	migrations, err := loadMigrations(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations, out: os.Stdout}, nil
+}
+
+// loadMigrations reads the migration files in fsys, in version order
+func loadMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	files := make(map[string]bool)
+	for _, entry := range entries {
+		match := migrationFileName.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			return nil, fmt.Errorf("unexpected file in migrations: %s", entry.Name())
+		}
+
+		version, err := strconv.Atoi(match[1])
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("invalid migration version: %s", entry.Name())
+		}
+		data, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		migration := byVersion[version]
+		if migration == nil {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has files named both %s and %s", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(data)
+		} else {
+			migration.Down = string(data)
+		}
+		files[migration.String()+"."+match[3]] = true
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		for _, direction := range []string{"up", "down"} {
+			if !files[migration.String()+"."+direction] {
+				return nil, fmt.Errorf("migration %s has no %s file", migration, direction)
+			}
+		}
+		sum := sha256.Sum256([]byte(migration.Up))
+		migration.Checksum = hex.EncodeToString(sum[:])
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// Up applies every pending migration, oldest first
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.locked(ctx, func(applied []appliedMigration) error {
+		return m.apply(ctx, applied, -1)
+	})
+}
+
+// Down reverts the last n applied migrations, newest first
+func (m *Migrator) Down(ctx context.Context, n int) error {
+	if n <= 0 {
+		return fmt.Errorf("invalid number of migrations to revert: %d", n)
+	}
+	return m.locked(ctx, func(applied []appliedMigration) error {
+		if n > len(applied) {
+			n = len(applied)
+		}
+		return m.revert(ctx, applied[len(applied)-n:])
+	})
+}
+
+// Goto applies or reverts migrations until version is the newest one
+// applied. Version 0 reverts them all.
+func (m *Migrator) Goto(ctx context.Context, version int) error {
+	if version != 0 && m.find(version) == nil {
+		return fmt.Errorf("no migration with version %d", version)
+	}
+	return m.locked(ctx, func(applied []appliedMigration) error {
+		newer := sort.Search(len(applied), func(i int) bool {
+			return applied[i].Version > version
+		})
+		if err := m.revert(ctx, applied[newer:]); err != nil {
+			return err
+		}
+		return m.apply(ctx, applied[:newer], version)
+	})
+}
+
+// Status lists the migrations, and any applied ones without files, in
+// version order
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byVersion := make(map[int]appliedMigration, len(applied))
+	for _, a := range applied {
+		byVersion[a.Version] = a
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := MigrationStatus{Migration: migration}
+		if a, ok := byVersion[migration.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = a.AppliedAt
+			status.Changed = a.Checksum != migration.Checksum
+			delete(byVersion, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	for _, a := range byVersion {
+		statuses = append(statuses, MigrationStatus{
+			Migration: Migration{Version: a.Version, Name: a.Name, Checksum: a.Checksum},
+			Applied:   true,
+			AppliedAt: a.AppliedAt,
+			Unknown:   true,
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+	return statuses, nil
+}
+
+// Verify checks that every applied migration still has its files and that
+// its up file hasn't changed since it was applied
+func (m *Migrator) Verify(ctx context.Context) error {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return err
+	}
+	return m.verify(applied)
+}
+
+// verify checks the applied migrations against the files
+func (m *Migrator) verify(applied []appliedMigration) error {
+	var problems []string
+	for _, a := range applied {
+		migration := m.find(a.Version)
+		switch {
+		case migration == nil:
+			problems = append(problems, fmt.Sprintf("%06d_%s is applied but has no files", a.Version, a.Name))
+		case migration.Checksum != a.Checksum:
+			problems = append(problems, fmt.Sprintf("%s has changed since it was applied", migration))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrMigrationsChanged, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// find returns the migration with a version, or nil
+func (m *Migrator) find(version int) *Migration {
+	for i := range m.migrations {
+		if m.migrations[i].Version == version {
+			return &m.migrations[i]
+		}
+	}
+	return nil
+}
+
+// apply runs the pending migrations up to and including version, or all of
+// them if version is negative
+func (m *Migrator) apply(ctx context.Context, applied []appliedMigration, version int) error {
+	done := make(map[int]bool, len(applied))
+	for _, a := range applied {
+		done[a.Version] = true
+	}
+	for _, migration := range m.migrations {
+		if version >= 0 && migration.Version > version {
+			break
+		}
+		if !done[migration.Version] {
+			if err := m.run(ctx, migration, true); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// revert runs the down files of applied migrations, newest first
+func (m *Migrator) revert(ctx context.Context, applied []appliedMigration) error {
+	for i := len(applied) - 1; i >= 0; i-- {
+		if err := m.run(ctx, *m.find(applied[i].Version), false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// run applies or reverts a migration and records it, in a transaction
+func (m *Migrator) run(ctx context.Context, migration Migration, up bool) error {
+	script, action, direction := migration.Up, "apply", "up"
+	record := `INSERT INTO schema_migrations (version, name, checksum, applied_at) VALUES (?, ?, ?, ?)`
+	args := []interface{}{migration.Version, migration.Name, migration.Checksum, time.Now()}
+	if !up {
+		script, action, direction = migration.Down, "revert", "down"
+		record = `DELETE FROM schema_migrations WHERE version = ?`
+		args = args[:1]
+	}
+	fmt.Fprintf(m.out, "Running %s migration %s\n", direction, migration)
+
+	tx, err := m.db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// The script goes to the driver in one piece; MySQL needs
+	// multiStatements=true in its DSN to take it
+	if hasStatements(script) {
+		if _, err := tx.ExecContext(ctx, script); err != nil {
+			return fmt.Errorf("failed to %s migration %s: %w", action, migration, err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, m.db.Dialect.Rebind(record), args...); err != nil {
+		return fmt.Errorf("failed to record migration %s: %w", migration, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration %s: %w", migration, err)
+	}
+	return nil
+}
+
+// hasStatements reports whether a script has anything but comments in it;
+// MySQL rejects a query without a statement
+func hasStatements(script string) bool {
+	for _, line := range strings.Split(script, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "--") {
+			return true
+		}
+	}
+	return false
+}
+
+// locked calls fn with the applied migrations while holding the migration
+// lock, once schema_migrations is set up and matches the migration files
+func (m *Migrator) locked(ctx context.Context, fn func(applied []appliedMigration) error) error {
+	unlock, err := m.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := m.prepare(ctx); err != nil {
+		return err
+	}
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return err
+	}
+	if err := m.verify(applied); err != nil {
+		return err
+	}
+	return fn(applied)
+}
+
+// lock waits for any other runner to finish and keeps the others out until
+// unlock is called
+func (m *Migrator) lock(ctx context.Context) (unlock func(), err error) {
+	ctx, cancel := context.WithTimeout(ctx, migrationLockTimeout)
+	defer cancel()
+
+	if m.db.Dialect == DialectSQLite {
+		return m.lockTable(ctx)
+	}
+
+	// Advisory locks belong to a session, so the lock is taken and released
+	// on a connection of its own
+	conn, err := m.db.DB.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock migrations: %w", err)
+	}
+	release := func(query string, args ...interface{}) func() {
+		return func() {
+			_, _ = conn.ExecContext(context.Background(), query, args...)
+			conn.Close()
+		}
+	}
+
+	if m.db.Dialect == DialectPostgres {
+		_, err = conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationLockID)
+		unlock = release(`SELECT pg_advisory_unlock($1)`, migrationLockID)
+	} else {
+		var locked sql.NullInt64
+		err = conn.QueryRowContext(ctx, `SELECT GET_LOCK(?, ?)`, migrationLockName, int(migrationLockTimeout.Seconds())).Scan(&locked)
+		if err == nil && locked.Int64 != 1 {
+			err = errMigrationsLocked
+		}
+		unlock = release(`SELECT RELEASE_LOCK(?)`, migrationLockName)
+	}
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to lock migrations: %w", err)
+	}
+	return unlock, nil
+}
+
+// lockTable takes the migration lock on SQLite, which has no advisory locks,
+// by claiming the one row of schema_migrations_lock. The holder refreshes
+// the row's locked_at while it migrates; a row that hasn't been refreshed
+// for migrationLockStale belongs to a runner that crashed and is taken over.
+func (m *Migrator) lockTable(ctx context.Context) (unlock func(), err error) {
+	_, err = m.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations_lock (
+		id INTEGER PRIMARY KEY,
+		locked_at DATETIME NOT NULL
+	)`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create migration lock table: %w", err)
+	}
+
+	for {
+		claimed, err := m.claimLockRow(ctx)
+		var sqliteErr sqlite3.Error
+		switch {
+		case errors.As(err, &sqliteErr) && sqliteErr.Code == sqlite3.ErrBusy, err != nil && ctx.Err() != nil:
+			// Another runner is writing, or the wait is over
+		case err != nil:
+			return nil, fmt.Errorf("failed to lock migrations: %w", err)
+		case claimed:
+			return m.holdLockRow(), nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("failed to lock migrations: %w", errMigrationsLocked)
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+// claimLockRow inserts the lock row, first removing it if it's stale.
+// Returns false if another runner holds the lock.
+func (m *Migrator) claimLockRow(ctx context.Context) (bool, error) {
+	now := time.Now()
+	result, err := m.db.ExecContext(ctx, `DELETE FROM schema_migrations_lock WHERE id = 1 AND locked_at < ?`, now.Add(-migrationLockStale))
+	if err != nil {
+		return false, err
+	}
+	if n, _ := result.RowsAffected(); n == 1 {
+		fmt.Fprintln(m.out, "Removed a migration lock left behind by a runner that stopped")
+	}
+
+	result, err = m.db.ExecContext(ctx, `INSERT OR IGNORE INTO schema_migrations_lock (id, locked_at) VALUES (1, ?)`, now)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n == 1, err
+}
+
+// holdLockRow refreshes the claimed lock row every migrationLockHeartbeat
+// until the returned unlock is called, which deletes the row
+func (m *Migrator) holdLockRow() (unlock func()) {
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(migrationLockHeartbeat)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				_, _ = m.db.ExecContext(context.Background(), `UPDATE schema_migrations_lock SET locked_at = ? WHERE id = 1`, time.Now())
+			}
+		}
+	}()
+
+	return func() {
+		close(stop)
+		<-done
+		_, _ = m.db.ExecContext(context.Background(), `DELETE FROM schema_migrations_lock WHERE id = 1`)
+	}
+}
+
+// prepare creates schema_migrations. A database from before the migration
+// files is first brought up to the baseline by the legacy migrations; its
+// old schema_migrations is kept as schema_migrations_legacy and the
+// baseline is recorded as applied.
+func (m *Migrator) prepare(ctx context.Context) error {
+	legacy, err := m.isLegacy(ctx)
+	if err != nil {
+		return err
+	}
+	if !legacy {
+		return m.createTable(ctx, m.db.DB)
+	}
+
+	fmt.Fprintln(m.out, "Upgrading database from the legacy migrations...")
+	if err := runLegacyMigrations(m.db.DB, string(m.db.Dialect)); err != nil {
+		return err
+	}
+	baseline := m.find(baselineVersion)
+	if baseline == nil {
+		return fmt.Errorf("no baseline migration")
+	}
+
+	tx, err := m.db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `ALTER TABLE schema_migrations RENAME TO schema_migrations_legacy`); err != nil {
+		return fmt.Errorf("failed to rename legacy schema_migrations: %w", err)
+	}
+	if err := m.createTable(ctx, tx); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, m.db.Dialect.Rebind(`INSERT INTO schema_migrations (version, name, checksum, applied_at) VALUES (?, ?, ?, ?)`),
+		baseline.Version, baseline.Name, baseline.Checksum, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to record baseline migration: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// isLegacy reports whether a database predates the migration files: it has
+// tables but no schema_migrations, or the legacy schema_migrations with a
+// description column
+func (m *Migrator) isLegacy(ctx context.Context) (bool, error) {
+	driver := string(m.db.Dialect)
+	exists, err := checkTableExists(m.db.DB, driver, "schema_migrations")
+	if err != nil {
+		return false, fmt.Errorf("failed to check for schema_migrations: %w", err)
+	}
+	if !exists {
+		legacy, err := checkTableExists(m.db.DB, driver, "users")
+		if err != nil {
+			return false, fmt.Errorf("failed to check for users: %w", err)
+		}
+		return legacy, nil
+	}
+
+	legacy, err := checkColumnExists(ctx, m.db, "schema_migrations", "description")
+	if err != nil {
+		return false, fmt.Errorf("failed to check schema_migrations columns: %w", err)
+	}
+	return legacy, nil
+}
+
+// createTable creates schema_migrations if it doesn't exist
+func (m *Migrator) createTable(ctx context.Context, exec interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}) error {
+	var query string
+	switch m.db.Dialect {
+	case DialectPostgres:
+		query = `CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			checksum CHAR(64) NOT NULL,
+			applied_at TIMESTAMP NOT NULL
+		)`
+	case DialectMySQL:
+		query = `CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			checksum CHAR(64) NOT NULL,
+			applied_at DATETIME NOT NULL
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci`
+	default:
+		query = `CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			checksum TEXT NOT NULL,
+			applied_at DATETIME NOT NULL
+		)`
+	}
+
+	if _, err := exec.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+	return nil
+}
+
+// applied returns the applied migrations in version order
+func (m *Migrator) applied(ctx context.Context) ([]appliedMigration, error) {
+	legacy, err := m.isLegacy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if legacy {
+		return nil, ErrLegacyMigrations
+	}
+	exists, err := checkTableExists(m.db.DB, string(m.db.Dialect), "schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for schema_migrations: %w", err)
+	}
+	if !exists {
+		return nil, nil
+	}
+
+	rows, err := m.db.QueryContext(ctx, `SELECT version, name, checksum, applied_at FROM schema_migrations ORDER BY version`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	var applied []appliedMigration
+	for rows.Next() {
+		var a appliedMigration
+		if err := rows.Scan(&a.Version, &a.Name, &a.Checksum, &a.AppliedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan applied migration: %w", err)
+		}
+		applied = append(applied, a)
+	}
+	return applied, rows.Err()
+}
+
+// CreateMigrationFiles writes empty up and down files for a new migration
+// into each dialect's directory under dir, numbered after the newest
+// migration there, and returns their paths
+func CreateMigrationFiles(dir, name string) ([]string, error) {
+	name = strings.Trim(migrationNameSeparators.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return nil, errors.New("migration name is required")
+	}
+
+	version := 1
+	for _, dialect := range migrationDialects {
+		entries, err := os.ReadDir(filepath.Join(dir, string(dialect)))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("failed to read migrations: %w", err)
+		}
+		for _, entry := range entries {
+			if match := migrationFileName.FindStringSubmatch(entry.Name()); match != nil {
+				if v, _ := strconv.Atoi(match[1]); v >= version {
+					version = v + 1
+				}
+			}
+		}
+	}
+
+	migration := Migration{Version: version, Name: name}
+	var paths []string
+	for _, dialect := range migrationDialects {
+		if err := os.MkdirAll(filepath.Join(dir, string(dialect)), 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create migrations directory: %w", err)
+		}
+		for _, direction := range []string{"up", "down"} {
+			path := filepath.Join(dir, string(dialect), migration.String()+"."+direction+".sql")
+			f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create migration file: %w", err)
+			}
+			f.Close()
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
@@ -0,0 +1,329 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestMigrationFiles(t *testing.T) {
+	var want []Migration
+	for _, dialect := range migrationDialects {
+		fsys, err := fs.Sub(migrationFiles, "migrations/"+string(dialect))
+		if err != nil {
+			t.Fatal(err)
+		}
+		migrations, err := loadMigrations(fsys)
+		if err != nil {
+			t.Fatalf("%s: %v", dialect, err)
+		}
+		if len(migrations) == 0 || migrations[0].Version != baselineVersion {
+			t.Fatalf("%s: expected migrations starting at the baseline", dialect)
+		}
+
+		// Every dialect has the same migrations
+		if want == nil {
+			want = migrations
+			continue
+		}
+		if len(migrations) != len(want) {
+			t.Fatalf("%s: expected %d migrations, got %d", dialect, len(want), len(migrations))
+		}
+		for i := range migrations {
+			if migrations[i].String() != want[i].String() {
+				t.Errorf("%s: expected migration %s, got %s", dialect, want[i], migrations[i])
+			}
+		}
+	}
+}
+
+func TestMigrator(t *testing.T) {
+	sqlDB, err := sql.Open("sqlite3", t.TempDir()+"/actalog.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sqlDB.Close()
+	ctx := context.Background()
+
+	files := fstest.MapFS{
+		"000001_create_a.up.sql":   {Data: []byte("CREATE TABLE a (id INTEGER PRIMARY KEY);\nCREATE INDEX idx_a ON a(id);\n")},
+		"000001_create_a.down.sql": {Data: []byte("DROP TABLE a;\n")},
+		"000002_create_b.up.sql":   {Data: []byte("CREATE TABLE b (id INTEGER PRIMARY KEY);\n")},
+		"000002_create_b.down.sql": {Data: []byte("DROP TABLE b;\n")},
+		"000003_noop.up.sql":       {Data: []byte("-- nothing to do on this dialect\n")},
+		"000003_noop.down.sql":     {Data: []byte("")},
+	}
+	migrator, err := newMigrator(NewDB(sqlDB), files)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tables := func() map[string]bool {
+		return map[string]bool{"a": tableExists(t, sqlDB, "a"), "b": tableExists(t, sqlDB, "b")}
+	}
+	applied := func() []int {
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var versions []int
+		for _, status := range statuses {
+			if status.Applied {
+				versions = append(versions, status.Version)
+			}
+		}
+		return versions
+	}
+
+	if err := migrator.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := applied(); len(got) != 3 || !tables()["a"] || !tables()["b"] {
+		t.Fatalf("expected every migration applied, got %v and tables %v", got, tables())
+	}
+	if err := migrator.Up(ctx); err != nil {
+		t.Errorf("expected a second up to do nothing, got %v", err)
+	}
+
+	if err := migrator.Down(ctx, 2); err != nil {
+		t.Fatal(err)
+	}
+	if got := applied(); len(got) != 1 || tables()["b"] {
+		t.Errorf("expected the last two migrations reverted, got %v and tables %v", got, tables())
+	}
+
+	if err := migrator.Goto(ctx, 2); err != nil {
+		t.Fatal(err)
+	}
+	if got := applied(); len(got) != 2 || !tables()["b"] {
+		t.Errorf("expected migrations up to 2 applied, got %v", got)
+	}
+	if err := migrator.Goto(ctx, 0); err != nil {
+		t.Fatal(err)
+	}
+	if got := applied(); len(got) != 0 || tables()["a"] {
+		t.Errorf("expected every migration reverted, got %v and tables %v", got, tables())
+	}
+	if err := migrator.Goto(ctx, 9); err == nil {
+		t.Error("expected an unknown version to be rejected")
+	}
+
+	// An applied migration whose file changed stops further migrations
+	if err := migrator.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.Verify(ctx); err != nil {
+		t.Errorf("expected the applied migrations to verify, got %v", err)
+	}
+	if _, err := sqlDB.Exec(`UPDATE schema_migrations SET checksum = 'edited' WHERE version = 2`); err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.Verify(ctx); !errors.Is(err, ErrMigrationsChanged) {
+		t.Errorf("expected a changed migration to fail verification, got %v", err)
+	}
+	if err := migrator.Down(ctx, 1); !errors.Is(err, ErrMigrationsChanged) {
+		t.Errorf("expected migrating to refuse a changed migration, got %v", err)
+	}
+
+	// So does one applied by a newer release
+	if _, err := sqlDB.Exec(`UPDATE schema_migrations SET checksum = ? WHERE version = 2`, migrator.find(2).Checksum); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sqlDB.Exec(`INSERT INTO schema_migrations (version, name, checksum, applied_at) VALUES (4, 'newer', '', ?)`, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last := statuses[len(statuses)-1]; !last.Unknown || last.Version != 4 {
+		t.Errorf("expected the newer migration in the status, got %+v", last)
+	}
+	if err := migrator.Up(ctx); !errors.Is(err, ErrMigrationsChanged) {
+		t.Errorf("expected migrating to refuse an unknown migration, got %v", err)
+	}
+}
+
+func TestMigratorLock(t *testing.T) {
+	path := t.TempDir() + "/actalog.db"
+	ctx := context.Background()
+
+	// Runners started together each wait their turn
+	var wg sync.WaitGroup
+	errs := make([]error, 3)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sqlDB, err := sql.Open("sqlite3", path)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			defer sqlDB.Close()
+			migrator, err := NewMigrator(sqlDB)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			errs[i] = migrator.Up(ctx)
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			t.Errorf("expected concurrent runners to succeed, got %v", err)
+		}
+	}
+
+	// A runner gives up on a lock that isn't released
+	sqlDB, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sqlDB.Close()
+	if _, err := sqlDB.Exec(`INSERT INTO schema_migrations_lock (id, locked_at) VALUES (1, ?)`, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	migrator, err := NewMigrator(sqlDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	timeout, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer cancel()
+	if err := migrator.Up(timeout); !errors.Is(err, errMigrationsLocked) {
+		t.Errorf("expected the held lock to time out, got %v", err)
+	}
+
+	// A lock left by a runner that stopped refreshing it is taken over
+	if _, err := sqlDB.Exec(`UPDATE schema_migrations_lock SET locked_at = ? WHERE id = 1`, time.Now().Add(-2*migrationLockStale)); err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.Up(ctx); err != nil {
+		t.Errorf("expected the stale lock to be released, got %v", err)
+	}
+	var locks int
+	if err := sqlDB.QueryRow(`SELECT COUNT(*) FROM schema_migrations_lock`).Scan(&locks); err != nil || locks != 0 {
+		t.Errorf("expected the lock to be released after migrating, got %d rows (%v)", locks, err)
+	}
+}
+
+func TestMigratorLegacyUpgrade(t *testing.T) {
+	sqlDB, err := sql.Open("sqlite3", t.TempDir()+"/actalog.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sqlDB.Close()
+	ctx := context.Background()
+
+	// A database created by a release from before the migration files,
+	// with one of its rows
+	legacySchema, err := os.ReadFile("testdata/legacy_schema_sqlite3.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sqlDB.Exec(string(legacySchema)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sqlDB.Exec(`INSERT INTO users (email, password_hash, name, created_at, updated_at) VALUES ('ana@example.com', 'x', 'Ana', ?, ?)`, time.Now(), time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	migrator, err := NewMigrator(sqlDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrator.out = io.Discard
+	if _, err := migrator.Status(ctx); !errors.Is(err, ErrLegacyMigrations) {
+		t.Errorf("expected the legacy schema to be reported, got %v", err)
+	}
+	// Up runs the legacy migrations first, then the files after the baseline
+	if err := migrator.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, status := range statuses {
+		if !status.Applied {
+			t.Errorf("expected %s to be applied", status.Migration)
+		}
+	}
+	var legacyApplied int
+	if err := sqlDB.QueryRow(`SELECT COUNT(*) FROM schema_migrations_legacy`).Scan(&legacyApplied); err != nil || legacyApplied != len(legacyMigrations) {
+		t.Errorf("expected the %d legacy migrations to be kept, got %d (%v)", len(legacyMigrations), legacyApplied, err)
+	}
+
+	// The upgraded database has exactly the schema of a new one
+	scratch, cleanup, err := OpenScratchDB(ctx, sqlDB, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+	expected, err := ExpectedSchema(ctx, scratch, statuses[len(statuses)-1].Version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, err := InspectSchema(ctx, sqlDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, drift := range DiffSchemas(expected, actual) {
+		t.Errorf("drift after the legacy upgrade: %s: %s", drift.Table, drift.Problem)
+	}
+
+	var email string
+	if err := sqlDB.QueryRow(`SELECT email FROM users`).Scan(&email); err != nil || email != "ana@example.com" {
+		t.Errorf("expected the user to survive the upgrade, got %q (%v)", email, err)
+	}
+}
+
+func TestCreateMigrationFiles(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "sqlite3"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "sqlite3", "000007_existing.up.sql"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	paths, err := CreateMigrationFiles(dir, "Add Workout Tags!")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 2*len(migrationDialects) {
+		t.Fatalf("expected up and down files for each dialect, got %v", paths)
+	}
+	for _, dialect := range migrationDialects {
+		for _, direction := range []string{"up", "down"} {
+			path := filepath.Join(dir, string(dialect), "000008_add_workout_tags."+direction+".sql")
+			if _, err := os.Stat(path); err != nil {
+				t.Errorf("expected %s to be created: %v", path, err)
+			}
+		}
+	}
+
+	if _, err := CreateMigrationFiles(dir, "!!"); err == nil {
+		t.Error("expected a name without letters or digits to be rejected")
+	}
+}
+
+// tableExists reports whether a SQLite database has a table
+func tableExists(t *testing.T, db *sql.DB, name string) bool {
+	t.Helper()
+	exists, err := checkTableExists(db, "sqlite3", name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return exists
+}
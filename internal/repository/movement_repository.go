@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"time"
@@ -11,44 +12,39 @@ import (
 // MovementRepository implements domain.MovementRepository
 // Note: After v0.4.0 migration, this accesses the 'movements' table
 type MovementRepository struct {
-	db *sql.DB
+	db *DB
 }
 
 // NewMovementRepository creates a new movement repository
 func NewMovementRepository(db *sql.DB) *MovementRepository {
-	return &MovementRepository{db: db}
+	return &MovementRepository{db: NewDB(db)}
 }
 
 // Create creates a new movement
-func (r *MovementRepository) Create(movement *domain.Movement) error {
+func (r *MovementRepository) Create(ctx context.Context, movement *domain.Movement) error {
 	movement.CreatedAt = time.Now()
 	movement.UpdatedAt = time.Now()
 
 	query := `INSERT INTO movements (name, description, type, is_standard, created_by, created_at, updated_at)
 	          VALUES (?, ?, ?, ?, ?, ?, ?)`
 
-	result, err := r.db.Exec(query, movement.Name, movement.Description, movement.Type, movement.IsStandard, movement.CreatedBy, movement.CreatedAt, movement.UpdatedAt)
+	id, err := r.db.InsertContext(ctx, query, movement.Name, movement.Description, movement.Type, movement.IsStandard, movement.CreatedBy, movement.CreatedAt, movement.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to create movement: %w", err)
 	}
 
-	id, err := result.LastInsertId()
-	if err != nil {
-		return fmt.Errorf("failed to get movement ID: %w", err)
-	}
-
 	movement.ID = id
 	return nil
 }
 
 // GetByID retrieves a movement by ID
-func (r *MovementRepository) GetByID(id int64) (*domain.Movement, error) {
+func (r *MovementRepository) GetByID(ctx context.Context, id int64) (*domain.Movement, error) {
 	query := `SELECT id, name, description, type, is_standard, created_by, created_at, updated_at FROM movements WHERE id = ?`
 
 	movement := &domain.Movement{}
 	var createdBy sql.NullInt64
 
-	err := r.db.QueryRow(query, id).Scan(&movement.ID, &movement.Name, &movement.Description, &movement.Type, &movement.IsStandard, &createdBy, &movement.CreatedAt, &movement.UpdatedAt)
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&movement.ID, &movement.Name, &movement.Description, &movement.Type, &movement.IsStandard, &createdBy, &movement.CreatedAt, &movement.UpdatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -64,13 +60,13 @@ func (r *MovementRepository) GetByID(id int64) (*domain.Movement, error) {
 }
 
 // GetByName retrieves a movement by name
-func (r *MovementRepository) GetByName(name string) (*domain.Movement, error) {
+func (r *MovementRepository) GetByName(ctx context.Context, name string) (*domain.Movement, error) {
 	query := `SELECT id, name, description, type, is_standard, created_by, created_at, updated_at FROM movements WHERE name = ?`
 
 	movement := &domain.Movement{}
 	var createdBy sql.NullInt64
 
-	err := r.db.QueryRow(query, name).Scan(&movement.ID, &movement.Name, &movement.Description, &movement.Type, &movement.IsStandard, &createdBy, &movement.CreatedAt, &movement.UpdatedAt)
+	err := r.db.QueryRowContext(ctx, query, name).Scan(&movement.ID, &movement.Name, &movement.Description, &movement.Type, &movement.IsStandard, &createdBy, &movement.CreatedAt, &movement.UpdatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -86,10 +82,10 @@ func (r *MovementRepository) GetByName(name string) (*domain.Movement, error) {
 }
 
 // ListStandard retrieves all standard movements
-func (r *MovementRepository) ListStandard() ([]*domain.Movement, error) {
-	query := `SELECT id, name, description, type, is_standard, created_by, created_at, updated_at FROM movements WHERE is_standard = 1 ORDER BY name`
+func (r *MovementRepository) ListStandard(ctx context.Context) ([]*domain.Movement, error) {
+	query := `SELECT id, name, description, type, is_standard, created_by, created_at, updated_at FROM movements WHERE is_standard = ? ORDER BY name`
 
-	rows, err := r.db.Query(query)
+	rows, err := r.db.QueryContext(ctx, query, true)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list standard movements: %w", err)
 	}
@@ -99,10 +95,10 @@ func (r *MovementRepository) ListStandard() ([]*domain.Movement, error) {
 }
 
 // ListAll retrieves all movements (both standard and custom)
-func (r *MovementRepository) ListAll() ([]*domain.Movement, error) {
+func (r *MovementRepository) ListAll(ctx context.Context) ([]*domain.Movement, error) {
 	query := `SELECT id, name, description, type, is_standard, created_by, created_at, updated_at FROM movements ORDER BY name`
 
-	rows, err := r.db.Query(query)
+	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list all movements: %w", err)
 	}
@@ -112,10 +108,10 @@ func (r *MovementRepository) ListAll() ([]*domain.Movement, error) {
 }
 
 // ListByUser retrieves movements created by a user
-func (r *MovementRepository) ListByUser(userID int64) ([]*domain.Movement, error) {
+func (r *MovementRepository) ListByUser(ctx context.Context, userID int64) ([]*domain.Movement, error) {
 	query := `SELECT id, name, description, type, is_standard, created_by, created_at, updated_at FROM movements WHERE created_by = ? ORDER BY name`
 
-	rows, err := r.db.Query(query, userID)
+	rows, err := r.db.QueryContext(ctx, query, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list user movements: %w", err)
 	}
@@ -125,14 +121,14 @@ func (r *MovementRepository) ListByUser(userID int64) ([]*domain.Movement, error
 }
 
 // Update updates a movement (only for user-created movements)
-func (r *MovementRepository) Update(movement *domain.Movement) error {
+func (r *MovementRepository) Update(ctx context.Context, movement *domain.Movement) error {
 	movement.UpdatedAt = time.Now()
 
 	query := `UPDATE movements
 	          SET name = ?, description = ?, type = ?, updated_at = ?
-	          WHERE id = ? AND is_standard = 0`
+	          WHERE id = ? AND is_standard = ?`
 
-	result, err := r.db.Exec(query, movement.Name, movement.Description, movement.Type, movement.UpdatedAt, movement.ID)
+	result, err := r.db.ExecContext(ctx, query, movement.Name, movement.Description, movement.Type, movement.UpdatedAt, movement.ID, false)
 	if err != nil {
 		return fmt.Errorf("failed to update movement: %w", err)
 	}
@@ -150,10 +146,10 @@ func (r *MovementRepository) Update(movement *domain.Movement) error {
 }
 
 // Delete deletes a movement (only for user-created movements)
-func (r *MovementRepository) Delete(id int64) error {
-	query := `DELETE FROM movements WHERE id = ? AND is_standard = 0`
+func (r *MovementRepository) Delete(ctx context.Context, id int64) error {
+	query := `DELETE FROM movements WHERE id = ? AND is_standard = ?`
 
-	result, err := r.db.Exec(query, id)
+	result, err := r.db.ExecContext(ctx, query, id, false)
 	if err != nil {
 		return fmt.Errorf("failed to delete movement: %w", err)
 	}
@@ -171,13 +167,13 @@ func (r *MovementRepository) Delete(id int64) error {
 }
 
 // Search searches for movements by name
-func (r *MovementRepository) Search(query string, limit int) ([]*domain.Movement, error) {
+func (r *MovementRepository) Search(ctx context.Context, query string, limit int) ([]*domain.Movement, error) {
 	searchQuery := `SELECT id, name, description, type, is_standard, created_by, created_at, updated_at FROM movements
 	                WHERE name LIKE ?
 	                ORDER BY is_standard DESC, name
 	                LIMIT ?`
 
-	rows, err := r.db.Query(searchQuery, "%"+query+"%", limit)
+	rows, err := r.db.QueryContext(ctx, searchQuery, "%"+query+"%", limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search movements: %w", err)
 	}
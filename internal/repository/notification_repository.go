@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/johnzastrow/actalog/internal/domain"
+)
+
+// NotificationRepository implements domain.NotificationRepository
+type NotificationRepository struct {
+	db *DB
+}
+
+// NewNotificationRepository creates a new notification repository
+func NewNotificationRepository(db *sql.DB) *NotificationRepository {
+	return &NotificationRepository{db: NewDB(db)}
+}
+
+const notificationColumns = `id, user_id, type, title, body, link, dedupe_key, read_at, created_at`
+
+// scanNotification scans a notification row into a domain.Notification
+func scanNotification(scanner interface{ Scan(...interface{}) error }) (*domain.Notification, error) {
+	notification := &domain.Notification{}
+	var link, dedupeKey sql.NullString
+	var readAt sql.NullTime
+	err := scanner.Scan(
+		&notification.ID,
+		&notification.UserID,
+		&notification.Type,
+		&notification.Title,
+		&notification.Body,
+		&link,
+		&dedupeKey,
+		&readAt,
+		&notification.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if link.Valid {
+		notification.Link = &link.String
+	}
+	if dedupeKey.Valid {
+		notification.DedupeKey = &dedupeKey.String
+	}
+	if readAt.Valid {
+		notification.ReadAt = &readAt.Time
+	}
+	return notification, nil
+}
+
+// Create creates a new notification
+func (r *NotificationRepository) Create(ctx context.Context, notification *domain.Notification) error {
+	notification.CreatedAt = time.Now()
+
+	query := `INSERT INTO notifications (user_id, type, title, body, link, dedupe_key, read_at, created_at)
+	          VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+	id, err := r.db.InsertContext(ctx, query,
+		notification.UserID,
+		notification.Type,
+		notification.Title,
+		notification.Body,
+		notification.Link,
+		notification.DedupeKey,
+		notification.ReadAt,
+		notification.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create notification: %w", err)
+	}
+
+	notification.ID = id
+	return nil
+}
+
+// GetByDedupeKey retrieves a user's notification by its deduplication key
+func (r *NotificationRepository) GetByDedupeKey(ctx context.Context, userID int64, dedupeKey string) (*domain.Notification, error) {
+	query := `SELECT ` + notificationColumns + ` FROM notifications WHERE user_id = ? AND dedupe_key = ?`
+
+	notification, err := scanNotification(r.db.QueryRowContext(ctx, query, userID, dedupeKey))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification: %w", err)
+	}
+
+	return notification, nil
+}
+
+// ListByUser retrieves a user's notifications, newest first
+func (r *NotificationRepository) ListByUser(ctx context.Context, userID int64, unreadOnly bool, limit, offset int) ([]*domain.Notification, error) {
+	query := `SELECT ` + notificationColumns + ` FROM notifications WHERE user_id = ?`
+	if unreadOnly {
+		query += ` AND read_at IS NULL`
+	}
+	query += ` ORDER BY created_at DESC, id DESC LIMIT ? OFFSET ?`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notifications: %w", err)
+	}
+	defer rows.Close()
+
+	notifications := []*domain.Notification{}
+	for rows.Next() {
+		notification, err := scanNotification(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan notification: %w", err)
+		}
+		notifications = append(notifications, notification)
+	}
+
+	return notifications, rows.Err()
+}
+
+// CountUnread counts a user's unread notifications
+func (r *NotificationRepository) CountUnread(ctx context.Context, userID int64) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM notifications WHERE user_id = ? AND read_at IS NULL`, userID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count unread notifications: %w", err)
+	}
+	return count, nil
+}
+
+// MarkRead marks one of the user's notifications read. Returns false if it doesn't exist.
+// Marking an already-read notification keeps its original read time.
+func (r *NotificationRepository) MarkRead(ctx context.Context, id, userID int64, at time.Time) (bool, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM notifications WHERE id = ? AND user_id = ?`, id, userID).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("failed to get notification: %w", err)
+	}
+	if count == 0 {
+		return false, nil
+	}
+
+	_, err = r.db.ExecContext(ctx, `UPDATE notifications SET read_at = ? WHERE id = ? AND user_id = ? AND read_at IS NULL`, at, id, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark notification read: %w", err)
+	}
+	return true, nil
+}
+
+// MarkAllRead marks all of the user's unread notifications read and returns how many changed
+func (r *NotificationRepository) MarkAllRead(ctx context.Context, userID int64, at time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `UPDATE notifications SET read_at = ? WHERE user_id = ? AND read_at IS NULL`, at, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark notifications read: %w", err)
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	return count, nil
+}
@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/johnzastrow/actalog/internal/domain"
+)
+
+// OIDCRepository implements domain.OIDCRepository
+type OIDCRepository struct {
+	db *DB
+}
+
+// NewOIDCRepository creates a new OIDC repository
+func NewOIDCRepository(db *sql.DB) *OIDCRepository {
+	return &OIDCRepository{db: NewDB(db)}
+}
+
+const userIdentityColumns = `id, user_id, issuer, subject, email, last_login_at, created_at`
+
+// scanUserIdentity scans an identity row into a domain.UserIdentity
+func scanUserIdentity(scanner interface{ Scan(...interface{}) error }) (*domain.UserIdentity, error) {
+	identity := &domain.UserIdentity{}
+	var lastLoginAt sql.NullTime
+	err := scanner.Scan(
+		&identity.ID,
+		&identity.UserID,
+		&identity.Issuer,
+		&identity.Subject,
+		&identity.Email,
+		&lastLoginAt,
+		&identity.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if lastLoginAt.Valid {
+		identity.LastLoginAt = &lastLoginAt.Time
+	}
+	return identity, nil
+}
+
+// GetIdentity retrieves the identity for a provider account, or nil if it isn't linked
+func (r *OIDCRepository) GetIdentity(ctx context.Context, issuer, subject string) (*domain.UserIdentity, error) {
+	query := `SELECT ` + userIdentityColumns + ` FROM user_identities WHERE issuer = ? AND subject = ?`
+
+	identity, err := scanUserIdentity(r.db.QueryRowContext(ctx, query, issuer, subject))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user identity: %w", err)
+	}
+	return identity, nil
+}
+
+// CreateIdentity links a provider account to a user
+func (r *OIDCRepository) CreateIdentity(ctx context.Context, identity *domain.UserIdentity) error {
+	identity.CreatedAt = time.Now()
+
+	query := `INSERT INTO user_identities (user_id, issuer, subject, email, last_login_at, created_at) VALUES (?, ?, ?, ?, ?, ?)`
+	id, err := r.db.InsertContext(ctx, query,
+		identity.UserID,
+		identity.Issuer,
+		identity.Subject,
+		identity.Email,
+		identity.LastLoginAt,
+		identity.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create user identity: %w", err)
+	}
+
+	identity.ID = id
+	return nil
+}
+
+// ListIdentitiesByUser retrieves the provider accounts linked to a user
+func (r *OIDCRepository) ListIdentitiesByUser(ctx context.Context, userID int64) ([]*domain.UserIdentity, error) {
+	query := `SELECT ` + userIdentityColumns + ` FROM user_identities WHERE user_id = ? ORDER BY created_at, id`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user identities: %w", err)
+	}
+	defer rows.Close()
+
+	identities := []*domain.UserIdentity{}
+	for rows.Next() {
+		identity, err := scanUserIdentity(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user identity: %w", err)
+		}
+		identities = append(identities, identity)
+	}
+
+	return identities, rows.Err()
+}
+
+// RecordIdentityLogin stores the time of a login through the identity
+func (r *OIDCRepository) RecordIdentityLogin(ctx context.Context, id int64, at time.Time) error {
+	if _, err := r.db.ExecContext(ctx, `UPDATE user_identities SET last_login_at = ? WHERE id = ?`, at, id); err != nil {
+		return fmt.Errorf("failed to record identity login: %w", err)
+	}
+	return nil
+}
+
+// CreateLoginState stores an outstanding login
+func (r *OIDCRepository) CreateLoginState(ctx context.Context, state *domain.OIDCLoginState) error {
+	state.CreatedAt = time.Now()
+
+	query := `INSERT INTO oidc_login_states (state, nonce, code_verifier, expires_at, created_at) VALUES (?, ?, ?, ?, ?)`
+	id, err := r.db.InsertContext(ctx, query, state.State, state.Nonce, state.CodeVerifier, state.ExpiresAt, state.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create OIDC login state: %w", err)
+	}
+
+	state.ID = id
+	return nil
+}
+
+// ConsumeLoginState deletes and returns an unexpired login state, or nil if
+// there is none. The delete decides the winner when the same state is
+// submitted twice concurrently.
+func (r *OIDCRepository) ConsumeLoginState(ctx context.Context, value string, now time.Time) (*domain.OIDCLoginState, error) {
+	query := `SELECT id, state, nonce, code_verifier, expires_at, created_at FROM oidc_login_states WHERE state = ?`
+
+	state := &domain.OIDCLoginState{}
+	err := r.db.QueryRowContext(ctx, query, value).Scan(
+		&state.ID,
+		&state.State,
+		&state.Nonce,
+		&state.CodeVerifier,
+		&state.ExpiresAt,
+		&state.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get OIDC login state: %w", err)
+	}
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM oidc_login_states WHERE id = ?`, state.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete OIDC login state: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 || !state.ExpiresAt.After(now) {
+		return nil, nil
+	}
+
+	return state, nil
+}
+
+// DeleteExpiredLoginStates removes login states that expired before the given time
+func (r *OIDCRepository) DeleteExpiredLoginStates(ctx context.Context, before time.Time) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM oidc_login_states WHERE expires_at < ?`, before); err != nil {
+		return fmt.Errorf("failed to delete expired OIDC login states: %w", err)
+	}
+	return nil
+}
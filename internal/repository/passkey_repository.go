@@ -0,0 +1,216 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/johnzastrow/actalog/internal/domain"
+)
+
+// PasskeyRepository implements domain.PasskeyRepository
+type PasskeyRepository struct {
+	db *DB
+}
+
+// NewPasskeyRepository creates a new passkey repository
+func NewPasskeyRepository(db *sql.DB) *PasskeyRepository {
+	return &PasskeyRepository{db: NewDB(db)}
+}
+
+const passkeyColumns = `id, user_id, credential_id, public_key, sign_count, transports, name, last_used_at, created_at, updated_at`
+
+// Create registers a new passkey
+func (r *PasskeyRepository) Create(ctx context.Context, passkey *domain.Passkey) error {
+	now := time.Now()
+	passkey.CreatedAt = now
+	passkey.UpdatedAt = now
+
+	query := `INSERT INTO passkeys (user_id, credential_id, public_key, sign_count, transports, name, created_at, updated_at)
+	          VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+	id, err := r.db.InsertContext(ctx, query,
+		passkey.UserID,
+		passkey.CredentialID,
+		passkey.PublicKey,
+		passkey.SignCount,
+		strings.Join(passkey.Transports, ","),
+		passkey.Name,
+		passkey.CreatedAt,
+		passkey.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create passkey: %w", err)
+	}
+
+	passkey.ID = id
+	return nil
+}
+
+// GetByCredentialID retrieves a passkey by its WebAuthn credential ID
+func (r *PasskeyRepository) GetByCredentialID(ctx context.Context, credentialID string) (*domain.Passkey, error) {
+	query := `SELECT ` + passkeyColumns + ` FROM passkeys WHERE credential_id = ?`
+
+	passkey, err := scanPasskey(r.db.QueryRowContext(ctx, query, credentialID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get passkey: %w", err)
+	}
+	return passkey, nil
+}
+
+// ListByUser retrieves a user's passkeys, oldest first
+func (r *PasskeyRepository) ListByUser(ctx context.Context, userID int64) ([]*domain.Passkey, error) {
+	query := `SELECT ` + passkeyColumns + ` FROM passkeys WHERE user_id = ? ORDER BY created_at, id`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list passkeys: %w", err)
+	}
+	defer rows.Close()
+
+	passkeys := []*domain.Passkey{}
+	for rows.Next() {
+		passkey, err := scanPasskey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan passkey: %w", err)
+		}
+		passkeys = append(passkeys, passkey)
+	}
+
+	return passkeys, rows.Err()
+}
+
+// Rename changes a passkey's name. Returns false if the user has no such passkey.
+func (r *PasskeyRepository) Rename(ctx context.Context, id, userID int64, name string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `UPDATE passkeys SET name = ?, updated_at = ? WHERE id = ? AND user_id = ?`, name, time.Now(), id, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to rename passkey: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rows > 0, nil
+}
+
+// RecordUse stores the new signature counter and last-used time
+func (r *PasskeyRepository) RecordUse(ctx context.Context, id int64, signCount uint32, usedAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE passkeys SET sign_count = ?, last_used_at = ?, updated_at = ? WHERE id = ?`, signCount, usedAt, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("failed to record passkey use: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a passkey. Returns false if the user has no such passkey.
+func (r *PasskeyRepository) Delete(ctx context.Context, id, userID int64) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM passkeys WHERE id = ? AND user_id = ?`, id, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete passkey: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rows > 0, nil
+}
+
+// CreateChallenge stores an outstanding WebAuthn challenge
+func (r *PasskeyRepository) CreateChallenge(ctx context.Context, challenge *domain.PasskeyChallenge) error {
+	challenge.CreatedAt = time.Now()
+
+	query := `INSERT INTO passkey_challenges (challenge, purpose, user_id, expires_at, created_at) VALUES (?, ?, ?, ?, ?)`
+	id, err := r.db.InsertContext(ctx, query, challenge.Challenge, challenge.Purpose, challenge.UserID, challenge.ExpiresAt, challenge.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create passkey challenge: %w", err)
+	}
+
+	challenge.ID = id
+	return nil
+}
+
+// ConsumeChallenge deletes and returns an unexpired challenge for the purpose,
+// or nil if there is none. The delete decides the winner when the same
+// challenge is submitted twice concurrently.
+func (r *PasskeyRepository) ConsumeChallenge(ctx context.Context, value, purpose string, now time.Time) (*domain.PasskeyChallenge, error) {
+	query := `SELECT id, challenge, purpose, user_id, expires_at, created_at FROM passkey_challenges WHERE challenge = ? AND purpose = ?`
+
+	challenge := &domain.PasskeyChallenge{}
+	var userID sql.NullInt64
+	err := r.db.QueryRowContext(ctx, query, value, purpose).Scan(
+		&challenge.ID,
+		&challenge.Challenge,
+		&challenge.Purpose,
+		&userID,
+		&challenge.ExpiresAt,
+		&challenge.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get passkey challenge: %w", err)
+	}
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM passkey_challenges WHERE id = ?`, challenge.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete passkey challenge: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 || !challenge.ExpiresAt.After(now) {
+		return nil, nil
+	}
+
+	if userID.Valid {
+		challenge.UserID = &userID.Int64
+	}
+	return challenge, nil
+}
+
+// DeleteExpiredChallenges removes challenges that expired before the given time
+func (r *PasskeyRepository) DeleteExpiredChallenges(ctx context.Context, before time.Time) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM passkey_challenges WHERE expires_at < ?`, before); err != nil {
+		return fmt.Errorf("failed to delete expired passkey challenges: %w", err)
+	}
+	return nil
+}
+
+// scanPasskey scans a passkey row selected with passkeyColumns
+func scanPasskey(scanner interface{ Scan(...interface{}) error }) (*domain.Passkey, error) {
+	passkey := &domain.Passkey{}
+	var transports string
+	var lastUsedAt sql.NullTime
+	err := scanner.Scan(
+		&passkey.ID,
+		&passkey.UserID,
+		&passkey.CredentialID,
+		&passkey.PublicKey,
+		&passkey.SignCount,
+		&transports,
+		&passkey.Name,
+		&lastUsedAt,
+		&passkey.CreatedAt,
+		&passkey.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if transports != "" {
+		passkey.Transports = strings.Split(transports, ",")
+	}
+	if lastUsedAt.Valid {
+		passkey.LastUsedAt = &lastUsedAt.Time
+	}
+	return passkey, nil
+}
@@ -1,68 +1,103 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"time"
 
 	"github.com/johnzastrow/actalog/internal/domain"
 )
 
 // SQLiteRefreshTokenRepository implements RefreshTokenRepository for SQLite
 type SQLiteRefreshTokenRepository struct {
-	db *sql.DB
+	db *DB
 }
 
 // NewSQLiteRefreshTokenRepository creates a new SQLite refresh token repository
 func NewSQLiteRefreshTokenRepository(db *sql.DB) domain.RefreshTokenRepository {
-	return &SQLiteRefreshTokenRepository{db: db}
+	return &SQLiteRefreshTokenRepository{db: NewDB(db)}
+}
+
+const refreshTokenColumns = `id, user_id, family_id, token, expires_at, created_at, revoked_at, device_info, ip_address, signed_in_at`
+
+// execer is satisfied by *DB and *Tx
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	InsertContext(ctx context.Context, query string, args ...interface{}) (int64, error)
 }
 
 // Create creates a new refresh token
-func (r *SQLiteRefreshTokenRepository) Create(token *domain.RefreshToken) error {
+func (r *SQLiteRefreshTokenRepository) Create(ctx context.Context, token *domain.RefreshToken) error {
+	return createRefreshToken(ctx, r.db, token)
+}
+
+func createRefreshToken(ctx context.Context, db execer, token *domain.RefreshToken) error {
+	if token.SignedInAt.IsZero() {
+		token.SignedInAt = token.CreatedAt
+	}
+
 	query := `
-		INSERT INTO refresh_tokens (user_id, token, expires_at, created_at, device_info)
-		VALUES (?, ?, ?, ?, ?)
+		INSERT INTO refresh_tokens (user_id, family_id, token, expires_at, created_at, device_info, ip_address, signed_in_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
-	result, err := r.db.Exec(query,
+	var familyID sql.NullInt64
+	if token.FamilyID != 0 {
+		familyID = sql.NullInt64{Int64: token.FamilyID, Valid: true}
+	}
+
+	id, err := db.InsertContext(ctx, query,
 		token.UserID,
+		familyID,
 		token.Token,
 		token.ExpiresAt,
 		token.CreatedAt,
 		token.DeviceInfo,
+		token.IPAddress,
+		token.SignedInAt,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create refresh token: %w", err)
 	}
 
-	id, err := result.LastInsertId()
-	if err != nil {
-		return fmt.Errorf("failed to get last insert id: %w", err)
-	}
-
 	token.ID = id
+
+	// The first token of a login starts a new family named after itself
+	if token.FamilyID == 0 {
+		if _, err := db.ExecContext(ctx, `UPDATE refresh_tokens SET family_id = ? WHERE id = ?`, id, id); err != nil {
+			return fmt.Errorf("failed to set refresh token family: %w", err)
+		}
+		token.FamilyID = id
+	}
 	return nil
 }
 
 // GetByToken retrieves a refresh token by its token string
-func (r *SQLiteRefreshTokenRepository) GetByToken(tokenStr string) (*domain.RefreshToken, error) {
+func (r *SQLiteRefreshTokenRepository) GetByToken(ctx context.Context, tokenStr string) (*domain.RefreshToken, error) {
 	query := `
-		SELECT id, user_id, token, expires_at, created_at, revoked_at, device_info
+		SELECT ` + refreshTokenColumns + `
 		FROM refresh_tokens
-		WHERE token = ? AND revoked_at IS NULL AND expires_at > datetime('now')
+		WHERE token = ? AND revoked_at IS NULL AND expires_at > ?
 	`
 
-	token := &domain.RefreshToken{}
-	err := r.db.QueryRow(query, tokenStr).Scan(
-		&token.ID,
-		&token.UserID,
-		&token.Token,
-		&token.ExpiresAt,
-		&token.CreatedAt,
-		&token.RevokedAt,
-		&token.DeviceInfo,
-	)
+	token, err := scanRefreshToken(r.db.QueryRowContext(ctx, query, tokenStr, time.Now()))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get refresh token: %w", err)
+	}
+
+	return token, nil
+}
+
+// GetByTokenIncludingRevoked retrieves a refresh token by its token string,
+// whether or not it has been revoked or has expired
+func (r *SQLiteRefreshTokenRepository) GetByTokenIncludingRevoked(ctx context.Context, tokenStr string) (*domain.RefreshToken, error) {
+	query := `SELECT ` + refreshTokenColumns + ` FROM refresh_tokens WHERE token = ?`
 
+	token, err := scanRefreshToken(r.db.QueryRowContext(ctx, query, tokenStr))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -74,15 +109,15 @@ func (r *SQLiteRefreshTokenRepository) GetByToken(tokenStr string) (*domain.Refr
 }
 
 // GetByUserID retrieves all refresh tokens for a user
-func (r *SQLiteRefreshTokenRepository) GetByUserID(userID int64) ([]*domain.RefreshToken, error) {
+func (r *SQLiteRefreshTokenRepository) GetByUserID(ctx context.Context, userID int64) ([]*domain.RefreshToken, error) {
 	query := `
-		SELECT id, user_id, token, expires_at, created_at, revoked_at, device_info
+		SELECT ` + refreshTokenColumns + `
 		FROM refresh_tokens
 		WHERE user_id = ? AND revoked_at IS NULL
 		ORDER BY created_at DESC
 	`
 
-	rows, err := r.db.Query(query, userID)
+	rows, err := r.db.QueryContext(ctx, query, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query refresh tokens: %w", err)
 	}
@@ -90,34 +125,56 @@ func (r *SQLiteRefreshTokenRepository) GetByUserID(userID int64) ([]*domain.Refr
 
 	var tokens []*domain.RefreshToken
 	for rows.Next() {
-		token := &domain.RefreshToken{}
-		err := rows.Scan(
-			&token.ID,
-			&token.UserID,
-			&token.Token,
-			&token.ExpiresAt,
-			&token.CreatedAt,
-			&token.RevokedAt,
-			&token.DeviceInfo,
-		)
+		token, err := scanRefreshToken(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan refresh token: %w", err)
 		}
 		tokens = append(tokens, token)
 	}
 
-	return tokens, nil
+	return tokens, rows.Err()
+}
+
+// Rotate revokes old and stores next in the same transaction. Returns false,
+// storing nothing, if old was already revoked (e.g. by a concurrent rotation).
+func (r *SQLiteRefreshTokenRepository) Rotate(ctx context.Context, old, next *domain.RefreshToken) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `UPDATE refresh_tokens SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL`, time.Now(), old.ID)
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke refresh token: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return false, nil
+	}
+
+	if err := createRefreshToken(ctx, tx, next); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit refresh token rotation: %w", err)
+	}
+	return true, nil
 }
 
 // Revoke revokes a specific refresh token
-func (r *SQLiteRefreshTokenRepository) Revoke(tokenID int64) error {
+func (r *SQLiteRefreshTokenRepository) Revoke(ctx context.Context, tokenID int64) error {
 	query := `
 		UPDATE refresh_tokens
-		SET revoked_at = datetime('now')
+		SET revoked_at = ?
 		WHERE id = ?
 	`
 
-	_, err := r.db.Exec(query, tokenID)
+	_, err := r.db.ExecContext(ctx, query, time.Now(), tokenID)
 	if err != nil {
 		return fmt.Errorf("failed to revoke refresh token: %w", err)
 	}
@@ -125,15 +182,36 @@ func (r *SQLiteRefreshTokenRepository) Revoke(tokenID int64) error {
 	return nil
 }
 
+// RevokeFamily revokes a user's active tokens in a family. Returns false if
+// there were none.
+func (r *SQLiteRefreshTokenRepository) RevokeFamily(ctx context.Context, familyID, userID int64) (bool, error) {
+	query := `
+		UPDATE refresh_tokens
+		SET revoked_at = ?
+		WHERE family_id = ? AND user_id = ? AND revoked_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, time.Now(), familyID, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke refresh token family: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rows > 0, nil
+}
+
 // RevokeAllForUser revokes all refresh tokens for a user
-func (r *SQLiteRefreshTokenRepository) RevokeAllForUser(userID int64) error {
+func (r *SQLiteRefreshTokenRepository) RevokeAllForUser(ctx context.Context, userID int64) error {
 	query := `
 		UPDATE refresh_tokens
-		SET revoked_at = datetime('now')
+		SET revoked_at = ?
 		WHERE user_id = ? AND revoked_at IS NULL
 	`
 
-	_, err := r.db.Exec(query, userID)
+	_, err := r.db.ExecContext(ctx, query, time.Now(), userID)
 	if err != nil {
 		return fmt.Errorf("failed to revoke all refresh tokens: %w", err)
 	}
@@ -142,13 +220,13 @@ func (r *SQLiteRefreshTokenRepository) RevokeAllForUser(userID int64) error {
 }
 
 // DeleteExpired deletes all expired refresh tokens
-func (r *SQLiteRefreshTokenRepository) DeleteExpired() error {
+func (r *SQLiteRefreshTokenRepository) DeleteExpired(ctx context.Context) error {
 	query := `
 		DELETE FROM refresh_tokens
-		WHERE expires_at < datetime('now')
+		WHERE expires_at < ?
 	`
 
-	_, err := r.db.Exec(query)
+	_, err := r.db.ExecContext(ctx, query, time.Now())
 	if err != nil {
 		return fmt.Errorf("failed to delete expired refresh tokens: %w", err)
 	}
@@ -157,13 +235,48 @@ func (r *SQLiteRefreshTokenRepository) DeleteExpired() error {
 }
 
 // Delete deletes a specific refresh token
-func (r *SQLiteRefreshTokenRepository) Delete(tokenID int64) error {
+func (r *SQLiteRefreshTokenRepository) Delete(ctx context.Context, tokenID int64) error {
 	query := `DELETE FROM refresh_tokens WHERE id = ?`
 
-	_, err := r.db.Exec(query, tokenID)
+	_, err := r.db.ExecContext(ctx, query, tokenID)
 	if err != nil {
 		return fmt.Errorf("failed to delete refresh token: %w", err)
 	}
 
 	return nil
 }
+
+// scanRefreshToken scans a refresh token row selected with refreshTokenColumns
+func scanRefreshToken(scanner interface{ Scan(...interface{}) error }) (*domain.RefreshToken, error) {
+	token := &domain.RefreshToken{}
+	var familyID sql.NullInt64
+	var deviceInfo, ipAddress sql.NullString
+	var signedInAt sql.NullTime
+	err := scanner.Scan(
+		&token.ID,
+		&token.UserID,
+		&familyID,
+		&token.Token,
+		&token.ExpiresAt,
+		&token.CreatedAt,
+		&token.RevokedAt,
+		&deviceInfo,
+		&ipAddress,
+		&signedInAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	token.FamilyID = token.ID
+	if familyID.Valid {
+		token.FamilyID = familyID.Int64
+	}
+	token.DeviceInfo = deviceInfo.String
+	token.IPAddress = ipAddress.String
+	token.SignedInAt = token.CreatedAt
+	if signedInAt.Valid {
+		token.SignedInAt = signedInAt.Time
+	}
+	return token, nil
+}
@@ -0,0 +1,653 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// schemaBookkeepingTables are the migrator's own tables, which the schema
+// check leaves out
+var schemaBookkeepingTables = map[string]bool{
+	"schema_migrations":        true,
+	"schema_migrations_legacy": true,
+	"schema_migrations_lock":   true,
+}
+
+// Schema is the structure of a database's tables, as read back from the
+// database itself
+type Schema struct {
+	Tables map[string]*TableSchema
+}
+
+// TableNames returns the names of the tables, sorted
+func (s *Schema) TableNames() []string {
+	names := make([]string, 0, len(s.Tables))
+	for name := range s.Tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// TableSchema is a table's columns, indexes and foreign keys. Unique
+// constraints are listed with the indexes that enforce them.
+type TableSchema struct {
+	Name        string
+	Columns     map[string]ColumnSchema
+	Indexes     []IndexSchema
+	ForeignKeys []ForeignKeySchema
+}
+
+// ColumnSchema is a column as the database reports it. Type and Default are
+// in the database's own spelling, so schemas only compare within a dialect.
+type ColumnSchema struct {
+	Name       string
+	Type       string
+	NotNull    bool
+	Default    sql.NullString
+	PrimaryKey bool
+}
+
+// String describes the column, e.g. VARCHAR(50) NOT NULL DEFAULT 'x'
+func (c ColumnSchema) String() string {
+	s := c.Type
+	if c.PrimaryKey {
+		s += " PRIMARY KEY"
+	}
+	if c.NotNull {
+		s += " NOT NULL"
+	}
+	if c.Default.Valid {
+		s += " DEFAULT " + c.Default.String
+	}
+	return s
+}
+
+// IndexSchema is an index other than the primary key's
+type IndexSchema struct {
+	Name    string
+	Columns []string
+	Unique  bool
+}
+
+// signature identifies the index by what it covers rather than its name,
+// which the database picks for unique constraints
+func (i IndexSchema) signature() string {
+	kind := "index"
+	if i.Unique {
+		kind = "unique index"
+	}
+	return fmt.Sprintf("%s (%s)", kind, strings.Join(i.Columns, ", "))
+}
+
+// ForeignKeySchema is a foreign key constraint
+type ForeignKeySchema struct {
+	Columns    []string
+	RefTable   string
+	RefColumns []string
+	OnDelete   string
+}
+
+// signature describes the foreign key, e.g. (user_id) REFERENCES users (id)
+// ON DELETE CASCADE
+func (f ForeignKeySchema) signature() string {
+	return fmt.Sprintf("foreign key (%s) REFERENCES %s (%s) ON DELETE %s",
+		strings.Join(f.Columns, ", "), f.RefTable, strings.Join(f.RefColumns, ", "), f.OnDelete)
+}
+
+// SchemaDrift is a difference between a table and what the migrations
+// expect of it
+type SchemaDrift struct {
+	Table   string
+	Problem string
+}
+
+// InspectSchema reads the tables of a database, apart from the migrator's
+// own. PostgreSQL is read from the current schema and MySQL from the
+// current database.
+func InspectSchema(ctx context.Context, db *sql.DB) (*Schema, error) {
+	conn := NewDB(db)
+	inspector := schemaInspectors[conn.Dialect]
+
+	names, err := queryStrings(ctx, conn, inspector.tables)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+
+	schema := &Schema{Tables: make(map[string]*TableSchema, len(names))}
+	for _, name := range names {
+		if schemaBookkeepingTables[name] {
+			continue
+		}
+		table := &TableSchema{Name: name}
+		if table.Columns, err = inspector.columns(ctx, conn, name); err != nil {
+			return nil, fmt.Errorf("failed to read columns of %s: %w", name, err)
+		}
+		if table.Indexes, err = inspector.indexes(ctx, conn, name); err != nil {
+			return nil, fmt.Errorf("failed to read indexes of %s: %w", name, err)
+		}
+		if table.ForeignKeys, err = inspector.foreignKeys(ctx, conn, name); err != nil {
+			return nil, fmt.Errorf("failed to read foreign keys of %s: %w", name, err)
+		}
+		schema.Tables[name] = table
+	}
+	return schema, nil
+}
+
+// ExpectedSchema runs the migrations up to version in an empty scratch
+// database and reads back the schema they leave
+func ExpectedSchema(ctx context.Context, scratch *sql.DB, version int) (*Schema, error) {
+	migrator, err := NewMigrator(scratch)
+	if err != nil {
+		return nil, err
+	}
+	migrator.out = io.Discard
+	if err := migrator.Goto(ctx, version); err != nil {
+		return nil, fmt.Errorf("failed to migrate scratch database: %w", err)
+	}
+	return InspectSchema(ctx, scratch)
+}
+
+// OpenScratchDB opens an empty database alongside db, for ExpectedSchema,
+// and returns a function that closes and removes it. dsn is db's data
+// source name. SQLite gets a temporary file, PostgreSQL a temporary schema
+// in the same database and MySQL a temporary database, which needs the
+// CREATE and DROP privileges.
+func OpenScratchDB(ctx context.Context, db *sql.DB, dsn string) (*sql.DB, func(), error) {
+	dialect := DialectOf(db)
+	name := fmt.Sprintf("actalog_check_%d", time.Now().UnixNano())
+
+	switch dialect {
+	case DialectPostgres:
+		if _, err := db.ExecContext(ctx, "CREATE SCHEMA "+name); err != nil {
+			return nil, nil, fmt.Errorf("failed to create scratch schema: %w", err)
+		}
+		// lib/pq sends settings it doesn't know to the server, so each
+		// connection starts out in the scratch schema
+		if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+			u, err := url.Parse(dsn)
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid postgres DSN: %w", err)
+			}
+			query := u.Query()
+			query.Set("search_path", name)
+			u.RawQuery = query.Encode()
+			dsn = u.String()
+		} else {
+			dsn += " search_path=" + name
+		}
+		scratch, err := sql.Open("postgres", dsn)
+		cleanup := func() {
+			if scratch != nil {
+				scratch.Close()
+			}
+			_, _ = db.ExecContext(context.Background(), "DROP SCHEMA IF EXISTS "+name+" CASCADE")
+		}
+		if err != nil {
+			cleanup()
+			return nil, nil, fmt.Errorf("failed to open scratch schema: %w", err)
+		}
+		return scratch, cleanup, nil
+
+	case DialectMySQL:
+		cfg, err := mysql.ParseDSN(dsn)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid mysql DSN: %w", err)
+		}
+		if _, err := db.ExecContext(ctx, "CREATE DATABASE `"+name+"` CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci"); err != nil {
+			return nil, nil, fmt.Errorf("failed to create scratch database: %w", err)
+		}
+		cfg.DBName = name
+		scratch, err := sql.Open("mysql", cfg.FormatDSN())
+		cleanup := func() {
+			if scratch != nil {
+				scratch.Close()
+			}
+			_, _ = db.ExecContext(context.Background(), "DROP DATABASE IF EXISTS `"+name+"`")
+		}
+		if err != nil {
+			cleanup()
+			return nil, nil, fmt.Errorf("failed to open scratch database: %w", err)
+		}
+		return scratch, cleanup, nil
+
+	default:
+		dir, err := os.MkdirTemp("", name)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create scratch database: %w", err)
+		}
+		scratch, err := sql.Open("sqlite3", filepath.Join(dir, "scratch.db"))
+		cleanup := func() {
+			if scratch != nil {
+				scratch.Close()
+			}
+			os.RemoveAll(dir)
+		}
+		if err != nil {
+			cleanup()
+			return nil, nil, fmt.Errorf("failed to open scratch database: %w", err)
+		}
+		return scratch, cleanup, nil
+	}
+}
+
+// DiffSchemas lists how actual differs from expected, by table. Column
+// order and index names don't count.
+func DiffSchemas(expected, actual *Schema) []SchemaDrift {
+	var drift []SchemaDrift
+	report := func(table, format string, args ...interface{}) {
+		drift = append(drift, SchemaDrift{Table: table, Problem: fmt.Sprintf(format, args...)})
+	}
+
+	for _, name := range expected.TableNames() {
+		want := expected.Tables[name]
+		got, ok := actual.Tables[name]
+		if !ok {
+			report(name, "missing table")
+			continue
+		}
+
+		for _, column := range sortedColumns(want) {
+			have, ok := got.Columns[column.Name]
+			switch {
+			case !ok:
+				report(name, "missing column %s %s", column.Name, column)
+			case !strings.EqualFold(have.Type, column.Type):
+				report(name, "column %s has type %s, expected %s", column.Name, have.Type, column.Type)
+			case have.NotNull != column.NotNull:
+				report(name, "column %s is %s, expected %s", column.Name, nullability(have.NotNull), nullability(column.NotNull))
+			case have.Default != column.Default:
+				report(name, "column %s has default %s, expected %s", column.Name, describeDefault(have.Default), describeDefault(column.Default))
+			case have.PrimaryKey != column.PrimaryKey:
+				report(name, "column %s is %sin the primary key, expected %s", column.Name, map[bool]string{false: "not "}[have.PrimaryKey], column)
+			}
+		}
+		for _, column := range sortedColumns(got) {
+			if _, ok := want.Columns[column.Name]; !ok {
+				report(name, "unexpected column %s %s", column.Name, column)
+			}
+		}
+
+		wantIndexes, gotIndexes := indexSignatures(want), indexSignatures(got)
+		for _, signature := range sortedKeys(wantIndexes) {
+			if _, ok := gotIndexes[signature]; !ok {
+				report(name, "missing %s %s", signature, wantIndexes[signature])
+			}
+		}
+		for _, signature := range sortedKeys(gotIndexes) {
+			if _, ok := wantIndexes[signature]; !ok {
+				report(name, "unexpected %s %s", signature, gotIndexes[signature])
+			}
+		}
+
+		wantKeys, gotKeys := foreignKeySignatures(want), foreignKeySignatures(got)
+		for _, signature := range sortedKeys(wantKeys) {
+			if !gotKeys[signature] {
+				report(name, "missing %s", signature)
+			}
+		}
+		for _, signature := range sortedKeys(gotKeys) {
+			if !wantKeys[signature] {
+				report(name, "unexpected %s", signature)
+			}
+		}
+	}
+
+	for _, name := range actual.TableNames() {
+		if _, ok := expected.Tables[name]; !ok {
+			report(name, "unexpected table")
+		}
+	}
+	return drift
+}
+
+// sortedColumns returns a table's columns in name order
+func sortedColumns(table *TableSchema) []ColumnSchema {
+	columns := make([]ColumnSchema, 0, len(table.Columns))
+	for _, column := range table.Columns {
+		columns = append(columns, column)
+	}
+	sort.Slice(columns, func(i, j int) bool {
+		return columns[i].Name < columns[j].Name
+	})
+	return columns
+}
+
+// indexSignatures maps the signature of each of a table's indexes to its name
+func indexSignatures(table *TableSchema) map[string]string {
+	signatures := make(map[string]string, len(table.Indexes))
+	for _, index := range table.Indexes {
+		signatures[index.signature()] = index.Name
+	}
+	return signatures
+}
+
+// foreignKeySignatures returns the signatures of a table's foreign keys
+func foreignKeySignatures(table *TableSchema) map[string]bool {
+	signatures := make(map[string]bool, len(table.ForeignKeys))
+	for _, key := range table.ForeignKeys {
+		signatures[key.signature()] = true
+	}
+	return signatures
+}
+
+// sortedKeys returns the keys of a map, sorted
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// nullability describes whether a column takes NULL
+func nullability(notNull bool) string {
+	if notNull {
+		return "NOT NULL"
+	}
+	return "nullable"
+}
+
+// describeDefault describes a column default for the report
+func describeDefault(value sql.NullString) string {
+	if !value.Valid {
+		return "none"
+	}
+	return value.String
+}
+
+// schemaInspector reads a dialect's catalogue
+type schemaInspector struct {
+	tables      string
+	columns     func(ctx context.Context, db *DB, table string) (map[string]ColumnSchema, error)
+	indexes     func(ctx context.Context, db *DB, table string) ([]IndexSchema, error)
+	foreignKeys func(ctx context.Context, db *DB, table string) ([]ForeignKeySchema, error)
+}
+
+var schemaInspectors = map[Dialect]schemaInspector{
+	DialectSQLite: {
+		// Virtual tables, such as the full-text search indexes, and the
+		// shadow tables that store them are left out, like other indexes'
+		// storage; their triggers fill them in
+		tables: `SELECT name FROM sqlite_master t
+			WHERE type = 'table' AND name NOT LIKE 'sqlite_%' AND sql NOT LIKE 'CREATE VIRTUAL TABLE%'
+			AND NOT EXISTS (SELECT 1 FROM sqlite_master v WHERE v.type = 'table' AND v.sql LIKE 'CREATE VIRTUAL TABLE%' AND t.name LIKE v.name || '\_%' ESCAPE '\')
+			ORDER BY name`,
+		columns:     sqliteColumns,
+		indexes:     sqliteIndexes,
+		foreignKeys: sqliteForeignKeys,
+	},
+	DialectPostgres: {
+		tables:      `SELECT table_name FROM information_schema.tables WHERE table_schema = current_schema() AND table_type = 'BASE TABLE' ORDER BY table_name`,
+		columns:     postgresColumns,
+		indexes:     postgresIndexes,
+		foreignKeys: postgresForeignKeys,
+	},
+	DialectMySQL: {
+		tables:      `SELECT table_name FROM information_schema.tables WHERE table_schema = DATABASE() AND table_type = 'BASE TABLE' ORDER BY table_name`,
+		columns:     mysqlColumns,
+		indexes:     mysqlIndexes,
+		foreignKeys: mysqlForeignKeys,
+	},
+}
+
+func sqliteColumns(ctx context.Context, db *DB, table string) (map[string]ColumnSchema, error) {
+	rows, err := db.QueryContext(ctx, `SELECT name, type, "notnull", dflt_value, pk FROM pragma_table_info(?)`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns := make(map[string]ColumnSchema)
+	for rows.Next() {
+		var column ColumnSchema
+		var pk int
+		if err := rows.Scan(&column.Name, &column.Type, &column.NotNull, &column.Default, &pk); err != nil {
+			return nil, err
+		}
+		column.Type = strings.ToUpper(column.Type)
+		column.PrimaryKey = pk > 0
+		columns[column.Name] = column
+	}
+	return columns, rows.Err()
+}
+
+func sqliteIndexes(ctx context.Context, db *DB, table string) ([]IndexSchema, error) {
+	// The primary key's index, if it has one, is covered by the columns
+	rows, err := db.QueryContext(ctx, `SELECT name, "unique" FROM pragma_index_list(?) WHERE origin <> 'pk' ORDER BY name`, table)
+	if err != nil {
+		return nil, err
+	}
+	var indexes []IndexSchema
+	for rows.Next() {
+		var index IndexSchema
+		if err := rows.Scan(&index.Name, &index.Unique); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		indexes = append(indexes, index)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range indexes {
+		// Expression columns have no name
+		columns, err := queryStrings(ctx, db, `SELECT COALESCE(name, '<expression>') FROM pragma_index_info(?) ORDER BY seqno`, indexes[i].Name)
+		if err != nil {
+			return nil, err
+		}
+		indexes[i].Columns = columns
+	}
+	return indexes, nil
+}
+
+func sqliteForeignKeys(ctx context.Context, db *DB, table string) ([]ForeignKeySchema, error) {
+	rows, err := db.QueryContext(ctx, `SELECT id, "table", "from", "to", on_delete FROM pragma_foreign_key_list(?) ORDER BY id, seq`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanForeignKeys(rows)
+}
+
+func postgresColumns(ctx context.Context, db *DB, table string) (map[string]ColumnSchema, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT a.attname, format_type(a.atttypid, a.atttypmod), a.attnotnull,
+			pg_get_expr(d.adbin, d.adrelid),
+			EXISTS (
+				SELECT 1 FROM pg_index i
+				WHERE i.indrelid = c.oid AND i.indisprimary AND a.attnum = ANY(i.indkey)
+			)
+		FROM pg_attribute a
+		JOIN pg_class c ON c.oid = a.attrelid
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		LEFT JOIN pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+		WHERE n.nspname = current_schema() AND c.relname = ? AND a.attnum > 0 AND NOT a.attisdropped`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns := make(map[string]ColumnSchema)
+	for rows.Next() {
+		var column ColumnSchema
+		if err := rows.Scan(&column.Name, &column.Type, &column.NotNull, &column.Default, &column.PrimaryKey); err != nil {
+			return nil, err
+		}
+		columns[column.Name] = column
+	}
+	return columns, rows.Err()
+}
+
+func postgresIndexes(ctx context.Context, db *DB, table string) ([]IndexSchema, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT ic.relname, i.indisunique, COALESCE(a.attname, '<expression>')
+		FROM pg_index i
+		JOIN pg_class c ON c.oid = i.indrelid
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		JOIN pg_class ic ON ic.oid = i.indexrelid
+		CROSS JOIN LATERAL unnest(i.indkey) WITH ORDINALITY AS k(attnum, ord)
+		LEFT JOIN pg_attribute a ON a.attrelid = c.oid AND a.attnum = k.attnum
+		WHERE n.nspname = current_schema() AND c.relname = ? AND NOT i.indisprimary
+		ORDER BY ic.relname, k.ord`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanIndexes(rows)
+}
+
+func postgresForeignKeys(ctx context.Context, db *DB, table string) ([]ForeignKeySchema, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT con.conname, rc.relname, a.attname, ra.attname,
+			CASE con.confdeltype
+				WHEN 'c' THEN 'CASCADE'
+				WHEN 'n' THEN 'SET NULL'
+				WHEN 'd' THEN 'SET DEFAULT'
+				WHEN 'r' THEN 'RESTRICT'
+				ELSE 'NO ACTION'
+			END
+		FROM pg_constraint con
+		JOIN pg_class c ON c.oid = con.conrelid
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		JOIN pg_class rc ON rc.oid = con.confrelid
+		CROSS JOIN LATERAL unnest(con.conkey, con.confkey) WITH ORDINALITY AS k(attnum, refattnum, ord)
+		JOIN pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.attnum
+		JOIN pg_attribute ra ON ra.attrelid = con.confrelid AND ra.attnum = k.refattnum
+		WHERE con.contype = 'f' AND n.nspname = current_schema() AND c.relname = ?
+		ORDER BY con.conname, k.ord`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanForeignKeys(rows)
+}
+
+func mysqlColumns(ctx context.Context, db *DB, table string) (map[string]ColumnSchema, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT column_name, column_type, is_nullable = 'NO', column_default, column_key = 'PRI'
+		FROM information_schema.columns
+		WHERE table_schema = DATABASE() AND table_name = ?`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns := make(map[string]ColumnSchema)
+	for rows.Next() {
+		var column ColumnSchema
+		if err := rows.Scan(&column.Name, &column.Type, &column.NotNull, &column.Default, &column.PrimaryKey); err != nil {
+			return nil, err
+		}
+		columns[column.Name] = column
+	}
+	return columns, rows.Err()
+}
+
+func mysqlIndexes(ctx context.Context, db *DB, table string) ([]IndexSchema, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT index_name, non_unique = 0, COALESCE(column_name, '<expression>')
+		FROM information_schema.statistics
+		WHERE table_schema = DATABASE() AND table_name = ? AND index_name <> 'PRIMARY'
+		ORDER BY index_name, seq_in_index`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanIndexes(rows)
+}
+
+func mysqlForeignKeys(ctx context.Context, db *DB, table string) ([]ForeignKeySchema, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT k.constraint_name, k.referenced_table_name, k.column_name, k.referenced_column_name, r.delete_rule
+		FROM information_schema.key_column_usage k
+		JOIN information_schema.referential_constraints r
+			ON r.constraint_schema = k.constraint_schema AND r.constraint_name = k.constraint_name
+		WHERE k.table_schema = DATABASE() AND k.table_name = ? AND k.referenced_table_name IS NOT NULL
+		ORDER BY k.constraint_name, k.ordinal_position`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanForeignKeys(rows)
+}
+
+// scanIndexes collects rows of (index name, unique, column), one per column
+// in index order, into indexes
+func scanIndexes(rows *sql.Rows) ([]IndexSchema, error) {
+	var indexes []IndexSchema
+	for rows.Next() {
+		var name, column string
+		var unique bool
+		if err := rows.Scan(&name, &unique, &column); err != nil {
+			return nil, err
+		}
+		if len(indexes) == 0 || indexes[len(indexes)-1].Name != name {
+			indexes = append(indexes, IndexSchema{Name: name, Unique: unique})
+		}
+		last := &indexes[len(indexes)-1]
+		last.Columns = append(last.Columns, column)
+	}
+	return indexes, rows.Err()
+}
+
+// scanForeignKeys collects rows of (constraint id, referenced table,
+// column, referenced column, on delete), one per column in key order, into
+// foreign keys
+func scanForeignKeys(rows *sql.Rows) ([]ForeignKeySchema, error) {
+	var keys []ForeignKeySchema
+	var lastID string
+	for rows.Next() {
+		var id, column string
+		var key ForeignKeySchema
+		var refColumn sql.NullString
+		if err := rows.Scan(&id, &key.RefTable, &column, &refColumn, &key.OnDelete); err != nil {
+			return nil, err
+		}
+		// SQLite leaves out the referenced column of a key on the other
+		// table's primary key
+		if !refColumn.Valid {
+			refColumn.String = "<primary key>"
+		}
+		if len(keys) == 0 || id != lastID {
+			keys = append(keys, key)
+			lastID = id
+		}
+		last := &keys[len(keys)-1]
+		last.Columns = append(last.Columns, column)
+		last.RefColumns = append(last.RefColumns, refColumn.String)
+	}
+	return keys, rows.Err()
+}
+
+// queryStrings runs a query returning one string column
+func queryStrings(ctx context.Context, db *DB, query string, args ...interface{}) ([]string, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var values []string
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, rows.Err()
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+)
+
+func TestSchemaCheck(t *testing.T) {
+	sqlDB, err := sql.Open("sqlite3", t.TempDir()+"/actalog.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sqlDB.Close()
+	ctx := context.Background()
+
+	migrator, err := NewMigrator(sqlDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+	latest := migrator.migrations[len(migrator.migrations)-1].Version
+
+	scratch, cleanup, err := OpenScratchDB(ctx, sqlDB, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+	expected, err := ExpectedSchema(ctx, scratch, latest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := expected.Tables["workout_wods"]; !ok {
+		t.Fatalf("expected the migrations to create workout_wods, got %v", expected.TableNames())
+	}
+	if _, ok := expected.Tables["schema_migrations"]; ok {
+		t.Error("expected the migrator's own tables to be left out")
+	}
+
+	check := func() []SchemaDrift {
+		actual, err := InspectSchema(ctx, sqlDB)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return DiffSchemas(expected, actual)
+	}
+	if drift := check(); len(drift) != 0 {
+		t.Fatalf("expected a migrated database to match, got %v", drift)
+	}
+
+	// A database that missed the 0.4.1 columns, with an index of its own
+	for _, query := range []string{
+		`ALTER TABLE workout_wods DROP COLUMN score_value`,
+		`CREATE INDEX idx_users_name ON users(name)`,
+		`CREATE TABLE scratch_notes (id INTEGER PRIMARY KEY)`,
+	} {
+		if _, err := sqlDB.Exec(query); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []SchemaDrift{
+		{Table: "users", Problem: "unexpected index (name) idx_users_name"},
+		{Table: "workout_wods", Problem: "missing column score_value TEXT"},
+		{Table: "scratch_notes", Problem: "unexpected table"},
+	}
+	if drift := check(); !reflect.DeepEqual(drift, want) {
+		t.Errorf("expected %v, got %v", want, drift)
+	}
+}
+
+func TestDiffSchemas(t *testing.T) {
+	table := func(columns []ColumnSchema, keys ...ForeignKeySchema) *Schema {
+		wods := &TableSchema{Name: "wods", Columns: map[string]ColumnSchema{}, ForeignKeys: keys}
+		for _, column := range columns {
+			wods.Columns[column.Name] = column
+		}
+		return &Schema{Tables: map[string]*TableSchema{"wods": wods}}
+	}
+	key := ForeignKeySchema{Columns: []string{"user_id"}, RefTable: "users", RefColumns: []string{"id"}, OnDelete: "CASCADE"}
+	expected := table([]ColumnSchema{
+		{Name: "id", Type: "INTEGER", PrimaryKey: true},
+		{Name: "name", Type: "TEXT", NotNull: true},
+		{Name: "is_pr", Type: "INTEGER", NotNull: true, Default: sql.NullString{String: "0", Valid: true}},
+		{Name: "user_id", Type: "INTEGER"},
+	}, key)
+
+	key.OnDelete = "NO ACTION"
+	actual := table([]ColumnSchema{
+		{Name: "id", Type: "integer", PrimaryKey: true},
+		{Name: "name", Type: "TEXT"},
+		{Name: "is_pr", Type: "INTEGER", NotNull: true},
+		{Name: "user_id", Type: "BIGINT"},
+	}, key)
+
+	want := []string{
+		"column is_pr has default none, expected 0",
+		"column name is nullable, expected NOT NULL",
+		"column user_id has type BIGINT, expected INTEGER",
+		"missing foreign key (user_id) REFERENCES users (id) ON DELETE CASCADE",
+		"unexpected foreign key (user_id) REFERENCES users (id) ON DELETE NO ACTION",
+	}
+	var got []string
+	for _, drift := range DiffSchemas(expected, actual) {
+		got = append(got, drift.Problem)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
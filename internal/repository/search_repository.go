@@ -0,0 +1,427 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"fmt"
+	"html"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/johnzastrow/actalog/internal/domain"
+	"github.com/mattn/go-sqlite3"
+)
+
+// maxSearchTerms caps how many words of a query are searched for
+const maxSearchTerms = 8
+
+// Full-text vectors for PostgreSQL. The movement and WOD ones must match the
+// expressions of the indexes in migration 000003_search.
+const (
+	pgMovementVector = `setweight(to_tsvector('english', COALESCE(m.name, '')), 'A') ||
+		setweight(to_tsvector('english', COALESCE(m.description, '')), 'B')`
+	pgWODVector = `setweight(to_tsvector('english', COALESCE(w.name, '')), 'A') ||
+		setweight(to_tsvector('english', COALESCE(w.description, '')), 'B') ||
+		setweight(to_tsvector('english', COALESCE(w.notes, '')), 'C')`
+	pgWorkoutVector = `setweight(to_tsvector('english', COALESCE(uw.workout_name, w.name, '')), 'A') ||
+		setweight(to_tsvector('english', COALESCE(uw.notes, '')), 'B')`
+)
+
+// searchQueries holds each dialect's query for each type of result. Each
+// selects the result's columns and then its score, and takes the match
+// (once per AGAINST in MySQL), the visibility arguments and the limit.
+// SQLite's score is bm25 of the FTS4 matchinfo, weighing each column's
+// matches, name first.
+var searchQueries = map[Dialect]map[string]string{
+	DialectSQLite: {
+		domain.SearchTypeMovement: `SELECT m.id, m.name, m.description, m.type, m.is_standard, m.created_by, m.created_at, m.updated_at,
+				bm25(matchinfo(movements_fts, 'pcnalx'), 4.0, 1.0) AS score
+			FROM movements_fts
+			JOIN movements m ON m.id = movements_fts.docid
+			WHERE movements_fts MATCH ? AND (m.is_standard = ? OR m.created_by = ?)
+			ORDER BY score DESC, m.name
+			LIMIT ?`,
+		domain.SearchTypeWOD: `SELECT w.id, w.name, w.source, w.type, w.regime, w.score_type, w.description, w.url, w.notes,
+				w.is_standard, w.created_by, w.created_at, w.updated_at, bm25(matchinfo(wods_fts, 'pcnalx'), 4.0, 1.0, 0.5) AS score
+			FROM wods_fts
+			JOIN wods w ON w.id = wods_fts.docid
+			WHERE wods_fts MATCH ? AND (w.is_standard = ? OR w.created_by = ?)
+			ORDER BY score DESC, w.name
+			LIMIT ?`,
+		domain.SearchTypeWorkout: `SELECT uw.id, COALESCE(uw.workout_name, w.name, ''), uw.notes, uw.workout_date,
+				bm25(matchinfo(user_workouts_fts, 'pcnalx'), 4.0, 1.0) AS score
+			FROM user_workouts_fts
+			JOIN user_workouts uw ON uw.id = user_workouts_fts.docid
+			LEFT JOIN workouts w ON w.id = uw.workout_id
+			WHERE user_workouts_fts MATCH ? AND uw.user_id = ?
+			ORDER BY score DESC, uw.workout_date DESC
+			LIMIT ?`,
+	},
+	DialectPostgres: {
+		domain.SearchTypeMovement: `SELECT m.id, m.name, m.description, m.type, m.is_standard, m.created_by, m.created_at, m.updated_at,
+				ts_rank(` + pgMovementVector + `, q) AS score
+			FROM movements m, to_tsquery('english', ?) q
+			WHERE (` + pgMovementVector + `) @@ q AND (m.is_standard = ? OR m.created_by = ?)
+			ORDER BY score DESC, m.name
+			LIMIT ?`,
+		domain.SearchTypeWOD: `SELECT w.id, w.name, w.source, w.type, w.regime, w.score_type, w.description, w.url, w.notes,
+				w.is_standard, w.created_by, w.created_at, w.updated_at, ts_rank(` + pgWODVector + `, q) AS score
+			FROM wods w, to_tsquery('english', ?) q
+			WHERE (` + pgWODVector + `) @@ q AND (w.is_standard = ? OR w.created_by = ?)
+			ORDER BY score DESC, w.name
+			LIMIT ?`,
+		domain.SearchTypeWorkout: `SELECT uw.id, COALESCE(uw.workout_name, w.name, ''), uw.notes, uw.workout_date,
+				ts_rank(` + pgWorkoutVector + `, q) AS score
+			FROM user_workouts uw
+			LEFT JOIN workouts w ON w.id = uw.workout_id
+			CROSS JOIN to_tsquery('english', ?) q
+			WHERE (` + pgWorkoutVector + `) @@ q AND uw.user_id = ?
+			ORDER BY score DESC, uw.workout_date DESC
+			LIMIT ?`,
+	},
+	DialectMySQL: {
+		domain.SearchTypeMovement: `SELECT m.id, m.name, m.description, m.type, m.is_standard, m.created_by, m.created_at, m.updated_at,
+				MATCH(m.name, m.description) AGAINST (? IN BOOLEAN MODE) AS score
+			FROM movements m
+			WHERE MATCH(m.name, m.description) AGAINST (? IN BOOLEAN MODE) AND (m.is_standard = ? OR m.created_by = ?)
+			ORDER BY score DESC, m.name
+			LIMIT ?`,
+		domain.SearchTypeWOD: `SELECT w.id, w.name, w.source, w.type, w.regime, w.score_type, w.description, w.url, w.notes,
+				w.is_standard, w.created_by, w.created_at, w.updated_at,
+				MATCH(w.name, w.description, w.notes) AGAINST (? IN BOOLEAN MODE) AS score
+			FROM wods w
+			WHERE MATCH(w.name, w.description, w.notes) AGAINST (? IN BOOLEAN MODE) AND (w.is_standard = ? OR w.created_by = ?)
+			ORDER BY score DESC, w.name
+			LIMIT ?`,
+		domain.SearchTypeWorkout: `SELECT uw.id, COALESCE(uw.workout_name, w.name, ''), uw.notes, uw.workout_date,
+				MATCH(uw.workout_name, uw.notes) AGAINST (? IN BOOLEAN MODE) + COALESCE(MATCH(w.name) AGAINST (? IN BOOLEAN MODE), 0) AS score
+			FROM user_workouts uw
+			LEFT JOIN workouts w ON w.id = uw.workout_id
+			WHERE (MATCH(uw.workout_name, uw.notes) AGAINST (? IN BOOLEAN MODE) OR MATCH(w.name) AGAINST (? IN BOOLEAN MODE))
+				AND uw.user_id = ?
+			ORDER BY score DESC, uw.workout_date DESC
+			LIMIT ?`,
+	},
+}
+
+// SearchRepository implements domain.SearchRepository with each dialect's
+// full-text search: SQLite FTS4 tables, PostgreSQL tsvector indexes and
+// MySQL FULLTEXT indexes
+type SearchRepository struct {
+	db *DB
+}
+
+// NewSearchRepository creates a new search repository
+func NewSearchRepository(db *sql.DB) *SearchRepository {
+	return &SearchRepository{db: NewDB(db)}
+}
+
+// Search returns up to limit of the movements, WODs and logged workouts of
+// the given types that contain every word of query, most relevant first.
+// Words match as prefixes, and also by stem except in MySQL, so "squat"
+// finds "Squats" and "squatting". No types means all of them.
+func (r *SearchRepository) Search(ctx context.Context, userID int64, query string, types []string, limit int) ([]*domain.SearchResult, error) {
+	terms := searchTerms(query)
+	if len(terms) == 0 || limit <= 0 {
+		return []*domain.SearchResult{}, nil
+	}
+	if len(types) == 0 {
+		types = []string{domain.SearchTypeMovement, domain.SearchTypeWOD, domain.SearchTypeWorkout}
+	}
+
+	results := []*domain.SearchResult{}
+	for _, t := range types {
+		found, err := r.search(ctx, t, userID, terms, limit)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, found...)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Rank != results[j].Rank {
+			return results[i].Rank > results[j].Rank
+		}
+		return results[i].Name < results[j].Name
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// search runs the query for one type of result
+func (r *SearchRepository) search(ctx context.Context, resultType string, userID int64, terms []string, limit int) ([]*domain.SearchResult, error) {
+	query, ok := searchQueries[r.db.Dialect][resultType]
+	if !ok {
+		return nil, fmt.Errorf("unsupported search type %q", resultType)
+	}
+
+	var args []interface{}
+	switch r.db.Dialect {
+	case DialectSQLite:
+		args = append(args, sqliteMatch(terms))
+	case DialectPostgres:
+		args = append(args, postgresMatch(terms))
+	case DialectMySQL:
+		match := mysqlMatch(terms)
+		for i := 0; i < strings.Count(query, "AGAINST (?"); i++ {
+			args = append(args, match)
+		}
+	}
+	if resultType != domain.SearchTypeWorkout {
+		args = append(args, true)
+	}
+	args = append(args, userID, limit)
+
+	rows, release, err := r.query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search %ss: %w", resultType, err)
+	}
+	defer release()
+	defer rows.Close()
+
+	var results []*domain.SearchResult
+	for rows.Next() {
+		var (
+			result = &domain.SearchResult{Type: resultType}
+			body   []string
+			score  sql.NullFloat64
+		)
+
+		switch resultType {
+		case domain.SearchTypeMovement:
+			m := &domain.Movement{}
+			var description sql.NullString
+			var createdBy sql.NullInt64
+			if err := rows.Scan(&m.ID, &m.Name, &description, &m.Type, &m.IsStandard, &createdBy, &m.CreatedAt, &m.UpdatedAt, &score); err != nil {
+				return nil, fmt.Errorf("failed to scan movement: %w", err)
+			}
+			m.Description = description.String
+			if createdBy.Valid {
+				m.CreatedBy = &createdBy.Int64
+			}
+			result.ID, result.Name, result.Category, result.Data = m.ID, m.Name, string(m.Type), m
+			body = []string{m.Description}
+
+		case domain.SearchTypeWOD:
+			w := &domain.WOD{}
+			var source, wodType, regime, scoreType, description, url, notes sql.NullString
+			var createdBy sql.NullInt64
+			if err := rows.Scan(&w.ID, &w.Name, &source, &wodType, &regime, &scoreType, &description, &url, &notes,
+				&w.IsStandard, &createdBy, &w.CreatedAt, &w.UpdatedAt, &score); err != nil {
+				return nil, fmt.Errorf("failed to scan WOD: %w", err)
+			}
+			w.Source, w.Type, w.Regime, w.ScoreType, w.Description = source.String, wodType.String, regime.String, scoreType.String, description.String
+			if url.Valid {
+				w.URL = &url.String
+			}
+			if notes.Valid {
+				w.Notes = &notes.String
+			}
+			if createdBy.Valid {
+				w.CreatedBy = &createdBy.Int64
+			}
+			result.ID, result.Name, result.Category, result.Data = w.ID, w.Name, w.Type, w
+			body = []string{w.Description, notes.String}
+
+		case domain.SearchTypeWorkout:
+			var notes sql.NullString
+			var date time.Time
+			if err := rows.Scan(&result.ID, &result.Name, &notes, &date, &score); err != nil {
+				return nil, fmt.Errorf("failed to scan workout: %w", err)
+			}
+			result.Date = &date
+			body = []string{notes.String}
+		}
+
+		result.Rank = score.Float64
+		result.Highlight = highlight(result.Name, terms)
+		result.Snippet = snippet(body, terms)
+		results = append(results, result)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to search %ss: %w", resultType, err)
+	}
+	return results, nil
+}
+
+// query runs a search query. SQLite's runs on a connection of its own with
+// bm25 registered, which FTS4 doesn't provide; release returns it to the
+// pool once the rows are closed.
+func (r *SearchRepository) query(ctx context.Context, query string, args ...interface{}) (rows *sql.Rows, release func(), err error) {
+	if r.db.Dialect != DialectSQLite {
+		rows, err = r.db.QueryContext(ctx, query, args...)
+		return rows, func() {}, err
+	}
+
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	err = conn.Raw(func(driverConn interface{}) error {
+		sqliteConn, ok := driverConn.(*sqlite3.SQLiteConn)
+		if !ok {
+			return fmt.Errorf("unexpected SQLite connection %T", driverConn)
+		}
+		return sqliteConn.RegisterFunc("bm25", bm25, true)
+	})
+	if err == nil {
+		rows, err = conn.QueryContext(ctx, query, args...)
+	}
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	return rows, func() { conn.Close() }, nil
+}
+
+// searchTerms splits a query into lowercase words of letters and digits,
+// which keeps every dialect's query syntax out of it
+func searchTerms(query string) []string {
+	terms := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(terms) > maxSearchTerms {
+		terms = terms[:maxSearchTerms]
+	}
+	return terms
+}
+
+// sqliteMatch builds an FTS4 query needing every term as a prefix
+func sqliteMatch(terms []string) string {
+	return strings.Join(terms, "* ") + "*"
+}
+
+// postgresMatch builds a tsquery needing every term as a prefix
+func postgresMatch(terms []string) string {
+	return strings.Join(terms, ":* & ") + ":*"
+}
+
+// mysqlMatch builds a boolean mode query needing every term as a prefix
+func mysqlMatch(terms []string) string {
+	return "+" + strings.Join(terms, "* +") + "*"
+}
+
+// bm25 scores an FTS4 match from its matchinfo 'pcnalx' with Okapi BM25,
+// weighing each column's matches
+func bm25(info []byte, weights ...float64) float64 {
+	const k1, b = 1.2, 0.75
+	values := make([]float64, len(info)/4)
+	for i := range values {
+		values[i] = float64(binary.NativeEndian.Uint32(info[i*4:]))
+	}
+	if len(values) < 3 {
+		return 0
+	}
+	phrases, columns, rows := int(values[0]), int(values[1]), values[2]
+	if len(values) < 3+2*columns+3*phrases*columns {
+		return 0
+	}
+	average, lengths, hits := values[3:3+columns], values[3+columns:3+2*columns], values[3+2*columns:]
+
+	score := 0.0
+	for p := 0; p < phrases; p++ {
+		for c := 0; c < columns && c < len(weights); c++ {
+			x := hits[3*(p*columns+c):]
+			frequency, documents := x[0], x[2]
+			if frequency == 0 {
+				continue
+			}
+			// Terms in most rows would score below zero
+			idf := math.Max(math.Log((rows-documents+0.5)/(documents+0.5)), 1e-6)
+			norm := 1 - b
+			if average[c] > 0 {
+				norm += b * lengths[c] / average[c]
+			}
+			score += weights[c] * idf * frequency * (k1 + 1) / (frequency + k1*norm)
+		}
+	}
+	return score
+}
+
+// matchesTerm reports whether a word matches a search term: it starts with
+// the term, or the term starts with it, as a stemmed match would
+func matchesTerm(word string, terms []string) bool {
+	word = strings.ToLower(word)
+	for _, term := range terms {
+		if strings.HasPrefix(word, term) || (len([]rune(word)) >= 3 && strings.HasPrefix(term, word)) {
+			return true
+		}
+	}
+	return false
+}
+
+// highlight escapes text as HTML and puts the words matching terms in <mark>
+func highlight(text string, terms []string) string {
+	var sb strings.Builder
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		j := i
+		isWord := unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i])
+		for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j])) == isWord {
+			j++
+		}
+		part := string(runes[i:j])
+		if isWord && matchesTerm(part, terms) {
+			sb.WriteString("<mark>" + html.EscapeString(part) + "</mark>")
+		} else {
+			sb.WriteString(html.EscapeString(part))
+		}
+		i = j
+	}
+	return sb.String()
+}
+
+// snippetWords is how many words a snippet holds
+const snippetWords = 24
+
+// snippet highlights an excerpt of the first text with a match, around
+// its first match, or of the first text there is
+func snippet(texts []string, terms []string) string {
+	var words []string
+	start := -1
+	for _, text := range texts {
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+		if words == nil {
+			words = fields
+		}
+		for i, field := range fields {
+			if matchesTerm(strings.TrimFunc(field, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }), terms) {
+				words, start = fields, i
+				break
+			}
+		}
+		if start >= 0 {
+			break
+		}
+	}
+	if words == nil {
+		return ""
+	}
+
+	// Show a few words before the match
+	from := 0
+	if start > snippetWords/4 {
+		from = start - snippetWords/4
+	}
+	to := from + snippetWords
+	if to > len(words) {
+		to = len(words)
+	}
+	excerpt := highlight(strings.Join(words[from:to], " "), terms)
+	if from > 0 {
+		excerpt = "…" + excerpt
+	}
+	if to < len(words) {
+		excerpt += "…"
+	}
+	return excerpt
+}
@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/johnzastrow/actalog/internal/domain"
+)
+
+func TestSearchRepository(t *testing.T) {
+	sqlDB, err := InitDatabase("sqlite3", filepath.Join(t.TempDir(), "actalog.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sqlDB.Close()
+	ctx := context.Background()
+	repo := NewSearchRepository(sqlDB)
+
+	for _, query := range []string{
+		`INSERT INTO users (id, email, password_hash, name, role, created_at, updated_at) VALUES (1, 'a@example.com', 'x', 'A', 'user', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`,
+		`INSERT INTO users (id, email, password_hash, name, role, created_at, updated_at) VALUES (2, 'b@example.com', 'x', 'B', 'user', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`,
+		`INSERT INTO movements (name, description, type, is_standard, created_by, created_at, updated_at) VALUES ('Zercher Squat', 'Bar held in the elbows', 'weightlifting', 0, 2, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`,
+		`INSERT INTO workouts (id, name, created_by, created_at, updated_at) VALUES (100, 'Leg Day', 1, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`,
+		`INSERT INTO user_workouts (id, user_id, workout_id, workout_name, workout_date, notes, created_at, updated_at) VALUES (10, 1, NULL, 'Heavy singles', DATE('2025-10-06'), 'Front squats felt strong & fast', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`,
+		`INSERT INTO user_workouts (id, user_id, workout_id, workout_date, created_at, updated_at) VALUES (11, 1, 100, DATE('2025-10-07'), CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`,
+		`INSERT INTO user_workouts (id, user_id, workout_id, workout_name, workout_date, notes, created_at, updated_at) VALUES (12, 2, NULL, 'Squat day', DATE('2025-10-06'), 'Squats', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`,
+	} {
+		if _, err := sqlDB.Exec(query); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	search := func(userID int64, query string, types ...string) []*domain.SearchResult {
+		t.Helper()
+		results, err := repo.Search(ctx, userID, query, types, 50)
+		if err != nil {
+			t.Fatalf("Search(%q) failed: %v", query, err)
+		}
+		return results
+	}
+	find := func(results []*domain.SearchResult, resultType, name string) *domain.SearchResult {
+		for _, result := range results {
+			if result.Type == resultType && result.Name == name {
+				return result
+			}
+		}
+		return nil
+	}
+
+	// Stemmed prefixes match, and name matches rank first
+	results := search(1, "squat")
+	if len(results) == 0 || results[0].Type != domain.SearchTypeMovement {
+		t.Fatalf("expected a squat movement first, got %+v", results)
+	}
+	backSquat := find(results, domain.SearchTypeMovement, "Back Squat")
+	if backSquat == nil || backSquat.Highlight != "Back <mark>Squat</mark>" || backSquat.Category != string(domain.MovementTypeWeightlifting) {
+		t.Errorf("expected Back Squat highlighted, got %+v", backSquat)
+	}
+	if _, ok := backSquat.Data.(*domain.Movement); !ok {
+		t.Errorf("expected the movement as data, got %T", backSquat.Data)
+	}
+	workout := find(results, domain.SearchTypeWorkout, "Heavy singles")
+	if workout == nil || workout.Date == nil || workout.Snippet != "Front <mark>squats</mark> felt strong &amp; fast" {
+		t.Errorf("expected the user's workout with its notes highlighted, got %+v", workout)
+	}
+	for i := 1; i < len(results); i++ {
+		if results[i].Rank > results[i-1].Rank {
+			t.Errorf("results out of rank order at %d: %v > %v", i, results[i].Rank, results[i-1].Rank)
+		}
+	}
+
+	// The limit keeps the best matches
+	movements := search(1, "squat", domain.SearchTypeMovement)
+	limited, err := repo.Search(ctx, 1, "squat", []string{domain.SearchTypeMovement}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(movements) < 3 || len(limited) != 2 || limited[0].ID != movements[0].ID || limited[1].ID != movements[1].ID {
+		t.Errorf("expected the 2 best of %d movements, got %+v", len(movements), limited)
+	}
+
+	// Other users' movements and workouts stay private
+	if find(results, domain.SearchTypeMovement, "Zercher Squat") != nil || find(results, domain.SearchTypeWorkout, "Squat day") != nil {
+		t.Error("expected another user's movement and workout to be left out")
+	}
+	if find(search(2, "squat"), domain.SearchTypeMovement, "Zercher Squat") == nil {
+		t.Error("expected the owner to find their own movement")
+	}
+
+	// Every word must match, and types narrow the search
+	if results := search(1, "front squat", domain.SearchTypeMovement); find(results, domain.SearchTypeMovement, "Front Squat") == nil || find(results, domain.SearchTypeMovement, "Back Squat") != nil {
+		t.Errorf("expected only Front Squat, got %+v", results)
+	}
+	if results := search(1, "fran", domain.SearchTypeWOD); len(results) != 1 || results[0].Name != "Fran" {
+		t.Errorf("expected Fran, got %+v", results)
+	}
+
+	// Workouts logged from a template are found by its name, and follow renames
+	if find(search(1, "leg day"), domain.SearchTypeWorkout, "Leg Day") == nil {
+		t.Error("expected the workout logged from Leg Day")
+	}
+	if _, err := sqlDB.Exec(`UPDATE workouts SET name = 'Lower Body' WHERE id = 100`); err != nil {
+		t.Fatal(err)
+	}
+	if find(search(1, "lower"), domain.SearchTypeWorkout, "Lower Body") == nil || len(search(1, "leg day")) != 0 {
+		t.Error("expected the logged workout to follow its template's new name")
+	}
+
+	// Deleted rows leave the index
+	if _, err := sqlDB.Exec(`DELETE FROM user_workouts WHERE id = 10`); err != nil {
+		t.Fatal(err)
+	}
+	if find(search(1, "heavy"), domain.SearchTypeWorkout, "Heavy singles") != nil {
+		t.Error("expected a deleted workout to be gone")
+	}
+
+	// Query syntax is ignored rather than passed to the database
+	if results := search(1, `"NEAR* OR -)`); len(results) != 0 {
+		t.Errorf("expected no results, got %+v", results)
+	}
+	if results := search(1, "  "); len(results) != 0 {
+		t.Errorf("expected an empty query to find nothing, got %+v", results)
+	}
+	if _, err := repo.Search(ctx, 1, "squat", []string{"user"}, 10); err == nil {
+		t.Error("expected an unknown type to be rejected")
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		text     string
+		terms    []string
+		expected string
+	}{
+		{"Back Squat", []string{"squat"}, "Back <mark>Squat</mark>"},
+		{"Pull-ups & Push-ups", []string{"pull"}, "<mark>Pull</mark>-ups &amp; Push-ups"},
+		{"Running", []string{"running", "run"}, "<mark>Running</mark>"},
+		{"Row <b>", []string{"rowing"}, "<mark>Row</mark> &lt;b&gt;"},
+		{"A to B", []string{"a"}, "<mark>A</mark> to B"},
+	}
+	for _, tt := range tests {
+		if got := highlight(tt.text, tt.terms); got != tt.expected {
+			t.Errorf("highlight(%q, %v) = %q, want %q", tt.text, tt.terms, got, tt.expected)
+		}
+	}
+
+	long := "one two three four five six seven eight nine ten eleven twelve thirteen fourteen fifteen sixteen seventeen eighteen nineteen twenty twentyone twentytwo twentythree twentyfour twentyfive thrusters"
+	if got, want := snippet([]string{"", long}, []string{"thruster"}), "…twenty twentyone twentytwo twentythree twentyfour twentyfive <mark>thrusters</mark>"; got != want {
+		t.Errorf("snippet = %q, want %q", got, want)
+	}
+}
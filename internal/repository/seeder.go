@@ -0,0 +1,543 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strconv"
+
+	"github.com/johnzastrow/actalog/internal/domain"
+)
+
+// Seed pack files. Each is optional, and columns are matched by their
+// header, so a pack may leave out the ones it doesn't use.
+const (
+	seedMovementsFile = "movements.csv" // name, description, type
+	seedWODsFile      = "wods.csv"      // name, source, type, regime, score_type, description, url, notes
+	seedTemplatesFile = "templates.csv" // workout, notes, movement or wod, weight, sets, reps, time, distance
+)
+
+// SeedPack is a set of standard movements, WODs and workout templates, like
+// the standard pack in the seeds package or a gym's own benchmark library
+type SeedPack struct {
+	Name      string
+	Movements []SeedMovement
+	WODs      []SeedWOD
+	Templates []SeedTemplate
+}
+
+// SeedMovement is a standard movement, identified by its name
+type SeedMovement struct {
+	Name        string
+	Description string
+	Type        string
+}
+
+// SeedWOD is a standard WOD, identified by its name
+type SeedWOD struct {
+	Name        string
+	Source      string
+	Type        string
+	Regime      string
+	ScoreType   string
+	Description string
+	URL         string
+	Notes       string
+}
+
+// SeedTemplate is a workout template, identified by its name. Each row of
+// templates.csv adds a movement or a WOD to the template it names.
+type SeedTemplate struct {
+	Name      string
+	Notes     string
+	Movements []SeedTemplateMovement
+	WODs      []string
+}
+
+// SeedTemplateMovement is a movement of a workout template
+type SeedTemplateMovement struct {
+	Movement string
+	Weight   sql.NullFloat64
+	Sets     sql.NullInt64
+	Reps     sql.NullInt64
+	Time     sql.NullInt64
+	Distance sql.NullFloat64
+}
+
+// SeedCounts counts what seeding did to one kind of seed
+type SeedCounts struct {
+	Inserted  int `json:"inserted"`
+	Updated   int `json:"updated"`
+	Unchanged int `json:"unchanged"`
+	Skipped   int `json:"skipped"` // Names taken by a user's own movement or WOD
+}
+
+// SeedResult is the outcome of seeding a pack
+type SeedResult struct {
+	Movements SeedCounts `json:"movements"`
+	WODs      SeedCounts `json:"wods"`
+	Templates SeedCounts `json:"templates"`
+}
+
+// LoadSeedPack reads a seed pack from the movements.csv, wods.csv and
+// templates.csv files in fsys
+func LoadSeedPack(name string, fsys fs.FS) (*SeedPack, error) {
+	pack := &SeedPack{Name: name}
+	found := false
+
+	rows, err := readSeedFile(fsys, seedMovementsFile)
+	if err != nil {
+		return nil, err
+	}
+	found = found || rows != nil
+	for _, row := range rows {
+		m := SeedMovement{Name: row.get("name"), Description: row.get("description"), Type: row.get("type")}
+		if m.Name == "" {
+			return nil, row.errorf("missing name")
+		}
+		switch domain.MovementType(m.Type) {
+		case domain.MovementTypeWeightlifting, domain.MovementTypeBodyweight, domain.MovementTypeCardio, domain.MovementTypeGymnastics:
+		default:
+			return nil, row.errorf("movement %s has invalid type %q", m.Name, m.Type)
+		}
+		pack.Movements = append(pack.Movements, m)
+	}
+
+	rows, err = readSeedFile(fsys, seedWODsFile)
+	if err != nil {
+		return nil, err
+	}
+	found = found || rows != nil
+	for _, row := range rows {
+		w := SeedWOD{
+			Name:        row.get("name"),
+			Source:      row.get("source"),
+			Type:        row.get("type"),
+			Regime:      row.get("regime"),
+			ScoreType:   row.get("score_type"),
+			Description: row.get("description"),
+			URL:         row.get("url"),
+			Notes:       row.get("notes"),
+		}
+		if w.Name == "" {
+			return nil, row.errorf("missing name")
+		}
+		pack.WODs = append(pack.WODs, w)
+	}
+
+	rows, err = readSeedFile(fsys, seedTemplatesFile)
+	if err != nil {
+		return nil, err
+	}
+	found = found || rows != nil
+	templates := make(map[string]int)
+	for _, row := range rows {
+		name := row.get("workout")
+		if name == "" {
+			return nil, row.errorf("missing workout")
+		}
+		i, ok := templates[name]
+		if !ok {
+			i = len(pack.Templates)
+			templates[name] = i
+			pack.Templates = append(pack.Templates, SeedTemplate{Name: name})
+		}
+		template := &pack.Templates[i]
+		if notes := row.get("notes"); notes != "" {
+			template.Notes = notes
+		}
+
+		movement, wod := row.get("movement"), row.get("wod")
+		switch {
+		case movement != "" && wod != "":
+			return nil, row.errorf("a row adds either a movement or a WOD, not both")
+		case wod != "":
+			template.WODs = append(template.WODs, wod)
+		case movement != "":
+			m := SeedTemplateMovement{Movement: movement}
+			if m.Weight, err = row.float("weight"); err != nil {
+				return nil, err
+			}
+			if m.Sets, err = row.int("sets"); err != nil {
+				return nil, err
+			}
+			if m.Reps, err = row.int("reps"); err != nil {
+				return nil, err
+			}
+			if m.Time, err = row.int("time"); err != nil {
+				return nil, err
+			}
+			if m.Distance, err = row.float("distance"); err != nil {
+				return nil, err
+			}
+			template.Movements = append(template.Movements, m)
+		default:
+			return nil, row.errorf("missing movement or WOD")
+		}
+	}
+
+	if !found {
+		return nil, fmt.Errorf("seed pack %s has no %s, %s or %s", name, seedMovementsFile, seedWODsFile, seedTemplatesFile)
+	}
+	return pack, nil
+}
+
+// seedRow is a row of a seed file, with its columns by header
+type seedRow struct {
+	file    string
+	line    int
+	columns map[string]int
+	record  []string
+}
+
+func (r seedRow) get(column string) string {
+	i, ok := r.columns[column]
+	if !ok || i >= len(r.record) {
+		return ""
+	}
+	return r.record[i]
+}
+
+func (r seedRow) int(column string) (sql.NullInt64, error) {
+	value := r.get(column)
+	if value == "" {
+		return sql.NullInt64{}, nil
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return sql.NullInt64{}, r.errorf("invalid %s %q", column, value)
+	}
+	return sql.NullInt64{Int64: n, Valid: true}, nil
+}
+
+func (r seedRow) float(column string) (sql.NullFloat64, error) {
+	value := r.get(column)
+	if value == "" {
+		return sql.NullFloat64{}, nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return sql.NullFloat64{}, r.errorf("invalid %s %q", column, value)
+	}
+	return sql.NullFloat64{Float64: f, Valid: true}, nil
+}
+
+func (r seedRow) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%s line %d: %s", r.file, r.line, fmt.Sprintf(format, args...))
+}
+
+// readSeedFile reads the rows of a seed file, or returns nil if the pack
+// doesn't have it
+func readSeedFile(fsys fs.FS, name string) ([]seedRow, error) {
+	file, err := fsys.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", name, err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	header, err := reader.Read()
+	if err == io.EOF {
+		return []seedRow{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, column := range header {
+		columns[column] = i
+	}
+
+	rows := []seedRow{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		line, _ := reader.FieldPos(0)
+		rows = append(rows, seedRow{file: name, line: line, columns: columns, record: record})
+	}
+}
+
+// Seeder loads seed packs into a database
+type Seeder struct {
+	db         *DB
+	transactor *Transactor
+}
+
+// NewSeeder creates a new seeder
+func NewSeeder(db *sql.DB) *Seeder {
+	return &Seeder{db: NewDB(db), transactor: NewTransactor(db)}
+}
+
+// Seed upserts a pack's movements, WODs and workout templates by name, in
+// one transaction. Standard movements and WODs whose fields changed are
+// updated, but a name taken by a user's own movement or WOD is skipped.
+// Templates are the workouts without an owner; one whose notes or
+// movements changed is updated in place, so logged workouts keep it.
+// Nothing missing from the pack is deleted.
+func (s *Seeder) Seed(ctx context.Context, pack *SeedPack) (*SeedResult, error) {
+	result := &SeedResult{}
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		for _, m := range pack.Movements {
+			if err := s.seedMovement(ctx, m, &result.Movements); err != nil {
+				return fmt.Errorf("failed to seed movement %s: %w", m.Name, err)
+			}
+		}
+		for _, w := range pack.WODs {
+			if err := s.seedWOD(ctx, w, &result.WODs); err != nil {
+				return fmt.Errorf("failed to seed WOD %s: %w", w.Name, err)
+			}
+		}
+		for _, t := range pack.Templates {
+			if err := s.seedTemplate(ctx, t, &result.Templates); err != nil {
+				return fmt.Errorf("failed to seed workout template %s: %w", t.Name, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to seed %s: %w", pack.Name, err)
+	}
+	return result, nil
+}
+
+func (s *Seeder) seedMovement(ctx context.Context, m SeedMovement, counts *SeedCounts) error {
+	var (
+		id          int64
+		standard    bool
+		description sql.NullString
+		movType     string
+	)
+	err := s.db.QueryRowContext(ctx, `SELECT id, is_standard, description, type FROM movements WHERE name = ?`, m.Name).
+		Scan(&id, &standard, &description, &movType)
+	switch {
+	case err == sql.ErrNoRows:
+		_, err := s.db.ExecContext(ctx, `INSERT INTO movements (name, description, type, is_standard, created_by, created_at, updated_at)
+			VALUES (?, ?, ?, ?, NULL, `+s.db.Dialect.Now()+`, `+s.db.Dialect.Now()+`)`,
+			m.Name, seedNullString(m.Description), m.Type, true)
+		if err != nil {
+			return err
+		}
+		counts.Inserted++
+	case err != nil:
+		return err
+	case !standard:
+		counts.Skipped++
+	case description.String == m.Description && movType == m.Type:
+		counts.Unchanged++
+	default:
+		_, err := s.db.ExecContext(ctx, `UPDATE movements SET description = ?, type = ?, updated_at = `+s.db.Dialect.Now()+` WHERE id = ?`,
+			seedNullString(m.Description), m.Type, id)
+		if err != nil {
+			return err
+		}
+		counts.Updated++
+	}
+	return nil
+}
+
+func (s *Seeder) seedWOD(ctx context.Context, w SeedWOD, counts *SeedCounts) error {
+	var (
+		id       int64
+		standard bool
+		current  [7]sql.NullString
+	)
+	err := s.db.QueryRowContext(ctx, `SELECT id, is_standard, source, type, regime, score_type, description, url, notes FROM wods WHERE name = ?`, w.Name).
+		Scan(&id, &standard, &current[0], &current[1], &current[2], &current[3], &current[4], &current[5], &current[6])
+	fields := [7]string{w.Source, w.Type, w.Regime, w.ScoreType, w.Description, w.URL, w.Notes}
+	args := make([]interface{}, len(fields))
+	unchanged := true
+	for i, field := range fields {
+		args[i] = seedNullString(field)
+		unchanged = unchanged && current[i].String == field
+	}
+
+	switch {
+	case err == sql.ErrNoRows:
+		_, err := s.db.ExecContext(ctx, `INSERT INTO wods (source, type, regime, score_type, description, url, notes, name, is_standard, created_by, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, NULL, `+s.db.Dialect.Now()+`, `+s.db.Dialect.Now()+`)`,
+			append(args, w.Name, true)...)
+		if err != nil {
+			return err
+		}
+		counts.Inserted++
+	case err != nil:
+		return err
+	case !standard:
+		counts.Skipped++
+	case unchanged:
+		counts.Unchanged++
+	default:
+		_, err := s.db.ExecContext(ctx, `UPDATE wods SET source = ?, type = ?, regime = ?, score_type = ?, description = ?, url = ?, notes = ?,
+			updated_at = `+s.db.Dialect.Now()+` WHERE id = ?`,
+			append(args, id)...)
+		if err != nil {
+			return err
+		}
+		counts.Updated++
+	}
+	return nil
+}
+
+func (s *Seeder) seedTemplate(ctx context.Context, t SeedTemplate, counts *SeedCounts) error {
+	// Resolve names first, so a template with a typo fails before it's
+	// written. Templates are shared, so they may only use standard movements
+	// and WODs, never a user's own one that happens to have the name.
+	movementIDs := make([]int64, len(t.Movements))
+	for i, m := range t.Movements {
+		if err := s.db.QueryRowContext(ctx, `SELECT id FROM movements WHERE name = ? AND is_standard = ?`, m.Movement, true).Scan(&movementIDs[i]); err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("no standard movement named %s", m.Movement)
+			}
+			return err
+		}
+	}
+	wodIDs := make([]int64, len(t.WODs))
+	for i, name := range t.WODs {
+		if err := s.db.QueryRowContext(ctx, `SELECT id FROM wods WHERE name = ? AND is_standard = ?`, name, true).Scan(&wodIDs[i]); err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("no standard WOD named %s", name)
+			}
+			return err
+		}
+	}
+
+	var (
+		id    int64
+		notes sql.NullString
+	)
+	err := s.db.QueryRowContext(ctx, `SELECT id, notes FROM workouts WHERE name = ? AND created_by IS NULL ORDER BY id`, t.Name).Scan(&id, &notes)
+	switch {
+	case err == sql.ErrNoRows:
+		id, err = s.db.InsertContext(ctx, `INSERT INTO workouts (name, notes, created_by, created_at, updated_at)
+			VALUES (?, ?, NULL, `+s.db.Dialect.Now()+`, `+s.db.Dialect.Now()+`)`, t.Name, seedNullString(t.Notes))
+		if err != nil {
+			return err
+		}
+		counts.Inserted++
+	case err != nil:
+		return err
+	default:
+		same, err := s.templateMatches(ctx, id, t, movementIDs, wodIDs)
+		if err != nil {
+			return err
+		}
+		if same && notes.String == t.Notes {
+			counts.Unchanged++
+			return nil
+		}
+		if _, err := s.db.ExecContext(ctx, `UPDATE workouts SET notes = ?, updated_at = `+s.db.Dialect.Now()+` WHERE id = ?`, seedNullString(t.Notes), id); err != nil {
+			return err
+		}
+		if _, err := s.db.ExecContext(ctx, `DELETE FROM workout_movements WHERE workout_id = ?`, id); err != nil {
+			return err
+		}
+		if _, err := s.db.ExecContext(ctx, `DELETE FROM workout_wods WHERE workout_id = ?`, id); err != nil {
+			return err
+		}
+		counts.Updated++
+	}
+
+	for i, m := range t.Movements {
+		_, err := s.db.ExecContext(ctx, `INSERT INTO workout_movements (workout_id, movement_id, weight, sets, reps, time, distance, is_rx, is_pr, order_index, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, `+s.db.Dialect.Now()+`, `+s.db.Dialect.Now()+`)`,
+			id, movementIDs[i], m.Weight, m.Sets, m.Reps, m.Time, m.Distance, false, false, i)
+		if err != nil {
+			return err
+		}
+	}
+	for i, wodID := range wodIDs {
+		_, err := s.db.ExecContext(ctx, `INSERT INTO workout_wods (workout_id, wod_id, order_index, created_at, updated_at)
+			VALUES (?, ?, ?, `+s.db.Dialect.Now()+`, `+s.db.Dialect.Now()+`)`, id, wodID, i)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// templateMatches reports whether a template's workout already has the
+// movements and WODs of t, in order
+func (s *Seeder) templateMatches(ctx context.Context, workoutID int64, t SeedTemplate, movementIDs, wodIDs []int64) (bool, error) {
+	ids, movements, err := s.workoutMovements(ctx, workoutID)
+	if err != nil {
+		return false, err
+	}
+	if len(movements) != len(t.Movements) {
+		return false, nil
+	}
+	for i, m := range t.Movements {
+		current := movements[i]
+		current.Movement = m.Movement
+		if ids[i] != movementIDs[i] || current != m {
+			return false, nil
+		}
+	}
+
+	wods, err := s.workoutWODIDs(ctx, workoutID)
+	if err != nil {
+		return false, err
+	}
+	if len(wods) != len(wodIDs) {
+		return false, nil
+	}
+	for i := range wods {
+		if wods[i] != wodIDs[i] {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (s *Seeder) workoutMovements(ctx context.Context, workoutID int64) ([]int64, []SeedTemplateMovement, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT movement_id, weight, sets, reps, time, distance FROM workout_movements WHERE workout_id = ? ORDER BY order_index, id`, workoutID)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+	var ids []int64
+	var movements []SeedTemplateMovement
+	for rows.Next() {
+		var id int64
+		var m SeedTemplateMovement
+		if err := rows.Scan(&id, &m.Weight, &m.Sets, &m.Reps, &m.Time, &m.Distance); err != nil {
+			return nil, nil, err
+		}
+		ids = append(ids, id)
+		movements = append(movements, m)
+	}
+	return ids, movements, rows.Err()
+}
+
+func (s *Seeder) workoutWODIDs(ctx context.Context, workoutID int64) ([]int64, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT wod_id FROM workout_wods WHERE workout_id = ? ORDER BY order_index, id`, workoutID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// seedNullString stores an empty seed field as NULL
+func seedNullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
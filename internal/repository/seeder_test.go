@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/johnzastrow/actalog/seeds"
+)
+
+func TestLoadSeedPack(t *testing.T) {
+	pack, err := LoadSeedPack("standard", seeds.Standard)
+	if err != nil {
+		t.Fatalf("failed to load the standard pack: %v", err)
+	}
+	if len(pack.Movements) == 0 || len(pack.WODs) == 0 || len(pack.Templates) == 0 {
+		t.Fatalf("expected movements, WODs and templates, got %d, %d and %d", len(pack.Movements), len(pack.WODs), len(pack.Templates))
+	}
+	names := make(map[string]bool)
+	for _, m := range pack.Movements {
+		if names[m.Name] {
+			t.Errorf("movement %s is listed twice", m.Name)
+		}
+		names[m.Name] = true
+	}
+
+	tests := []struct {
+		name  string
+		files fstest.MapFS
+	}{
+		{"no seed files", fstest.MapFS{"README.md": {Data: []byte("hi")}}},
+		{"invalid movement type", fstest.MapFS{"movements.csv": {Data: []byte("name,type\nSled Drag,strongman\n")}}},
+		{"WOD without a name", fstest.MapFS{"wods.csv": {Data: []byte("name,type\n,Girl\n")}}},
+		{"movement and WOD in one row", fstest.MapFS{"templates.csv": {Data: []byte("workout,movement,wod\nFran,Thruster,Fran\n")}}},
+		{"invalid number", fstest.MapFS{"templates.csv": {Data: []byte("workout,movement,sets\nSquats,Back Squat,five\n")}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := LoadSeedPack("test", tt.files); err == nil {
+				t.Error("expected the pack to be rejected")
+			}
+		})
+	}
+}
+
+func TestSeeder(t *testing.T) {
+	sqlDB, err := InitDatabase("sqlite3", t.TempDir()+"/actalog.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sqlDB.Close()
+	ctx := context.Background()
+	seeder := NewSeeder(sqlDB)
+
+	// Seeding again on startup changes nothing
+	standard, err := LoadSeedPack("standard", seeds.Standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := seeder.Seed(ctx, standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := SeedResult{
+		Movements: SeedCounts{Unchanged: len(standard.Movements)},
+		WODs:      SeedCounts{Unchanged: len(standard.WODs)},
+		Templates: SeedCounts{Unchanged: len(standard.Templates)},
+	}
+	if *result != want {
+		t.Errorf("expected everything unchanged, got %+v", *result)
+	}
+
+	// A user's own movement, and a log of a template
+	if _, err := sqlDB.Exec(`INSERT INTO users (id, email, password_hash, name, role, created_at, updated_at) VALUES (1, 'a@example.com', 'x', 'A', 'user', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sqlDB.Exec(`INSERT INTO movements (name, description, type, is_standard, created_by, created_at, updated_at) VALUES ('Sandbag Carry', 'Mine', 'cardio', 0, 1, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`); err != nil {
+		t.Fatal(err)
+	}
+	var templateID int64
+	if err := sqlDB.QueryRow(`SELECT id FROM workouts WHERE name = 'Gymnastics Strength'`).Scan(&templateID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sqlDB.Exec(`INSERT INTO user_workouts (user_id, workout_id, workout_date, created_at, updated_at) VALUES (1, ?, DATE('now'), CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`, templateID); err != nil {
+		t.Fatal(err)
+	}
+
+	// A gym's pack updates Fran, adds a benchmark and changes a template
+	pack, err := LoadSeedPack("gym", fstest.MapFS{
+		"movements.csv": {Data: []byte("name,description,type\nSandbag Carry,Carry a sandbag,weightlifting\nSled Drag,Drag a sled,weightlifting\n")},
+		"wods.csv":      {Data: []byte("name,source,type,regime,score_type,description\nFran,CrossFit,Girl,Fastest Time,Time (HH:MM:SS),21-15-9 Thrusters and Pull-ups\nThe Yard,Our Gym,Benchmark,Fastest Time,Time (HH:MM:SS),5 rounds: 50m Sled Drag\n")},
+		"templates.csv": {Data: []byte("workout,notes,movement,wod,weight,sets,reps\nGymnastics Strength,Pulling only,Pull-up,,0,5,10\nThe Yard,,,The Yard,,,\nThe Yard,,Sled Drag,,90,5,\n")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err = seeder.Seed(ctx, pack)
+	if err != nil {
+		t.Fatalf("Seed failed: %v", err)
+	}
+	want = SeedResult{
+		Movements: SeedCounts{Inserted: 1, Skipped: 1},
+		WODs:      SeedCounts{Inserted: 1, Updated: 1},
+		Templates: SeedCounts{Inserted: 1, Updated: 1},
+	}
+	if *result != want {
+		t.Errorf("expected %+v, got %+v", want, *result)
+	}
+
+	var description string
+	sqlDB.QueryRow(`SELECT description FROM movements WHERE name = 'Sandbag Carry'`).Scan(&description)
+	if description != "Mine" {
+		t.Errorf("expected the user's movement to be left alone, got %q", description)
+	}
+	sqlDB.QueryRow(`SELECT description FROM wods WHERE name = 'Fran'`).Scan(&description)
+	if description != "21-15-9 Thrusters and Pull-ups" {
+		t.Errorf("expected Fran to be updated, got %q", description)
+	}
+
+	// The updated template keeps its ID, so the logged workout still refers to it
+	var id int64
+	var movements int
+	sqlDB.QueryRow(`SELECT id FROM workouts WHERE name = 'Gymnastics Strength' AND created_by IS NULL`).Scan(&id)
+	sqlDB.QueryRow(`SELECT COUNT(*) FROM workout_movements WHERE workout_id = ?`, id).Scan(&movements)
+	if id != templateID || movements != 1 {
+		t.Errorf("expected template %d updated to 1 movement, got template %d with %d", templateID, id, movements)
+	}
+
+	// Seeding the pack again is a no-op
+	result, err = seeder.Seed(ctx, pack)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Movements.Inserted+result.Movements.Updated+result.WODs.Inserted+result.WODs.Updated+result.Templates.Inserted+result.Templates.Updated != 0 {
+		t.Errorf("expected a second seeding to change nothing, got %+v", *result)
+	}
+
+	// A template naming an unknown movement fails without writing anything
+	broken, err := LoadSeedPack("broken", fstest.MapFS{
+		"wods.csv":      {Data: []byte("name\nNew WOD\n")},
+		"templates.csv": {Data: []byte("workout,movement\nTypo,Bak Squat\n")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := seeder.Seed(ctx, broken); err == nil {
+		t.Error("expected an unknown movement to fail")
+	}
+	var count int
+	sqlDB.QueryRow(`SELECT COUNT(*) FROM wods WHERE name = 'New WOD'`).Scan(&count)
+	if count != 0 {
+		t.Error("expected a failed pack to be rolled back")
+	}
+
+	// Nor may a template use a user's own movement
+	private, err := LoadSeedPack("private", fstest.MapFS{
+		"templates.csv": {Data: []byte("workout,movement\nCarries,Sandbag Carry\n")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := seeder.Seed(ctx, private); err == nil || !strings.Contains(err.Error(), "no standard movement named Sandbag Carry") {
+		t.Errorf("expected a user's movement to be refused, got %v", err)
+	}
+	sqlDB.QueryRow(`SELECT COUNT(*) FROM workouts WHERE name = 'Carries'`).Scan(&count)
+	if count != 0 {
+		t.Error("expected no template using a user's movement")
+	}
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/johnzastrow/actalog/internal/domain"
+)
+
+// SigningKeyRepository implements domain.SigningKeyRepository
+type SigningKeyRepository struct {
+	db *DB
+}
+
+// NewSigningKeyRepository creates a new signing key repository
+func NewSigningKeyRepository(db *sql.DB) *SigningKeyRepository {
+	return &SigningKeyRepository{db: NewDB(db)}
+}
+
+// Create stores a new signing key
+func (r *SigningKeyRepository) Create(ctx context.Context, key *domain.SigningKey) error {
+	query := `INSERT INTO jwt_signing_keys (kid, algorithm, private_key, created_at, activates_at)
+	          VALUES (?, ?, ?, ?, ?)`
+
+	id, err := r.db.InsertContext(ctx, query, key.KeyID, key.Algorithm, key.PrivateKey, key.CreatedAt, key.ActivatesAt)
+	if err != nil {
+		return fmt.Errorf("failed to create signing key: %w", err)
+	}
+	key.ID = id
+	return nil
+}
+
+// List retrieves all signing keys, oldest activation first
+func (r *SigningKeyRepository) List(ctx context.Context) ([]*domain.SigningKey, error) {
+	query := `SELECT id, kid, algorithm, private_key, created_at, activates_at
+	          FROM jwt_signing_keys
+	          ORDER BY activates_at, id`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list signing keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []*domain.SigningKey{}
+	for rows.Next() {
+		key := &domain.SigningKey{}
+		if err := rows.Scan(&key.ID, &key.KeyID, &key.Algorithm, &key.PrivateKey, &key.CreatedAt, &key.ActivatesAt); err != nil {
+			return nil, fmt.Errorf("failed to scan signing key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// UpdatePrivateKey replaces the stored private key
+func (r *SigningKeyRepository) UpdatePrivateKey(ctx context.Context, id int64, privateKey string) error {
+	if _, err := r.db.ExecContext(ctx, `UPDATE jwt_signing_keys SET private_key = ? WHERE id = ?`, privateKey, id); err != nil {
+		return fmt.Errorf("failed to update signing key: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a signing key
+func (r *SigningKeyRepository) Delete(ctx context.Context, id int64) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM jwt_signing_keys WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete signing key: %w", err)
+	}
+	return nil
+}
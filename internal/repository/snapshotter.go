@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/johnzastrow/actalog/internal/domain"
+)
+
+// Snapshotter copies a SQLite database with VACUUM INTO, which writes a
+// consistent, compacted copy in one read transaction, so the server keeps
+// running while it is taken
+type Snapshotter struct {
+	db *DB
+}
+
+// NewSnapshotter creates a snapshotter for a database
+func NewSnapshotter(db *sql.DB) *Snapshotter {
+	return &Snapshotter{db: NewDB(db)}
+}
+
+// Driver returns the database/sql driver name of the database
+func (s *Snapshotter) Driver() string {
+	return string(s.db.Dialect)
+}
+
+// Snapshot writes a copy of the database to path, which must not exist
+func (s *Snapshotter) Snapshot(ctx context.Context, path string) error {
+	if s.db.Dialect != DialectSQLite {
+		return domain.ErrBackupUnsupported
+	}
+	if _, err := s.db.ExecContext(ctx, `VACUUM INTO ?`, path); err != nil {
+		return fmt.Errorf("failed to snapshot database: %w", err)
+	}
+	return nil
+}
+
+// SchemaVersion returns the newest migration applied to the database, or 0
+// if it hasn't been migrated
+func (s *Snapshotter) SchemaVersion(ctx context.Context) (int, error) {
+	return appliedVersion(ctx, s.db.DB)
+}
+
+// Verify checks a SQLite database file with PRAGMA integrity_check and
+// makes sure it has no migrations this release doesn't know about
+func (s *Snapshotter) Verify(ctx context.Context, path string) error {
+	if s.db.Dialect != DialectSQLite {
+		return domain.ErrBackupUnsupported
+	}
+	// mode=ro keeps a missing file from being created as an empty database
+	db, err := sql.Open("sqlite3", "file:"+path+"?mode=ro")
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	var result string
+	if err := db.QueryRowContext(ctx, `PRAGMA integrity_check`).Scan(&result); err != nil {
+		return fmt.Errorf("failed to check database: %w", err)
+	}
+	if result != "ok" {
+		return fmt.Errorf("database is damaged: %s", result)
+	}
+
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		return err
+	}
+	statuses, err := migrator.Status(ctx)
+	if errors.Is(err, ErrLegacyMigrations) {
+		// Upgraded when the server next starts
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read migrations: %w", err)
+	}
+	for _, status := range statuses {
+		if status.Unknown {
+			return fmt.Errorf("database has migration %s, which this release doesn't have; restore it with a newer release", status.Migration)
+		}
+	}
+	return nil
+}
+
+// appliedVersion returns the newest migration applied to a database, or 0
+// if it hasn't been migrated
+func appliedVersion(ctx context.Context, db *sql.DB) (int, error) {
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		return 0, err
+	}
+	statuses, err := migrator.Status(ctx)
+	if errors.Is(err, ErrLegacyMigrations) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read migrations: %w", err)
+	}
+	version := 0
+	for _, status := range statuses {
+		if status.Applied {
+			version = status.Version
+		}
+	}
+	return version, nil
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSnapshotter(t *testing.T) {
+	dir := t.TempDir()
+	sqlDB, err := sql.Open("sqlite3", filepath.Join(dir, "actalog.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sqlDB.Close()
+	ctx := context.Background()
+
+	migrator, err := NewMigrator(sqlDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sqlDB.Exec(`INSERT INTO users (email, password_hash, name, role, created_at, updated_at) VALUES ('a@example.com', 'x', 'A', 'user', ?, ?)`, time.Now(), time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshotter := NewSnapshotter(sqlDB)
+	version, err := snapshotter.SchemaVersion(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := migrator.migrations[len(migrator.migrations)-1].Version; version != want {
+		t.Errorf("expected schema version %d, got %d", want, version)
+	}
+
+	// The snapshot is a working copy of the database
+	snapshot := filepath.Join(dir, "snapshot.db")
+	if err := snapshotter.Snapshot(ctx, snapshot); err != nil {
+		t.Fatal(err)
+	}
+	if err := snapshotter.Verify(ctx, snapshot); err != nil {
+		t.Errorf("expected the snapshot to verify, got %v", err)
+	}
+	copied, err := sql.Open("sqlite3", snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer copied.Close()
+	var count int
+	if err := copied.QueryRow(`SELECT COUNT(*) FROM users`).Scan(&count); err != nil || count != 1 {
+		t.Errorf("expected the user in the snapshot, got %d (%v)", count, err)
+	}
+
+	// A snapshot from a newer release can't be restored
+	if _, err := copied.Exec(`INSERT INTO schema_migrations (version, name, checksum, applied_at) VALUES (999, 'newer', '', ?)`, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if err := snapshotter.Verify(ctx, snapshot); err == nil {
+		t.Error("expected a snapshot with unknown migrations to be rejected")
+	}
+
+	// Nor can a file that isn't a database
+	garbage := filepath.Join(dir, "garbage.db")
+	if err := os.WriteFile(garbage, []byte("not a database"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := snapshotter.Verify(ctx, garbage); err == nil {
+		t.Error("expected a file that isn't a database to be rejected")
+	}
+	if err := snapshotter.Verify(ctx, filepath.Join(dir, "missing.db")); err == nil {
+		t.Error("expected a missing file to be rejected")
+	}
+}
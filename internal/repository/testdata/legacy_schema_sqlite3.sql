@@ -0,0 +1,127 @@
+-- The schema releases before 0.4.0 created on startup, which the legacy
+-- migrations upgrade from. Kept to test that upgrade.
+CREATE TABLE IF NOT EXISTS users (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	email TEXT UNIQUE NOT NULL,
+	password_hash TEXT NOT NULL,
+	name TEXT NOT NULL,
+	profile_image TEXT,
+	birthday DATE,
+	role TEXT NOT NULL DEFAULT 'user',
+	email_verified INTEGER NOT NULL DEFAULT 0,
+	email_verified_at DATETIME,
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL,
+	last_login_at DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS idx_users_email ON users(email);
+CREATE INDEX IF NOT EXISTS idx_users_role ON users(role);
+
+CREATE TABLE IF NOT EXISTS workouts (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	notes TEXT,
+	created_by INTEGER,
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL,
+	FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_workouts_created_by ON workouts(created_by);
+CREATE INDEX IF NOT EXISTS idx_workouts_name ON workouts(name);
+
+CREATE TABLE IF NOT EXISTS movements (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT UNIQUE NOT NULL,
+	description TEXT,
+	type TEXT NOT NULL,
+	is_standard INTEGER NOT NULL DEFAULT 0,
+	created_by INTEGER,
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL,
+	FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_movements_name ON movements(name);
+CREATE INDEX IF NOT EXISTS idx_movements_type ON movements(type);
+CREATE INDEX IF NOT EXISTS idx_movements_standard ON movements(is_standard);
+
+CREATE TABLE IF NOT EXISTS wods (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT UNIQUE NOT NULL,
+	source TEXT,
+	type TEXT,
+	regime TEXT,
+	score_type TEXT,
+	description TEXT,
+	url TEXT,
+	notes TEXT,
+	is_standard INTEGER NOT NULL DEFAULT 0,
+	created_by INTEGER,
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL,
+	FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_wods_name ON wods(name);
+CREATE INDEX IF NOT EXISTS idx_wods_type ON wods(type);
+CREATE INDEX IF NOT EXISTS idx_wods_is_standard ON wods(is_standard);
+
+CREATE TABLE IF NOT EXISTS workout_wods (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	workout_id INTEGER NOT NULL,
+	wod_id INTEGER NOT NULL,
+	score_value TEXT,
+	division TEXT,
+	is_pr INTEGER NOT NULL DEFAULT 0,
+	order_index INTEGER NOT NULL DEFAULT 0,
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL,
+	FOREIGN KEY (workout_id) REFERENCES workouts(id) ON DELETE CASCADE,
+	FOREIGN KEY (wod_id) REFERENCES wods(id) ON DELETE RESTRICT
+);
+
+CREATE INDEX IF NOT EXISTS idx_workout_wods_workout_id ON workout_wods(workout_id);
+CREATE INDEX IF NOT EXISTS idx_workout_wods_wod_id ON workout_wods(wod_id);
+
+CREATE TABLE IF NOT EXISTS user_workouts (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL,
+	workout_id INTEGER NOT NULL,
+	workout_date DATE NOT NULL,
+	workout_type TEXT,
+	total_time INTEGER,
+	notes TEXT,
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+	FOREIGN KEY (workout_id) REFERENCES workouts(id) ON DELETE RESTRICT
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_workouts_user_id ON user_workouts(user_id);
+CREATE INDEX IF NOT EXISTS idx_user_workouts_workout_date ON user_workouts(workout_date);
+CREATE INDEX IF NOT EXISTS idx_user_workouts_user_date ON user_workouts(user_id, workout_date DESC);
+
+CREATE TABLE IF NOT EXISTS workout_movements (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	workout_id INTEGER NOT NULL,
+	movement_id INTEGER NOT NULL,
+	weight REAL,
+	sets INTEGER,
+	reps INTEGER,
+	time INTEGER,
+	distance REAL,
+	is_rx INTEGER NOT NULL DEFAULT 0,
+	is_pr INTEGER NOT NULL DEFAULT 0,
+	notes TEXT,
+	order_index INTEGER NOT NULL DEFAULT 0,
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL,
+	FOREIGN KEY (workout_id) REFERENCES workouts(id) ON DELETE CASCADE,
+	FOREIGN KEY (movement_id) REFERENCES movements(id) ON DELETE RESTRICT
+);
+
+CREATE INDEX IF NOT EXISTS idx_wm_workout_id ON workout_movements(workout_id);
+CREATE INDEX IF NOT EXISTS idx_wm_movement_id ON workout_movements(movement_id);
+CREATE INDEX IF NOT EXISTS idx_wm_workout_order ON workout_movements(workout_id, order_index);
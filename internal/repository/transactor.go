@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// txKey is the context key of the transaction in progress
+type txKey struct{}
+
+// ctxTx is a transaction carried by a context, with the connection it belongs to
+type ctxTx struct {
+	db *sql.DB
+	tx *sql.Tx
+}
+
+// txFrom returns the transaction on db carried by ctx, if any
+func txFrom(ctx context.Context, db *sql.DB) *sql.Tx {
+	t, ok := ctx.Value(txKey{}).(*ctxTx)
+	if !ok || t.db != db {
+		return nil
+	}
+	return t.tx
+}
+
+// Transactor implements domain.Transactor. While fn runs, every DB on the
+// same connection runs the queries it's given fn's context for in the
+// transaction, so repositories take part without knowing about it.
+type Transactor struct {
+	db *sql.DB
+}
+
+// NewTransactor creates a transactor for a connection
+func NewTransactor(db *sql.DB) *Transactor {
+	return &Transactor{db: db}
+}
+
+// WithinTransaction calls fn in a transaction, committing it if fn returns nil
+// and rolling it back if fn returns an error or panics. If ctx already carries
+// a transaction, fn joins it and the outermost call commits.
+func (t *Transactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if txFrom(ctx, t.db) != nil {
+		return fn(ctx)
+	}
+
+	tx, err := t.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(context.WithValue(ctx, txKey{}, &ctxTx{db: t.db, tx: tx})); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/johnzastrow/actalog/internal/domain"
+)
+
+func TestTransactor(t *testing.T) {
+	sqlDB, err := InitDatabase("sqlite3", t.TempDir()+"/actalog.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sqlDB.Close()
+
+	transactor := NewTransactor(sqlDB)
+	workouts := NewUserWorkoutRepository(sqlDB)
+	movements := NewUserWorkoutMovementRepository(sqlDB)
+	ctx := context.Background()
+
+	standard, err := NewMovementRepository(sqlDB).ListStandard(ctx)
+	if err != nil || len(standard) == 0 {
+		t.Fatalf("expected seeded movements, got %v", err)
+	}
+
+	template := &domain.Workout{Name: "Template"}
+	if err := NewWorkoutRepository(sqlDB).Create(ctx, template); err != nil {
+		t.Fatal(err)
+	}
+
+	// logWorkout saves a workout and its movements, batched in a transaction
+	// of the movement repository's own
+	logWorkout := func(ctx context.Context, name string) error {
+		workout := &domain.UserWorkout{UserID: 1, WorkoutID: &template.ID, WorkoutName: &name, WorkoutDate: time.Now()}
+		if err := workouts.Create(ctx, workout); err != nil {
+			return err
+		}
+		return movements.CreateBatch(ctx, []*domain.UserWorkoutMovement{
+			{UserWorkoutID: workout.ID, MovementID: standard[0].ID},
+			{UserWorkoutID: workout.ID, MovementID: standard[0].ID},
+		})
+	}
+	count := func(table string) int {
+		var n int
+		if err := sqlDB.QueryRow(`SELECT COUNT(*) FROM ` + table).Scan(&n); err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+
+	err = transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		return logWorkout(ctx, "Committed")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count("user_workouts") != 1 || count("user_workout_movements") != 2 {
+		t.Fatalf("expected the workout and its movements to be committed, got %d and %d", count("user_workouts"), count("user_workout_movements"))
+	}
+
+	// An error rolls back every repository's writes, including the batch
+	// that committed its own joined transaction
+	errFailed := errors.New("failed")
+	err = transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := logWorkout(ctx, "Rolled back"); err != nil {
+			return err
+		}
+		return errFailed
+	})
+	if !errors.Is(err, errFailed) {
+		t.Errorf("expected fn's error, got %v", err)
+	}
+	if count("user_workouts") != 1 || count("user_workout_movements") != 2 {
+		t.Errorf("expected the failed unit of work to be rolled back, got %d workouts and %d movements", count("user_workouts"), count("user_workout_movements"))
+	}
+
+	// Nested calls join the outer transaction
+	err = transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		err := transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+			return logWorkout(ctx, "Nested")
+		})
+		if err != nil {
+			return err
+		}
+		return errFailed
+	})
+	if !errors.Is(err, errFailed) || count("user_workouts") != 1 {
+		t.Errorf("expected the nested unit of work to be rolled back with the outer one, got %v and %d workouts", err, count("user_workouts"))
+	}
+
+	// A panic rolls back and carries on
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected the panic to be re-raised")
+			}
+		}()
+		_ = transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+			if err := logWorkout(ctx, "Panicked"); err != nil {
+				return err
+			}
+			panic("boom")
+		})
+	}()
+	if count("user_workouts") != 1 {
+		t.Errorf("expected a panic to roll back, got %d workouts", count("user_workouts"))
+	}
+}
@@ -1,8 +1,10 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/johnzastrow/actalog/internal/domain"
@@ -10,142 +12,148 @@ import (
 
 // SQLiteUserRepository implements UserRepository using SQLite
 type SQLiteUserRepository struct {
-	db *sql.DB
+	db *DB
 }
 
 // NewSQLiteUserRepository creates a new SQLite user repository
 func NewSQLiteUserRepository(db *sql.DB) *SQLiteUserRepository {
-	return &SQLiteUserRepository{db: db}
+	return &SQLiteUserRepository{db: NewDB(db)}
 }
 
-// Create creates a new user
-func (r *SQLiteUserRepository) Create(user *domain.User) error {
-	query := `
-		INSERT INTO users (email, password_hash, name, role, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?)
-	`
-
-	result, err := r.db.Exec(
-		query,
-		user.Email,
-		user.PasswordHash,
-		user.Name,
-		user.Role,
-		user.CreatedAt,
-		user.UpdatedAt,
-	)
-	if err != nil {
-		return err
-	}
-
-	id, err := result.LastInsertId()
-	if err != nil {
-		return err
-	}
-
-	user.ID = id
-	return nil
-}
-
-// GetByID retrieves a user by ID
-func (r *SQLiteUserRepository) GetByID(id int64) (*domain.User, error) {
-	query := `
-		SELECT id, email, password_hash, name, profile_image, role,
-		       created_at, updated_at, last_login_at
-		FROM users
-		WHERE id = ?
-	`
+// userColumns lists the columns read by scanUser, in order
+const userColumns = `id, email, password_hash, name, profile_image, role, locale,
+	       email_verified, email_verified_at,
+	       verification_token, verification_token_expires_at,
+	       reset_token, reset_token_expires_at,
+	       disabled, created_at, updated_at, last_login_at`
 
+// scanUser reads a row selected with userColumns
+func scanUser(scanner interface{ Scan(...interface{}) error }) (*domain.User, error) {
 	user := &domain.User{}
-	var lastLoginAt sql.NullTime
+	var lastLoginAt, emailVerifiedAt, verificationExpiresAt, resetExpiresAt sql.NullTime
+	var verificationToken, resetToken sql.NullString
 
-	err := r.db.QueryRow(query, id).Scan(
+	err := scanner.Scan(
 		&user.ID,
 		&user.Email,
 		&user.PasswordHash,
 		&user.Name,
 		&user.ProfileImage,
 		&user.Role,
+		&user.Locale,
+		&user.EmailVerified,
+		&emailVerifiedAt,
+		&verificationToken,
+		&verificationExpiresAt,
+		&resetToken,
+		&resetExpiresAt,
+		&user.Disabled,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 		&lastLoginAt,
 	)
-
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, nil
-		}
 		return nil, err
 	}
 
 	if lastLoginAt.Valid {
 		user.LastLoginAt = &lastLoginAt.Time
 	}
+	if emailVerifiedAt.Valid {
+		user.EmailVerifiedAt = &emailVerifiedAt.Time
+	}
+	if verificationToken.Valid {
+		user.VerificationToken = &verificationToken.String
+	}
+	if verificationExpiresAt.Valid {
+		user.VerificationTokenExpiresAt = &verificationExpiresAt.Time
+	}
+	if resetToken.Valid {
+		user.ResetToken = &resetToken.String
+	}
+	if resetExpiresAt.Valid {
+		user.ResetTokenExpiresAt = &resetExpiresAt.Time
+	}
 
 	return user, nil
 }
 
-// GetByEmail retrieves a user by email
-func (r *SQLiteUserRepository) GetByEmail(email string) (*domain.User, error) {
-	query := `
-		SELECT id, email, password_hash, name, profile_image, role,
-		       created_at, updated_at, last_login_at
-		FROM users
-		WHERE email = ?
-	`
-
-	user := &domain.User{}
-	var lastLoginAt sql.NullTime
-
-	err := r.db.QueryRow(query, email).Scan(
-		&user.ID,
-		&user.Email,
-		&user.PasswordHash,
-		&user.Name,
-		&user.ProfileImage,
-		&user.Role,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-		&lastLoginAt,
-	)
-
+// getOne returns the single user matched by a WHERE clause, or nil
+func (r *SQLiteUserRepository) getOne(ctx context.Context, where string, args ...interface{}) (*domain.User, error) {
+	user, err := scanUser(r.db.QueryRowContext(ctx, `SELECT `+userColumns+` FROM users WHERE `+where, args...))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
 		}
 		return nil, err
 	}
+	return user, nil
+}
 
-	if lastLoginAt.Valid {
-		user.LastLoginAt = &lastLoginAt.Time
+// Create creates a new user
+func (r *SQLiteUserRepository) Create(ctx context.Context, user *domain.User) error {
+	query := `
+		INSERT INTO users (email, password_hash, name, role, locale, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+
+	if user.Locale == "" {
+		user.Locale = domain.DefaultLocale
 	}
 
-	return user, nil
+	id, err := r.db.InsertContext(ctx,
+		query,
+		user.Email,
+		user.PasswordHash,
+		user.Name,
+		user.Role,
+		user.Locale,
+		user.CreatedAt,
+		user.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	user.ID = id
+	return nil
+}
+
+// GetByID retrieves a user by ID
+func (r *SQLiteUserRepository) GetByID(ctx context.Context, id int64) (*domain.User, error) {
+	return r.getOne(ctx, `id = ?`, id)
 }
 
-// GetByResetToken retrieves a user by reset token
-// NOTE: This method is currently not functional as reset_token columns don't exist in the database.
-// The system should use the separate password_resets table instead.
-func (r *SQLiteUserRepository) GetByResetToken(token string) (*domain.User, error) {
-	// This functionality is not implemented - reset tokens should use a separate table
-	return nil, nil
+// GetByEmail retrieves a user by email
+func (r *SQLiteUserRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	return r.getOne(ctx, `email = ?`, email)
 }
 
-// GetByVerificationToken retrieves a user by verification token
-// NOTE: This method is currently not functional as verification_token columns don't exist in the database.
-// The system should use the separate email_verification_tokens table instead.
-func (r *SQLiteUserRepository) GetByVerificationToken(token string) (*domain.User, error) {
-	// This functionality is not implemented - verification tokens should use a separate table
-	return nil, nil
+// GetByResetToken retrieves a user by password reset token
+func (r *SQLiteUserRepository) GetByResetToken(ctx context.Context, token string) (*domain.User, error) {
+	if token == "" {
+		return nil, nil
+	}
+	return r.getOne(ctx, `reset_token = ?`, token)
+}
+
+// GetByVerificationToken retrieves a user by email verification token
+func (r *SQLiteUserRepository) GetByVerificationToken(ctx context.Context, token string) (*domain.User, error) {
+	if token == "" {
+		return nil, nil
+	}
+	return r.getOne(ctx, `verification_token = ?`, token)
 }
 
 // Update updates a user
-func (r *SQLiteUserRepository) Update(user *domain.User) error {
+func (r *SQLiteUserRepository) Update(ctx context.Context, user *domain.User) error {
 	query := `
 		UPDATE users
 		SET email = ?, name = ?, profile_image = ?, role = ?,
 		    updated_at = ?, last_login_at = ?, password_hash = ?,
-		    email_verified = ?, email_verified_at = ?
+		    email_verified = ?, email_verified_at = ?, locale = ?,
+		    verification_token = ?, verification_token_expires_at = ?,
+		    reset_token = ?, reset_token_expires_at = ?, disabled = ?
 		WHERE id = ?
 	`
 
@@ -164,9 +172,25 @@ func (r *SQLiteUserRepository) Update(user *domain.User) error {
 		profileImage = *user.ProfileImage
 	}
 
+	var verificationToken, verificationExpiresAt interface{}
+	if user.VerificationToken != nil {
+		verificationToken = *user.VerificationToken
+	}
+	if user.VerificationTokenExpiresAt != nil {
+		verificationExpiresAt = *user.VerificationTokenExpiresAt
+	}
+
+	var resetToken, resetExpiresAt interface{}
+	if user.ResetToken != nil {
+		resetToken = *user.ResetToken
+	}
+	if user.ResetTokenExpiresAt != nil {
+		resetExpiresAt = *user.ResetTokenExpiresAt
+	}
+
 	user.UpdatedAt = time.Now()
 
-	_, err := r.db.Exec(
+	_, err := r.db.ExecContext(ctx,
 		query,
 		user.Email,
 		user.Name,
@@ -177,6 +201,12 @@ func (r *SQLiteUserRepository) Update(user *domain.User) error {
 		user.PasswordHash,
 		user.EmailVerified,
 		emailVerifiedAt,
+		user.Locale,
+		verificationToken,
+		verificationExpiresAt,
+		resetToken,
+		resetExpiresAt,
+		user.Disabled,
 		user.ID,
 	)
 
@@ -184,30 +214,112 @@ func (r *SQLiteUserRepository) Update(user *domain.User) error {
 }
 
 // UpdatePassword updates only the password for a user
-func (r *SQLiteUserRepository) UpdatePassword(userID int64, hashedPassword string) error {
+func (r *SQLiteUserRepository) UpdatePassword(ctx context.Context, userID int64, hashedPassword string) error {
 	query := `UPDATE users SET password_hash = ?, updated_at = ? WHERE id = ?`
-	_, err := r.db.Exec(query, hashedPassword, time.Now(), userID)
+	_, err := r.db.ExecContext(ctx, query, hashedPassword, time.Now(), userID)
 	return err
 }
 
-// Delete deletes a user
-func (r *SQLiteUserRepository) Delete(id int64) error {
-	query := `DELETE FROM users WHERE id = ?`
-	_, err := r.db.Exec(query, id)
-	return err
+// userDeleteQueries delete a user and everything they own, in an order the
+// RESTRICT foreign keys allow. Custom movements, WODs and templates are
+// deleted explicitly: their created_by is ON DELETE SET NULL, and a NULL
+// creator would turn them into standard entries visible to everyone.
+var userDeleteQueries = []string{
+	`DELETE FROM user_workouts WHERE user_id = ?`,
+	`DELETE FROM workouts WHERE created_by = ?`,
+	`DELETE FROM wods WHERE created_by = ?`,
+	`DELETE FROM movements WHERE created_by = ?`,
+	`DELETE FROM users WHERE id = ?`,
+}
+
+// Delete deletes a user along with everything they own, in one transaction.
+// The schema declares ON DELETE CASCADE on every other user-owned table, but
+// SQLite only honours it when foreign keys are enabled on the connection, so
+// they are switched on for the delete.
+func (r *SQLiteUserRepository) Delete(ctx context.Context, id int64) error {
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if r.db.Dialect == DialectSQLite {
+		if _, err := conn.ExecContext(ctx, `PRAGMA foreign_keys = ON`); err != nil {
+			return err
+		}
+		// The connection goes back to the pool even if ctx is cancelled
+		defer conn.ExecContext(context.WithoutCancel(ctx), `PRAGMA foreign_keys = OFF`)
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, query := range userDeleteQueries {
+		if _, err := tx.ExecContext(ctx, r.db.Dialect.Rebind(query), id); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
 }
 
 // List retrieves a list of users with pagination
-func (r *SQLiteUserRepository) List(limit, offset int) ([]*domain.User, error) {
-	query := `
-		SELECT id, email, password_hash, name, profile_image, role,
-		       created_at, updated_at, last_login_at
-		FROM users
-		ORDER BY created_at DESC
-		LIMIT ? OFFSET ?
-	`
+func (r *SQLiteUserRepository) List(ctx context.Context, limit, offset int) ([]*domain.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users ORDER BY created_at DESC LIMIT ? OFFSET ?`
+	return r.query(ctx, query, limit, offset)
+}
+
+// Count returns the total number of users
+func (r *SQLiteUserRepository) Count(ctx context.Context) (int64, error) {
+	query := `SELECT COUNT(*) FROM users`
+	var count int64
+	err := r.db.QueryRowContext(ctx, query).Scan(&count)
+	return count, err
+}
+
+// Search returns a page of users matching the filter and the total number of
+// matches
+func (r *SQLiteUserRepository) Search(ctx context.Context, filter domain.UserFilter) ([]*domain.User, int64, error) {
+	var conditions []string
+	var args []interface{}
+
+	if q := strings.TrimSpace(filter.Query); q != "" {
+		pattern := "%" + strings.ToLower(q) + "%"
+		conditions = append(conditions, `(LOWER(email) LIKE ? OR LOWER(name) LIKE ?)`)
+		args = append(args, pattern, pattern)
+	}
+	if filter.Role != "" {
+		conditions = append(conditions, `role = ?`)
+		args = append(args, filter.Role)
+	}
+	if filter.Disabled != nil {
+		conditions = append(conditions, `disabled = ?`)
+		args = append(args, *filter.Disabled)
+	}
 
-	rows, err := r.db.Query(query, limit, offset)
+	where := ""
+	if len(conditions) > 0 {
+		where = ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+
+	var total int64
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM users`+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT ` + userColumns + ` FROM users` + where + ` ORDER BY created_at DESC, id DESC LIMIT ? OFFSET ?`
+	users, err := r.query(ctx, query, append(args, filter.Limit, filter.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+// query runs a SELECT of userColumns and scans every row
+func (r *SQLiteUserRepository) query(ctx context.Context, query string, args ...interface{}) ([]*domain.User, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -215,38 +327,12 @@ func (r *SQLiteUserRepository) List(limit, offset int) ([]*domain.User, error) {
 
 	var users []*domain.User
 	for rows.Next() {
-		user := &domain.User{}
-		var lastLoginAt sql.NullTime
-
-		err := rows.Scan(
-			&user.ID,
-			&user.Email,
-			&user.PasswordHash,
-			&user.Name,
-			&user.ProfileImage,
-			&user.Role,
-			&user.CreatedAt,
-			&user.UpdatedAt,
-			&lastLoginAt,
-		)
+		user, err := scanUser(rows)
 		if err != nil {
 			return nil, err
 		}
-
-		if lastLoginAt.Valid {
-			user.LastLoginAt = &lastLoginAt.Time
-		}
-
 		users = append(users, user)
 	}
 
 	return users, rows.Err()
 }
-
-// Count returns the total number of users
-func (r *SQLiteUserRepository) Count() (int64, error) {
-	query := `SELECT COUNT(*) FROM users`
-	var count int64
-	err := r.db.QueryRow(query).Scan(&count)
-	return count, err
-}
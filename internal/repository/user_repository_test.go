@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestUserRepositoryDeleteRemovesOwnedCatalogue(t *testing.T) {
+	sqlDB, err := InitDatabase("sqlite3", filepath.Join(t.TempDir(), "actalog.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sqlDB.Close()
+	repo := NewSQLiteUserRepository(sqlDB)
+
+	count := func(query string, args ...interface{}) int {
+		t.Helper()
+		var n int
+		if err := sqlDB.QueryRow(query, args...).Scan(&n); err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+	standardWorkouts := count(`SELECT COUNT(*) FROM workouts WHERE created_by IS NULL`)
+	standardMovements := count(`SELECT COUNT(*) FROM movements WHERE created_by IS NULL`)
+	standardWODs := count(`SELECT COUNT(*) FROM wods WHERE created_by IS NULL`)
+
+	for _, query := range []string{
+		`INSERT INTO users (id, email, password_hash, name, role, created_at, updated_at) VALUES (1, 'a@example.com', 'x', 'A', 'user', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`,
+		`INSERT INTO users (id, email, password_hash, name, role, created_at, updated_at) VALUES (2, 'b@example.com', 'x', 'B', 'user', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`,
+		`INSERT INTO movements (id, name, type, is_standard, created_by, created_at, updated_at) VALUES (1000, 'Zercher Squat', 'weightlifting', 0, 1, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`,
+		`INSERT INTO movements (id, name, type, is_standard, created_by, created_at, updated_at) VALUES (1001, 'Jefferson Curl', 'weightlifting', 0, 2, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`,
+		`INSERT INTO wods (id, name, source, type, regime, score_type, is_standard, created_by, created_at, updated_at) VALUES (1000, 'Secret WOD', 'Self-recorded', 'Self-created', 'AMRAP', 'Rounds+Reps', 0, 1, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`,
+		`INSERT INTO workouts (id, name, created_by, created_at, updated_at) VALUES (1000, 'Private Template', 1, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`,
+		`INSERT INTO workout_movements (workout_id, movement_id, order_index, created_at, updated_at) VALUES (1000, 1000, 0, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`,
+		`INSERT INTO workout_wods (workout_id, wod_id, order_index, created_at, updated_at) VALUES (1000, 1000, 1, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`,
+		`INSERT INTO user_workouts (id, user_id, workout_id, workout_date, created_at, updated_at) VALUES (1000, 1, 1000, DATE('2025-10-06'), CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`,
+		`INSERT INTO user_workout_movements (user_workout_id, movement_id, order_index, created_at, updated_at) VALUES (1000, 1000, 0, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`,
+		`INSERT INTO user_workout_wods (user_workout_id, wod_id, order_index, created_at, updated_at) VALUES (1000, 1000, 1, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`,
+	} {
+		if _, err := sqlDB.Exec(query); err != nil {
+			t.Fatalf("%s: %v", query, err)
+		}
+	}
+
+	if err := repo.Delete(context.Background(), 1); err != nil {
+		t.Fatalf("failed to delete user: %v", err)
+	}
+
+	// Nothing the user owned is left behind as a standard entry
+	if got := count(`SELECT COUNT(*) FROM workouts WHERE created_by IS NULL`); got != standardWorkouts {
+		t.Errorf("expected %d standard templates, got %d", standardWorkouts, got)
+	}
+	if got := count(`SELECT COUNT(*) FROM movements WHERE created_by IS NULL`); got != standardMovements {
+		t.Errorf("expected %d standard movements, got %d", standardMovements, got)
+	}
+	if got := count(`SELECT COUNT(*) FROM wods WHERE created_by IS NULL`); got != standardWODs {
+		t.Errorf("expected %d standard WODs, got %d", standardWODs, got)
+	}
+	for _, table := range []string{"workouts", "movements", "wods", "user_workouts"} {
+		if got := count(`SELECT COUNT(*) FROM ` + table + ` WHERE id = 1000`); got != 0 {
+			t.Errorf("expected the user's row in %s to be deleted", table)
+		}
+	}
+
+	// Other users keep what they own
+	if count(`SELECT COUNT(*) FROM movements WHERE id = 1001 AND created_by = 2`) != 1 || count(`SELECT COUNT(*) FROM users WHERE id = 2`) != 1 {
+		t.Error("expected the other user and their movement to remain")
+	}
+}
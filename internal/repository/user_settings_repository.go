@@ -1,7 +1,9 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/johnzastrow/actalog/internal/domain"
@@ -9,16 +11,16 @@ import (
 
 // SQLiteUserSettingsRepository implements UserSettingsRepository for SQLite
 type SQLiteUserSettingsRepository struct {
-	db *sql.DB
+	db *DB
 }
 
 // NewSQLiteUserSettingsRepository creates a new user settings repository
 func NewSQLiteUserSettingsRepository(db *sql.DB) domain.UserSettingsRepository {
-	return &SQLiteUserSettingsRepository{db: db}
+	return &SQLiteUserSettingsRepository{db: NewDB(db)}
 }
 
 // GetByUserID retrieves settings for a specific user
-func (r *SQLiteUserSettingsRepository) GetByUserID(userID int64) (*domain.UserSettings, error) {
+func (r *SQLiteUserSettingsRepository) GetByUserID(ctx context.Context, userID int64) (*domain.UserSettings, error) {
 	query := `
 		SELECT id, user_id, notification_preferences, data_export_format, theme,
 		       weight_unit, distance_unit, created_at, updated_at
@@ -27,10 +29,11 @@ func (r *SQLiteUserSettingsRepository) GetByUserID(userID int64) (*domain.UserSe
 	`
 
 	settings := &domain.UserSettings{}
-	err := r.db.QueryRow(query, userID).Scan(
+	var prefs string
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(
 		&settings.ID,
 		&settings.UserID,
-		&settings.NotificationPreferences,
+		&prefs,
 		&settings.DataExportFormat,
 		&settings.Theme,
 		&settings.WeightUnit,
@@ -45,12 +48,13 @@ func (r *SQLiteUserSettingsRepository) GetByUserID(userID int64) (*domain.UserSe
 	if err != nil {
 		return nil, err
 	}
+	settings.NotificationPreferences = decodeNotificationPreferences(prefs)
 
 	return settings, nil
 }
 
 // Create creates new settings for a user
-func (r *SQLiteUserSettingsRepository) Create(settings *domain.UserSettings) error {
+func (r *SQLiteUserSettingsRepository) Create(ctx context.Context, settings *domain.UserSettings) error {
 	query := `
 		INSERT INTO user_settings (
 			user_id, notification_preferences, data_export_format, theme,
@@ -58,14 +62,19 @@ func (r *SQLiteUserSettingsRepository) Create(settings *domain.UserSettings) err
 		) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
+	prefs, err := json.Marshal(settings.NotificationPreferences)
+	if err != nil {
+		return err
+	}
+
 	now := time.Now()
 	settings.CreatedAt = now
 	settings.UpdatedAt = now
 
-	result, err := r.db.Exec(
+	id, err := r.db.InsertContext(ctx,
 		query,
 		settings.UserID,
-		settings.NotificationPreferences,
+		string(prefs),
 		settings.DataExportFormat,
 		settings.Theme,
 		settings.WeightUnit,
@@ -77,17 +86,12 @@ func (r *SQLiteUserSettingsRepository) Create(settings *domain.UserSettings) err
 		return err
 	}
 
-	id, err := result.LastInsertId()
-	if err != nil {
-		return err
-	}
-
 	settings.ID = id
 	return nil
 }
 
 // Update updates existing user settings
-func (r *SQLiteUserSettingsRepository) Update(settings *domain.UserSettings) error {
+func (r *SQLiteUserSettingsRepository) Update(ctx context.Context, settings *domain.UserSettings) error {
 	query := `
 		UPDATE user_settings
 		SET notification_preferences = ?, data_export_format = ?, theme = ?,
@@ -95,11 +99,16 @@ func (r *SQLiteUserSettingsRepository) Update(settings *domain.UserSettings) err
 		WHERE user_id = ?
 	`
 
+	prefs, err := json.Marshal(settings.NotificationPreferences)
+	if err != nil {
+		return err
+	}
+
 	settings.UpdatedAt = time.Now()
 
-	_, err := r.db.Exec(
+	_, err = r.db.ExecContext(ctx,
 		query,
-		settings.NotificationPreferences,
+		string(prefs),
 		settings.DataExportFormat,
 		settings.Theme,
 		settings.WeightUnit,
@@ -112,8 +121,60 @@ func (r *SQLiteUserSettingsRepository) Update(settings *domain.UserSettings) err
 }
 
 // Delete removes user settings
-func (r *SQLiteUserSettingsRepository) Delete(userID int64) error {
+func (r *SQLiteUserSettingsRepository) Delete(ctx context.Context, userID int64) error {
 	query := `DELETE FROM user_settings WHERE user_id = ?`
-	_, err := r.db.Exec(query, userID)
+	_, err := r.db.ExecContext(ctx, query, userID)
 	return err
 }
+
+// List retrieves settings for all users with pagination, ordered by user ID
+func (r *SQLiteUserSettingsRepository) List(ctx context.Context, limit, offset int) ([]*domain.UserSettings, error) {
+	query := `
+		SELECT id, user_id, notification_preferences, data_export_format, theme,
+		       weight_unit, distance_unit, created_at, updated_at
+		FROM user_settings
+		ORDER BY user_id
+		LIMIT ? OFFSET ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []*domain.UserSettings
+	for rows.Next() {
+		settings := &domain.UserSettings{}
+		var prefs string
+		err := rows.Scan(
+			&settings.ID,
+			&settings.UserID,
+			&prefs,
+			&settings.DataExportFormat,
+			&settings.Theme,
+			&settings.WeightUnit,
+			&settings.DistanceUnit,
+			&settings.CreatedAt,
+			&settings.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		settings.NotificationPreferences = decodeNotificationPreferences(prefs)
+		list = append(list, settings)
+	}
+
+	return list, rows.Err()
+}
+
+// decodeNotificationPreferences parses stored preferences. Rows written before
+// the preferences were typed may hold anything, so invalid values fall back to
+// the defaults rather than failing the read.
+func decodeNotificationPreferences(value string) domain.NotificationPreferences {
+	var prefs domain.NotificationPreferences
+	if err := json.Unmarshal([]byte(value), &prefs); err != nil {
+		return domain.DefaultNotificationPreferences()
+	}
+	return prefs
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"time"
@@ -10,43 +11,38 @@ import (
 
 // UserWorkoutMovementRepository implements domain.UserWorkoutMovementRepository
 type UserWorkoutMovementRepository struct {
-	db *sql.DB
+	db *DB
 }
 
 // NewUserWorkoutMovementRepository creates a new user workout movement repository
 func NewUserWorkoutMovementRepository(db *sql.DB) *UserWorkoutMovementRepository {
-	return &UserWorkoutMovementRepository{db: db}
+	return &UserWorkoutMovementRepository{db: NewDB(db)}
 }
 
 // Create creates a new user workout movement performance record
-func (r *UserWorkoutMovementRepository) Create(uwm *domain.UserWorkoutMovement) error {
+func (r *UserWorkoutMovementRepository) Create(ctx context.Context, uwm *domain.UserWorkoutMovement) error {
 	uwm.CreatedAt = time.Now()
 	uwm.UpdatedAt = time.Now()
 
 	query := `INSERT INTO user_workout_movements (user_workout_id, movement_id, sets, reps, weight, time, distance, notes, is_pr, order_index, created_at, updated_at)
 	          VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
-	result, err := r.db.Exec(query, uwm.UserWorkoutID, uwm.MovementID, uwm.Sets, uwm.Reps, uwm.Weight, uwm.Time, uwm.Distance, uwm.Notes, uwm.IsPR, uwm.OrderIndex, uwm.CreatedAt, uwm.UpdatedAt)
+	id, err := r.db.InsertContext(ctx, query, uwm.UserWorkoutID, uwm.MovementID, uwm.Sets, uwm.Reps, uwm.Weight, uwm.Time, uwm.Distance, uwm.Notes, uwm.IsPR, uwm.OrderIndex, uwm.CreatedAt, uwm.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to create user workout movement: %w", err)
 	}
 
-	id, err := result.LastInsertId()
-	if err != nil {
-		return fmt.Errorf("failed to get user workout movement ID: %w", err)
-	}
-
 	uwm.ID = id
 	return nil
 }
 
 // CreateBatch creates multiple user workout movement records at once
-func (r *UserWorkoutMovementRepository) CreateBatch(movements []*domain.UserWorkoutMovement) error {
+func (r *UserWorkoutMovementRepository) CreateBatch(ctx context.Context, movements []*domain.UserWorkoutMovement) error {
 	if len(movements) == 0 {
 		return nil
 	}
 
-	tx, err := r.db.Begin()
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
@@ -55,26 +51,15 @@ func (r *UserWorkoutMovementRepository) CreateBatch(movements []*domain.UserWork
 	query := `INSERT INTO user_workout_movements (user_workout_id, movement_id, sets, reps, weight, time, distance, notes, is_pr, order_index, created_at, updated_at)
 	          VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
-	stmt, err := tx.Prepare(query)
-	if err != nil {
-		return fmt.Errorf("failed to prepare statement: %w", err)
-	}
-	defer stmt.Close()
-
 	now := time.Now()
 	for _, uwm := range movements {
 		uwm.CreatedAt = now
 		uwm.UpdatedAt = now
 
-		result, err := stmt.Exec(uwm.UserWorkoutID, uwm.MovementID, uwm.Sets, uwm.Reps, uwm.Weight, uwm.Time, uwm.Distance, uwm.Notes, uwm.IsPR, uwm.OrderIndex, uwm.CreatedAt, uwm.UpdatedAt)
+		id, err := tx.InsertContext(ctx, query, uwm.UserWorkoutID, uwm.MovementID, uwm.Sets, uwm.Reps, uwm.Weight, uwm.Time, uwm.Distance, uwm.Notes, uwm.IsPR, uwm.OrderIndex, uwm.CreatedAt, uwm.UpdatedAt)
 		if err != nil {
 			return fmt.Errorf("failed to insert user workout movement: %w", err)
 		}
-
-		id, err := result.LastInsertId()
-		if err != nil {
-			return fmt.Errorf("failed to get user workout movement ID: %w", err)
-		}
 		uwm.ID = id
 	}
 
@@ -86,7 +71,7 @@ func (r *UserWorkoutMovementRepository) CreateBatch(movements []*domain.UserWork
 }
 
 // GetByID retrieves a user workout movement by ID
-func (r *UserWorkoutMovementRepository) GetByID(id int64) (*domain.UserWorkoutMovement, error) {
+func (r *UserWorkoutMovementRepository) GetByID(ctx context.Context, id int64) (*domain.UserWorkoutMovement, error) {
 	query := `SELECT id, user_workout_id, movement_id, sets, reps, weight, time, distance, notes, order_index, created_at, updated_at
 	          FROM user_workout_movements WHERE id = ?`
 
@@ -97,7 +82,7 @@ func (r *UserWorkoutMovementRepository) GetByID(id int64) (*domain.UserWorkoutMo
 	var time sql.NullInt64
 	var distance sql.NullFloat64
 
-	err := r.db.QueryRow(query, id).Scan(&uwm.ID, &uwm.UserWorkoutID, &uwm.MovementID, &sets, &reps, &weight, &time, &distance, &uwm.Notes, &uwm.OrderIndex, &uwm.CreatedAt, &uwm.UpdatedAt)
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&uwm.ID, &uwm.UserWorkoutID, &uwm.MovementID, &sets, &reps, &weight, &time, &distance, &uwm.Notes, &uwm.OrderIndex, &uwm.CreatedAt, &uwm.UpdatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -128,17 +113,17 @@ func (r *UserWorkoutMovementRepository) GetByID(id int64) (*domain.UserWorkoutMo
 }
 
 // GetByUserWorkoutID retrieves all movements for a specific logged workout
-func (r *UserWorkoutMovementRepository) GetByUserWorkoutID(userWorkoutID int64) ([]*domain.UserWorkoutMovement, error) {
+func (r *UserWorkoutMovementRepository) GetByUserWorkoutID(ctx context.Context, userWorkoutID int64) ([]*domain.UserWorkoutMovement, error) {
 	query := `
 		SELECT uwm.id, uwm.user_workout_id, uwm.movement_id, uwm.sets, uwm.reps, uwm.weight, uwm.time, uwm.distance,
-		       uwm.notes, uwm.order_index, uwm.created_at, uwm.updated_at,
+		       uwm.notes, uwm.is_pr, uwm.order_index, uwm.created_at, uwm.updated_at,
 		       m.id as movement_id, m.name, m.description, m.type, m.is_standard, m.created_by, m.created_at, m.updated_at
 		FROM user_workout_movements uwm
 		JOIN movements m ON uwm.movement_id = m.id
 		WHERE uwm.user_workout_id = ?
 		ORDER BY uwm.order_index`
 
-	rows, err := r.db.Query(query, userWorkoutID)
+	rows, err := r.db.QueryContext(ctx, query, userWorkoutID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user workout movements: %w", err)
 	}
@@ -157,7 +142,7 @@ func (r *UserWorkoutMovementRepository) GetByUserWorkoutID(userWorkoutID int64)
 		var createdBy sql.NullInt64
 
 		err := rows.Scan(&uwm.ID, &uwm.UserWorkoutID, &uwm.MovementID, &sets, &reps, &weight, &time, &distance,
-			&uwm.Notes, &uwm.OrderIndex, &uwm.CreatedAt, &uwm.UpdatedAt,
+			&uwm.Notes, &uwm.IsPR, &uwm.OrderIndex, &uwm.CreatedAt, &uwm.UpdatedAt,
 			&uwm.Movement.ID, &uwm.Movement.Name, &uwm.Movement.Description, &uwm.Movement.Type, &uwm.Movement.IsStandard, &createdBy, &uwm.Movement.CreatedAt, &uwm.Movement.UpdatedAt)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan user workout movement: %w", err)
@@ -197,14 +182,14 @@ func (r *UserWorkoutMovementRepository) GetByUserWorkoutID(userWorkoutID int64)
 }
 
 // Update updates an existing user workout movement
-func (r *UserWorkoutMovementRepository) Update(uwm *domain.UserWorkoutMovement) error {
+func (r *UserWorkoutMovementRepository) Update(ctx context.Context, uwm *domain.UserWorkoutMovement) error {
 	uwm.UpdatedAt = time.Now()
 
 	query := `UPDATE user_workout_movements
 	          SET sets = ?, reps = ?, weight = ?, time = ?, distance = ?, notes = ?, order_index = ?, updated_at = ?
 	          WHERE id = ?`
 
-	result, err := r.db.Exec(query, uwm.Sets, uwm.Reps, uwm.Weight, uwm.Time, uwm.Distance, uwm.Notes, uwm.OrderIndex, uwm.UpdatedAt, uwm.ID)
+	result, err := r.db.ExecContext(ctx, query, uwm.Sets, uwm.Reps, uwm.Weight, uwm.Time, uwm.Distance, uwm.Notes, uwm.OrderIndex, uwm.UpdatedAt, uwm.ID)
 	if err != nil {
 		return fmt.Errorf("failed to update user workout movement: %w", err)
 	}
@@ -222,10 +207,10 @@ func (r *UserWorkoutMovementRepository) Update(uwm *domain.UserWorkoutMovement)
 }
 
 // Delete deletes a user workout movement
-func (r *UserWorkoutMovementRepository) Delete(id int64) error {
+func (r *UserWorkoutMovementRepository) Delete(ctx context.Context, id int64) error {
 	query := `DELETE FROM user_workout_movements WHERE id = ?`
 
-	result, err := r.db.Exec(query, id)
+	result, err := r.db.ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete user workout movement: %w", err)
 	}
@@ -243,10 +228,10 @@ func (r *UserWorkoutMovementRepository) Delete(id int64) error {
 }
 
 // DeleteByUserWorkoutID deletes all movements for a logged workout
-func (r *UserWorkoutMovementRepository) DeleteByUserWorkoutID(userWorkoutID int64) error {
+func (r *UserWorkoutMovementRepository) DeleteByUserWorkoutID(ctx context.Context, userWorkoutID int64) error {
 	query := `DELETE FROM user_workout_movements WHERE user_workout_id = ?`
 
-	_, err := r.db.Exec(query, userWorkoutID)
+	_, err := r.db.ExecContext(ctx, query, userWorkoutID)
 	if err != nil {
 		return fmt.Errorf("failed to delete user workout movements: %w", err)
 	}
@@ -255,7 +240,7 @@ func (r *UserWorkoutMovementRepository) DeleteByUserWorkoutID(userWorkoutID int6
 }
 
 // GetMaxWeightForMovement retrieves the maximum weight for a specific movement for a user
-func (r *UserWorkoutMovementRepository) GetMaxWeightForMovement(userID, movementID int64) (*float64, error) {
+func (r *UserWorkoutMovementRepository) GetMaxWeightForMovement(ctx context.Context, userID, movementID int64) (*float64, error) {
 	query := `
 		SELECT MAX(uwm.weight)
 		FROM user_workout_movements uwm
@@ -263,7 +248,7 @@ func (r *UserWorkoutMovementRepository) GetMaxWeightForMovement(userID, movement
 		WHERE uw.user_id = ? AND uwm.movement_id = ? AND uwm.weight IS NOT NULL`
 
 	var maxWeight sql.NullFloat64
-	err := r.db.QueryRow(query, userID, movementID).Scan(&maxWeight)
+	err := r.db.QueryRowContext(ctx, query, userID, movementID).Scan(&maxWeight)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -279,7 +264,7 @@ func (r *UserWorkoutMovementRepository) GetMaxWeightForMovement(userID, movement
 }
 
 // GetPRMovements retrieves recent PR-flagged movements for a user
-func (r *UserWorkoutMovementRepository) GetPRMovements(userID int64, limit int) ([]*domain.UserWorkoutMovement, error) {
+func (r *UserWorkoutMovementRepository) GetPRMovements(ctx context.Context, userID int64, limit int) ([]*domain.UserWorkoutMovement, error) {
 	query := `
 		SELECT uwm.id, uwm.user_workout_id, uwm.movement_id, uwm.sets, uwm.reps, uwm.weight, uwm.time, uwm.distance,
 		       uwm.notes, uwm.is_pr, uwm.order_index, uwm.created_at, uwm.updated_at,
@@ -288,11 +273,11 @@ func (r *UserWorkoutMovementRepository) GetPRMovements(userID int64, limit int)
 		FROM user_workout_movements uwm
 		JOIN movements m ON uwm.movement_id = m.id
 		JOIN user_workouts uw ON uwm.user_workout_id = uw.id
-		WHERE uw.user_id = ? AND uwm.is_pr = 1
+		WHERE uw.user_id = ? AND uwm.is_pr = ?
 		ORDER BY uw.workout_date DESC, uwm.created_at DESC
 		LIMIT ?`
 
-	rows, err := r.db.Query(query, userID, limit)
+	rows, err := r.db.QueryContext(ctx, query, userID, true, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get PR movements: %w", err)
 	}
@@ -345,9 +330,9 @@ func (r *UserWorkoutMovementRepository) GetPRMovements(userID int64, limit int)
 }
 
 // UpdatePRFlag updates the is_pr flag for a user workout movement
-func (r *UserWorkoutMovementRepository) UpdatePRFlag(id int64, isPR bool) error {
+func (r *UserWorkoutMovementRepository) UpdatePRFlag(ctx context.Context, id int64, isPR bool) error {
 	query := `UPDATE user_workout_movements SET is_pr = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`
-	result, err := r.db.Exec(query, isPR, id)
+	result, err := r.db.ExecContext(ctx, query, isPR, id)
 	if err != nil {
 		return fmt.Errorf("failed to update PR flag: %w", err)
 	}
@@ -365,7 +350,7 @@ func (r *UserWorkoutMovementRepository) UpdatePRFlag(id int64, isPR bool) error
 }
 
 // GetByUserIDAndMovementID retrieves all movement performance records for a specific user and movement
-func (r *UserWorkoutMovementRepository) GetByUserIDAndMovementID(userID, movementID int64, limit int) ([]*domain.UserWorkoutMovement, error) {
+func (r *UserWorkoutMovementRepository) GetByUserIDAndMovementID(ctx context.Context, userID, movementID int64, limit int) ([]*domain.UserWorkoutMovement, error) {
 	query := `
 		SELECT uwm.id, uwm.user_workout_id, uwm.movement_id, uwm.weight, uwm.reps,
 		       uwm.sets, uwm.time, uwm.notes, uwm.is_pr, uwm.order_index,
@@ -379,7 +364,7 @@ func (r *UserWorkoutMovementRepository) GetByUserIDAndMovementID(userID, movemen
 		ORDER BY uw.workout_date DESC, uwm.created_at DESC
 		LIMIT ?`
 
-	rows, err := r.db.Query(query, userID, movementID, limit)
+	rows, err := r.db.QueryContext(ctx, query, userID, movementID, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query movement performances: %w", err)
 	}
@@ -1,45 +1,44 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/johnzastrow/actalog/internal/domain"
 )
 
 type UserWorkoutRepository struct {
-	db *sql.DB
+	db *DB
 }
 
 func NewUserWorkoutRepository(db *sql.DB) *UserWorkoutRepository {
-	return &UserWorkoutRepository{db: db}
+	return &UserWorkoutRepository{db: NewDB(db)}
 }
 
 // Create creates a new user workout (logs a workout instance)
-func (r *UserWorkoutRepository) Create(userWorkout *domain.UserWorkout) error {
+func (r *UserWorkoutRepository) Create(ctx context.Context, userWorkout *domain.UserWorkout) error {
 	userWorkout.CreatedAt = time.Now()
 	userWorkout.UpdatedAt = time.Now()
 
 	query := `INSERT INTO user_workouts (user_id, workout_id, workout_name, workout_date, workout_type, total_time, notes, created_at, updated_at)
 	          VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
-	result, err := r.db.Exec(query, userWorkout.UserID, userWorkout.WorkoutID, userWorkout.WorkoutName, userWorkout.WorkoutDate, userWorkout.WorkoutType, userWorkout.TotalTime, userWorkout.Notes, userWorkout.CreatedAt, userWorkout.UpdatedAt)
+	id, err := r.db.InsertContext(ctx, query, userWorkout.UserID, userWorkout.WorkoutID, userWorkout.WorkoutName, userWorkout.WorkoutDate, userWorkout.WorkoutType, userWorkout.TotalTime, userWorkout.Notes, userWorkout.CreatedAt, userWorkout.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to create user workout: %w", err)
 	}
 
-	id, err := result.LastInsertId()
-	if err != nil {
-		return fmt.Errorf("failed to get user workout ID: %w", err)
-	}
-
 	userWorkout.ID = id
 	return nil
 }
 
 // GetByID retrieves a user workout by ID
-func (r *UserWorkoutRepository) GetByID(id int64) (*domain.UserWorkout, error) {
+func (r *UserWorkoutRepository) GetByID(ctx context.Context, id int64) (*domain.UserWorkout, error) {
 	query := `SELECT id, user_id, workout_id, workout_name, workout_date, workout_type, total_time, notes, created_at, updated_at FROM user_workouts WHERE id = ?`
 
 	userWorkout := &domain.UserWorkout{}
@@ -49,7 +48,7 @@ func (r *UserWorkoutRepository) GetByID(id int64) (*domain.UserWorkout, error) {
 	var totalTime sql.NullInt64
 	var notes sql.NullString
 
-	err := r.db.QueryRow(query, id).Scan(&userWorkout.ID, &userWorkout.UserID, &workoutID, &workoutName, &userWorkout.WorkoutDate, &workoutType, &totalTime, &notes, &userWorkout.CreatedAt, &userWorkout.UpdatedAt)
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&userWorkout.ID, &userWorkout.UserID, &workoutID, &workoutName, &userWorkout.WorkoutDate, &workoutType, &totalTime, &notes, &userWorkout.CreatedAt, &userWorkout.UpdatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -79,9 +78,9 @@ func (r *UserWorkoutRepository) GetByID(id int64) (*domain.UserWorkout, error) {
 }
 
 // GetByIDWithDetails retrieves a user workout with full details (movements, WODs)
-func (r *UserWorkoutRepository) GetByIDWithDetails(id int64, userID int64) (*domain.UserWorkoutWithDetails, error) {
+func (r *UserWorkoutRepository) GetByIDWithDetails(ctx context.Context, id int64, userID int64) (*domain.UserWorkoutWithDetails, error) {
 	// First get the user workout
-	userWorkout, err := r.GetByID(id)
+	userWorkout, err := r.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -102,7 +101,7 @@ func (r *UserWorkoutRepository) GetByIDWithDetails(id int64, userID int64) (*dom
 		// Template-based workout - get name from template
 		var workoutNotes sql.NullString
 		query := `SELECT name, notes FROM workouts WHERE id = ?`
-		if err := r.db.QueryRow(query, *userWorkout.WorkoutID).Scan(&workoutName, &workoutNotes); err != nil {
+		if err := r.db.QueryRowContext(ctx, query, *userWorkout.WorkoutID).Scan(&workoutName, &workoutNotes); err != nil {
 			if err == sql.ErrNoRows {
 				return nil, fmt.Errorf("workout template not found")
 			}
@@ -118,131 +117,219 @@ func (r *UserWorkoutRepository) GetByIDWithDetails(id int64, userID int64) (*dom
 		return nil, fmt.Errorf("workout has neither workout_id nor workout_name")
 	}
 
-	// Get movements from workout_movements table with movement details (only for template-based workouts)
-	var movements []*domain.WorkoutMovement
-	if userWorkout.WorkoutID != nil {
-		movementsQuery := `
-			SELECT ws.id, ws.workout_id, ws.movement_id, ws.weight, ws.sets, ws.reps, ws.time, ws.distance,
-				   ws.is_rx, ws.is_pr, ws.notes, ws.order_index, ws.created_at, ws.updated_at,
-				   m.name as movement_name, m.type as movement_type
-			FROM workout_movements ws
-			JOIN movements m ON ws.movement_id = m.id
-			WHERE ws.workout_id = ?
-			ORDER BY ws.order_index`
-
-		rows, err := r.db.Query(movementsQuery, *userWorkout.WorkoutID)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get workout movements: %w", err)
-		}
-		defer rows.Close()
-
-		for rows.Next() {
-			wm := &domain.WorkoutMovement{}
-			var weight sql.NullFloat64
-			var sets sql.NullInt64
-			var reps sql.NullInt64
-			var time sql.NullInt64
-			var distance sql.NullFloat64
-			var notes sql.NullString
-			var movementName string
-			var movementType string
-
-			err := rows.Scan(&wm.ID, &wm.WorkoutID, &wm.MovementID, &weight, &sets, &reps, &time, &distance,
-				&wm.IsRx, &wm.IsPR, &notes, &wm.OrderIndex, &wm.CreatedAt, &wm.UpdatedAt,
-				&movementName, &movementType)
-			if err != nil {
-				return nil, fmt.Errorf("failed to scan workout movement: %w", err)
-			}
+	result := &domain.UserWorkoutWithDetails{
+		UserWorkout:        *userWorkout,
+		WorkoutName:        workoutName,
+		WorkoutDescription: workoutDescription,
+	}
+	if err := r.loadDetails(ctx, []*domain.UserWorkoutWithDetails{result}); err != nil {
+		return nil, err
+	}
 
-			if weight.Valid {
-				wm.Weight = &weight.Float64
-			}
-			if sets.Valid {
-				s := int(sets.Int64)
-				wm.Sets = &s
-			}
-			if reps.Valid {
-				r := int(reps.Int64)
-				wm.Reps = &r
-			}
-			if time.Valid {
-				t := int(time.Int64)
-				wm.Time = &t
-			}
-			if distance.Valid {
-				wm.Distance = &distance.Float64
-			}
-			if notes.Valid {
-				wm.Notes = notes.String
+	return result, nil
+}
+
+// loadDetails fills in the template movements and WODs and the performance
+// data of logged workouts, with one query for each whatever their number
+func (r *UserWorkoutRepository) loadDetails(ctx context.Context, workouts []*domain.UserWorkoutWithDetails) error {
+	if len(workouts) == 0 {
+		return nil
+	}
+
+	byID := make(map[int64]*domain.UserWorkoutWithDetails, len(workouts))
+	byTemplate := make(map[int64][]*domain.UserWorkoutWithDetails)
+	var ids, templateIDs []interface{}
+	for _, workout := range workouts {
+		byID[workout.ID] = workout
+		ids = append(ids, workout.ID)
+		if workout.WorkoutID != nil {
+			if _, ok := byTemplate[*workout.WorkoutID]; !ok {
+				templateIDs = append(templateIDs, *workout.WorkoutID)
 			}
+			byTemplate[*workout.WorkoutID] = append(byTemplate[*workout.WorkoutID], workout)
+		}
+	}
 
-			wm.Movement = &domain.Movement{
-				ID:   wm.MovementID,
-				Name: movementName,
-				Type: domain.MovementType(movementType),
+	// Movements and WODs of the templates (ad-hoc workouts have none)
+	if len(templateIDs) > 0 {
+		movements, err := r.templateMovements(ctx, templateIDs)
+		if err != nil {
+			return err
+		}
+		for _, wm := range movements {
+			for _, workout := range byTemplate[wm.WorkoutID] {
+				workout.Movements = append(workout.Movements, wm)
 			}
+		}
 
-			movements = append(movements, wm)
+		wods, err := r.templateWODs(ctx, templateIDs)
+		if err != nil {
+			return err
 		}
-		if err = rows.Err(); err != nil {
-			return nil, fmt.Errorf("failed to get workout movements: %w", err)
+		for _, wod := range wods {
+			for _, workout := range byTemplate[wod.WorkoutID] {
+				workout.WODs = append(workout.WODs, wod)
+			}
 		}
 	}
 
-	// Get WODs from workout_wods table with WOD details (only for template-based workouts)
-	var wods []*domain.WorkoutWODWithDetails
-	if userWorkout.WorkoutID != nil {
-		wodsQuery := `
-			SELECT ww.id, ww.workout_id, ww.wod_id,
-				   ww.order_index, ww.created_at, ww.updated_at,
-				   w.name as wod_name, w.type as wod_type, w.regime as wod_regime,
-				   w.score_type as wod_score_type, w.description as wod_description
-			FROM workout_wods ww
-			JOIN wods w ON ww.wod_id = w.id
-			WHERE ww.workout_id = ?
-			ORDER BY ww.order_index`
-
-		rows, err := r.db.Query(wodsQuery, *userWorkout.WorkoutID)
+	// Actual performance data
+	performanceMovements, err := r.performanceMovements(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, uwm := range performanceMovements {
+		workout := byID[uwm.UserWorkoutID]
+		workout.PerformanceMovements = append(workout.PerformanceMovements, uwm)
+	}
+
+	performanceWODs, err := r.performanceWODs(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, uww := range performanceWODs {
+		workout := byID[uww.UserWorkoutID]
+		workout.PerformanceWODs = append(workout.PerformanceWODs, uww)
+	}
+
+	return nil
+}
+
+// inList returns the placeholders for an IN list of n values
+func inList(n int) string {
+	return "(" + strings.TrimSuffix(strings.Repeat("?, ", n), ", ") + ")"
+}
+
+// templateMovements gets the movements of workout templates with movement details
+func (r *UserWorkoutRepository) templateMovements(ctx context.Context, templateIDs []interface{}) ([]*domain.WorkoutMovement, error) {
+	query := `
+		SELECT ws.id, ws.workout_id, ws.movement_id, ws.weight, ws.sets, ws.reps, ws.time, ws.distance,
+			   ws.is_rx, ws.is_pr, ws.notes, ws.order_index, ws.created_at, ws.updated_at,
+			   m.name as movement_name, m.type as movement_type
+		FROM workout_movements ws
+		JOIN movements m ON ws.movement_id = m.id
+		WHERE ws.workout_id IN ` + inList(len(templateIDs)) + `
+		ORDER BY ws.workout_id, ws.order_index`
+
+	rows, err := r.db.QueryContext(ctx, query, templateIDs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workout movements: %w", err)
+	}
+	defer rows.Close()
+
+	var movements []*domain.WorkoutMovement
+	for rows.Next() {
+		wm := &domain.WorkoutMovement{}
+		var weight sql.NullFloat64
+		var sets sql.NullInt64
+		var reps sql.NullInt64
+		var time sql.NullInt64
+		var distance sql.NullFloat64
+		var notes sql.NullString
+		var movementName string
+		var movementType string
+
+		err := rows.Scan(&wm.ID, &wm.WorkoutID, &wm.MovementID, &weight, &sets, &reps, &time, &distance,
+			&wm.IsRx, &wm.IsPR, &notes, &wm.OrderIndex, &wm.CreatedAt, &wm.UpdatedAt,
+			&movementName, &movementType)
 		if err != nil {
-			return nil, fmt.Errorf("failed to get workout WODs: %w", err)
+			return nil, fmt.Errorf("failed to scan workout movement: %w", err)
 		}
-		defer rows.Close()
-
-		for rows.Next() {
-			wod := &domain.WorkoutWODWithDetails{}
 
-			err := rows.Scan(&wod.ID, &wod.WorkoutID, &wod.WODID,
-				&wod.OrderIndex, &wod.CreatedAt, &wod.UpdatedAt,
-				&wod.WODName, &wod.WODType, &wod.WODRegime, &wod.WODScoreType, &wod.WODDescription)
-			if err != nil {
-				return nil, fmt.Errorf("failed to scan workout WOD: %w", err)
-			}
+		if weight.Valid {
+			wm.Weight = &weight.Float64
+		}
+		if sets.Valid {
+			s := int(sets.Int64)
+			wm.Sets = &s
+		}
+		if reps.Valid {
+			r := int(reps.Int64)
+			wm.Reps = &r
+		}
+		if time.Valid {
+			t := int(time.Int64)
+			wm.Time = &t
+		}
+		if distance.Valid {
+			wm.Distance = &distance.Float64
+		}
+		if notes.Valid {
+			wm.Notes = notes.String
+		}
 
-			wods = append(wods, wod)
+		wm.Movement = &domain.Movement{
+			ID:   wm.MovementID,
+			Name: movementName,
+			Type: domain.MovementType(movementType),
 		}
-		if err = rows.Err(); err != nil {
-			return nil, fmt.Errorf("failed to get workout WODs: %w", err)
+
+		movements = append(movements, wm)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get workout movements: %w", err)
+	}
+
+	return movements, nil
+}
+
+// templateWODs gets the WODs of workout templates with WOD details
+func (r *UserWorkoutRepository) templateWODs(ctx context.Context, templateIDs []interface{}) ([]*domain.WorkoutWODWithDetails, error) {
+	query := `
+		SELECT ww.id, ww.workout_id, ww.wod_id,
+			   ww.order_index, ww.created_at, ww.updated_at,
+			   w.name as wod_name, w.type as wod_type, w.regime as wod_regime,
+			   w.score_type as wod_score_type, w.description as wod_description
+		FROM workout_wods ww
+		JOIN wods w ON ww.wod_id = w.id
+		WHERE ww.workout_id IN ` + inList(len(templateIDs)) + `
+		ORDER BY ww.workout_id, ww.order_index`
+
+	rows, err := r.db.QueryContext(ctx, query, templateIDs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workout WODs: %w", err)
+	}
+	defer rows.Close()
+
+	var wods []*domain.WorkoutWODWithDetails
+	for rows.Next() {
+		wod := &domain.WorkoutWODWithDetails{}
+
+		err := rows.Scan(&wod.ID, &wod.WorkoutID, &wod.WODID,
+			&wod.OrderIndex, &wod.CreatedAt, &wod.UpdatedAt,
+			&wod.WODName, &wod.WODType, &wod.WODRegime, &wod.WODScoreType, &wod.WODDescription)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan workout WOD: %w", err)
 		}
+
+		wods = append(wods, wod)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get workout WODs: %w", err)
 	}
 
-	// Get actual performance movements from user_workout_movements table
-	perfMovementsQuery := `
+	return wods, nil
+}
+
+// performanceMovements gets the movements performed in logged workouts
+func (r *UserWorkoutRepository) performanceMovements(ctx context.Context, ids []interface{}) ([]*domain.UserWorkoutMovement, error) {
+	query := `
 		SELECT uwm.id, uwm.user_workout_id, uwm.movement_id, uwm.sets, uwm.reps, uwm.weight,
 		       uwm.time, uwm.distance, uwm.notes, uwm.order_index, uwm.created_at, uwm.updated_at,
 		       m.name as movement_name, m.type as movement_type
 		FROM user_workout_movements uwm
 		JOIN movements m ON uwm.movement_id = m.id
-		WHERE uwm.user_workout_id = ?
-		ORDER BY uwm.order_index`
+		WHERE uwm.user_workout_id IN ` + inList(len(ids)) + `
+		ORDER BY uwm.user_workout_id, uwm.order_index`
 
-	perfMovRows, err := r.db.Query(perfMovementsQuery, id)
+	rows, err := r.db.QueryContext(ctx, query, ids...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user workout movements: %w", err)
 	}
-	defer perfMovRows.Close()
+	defer rows.Close()
 
 	var performanceMovements []*domain.UserWorkoutMovement
-	for perfMovRows.Next() {
+	for rows.Next() {
 		uwm := &domain.UserWorkoutMovement{}
 		var sets sql.NullInt64
 		var reps sql.NullInt64
@@ -253,7 +340,7 @@ func (r *UserWorkoutRepository) GetByIDWithDetails(id int64, userID int64) (*dom
 		var movementName string
 		var movementType string
 
-		err := perfMovRows.Scan(&uwm.ID, &uwm.UserWorkoutID, &uwm.MovementID, &sets, &reps, &weight,
+		err := rows.Scan(&uwm.ID, &uwm.UserWorkoutID, &uwm.MovementID, &sets, &reps, &weight,
 			&time, &distance, &notes, &uwm.OrderIndex, &uwm.CreatedAt, &uwm.UpdatedAt,
 			&movementName, &movementType)
 		if err != nil {
@@ -292,29 +379,33 @@ func (r *UserWorkoutRepository) GetByIDWithDetails(id int64, userID int64) (*dom
 
 		performanceMovements = append(performanceMovements, uwm)
 	}
-	if err = perfMovRows.Err(); err != nil {
+	if err = rows.Err(); err != nil {
 		return nil, fmt.Errorf("failed to get user workout movements: %w", err)
 	}
 
-	// Get actual performance WODs from user_workout_wods table
-	perfWODsQuery := `
+	return performanceMovements, nil
+}
+
+// performanceWODs gets the WODs performed in logged workouts
+func (r *UserWorkoutRepository) performanceWODs(ctx context.Context, ids []interface{}) ([]*domain.UserWorkoutWOD, error) {
+	query := `
 		SELECT uww.id, uww.user_workout_id, uww.wod_id, uww.score_type, uww.score_value,
 		       uww.time_seconds, uww.rounds, uww.reps, uww.weight, uww.notes,
 		       uww.order_index, uww.created_at, uww.updated_at,
 		       w.name as wod_name, w.type as wod_type, w.regime as wod_regime
 		FROM user_workout_wods uww
 		JOIN wods w ON uww.wod_id = w.id
-		WHERE uww.user_workout_id = ?
-		ORDER BY uww.order_index`
+		WHERE uww.user_workout_id IN ` + inList(len(ids)) + `
+		ORDER BY uww.user_workout_id, uww.order_index`
 
-	perfWODRows, err := r.db.Query(perfWODsQuery, id)
+	rows, err := r.db.QueryContext(ctx, query, ids...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user workout WODs: %w", err)
 	}
-	defer perfWODRows.Close()
+	defer rows.Close()
 
 	var performanceWODs []*domain.UserWorkoutWOD
-	for perfWODRows.Next() {
+	for rows.Next() {
 		uww := &domain.UserWorkoutWOD{}
 		var scoreType sql.NullString
 		var scoreValue sql.NullString
@@ -327,7 +418,7 @@ func (r *UserWorkoutRepository) GetByIDWithDetails(id int64, userID int64) (*dom
 		var wodType string
 		var wodRegime string
 
-		err := perfWODRows.Scan(&uww.ID, &uww.UserWorkoutID, &uww.WODID, &scoreType, &scoreValue,
+		err := rows.Scan(&uww.ID, &uww.UserWorkoutID, &uww.WODID, &scoreType, &scoreValue,
 			&timeSeconds, &rounds, &reps, &weight, &notes,
 			&uww.OrderIndex, &uww.CreatedAt, &uww.UpdatedAt,
 			&wodName, &wodType, &wodRegime)
@@ -370,29 +461,18 @@ func (r *UserWorkoutRepository) GetByIDWithDetails(id int64, userID int64) (*dom
 
 		performanceWODs = append(performanceWODs, uww)
 	}
-	if err = perfWODRows.Err(); err != nil {
+	if err = rows.Err(); err != nil {
 		return nil, fmt.Errorf("failed to get user workout WODs: %w", err)
 	}
 
-	// Construct the detailed response
-	result := &domain.UserWorkoutWithDetails{
-		UserWorkout:          *userWorkout,
-		WorkoutName:          workoutName,
-		WorkoutDescription:   workoutDescription,
-		Movements:            movements,
-		WODs:                 wods,
-		PerformanceMovements: performanceMovements,
-		PerformanceWODs:      performanceWODs,
-	}
-
-	return result, nil
+	return performanceWODs, nil
 }
 
 // ListByUser retrieves all workouts logged by a specific user
-func (r *UserWorkoutRepository) ListByUser(userID int64, limit, offset int) ([]*domain.UserWorkout, error) {
+func (r *UserWorkoutRepository) ListByUser(ctx context.Context, userID int64, limit, offset int) ([]*domain.UserWorkout, error) {
 	query := `SELECT id, user_id, workout_id, workout_date, workout_type, total_time, notes, created_at, updated_at FROM user_workouts WHERE user_id = ? ORDER BY workout_date DESC, created_at DESC LIMIT ? OFFSET ?`
 
-	rows, err := r.db.Query(query, userID, limit, offset)
+	rows, err := r.db.QueryContext(ctx, query, userID, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list user workouts: %w", err)
 	}
@@ -401,32 +481,246 @@ func (r *UserWorkoutRepository) ListByUser(userID int64, limit, offset int) ([]*
 	return r.scanUserWorkouts(rows)
 }
 
-// ListByUserWithDetails retrieves all workouts logged by a user with details
-func (r *UserWorkoutRepository) ListByUserWithDetails(userID int64, limit, offset int) ([]*domain.UserWorkoutWithDetails, error) {
-	// Get user workouts
-	userWorkouts, err := r.ListByUser(userID, limit, offset)
+// workoutSorts gives the expression each order sorts by, and whether it is
+// descending. Ties are broken by ID in the same direction, so that every
+// row has a distinct position for cursors to point at.
+var workoutSorts = map[string]struct {
+	key  string
+	desc bool
+}{
+	domain.WorkoutSortDateDesc: {`DATE(uw.workout_date)`, true},
+	domain.WorkoutSortDateAsc:  {`DATE(uw.workout_date)`, false},
+	domain.WorkoutSortNameAsc:  {`COALESCE(w.name, uw.workout_name, '')`, false},
+	domain.WorkoutSortNameDesc: {`COALESCE(w.name, uw.workout_name, '')`, true},
+}
+
+// workoutCursor is the position of the last workout on a page. Cursors are
+// handed out as base64-encoded JSON.
+type workoutCursor struct {
+	Sort string `json:"s"`
+	Key  string `json:"k"`
+	ID   int64  `json:"id"`
+}
+
+// encodeWorkoutCursor returns the cursor for the page after a workout
+func encodeWorkoutCursor(cursor workoutCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeWorkoutCursor parses a cursor, which must be for the given sort
+func decodeWorkoutCursor(s, sort string) (*workoutCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
 	if err != nil {
-		return nil, err
+		return nil, domain.ErrInvalidCursor
+	}
+	var cursor workoutCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.Sort != sort {
+		return nil, domain.ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// sortKey turns a scanned sort key into the text a cursor holds. Dates come
+// back as text from SQLite and as times from PostgreSQL and MySQL.
+func sortKey(value interface{}) string {
+	switch v := value.(type) {
+	case time.Time:
+		return v.Format("2006-01-02")
+	case []byte:
+		return string(v)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// ListWithDetails retrieves a page of the workouts logged by a user that
+// match the filter, with details. The page and its details are read with a
+// fixed number of queries, however many workouts it has.
+func (r *UserWorkoutRepository) ListWithDetails(ctx context.Context, userID int64, filter domain.WorkoutFilter) (*domain.WorkoutPage, error) {
+	if filter.Sort == "" {
+		filter.Sort = domain.WorkoutSortDateDesc
+	}
+	sort, ok := workoutSorts[filter.Sort]
+	if !ok {
+		return nil, fmt.Errorf("unknown workout sort %q", filter.Sort)
+	}
+	if filter.Limit < 0 || filter.Offset < 0 {
+		return nil, fmt.Errorf("invalid workout page size %d or offset %d", filter.Limit, filter.Offset)
 	}
 
-	var results []*domain.UserWorkoutWithDetails
-	for _, uw := range userWorkouts {
-		// Get details for each workout
-		details, err := r.GetByIDWithDetails(uw.ID, userID)
+	conditions := []string{`uw.user_id = ?`}
+	args := []interface{}{userID}
+
+	if filter.StartDate != nil {
+		conditions = append(conditions, `DATE(uw.workout_date) >= ?`)
+		args = append(args, filter.StartDate.Format("2006-01-02"))
+	}
+	if filter.EndDate != nil {
+		conditions = append(conditions, `DATE(uw.workout_date) <= ?`)
+		args = append(args, filter.EndDate.Format("2006-01-02"))
+	}
+	if filter.MovementID != nil {
+		conditions = append(conditions, `(EXISTS (SELECT 1 FROM user_workout_movements uwm WHERE uwm.user_workout_id = uw.id AND uwm.movement_id = ?)
+			OR EXISTS (SELECT 1 FROM workout_movements wm WHERE wm.workout_id = uw.workout_id AND wm.movement_id = ?))`)
+		args = append(args, *filter.MovementID, *filter.MovementID)
+	}
+	if filter.WODID != nil {
+		conditions = append(conditions, `(EXISTS (SELECT 1 FROM user_workout_wods uww WHERE uww.user_workout_id = uw.id AND uww.wod_id = ?)
+			OR EXISTS (SELECT 1 FROM workout_wods ww WHERE ww.workout_id = uw.workout_id AND ww.wod_id = ?))`)
+		args = append(args, *filter.WODID, *filter.WODID)
+	}
+	if filter.WorkoutType != "" {
+		conditions = append(conditions, `uw.workout_type = ?`)
+		args = append(args, filter.WorkoutType)
+	}
+	if filter.HasPR != nil {
+		hasPR := `(EXISTS (SELECT 1 FROM user_workout_movements uwm WHERE uwm.user_workout_id = uw.id AND uwm.is_pr = ?)
+			OR EXISTS (SELECT 1 FROM user_workout_wods uww WHERE uww.user_workout_id = uw.id AND uww.is_pr = ?))`
+		if !*filter.HasPR {
+			hasPR = `NOT ` + hasPR
+		}
+		conditions = append(conditions, hasPR)
+		args = append(args, true, true)
+	}
+	if filter.TemplateID != nil {
+		conditions = append(conditions, `uw.workout_id = ?`)
+		args = append(args, *filter.TemplateID)
+	}
+	if q := strings.TrimSpace(filter.Query); q != "" {
+		pattern := containsPattern(strings.ToLower(q))
+		conditions = append(conditions, `(LOWER(COALESCE(w.name, uw.workout_name, '')) LIKE ? ESCAPE '!' OR LOWER(COALESCE(uw.notes, '')) LIKE ? ESCAPE '!')`)
+		args = append(args, pattern, pattern)
+	}
+
+	direction, comparison := "ASC", ">"
+	if sort.desc {
+		direction, comparison = "DESC", "<"
+	}
+	if filter.Cursor != "" {
+		cursor, err := decodeWorkoutCursor(filter.Cursor, filter.Sort)
 		if err != nil {
 			return nil, err
 		}
-		results = append(results, details)
+		conditions = append(conditions, `(`+sort.key+` `+comparison+` ? OR (`+sort.key+` = ? AND uw.id `+comparison+` ?))`)
+		args = append(args, cursor.Key, cursor.Key, cursor.ID)
+	}
+
+	// One more than a page, to tell whether there is a next one
+	limit := 0
+	if filter.Limit > 0 {
+		limit = filter.Limit + 1
+	}
+	query := `SELECT uw.id, uw.user_id, uw.workout_id, uw.workout_name, uw.workout_date, uw.workout_type, uw.total_time,
+	                 uw.notes, uw.created_at, uw.updated_at,
+	                 COALESCE(w.name, uw.workout_name, ''), w.notes, ` + sort.key + `
+	          FROM user_workouts uw
+	          LEFT JOIN workouts w ON uw.workout_id = w.id
+	          WHERE ` + strings.Join(conditions, " AND ") + `
+	          ORDER BY ` + sort.key + ` ` + direction + `, uw.id ` + direction +
+		r.db.Dialect.Limit(limit, filter.Offset)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user workouts: %w", err)
 	}
+	defer rows.Close()
 
-	return results, nil
+	page := &domain.WorkoutPage{Workouts: []*domain.UserWorkoutWithDetails{}}
+	var lastKey interface{}
+	for rows.Next() {
+		details := &domain.UserWorkoutWithDetails{}
+		var workoutID sql.NullInt64
+		var workoutName sql.NullString
+		var workoutType sql.NullString
+		var totalTime sql.NullInt64
+		var notes sql.NullString
+		var workoutDescription sql.NullString
+		var key interface{}
+
+		err := rows.Scan(
+			&details.ID,
+			&details.UserID,
+			&workoutID,
+			&workoutName,
+			&details.WorkoutDate,
+			&workoutType,
+			&totalTime,
+			&notes,
+			&details.CreatedAt,
+			&details.UpdatedAt,
+			&details.WorkoutName,
+			&workoutDescription,
+			&key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user workout: %w", err)
+		}
+
+		if filter.Limit > 0 && len(page.Workouts) == filter.Limit {
+			page.NextCursor = encodeWorkoutCursor(workoutCursor{
+				Sort: filter.Sort,
+				Key:  sortKey(lastKey),
+				ID:   page.Workouts[len(page.Workouts)-1].ID,
+			})
+			break
+		}
+
+		if workoutID.Valid {
+			wid := workoutID.Int64
+			details.UserWorkout.WorkoutID = &wid
+		}
+		if workoutName.Valid {
+			details.UserWorkout.WorkoutName = &workoutName.String
+		}
+		if workoutType.Valid {
+			details.WorkoutType = &workoutType.String
+		}
+		if totalTime.Valid {
+			t := int(totalTime.Int64)
+			details.TotalTime = &t
+		}
+		if notes.Valid {
+			details.Notes = &notes.String
+		}
+		if workoutDescription.Valid {
+			details.WorkoutDescription = &workoutDescription.String
+		}
+
+		page.Workouts = append(page.Workouts, details)
+		lastKey = key
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list user workouts: %w", err)
+	}
+	rows.Close()
+
+	if err := r.loadDetails(ctx, page.Workouts); err != nil {
+		return nil, err
+	}
+
+	return page, nil
 }
 
 // ListByUserAndDateRange retrieves workouts within a date range
-func (r *UserWorkoutRepository) ListByUserAndDateRange(userID int64, startDate, endDate time.Time) ([]*domain.UserWorkout, error) {
+func (r *UserWorkoutRepository) ListByUserAndDateRange(ctx context.Context, userID int64, startDate, endDate time.Time) ([]*domain.UserWorkout, error) {
 	query := `SELECT id, user_id, workout_id, workout_date, workout_type, total_time, notes, created_at, updated_at FROM user_workouts WHERE user_id = ? AND workout_date >= ? AND workout_date <= ? ORDER BY workout_date DESC`
 
-	rows, err := r.db.Query(query, userID, startDate, endDate)
+	rows, err := r.db.QueryContext(ctx, query, userID, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user workouts by date range: %w", err)
+	}
+	defer rows.Close()
+
+	return r.scanUserWorkouts(rows)
+}
+
+// ListByDateRange retrieves all users' workouts within a date range
+func (r *UserWorkoutRepository) ListByDateRange(ctx context.Context, startDate, endDate time.Time) ([]*domain.UserWorkout, error) {
+	query := `SELECT id, user_id, workout_id, workout_date, workout_type, total_time, notes, created_at, updated_at FROM user_workouts WHERE workout_date >= ? AND workout_date <= ? ORDER BY workout_date, id`
+
+	rows, err := r.db.QueryContext(ctx, query, startDate, endDate)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list user workouts by date range: %w", err)
 	}
@@ -436,7 +730,7 @@ func (r *UserWorkoutRepository) ListByUserAndDateRange(userID int64, startDate,
 }
 
 // Update updates an existing user workout
-func (r *UserWorkoutRepository) Update(userWorkout *domain.UserWorkout) error {
+func (r *UserWorkoutRepository) Update(ctx context.Context, userWorkout *domain.UserWorkout) error {
 	userWorkout.UpdatedAt = time.Now()
 
 	query := `UPDATE user_workouts
@@ -444,7 +738,7 @@ func (r *UserWorkoutRepository) Update(userWorkout *domain.UserWorkout) error {
 	              notes = ?, updated_at = ?
 	          WHERE id = ? AND user_id = ?`
 
-	result, err := r.db.Exec(query, userWorkout.WorkoutName, userWorkout.WorkoutDate, userWorkout.WorkoutType, userWorkout.TotalTime, userWorkout.Notes, userWorkout.UpdatedAt, userWorkout.ID, userWorkout.UserID)
+	result, err := r.db.ExecContext(ctx, query, userWorkout.WorkoutName, userWorkout.WorkoutDate, userWorkout.WorkoutType, userWorkout.TotalTime, userWorkout.Notes, userWorkout.UpdatedAt, userWorkout.ID, userWorkout.UserID)
 	if err != nil {
 		return fmt.Errorf("failed to update user workout: %w", err)
 	}
@@ -462,10 +756,10 @@ func (r *UserWorkoutRepository) Update(userWorkout *domain.UserWorkout) error {
 }
 
 // Delete deletes a user workout
-func (r *UserWorkoutRepository) Delete(id int64, userID int64) error {
+func (r *UserWorkoutRepository) Delete(ctx context.Context, id int64, userID int64) error {
 	query := `DELETE FROM user_workouts WHERE id = ? AND user_id = ?`
 
-	result, err := r.db.Exec(query, id, userID)
+	result, err := r.db.ExecContext(ctx, query, id, userID)
 	if err != nil {
 		return fmt.Errorf("failed to delete user workout: %w", err)
 	}
@@ -483,7 +777,7 @@ func (r *UserWorkoutRepository) Delete(id int64, userID int64) error {
 }
 
 // GetByUserWorkoutDate checks if a user has already logged a specific workout on a date
-func (r *UserWorkoutRepository) GetByUserWorkoutDate(userID, workoutID int64, date time.Time) (*domain.UserWorkout, error) {
+func (r *UserWorkoutRepository) GetByUserWorkoutDate(ctx context.Context, userID, workoutID int64, date time.Time) (*domain.UserWorkout, error) {
 	query := `SELECT id, user_id, workout_id, workout_date, workout_type, total_time, notes, created_at, updated_at FROM user_workouts WHERE user_id = ? AND workout_id = ? AND DATE(workout_date) = DATE(?)`
 
 	userWorkout := &domain.UserWorkout{}
@@ -491,7 +785,7 @@ func (r *UserWorkoutRepository) GetByUserWorkoutDate(userID, workoutID int64, da
 	var totalTime sql.NullInt64
 	var notes sql.NullString
 
-	err := r.db.QueryRow(query, userID, workoutID, date).Scan(&userWorkout.ID, &userWorkout.UserID, &userWorkout.WorkoutID, &userWorkout.WorkoutDate, &workoutType, &totalTime, &notes, &userWorkout.CreatedAt, &userWorkout.UpdatedAt)
+	err := r.db.QueryRowContext(ctx, query, userID, workoutID, date).Scan(&userWorkout.ID, &userWorkout.UserID, &userWorkout.WorkoutID, &userWorkout.WorkoutDate, &workoutType, &totalTime, &notes, &userWorkout.CreatedAt, &userWorkout.UpdatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -514,11 +808,11 @@ func (r *UserWorkoutRepository) GetByUserWorkoutDate(userID, workoutID int64, da
 }
 
 // Count counts total user workouts for a specific user
-func (r *UserWorkoutRepository) Count(userID int64) (int64, error) {
+func (r *UserWorkoutRepository) Count(ctx context.Context, userID int64) (int64, error) {
 	var count int64
 	query := `SELECT COUNT(*) FROM user_workouts WHERE user_id = ?`
 
-	err := r.db.QueryRow(query, userID).Scan(&count)
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("failed to count user workouts: %w", err)
 	}
@@ -527,7 +821,7 @@ func (r *UserWorkoutRepository) Count(userID int64) (int64, error) {
 }
 
 // GetRecentForUser retrieves recent user workouts with details (for dashboard/activity feed)
-func (r *UserWorkoutRepository) GetRecentForUser(userID int64, limit int) ([]*domain.UserWorkoutWithDetails, error) {
+func (r *UserWorkoutRepository) GetRecentForUser(ctx context.Context, userID int64, limit int) ([]*domain.UserWorkoutWithDetails, error) {
 	query := `SELECT uw.id, uw.user_id, uw.workout_id, uw.workout_date, uw.workout_type, uw.total_time,
 	                 uw.notes, uw.created_at, uw.updated_at,
 	                 w.name as workout_name, w.notes as workout_description
@@ -537,7 +831,7 @@ func (r *UserWorkoutRepository) GetRecentForUser(userID int64, limit int) ([]*do
 	          ORDER BY uw.workout_date DESC, uw.created_at DESC
 	          LIMIT ?`
 
-	rows, err := r.db.Query(query, userID, limit)
+	rows, err := r.db.QueryContext(ctx, query, userID, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get recent user workouts: %w", err)
 	}
@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/johnzastrow/actalog/internal/domain"
+)
+
+func TestUserWorkoutRepositoryListWithDetails(t *testing.T) {
+	sqlDB, err := InitDatabase("sqlite3", filepath.Join(t.TempDir(), "actalog.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sqlDB.Close()
+	ctx := context.Background()
+	repo := NewUserWorkoutRepository(sqlDB)
+
+	for _, query := range []string{
+		`INSERT INTO users (id, email, password_hash, name, role, created_at, updated_at) VALUES (1, 'a@example.com', 'x', 'A', 'user', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`,
+		`INSERT INTO users (id, email, password_hash, name, role, created_at, updated_at) VALUES (2, 'b@example.com', 'x', 'B', 'user', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`,
+	} {
+		if _, err := sqlDB.Exec(query); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var templateID, templateMovementID, backSquatID, franID int64
+	sqlDB.QueryRow(`SELECT id FROM workouts WHERE name = 'Gymnastics Strength'`).Scan(&templateID)
+	sqlDB.QueryRow(`SELECT movement_id FROM workout_movements WHERE workout_id = ?`, templateID).Scan(&templateMovementID)
+	sqlDB.QueryRow(`SELECT id FROM movements WHERE name = 'Back Squat'`).Scan(&backSquatID)
+	sqlDB.QueryRow(`SELECT id FROM wods WHERE name = 'Fran'`).Scan(&franID)
+	if templateID == 0 || templateMovementID == 0 || backSquatID == 0 || franID == 0 {
+		t.Fatal("expected the standard template, movements and WODs")
+	}
+
+	str := func(s string) *string { return &s }
+	logged := make(map[string]int64)
+	for _, uw := range []*domain.UserWorkout{
+		{UserID: 1, WorkoutName: str("Heavy singles"), WorkoutDate: time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC), WorkoutType: str("strength"), Notes: str("Back squat triple")},
+		{UserID: 1, WorkoutID: &templateID, WorkoutDate: time.Date(2025, 10, 2, 0, 0, 0, 0, time.UTC)},
+		{UserID: 1, WorkoutName: str("Fran day"), WorkoutDate: time.Date(2025, 10, 3, 0, 0, 0, 0, time.UTC), WorkoutType: str("metcon")},
+		{UserID: 1, WorkoutName: str("Easy row"), WorkoutDate: time.Date(2025, 10, 3, 0, 0, 0, 0, time.UTC), WorkoutType: str("cardio")},
+		{UserID: 1, WorkoutName: str("Accessory"), WorkoutDate: time.Date(2025, 10, 5, 0, 0, 0, 0, time.UTC)},
+		{UserID: 2, WorkoutName: str("Not mine"), WorkoutDate: time.Date(2025, 10, 4, 0, 0, 0, 0, time.UTC)},
+	} {
+		if err := repo.Create(ctx, uw); err != nil {
+			t.Fatal(err)
+		}
+		name := "Gymnastics Strength"
+		if uw.WorkoutName != nil {
+			name = *uw.WorkoutName
+		}
+		logged[name] = uw.ID
+	}
+	if _, err := sqlDB.Exec(`INSERT INTO user_workout_movements (user_workout_id, movement_id, sets, reps, weight, is_pr, order_index, created_at, updated_at) VALUES (?, ?, 1, 3, 140, 1, 0, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`, logged["Heavy singles"], backSquatID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sqlDB.Exec(`INSERT INTO user_workout_wods (user_workout_id, wod_id, score_type, score_value, order_index, created_at, updated_at) VALUES (?, ?, 'Time (HH:MM:SS)', '00:04:30', 0, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`, logged["Fran day"], franID); err != nil {
+		t.Fatal(err)
+	}
+
+	// listAll follows the cursors through every page
+	listAll := func(filter domain.WorkoutFilter) ([]string, []*domain.UserWorkoutWithDetails) {
+		t.Helper()
+		var names []string
+		var workouts []*domain.UserWorkoutWithDetails
+		for pages := 0; ; pages++ {
+			if pages > 10 {
+				t.Fatal("expected the cursors to reach the last page")
+			}
+			page, err := repo.ListWithDetails(ctx, 1, filter)
+			if err != nil {
+				t.Fatalf("ListWithDetails(%+v) failed: %v", filter, err)
+			}
+			if len(page.Workouts) > filter.Limit {
+				t.Fatalf("expected at most %d workouts, got %d", filter.Limit, len(page.Workouts))
+			}
+			for _, workout := range page.Workouts {
+				names = append(names, workout.WorkoutName)
+				workouts = append(workouts, workout)
+			}
+			if page.NextCursor == "" {
+				return names, workouts
+			}
+			filter.Cursor = page.NextCursor
+		}
+	}
+	expect := func(filter domain.WorkoutFilter, want ...string) {
+		t.Helper()
+		got, _ := listAll(filter)
+		if len(got) != len(want) {
+			t.Errorf("%+v: expected %v, got %v", filter, want, got)
+			return
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("%+v: expected %v, got %v", filter, want, got)
+				return
+			}
+		}
+	}
+
+	// Sorting and paging, with ties on the date broken by ID
+	expect(domain.WorkoutFilter{Limit: 2}, "Accessory", "Easy row", "Fran day", "Gymnastics Strength", "Heavy singles")
+	expect(domain.WorkoutFilter{Limit: 2, Sort: domain.WorkoutSortDateAsc}, "Heavy singles", "Gymnastics Strength", "Fran day", "Easy row", "Accessory")
+	expect(domain.WorkoutFilter{Limit: 3, Sort: domain.WorkoutSortNameAsc}, "Accessory", "Easy row", "Fran day", "Gymnastics Strength", "Heavy singles")
+	expect(domain.WorkoutFilter{Limit: 5, Sort: domain.WorkoutSortNameDesc}, "Heavy singles", "Gymnastics Strength", "Fran day", "Easy row", "Accessory")
+
+	// Filters
+	start, end := time.Date(2025, 10, 2, 0, 0, 0, 0, time.UTC), time.Date(2025, 10, 3, 0, 0, 0, 0, time.UTC)
+	hasPR, noPR := true, false
+	expect(domain.WorkoutFilter{Limit: 10, StartDate: &start, EndDate: &end}, "Easy row", "Fran day", "Gymnastics Strength")
+	expect(domain.WorkoutFilter{Limit: 10, MovementID: &backSquatID}, "Heavy singles")
+	expect(domain.WorkoutFilter{Limit: 10, MovementID: &templateMovementID}, "Gymnastics Strength")
+	expect(domain.WorkoutFilter{Limit: 10, WODID: &franID}, "Fran day")
+	expect(domain.WorkoutFilter{Limit: 10, WorkoutType: "cardio"}, "Easy row")
+	expect(domain.WorkoutFilter{Limit: 10, HasPR: &hasPR}, "Heavy singles")
+	expect(domain.WorkoutFilter{Limit: 10, HasPR: &noPR}, "Accessory", "Easy row", "Fran day", "Gymnastics Strength")
+	expect(domain.WorkoutFilter{Limit: 10, TemplateID: &templateID}, "Gymnastics Strength")
+	expect(domain.WorkoutFilter{Limit: 10, Query: "SQUAT"}, "Heavy singles")
+	expect(domain.WorkoutFilter{Limit: 1, Query: "gymnastics"}, "Gymnastics Strength")
+	expect(domain.WorkoutFilter{Limit: 10, Query: "deadlift"})
+	expect(domain.WorkoutFilter{Limit: 10, Query: "%"})
+	expect(domain.WorkoutFilter{Limit: 10, Query: "_"})
+
+	// Offsets page without cursors, and no limit lists every workout at once
+	for _, tt := range []struct {
+		filter domain.WorkoutFilter
+		want   int
+	}{
+		{domain.WorkoutFilter{Limit: 2, Offset: 1}, 2},
+		{domain.WorkoutFilter{Offset: 3}, 2},
+		{domain.WorkoutFilter{}, 5},
+	} {
+		page, err := repo.ListWithDetails(ctx, 1, tt.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(page.Workouts) != tt.want || (tt.filter.Limit == 0 && page.NextCursor != "") {
+			t.Errorf("%+v: expected %d workouts on one page, got %d (next cursor %q)", tt.filter, tt.want, len(page.Workouts), page.NextCursor)
+		} else if tt.filter.Offset == 1 && (page.Workouts[0].WorkoutName != "Easy row" || page.Workouts[1].WorkoutName != "Fran day") {
+			t.Errorf("expected the second and third workouts, got %s and %s", page.Workouts[0].WorkoutName, page.Workouts[1].WorkoutName)
+		}
+	}
+
+	// Details are loaded for every workout on the page
+	_, workouts := listAll(domain.WorkoutFilter{Limit: 2})
+	for _, workout := range workouts {
+		switch workout.WorkoutName {
+		case "Gymnastics Strength":
+			if len(workout.Movements) == 0 || workout.Movements[0].Movement == nil {
+				t.Errorf("expected the template's movements, got %+v", workout.Movements)
+			}
+		case "Heavy singles":
+			if len(workout.PerformanceMovements) != 1 || workout.PerformanceMovements[0].MovementName != "Back Squat" {
+				t.Errorf("expected the performed back squat, got %+v", workout.PerformanceMovements)
+			}
+			if workout.Notes == nil || *workout.Notes != "Back squat triple" || workout.WorkoutType == nil || *workout.WorkoutType != "strength" {
+				t.Errorf("expected the workout's notes and type, got %+v", workout.UserWorkout)
+			}
+		case "Fran day":
+			if len(workout.PerformanceWODs) != 1 || workout.PerformanceWODs[0].WODName != "Fran" {
+				t.Errorf("expected the performed Fran, got %+v", workout.PerformanceWODs)
+			}
+		}
+	}
+
+	// Cursors only continue the listing they came from
+	page, err := repo.ListWithDetails(ctx, 1, domain.WorkoutFilter{Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, filter := range []domain.WorkoutFilter{
+		{Limit: 1, Sort: domain.WorkoutSortNameAsc, Cursor: page.NextCursor},
+		{Limit: 1, Cursor: "not a cursor"},
+	} {
+		if _, err := repo.ListWithDetails(ctx, 1, filter); !errors.Is(err, domain.ErrInvalidCursor) {
+			t.Errorf("%+v: expected ErrInvalidCursor, got %v", filter, err)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"time"
@@ -10,43 +11,38 @@ import (
 
 // UserWorkoutWODRepository implements domain.UserWorkoutWODRepository
 type UserWorkoutWODRepository struct {
-	db *sql.DB
+	db *DB
 }
 
 // NewUserWorkoutWODRepository creates a new user workout WOD repository
 func NewUserWorkoutWODRepository(db *sql.DB) *UserWorkoutWODRepository {
-	return &UserWorkoutWODRepository{db: db}
+	return &UserWorkoutWODRepository{db: NewDB(db)}
 }
 
 // Create creates a new user workout WOD performance record
-func (r *UserWorkoutWODRepository) Create(uww *domain.UserWorkoutWOD) error {
+func (r *UserWorkoutWODRepository) Create(ctx context.Context, uww *domain.UserWorkoutWOD) error {
 	uww.CreatedAt = time.Now()
 	uww.UpdatedAt = time.Now()
 
 	query := `INSERT INTO user_workout_wods (user_workout_id, wod_id, score_type, score_value, time_seconds, rounds, reps, weight, notes, is_pr, order_index, created_at, updated_at)
 	          VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
-	result, err := r.db.Exec(query, uww.UserWorkoutID, uww.WODID, uww.ScoreType, uww.ScoreValue, uww.TimeSeconds, uww.Rounds, uww.Reps, uww.Weight, uww.Notes, uww.IsPR, uww.OrderIndex, uww.CreatedAt, uww.UpdatedAt)
+	id, err := r.db.InsertContext(ctx, query, uww.UserWorkoutID, uww.WODID, uww.ScoreType, uww.ScoreValue, uww.TimeSeconds, uww.Rounds, uww.Reps, uww.Weight, uww.Notes, uww.IsPR, uww.OrderIndex, uww.CreatedAt, uww.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to create user workout WOD: %w", err)
 	}
 
-	id, err := result.LastInsertId()
-	if err != nil {
-		return fmt.Errorf("failed to get user workout WOD ID: %w", err)
-	}
-
 	uww.ID = id
 	return nil
 }
 
 // CreateBatch creates multiple user workout WOD records at once
-func (r *UserWorkoutWODRepository) CreateBatch(wods []*domain.UserWorkoutWOD) error {
+func (r *UserWorkoutWODRepository) CreateBatch(ctx context.Context, wods []*domain.UserWorkoutWOD) error {
 	if len(wods) == 0 {
 		return nil
 	}
 
-	tx, err := r.db.Begin()
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
@@ -55,26 +51,15 @@ func (r *UserWorkoutWODRepository) CreateBatch(wods []*domain.UserWorkoutWOD) er
 	query := `INSERT INTO user_workout_wods (user_workout_id, wod_id, score_type, score_value, time_seconds, rounds, reps, weight, notes, is_pr, order_index, created_at, updated_at)
 	          VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
-	stmt, err := tx.Prepare(query)
-	if err != nil {
-		return fmt.Errorf("failed to prepare statement: %w", err)
-	}
-	defer stmt.Close()
-
 	now := time.Now()
 	for _, uww := range wods {
 		uww.CreatedAt = now
 		uww.UpdatedAt = now
 
-		result, err := stmt.Exec(uww.UserWorkoutID, uww.WODID, uww.ScoreType, uww.ScoreValue, uww.TimeSeconds, uww.Rounds, uww.Reps, uww.Weight, uww.Notes, uww.IsPR, uww.OrderIndex, uww.CreatedAt, uww.UpdatedAt)
+		id, err := tx.InsertContext(ctx, query, uww.UserWorkoutID, uww.WODID, uww.ScoreType, uww.ScoreValue, uww.TimeSeconds, uww.Rounds, uww.Reps, uww.Weight, uww.Notes, uww.IsPR, uww.OrderIndex, uww.CreatedAt, uww.UpdatedAt)
 		if err != nil {
 			return fmt.Errorf("failed to insert user workout WOD: %w", err)
 		}
-
-		id, err := result.LastInsertId()
-		if err != nil {
-			return fmt.Errorf("failed to get user workout WOD ID: %w", err)
-		}
 		uww.ID = id
 	}
 
@@ -86,7 +71,7 @@ func (r *UserWorkoutWODRepository) CreateBatch(wods []*domain.UserWorkoutWOD) er
 }
 
 // GetByID retrieves a user workout WOD by ID
-func (r *UserWorkoutWODRepository) GetByID(id int64) (*domain.UserWorkoutWOD, error) {
+func (r *UserWorkoutWODRepository) GetByID(ctx context.Context, id int64) (*domain.UserWorkoutWOD, error) {
 	query := `SELECT id, user_workout_id, wod_id, score_type, score_value, time_seconds, rounds, reps, weight, notes, order_index, created_at, updated_at
 	          FROM user_workout_wods WHERE id = ?`
 
@@ -98,7 +83,7 @@ func (r *UserWorkoutWODRepository) GetByID(id int64) (*domain.UserWorkoutWOD, er
 	var reps sql.NullInt64
 	var weight sql.NullFloat64
 
-	err := r.db.QueryRow(query, id).Scan(&uww.ID, &uww.UserWorkoutID, &uww.WODID, &scoreType, &scoreValue, &timeSeconds, &rounds, &reps, &weight, &uww.Notes, &uww.OrderIndex, &uww.CreatedAt, &uww.UpdatedAt)
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&uww.ID, &uww.UserWorkoutID, &uww.WODID, &scoreType, &scoreValue, &timeSeconds, &rounds, &reps, &weight, &uww.Notes, &uww.OrderIndex, &uww.CreatedAt, &uww.UpdatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -132,17 +117,17 @@ func (r *UserWorkoutWODRepository) GetByID(id int64) (*domain.UserWorkoutWOD, er
 }
 
 // GetByUserWorkoutID retrieves all WODs for a specific logged workout
-func (r *UserWorkoutWODRepository) GetByUserWorkoutID(userWorkoutID int64) ([]*domain.UserWorkoutWOD, error) {
+func (r *UserWorkoutWODRepository) GetByUserWorkoutID(ctx context.Context, userWorkoutID int64) ([]*domain.UserWorkoutWOD, error) {
 	query := `
 		SELECT uww.id, uww.user_workout_id, uww.wod_id, uww.score_type, uww.score_value, uww.time_seconds, uww.rounds, uww.reps, uww.weight,
-		       uww.notes, uww.order_index, uww.created_at, uww.updated_at,
+		       uww.notes, uww.is_pr, uww.order_index, uww.created_at, uww.updated_at,
 		       w.id as wod_id, w.name, w.source, w.type, w.regime, w.score_type as wod_score_type, w.description, w.url, w.notes as wod_notes, w.is_standard, w.created_by, w.created_at, w.updated_at
 		FROM user_workout_wods uww
 		JOIN wods w ON uww.wod_id = w.id
 		WHERE uww.user_workout_id = ?
 		ORDER BY uww.order_index`
 
-	rows, err := r.db.Query(query, userWorkoutID)
+	rows, err := r.db.QueryContext(ctx, query, userWorkoutID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user workout WODs: %w", err)
 	}
@@ -164,7 +149,7 @@ func (r *UserWorkoutWODRepository) GetByUserWorkoutID(userWorkoutID int64) ([]*d
 		var createdBy sql.NullInt64
 
 		err := rows.Scan(&uww.ID, &uww.UserWorkoutID, &uww.WODID, &scoreType, &scoreValue, &timeSeconds, &rounds, &reps, &weight,
-			&uww.Notes, &uww.OrderIndex, &uww.CreatedAt, &uww.UpdatedAt,
+			&uww.Notes, &uww.IsPR, &uww.OrderIndex, &uww.CreatedAt, &uww.UpdatedAt,
 			&uww.WOD.ID, &uww.WOD.Name, &uww.WOD.Source, &uww.WOD.Type, &uww.WOD.Regime, &uww.WOD.ScoreType, &uww.WOD.Description, &wodURL, &wodNotes, &uww.WOD.IsStandard, &createdBy, &uww.WOD.CreatedAt, &uww.WOD.UpdatedAt)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan user workout WOD: %w", err)
@@ -213,14 +198,14 @@ func (r *UserWorkoutWODRepository) GetByUserWorkoutID(userWorkoutID int64) ([]*d
 }
 
 // Update updates an existing user workout WOD
-func (r *UserWorkoutWODRepository) Update(uww *domain.UserWorkoutWOD) error {
+func (r *UserWorkoutWODRepository) Update(ctx context.Context, uww *domain.UserWorkoutWOD) error {
 	uww.UpdatedAt = time.Now()
 
 	query := `UPDATE user_workout_wods
 	          SET score_type = ?, score_value = ?, time_seconds = ?, rounds = ?, reps = ?, weight = ?, notes = ?, order_index = ?, updated_at = ?
 	          WHERE id = ?`
 
-	result, err := r.db.Exec(query, uww.ScoreType, uww.ScoreValue, uww.TimeSeconds, uww.Rounds, uww.Reps, uww.Weight, uww.Notes, uww.OrderIndex, uww.UpdatedAt, uww.ID)
+	result, err := r.db.ExecContext(ctx, query, uww.ScoreType, uww.ScoreValue, uww.TimeSeconds, uww.Rounds, uww.Reps, uww.Weight, uww.Notes, uww.OrderIndex, uww.UpdatedAt, uww.ID)
 	if err != nil {
 		return fmt.Errorf("failed to update user workout WOD: %w", err)
 	}
@@ -238,10 +223,10 @@ func (r *UserWorkoutWODRepository) Update(uww *domain.UserWorkoutWOD) error {
 }
 
 // Delete deletes a user workout WOD
-func (r *UserWorkoutWODRepository) Delete(id int64) error {
+func (r *UserWorkoutWODRepository) Delete(ctx context.Context, id int64) error {
 	query := `DELETE FROM user_workout_wods WHERE id = ?`
 
-	result, err := r.db.Exec(query, id)
+	result, err := r.db.ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete user workout WOD: %w", err)
 	}
@@ -259,10 +244,10 @@ func (r *UserWorkoutWODRepository) Delete(id int64) error {
 }
 
 // DeleteByUserWorkoutID deletes all WODs for a logged workout
-func (r *UserWorkoutWODRepository) DeleteByUserWorkoutID(userWorkoutID int64) error {
+func (r *UserWorkoutWODRepository) DeleteByUserWorkoutID(ctx context.Context, userWorkoutID int64) error {
 	query := `DELETE FROM user_workout_wods WHERE user_workout_id = ?`
 
-	_, err := r.db.Exec(query, userWorkoutID)
+	_, err := r.db.ExecContext(ctx, query, userWorkoutID)
 	if err != nil {
 		return fmt.Errorf("failed to delete user workout WODs: %w", err)
 	}
@@ -271,7 +256,7 @@ func (r *UserWorkoutWODRepository) DeleteByUserWorkoutID(userWorkoutID int64) er
 }
 
 // GetBestTimeForWOD retrieves the fastest time for a specific WOD for a user
-func (r *UserWorkoutWODRepository) GetBestTimeForWOD(userID, wodID int64) (*int, error) {
+func (r *UserWorkoutWODRepository) GetBestTimeForWOD(ctx context.Context, userID, wodID int64) (*int, error) {
 	query := `
 		SELECT MIN(uww.time_seconds)
 		FROM user_workout_wods uww
@@ -279,7 +264,7 @@ func (r *UserWorkoutWODRepository) GetBestTimeForWOD(userID, wodID int64) (*int,
 		WHERE uw.user_id = ? AND uww.wod_id = ? AND uww.time_seconds IS NOT NULL`
 
 	var bestTime sql.NullInt64
-	err := r.db.QueryRow(query, userID, wodID).Scan(&bestTime)
+	err := r.db.QueryRowContext(ctx, query, userID, wodID).Scan(&bestTime)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -297,7 +282,7 @@ func (r *UserWorkoutWODRepository) GetBestTimeForWOD(userID, wodID int64) (*int,
 
 // GetBestRoundsRepsForWOD retrieves the best rounds+reps for a specific WOD for a user
 // Returns the most rounds, and if tied, the most reps
-func (r *UserWorkoutWODRepository) GetBestRoundsRepsForWOD(userID, wodID int64) (rounds *int, reps *int, err error) {
+func (r *UserWorkoutWODRepository) GetBestRoundsRepsForWOD(ctx context.Context, userID, wodID int64) (rounds *int, reps *int, err error) {
 	query := `
 		SELECT uww.rounds, uww.reps
 		FROM user_workout_wods uww
@@ -308,7 +293,7 @@ func (r *UserWorkoutWODRepository) GetBestRoundsRepsForWOD(userID, wodID int64)
 
 	var roundsVal sql.NullInt64
 	var repsVal sql.NullInt64
-	err = r.db.QueryRow(query, userID, wodID).Scan(&roundsVal, &repsVal)
+	err = r.db.QueryRowContext(ctx, query, userID, wodID).Scan(&roundsVal, &repsVal)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil, nil
@@ -329,7 +314,7 @@ func (r *UserWorkoutWODRepository) GetBestRoundsRepsForWOD(userID, wodID int64)
 }
 
 // GetPRWODs retrieves recent PR-flagged WODs for a user
-func (r *UserWorkoutWODRepository) GetPRWODs(userID int64, limit int) ([]*domain.UserWorkoutWOD, error) {
+func (r *UserWorkoutWODRepository) GetPRWODs(ctx context.Context, userID int64, limit int) ([]*domain.UserWorkoutWOD, error) {
 	query := `
 		SELECT uww.id, uww.user_workout_id, uww.wod_id, uww.score_type, uww.score_value, uww.time_seconds, uww.rounds, uww.reps, uww.weight,
 		       uww.notes, uww.is_pr, uww.order_index, uww.created_at, uww.updated_at,
@@ -338,11 +323,11 @@ func (r *UserWorkoutWODRepository) GetPRWODs(userID int64, limit int) ([]*domain
 		FROM user_workout_wods uww
 		JOIN wods w ON uww.wod_id = w.id
 		JOIN user_workouts uw ON uww.user_workout_id = uw.id
-		WHERE uw.user_id = ? AND uww.is_pr = 1
+		WHERE uw.user_id = ? AND uww.is_pr = ?
 		ORDER BY uw.workout_date DESC, uww.created_at DESC
 		LIMIT ?`
 
-	rows, err := r.db.Query(query, userID, limit)
+	rows, err := r.db.QueryContext(ctx, query, userID, true, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get PR WODs: %w", err)
 	}
@@ -399,9 +384,9 @@ func (r *UserWorkoutWODRepository) GetPRWODs(userID int64, limit int) ([]*domain
 }
 
 // UpdatePRFlag updates the is_pr flag for a user workout WOD
-func (r *UserWorkoutWODRepository) UpdatePRFlag(id int64, isPR bool) error {
+func (r *UserWorkoutWODRepository) UpdatePRFlag(ctx context.Context, id int64, isPR bool) error {
 	query := `UPDATE user_workout_wods SET is_pr = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`
-	result, err := r.db.Exec(query, isPR, id)
+	result, err := r.db.ExecContext(ctx, query, isPR, id)
 	if err != nil {
 		return fmt.Errorf("failed to update PR flag: %w", err)
 	}
@@ -419,7 +404,7 @@ func (r *UserWorkoutWODRepository) UpdatePRFlag(id int64, isPR bool) error {
 }
 
 // GetByUserIDAndWODID retrieves all WOD performance records for a specific user and WOD
-func (r *UserWorkoutWODRepository) GetByUserIDAndWODID(userID, wodID int64, limit int) ([]*domain.UserWorkoutWOD, error) {
+func (r *UserWorkoutWODRepository) GetByUserIDAndWODID(ctx context.Context, userID, wodID int64, limit int) ([]*domain.UserWorkoutWOD, error) {
 	query := `
 		SELECT uww.id, uww.user_workout_id, uww.wod_id, uww.score_type, uww.score_value,
 		       uww.time_seconds, uww.rounds, uww.reps, uww.weight, uww.notes, uww.is_pr,
@@ -433,7 +418,7 @@ func (r *UserWorkoutWODRepository) GetByUserIDAndWODID(userID, wodID int64, limi
 		ORDER BY uw.workout_date DESC, uww.created_at DESC
 		LIMIT ?`
 
-	rows, err := r.db.Query(query, userID, wodID, limit)
+	rows, err := r.db.QueryContext(ctx, query, userID, wodID, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query WOD performances: %w", err)
 	}
@@ -0,0 +1,310 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/johnzastrow/actalog/internal/domain"
+)
+
+// WebhookRepository implements domain.WebhookRepository
+type WebhookRepository struct {
+	db *DB
+}
+
+// NewWebhookRepository creates a new webhook repository
+func NewWebhookRepository(db *sql.DB) *WebhookRepository {
+	return &WebhookRepository{db: NewDB(db)}
+}
+
+const webhookColumns = `id, user_id, url, secret, events, all_users, description, is_active, created_at, updated_at`
+
+// scanWebhook scans a webhook row into a domain.Webhook
+func scanWebhook(scanner interface{ Scan(...interface{}) error }) (*domain.Webhook, error) {
+	webhook := &domain.Webhook{}
+	var events string
+	var description sql.NullString
+	err := scanner.Scan(
+		&webhook.ID,
+		&webhook.UserID,
+		&webhook.URL,
+		&webhook.Secret,
+		&events,
+		&webhook.AllUsers,
+		&description,
+		&webhook.IsActive,
+		&webhook.CreatedAt,
+		&webhook.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	webhook.Events = splitWebhookEvents(events)
+	if description.Valid {
+		webhook.Description = &description.String
+	}
+	return webhook, nil
+}
+
+// splitWebhookEvents converts the stored comma-separated event list into a slice
+func splitWebhookEvents(events string) []string {
+	result := []string{}
+	for _, e := range strings.Split(events, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+// Create creates a new webhook
+func (r *WebhookRepository) Create(ctx context.Context, webhook *domain.Webhook) error {
+	now := time.Now()
+	webhook.CreatedAt = now
+	webhook.UpdatedAt = now
+
+	query := `INSERT INTO webhooks (user_id, url, secret, events, all_users, description, is_active, created_at, updated_at)
+	          VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	id, err := r.db.InsertContext(ctx, query,
+		webhook.UserID,
+		webhook.URL,
+		webhook.Secret,
+		strings.Join(webhook.Events, ","),
+		webhook.AllUsers,
+		webhook.Description,
+		webhook.IsActive,
+		webhook.CreatedAt,
+		webhook.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook: %w", err)
+	}
+
+	webhook.ID = id
+	return nil
+}
+
+// GetByID retrieves a webhook by ID
+func (r *WebhookRepository) GetByID(ctx context.Context, id int64) (*domain.Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks WHERE id = ?`
+
+	webhook, err := scanWebhook(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook: %w", err)
+	}
+
+	return webhook, nil
+}
+
+// ListByUser retrieves all webhooks registered by a user
+func (r *WebhookRepository) ListByUser(ctx context.Context, userID int64) ([]*domain.Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks WHERE user_id = ? ORDER BY created_at DESC`
+	return r.list(ctx, query, userID)
+}
+
+// ListActiveForUser retrieves active webhooks that receive events for a user
+func (r *WebhookRepository) ListActiveForUser(ctx context.Context, userID int64) ([]*domain.Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks
+	          WHERE is_active = ? AND (user_id = ? OR all_users = ?)
+	          ORDER BY id`
+	return r.list(ctx, query, true, userID, true)
+}
+
+func (r *WebhookRepository) list(ctx context.Context, query string, args ...interface{}) ([]*domain.Webhook, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	webhooks := []*domain.Webhook{}
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook: %w", err)
+		}
+		webhooks = append(webhooks, webhook)
+	}
+
+	return webhooks, rows.Err()
+}
+
+// Update updates an existing webhook
+func (r *WebhookRepository) Update(ctx context.Context, webhook *domain.Webhook) error {
+	webhook.UpdatedAt = time.Now()
+
+	query := `UPDATE webhooks
+	          SET url = ?, secret = ?, events = ?, all_users = ?, description = ?, is_active = ?, updated_at = ?
+	          WHERE id = ?`
+
+	_, err := r.db.ExecContext(ctx, query,
+		webhook.URL,
+		webhook.Secret,
+		strings.Join(webhook.Events, ","),
+		webhook.AllUsers,
+		webhook.Description,
+		webhook.IsActive,
+		webhook.UpdatedAt,
+		webhook.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook: %w", err)
+	}
+
+	return nil
+}
+
+// Delete deletes a webhook and its delivery log
+func (r *WebhookRepository) Delete(ctx context.Context, id int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM webhook_deliveries WHERE webhook_id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete webhook deliveries: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM webhooks WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// CreateDelivery records a new delivery
+func (r *WebhookRepository) CreateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	delivery.CreatedAt = time.Now()
+
+	query := `INSERT INTO webhook_deliveries (webhook_id, event_type, payload, attempts, status_code, error, success, created_at, delivered_at, next_attempt_at)
+	          VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	id, err := r.db.InsertContext(ctx, query,
+		delivery.WebhookID,
+		delivery.EventType,
+		delivery.Payload,
+		delivery.Attempts,
+		delivery.StatusCode,
+		delivery.Error,
+		delivery.Success,
+		delivery.CreatedAt,
+		delivery.DeliveredAt,
+		delivery.NextAttemptAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook delivery: %w", err)
+	}
+
+	delivery.ID = id
+	return nil
+}
+
+// UpdateDelivery updates a delivery after an attempt
+func (r *WebhookRepository) UpdateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	query := `UPDATE webhook_deliveries
+	          SET attempts = ?, status_code = ?, error = ?, success = ?, delivered_at = ?, next_attempt_at = ?
+	          WHERE id = ?`
+
+	_, err := r.db.ExecContext(ctx, query,
+		delivery.Attempts,
+		delivery.StatusCode,
+		delivery.Error,
+		delivery.Success,
+		delivery.DeliveredAt,
+		delivery.NextAttemptAt,
+		delivery.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook delivery: %w", err)
+	}
+
+	return nil
+}
+
+const deliveryColumns = `id, webhook_id, event_type, payload, attempts, status_code, error, success, created_at, delivered_at, next_attempt_at`
+
+// ListDeliveries retrieves the delivery log for a webhook, newest first
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, webhookID int64, limit, offset int) ([]*domain.WebhookDelivery, error) {
+	query := `SELECT ` + deliveryColumns + `
+	          FROM webhook_deliveries
+	          WHERE webhook_id = ?
+	          ORDER BY created_at DESC, id DESC
+	          LIMIT ? OFFSET ?`
+
+	rows, err := r.db.QueryContext(ctx, query, webhookID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	return scanDeliveries(rows)
+}
+
+// ListDueDeliveries retrieves deliveries whose next attempt is due, oldest first
+func (r *WebhookRepository) ListDueDeliveries(ctx context.Context, now time.Time, limit int) ([]*domain.WebhookDelivery, error) {
+	query := `SELECT ` + deliveryColumns + `
+	          FROM webhook_deliveries
+	          WHERE next_attempt_at IS NOT NULL AND next_attempt_at <= ?
+	          ORDER BY next_attempt_at, id
+	          LIMIT ?`
+
+	rows, err := r.db.QueryContext(ctx, query, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list due webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	return scanDeliveries(rows)
+}
+
+// scanDeliveries scans webhook delivery rows
+func scanDeliveries(rows *sql.Rows) ([]*domain.WebhookDelivery, error) {
+	deliveries := []*domain.WebhookDelivery{}
+	for rows.Next() {
+		d := &domain.WebhookDelivery{}
+		var statusCode sql.NullInt64
+		var deliveryErr sql.NullString
+		var deliveredAt, nextAttemptAt sql.NullTime
+		err := rows.Scan(
+			&d.ID,
+			&d.WebhookID,
+			&d.EventType,
+			&d.Payload,
+			&d.Attempts,
+			&statusCode,
+			&deliveryErr,
+			&d.Success,
+			&d.CreatedAt,
+			&deliveredAt,
+			&nextAttemptAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		if statusCode.Valid {
+			code := int(statusCode.Int64)
+			d.StatusCode = &code
+		}
+		if deliveryErr.Valid {
+			d.Error = &deliveryErr.String
+		}
+		if deliveredAt.Valid {
+			d.DeliveredAt = &deliveredAt.Time
+		}
+		if nextAttemptAt.Valid {
+			d.NextAttemptAt = &nextAttemptAt.Time
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, rows.Err()
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"strings"
@@ -11,23 +12,23 @@ import (
 
 // WODRepository implements domain.WODRepository
 type WODRepository struct {
-	db *sql.DB
+	db *DB
 }
 
 // NewWODRepository creates a new WOD repository
 func NewWODRepository(db *sql.DB) *WODRepository {
-	return &WODRepository{db: db}
+	return &WODRepository{db: NewDB(db)}
 }
 
 // Create creates a new custom WOD
-func (r *WODRepository) Create(wod *domain.WOD) error {
+func (r *WODRepository) Create(ctx context.Context, wod *domain.WOD) error {
 	wod.CreatedAt = time.Now()
 	wod.UpdatedAt = time.Now()
 
 	query := `INSERT INTO wods (name, source, type, regime, score_type, description, url, notes, is_standard, created_by, created_at, updated_at)
 	          VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
-	result, err := r.db.Exec(query,
+	id, err := r.db.InsertContext(ctx, query,
 		wod.Name,
 		wod.Source,
 		wod.Type,
@@ -45,17 +46,12 @@ func (r *WODRepository) Create(wod *domain.WOD) error {
 		return fmt.Errorf("failed to create wod: %w", err)
 	}
 
-	id, err := result.LastInsertId()
-	if err != nil {
-		return fmt.Errorf("failed to get wod ID: %w", err)
-	}
-
 	wod.ID = id
 	return nil
 }
 
 // GetByID retrieves a WOD by ID
-func (r *WODRepository) GetByID(id int64) (*domain.WOD, error) {
+func (r *WODRepository) GetByID(ctx context.Context, id int64) (*domain.WOD, error) {
 	query := `SELECT id, name, source, type, regime, score_type, description, url, notes, is_standard, created_by, created_at, updated_at
 	          FROM wods WHERE id = ?`
 
@@ -63,7 +59,7 @@ func (r *WODRepository) GetByID(id int64) (*domain.WOD, error) {
 	var url, notes sql.NullString
 	var createdBy sql.NullInt64
 
-	err := r.db.QueryRow(query, id).Scan(
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&wod.ID,
 		&wod.Name,
 		&wod.Source,
@@ -99,7 +95,7 @@ func (r *WODRepository) GetByID(id int64) (*domain.WOD, error) {
 }
 
 // GetByName retrieves a WOD by name
-func (r *WODRepository) GetByName(name string) (*domain.WOD, error) {
+func (r *WODRepository) GetByName(ctx context.Context, name string) (*domain.WOD, error) {
 	query := `SELECT id, name, source, type, regime, score_type, description, url, notes, is_standard, created_by, created_at, updated_at
 	          FROM wods WHERE name = ?`
 
@@ -107,7 +103,7 @@ func (r *WODRepository) GetByName(name string) (*domain.WOD, error) {
 	var url, notes sql.NullString
 	var createdBy sql.NullInt64
 
-	err := r.db.QueryRow(query, name).Scan(
+	err := r.db.QueryRowContext(ctx, query, name).Scan(
 		&wod.ID,
 		&wod.Name,
 		&wod.Source,
@@ -143,7 +139,7 @@ func (r *WODRepository) GetByName(name string) (*domain.WOD, error) {
 }
 
 // List retrieves WODs with optional filtering, limit, and offset
-func (r *WODRepository) List(filters map[string]interface{}, limit, offset int) ([]*domain.WOD, error) {
+func (r *WODRepository) List(ctx context.Context, filters map[string]interface{}, limit, offset int) ([]*domain.WOD, error) {
 	query := `SELECT id, name, source, type, regime, score_type, description, url, notes, is_standard, created_by, created_at, updated_at
 	          FROM wods WHERE 1=1`
 
@@ -180,16 +176,9 @@ func (r *WODRepository) List(filters map[string]interface{}, limit, offset int)
 	query += " ORDER BY is_standard DESC, name"
 
 	// Add pagination
-	if limit > 0 {
-		query += " LIMIT ?"
-		args = append(args, limit)
-	}
-	if offset > 0 {
-		query += " OFFSET ?"
-		args = append(args, offset)
-	}
+	query += r.db.Dialect.Limit(limit, offset)
 
-	rows, err := r.db.Query(query, args...)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list wods: %w", err)
 	}
@@ -199,22 +188,12 @@ func (r *WODRepository) List(filters map[string]interface{}, limit, offset int)
 }
 
 // ListStandard retrieves all standard (pre-seeded) WODs
-func (r *WODRepository) ListStandard(limit, offset int) ([]*domain.WOD, error) {
+func (r *WODRepository) ListStandard(ctx context.Context, limit, offset int) ([]*domain.WOD, error) {
 	query := `SELECT id, name, source, type, regime, score_type, description, url, notes, is_standard, created_by, created_at, updated_at
-	          FROM wods WHERE is_standard = 1 ORDER BY name`
-
-	var args []interface{}
+	          FROM wods WHERE is_standard = ? ORDER BY name`
+	query += r.db.Dialect.Limit(limit, offset)
 
-	if limit > 0 {
-		query += " LIMIT ?"
-		args = append(args, limit)
-	}
-	if offset > 0 {
-		query += " OFFSET ?"
-		args = append(args, offset)
-	}
-
-	rows, err := r.db.Query(query, args...)
+	rows, err := r.db.QueryContext(ctx, query, true)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list standard wods: %w", err)
 	}
@@ -224,23 +203,16 @@ func (r *WODRepository) ListStandard(limit, offset int) ([]*domain.WOD, error) {
 }
 
 // ListByUser retrieves all custom WODs created by a specific user
-func (r *WODRepository) ListByUser(userID int64, limit, offset int) ([]*domain.WOD, error) {
+func (r *WODRepository) ListByUser(ctx context.Context, userID int64, limit, offset int) ([]*domain.WOD, error) {
 	query := `SELECT id, name, source, type, regime, score_type, description, url, notes, is_standard, created_by, created_at, updated_at
 	          FROM wods WHERE created_by = ? ORDER BY name`
 
 	var args []interface{}
 	args = append(args, userID)
 
-	if limit > 0 {
-		query += " LIMIT ?"
-		args = append(args, limit)
-	}
-	if offset > 0 {
-		query += " OFFSET ?"
-		args = append(args, offset)
-	}
+	query += r.db.Dialect.Limit(limit, offset)
 
-	rows, err := r.db.Query(query, args...)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list user wods: %w", err)
 	}
@@ -250,14 +222,14 @@ func (r *WODRepository) ListByUser(userID int64, limit, offset int) ([]*domain.W
 }
 
 // Update updates an existing WOD (only for user-created WODs)
-func (r *WODRepository) Update(wod *domain.WOD) error {
+func (r *WODRepository) Update(ctx context.Context, wod *domain.WOD) error {
 	wod.UpdatedAt = time.Now()
 
 	query := `UPDATE wods
 	          SET name = ?, source = ?, type = ?, regime = ?, score_type = ?, description = ?, url = ?, notes = ?, updated_at = ?
-	          WHERE id = ? AND is_standard = 0`
+	          WHERE id = ? AND is_standard = ?`
 
-	result, err := r.db.Exec(query,
+	result, err := r.db.ExecContext(ctx, query,
 		wod.Name,
 		wod.Source,
 		wod.Type,
@@ -268,6 +240,7 @@ func (r *WODRepository) Update(wod *domain.WOD) error {
 		wod.Notes,
 		wod.UpdatedAt,
 		wod.ID,
+		false,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to update wod: %w", err)
@@ -286,10 +259,10 @@ func (r *WODRepository) Update(wod *domain.WOD) error {
 }
 
 // Delete deletes a WOD (only for user-created WODs)
-func (r *WODRepository) Delete(id int64) error {
-	query := `DELETE FROM wods WHERE id = ? AND is_standard = 0`
+func (r *WODRepository) Delete(ctx context.Context, id int64) error {
+	query := `DELETE FROM wods WHERE id = ? AND is_standard = ?`
 
-	result, err := r.db.Exec(query, id)
+	result, err := r.db.ExecContext(ctx, query, id, false)
 	if err != nil {
 		return fmt.Errorf("failed to delete wod: %w", err)
 	}
@@ -307,7 +280,7 @@ func (r *WODRepository) Delete(id int64) error {
 }
 
 // Search searches for WODs by name (partial match)
-func (r *WODRepository) Search(query string, limit int) ([]*domain.WOD, error) {
+func (r *WODRepository) Search(ctx context.Context, query string, limit int) ([]*domain.WOD, error) {
 	searchQuery := `SELECT id, name, source, type, regime, score_type, description, url, notes, is_standard, created_by, created_at, updated_at
 	                FROM wods
 	                WHERE name LIKE ?
@@ -321,7 +294,7 @@ func (r *WODRepository) Search(query string, limit int) ([]*domain.WOD, error) {
 		args = append(args, limit)
 	}
 
-	rows, err := r.db.Query(searchQuery, args...)
+	rows, err := r.db.QueryContext(ctx, searchQuery, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search wods: %w", err)
 	}
@@ -331,7 +304,7 @@ func (r *WODRepository) Search(query string, limit int) ([]*domain.WOD, error) {
 }
 
 // Count returns the total count of WODs, optionally filtered by user
-func (r *WODRepository) Count(userID *int64) (int64, error) {
+func (r *WODRepository) Count(ctx context.Context, userID *int64) (int64, error) {
 	var query string
 	var args []interface{}
 
@@ -343,7 +316,7 @@ func (r *WODRepository) Count(userID *int64) (int64, error) {
 	}
 
 	var count int64
-	err := r.db.QueryRow(query, args...).Scan(&count)
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("failed to count wods: %w", err)
 	}
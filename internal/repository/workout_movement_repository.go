@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"time"
@@ -10,38 +11,33 @@ import (
 
 // WorkoutMovementRepository implements domain.WorkoutMovementRepository
 type WorkoutMovementRepository struct {
-	db *sql.DB
+	db *DB
 }
 
 // NewWorkoutMovementRepository creates a new workout movement repository
 func NewWorkoutMovementRepository(db *sql.DB) *WorkoutMovementRepository {
-	return &WorkoutMovementRepository{db: db}
+	return &WorkoutMovementRepository{db: NewDB(db)}
 }
 
 // Create creates a new workout movement
-func (r *WorkoutMovementRepository) Create(wm *domain.WorkoutMovement) error {
+func (r *WorkoutMovementRepository) Create(ctx context.Context, wm *domain.WorkoutMovement) error {
 	wm.CreatedAt = time.Now()
 	wm.UpdatedAt = time.Now()
 
 	query := `INSERT INTO workout_movements (workout_id, movement_id, weight, sets, reps, time, distance, is_rx, is_pr, notes, order_index, created_at, updated_at)
 	          VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
-	result, err := r.db.Exec(query, wm.WorkoutID, wm.MovementID, wm.Weight, wm.Sets, wm.Reps, wm.Time, wm.Distance, wm.IsRx, wm.IsPR, wm.Notes, wm.OrderIndex, wm.CreatedAt, wm.UpdatedAt)
+	id, err := r.db.InsertContext(ctx, query, wm.WorkoutID, wm.MovementID, wm.Weight, wm.Sets, wm.Reps, wm.Time, wm.Distance, wm.IsRx, wm.IsPR, wm.Notes, wm.OrderIndex, wm.CreatedAt, wm.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to create workout movement: %w", err)
 	}
 
-	id, err := result.LastInsertId()
-	if err != nil {
-		return fmt.Errorf("failed to get workout movement ID: %w", err)
-	}
-
 	wm.ID = id
 	return nil
 }
 
 // GetByID retrieves a workout movement by ID
-func (r *WorkoutMovementRepository) GetByID(id int64) (*domain.WorkoutMovement, error) {
+func (r *WorkoutMovementRepository) GetByID(ctx context.Context, id int64) (*domain.WorkoutMovement, error) {
 	query := `SELECT id, workout_id, movement_id, weight, sets, reps, time, distance, is_rx, is_pr, notes, order_index, created_at, updated_at FROM workout_movements WHERE id = ?`
 
 	wm := &domain.WorkoutMovement{}
@@ -52,7 +48,7 @@ func (r *WorkoutMovementRepository) GetByID(id int64) (*domain.WorkoutMovement,
 	var distance sql.NullFloat64
 	var notes sql.NullString
 
-	err := r.db.QueryRow(query, id).Scan(&wm.ID, &wm.WorkoutID, &wm.MovementID, &weight, &sets, &reps, &time, &distance, &wm.IsRx, &wm.IsPR, &notes, &wm.OrderIndex, &wm.CreatedAt, &wm.UpdatedAt)
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&wm.ID, &wm.WorkoutID, &wm.MovementID, &weight, &sets, &reps, &time, &distance, &wm.IsRx, &wm.IsPR, &notes, &wm.OrderIndex, &wm.CreatedAt, &wm.UpdatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -86,7 +82,7 @@ func (r *WorkoutMovementRepository) GetByID(id int64) (*domain.WorkoutMovement,
 }
 
 // GetByWorkoutID retrieves all workout movements for a specific workout template
-func (r *WorkoutMovementRepository) GetByWorkoutID(workoutID int64) ([]*domain.WorkoutMovement, error) {
+func (r *WorkoutMovementRepository) GetByWorkoutID(ctx context.Context, workoutID int64) ([]*domain.WorkoutMovement, error) {
 	query := `
 		SELECT ws.id, ws.workout_id, ws.movement_id, ws.weight, ws.sets, ws.reps, ws.time, ws.distance,
 		       ws.is_rx, ws.is_pr, ws.notes, ws.order_index, ws.created_at, ws.updated_at,
@@ -96,7 +92,7 @@ func (r *WorkoutMovementRepository) GetByWorkoutID(workoutID int64) ([]*domain.W
 		WHERE ws.workout_id = ?
 		ORDER BY ws.order_index`
 
-	rows, err := r.db.Query(query, workoutID)
+	rows, err := r.db.QueryContext(ctx, query, workoutID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get workout movements: %w", err)
 	}
@@ -157,7 +153,7 @@ func (r *WorkoutMovementRepository) GetByWorkoutID(workoutID int64) ([]*domain.W
 
 // GetByUserIDAndMovementID retrieves workout movements for a user and specific movement
 // This now queries through user_workouts junction table since workouts are templates
-func (r *WorkoutMovementRepository) GetByUserIDAndMovementID(userID, movementID int64, limit int) ([]*domain.WorkoutMovement, error) {
+func (r *WorkoutMovementRepository) GetByUserIDAndMovementID(ctx context.Context, userID, movementID int64, limit int) ([]*domain.WorkoutMovement, error) {
 	query := `
 		SELECT ws.id, ws.workout_id, ws.movement_id, ws.weight, ws.sets, ws.reps, ws.time, ws.distance,
 		       ws.is_rx, ws.is_pr, ws.notes, ws.order_index, ws.created_at, ws.updated_at
@@ -167,7 +163,7 @@ func (r *WorkoutMovementRepository) GetByUserIDAndMovementID(userID, movementID
 		ORDER BY uw.workout_date DESC, ws.created_at DESC
 		LIMIT ?`
 
-	rows, err := r.db.Query(query, userID, movementID, limit)
+	rows, err := r.db.QueryContext(ctx, query, userID, movementID, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user workout movements: %w", err)
 	}
@@ -218,7 +214,7 @@ func (r *WorkoutMovementRepository) GetByUserIDAndMovementID(userID, movementID
 }
 
 // Update updates a workout movement
-func (r *WorkoutMovementRepository) Update(wm *domain.WorkoutMovement) error {
+func (r *WorkoutMovementRepository) Update(ctx context.Context, wm *domain.WorkoutMovement) error {
 	wm.UpdatedAt = time.Now()
 
 	query := `UPDATE workout_movements
@@ -227,7 +223,7 @@ func (r *WorkoutMovementRepository) Update(wm *domain.WorkoutMovement) error {
 	              notes = ?, order_index = ?, updated_at = ?
 	          WHERE id = ?`
 
-	result, err := r.db.Exec(query, wm.MovementID, wm.Weight, wm.Sets, wm.Reps, wm.Time, wm.Distance, wm.IsRx, wm.IsPR, wm.Notes, wm.OrderIndex, wm.UpdatedAt, wm.ID)
+	result, err := r.db.ExecContext(ctx, query, wm.MovementID, wm.Weight, wm.Sets, wm.Reps, wm.Time, wm.Distance, wm.IsRx, wm.IsPR, wm.Notes, wm.OrderIndex, wm.UpdatedAt, wm.ID)
 	if err != nil {
 		return fmt.Errorf("failed to update workout movement: %w", err)
 	}
@@ -245,10 +241,10 @@ func (r *WorkoutMovementRepository) Update(wm *domain.WorkoutMovement) error {
 }
 
 // Delete deletes a workout movement
-func (r *WorkoutMovementRepository) Delete(id int64) error {
+func (r *WorkoutMovementRepository) Delete(ctx context.Context, id int64) error {
 	query := `DELETE FROM workout_movements WHERE id = ?`
 
-	result, err := r.db.Exec(query, id)
+	result, err := r.db.ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete workout movement: %w", err)
 	}
@@ -266,10 +262,10 @@ func (r *WorkoutMovementRepository) Delete(id int64) error {
 }
 
 // DeleteByWorkoutID deletes all movements for a workout template
-func (r *WorkoutMovementRepository) DeleteByWorkoutID(workoutID int64) error {
+func (r *WorkoutMovementRepository) DeleteByWorkoutID(ctx context.Context, workoutID int64) error {
 	query := `DELETE FROM workout_movements WHERE workout_id = ?`
 
-	if _, err := r.db.Exec(query, workoutID); err != nil {
+	if _, err := r.db.ExecContext(ctx, query, workoutID); err != nil {
 		return fmt.Errorf("failed to delete workout movements: %w", err)
 	}
 
@@ -278,7 +274,7 @@ func (r *WorkoutMovementRepository) DeleteByWorkoutID(workoutID int64) error {
 
 // GetPersonalRecords retrieves all personal records for a user
 // Updated to work with new schema where user_workouts is the junction table
-func (r *WorkoutMovementRepository) GetPersonalRecords(userID int64) ([]*domain.PersonalRecord, error) {
+func (r *WorkoutMovementRepository) GetPersonalRecords(ctx context.Context, userID int64) ([]*domain.PersonalRecord, error) {
 	query := `
 		SELECT
 			m.id as movement_id,
@@ -296,7 +292,7 @@ func (r *WorkoutMovementRepository) GetPersonalRecords(userID int64) ([]*domain.
 		GROUP BY m.id, m.name
 		ORDER BY m.name`
 
-	rows, err := r.db.Query(query, userID)
+	rows, err := r.db.QueryContext(ctx, query, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get personal records: %w", err)
 	}
@@ -343,7 +339,7 @@ func (r *WorkoutMovementRepository) GetPersonalRecords(userID int64) ([]*domain.
 
 // GetMaxWeightForMovement retrieves the maximum weight for a specific movement for a user
 // Updated to work with new schema where user_workouts is the junction table
-func (r *WorkoutMovementRepository) GetMaxWeightForMovement(userID, movementID int64) (*float64, error) {
+func (r *WorkoutMovementRepository) GetMaxWeightForMovement(ctx context.Context, userID, movementID int64) (*float64, error) {
 	query := `
 		SELECT MAX(ws.weight)
 		FROM workout_movements ws
@@ -351,7 +347,7 @@ func (r *WorkoutMovementRepository) GetMaxWeightForMovement(userID, movementID i
 		WHERE uw.user_id = ? AND ws.movement_id = ? AND ws.weight IS NOT NULL`
 
 	var maxWeight sql.NullFloat64
-	err := r.db.QueryRow(query, userID, movementID).Scan(&maxWeight)
+	err := r.db.QueryRowContext(ctx, query, userID, movementID).Scan(&maxWeight)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -368,7 +364,7 @@ func (r *WorkoutMovementRepository) GetMaxWeightForMovement(userID, movementID i
 
 // GetPRMovements retrieves recent PR-flagged movements for a user
 // Updated to work with new schema where user_workouts is the junction table
-func (r *WorkoutMovementRepository) GetPRMovements(userID int64, limit int) ([]*domain.WorkoutMovement, error) {
+func (r *WorkoutMovementRepository) GetPRMovements(ctx context.Context, userID int64, limit int) ([]*domain.WorkoutMovement, error) {
 	query := `
 		SELECT ws.id, ws.workout_id, ws.movement_id, ws.weight, ws.sets, ws.reps, ws.time, ws.distance,
 		       ws.is_rx, ws.is_pr, ws.notes, ws.order_index, ws.created_at, ws.updated_at,
@@ -376,11 +372,11 @@ func (r *WorkoutMovementRepository) GetPRMovements(userID int64, limit int) ([]*
 		FROM workout_movements ws
 		INNER JOIN user_workouts uw ON ws.workout_id = uw.workout_id
 		INNER JOIN movements m ON ws.movement_id = m.id
-		WHERE uw.user_id = ? AND ws.is_pr = 1
+		WHERE uw.user_id = ? AND ws.is_pr = ?
 		ORDER BY uw.workout_date DESC, ws.created_at DESC
 		LIMIT ?`
 
-	rows, err := r.db.Query(query, userID, limit)
+	rows, err := r.db.QueryContext(ctx, query, userID, true, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get PR movements: %w", err)
 	}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"time"
@@ -10,45 +11,40 @@ import (
 
 // WorkoutRepository implements domain.WorkoutRepository for workout templates
 type WorkoutRepository struct {
-	db *sql.DB
+	db *DB
 }
 
 // NewWorkoutRepository creates a new workout repository
 func NewWorkoutRepository(db *sql.DB) *WorkoutRepository {
-	return &WorkoutRepository{db: db}
+	return &WorkoutRepository{db: NewDB(db)}
 }
 
 // Create creates a new workout template
-func (r *WorkoutRepository) Create(workout *domain.Workout) error {
+func (r *WorkoutRepository) Create(ctx context.Context, workout *domain.Workout) error {
 	workout.CreatedAt = time.Now()
 	workout.UpdatedAt = time.Now()
 
 	query := `INSERT INTO workouts (name, notes, created_by, created_at, updated_at)
 	          VALUES (?, ?, ?, ?, ?)`
 
-	result, err := r.db.Exec(query, workout.Name, workout.Notes, workout.CreatedBy, workout.CreatedAt, workout.UpdatedAt)
+	id, err := r.db.InsertContext(ctx, query, workout.Name, workout.Notes, workout.CreatedBy, workout.CreatedAt, workout.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to create workout: %w", err)
 	}
 
-	id, err := result.LastInsertId()
-	if err != nil {
-		return fmt.Errorf("failed to get workout ID: %w", err)
-	}
-
 	workout.ID = id
 	return nil
 }
 
 // GetByID retrieves a workout template by ID
-func (r *WorkoutRepository) GetByID(id int64) (*domain.Workout, error) {
+func (r *WorkoutRepository) GetByID(ctx context.Context, id int64) (*domain.Workout, error) {
 	query := `SELECT id, name, notes, created_by, created_at, updated_at FROM workouts WHERE id = ?`
 
 	workout := &domain.Workout{}
 	var createdBy sql.NullInt64
 	var notes sql.NullString
 
-	err := r.db.QueryRow(query, id).Scan(&workout.ID, &workout.Name, &notes, &createdBy, &workout.CreatedAt, &workout.UpdatedAt)
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&workout.ID, &workout.Name, &notes, &createdBy, &workout.CreatedAt, &workout.UpdatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -67,9 +63,9 @@ func (r *WorkoutRepository) GetByID(id int64) (*domain.Workout, error) {
 }
 
 // GetByIDWithDetails retrieves a workout with movements and WODs
-func (r *WorkoutRepository) GetByIDWithDetails(id int64) (*domain.Workout, error) {
+func (r *WorkoutRepository) GetByIDWithDetails(ctx context.Context, id int64) (*domain.Workout, error) {
 	// Get the workout template
-	workout, err := r.GetByID(id)
+	workout, err := r.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -87,7 +83,7 @@ func (r *WorkoutRepository) GetByIDWithDetails(id int64) (*domain.Workout, error
 		WHERE ws.workout_id = ?
 		ORDER BY ws.order_index`
 
-	rows, err := r.db.Query(movementsQuery, id)
+	rows, err := r.db.QueryContext(ctx, movementsQuery, id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get workout movements: %w", err)
 	}
@@ -159,7 +155,7 @@ func (r *WorkoutRepository) GetByIDWithDetails(id int64) (*domain.Workout, error
 		WHERE ww.workout_id = ?
 		ORDER BY ww.order_index`
 
-	rows, err = r.db.Query(wodsQuery, id)
+	rows, err = r.db.QueryContext(ctx, wodsQuery, id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get workout WODs: %w", err)
 	}
@@ -188,7 +184,7 @@ func (r *WorkoutRepository) GetByIDWithDetails(id int64) (*domain.Workout, error
 }
 
 // List retrieves all workout templates with optional filtering
-func (r *WorkoutRepository) List(filters map[string]interface{}, limit, offset int) ([]*domain.Workout, error) {
+func (r *WorkoutRepository) List(ctx context.Context, filters map[string]interface{}, limit, offset int) ([]*domain.Workout, error) {
 	query := `SELECT id, name, notes, created_by, created_at, updated_at FROM workouts WHERE 1=1`
 	args := []interface{}{}
 
@@ -206,7 +202,7 @@ func (r *WorkoutRepository) List(filters map[string]interface{}, limit, offset i
 	query += ` ORDER BY name LIMIT ? OFFSET ?`
 	args = append(args, limit, offset)
 
-	rows, err := r.db.Query(query, args...)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list workouts: %w", err)
 	}
@@ -216,14 +212,14 @@ func (r *WorkoutRepository) List(filters map[string]interface{}, limit, offset i
 }
 
 // ListByUser retrieves all workout templates created by a specific user
-func (r *WorkoutRepository) ListByUser(userID int64, limit, offset int) ([]*domain.Workout, error) {
+func (r *WorkoutRepository) ListByUser(ctx context.Context, userID int64, limit, offset int) ([]*domain.Workout, error) {
 	query := `SELECT id, name, notes, created_by, created_at, updated_at
 	          FROM workouts
 	          WHERE created_by = ?
 	          ORDER BY name
 	          LIMIT ? OFFSET ?`
 
-	rows, err := r.db.Query(query, userID, limit, offset)
+	rows, err := r.db.QueryContext(ctx, query, userID, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list user workouts: %w", err)
 	}
@@ -233,14 +229,14 @@ func (r *WorkoutRepository) ListByUser(userID int64, limit, offset int) ([]*doma
 }
 
 // ListStandard retrieves all standard (system) workout templates
-func (r *WorkoutRepository) ListStandard(limit, offset int) ([]*domain.Workout, error) {
+func (r *WorkoutRepository) ListStandard(ctx context.Context, limit, offset int) ([]*domain.Workout, error) {
 	query := `SELECT id, name, notes, created_by, created_at, updated_at
 	          FROM workouts
 	          WHERE created_by IS NULL
 	          ORDER BY name
 	          LIMIT ? OFFSET ?`
 
-	rows, err := r.db.Query(query, limit, offset)
+	rows, err := r.db.QueryContext(ctx, query, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list standard workouts: %w", err)
 	}
@@ -250,14 +246,14 @@ func (r *WorkoutRepository) ListStandard(limit, offset int) ([]*domain.Workout,
 }
 
 // Update updates an existing workout template
-func (r *WorkoutRepository) Update(workout *domain.Workout) error {
+func (r *WorkoutRepository) Update(ctx context.Context, workout *domain.Workout) error {
 	workout.UpdatedAt = time.Now()
 
 	query := `UPDATE workouts
 	          SET name = ?, notes = ?, updated_at = ?
 	          WHERE id = ?`
 
-	result, err := r.db.Exec(query, workout.Name, workout.Notes, workout.UpdatedAt, workout.ID)
+	result, err := r.db.ExecContext(ctx, query, workout.Name, workout.Notes, workout.UpdatedAt, workout.ID)
 	if err != nil {
 		return fmt.Errorf("failed to update workout: %w", err)
 	}
@@ -275,10 +271,10 @@ func (r *WorkoutRepository) Update(workout *domain.Workout) error {
 }
 
 // Delete deletes a workout template
-func (r *WorkoutRepository) Delete(id int64) error {
+func (r *WorkoutRepository) Delete(ctx context.Context, id int64) error {
 	query := `DELETE FROM workouts WHERE id = ?`
 
-	result, err := r.db.Exec(query, id)
+	result, err := r.db.ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete workout: %w", err)
 	}
@@ -296,14 +292,14 @@ func (r *WorkoutRepository) Delete(id int64) error {
 }
 
 // Search searches workout templates by name
-func (r *WorkoutRepository) Search(query string, limit int) ([]*domain.Workout, error) {
+func (r *WorkoutRepository) Search(ctx context.Context, query string, limit int) ([]*domain.Workout, error) {
 	searchQuery := `SELECT id, name, notes, created_by, created_at, updated_at
 	                FROM workouts
 	                WHERE name LIKE ?
 	                ORDER BY name
 	                LIMIT ?`
 
-	rows, err := r.db.Query(searchQuery, "%"+query+"%", limit)
+	rows, err := r.db.QueryContext(ctx, searchQuery, "%"+query+"%", limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search workouts: %w", err)
 	}
@@ -313,19 +309,19 @@ func (r *WorkoutRepository) Search(query string, limit int) ([]*domain.Workout,
 }
 
 // Count counts total workout templates (optionally filtered by user)
-func (r *WorkoutRepository) Count(userID *int64) (int64, error) {
+func (r *WorkoutRepository) Count(ctx context.Context, userID *int64) (int64, error) {
 	var count int64
 	var query string
 
 	if userID != nil {
 		query = `SELECT COUNT(*) FROM workouts WHERE created_by = ?`
-		err := r.db.QueryRow(query, *userID).Scan(&count)
+		err := r.db.QueryRowContext(ctx, query, *userID).Scan(&count)
 		if err != nil {
 			return 0, fmt.Errorf("failed to count workouts: %w", err)
 		}
 	} else {
 		query = `SELECT COUNT(*) FROM workouts`
-		err := r.db.QueryRow(query).Scan(&count)
+		err := r.db.QueryRowContext(ctx, query).Scan(&count)
 		if err != nil {
 			return 0, fmt.Errorf("failed to count workouts: %w", err)
 		}
@@ -335,9 +331,9 @@ func (r *WorkoutRepository) Count(userID *int64) (int64, error) {
 }
 
 // GetUsageStats gets usage statistics for a template
-func (r *WorkoutRepository) GetUsageStats(workoutID int64) (*domain.WorkoutWithUsageStats, error) {
+func (r *WorkoutRepository) GetUsageStats(ctx context.Context, workoutID int64) (*domain.WorkoutWithUsageStats, error) {
 	// Get the workout template
-	workout, err := r.GetByID(workoutID)
+	workout, err := r.GetByID(ctx, workoutID)
 	if err != nil {
 		return nil, err
 	}
@@ -348,7 +344,7 @@ func (r *WorkoutRepository) GetUsageStats(workoutID int64) (*domain.WorkoutWithU
 	// Count how many times this template has been used
 	var timesUsed int64
 	countQuery := `SELECT COUNT(*) FROM user_workouts WHERE workout_id = ?`
-	if err := r.db.QueryRow(countQuery, workoutID).Scan(&timesUsed); err != nil {
+	if err := r.db.QueryRowContext(ctx, countQuery, workoutID).Scan(&timesUsed); err != nil {
 		return nil, fmt.Errorf("failed to count usage: %w", err)
 	}
 
@@ -356,7 +352,7 @@ func (r *WorkoutRepository) GetUsageStats(workoutID int64) (*domain.WorkoutWithU
 	var lastUsedAt *time.Time
 	lastUsedQuery := `SELECT MAX(workout_date) FROM user_workouts WHERE workout_id = ?`
 	var nullableLastUsed sql.NullTime
-	if err := r.db.QueryRow(lastUsedQuery, workoutID).Scan(&nullableLastUsed); err != nil && err != sql.ErrNoRows {
+	if err := r.db.QueryRowContext(ctx, lastUsedQuery, workoutID).Scan(&nullableLastUsed); err != nil && err != sql.ErrNoRows {
 		return nil, fmt.Errorf("failed to get last usage: %w", err)
 	}
 	if nullableLastUsed.Valid {
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"time"
@@ -9,44 +10,39 @@ import (
 )
 
 type WorkoutWODRepository struct {
-	db *sql.DB
+	db *DB
 }
 
 func NewWorkoutWODRepository(db *sql.DB) *WorkoutWODRepository {
-	return &WorkoutWODRepository{db: db}
+	return &WorkoutWODRepository{db: NewDB(db)}
 }
 
 // Create creates a new workout-WOD association
-func (r *WorkoutWODRepository) Create(workoutWOD *domain.WorkoutWOD) error {
+func (r *WorkoutWODRepository) Create(ctx context.Context, workoutWOD *domain.WorkoutWOD) error {
 	workoutWOD.CreatedAt = time.Now()
 	workoutWOD.UpdatedAt = time.Now()
 
 	query := `INSERT INTO workout_wods (workout_id, wod_id, score_value, division, is_pr, order_index, created_at, updated_at)
 	          VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
 
-	result, err := r.db.Exec(query, workoutWOD.WorkoutID, workoutWOD.WODID, workoutWOD.ScoreValue, workoutWOD.Division, workoutWOD.IsPR, workoutWOD.OrderIndex, workoutWOD.CreatedAt, workoutWOD.UpdatedAt)
+	id, err := r.db.InsertContext(ctx, query, workoutWOD.WorkoutID, workoutWOD.WODID, workoutWOD.ScoreValue, workoutWOD.Division, workoutWOD.IsPR, workoutWOD.OrderIndex, workoutWOD.CreatedAt, workoutWOD.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to create workout-WOD: %w", err)
 	}
 
-	id, err := result.LastInsertId()
-	if err != nil {
-		return fmt.Errorf("failed to get workout-WOD ID: %w", err)
-	}
-
 	workoutWOD.ID = id
 	return nil
 }
 
 // GetByID retrieves a workout-WOD by ID
-func (r *WorkoutWODRepository) GetByID(id int64) (*domain.WorkoutWOD, error) {
+func (r *WorkoutWODRepository) GetByID(ctx context.Context, id int64) (*domain.WorkoutWOD, error) {
 	query := `SELECT id, workout_id, wod_id, score_value, division, is_pr, order_index, created_at, updated_at FROM workout_wods WHERE id = ?`
 
 	workoutWOD := &domain.WorkoutWOD{}
 	var scoreValue sql.NullString
 	var division sql.NullString
 
-	err := r.db.QueryRow(query, id).Scan(&workoutWOD.ID, &workoutWOD.WorkoutID, &workoutWOD.WODID, &scoreValue, &division, &workoutWOD.IsPR, &workoutWOD.OrderIndex, &workoutWOD.CreatedAt, &workoutWOD.UpdatedAt)
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&workoutWOD.ID, &workoutWOD.WorkoutID, &workoutWOD.WODID, &scoreValue, &division, &workoutWOD.IsPR, &workoutWOD.OrderIndex, &workoutWOD.CreatedAt, &workoutWOD.UpdatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -65,10 +61,10 @@ func (r *WorkoutWODRepository) GetByID(id int64) (*domain.WorkoutWOD, error) {
 }
 
 // ListByWorkout retrieves all WODs associated with a workout template
-func (r *WorkoutWODRepository) ListByWorkout(workoutID int64) ([]*domain.WorkoutWOD, error) {
+func (r *WorkoutWODRepository) ListByWorkout(ctx context.Context, workoutID int64) ([]*domain.WorkoutWOD, error) {
 	query := `SELECT id, workout_id, wod_id, score_value, division, is_pr, order_index, created_at, updated_at FROM workout_wods WHERE workout_id = ? ORDER BY order_index`
 
-	rows, err := r.db.Query(query, workoutID)
+	rows, err := r.db.QueryContext(ctx, query, workoutID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list workout WODs: %w", err)
 	}
@@ -78,7 +74,7 @@ func (r *WorkoutWODRepository) ListByWorkout(workoutID int64) ([]*domain.Workout
 }
 
 // ListByWorkoutWithDetails retrieves WODs with full WOD details
-func (r *WorkoutWODRepository) ListByWorkoutWithDetails(workoutID int64) ([]*domain.WorkoutWODWithDetails, error) {
+func (r *WorkoutWODRepository) ListByWorkoutWithDetails(ctx context.Context, workoutID int64) ([]*domain.WorkoutWODWithDetails, error) {
 	query := `
 		SELECT
 			ww.id, ww.workout_id, ww.wod_id, ww.score_value, ww.division, ww.is_pr,
@@ -90,7 +86,7 @@ func (r *WorkoutWODRepository) ListByWorkoutWithDetails(workoutID int64) ([]*dom
 		WHERE ww.workout_id = ?
 		ORDER BY ww.order_index`
 
-	rows, err := r.db.Query(query, workoutID)
+	rows, err := r.db.QueryContext(ctx, query, workoutID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list workout WODs with details: %w", err)
 	}
@@ -100,7 +96,7 @@ func (r *WorkoutWODRepository) ListByWorkoutWithDetails(workoutID int64) ([]*dom
 }
 
 // Update updates an existing workout-WOD association
-func (r *WorkoutWODRepository) Update(workoutWOD *domain.WorkoutWOD) error {
+func (r *WorkoutWODRepository) Update(ctx context.Context, workoutWOD *domain.WorkoutWOD) error {
 	workoutWOD.UpdatedAt = time.Now()
 
 	query := `UPDATE workout_wods
@@ -108,7 +104,7 @@ func (r *WorkoutWODRepository) Update(workoutWOD *domain.WorkoutWOD) error {
 	              order_index = ?, updated_at = ?
 	          WHERE id = ?`
 
-	result, err := r.db.Exec(query, workoutWOD.ScoreValue, workoutWOD.Division, workoutWOD.IsPR, workoutWOD.OrderIndex, workoutWOD.UpdatedAt, workoutWOD.ID)
+	result, err := r.db.ExecContext(ctx, query, workoutWOD.ScoreValue, workoutWOD.Division, workoutWOD.IsPR, workoutWOD.OrderIndex, workoutWOD.UpdatedAt, workoutWOD.ID)
 	if err != nil {
 		return fmt.Errorf("failed to update workout-WOD: %w", err)
 	}
@@ -126,10 +122,10 @@ func (r *WorkoutWODRepository) Update(workoutWOD *domain.WorkoutWOD) error {
 }
 
 // Delete deletes a workout-WOD association
-func (r *WorkoutWODRepository) Delete(id int64) error {
+func (r *WorkoutWODRepository) Delete(ctx context.Context, id int64) error {
 	query := `DELETE FROM workout_wods WHERE id = ?`
 
-	result, err := r.db.Exec(query, id)
+	result, err := r.db.ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete workout-WOD: %w", err)
 	}
@@ -147,18 +143,18 @@ func (r *WorkoutWODRepository) Delete(id int64) error {
 }
 
 // GetByWorkoutID retrieves all WODs for a specific workout (alias for ListByWorkout)
-func (r *WorkoutWODRepository) GetByWorkoutID(workoutID int64) ([]*domain.WorkoutWOD, error) {
-	return r.ListByWorkout(workoutID)
+func (r *WorkoutWODRepository) GetByWorkoutID(ctx context.Context, workoutID int64) ([]*domain.WorkoutWOD, error) {
+	return r.ListByWorkout(ctx, workoutID)
 }
 
 // GetByWODID finds which workouts use this WOD
-func (r *WorkoutWODRepository) GetByWODID(wodID int64) ([]*domain.WorkoutWOD, error) {
+func (r *WorkoutWODRepository) GetByWODID(ctx context.Context, wodID int64) ([]*domain.WorkoutWOD, error) {
 	query := `SELECT id, workout_id, wod_id, score_value, division, is_pr, order_index, created_at, updated_at
 	          FROM workout_wods
 	          WHERE wod_id = ?
 	          ORDER BY created_at DESC`
 
-	rows, err := r.db.Query(query, wodID)
+	rows, err := r.db.QueryContext(ctx, query, wodID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get workouts by WOD ID: %w", err)
 	}
@@ -168,10 +164,10 @@ func (r *WorkoutWODRepository) GetByWODID(wodID int64) ([]*domain.WorkoutWOD, er
 }
 
 // DeleteByWorkout deletes all WOD associations for a workout
-func (r *WorkoutWODRepository) DeleteByWorkout(workoutID int64) error {
+func (r *WorkoutWODRepository) DeleteByWorkout(ctx context.Context, workoutID int64) error {
 	query := `DELETE FROM workout_wods WHERE workout_id = ?`
 
-	if _, err := r.db.Exec(query, workoutID); err != nil {
+	if _, err := r.db.ExecContext(ctx, query, workoutID); err != nil {
 		return fmt.Errorf("failed to delete workout WODs: %w", err)
 	}
 
@@ -179,26 +175,26 @@ func (r *WorkoutWODRepository) DeleteByWorkout(workoutID int64) error {
 }
 
 // DeleteByWorkoutID deletes all WODs for a workout template (alias for DeleteByWorkout)
-func (r *WorkoutWODRepository) DeleteByWorkoutID(workoutID int64) error {
-	return r.DeleteByWorkout(workoutID)
+func (r *WorkoutWODRepository) DeleteByWorkoutID(ctx context.Context, workoutID int64) error {
+	return r.DeleteByWorkout(ctx, workoutID)
 }
 
 // BatchCreate adds multiple WODs to a workout at once
-func (r *WorkoutWODRepository) BatchCreate(workoutID int64, wodIDs []int64) error {
+func (r *WorkoutWODRepository) BatchCreate(ctx context.Context, workoutID int64, wodIDs []int64) error {
 	if len(wodIDs) == 0 {
 		return nil
 	}
 
-	tx, err := r.db.Begin()
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer tx.Rollback()
 
 	query := `INSERT INTO workout_wods (workout_id, wod_id, score_value, division, is_pr, order_index, created_at, updated_at)
-	          VALUES (?, ?, NULL, NULL, 0, ?, ?, ?)`
+	          VALUES (?, ?, NULL, NULL, ?, ?, ?, ?)`
 
-	stmt, err := tx.Prepare(query)
+	stmt, err := tx.PrepareContext(ctx, query)
 	if err != nil {
 		return fmt.Errorf("failed to prepare statement: %w", err)
 	}
@@ -206,7 +202,7 @@ func (r *WorkoutWODRepository) BatchCreate(workoutID int64, wodIDs []int64) erro
 
 	now := time.Now()
 	for i, wodID := range wodIDs {
-		_, err := stmt.Exec(workoutID, wodID, i, now, now)
+		_, err := stmt.ExecContext(ctx, workoutID, wodID, false, i, now, now)
 		if err != nil {
 			return fmt.Errorf("failed to create workout WOD for wod_id %d: %w", wodID, err)
 		}
@@ -220,12 +216,12 @@ func (r *WorkoutWODRepository) BatchCreate(workoutID int64, wodIDs []int64) erro
 }
 
 // Reorder updates order_index for WODs in a workout
-func (r *WorkoutWODRepository) Reorder(workoutID int64, wodIDs []int64) error {
+func (r *WorkoutWODRepository) Reorder(ctx context.Context, workoutID int64, wodIDs []int64) error {
 	if len(wodIDs) == 0 {
 		return nil
 	}
 
-	tx, err := r.db.Begin()
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
@@ -233,7 +229,7 @@ func (r *WorkoutWODRepository) Reorder(workoutID int64, wodIDs []int64) error {
 
 	query := `UPDATE workout_wods SET order_index = ?, updated_at = ? WHERE workout_id = ? AND wod_id = ?`
 
-	stmt, err := tx.Prepare(query)
+	stmt, err := tx.PrepareContext(ctx, query)
 	if err != nil {
 		return fmt.Errorf("failed to prepare statement: %w", err)
 	}
@@ -241,7 +237,7 @@ func (r *WorkoutWODRepository) Reorder(workoutID int64, wodIDs []int64) error {
 
 	now := time.Now()
 	for i, wodID := range wodIDs {
-		result, err := stmt.Exec(i, now, workoutID, wodID)
+		result, err := stmt.ExecContext(ctx, i, now, workoutID, wodID)
 		if err != nil {
 			return fmt.Errorf("failed to update order for wod_id %d: %w", wodID, err)
 		}
@@ -264,10 +260,10 @@ func (r *WorkoutWODRepository) Reorder(workoutID int64, wodIDs []int64) error {
 }
 
 // TogglePR toggles the PR flag for a workout-WOD
-func (r *WorkoutWODRepository) TogglePR(id int64) error {
+func (r *WorkoutWODRepository) TogglePR(ctx context.Context, id int64) error {
 	query := `UPDATE workout_wods SET is_pr = NOT is_pr, updated_at = ? WHERE id = ?`
 
-	result, err := r.db.Exec(query, time.Now(), id)
+	result, err := r.db.ExecContext(ctx, query, time.Now(), id)
 	if err != nil {
 		return fmt.Errorf("failed to toggle PR: %w", err)
 	}
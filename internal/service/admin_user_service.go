@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/johnzastrow/actalog/internal/domain"
+)
+
+var (
+	ErrInvalidRole      = errors.New("role must be user or admin")
+	ErrCannotModifySelf = errors.New("admins can't change their own role, disable or delete their own account")
+)
+
+// validRoles are the roles an admin can assign
+var validRoles = map[string]bool{"user": true, "admin": true}
+
+const (
+	defaultUserListLimit = 50
+	maxUserListLimit     = 200
+)
+
+// AdminUserService lets admins manage other users' accounts
+type AdminUserService struct {
+	userRepo    domain.UserRepository
+	userService *UserService
+}
+
+// NewAdminUserService creates a new admin user service
+func NewAdminUserService(userRepo domain.UserRepository, userService *UserService) *AdminUserService {
+	return &AdminUserService{
+		userRepo:    userRepo,
+		userService: userService,
+	}
+}
+
+// List returns a page of users matching the filter and the total number of
+// matches. The limit defaults to 50 and is capped at 200.
+func (s *AdminUserService) List(ctx context.Context, filter domain.UserFilter) ([]*domain.User, int64, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultUserListLimit
+	}
+	if filter.Limit > maxUserListLimit {
+		filter.Limit = maxUserListLimit
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	users, total, err := s.userRepo.Search(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list users: %w", err)
+	}
+	for _, user := range users {
+		user.PasswordHash = ""
+	}
+	if users == nil {
+		users = []*domain.User{}
+	}
+	return users, total, nil
+}
+
+// Get returns a user
+func (s *AdminUserService) Get(ctx context.Context, userID int64) (*domain.User, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	user.PasswordHash = ""
+	return user, nil
+}
+
+// SetRole changes a user's role. It applies from the user's next request,
+// as the auth middleware reads the role from the account rather than the
+// access token, and their remembered sessions are signed out.
+func (s *AdminUserService) SetRole(ctx context.Context, adminID, userID int64, role string) (*domain.User, error) {
+	if !validRoles[role] {
+		return nil, ErrInvalidRole
+	}
+	if adminID == userID {
+		return nil, ErrCannotModifySelf
+	}
+
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Role != role {
+		user.Role = role
+		if err := s.userRepo.Update(ctx, user); err != nil {
+			return nil, fmt.Errorf("failed to update user: %w", err)
+		}
+		if err := s.userService.RevokeAllRefreshTokens(ctx, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	user.PasswordHash = ""
+	return user, nil
+}
+
+// SetDisabled disables or re-enables a user's account. Disabling signs the
+// user out everywhere; their access tokens stop working straight away.
+func (s *AdminUserService) SetDisabled(ctx context.Context, adminID, userID int64, disabled bool) (*domain.User, error) {
+	if adminID == userID {
+		return nil, ErrCannotModifySelf
+	}
+
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Disabled != disabled {
+		user.Disabled = disabled
+		if err := s.userRepo.Update(ctx, user); err != nil {
+			return nil, fmt.Errorf("failed to update user: %w", err)
+		}
+	}
+	if disabled {
+		if err := s.userService.RevokeAllRefreshTokens(ctx, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	user.PasswordHash = ""
+	return user, nil
+}
+
+// ForcePasswordReset clears a user's password, signs them out everywhere and
+// emails them a password reset link. Until they choose a new password they
+// can only log in with a passkey or single sign-on.
+func (s *AdminUserService) ForcePasswordReset(ctx context.Context, userID int64) error {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	user.PasswordHash = ""
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return fmt.Errorf("failed to clear password: %w", err)
+	}
+	if err := s.userService.RevokeAllRefreshTokens(ctx, userID); err != nil {
+		return err
+	}
+
+	return s.userService.RequestPasswordReset(ctx, user.Email)
+}
+
+// ResendVerification emails a user a new verification link. It returns
+// ErrEmailAlreadyVerified if their address is already verified.
+func (s *AdminUserService) ResendVerification(ctx context.Context, userID int64) error {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerified {
+		return ErrEmailAlreadyVerified
+	}
+
+	return s.userService.ResendVerificationEmail(ctx, user.Email)
+}
+
+// Delete deletes a user and everything they own
+func (s *AdminUserService) Delete(ctx context.Context, adminID, userID int64) error {
+	if adminID == userID {
+		return ErrCannotModifySelf
+	}
+
+	if _, err := s.getUser(ctx, userID); err != nil {
+		return err
+	}
+	if err := s.userRepo.Delete(ctx, userID); err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+	return nil
+}
+
+func (s *AdminUserService) getUser(ctx context.Context, userID int64) (*domain.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
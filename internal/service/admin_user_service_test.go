@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/johnzastrow/actalog/internal/domain"
+)
+
+func TestAdminUserService_ManageAccounts(t *testing.T) {
+	userService := newTestUserService(true)
+	adminService := NewAdminUserService(userService.userRepo, userService)
+
+	admin, _, err := userService.Register(context.Background(), "Admin", "admin@example.com", "password123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, _, err := userService.Register(context.Background(), "Ana", "ana@example.com", "password123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	refresh, err := userService.CreateRefreshToken(context.Background(), user.ID, "phone", "127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Admins can't lock themselves out
+	if _, err := adminService.SetDisabled(context.Background(), admin.ID, admin.ID, true); !errors.Is(err, ErrCannotModifySelf) {
+		t.Errorf("expected ErrCannotModifySelf, got %v", err)
+	}
+	if _, err := adminService.SetRole(context.Background(), admin.ID, admin.ID, "user"); !errors.Is(err, ErrCannotModifySelf) {
+		t.Errorf("expected ErrCannotModifySelf, got %v", err)
+	}
+	if _, err := adminService.SetRole(context.Background(), admin.ID, user.ID, "owner"); !errors.Is(err, ErrInvalidRole) {
+		t.Errorf("expected ErrInvalidRole, got %v", err)
+	}
+
+	// Disabling blocks login, refresh and the account check used by middleware
+	if _, err := adminService.SetDisabled(context.Background(), admin.ID, user.ID, true); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := userService.Login(context.Background(), "ana@example.com", "password123"); !errors.Is(err, ErrAccountDisabled) {
+		t.Errorf("expected ErrAccountDisabled on login, got %v", err)
+	}
+	if _, _, _, err := userService.RefreshAccessToken(context.Background(), refresh, "127.0.0.1"); err == nil {
+		t.Error("expected refresh to fail for a disabled account")
+	}
+	if active, _, _ := userService.AccountStatus(context.Background(), user.ID); active {
+		t.Error("expected disabled account to be inactive")
+	}
+
+	disabled := true
+	users, total, err := adminService.List(context.Background(), domain.UserFilter{Disabled: &disabled})
+	if err != nil || total != 1 || users[0].ID != user.ID || users[0].PasswordHash != "" {
+		t.Errorf("expected only the disabled user without a password hash, got %+v total=%d err=%v", users, total, err)
+	}
+
+	// Re-enabling lets them log in again
+	if _, err := adminService.SetDisabled(context.Background(), admin.ID, user.ID, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := userService.Login(context.Background(), "ana@example.com", "password123"); err != nil {
+		t.Errorf("expected login after re-enabling, got %v", err)
+	}
+
+	updated, err := adminService.SetRole(context.Background(), admin.ID, user.ID, "admin")
+	if err != nil || updated.Role != "admin" {
+		t.Errorf("expected user to become admin, got %+v err=%v", updated, err)
+	}
+
+	if err := adminService.Delete(context.Background(), admin.ID, user.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := adminService.Get(context.Background(), user.ID); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("expected ErrUserNotFound after delete, got %v", err)
+	}
+	if active, _, _ := userService.AccountStatus(context.Background(), user.ID); active {
+		t.Error("expected deleted account to be inactive")
+	}
+}
+
+func TestAdminUserService_ForcePasswordReset(t *testing.T) {
+	userService := newTestUserService(true)
+	adminService := NewAdminUserService(userService.userRepo, userService)
+	emailService := userService.emailService.(*mockEmailService)
+
+	user, _, err := userService.Register(context.Background(), "Ana", "ana@example.com", "password123")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := adminService.ForcePasswordReset(context.Background(), user.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := userService.Login(context.Background(), "ana@example.com", "password123"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("expected the old password to stop working, got %v", err)
+	}
+
+	stored, _ := userService.userRepo.GetByID(context.Background(), user.ID)
+	if stored.ResetToken == nil {
+		t.Fatal("expected a reset token to be stored")
+	}
+	if len(emailService.sentEmails) == 0 || emailService.sentEmails[len(emailService.sentEmails)-1].subject != "Password Reset" {
+		t.Errorf("expected a password reset email, got %+v", emailService.sentEmails)
+	}
+
+	if _, err := userService.ResetPassword(context.Background(), *stored.ResetToken, "newpassword123"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := userService.Login(context.Background(), "ana@example.com", "newpassword123"); err != nil {
+		t.Errorf("expected login with the new password, got %v", err)
+	}
+
+	// Registration auto-verifies when verification isn't required
+	if err := adminService.ResendVerification(context.Background(), user.ID); !errors.Is(err, ErrEmailAlreadyVerified) {
+		t.Errorf("expected ErrEmailAlreadyVerified, got %v", err)
+	}
+}
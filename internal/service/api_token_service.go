@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/johnzastrow/actalog/internal/domain"
+	"github.com/johnzastrow/actalog/pkg/auth"
+)
+
+var (
+	ErrAPITokenNotFound      = errors.New("API token not found")
+	ErrInvalidAPIToken       = errors.New("invalid or expired API token")
+	ErrInvalidAPITokenName   = errors.New("token name must be 1-100 characters")
+	ErrInvalidAPITokenScope  = errors.New("invalid API token scope")
+	ErrInvalidAPITokenExpiry = errors.New("token expiry must be in the future")
+)
+
+const (
+	apiTokenPrefixLength  = 20 // Characters of the token kept to recognise it
+	apiTokenMaxNameLength = 100
+	// Last-used times are only written this often, not on every request
+	apiTokenUseResolution = time.Minute
+)
+
+// APITokenService manages personal API tokens, which scripts and integrations
+// use instead of logging in with a password
+type APITokenService struct {
+	tokenRepo domain.APITokenRepository
+	userRepo  domain.UserRepository
+	now       func() time.Time
+}
+
+// NewAPITokenService creates a new API token service
+func NewAPITokenService(tokenRepo domain.APITokenRepository, userRepo domain.UserRepository) *APITokenService {
+	return &APITokenService{
+		tokenRepo: tokenRepo,
+		userRepo:  userRepo,
+		now:       time.Now,
+	}
+}
+
+// List returns a user's API tokens
+func (s *APITokenService) List(ctx context.Context, userID int64) ([]*domain.APIToken, error) {
+	tokens, err := s.tokenRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+// Create issues a new API token and returns it with the token value, which
+// is not stored and can't be retrieved again
+func (s *APITokenService) Create(ctx context.Context, userID int64, name string, scopes []string, expiresAt *time.Time) (*domain.APIToken, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > apiTokenMaxNameLength {
+		return nil, "", ErrInvalidAPITokenName
+	}
+	scopes, err := normalizeScopes(scopes)
+	if err != nil {
+		return nil, "", err
+	}
+	if expiresAt != nil && !expiresAt.After(s.now()) {
+		return nil, "", ErrInvalidAPITokenExpiry
+	}
+
+	value, err := auth.GenerateAPIToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	token := &domain.APIToken{
+		UserID:    userID,
+		Name:      name,
+		Prefix:    value[:apiTokenPrefixLength],
+		TokenHash: auth.HashAPIToken(value),
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+	}
+	if err := s.tokenRepo.Create(ctx, token); err != nil {
+		return nil, "", err
+	}
+
+	return token, value, nil
+}
+
+// Delete revokes one of a user's API tokens
+func (s *APITokenService) Delete(ctx context.Context, id, userID int64) error {
+	deleted, err := s.tokenRepo.Delete(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrAPITokenNotFound
+	}
+	return nil
+}
+
+// ValidateAPIToken checks an API token from a request and returns its user
+// and scopes. It implements middleware.APITokenValidator.
+func (s *APITokenService) ValidateAPIToken(ctx context.Context, value string) (*auth.APITokenClaims, error) {
+	token, err := s.tokenRepo.GetByHash(ctx, auth.HashAPIToken(value))
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	if token == nil || (token.ExpiresAt != nil && !token.ExpiresAt.After(now)) {
+		return nil, ErrInvalidAPIToken
+	}
+
+	user, err := s.userRepo.GetByID(ctx, token.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil || user.Disabled {
+		return nil, ErrInvalidAPIToken
+	}
+
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= apiTokenUseResolution {
+		if err := s.tokenRepo.RecordUse(ctx, token.ID, now); err != nil {
+			return nil, err
+		}
+	}
+
+	return &auth.APITokenClaims{
+		UserID: user.ID,
+		Email:  user.Email,
+		Role:   user.Role,
+		Scopes: token.Scopes,
+	}, nil
+}
+
+// normalizeScopes validates requested scopes and removes duplicates
+func normalizeScopes(scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return nil, fmt.Errorf("%w: at least one scope is required", ErrInvalidAPITokenScope)
+	}
+
+	result := []string{}
+	for _, scope := range scopes {
+		valid := false
+		for _, known := range domain.APITokenScopes {
+			if scope == known {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidAPITokenScope, scope)
+		}
+
+		duplicate := false
+		for _, s := range result {
+			if s == scope {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			result = append(result, scope)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/johnzastrow/actalog/internal/domain"
+	"github.com/johnzastrow/actalog/pkg/auth"
+)
+
+type mockAPITokenRepo struct {
+	tokens []*domain.APIToken
+	uses   int
+}
+
+func (m *mockAPITokenRepo) Create(ctx context.Context, token *domain.APIToken) error {
+	token.ID = int64(len(m.tokens) + 1)
+	token.CreatedAt = time.Now()
+	copied := *token
+	m.tokens = append(m.tokens, &copied)
+	return nil
+}
+
+func (m *mockAPITokenRepo) GetByHash(ctx context.Context, tokenHash string) (*domain.APIToken, error) {
+	for _, t := range m.tokens {
+		if t.TokenHash == tokenHash {
+			copied := *t
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockAPITokenRepo) ListByUser(ctx context.Context, userID int64) ([]*domain.APIToken, error) {
+	result := []*domain.APIToken{}
+	for _, t := range m.tokens {
+		if t.UserID == userID {
+			copied := *t
+			result = append(result, &copied)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockAPITokenRepo) RecordUse(ctx context.Context, id int64, usedAt time.Time) error {
+	m.uses++
+	for _, t := range m.tokens {
+		if t.ID == id {
+			t.LastUsedAt = &usedAt
+		}
+	}
+	return nil
+}
+
+func (m *mockAPITokenRepo) Delete(ctx context.Context, id, userID int64) (bool, error) {
+	for i, t := range m.tokens {
+		if t.ID == id && t.UserID == userID {
+			m.tokens = append(m.tokens[:i], m.tokens[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func TestAPIToken_CreateAndValidate(t *testing.T) {
+	userService := newTestUserService(true)
+	user, _, err := userService.Register(context.Background(), "Ana", "ana@gym.example", "Password123!")
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := &mockAPITokenRepo{}
+	tokenService := NewAPITokenService(repo, userService.userRepo)
+	now := time.Now()
+	tokenService.now = func() time.Time { return now }
+
+	token, value, err := tokenService.Create(context.Background(), user.ID, " Spreadsheet import ", []string{domain.ScopeWorkoutsWrite, domain.ScopeWorkoutsWrite, domain.ScopePRsRead}, nil)
+	if err != nil {
+		t.Fatalf("failed to create token: %v", err)
+	}
+	if !auth.IsAPIToken(value) || !strings.HasPrefix(value, token.Prefix) {
+		t.Errorf("unexpected token value %q with prefix %q", value, token.Prefix)
+	}
+	if token.Name != "Spreadsheet import" || len(token.Scopes) != 2 {
+		t.Errorf("expected trimmed name and deduplicated scopes, got %+v", token)
+	}
+	if repo.tokens[0].TokenHash == value || strings.Contains(repo.tokens[0].TokenHash, value) {
+		t.Error("token value must not be stored")
+	}
+
+	claims, err := tokenService.ValidateAPIToken(context.Background(), value)
+	if err != nil {
+		t.Fatalf("failed to validate token: %v", err)
+	}
+	if claims.UserID != user.ID || claims.Email != "ana@gym.example" || claims.Role != "admin" || len(claims.Scopes) != 2 {
+		t.Errorf("unexpected claims %+v", claims)
+	}
+
+	// Last use is recorded at most once a minute
+	if _, err := tokenService.ValidateAPIToken(context.Background(), value); err != nil {
+		t.Fatal(err)
+	}
+	if repo.uses != 1 {
+		t.Errorf("expected 1 recorded use, got %d", repo.uses)
+	}
+
+	if _, err := tokenService.ValidateAPIToken(context.Background(), value+"x"); !errors.Is(err, ErrInvalidAPIToken) {
+		t.Errorf("expected ErrInvalidAPIToken for an unknown token, got %v", err)
+	}
+
+	// Only the owner can delete a token; deleted tokens stop working
+	if err := tokenService.Delete(context.Background(), token.ID, user.ID+1); !errors.Is(err, ErrAPITokenNotFound) {
+		t.Errorf("expected ErrAPITokenNotFound for another user, got %v", err)
+	}
+	if err := tokenService.Delete(context.Background(), token.ID, user.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tokenService.ValidateAPIToken(context.Background(), value); !errors.Is(err, ErrInvalidAPIToken) {
+		t.Errorf("expected ErrInvalidAPIToken after delete, got %v", err)
+	}
+}
+
+func TestAPIToken_Expiry(t *testing.T) {
+	userService := newTestUserService(true)
+	user, _, _ := userService.Register(context.Background(), "Ana", "ana@gym.example", "Password123!")
+	tokenService := NewAPITokenService(&mockAPITokenRepo{}, userService.userRepo)
+	now := time.Now()
+	tokenService.now = func() time.Time { return now }
+
+	past := now.Add(-time.Hour)
+	if _, _, err := tokenService.Create(context.Background(), user.ID, "Old", []string{domain.ScopeWorkoutsRead}, &past); !errors.Is(err, ErrInvalidAPITokenExpiry) {
+		t.Errorf("expected ErrInvalidAPITokenExpiry, got %v", err)
+	}
+
+	expiresAt := now.Add(time.Hour)
+	_, value, err := tokenService.Create(context.Background(), user.ID, "Short-lived", []string{domain.ScopeWorkoutsRead}, &expiresAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tokenService.ValidateAPIToken(context.Background(), value); err != nil {
+		t.Errorf("expected token to be valid before expiry, got %v", err)
+	}
+	now = now.Add(2 * time.Hour)
+	if _, err := tokenService.ValidateAPIToken(context.Background(), value); !errors.Is(err, ErrInvalidAPIToken) {
+		t.Errorf("expected ErrInvalidAPIToken after expiry, got %v", err)
+	}
+}
+
+func TestAPIToken_Validation(t *testing.T) {
+	tokenService := NewAPITokenService(&mockAPITokenRepo{}, newTestUserService(true).userRepo)
+
+	tests := []struct {
+		name    string
+		token   string
+		scopes  []string
+		wantErr error
+	}{
+		{"missing name", " ", []string{domain.ScopeWorkoutsRead}, ErrInvalidAPITokenName},
+		{"long name", strings.Repeat("x", 101), []string{domain.ScopeWorkoutsRead}, ErrInvalidAPITokenName},
+		{"no scopes", "Script", nil, ErrInvalidAPITokenScope},
+		{"unknown scope", "Script", []string{"admin:everything"}, ErrInvalidAPITokenScope},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := tokenService.Create(context.Background(), 1, tt.token, tt.scopes, nil); !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/johnzastrow/actalog/internal/domain"
+)
+
+const (
+	defaultAuditListLimit = 50
+	maxAuditListLimit     = 500
+)
+
+// AuditService records security-relevant and admin actions and lets admins
+// search them
+type AuditService struct {
+	auditRepo domain.AuditLogRepository
+	now       func() time.Time
+}
+
+// NewAuditService creates a new audit service
+func NewAuditService(auditRepo domain.AuditLogRepository) *AuditService {
+	return &AuditService{
+		auditRepo: auditRepo,
+		now:       time.Now,
+	}
+}
+
+// Record appends an entry to the audit log, stamping it with the current time
+func (s *AuditService) Record(ctx context.Context, entry *domain.AuditEntry) error {
+	entry.CreatedAt = s.now()
+	if err := s.auditRepo.Create(ctx, entry); err != nil {
+		return fmt.Errorf("failed to record audit entry: %w", err)
+	}
+	return nil
+}
+
+// List returns a page of audit entries matching the filter, newest first,
+// and the total number of matches. The limit defaults to 50 and is capped
+// at 500.
+func (s *AuditService) List(ctx context.Context, filter domain.AuditFilter) ([]*domain.AuditEntry, int64, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultAuditListLimit
+	}
+	if filter.Limit > maxAuditListLimit {
+		filter.Limit = maxAuditListLimit
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	entries, total, err := s.auditRepo.List(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list audit entries: %w", err)
+	}
+	return entries, total, nil
+}
+
+// AuditState encodes a value for an audit entry's Before or After field.
+// Values that can't be encoded are recorded as null rather than failing the
+// action being audited.
+func AuditState(v interface{}) json.RawMessage {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return json.RawMessage("null")
+	}
+	return data
+}
@@ -0,0 +1,497 @@
+package service
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/johnzastrow/actalog/internal/domain"
+	"github.com/johnzastrow/actalog/pkg/version"
+)
+
+var (
+	ErrBackupNotFound = errors.New("backup not found")
+	ErrInvalidBackup  = errors.New("not an ActaLog backup archive")
+)
+
+const (
+	// Paths inside a backup archive
+	backupManifestName = "manifest.json"
+	backupDatabaseName = "actalog.db"
+	backupAvatarsDir   = "uploads/avatars"
+
+	// backupTimeFormat names archives by when they were taken, in UTC
+	backupTimeFormat = "20060102-150405"
+
+	// backupMaxCheckInterval is the longest the scheduler waits between
+	// looking for a due backup
+	backupMaxCheckInterval = time.Hour
+)
+
+// backupName matches the archives the service writes, e.g.
+// actalog-20251018-020000.tar.gz; a second backup in the same second gets
+// a -2 suffix
+var backupName = regexp.MustCompile(`^actalog-(\d{8}-\d{6})(-\d+)?\.tar\.gz$`)
+
+// BackupService writes backup archives of a SQLite deployment: a snapshot of
+// the database taken while the server runs, the uploaded avatars and a
+// manifest with the release that made them. Archives are kept in one
+// directory; after each backup only the newest ones are kept.
+type BackupService struct {
+	snapshotter domain.DatabaseSnapshotter
+	dir         string
+	avatarsDir  string
+	retain      int           // Archives to keep (0 keeps all)
+	interval    time.Duration // Time between scheduled backups (0 disables them)
+	now         func() time.Time
+	mu          sync.Mutex // Serializes backups so archives don't collide
+}
+
+// NewBackupService creates a new backup service
+func NewBackupService(
+	snapshotter domain.DatabaseSnapshotter,
+	dir string,
+	avatarsDir string,
+	retain int,
+	interval time.Duration,
+) *BackupService {
+	return &BackupService{
+		snapshotter: snapshotter,
+		dir:         dir,
+		avatarsDir:  avatarsDir,
+		retain:      retain,
+		interval:    interval,
+		now:         time.Now,
+	}
+}
+
+// Run takes a backup whenever the newest one is a full interval old, until
+// the context is canceled. Due backups are worked out from the archives on
+// disk, so restarting the server doesn't take an extra one.
+func (s *BackupService) Run(ctx context.Context) {
+	if s.interval <= 0 {
+		return
+	}
+	checkInterval := s.interval
+	if checkInterval > backupMaxCheckInterval {
+		checkInterval = backupMaxCheckInterval
+	}
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	for {
+		_, _ = s.CreateIfDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CreateIfDue takes a scheduled backup if there is none yet or the newest is
+// at least an interval old. Returns the backup, or nil if none was due.
+func (s *BackupService) CreateIfDue(ctx context.Context) (*domain.Backup, error) {
+	backups, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	if len(backups) > 0 && s.now().Sub(backups[0].CreatedAt) < s.interval {
+		return nil, nil
+	}
+	return s.Create(ctx, domain.BackupTriggerScheduled)
+}
+
+// Create writes a new backup archive and then removes archives beyond the
+// number to keep
+func (s *BackupService) Create(ctx context.Context, trigger string) (*domain.Backup, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	createdAt := s.now().UTC().Truncate(time.Second)
+	name := s.archiveName(createdAt)
+
+	// The archive is assembled under a temporary name, so a failed or
+	// interrupted backup never looks like a finished one
+	tmp, err := os.CreateTemp(s.dir, ".backup-*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create backup archive: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	err = s.writeArchive(ctx, tmp, createdAt, trigger)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(s.dir, name)); err != nil {
+		return nil, fmt.Errorf("failed to save backup archive: %w", err)
+	}
+
+	info, err := os.Stat(filepath.Join(s.dir, name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to save backup archive: %w", err)
+	}
+	if err := s.prune(); err != nil {
+		return nil, err
+	}
+	return &domain.Backup{Name: name, Size: info.Size(), CreatedAt: createdAt}, nil
+}
+
+// archiveName picks an unused name for an archive taken at createdAt
+func (s *BackupService) archiveName(createdAt time.Time) string {
+	stem := "actalog-" + createdAt.Format(backupTimeFormat)
+	name := stem + ".tar.gz"
+	for i := 2; ; i++ {
+		if _, err := os.Stat(filepath.Join(s.dir, name)); errors.Is(err, fs.ErrNotExist) {
+			return name
+		}
+		name = fmt.Sprintf("%s-%d.tar.gz", stem, i)
+	}
+}
+
+// writeArchive writes the manifest, a database snapshot and the avatars to
+// w as a gzipped tar archive
+func (s *BackupService) writeArchive(ctx context.Context, w io.Writer, createdAt time.Time, trigger string) error {
+	workDir, err := os.MkdirTemp("", "actalog-backup-")
+	if err != nil {
+		return fmt.Errorf("failed to create working directory: %w", err)
+	}
+	defer os.RemoveAll(workDir)
+
+	snapshot := filepath.Join(workDir, backupDatabaseName)
+	if err := s.snapshotter.Snapshot(ctx, snapshot); err != nil {
+		return err
+	}
+	schemaVersion, err := s.snapshotter.SchemaVersion(ctx)
+	if err != nil {
+		return err
+	}
+	avatars, err := listFiles(s.avatarsDir)
+	if err != nil {
+		return fmt.Errorf("failed to list avatars: %w", err)
+	}
+	manifest, err := json.MarshalIndent(domain.BackupManifest{
+		AppVersion:    version.Version(),
+		Build:         version.BuildNumber(),
+		Driver:        s.snapshotter.Driver(),
+		SchemaVersion: schemaVersion,
+		Avatars:       len(avatars),
+		Trigger:       trigger,
+		CreatedAt:     createdAt,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	err = tw.WriteHeader(&tar.Header{Name: backupManifestName, Mode: 0o600, Size: int64(len(manifest)), ModTime: createdAt})
+	if err == nil {
+		_, err = tw.Write(manifest)
+	}
+	if err == nil {
+		err = addFileToArchive(tw, snapshot, backupDatabaseName)
+	}
+	for _, avatar := range avatars {
+		if err != nil {
+			break
+		}
+		err = addFileToArchive(tw, filepath.Join(s.avatarsDir, avatar), path.Join(backupAvatarsDir, filepath.ToSlash(avatar)))
+	}
+	if err == nil {
+		err = tw.Close()
+	}
+	if err == nil {
+		err = gz.Close()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write backup archive: %w", err)
+	}
+	return nil
+}
+
+// List returns the backup archives, newest first
+func (s *BackupService) List() ([]*domain.Backup, error) {
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return []*domain.Backup{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backups: %w", err)
+	}
+
+	backups := []*domain.Backup{}
+	for _, entry := range entries {
+		match := backupName.FindStringSubmatch(entry.Name())
+		if match == nil || !entry.Type().IsRegular() {
+			continue
+		}
+		createdAt, err := time.Parse(backupTimeFormat, match[1])
+		if err != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list backups: %w", err)
+		}
+		backups = append(backups, &domain.Backup{Name: entry.Name(), Size: info.Size(), CreatedAt: createdAt})
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		if !backups[i].CreatedAt.Equal(backups[j].CreatedAt) {
+			return backups[i].CreatedAt.After(backups[j].CreatedAt)
+		}
+		// Among backups in the same second, later ones have longer suffixes
+		if len(backups[i].Name) != len(backups[j].Name) {
+			return len(backups[i].Name) > len(backups[j].Name)
+		}
+		return backups[i].Name > backups[j].Name
+	})
+	return backups, nil
+}
+
+// Open opens a backup archive by name for reading
+func (s *BackupService) Open(name string) (*os.File, error) {
+	if !backupName.MatchString(name) {
+		return nil, ErrBackupNotFound
+	}
+	file, err := os.Open(filepath.Join(s.dir, name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrBackupNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open backup: %w", err)
+	}
+	return file, nil
+}
+
+// prune removes the oldest archives beyond the number to keep
+func (s *BackupService) prune() error {
+	if s.retain <= 0 {
+		return nil
+	}
+	backups, err := s.List()
+	if err != nil {
+		return err
+	}
+	for i := s.retain; i < len(backups); i++ {
+		if err := os.Remove(filepath.Join(s.dir, backups[i].Name)); err != nil {
+			return fmt.Errorf("failed to remove old backup: %w", err)
+		}
+	}
+	return nil
+}
+
+// RestoreBackup replaces the SQLite database at dbPath and the avatars
+// directory with the contents of a backup archive. The server must be
+// stopped. The archive's database is checked before anything is replaced,
+// and the current database (with its -wal and -shm files) and avatars are
+// renamed with a .pre-restore-<time> suffix rather than deleted. Returns the
+// archive's manifest and the paths that were set aside.
+func RestoreBackup(ctx context.Context, snapshotter domain.DatabaseSnapshotter, archivePath, dbPath, avatarsDir string) (*domain.BackupManifest, []string, error) {
+	if snapshotter.Driver() != "sqlite3" {
+		return nil, nil, domain.ErrBackupUnsupported
+	}
+
+	// Extract next to the database, so the files can be renamed into place
+	workDir, err := os.MkdirTemp(filepath.Dir(dbPath), ".actalog-restore-")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create working directory: %w", err)
+	}
+	defer os.RemoveAll(workDir)
+
+	manifest, err := extractBackup(archivePath, workDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	if manifest.Driver != "sqlite3" {
+		return nil, nil, fmt.Errorf("%w: archive is of a %s database", ErrInvalidBackup, manifest.Driver)
+	}
+	if err := snapshotter.Verify(ctx, filepath.Join(workDir, backupDatabaseName)); err != nil {
+		return nil, nil, fmt.Errorf("backup database failed verification: %w", err)
+	}
+
+	suffix := ".pre-restore-" + time.Now().UTC().Format(backupTimeFormat)
+	var setAside []string
+	for _, current := range []string{dbPath, dbPath + "-wal", dbPath + "-shm", avatarsDir} {
+		if _, err := os.Lstat(current); errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err := os.Rename(current, current+suffix); err != nil {
+			return nil, setAside, fmt.Errorf("failed to set aside %s: %w", current, err)
+		}
+		setAside = append(setAside, current+suffix)
+	}
+
+	if err := os.Rename(filepath.Join(workDir, backupDatabaseName), dbPath); err != nil {
+		return nil, setAside, fmt.Errorf("failed to restore database: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(avatarsDir), 0o755); err != nil {
+		return nil, setAside, fmt.Errorf("failed to restore avatars: %w", err)
+	}
+	extracted := filepath.Join(workDir, filepath.FromSlash(backupAvatarsDir))
+	if err := os.MkdirAll(extracted, 0o755); err != nil {
+		return nil, setAside, fmt.Errorf("failed to restore avatars: %w", err)
+	}
+	if err := moveDir(extracted, avatarsDir); err != nil {
+		return nil, setAside, fmt.Errorf("failed to restore avatars: %w", err)
+	}
+	return manifest, setAside, nil
+}
+
+// extractBackup unpacks a backup archive into dir and returns its manifest.
+// Only the manifest, the database and files under the avatars directory
+// are accepted.
+func extractBackup(archivePath, dir string) (*domain.BackupManifest, error) {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+	}
+	defer gz.Close()
+
+	var manifest *domain.BackupManifest
+	hasDatabase := false
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+		}
+		if header.Typeflag == tar.TypeDir {
+			continue
+		}
+		name := path.Clean(header.Name)
+		valid := header.Typeflag == tar.TypeReg &&
+			(name == backupManifestName || name == backupDatabaseName ||
+				strings.HasPrefix(name, backupAvatarsDir+"/") && filepath.IsLocal(name))
+		if !valid {
+			return nil, fmt.Errorf("%w: unexpected entry %s", ErrInvalidBackup, header.Name)
+		}
+
+		if name == backupManifestName {
+			manifest = &domain.BackupManifest{}
+			if err := json.NewDecoder(tr).Decode(manifest); err != nil {
+				return nil, fmt.Errorf("%w: invalid manifest: %v", ErrInvalidBackup, err)
+			}
+			continue
+		}
+		hasDatabase = hasDatabase || name == backupDatabaseName
+		if err := extractFile(tr, filepath.Join(dir, filepath.FromSlash(name))); err != nil {
+			return nil, fmt.Errorf("failed to extract %s: %w", name, err)
+		}
+	}
+
+	if manifest == nil || !hasDatabase {
+		return nil, fmt.Errorf("%w: missing manifest or database", ErrInvalidBackup)
+	}
+	return manifest, nil
+}
+
+// extractFile writes the current archive entry to path
+func extractFile(r io.Reader, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(file, r); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// addFileToArchive copies the file at path into the archive as name
+func addFileToArchive(tw *tar.Writer, path, name string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	header := &tar.Header{Name: name, Mode: 0o600, Size: info.Size(), ModTime: info.ModTime()}
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err = io.Copy(tw, file)
+	return err
+}
+
+// listFiles returns the regular files under dir, relative to it. A missing
+// directory has none.
+func listFiles(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(p string, entry fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) && p == dir {
+			return fs.SkipDir
+		}
+		if err != nil || !entry.Type().IsRegular() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		files = append(files, rel)
+		return nil
+	})
+	return files, err
+}
+
+// moveDir renames a directory, falling back to copying it when the two
+// paths are on different filesystems
+func moveDir(from, to string) error {
+	if err := os.Rename(from, to); err == nil {
+		return nil
+	}
+	files, err := listFiles(from)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(to, 0o755); err != nil {
+		return err
+	}
+	for _, rel := range files {
+		src, err := os.Open(filepath.Join(from, rel))
+		if err != nil {
+			return err
+		}
+		err = extractFile(src, filepath.Join(to, rel))
+		src.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
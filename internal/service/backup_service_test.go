@@ -0,0 +1,205 @@
+package service
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/johnzastrow/actalog/internal/domain"
+)
+
+// mockSnapshotter "snapshots" a database by writing its contents to a file
+type mockSnapshotter struct {
+	contents  string
+	verifyErr error
+}
+
+func (m *mockSnapshotter) Driver() string { return "sqlite3" }
+
+func (m *mockSnapshotter) Snapshot(ctx context.Context, path string) error {
+	return os.WriteFile(path, []byte(m.contents), 0o600)
+}
+
+func (m *mockSnapshotter) SchemaVersion(ctx context.Context) (int, error) { return 2, nil }
+
+func (m *mockSnapshotter) Verify(ctx context.Context, path string) error { return m.verifyErr }
+
+func writeTestFile(t *testing.T, path, contents string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readTestFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestBackupService_CreateAndRestore(t *testing.T) {
+	dir := t.TempDir()
+	avatarsDir := filepath.Join(dir, "uploads", "avatars")
+	writeTestFile(t, filepath.Join(avatarsDir, "user_1.png"), "avatar one")
+	snapshotter := &mockSnapshotter{contents: "database v1"}
+	svc := NewBackupService(snapshotter, filepath.Join(dir, "backups"), avatarsDir, 0, 0)
+	ctx := context.Background()
+
+	backup, err := svc.Create(ctx, domain.BackupTriggerCLI)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if backup.Size == 0 || !backupName.MatchString(backup.Name) {
+		t.Errorf("unexpected backup %+v", backup)
+	}
+
+	// The live data changes after the backup
+	dbPath := filepath.Join(dir, "actalog.db")
+	writeTestFile(t, dbPath, "database v2")
+	writeTestFile(t, dbPath+"-wal", "wal")
+	writeTestFile(t, filepath.Join(avatarsDir, "user_2.png"), "avatar two")
+
+	manifest, setAside, err := RestoreBackup(ctx, snapshotter, filepath.Join(dir, "backups", backup.Name), dbPath, avatarsDir)
+	if err != nil {
+		t.Fatalf("RestoreBackup failed: %v", err)
+	}
+	if manifest.SchemaVersion != 2 || manifest.Avatars != 1 || manifest.Trigger != domain.BackupTriggerCLI || manifest.AppVersion == "" {
+		t.Errorf("unexpected manifest %+v", manifest)
+	}
+	if got := readTestFile(t, dbPath); got != "database v1" {
+		t.Errorf("expected the backed up database, got %q", got)
+	}
+	if got := readTestFile(t, filepath.Join(avatarsDir, "user_1.png")); got != "avatar one" {
+		t.Errorf("expected the backed up avatar, got %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(avatarsDir, "user_2.png")); !errors.Is(err, os.ErrNotExist) {
+		t.Error("expected avatars added after the backup to be set aside")
+	}
+	if _, err := os.Stat(dbPath + "-wal"); !errors.Is(err, os.ErrNotExist) {
+		t.Error("expected the old write-ahead log to be set aside")
+	}
+
+	// Nothing is deleted
+	if len(setAside) != 3 {
+		t.Fatalf("expected the database, its WAL and the avatars set aside, got %v", setAside)
+	}
+	if got := readTestFile(t, setAside[0]); got != "database v2" {
+		t.Errorf("expected the replaced database to be kept, got %q", got)
+	}
+}
+
+func TestBackupService_RestoreRejectsBadBackups(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "actalog.db")
+	writeTestFile(t, dbPath, "current")
+	ctx := context.Background()
+
+	// A database that fails verification leaves the current one in place
+	snapshotter := &mockSnapshotter{contents: "damaged"}
+	svc := NewBackupService(snapshotter, filepath.Join(dir, "backups"), filepath.Join(dir, "avatars"), 0, 0)
+	backup, err := svc.Create(ctx, domain.BackupTriggerCLI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshotter.verifyErr = errors.New("database is damaged")
+	if _, _, err := RestoreBackup(ctx, snapshotter, filepath.Join(dir, "backups", backup.Name), dbPath, filepath.Join(dir, "avatars")); err == nil {
+		t.Error("expected a damaged database to be rejected")
+	}
+	if got := readTestFile(t, dbPath); got != "current" {
+		t.Errorf("expected the current database to be left alone, got %q", got)
+	}
+
+	// So does an archive with paths outside the backup
+	archive := filepath.Join(dir, "evil.tar.gz")
+	file, err := os.Create(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(file)
+	tw := tar.NewWriter(gz)
+	contents := []byte("x")
+	if err := tw.WriteHeader(&tar.Header{Name: "uploads/avatars/../../../escape", Mode: 0o600, Size: int64(len(contents))}); err != nil {
+		t.Fatal(err)
+	}
+	tw.Write(contents)
+	tw.Close()
+	gz.Close()
+	file.Close()
+
+	snapshotter.verifyErr = nil
+	if _, _, err := RestoreBackup(ctx, snapshotter, archive, dbPath, filepath.Join(dir, "avatars")); !errors.Is(err, ErrInvalidBackup) {
+		t.Errorf("expected ErrInvalidBackup, got %v", err)
+	}
+}
+
+func TestBackupService_ScheduleAndRetention(t *testing.T) {
+	dir := t.TempDir()
+	svc := NewBackupService(&mockSnapshotter{contents: "db"}, dir, filepath.Join(dir, "avatars"), 2, 24*time.Hour)
+	now := time.Date(2025, 10, 6, 2, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	// The first check takes a backup, later ones wait for the interval
+	for i, want := range []bool{true, false, true, true} {
+		backup, err := svc.CreateIfDue(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := backup != nil; got != want {
+			t.Errorf("check %d: expected backup taken %t, got %t", i, want, got)
+		}
+		if i == 0 {
+			now = now.Add(time.Hour)
+		} else {
+			now = now.Add(24 * time.Hour)
+		}
+	}
+
+	// Only the newest two are kept
+	backups, err := svc.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 2 {
+		t.Fatalf("expected 2 backups kept, got %d", len(backups))
+	}
+	if want := "actalog-20251008-030000.tar.gz"; backups[0].Name != want {
+		t.Errorf("expected newest backup %s first, got %s", want, backups[0].Name)
+	}
+
+	// Backups in the same second get distinct names
+	first, err := svc.Create(ctx, domain.BackupTriggerAdmin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := svc.Create(ctx, domain.BackupTriggerAdmin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Name == second.Name {
+		t.Errorf("expected distinct names, got %s twice", first.Name)
+	}
+	if backups, _ := svc.List(); len(backups) != 2 || backups[0].Name != second.Name || backups[1].Name != first.Name {
+		t.Errorf("expected the later backup listed first, got %v and %v", backups[0], backups[1])
+	}
+
+	if _, err := svc.Open("../secrets.tar.gz"); !errors.Is(err, ErrBackupNotFound) {
+		t.Errorf("expected ErrBackupNotFound for a name outside the directory, got %v", err)
+	}
+	file, err := svc.Open(second.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+}
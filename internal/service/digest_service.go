@@ -0,0 +1,328 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/johnzastrow/actalog/internal/domain"
+	"github.com/johnzastrow/actalog/pkg/email"
+)
+
+const (
+	digestPageSize     = 100
+	digestStreakWeeks  = 52 // How far back to look when counting the week streak
+	digestUpcomingDays = 7
+)
+
+// DigestService builds weekly training digests and emails them to users who
+// opted in via the "weekly_digest" notification preference. Each user receives
+// at most one digest per week, for the most recently completed week
+// (Monday to Sunday, UTC).
+type DigestService struct {
+	userRepo                domain.UserRepository
+	settingsRepo            domain.UserSettingsRepository
+	userWorkoutRepo         domain.UserWorkoutRepository
+	userWorkoutMovementRepo domain.UserWorkoutMovementRepository
+	userWorkoutWODRepo      domain.UserWorkoutWODRepository
+	digestRepo              domain.DigestRepository
+	emailService            email.EmailService
+	appURL                  string
+	checkInterval           time.Duration
+	now                     func() time.Time
+}
+
+// NewDigestService creates a new digest service
+func NewDigestService(
+	userRepo domain.UserRepository,
+	settingsRepo domain.UserSettingsRepository,
+	userWorkoutRepo domain.UserWorkoutRepository,
+	userWorkoutMovementRepo domain.UserWorkoutMovementRepository,
+	userWorkoutWODRepo domain.UserWorkoutWODRepository,
+	digestRepo domain.DigestRepository,
+	emailService email.EmailService,
+	appURL string,
+	checkInterval time.Duration,
+) *DigestService {
+	return &DigestService{
+		userRepo:                userRepo,
+		settingsRepo:            settingsRepo,
+		userWorkoutRepo:         userWorkoutRepo,
+		userWorkoutMovementRepo: userWorkoutMovementRepo,
+		userWorkoutWODRepo:      userWorkoutWODRepo,
+		digestRepo:              digestRepo,
+		emailService:            emailService,
+		appURL:                  appURL,
+		checkInterval:           checkInterval,
+		now:                     time.Now,
+	}
+}
+
+// Run sends due digests every check interval until the context is canceled.
+// Checking repeatedly is safe because each digest is only sent once.
+func (s *DigestService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.checkInterval)
+	defer ticker.Stop()
+
+	for {
+		_, _ = s.SendDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SendDue sends last week's digest to every opted-in user who hasn't received
+// it yet. Returns the number of digests sent; failures for individual users
+// are joined into the returned error and retried on the next run.
+func (s *DigestService) SendDue(ctx context.Context) (int, error) {
+	weekStart := startOfWeek(s.now()).AddDate(0, 0, -7)
+
+	sent := 0
+	var errs []error
+	for offset := 0; ; offset += digestPageSize {
+		page, err := s.settingsRepo.List(ctx, digestPageSize, offset)
+		if err != nil {
+			return sent, fmt.Errorf("failed to list user settings: %w", err)
+		}
+
+		for _, settings := range page {
+			if !settings.NotificationPreferences.WeeklyDigest {
+				continue
+			}
+
+			ok, err := s.sendDigest(ctx, settings, weekStart)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("user %d: %w", settings.UserID, err))
+				continue
+			}
+			if ok {
+				sent++
+			}
+		}
+
+		if len(page) < digestPageSize {
+			break
+		}
+	}
+
+	return sent, errors.Join(errs...)
+}
+
+// sendDigest claims and sends one user's digest. Returns false if the user no
+// longer exists or the digest was already sent.
+func (s *DigestService) sendDigest(ctx context.Context, settings *domain.UserSettings, weekStart time.Time) (bool, error) {
+	user, err := s.userRepo.GetByID(ctx, settings.UserID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return false, nil
+	}
+
+	claimed, err := s.digestRepo.Claim(ctx, user.ID, weekStart)
+	if err != nil {
+		return false, err
+	}
+	if !claimed {
+		return false, nil
+	}
+
+	digest, err := s.BuildDigest(ctx, user.ID, weekStart, settings.WeightUnit)
+	if err == nil {
+		err = s.emailService.SendTemplate(ctx, email.TemplateWeeklyDigest, emailRecipient(user), s.templateData(digest))
+	}
+	if err != nil {
+		// Release the claim so the next run retries this user
+		if releaseErr := s.digestRepo.Release(ctx, user.ID, weekStart); releaseErr != nil {
+			return false, errors.Join(err, releaseErr)
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// BuildDigest summarizes a user's training for the week starting at weekStart
+func (s *DigestService) BuildDigest(ctx context.Context, userID int64, weekStart time.Time, weightUnit string) (*domain.WeeklyDigest, error) {
+	weekStart = startOfWeek(weekStart)
+	weekEnd := weekStart.AddDate(0, 0, 7)
+
+	digest := &domain.WeeklyDigest{
+		UserID:     userID,
+		WeekStart:  weekStart,
+		WeekEnd:    weekEnd,
+		WeightUnit: weightUnit,
+		PRs:        []domain.DigestPR{},
+		Upcoming:   []domain.DigestSession{},
+	}
+
+	// One query covers both this week's workouts and the streak history
+	history, err := s.userWorkoutRepo.ListByUserAndDateRange(ctx, userID, weekStart.AddDate(0, 0, -7*digestStreakWeeks), weekEnd.Add(-time.Second))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workouts: %w", err)
+	}
+
+	activeWeeks := make(map[time.Time]bool)
+	for _, workout := range history {
+		activeWeeks[startOfWeek(workout.WorkoutDate)] = true
+		if workout.WorkoutDate.Before(weekStart) {
+			continue
+		}
+
+		digest.WorkoutsLogged++
+		if err := s.addPerformance(ctx, digest, workout); err != nil {
+			return nil, err
+		}
+	}
+
+	for week := weekStart; activeWeeks[week]; week = week.AddDate(0, 0, -7) {
+		digest.WeekStreak++
+	}
+
+	sort.Slice(digest.PRs, func(i, j int) bool { return digest.PRs[i].Date.Before(digest.PRs[j].Date) })
+
+	upcoming, err := s.upcomingSessions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	digest.Upcoming = upcoming
+
+	return digest, nil
+}
+
+// addPerformance adds a workout's volume and PRs to the digest
+func (s *DigestService) addPerformance(ctx context.Context, digest *domain.WeeklyDigest, workout *domain.UserWorkout) error {
+	movements, err := s.userWorkoutMovementRepo.GetByUserWorkoutID(ctx, workout.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get workout movements: %w", err)
+	}
+	for _, m := range movements {
+		if m.Reps != nil && m.Weight != nil {
+			sets := 1
+			if m.Sets != nil {
+				sets = *m.Sets
+			}
+			digest.TotalVolume += float64(sets**m.Reps) * *m.Weight
+		}
+
+		if m.IsPR {
+			name := m.MovementName
+			if m.Movement != nil && m.Movement.Name != "" {
+				name = m.Movement.Name
+			}
+			digest.PRs = append(digest.PRs, domain.DigestPR{
+				Name:  name,
+				Score: movementScore(m, digest.WeightUnit),
+				Date:  workout.WorkoutDate,
+			})
+		}
+	}
+
+	wods, err := s.userWorkoutWODRepo.GetByUserWorkoutID(ctx, workout.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get workout WODs: %w", err)
+	}
+	for _, w := range wods {
+		if !w.IsPR {
+			continue
+		}
+		name := w.WODName
+		if w.WOD != nil && w.WOD.Name != "" {
+			name = w.WOD.Name
+		}
+		score := ""
+		if w.ScoreValue != nil {
+			score = *w.ScoreValue
+		}
+		digest.PRs = append(digest.PRs, domain.DigestPR{
+			Name:  name,
+			Score: score,
+			Date:  workout.WorkoutDate,
+		})
+	}
+
+	return nil
+}
+
+// upcomingSessions lists workouts scheduled from today through the next week
+func (s *DigestService) upcomingSessions(ctx context.Context, userID int64) ([]domain.DigestSession, error) {
+	now := s.now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	scheduled, err := s.userWorkoutRepo.ListByUserAndDateRange(ctx, userID, today, today.AddDate(0, 0, digestUpcomingDays))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scheduled workouts: %w", err)
+	}
+
+	sessions := []domain.DigestSession{}
+	for _, workout := range scheduled {
+		name := "Workout"
+		details, err := s.userWorkoutRepo.GetByIDWithDetails(ctx, workout.ID, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get scheduled workout: %w", err)
+		}
+		if details != nil && details.WorkoutName != "" {
+			name = details.WorkoutName
+		}
+		sessions = append(sessions, domain.DigestSession{Name: name, Date: workout.WorkoutDate})
+	}
+
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].Date.Before(sessions[j].Date) })
+	return sessions, nil
+}
+
+// templateData flattens a digest into the weekly_digest template's data
+func (s *DigestService) templateData(digest *domain.WeeklyDigest) map[string]interface{} {
+	return map[string]interface{}{
+		"WeekStart":   digest.WeekStart,
+		"WeekEnd":     digest.WeekEnd.AddDate(0, 0, -1), // Last day of the week, for display
+		"Workouts":    digest.WorkoutsLogged,
+		"Volume":      formatThousands(int64(math.Round(digest.TotalVolume))),
+		"WeightUnit":  digest.WeightUnit,
+		"WeekStreak":  digest.WeekStreak,
+		"PRs":         digest.PRs,
+		"Upcoming":    digest.Upcoming,
+		"SettingsURL": s.appURL + "/settings",
+	}
+}
+
+// movementScore formats a movement PR (weight if recorded, otherwise reps or time)
+func movementScore(m *domain.UserWorkoutMovement, weightUnit string) string {
+	switch {
+	case m.Weight != nil:
+		return strconv.FormatFloat(*m.Weight, 'f', -1, 64) + " " + weightUnit
+	case m.Reps != nil:
+		return strconv.Itoa(*m.Reps) + " reps"
+	case m.Time != nil:
+		return fmt.Sprintf("%d:%02d", *m.Time/60, *m.Time%60)
+	default:
+		return ""
+	}
+}
+
+// startOfWeek returns Monday 00:00 UTC of the week containing t
+func startOfWeek(t time.Time) time.Time {
+	t = t.UTC()
+	daysSinceMonday := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-daysSinceMonday, 0, 0, 0, 0, time.UTC)
+}
+
+// formatThousands formats n with comma thousands separators (e.g. 12,345)
+func formatThousands(n int64) string {
+	s := strconv.FormatInt(n, 10)
+	if n < 0 {
+		return "-" + formatThousands(-n)
+	}
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
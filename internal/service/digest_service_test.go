@@ -0,0 +1,264 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/johnzastrow/actalog/internal/domain"
+	"github.com/johnzastrow/actalog/pkg/email"
+)
+
+type mockUserSettingsRepo struct {
+	settings []*domain.UserSettings
+}
+
+func (m *mockUserSettingsRepo) GetByUserID(ctx context.Context, userID int64) (*domain.UserSettings, error) {
+	for _, s := range m.settings {
+		if s.UserID == userID {
+			return s, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockUserSettingsRepo) Create(ctx context.Context, settings *domain.UserSettings) error {
+	settings.ID = int64(len(m.settings) + 1)
+	m.settings = append(m.settings, settings)
+	return nil
+}
+
+func (m *mockUserSettingsRepo) Update(ctx context.Context, settings *domain.UserSettings) error {
+	return nil
+}
+
+func (m *mockUserSettingsRepo) Delete(ctx context.Context, userID int64) error {
+	return nil
+}
+
+func (m *mockUserSettingsRepo) List(ctx context.Context, limit, offset int) ([]*domain.UserSettings, error) {
+	if offset >= len(m.settings) {
+		return nil, nil
+	}
+	end := offset + limit
+	if end > len(m.settings) {
+		end = len(m.settings)
+	}
+	return m.settings[offset:end], nil
+}
+
+type mockDigestRepo struct {
+	claims map[string]bool
+}
+
+func (m *mockDigestRepo) key(userID int64, weekStart time.Time) string {
+	return fmt.Sprintf("%d/%s", userID, weekStart.Format("2006-01-02"))
+}
+
+func (m *mockDigestRepo) Claim(ctx context.Context, userID int64, weekStart time.Time) (bool, error) {
+	if m.claims[m.key(userID, weekStart)] {
+		return false, nil
+	}
+	m.claims[m.key(userID, weekStart)] = true
+	return true, nil
+}
+
+func (m *mockDigestRepo) Release(ctx context.Context, userID int64, weekStart time.Time) error {
+	delete(m.claims, m.key(userID, weekStart))
+	return nil
+}
+
+// failingEmailService fails the first n templated sends
+type failingEmailService struct {
+	mockEmailService
+	failures int
+}
+
+func (f *failingEmailService) SendTemplate(ctx context.Context, name string, to email.Recipient, data map[string]interface{}) error {
+	if f.failures > 0 {
+		f.failures--
+		return errors.New("smtp unavailable")
+	}
+	return f.mockEmailService.SendTemplate(ctx, name, to, data)
+}
+
+type digestFixture struct {
+	service     *DigestService
+	emails      *failingEmailService
+	digestRepo  *mockDigestRepo
+	workoutRepo *mockUserWorkoutRepo
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// newDigestFixture sets up two users on Wednesday 2025-11-19, so the digest
+// covers Monday 2025-11-10 to Sunday 2025-11-16. Only user 1 opted in.
+func newDigestFixture(t *testing.T) *digestFixture {
+	t.Helper()
+
+	userRepo := &mockUserRepo{users: map[int64]*domain.User{
+		1: {ID: 1, Email: "ana@example.com", Name: "Ana", Locale: "es"},
+		2: {ID: 2, Email: "bob@example.com", Name: "Bob", Locale: "en"},
+	}}
+	settingsRepo := &mockUserSettingsRepo{settings: []*domain.UserSettings{
+		{UserID: 1, NotificationPreferences: domain.NotificationPreferences{WeeklyDigest: true}, WeightUnit: "kg"},
+		{UserID: 2, NotificationPreferences: domain.DefaultNotificationPreferences(), WeightUnit: "lbs"},
+	}}
+
+	workoutRepo := newMockUserWorkoutRepo()
+	movementRepo := newMockUserWorkoutMovementRepo()
+	wodRepo := newMockUserWorkoutWODRepo()
+
+	logWorkout := func(userID int64, day time.Time) int64 {
+		uw := &domain.UserWorkout{UserID: userID, WorkoutDate: day}
+		if err := workoutRepo.Create(context.Background(), uw); err != nil {
+			t.Fatal(err)
+		}
+		return uw.ID
+	}
+
+	// Digest week: a squat PR (3x5 @ 100kg) and a Fran PR
+	squatDay := logWorkout(1, date(2025, 11, 11))
+	_ = movementRepo.Create(context.Background(), &domain.UserWorkoutMovement{
+		UserWorkoutID: squatDay, Sets: intPtr(3), Reps: intPtr(5), Weight: float64Ptr(100),
+		IsPR: true, MovementName: "Back Squat",
+	})
+	franDay := logWorkout(1, date(2025, 11, 14))
+	_ = wodRepo.Create(context.Background(), &domain.UserWorkoutWOD{
+		UserWorkoutID: franDay, ScoreValue: stringPtr("4:12"), IsPR: true, WODName: "Fran",
+	})
+
+	// History: active the two previous weeks, then a gap
+	logWorkout(1, date(2025, 11, 5))
+	logWorkout(1, date(2025, 10, 27))
+	logWorkout(1, date(2025, 10, 14))
+
+	// Scheduled: one within the next week, one beyond it
+	logWorkout(1, date(2025, 11, 21))
+	logWorkout(1, date(2025, 12, 5))
+
+	// Another user's workout must not be counted
+	logWorkout(2, date(2025, 11, 12))
+
+	emails := &failingEmailService{}
+	digestRepo := &mockDigestRepo{claims: map[string]bool{}}
+	svc := NewDigestService(userRepo, settingsRepo, workoutRepo, movementRepo, wodRepo, digestRepo, emails, "https://actalog.example.com", time.Hour)
+	svc.now = func() time.Time { return time.Date(2025, 11, 19, 10, 0, 0, 0, time.UTC) }
+
+	return &digestFixture{service: svc, emails: emails, digestRepo: digestRepo, workoutRepo: workoutRepo}
+}
+
+func TestDigestService_BuildDigest(t *testing.T) {
+	f := newDigestFixture(t)
+
+	digest, err := f.service.BuildDigest(context.Background(), 1, date(2025, 11, 12), "kg")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !digest.WeekStart.Equal(date(2025, 11, 10)) || !digest.WeekEnd.Equal(date(2025, 11, 17)) {
+		t.Errorf("unexpected week %s - %s", digest.WeekStart, digest.WeekEnd)
+	}
+	if digest.WorkoutsLogged != 2 {
+		t.Errorf("expected 2 workouts, got %d", digest.WorkoutsLogged)
+	}
+	if digest.TotalVolume != 1500 {
+		t.Errorf("expected volume 1500, got %v", digest.TotalVolume)
+	}
+	if digest.WeekStreak != 3 {
+		t.Errorf("expected 3 week streak, got %d", digest.WeekStreak)
+	}
+	if len(digest.PRs) != 2 || digest.PRs[0].Name != "Back Squat" || digest.PRs[0].Score != "100 kg" ||
+		digest.PRs[1].Name != "Fran" || digest.PRs[1].Score != "4:12" {
+		t.Errorf("unexpected PRs %+v", digest.PRs)
+	}
+	if len(digest.Upcoming) != 1 || !digest.Upcoming[0].Date.Equal(date(2025, 11, 21)) {
+		t.Errorf("unexpected upcoming sessions %+v", digest.Upcoming)
+	}
+
+	// The digest renders with the built-in templates
+	msg, err := email.DefaultRenderer().Message(email.TemplateWeeklyDigest, email.Recipient{Email: "ana@example.com", Name: "Ana"}, f.service.templateData(digest))
+	if err != nil {
+		t.Fatalf("failed to render digest: %v", err)
+	}
+	if msg.Subject != "Your ActaLog week of Nov 10: 2 workouts" {
+		t.Errorf("unexpected subject %q", msg.Subject)
+	}
+	for _, want := range []string{"1,500 kg", "Back Squat: 100 kg (Tue Nov 11)", "Fran: 4:12", "Fri Nov 21: Test Workout", "https://actalog.example.com/settings"} {
+		if !strings.Contains(msg.TextBody, want) {
+			t.Errorf("expected text body to contain %q:\n%s", want, msg.TextBody)
+		}
+	}
+}
+
+func TestDigestService_SendDueIsIdempotent(t *testing.T) {
+	f := newDigestFixture(t)
+
+	sent, err := f.service.SendDue(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sent != 1 || len(f.emails.sentEmails) != 1 {
+		t.Fatalf("expected 1 digest sent, got %d (%d emails)", sent, len(f.emails.sentEmails))
+	}
+	sentEmail := f.emails.sentEmails[0]
+	if sentEmail.to != "ana@example.com" || sentEmail.locale != "es" || sentEmail.subject != email.TemplateWeeklyDigest {
+		t.Errorf("unexpected email %+v", sentEmail)
+	}
+
+	// Running again in the same week sends nothing
+	sent, err = f.service.SendDue(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sent != 0 || len(f.emails.sentEmails) != 1 {
+		t.Errorf("expected no new digests, got %d", sent)
+	}
+
+	// The following week is a new digest
+	f.service.now = func() time.Time { return time.Date(2025, 11, 24, 9, 0, 0, 0, time.UTC) }
+	if sent, _ := f.service.SendDue(context.Background()); sent != 1 {
+		t.Errorf("expected 1 digest for the next week, got %d", sent)
+	}
+}
+
+func TestDigestService_SendFailureIsRetried(t *testing.T) {
+	f := newDigestFixture(t)
+	f.emails.failures = 1
+
+	sent, err := f.service.SendDue(context.Background())
+	if err == nil || sent != 0 {
+		t.Fatalf("expected failure, got sent=%d err=%v", sent, err)
+	}
+	if len(f.digestRepo.claims) != 0 {
+		t.Error("expected the claim to be released after a failed send")
+	}
+
+	sent, err = f.service.SendDue(context.Background())
+	if err != nil || sent != 1 {
+		t.Errorf("expected retry to send 1 digest, got sent=%d err=%v", sent, err)
+	}
+}
+
+func TestStartOfWeek(t *testing.T) {
+	tests := []struct {
+		in   time.Time
+		want time.Time
+	}{
+		{time.Date(2025, 11, 10, 0, 0, 0, 0, time.UTC), date(2025, 11, 10)},   // Monday
+		{time.Date(2025, 11, 16, 23, 59, 0, 0, time.UTC), date(2025, 11, 10)}, // Sunday
+		{time.Date(2025, 11, 19, 12, 0, 0, 0, time.UTC), date(2025, 11, 17)},  // Wednesday
+		{time.Date(2026, 1, 1, 8, 0, 0, 0, time.UTC), date(2025, 12, 29)},     // Across a year boundary
+	}
+
+	for _, tt := range tests {
+		if got := startOfWeek(tt.in); !got.Equal(tt.want) {
+			t.Errorf("startOfWeek(%s) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
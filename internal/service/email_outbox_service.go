@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/johnzastrow/actalog/internal/domain"
+	"github.com/johnzastrow/actalog/pkg/email"
+)
+
+var (
+	ErrOutboxEmailNotFound = errors.New("outbox email not found")
+	ErrOutboxEmailNotDead  = errors.New("only dead-lettered emails can be retried")
+)
+
+const outboxBatchSize = 50
+
+// EmailOutboxService queues emails in the database and delivers them from a
+// background worker, so request handlers never block on SMTP. It implements
+// email.EmailService and can be passed anywhere the SMTP service was used.
+type EmailOutboxService struct {
+	outboxRepo   domain.EmailOutboxRepository
+	sender       email.Sender
+	renderer     *email.Renderer
+	maxAttempts  int
+	baseBackoff  time.Duration
+	pollInterval time.Duration
+}
+
+// NewEmailOutboxService creates a new email outbox service
+func NewEmailOutboxService(
+	outboxRepo domain.EmailOutboxRepository,
+	sender email.Sender,
+	renderer *email.Renderer,
+	maxAttempts int,
+	baseBackoff time.Duration,
+	pollInterval time.Duration,
+) *EmailOutboxService {
+	return &EmailOutboxService{
+		outboxRepo:   outboxRepo,
+		sender:       sender,
+		renderer:     renderer,
+		maxAttempts:  maxAttempts,
+		baseBackoff:  baseBackoff,
+		pollInterval: pollInterval,
+	}
+}
+
+// Enqueue stores a message for background delivery
+func (s *EmailOutboxService) Enqueue(ctx context.Context, msg email.Message) error {
+	outboxEmail := &domain.OutboxEmail{
+		Recipients:    msg.To,
+		Subject:       msg.Subject,
+		Body:          msg.Body,
+		TextBody:      msg.TextBody,
+		IsHTML:        msg.IsHTML,
+		Status:        domain.OutboxStatusPending,
+		NextAttemptAt: time.Now(),
+	}
+	if err := s.outboxRepo.Enqueue(ctx, outboxEmail); err != nil {
+		return fmt.Errorf("failed to queue email: %w", err)
+	}
+	return nil
+}
+
+// SendPasswordResetEmail queues a password reset email
+func (s *EmailOutboxService) SendPasswordResetEmail(ctx context.Context, to email.Recipient, resetURL string) error {
+	msg, err := s.renderer.PasswordResetMessage(to, resetURL)
+	if err != nil {
+		return fmt.Errorf("failed to render password reset email: %w", err)
+	}
+	return s.Enqueue(ctx, msg)
+}
+
+// SendVerificationEmail queues an email verification email
+func (s *EmailOutboxService) SendVerificationEmail(ctx context.Context, to email.Recipient, verifyURL string) error {
+	msg, err := s.renderer.VerificationMessage(to, verifyURL)
+	if err != nil {
+		return fmt.Errorf("failed to render verification email: %w", err)
+	}
+	return s.Enqueue(ctx, msg)
+}
+
+// SendTemplate queues an email rendered from the named template
+func (s *EmailOutboxService) SendTemplate(ctx context.Context, name string, to email.Recipient, data map[string]interface{}) error {
+	msg, err := s.renderer.Message(name, to, data)
+	if err != nil {
+		return fmt.Errorf("failed to render %s email: %w", name, err)
+	}
+	return s.Enqueue(ctx, msg)
+}
+
+// Run processes the outbox every poll interval until the context is canceled
+func (s *EmailOutboxService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		_, _ = s.ProcessDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessDue attempts delivery of every pending email whose next attempt is due.
+// Failed emails are rescheduled with exponential backoff and moved to the dead
+// letter status once max attempts is reached. Returns the number sent.
+func (s *EmailOutboxService) ProcessDue(ctx context.Context) (int, error) {
+	due, err := s.outboxRepo.ListDue(ctx, time.Now(), outboxBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list due emails: %w", err)
+	}
+
+	sent := 0
+	for _, outboxEmail := range due {
+		outboxEmail.Attempts++
+
+		err := s.sender.Send(email.Message{
+			To:      outboxEmail.Recipients,
+			Subject: outboxEmail.Subject,
+			Body:    outboxEmail.Body,
+			IsHTML:  outboxEmail.IsHTML,
+		})
+
+		if err == nil {
+			now := time.Now()
+			outboxEmail.Status = domain.OutboxStatusSent
+			outboxEmail.SentAt = &now
+			outboxEmail.LastError = nil
+			sent++
+		} else {
+			msg := err.Error()
+			outboxEmail.LastError = &msg
+			if outboxEmail.Attempts >= s.maxAttempts {
+				outboxEmail.Status = domain.OutboxStatusDead
+			} else {
+				outboxEmail.NextAttemptAt = time.Now().Add(s.backoff(outboxEmail.Attempts))
+			}
+		}
+
+		// The outcome is recorded even during shutdown, so a sent email isn't sent again
+		if err := s.outboxRepo.Update(context.WithoutCancel(ctx), outboxEmail); err != nil {
+			return sent, fmt.Errorf("failed to update outbox email %d: %w", outboxEmail.ID, err)
+		}
+	}
+
+	return sent, nil
+}
+
+// backoff returns the delay before the next attempt (base * 2^(attempts-1))
+func (s *EmailOutboxService) backoff(attempts int) time.Duration {
+	return s.baseBackoff * time.Duration(1<<(attempts-1))
+}
+
+// ListByStatus lists outbox emails with the given status (for the admin view)
+func (s *EmailOutboxService) ListByStatus(ctx context.Context, status string, limit, offset int) ([]*domain.OutboxEmail, error) {
+	emails, err := s.outboxRepo.ListByStatus(ctx, status, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list outbox emails: %w", err)
+	}
+	return emails, nil
+}
+
+// Stats returns the number of outbox emails in each status
+func (s *EmailOutboxService) Stats(ctx context.Context) (map[string]int, error) {
+	counts, err := s.outboxRepo.CountByStatus(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count outbox emails: %w", err)
+	}
+	return counts, nil
+}
+
+// Retry moves a dead-lettered email back to pending with a fresh attempt budget
+func (s *EmailOutboxService) Retry(ctx context.Context, id int64) (*domain.OutboxEmail, error) {
+	outboxEmail, err := s.outboxRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get outbox email: %w", err)
+	}
+	if outboxEmail == nil {
+		return nil, ErrOutboxEmailNotFound
+	}
+	if outboxEmail.Status != domain.OutboxStatusDead {
+		return nil, ErrOutboxEmailNotDead
+	}
+
+	outboxEmail.Status = domain.OutboxStatusPending
+	outboxEmail.Attempts = 0
+	outboxEmail.NextAttemptAt = time.Now()
+
+	if err := s.outboxRepo.Update(ctx, outboxEmail); err != nil {
+		return nil, fmt.Errorf("failed to update outbox email: %w", err)
+	}
+	return outboxEmail, nil
+}
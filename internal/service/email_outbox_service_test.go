@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/johnzastrow/actalog/internal/domain"
+	"github.com/johnzastrow/actalog/internal/testhelpers"
+	"github.com/johnzastrow/actalog/pkg/email"
+)
+
+// Mock EmailOutboxRepository
+type mockEmailOutboxRepo struct {
+	emails map[int64]*domain.OutboxEmail
+	nextID int64
+}
+
+func newMockEmailOutboxRepo() *mockEmailOutboxRepo {
+	return &mockEmailOutboxRepo{
+		emails: make(map[int64]*domain.OutboxEmail),
+		nextID: 1,
+	}
+}
+
+func (m *mockEmailOutboxRepo) Enqueue(ctx context.Context, e *domain.OutboxEmail) error {
+	e.ID = m.nextID
+	m.nextID++
+	e.CreatedAt = time.Now()
+	copied := *e
+	m.emails[e.ID] = &copied
+	return nil
+}
+
+func (m *mockEmailOutboxRepo) GetByID(ctx context.Context, id int64) (*domain.OutboxEmail, error) {
+	e, ok := m.emails[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *e
+	return &copied, nil
+}
+
+func (m *mockEmailOutboxRepo) ListDue(ctx context.Context, now time.Time, limit int) ([]*domain.OutboxEmail, error) {
+	var result []*domain.OutboxEmail
+	for _, e := range m.emails {
+		if e.Status == domain.OutboxStatusPending && !e.NextAttemptAt.After(now) {
+			copied := *e
+			result = append(result, &copied)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result, nil
+}
+
+func (m *mockEmailOutboxRepo) ListByStatus(ctx context.Context, status string, limit, offset int) ([]*domain.OutboxEmail, error) {
+	var result []*domain.OutboxEmail
+	for _, e := range m.emails {
+		if e.Status == status {
+			result = append(result, e)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockEmailOutboxRepo) CountByStatus(ctx context.Context) (map[string]int, error) {
+	counts := map[string]int{}
+	for _, e := range m.emails {
+		counts[e.Status]++
+	}
+	return counts, nil
+}
+
+func (m *mockEmailOutboxRepo) Update(ctx context.Context, e *domain.OutboxEmail) error {
+	copied := *e
+	m.emails[e.ID] = &copied
+	return nil
+}
+
+func newTestOutbox(t *testing.T, maxAttempts int) (*EmailOutboxService, *mockEmailOutboxRepo, *testhelpers.FakeSMTPServer) {
+	t.Helper()
+
+	server, err := testhelpers.NewFakeSMTPServer()
+	if err != nil {
+		t.Fatalf("failed to start fake SMTP server: %v", err)
+	}
+	t.Cleanup(server.Close)
+
+	sender := email.NewService(email.Config{
+		SMTPHost:    server.Host,
+		SMTPPort:    server.Port,
+		FromAddress: "noreply@example.com",
+	}, nil, log.New(io.Discard, "", 0))
+
+	repo := newMockEmailOutboxRepo()
+	// Zero backoff so retries are immediately due
+	return NewEmailOutboxService(repo, sender, email.DefaultRenderer(), maxAttempts, 0, time.Second), repo, server
+}
+
+func TestEmailOutboxService_EnqueueDoesNotSend(t *testing.T) {
+	outbox, repo, server := newTestOutbox(t, 3)
+
+	if err := outbox.SendVerificationEmail(context.Background(), email.Recipient{Email: "athlete@example.com"}, "https://example.com/verify"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(server.Messages()) != 0 {
+		t.Error("expected enqueue not to contact SMTP")
+	}
+	if len(repo.emails) != 1 || repo.emails[1].Status != domain.OutboxStatusPending {
+		t.Fatalf("expected one pending email, got %+v", repo.emails)
+	}
+
+	sent, err := outbox.ProcessDue(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sent != 1 || len(server.Messages()) != 1 {
+		t.Errorf("expected 1 email sent, got %d (server received %d)", sent, len(server.Messages()))
+	}
+	if repo.emails[1].Status != domain.OutboxStatusSent || repo.emails[1].SentAt == nil {
+		t.Errorf("expected email to be marked sent, got %+v", repo.emails[1])
+	}
+}
+
+func TestEmailOutboxService_RetryAndDeadLetter(t *testing.T) {
+	outbox, repo, server := newTestOutbox(t, 3)
+
+	if err := outbox.SendPasswordResetEmail(context.Background(), email.Recipient{Email: "athlete@example.com"}, "https://example.com/reset"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// First attempt fails and is rescheduled
+	server.FailNext(1)
+	if sent, _ := outbox.ProcessDue(context.Background()); sent != 0 {
+		t.Fatalf("expected no emails sent, got %d", sent)
+	}
+	if e := repo.emails[1]; e.Status != domain.OutboxStatusPending || e.Attempts != 1 || e.LastError == nil {
+		t.Fatalf("expected pending email with 1 attempt and error, got %+v", e)
+	}
+
+	// Remaining attempts fail and the email is dead-lettered
+	server.FailNext(10)
+	outbox.ProcessDue(context.Background())
+	outbox.ProcessDue(context.Background())
+	if e := repo.emails[1]; e.Status != domain.OutboxStatusDead || e.Attempts != 3 {
+		t.Fatalf("expected dead email after 3 attempts, got status=%s attempts=%d", e.Status, e.Attempts)
+	}
+
+	// Dead emails are not picked up again
+	outbox.ProcessDue(context.Background())
+	if repo.emails[1].Attempts != 3 {
+		t.Error("expected dead email not to be retried automatically")
+	}
+
+	// Admin retry moves it back to pending and it is delivered
+	server.FailNext(0)
+	if _, err := outbox.Retry(context.Background(), 1); err != nil {
+		t.Fatalf("unexpected retry error: %v", err)
+	}
+	if sent, _ := outbox.ProcessDue(context.Background()); sent != 1 {
+		t.Fatalf("expected retried email to be sent, got %d", sent)
+	}
+	if repo.emails[1].Status != domain.OutboxStatusSent {
+		t.Errorf("expected email to be sent, got %s", repo.emails[1].Status)
+	}
+}
+
+func TestEmailOutboxService_Retry(t *testing.T) {
+	outbox, repo, _ := newTestOutbox(t, 3)
+	repo.Enqueue(context.Background(), &domain.OutboxEmail{Recipients: []string{"a@example.com"}, Status: domain.OutboxStatusPending})
+
+	if _, err := outbox.Retry(context.Background(), 1); !errors.Is(err, ErrOutboxEmailNotDead) {
+		t.Errorf("expected ErrOutboxEmailNotDead, got %v", err)
+	}
+	if _, err := outbox.Retry(context.Background(), 999); !errors.Is(err, ErrOutboxEmailNotFound) {
+		t.Errorf("expected ErrOutboxEmailNotFound, got %v", err)
+	}
+}
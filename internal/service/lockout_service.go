@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/johnzastrow/actalog/internal/domain"
+	"github.com/johnzastrow/actalog/pkg/email"
+)
+
+// ErrAccountLocked is matched by AccountLockedError
+var ErrAccountLocked = errors.New("account temporarily locked after too many failed logins")
+
+// AccountLockedError is returned by Login and CompleteMFALogin while an account is locked
+type AccountLockedError struct {
+	LockedUntil time.Time
+}
+
+func (e *AccountLockedError) Error() string {
+	return ErrAccountLocked.Error()
+}
+
+// Is makes errors.Is(err, ErrAccountLocked) match
+func (e *AccountLockedError) Is(target error) bool {
+	return target == ErrAccountLocked
+}
+
+// lockoutForgetAfter is how long after the last failed login an account's
+// failure count and lockout history are forgotten
+const lockoutForgetAfter = 24 * time.Hour
+
+// LockoutPolicy controls when failed password logins lock an account
+type LockoutPolicy struct {
+	Threshold   int           // Consecutive failed logins that lock the account
+	Duration    time.Duration // Length of the first lockout; each further lockout doubles it
+	MaxDuration time.Duration // Longest a single lockout can last
+}
+
+// LockoutService locks accounts after repeated failed password logins or wrong
+// two-factor codes, doubling the lockout each time it happens again. Passkey
+// and single sign-on logins aren't affected, and resetting the password
+// unlocks the account.
+type LockoutService struct {
+	lockoutRepo  domain.LoginLockoutRepository
+	userRepo     domain.UserRepository
+	emailService email.EmailService
+	appURL       string
+	policy       LockoutPolicy
+	now          func() time.Time
+}
+
+// NewLockoutService creates a new lockout service. emailService may be nil,
+// in which case users aren't notified when their account is locked.
+func NewLockoutService(
+	lockoutRepo domain.LoginLockoutRepository,
+	userRepo domain.UserRepository,
+	emailService email.EmailService,
+	appURL string,
+	policy LockoutPolicy,
+) *LockoutService {
+	return &LockoutService{
+		lockoutRepo:  lockoutRepo,
+		userRepo:     userRepo,
+		emailService: emailService,
+		appURL:       appURL,
+		policy:       policy,
+		now:          time.Now,
+	}
+}
+
+// Check returns an AccountLockedError if the user's account is locked
+func (s *LockoutService) Check(ctx context.Context, userID int64) error {
+	lockout, err := s.lockoutRepo.Get(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if lockout.Locked(s.now()) {
+		return &AccountLockedError{LockedUntil: *lockout.LockedUntil}
+	}
+	return nil
+}
+
+// RecordFailure counts a failed password login or wrong two-factor code. If it locks the account, the
+// user is emailed and an AccountLockedError is returned.
+func (s *LockoutService) RecordFailure(ctx context.Context, user *domain.User) error {
+	lockout, err := s.lockoutRepo.Get(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	now := s.now()
+	if lockout == nil || now.Sub(lockout.LastFailureAt) > lockoutForgetAfter {
+		lockout = &domain.LoginLockout{UserID: user.ID}
+	}
+	lockout.FailedAttempts++
+	lockout.LastFailureAt = now
+
+	if lockout.FailedAttempts < s.policy.Threshold {
+		return s.lockoutRepo.Save(ctx, lockout)
+	}
+
+	duration := s.lockoutDuration(lockout.Lockouts)
+	lockedUntil := now.Add(duration)
+	lockout.LockedUntil = &lockedUntil
+	lockout.Lockouts++
+	lockout.FailedAttempts = 0
+	if err := s.lockoutRepo.Save(ctx, lockout); err != nil {
+		return err
+	}
+
+	if s.emailService != nil {
+		err := s.emailService.SendTemplate(ctx, email.TemplateAccountLocked, emailRecipient(user), map[string]interface{}{
+			"Attempts": s.policy.Threshold,
+			"Minutes":  int(duration.Round(time.Minute) / time.Minute),
+			"URL":      s.appURL + "/forgot-password",
+		})
+		if err != nil {
+			// Log error but don't fail login
+			fmt.Printf("warning: failed to send account locked email: %v\n", err)
+		}
+	}
+
+	return &AccountLockedError{LockedUntil: lockedUntil}
+}
+
+// Reset clears a user's failed logins, e.g. after a successful login
+func (s *LockoutService) Reset(ctx context.Context, userID int64) error {
+	_, err := s.lockoutRepo.Delete(ctx, userID)
+	return err
+}
+
+// Unlock lets an admin unlock a user's account. Returns false if it wasn't locked.
+func (s *LockoutService) Unlock(ctx context.Context, userID int64) (bool, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return false, ErrUserNotFound
+	}
+
+	lockout, err := s.lockoutRepo.Get(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	if err := s.Reset(ctx, userID); err != nil {
+		return false, err
+	}
+	return lockout.Locked(s.now()), nil
+}
+
+// lockoutDuration returns the length of a lockout given how many came before it
+func (s *LockoutService) lockoutDuration(previous int) time.Duration {
+	duration := s.policy.Duration
+	for i := 0; i < previous && duration < s.policy.MaxDuration; i++ {
+		duration *= 2
+	}
+	if s.policy.MaxDuration > 0 && duration > s.policy.MaxDuration {
+		duration = s.policy.MaxDuration
+	}
+	return duration
+}
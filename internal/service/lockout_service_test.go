@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/johnzastrow/actalog/internal/domain"
+	"github.com/johnzastrow/actalog/pkg/email"
+)
+
+type mockLoginLockoutRepo struct {
+	lockouts map[int64]domain.LoginLockout
+}
+
+func (m *mockLoginLockoutRepo) Get(ctx context.Context, userID int64) (*domain.LoginLockout, error) {
+	lockout, ok := m.lockouts[userID]
+	if !ok {
+		return nil, nil
+	}
+	return &lockout, nil
+}
+
+func (m *mockLoginLockoutRepo) Save(ctx context.Context, lockout *domain.LoginLockout) error {
+	m.lockouts[lockout.UserID] = *lockout
+	return nil
+}
+
+func (m *mockLoginLockoutRepo) Delete(ctx context.Context, userID int64) (bool, error) {
+	_, ok := m.lockouts[userID]
+	delete(m.lockouts, userID)
+	return ok, nil
+}
+
+// newTestLockout returns a user service with lockout after 3 failures,
+// starting at 10 minutes and capped at 30, and a controllable clock
+func newTestLockout(t *testing.T) (*UserService, *LockoutService, *domain.User, *time.Time) {
+	t.Helper()
+	userService := newTestUserService(true)
+	user, _, err := userService.Register(context.Background(), "Ana", "ana@gym.example", "Password123!")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lockoutService := NewLockoutService(&mockLoginLockoutRepo{lockouts: map[int64]domain.LoginLockout{}}, userService.userRepo, userService.emailService, "http://localhost:3000", LockoutPolicy{
+		Threshold:   3,
+		Duration:    10 * time.Minute,
+		MaxDuration: 30 * time.Minute,
+	})
+	now := time.Now()
+	lockoutService.now = func() time.Time { return now }
+	userService.SetLockoutService(lockoutService)
+	return userService, lockoutService, user, &now
+}
+
+func TestLockout_Progressive(t *testing.T) {
+	userService, _, _, now := newTestLockout(t)
+	emails := userService.emailService.(*mockEmailService)
+
+	fail := func() error {
+		_, _, err := userService.Login(context.Background(), "ana@gym.example", "wrong-password")
+		return err
+	}
+
+	for _, wantMinutes := range []int{10, 20, 30, 30} {
+		for i := 0; i < 2; i++ {
+			if err := fail(); !errors.Is(err, ErrInvalidCredentials) {
+				t.Fatalf("expected ErrInvalidCredentials before the threshold, got %v", err)
+			}
+		}
+
+		var locked *AccountLockedError
+		if err := fail(); !errors.As(err, &locked) {
+			t.Fatalf("expected AccountLockedError at the threshold, got %v", err)
+		}
+		if got := locked.LockedUntil.Sub(*now); got != time.Duration(wantMinutes)*time.Minute {
+			t.Errorf("expected a %d minute lockout, got %s", wantMinutes, got)
+		}
+
+		// Even the right password is refused while locked
+		if _, _, err := userService.Login(context.Background(), "ana@gym.example", "Password123!"); !errors.Is(err, ErrAccountLocked) {
+			t.Errorf("expected ErrAccountLocked with the right password, got %v", err)
+		}
+
+		*now = locked.LockedUntil
+	}
+
+	if len(emails.sentEmails) != 4 || emails.sentEmails[0].subject != email.TemplateAccountLocked {
+		t.Errorf("expected 4 account locked emails, got %+v", emails.sentEmails)
+	}
+
+	// A successful login starts over
+	if _, _, err := userService.Login(context.Background(), "ana@gym.example", "Password123!"); err != nil {
+		t.Fatalf("expected login after the lockout expired, got %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		fail()
+	}
+	var locked *AccountLockedError
+	if err := fail(); !errors.As(err, &locked) || locked.LockedUntil.Sub(*now) != 10*time.Minute {
+		t.Errorf("expected a fresh 10 minute lockout after a successful login, got %v", err)
+	}
+}
+
+func TestLockout_FailuresAreForgotten(t *testing.T) {
+	userService, _, _, now := newTestLockout(t)
+
+	for i := 0; i < 2; i++ {
+		userService.Login(context.Background(), "ana@gym.example", "wrong-password")
+	}
+	*now = now.Add(lockoutForgetAfter + time.Minute)
+
+	if _, _, err := userService.Login(context.Background(), "ana@gym.example", "wrong-password"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("expected old failures to be forgotten, got %v", err)
+	}
+}
+
+func TestLockout_UnlockAndPasswordReset(t *testing.T) {
+	userService, lockoutService, user, _ := newTestLockout(t)
+
+	lock := func() {
+		for i := 0; i < 3; i++ {
+			userService.Login(context.Background(), "ana@gym.example", "wrong-password")
+		}
+		if err := lockoutService.Check(context.Background(), user.ID); !errors.Is(err, ErrAccountLocked) {
+			t.Fatalf("expected account to be locked, got %v", err)
+		}
+	}
+
+	lock()
+	wasLocked, err := lockoutService.Unlock(context.Background(), user.ID)
+	if err != nil || !wasLocked {
+		t.Fatalf("expected unlock of a locked account, got %t, %v", wasLocked, err)
+	}
+	if _, _, err := userService.Login(context.Background(), "ana@gym.example", "Password123!"); err != nil {
+		t.Errorf("expected login after unlock, got %v", err)
+	}
+	if wasLocked, err := lockoutService.Unlock(context.Background(), user.ID); err != nil || wasLocked {
+		t.Errorf("expected unlocking an unlocked account to report false, got %t, %v", wasLocked, err)
+	}
+	if _, err := lockoutService.Unlock(context.Background(), user.ID+100); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("expected ErrUserNotFound, got %v", err)
+	}
+
+	// Resetting the password unlocks the account
+	lock()
+	if err := userService.RequestPasswordReset(context.Background(), "ana@gym.example"); err != nil {
+		t.Fatal(err)
+	}
+	stored, _ := userService.userRepo.GetByID(context.Background(), user.ID)
+	if _, err := userService.ResetPassword(context.Background(), *stored.ResetToken, "NewPassword456!"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := userService.Login(context.Background(), "ana@gym.example", "NewPassword456!"); err != nil {
+		t.Errorf("expected login after password reset, got %v", err)
+	}
+}
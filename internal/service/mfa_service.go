@@ -0,0 +1,293 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/johnzastrow/actalog/internal/domain"
+	"github.com/johnzastrow/actalog/pkg/auth"
+)
+
+var (
+	ErrMFARequired       = errors.New("two-factor authentication required")
+	ErrMFANotEnabled     = errors.New("two-factor authentication is not enabled")
+	ErrMFAAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrMFANotEnrolled    = errors.New("no pending two-factor enrollment; start enrollment first")
+	ErrInvalidMFACode    = errors.New("invalid authentication code")
+	ErrInvalidMFAToken   = errors.New("invalid or expired MFA token")
+)
+
+const recoveryCodeCount = 10
+
+// MFARequiredError is returned by Login when the password is correct but the
+// user has two-factor authentication enabled. Token is a short-lived MFA
+// challenge token to pass to CompleteMFALogin along with a code.
+type MFARequiredError struct {
+	Token string
+}
+
+func (e *MFARequiredError) Error() string {
+	return ErrMFARequired.Error()
+}
+
+// Is makes errors.Is(err, ErrMFARequired) match
+func (e *MFARequiredError) Is(target error) bool {
+	return target == ErrMFARequired
+}
+
+// TOTPSetup is returned when enrollment starts. The provisioning URI is
+// usually shown as a QR code; the secret is for manual entry.
+type TOTPSetup struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// MFAStatus describes a user's two-factor authentication setup
+type MFAStatus struct {
+	Enabled                bool       `json:"enabled"`
+	ConfirmedAt            *time.Time `json:"confirmed_at,omitempty"`
+	RecoveryCodesRemaining int        `json:"recovery_codes_remaining"`
+}
+
+// MFAService handles TOTP two-factor enrollment and verification
+type MFAService struct {
+	mfaRepo  domain.MFARepository
+	userRepo domain.UserRepository
+	issuer   string // Shown as the account's issuer in authenticator apps
+	now      func() time.Time
+}
+
+// NewMFAService creates a new MFA service
+func NewMFAService(mfaRepo domain.MFARepository, userRepo domain.UserRepository, issuer string) *MFAService {
+	return &MFAService{
+		mfaRepo:  mfaRepo,
+		userRepo: userRepo,
+		issuer:   issuer,
+		now:      time.Now,
+	}
+}
+
+// IsEnabled reports whether a user must supply a second factor to log in
+func (s *MFAService) IsEnabled(ctx context.Context, userID int64) (bool, error) {
+	enrollment, err := s.mfaRepo.GetTOTP(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return enrollment.Enabled(), nil
+}
+
+// Status returns a user's two-factor authentication status
+func (s *MFAService) Status(ctx context.Context, userID int64) (*MFAStatus, error) {
+	enrollment, err := s.mfaRepo.GetTOTP(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !enrollment.Enabled() {
+		return &MFAStatus{}, nil
+	}
+
+	codes, err := s.mfaRepo.ListUnusedRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MFAStatus{
+		Enabled:                true,
+		ConfirmedAt:            enrollment.ConfirmedAt,
+		RecoveryCodesRemaining: len(codes),
+	}, nil
+}
+
+// BeginTOTPEnrollment generates a new secret for the user. Two-factor
+// authentication isn't enforced until ConfirmTOTPEnrollment succeeds, so
+// starting over replaces any unconfirmed enrollment.
+func (s *MFAService) BeginTOTPEnrollment(ctx context.Context, userID int64) (*TOTPSetup, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	existing, err := s.mfaRepo.GetTOTP(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if existing.Enabled() {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate TOTP secret: %w", err)
+	}
+
+	if err := s.mfaRepo.SaveTOTP(ctx, &domain.TOTPEnrollment{UserID: userID, Secret: secret}); err != nil {
+		return nil, err
+	}
+
+	return &TOTPSetup{
+		Secret:          secret,
+		ProvisioningURI: auth.TOTPProvisioningURI(s.issuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmTOTPEnrollment enables two-factor authentication once the user
+// proves their authenticator works. Returns the recovery codes, which are
+// only ever shown this once.
+func (s *MFAService) ConfirmTOTPEnrollment(ctx context.Context, userID int64, code string) ([]string, error) {
+	enrollment, err := s.mfaRepo.GetTOTP(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if enrollment == nil {
+		return nil, ErrMFANotEnrolled
+	}
+	if enrollment.Enabled() {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	ok, err := s.verifyTOTP(ctx, enrollment, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+
+	codes, err := s.newRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.mfaRepo.ConfirmTOTP(ctx, userID, s.now()); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// RegenerateRecoveryCodes replaces the user's recovery codes. Requires a
+// current TOTP code, not a recovery code.
+func (s *MFAService) RegenerateRecoveryCodes(ctx context.Context, userID int64, code string) ([]string, error) {
+	enrollment, err := s.mfaRepo.GetTOTP(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !enrollment.Enabled() {
+		return nil, ErrMFANotEnabled
+	}
+
+	ok, err := s.verifyTOTP(ctx, enrollment, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+
+	return s.newRecoveryCodes(ctx, userID)
+}
+
+// Disable turns off two-factor authentication. Requires the user's password
+// and a TOTP or recovery code.
+func (s *MFAService) Disable(ctx context.Context, userID int64, password, code string) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+	if err := auth.CheckPassword(user.PasswordHash, password); err != nil {
+		return ErrInvalidCredentials
+	}
+
+	if err := s.Verify(ctx, userID, code); err != nil {
+		return err
+	}
+
+	return s.mfaRepo.DeleteTOTP(ctx, userID)
+}
+
+// Verify checks a second factor for a user with two-factor authentication
+// enabled. The code may be a TOTP code or an unused recovery code; each code
+// is accepted only once.
+func (s *MFAService) Verify(ctx context.Context, userID int64, code string) error {
+	enrollment, err := s.mfaRepo.GetTOTP(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !enrollment.Enabled() {
+		return ErrMFANotEnabled
+	}
+
+	ok, err := s.verifyTOTP(ctx, enrollment, code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		ok, err = s.useRecoveryCode(ctx, userID, code)
+		if err != nil {
+			return err
+		}
+	}
+	if !ok {
+		return ErrInvalidMFACode
+	}
+
+	return nil
+}
+
+// verifyTOTP checks a TOTP code and records its time step so it can't be reused
+func (s *MFAService) verifyTOTP(ctx context.Context, enrollment *domain.TOTPEnrollment, code string) (bool, error) {
+	step, ok := auth.ValidateTOTP(enrollment.Secret, code, s.now())
+	if !ok {
+		return false, nil
+	}
+	return s.mfaRepo.UseTOTPStep(ctx, enrollment.UserID, step)
+}
+
+// useRecoveryCode checks a recovery code against the user's unused codes and
+// marks the match used
+func (s *MFAService) useRecoveryCode(ctx context.Context, userID int64, code string) (bool, error) {
+	code = auth.NormalizeRecoveryCode(code)
+	if len(code) != 11 {
+		return false, nil
+	}
+
+	codes, err := s.mfaRepo.ListUnusedRecoveryCodes(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	for _, candidate := range codes {
+		if auth.CheckPassword(candidate.CodeHash, code) == nil {
+			return s.mfaRepo.UseRecoveryCode(ctx, candidate.ID, s.now())
+		}
+	}
+	return false, nil
+}
+
+// newRecoveryCodes generates, hashes and stores a fresh set of recovery codes
+func (s *MFAService) newRecoveryCodes(ctx context.Context, userID int64) ([]string, error) {
+	codes, err := auth.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate recovery codes: %w", err)
+	}
+
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i], err = auth.HashPassword(code)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash recovery code: %w", err)
+		}
+	}
+
+	if err := s.mfaRepo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
@@ -0,0 +1,249 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/johnzastrow/actalog/internal/domain"
+	"github.com/johnzastrow/actalog/pkg/auth"
+)
+
+type mockMFARepo struct {
+	totp  map[int64]*domain.TOTPEnrollment
+	codes []*domain.RecoveryCode
+}
+
+func newMockMFARepo() *mockMFARepo {
+	return &mockMFARepo{totp: make(map[int64]*domain.TOTPEnrollment)}
+}
+
+func (m *mockMFARepo) GetTOTP(ctx context.Context, userID int64) (*domain.TOTPEnrollment, error) {
+	return m.totp[userID], nil
+}
+
+func (m *mockMFARepo) SaveTOTP(ctx context.Context, enrollment *domain.TOTPEnrollment) error {
+	m.totp[enrollment.UserID] = enrollment
+	return nil
+}
+
+func (m *mockMFARepo) ConfirmTOTP(ctx context.Context, userID int64, confirmedAt time.Time) error {
+	m.totp[userID].ConfirmedAt = &confirmedAt
+	return nil
+}
+
+func (m *mockMFARepo) UseTOTPStep(ctx context.Context, userID, step int64) (bool, error) {
+	enrollment := m.totp[userID]
+	if enrollment == nil || enrollment.LastUsedStep >= step {
+		return false, nil
+	}
+	enrollment.LastUsedStep = step
+	return true, nil
+}
+
+func (m *mockMFARepo) DeleteTOTP(ctx context.Context, userID int64) error {
+	delete(m.totp, userID)
+	_ = m.ReplaceRecoveryCodes(ctx, userID, nil)
+	return nil
+}
+
+func (m *mockMFARepo) ReplaceRecoveryCodes(ctx context.Context, userID int64, codeHashes []string) error {
+	kept := []*domain.RecoveryCode{}
+	for _, c := range m.codes {
+		if c.UserID != userID {
+			kept = append(kept, c)
+		}
+	}
+	for _, hash := range codeHashes {
+		kept = append(kept, &domain.RecoveryCode{ID: int64(len(kept) + 100), UserID: userID, CodeHash: hash})
+	}
+	m.codes = kept
+	return nil
+}
+
+func (m *mockMFARepo) ListUnusedRecoveryCodes(ctx context.Context, userID int64) ([]*domain.RecoveryCode, error) {
+	result := []*domain.RecoveryCode{}
+	for _, c := range m.codes {
+		if c.UserID == userID && c.UsedAt == nil {
+			result = append(result, c)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockMFARepo) UseRecoveryCode(ctx context.Context, id int64, usedAt time.Time) (bool, error) {
+	for _, c := range m.codes {
+		if c.ID == id && c.UsedAt == nil {
+			c.UsedAt = &usedAt
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// totpCodeAt returns the code for the enrollment's secret at t
+func totpCodeAt(t *testing.T, repo *mockMFARepo, userID int64, at time.Time) string {
+	t.Helper()
+	code, err := auth.TOTPCode(repo.totp[userID].Secret, auth.TOTPStep(at))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+func TestMFA_EnrollmentAndLogin(t *testing.T) {
+	userService := newTestUserService(true)
+	user, _, err := userService.Register(context.Background(), "Ana", "ana@example.com", "Password123!")
+	if err != nil {
+		t.Fatalf("failed to register: %v", err)
+	}
+
+	repo := newMockMFARepo()
+	now := time.Date(2025, 11, 19, 10, 0, 0, 0, time.UTC)
+	mfa := NewMFAService(repo, userService.userRepo, "ActaLog")
+	mfa.now = func() time.Time { return now }
+	userService.SetMFAService(mfa)
+
+	// Confirming requires an enrollment
+	if _, err := mfa.ConfirmTOTPEnrollment(context.Background(), user.ID, "123456"); !errors.Is(err, ErrMFANotEnrolled) {
+		t.Errorf("expected ErrMFANotEnrolled, got %v", err)
+	}
+
+	setup, err := mfa.BeginTOTPEnrollment(context.Background(), user.ID)
+	if err != nil {
+		t.Fatalf("failed to begin enrollment: %v", err)
+	}
+	if setup.Secret == "" || setup.ProvisioningURI != auth.TOTPProvisioningURI("ActaLog", "ana@example.com", setup.Secret) {
+		t.Errorf("unexpected setup %+v", setup)
+	}
+
+	// Not enforced until confirmed
+	if _, _, err := userService.Login(context.Background(), "ana@example.com", "Password123!"); err != nil {
+		t.Fatalf("expected login without MFA before confirmation, got %v", err)
+	}
+
+	if _, err := mfa.ConfirmTOTPEnrollment(context.Background(), user.ID, "000000"); !errors.Is(err, ErrInvalidMFACode) {
+		t.Errorf("expected ErrInvalidMFACode, got %v", err)
+	}
+	recoveryCodes, err := mfa.ConfirmTOTPEnrollment(context.Background(), user.ID, totpCodeAt(t, repo, user.ID, now))
+	if err != nil {
+		t.Fatalf("failed to confirm enrollment: %v", err)
+	}
+	if len(recoveryCodes) != recoveryCodeCount {
+		t.Errorf("expected %d recovery codes, got %d", recoveryCodeCount, len(recoveryCodes))
+	}
+	if _, err := mfa.BeginTOTPEnrollment(context.Background(), user.ID); !errors.Is(err, ErrMFAAlreadyEnabled) {
+		t.Errorf("expected ErrMFAAlreadyEnabled, got %v", err)
+	}
+
+	// The password step now returns a challenge instead of an access token
+	_, token, err := userService.Login(context.Background(), "ana@example.com", "Password123!")
+	var mfaErr *MFARequiredError
+	if !errors.As(err, &mfaErr) || !errors.Is(err, ErrMFARequired) || token != "" {
+		t.Fatalf("expected MFARequiredError, got token=%q err=%v", token, err)
+	}
+	if _, err := userService.ValidateToken(mfaErr.Token); err == nil {
+		t.Error("expected the MFA challenge token not to work as an access token")
+	}
+
+	// The code used to confirm enrollment can't be replayed
+	if _, _, err := userService.CompleteMFALogin(context.Background(), mfaErr.Token, totpCodeAt(t, repo, user.ID, now)); !errors.Is(err, ErrInvalidMFACode) {
+		t.Errorf("expected replayed code to be rejected, got %v", err)
+	}
+
+	now = now.Add(auth.TOTPPeriod)
+	loggedIn, accessToken, err := userService.CompleteMFALogin(context.Background(), mfaErr.Token, totpCodeAt(t, repo, user.ID, now))
+	if err != nil || loggedIn.ID != user.ID || accessToken == "" {
+		t.Fatalf("expected MFA login to succeed, got user=%v err=%v", loggedIn, err)
+	}
+	if _, err := userService.ValidateToken(accessToken); err != nil {
+		t.Errorf("expected a valid access token, got %v", err)
+	}
+
+	if _, _, err := userService.CompleteMFALogin(context.Background(), "not-a-token", "123456"); !errors.Is(err, ErrInvalidMFAToken) {
+		t.Errorf("expected ErrInvalidMFAToken, got %v", err)
+	}
+
+	// Recovery codes work once, with or without the dash
+	if _, _, err := userService.CompleteMFALogin(context.Background(), mfaErr.Token, recoveryCodes[0][:5]+recoveryCodes[0][6:]); err != nil {
+		t.Errorf("expected recovery code login to succeed, got %v", err)
+	}
+	if _, _, err := userService.CompleteMFALogin(context.Background(), mfaErr.Token, recoveryCodes[0]); !errors.Is(err, ErrInvalidMFACode) {
+		t.Errorf("expected a used recovery code to be rejected, got %v", err)
+	}
+
+	status, err := mfa.Status(context.Background(), user.ID)
+	if err != nil || !status.Enabled || status.RecoveryCodesRemaining != recoveryCodeCount-1 {
+		t.Errorf("unexpected status %+v (err=%v)", status, err)
+	}
+
+	// Disabling needs the password and a second factor
+	if err := mfa.Disable(context.Background(), user.ID, "wrong", recoveryCodes[1]); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("expected ErrInvalidCredentials, got %v", err)
+	}
+	if err := mfa.Disable(context.Background(), user.ID, "Password123!", recoveryCodes[1]); err != nil {
+		t.Fatalf("failed to disable MFA: %v", err)
+	}
+	if _, _, err := userService.Login(context.Background(), "ana@example.com", "Password123!"); err != nil {
+		t.Errorf("expected login without MFA after disabling, got %v", err)
+	}
+}
+
+func TestMFA_WrongCodes(t *testing.T) {
+	userService, lockoutService, user, now := newTestLockout(t)
+	repo := newMockMFARepo()
+	mfa := NewMFAService(repo, userService.userRepo, "ActaLog")
+	mfa.now = func() time.Time { return *now }
+	userService.SetMFAService(mfa)
+	if _, err := mfa.BeginTOTPEnrollment(context.Background(), user.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mfa.ConfirmTOTPEnrollment(context.Background(), user.ID, totpCodeAt(t, repo, user.ID, *now)); err != nil {
+		t.Fatal(err)
+	}
+	*now = now.Add(auth.TOTPPeriod)
+
+	challenge := func() string {
+		t.Helper()
+		var mfaErr *MFARequiredError
+		if _, _, err := userService.Login(context.Background(), "ana@gym.example", "Password123!"); !errors.As(err, &mfaErr) {
+			t.Fatalf("expected MFARequiredError, got %v", err)
+		}
+		return mfaErr.Token
+	}
+
+	// Wrong codes count toward the lockout, and logging in again with the
+	// password doesn't reset them
+	for i := 0; i < 2; i++ {
+		if _, _, err := userService.CompleteMFALogin(context.Background(), challenge(), "000000"); !errors.Is(err, ErrInvalidMFACode) {
+			t.Fatalf("expected ErrInvalidMFACode, got %v", err)
+		}
+	}
+	token := challenge()
+	var locked *AccountLockedError
+	if _, _, err := userService.CompleteMFALogin(context.Background(), token, "000000"); !errors.As(err, &locked) {
+		t.Fatalf("expected AccountLockedError at the threshold, got %v", err)
+	}
+	if _, _, err := userService.CompleteMFALogin(context.Background(), token, totpCodeAt(t, repo, user.ID, *now)); !errors.Is(err, ErrAccountLocked) {
+		t.Errorf("expected the right code to be refused while locked, got %v", err)
+	}
+
+	// Without a lockout, a challenge still only takes so many wrong codes
+	if _, err := lockoutService.Unlock(context.Background(), user.ID); err != nil {
+		t.Fatal(err)
+	}
+	userService.SetLockoutService(nil)
+	token = challenge()
+	for i := 0; i < mfaChallengeMaxFailures; i++ {
+		if _, _, err := userService.CompleteMFALogin(context.Background(), token, "000000"); !errors.Is(err, ErrInvalidMFACode) {
+			t.Fatalf("expected ErrInvalidMFACode, got %v", err)
+		}
+	}
+	if _, _, err := userService.CompleteMFALogin(context.Background(), token, totpCodeAt(t, repo, user.ID, *now)); !errors.Is(err, ErrInvalidMFAToken) {
+		t.Errorf("expected the exhausted challenge to be rejected, got %v", err)
+	}
+	if _, _, err := userService.CompleteMFALogin(context.Background(), challenge(), totpCodeAt(t, repo, user.ID, *now)); err != nil {
+		t.Errorf("expected a new challenge to work, got %v", err)
+	}
+}
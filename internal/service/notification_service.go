@@ -0,0 +1,346 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/johnzastrow/actalog/internal/domain"
+	"github.com/johnzastrow/actalog/pkg/email"
+)
+
+// ErrNotificationNotFound is returned when a notification doesn't exist or belongs to another user
+var ErrNotificationNotFound = errors.New("notification not found")
+
+const maxNotificationPageSize = 100
+
+// NotificationService delivers event notifications to users' in-app
+// notification centers and, if they opted in, by email. Each event type is
+// routed according to the user's notification preferences.
+type NotificationService struct {
+	notificationRepo domain.NotificationRepository
+	settingsRepo     domain.UserSettingsRepository
+	userRepo         domain.UserRepository
+	userWorkoutRepo  domain.UserWorkoutRepository
+	movementRepo     domain.MovementRepository
+	wodRepo          domain.WODRepository
+	emailService     email.EmailService
+	appURL           string
+	reminderInterval time.Duration
+	now              func() time.Time
+}
+
+// NewNotificationService creates a new notification service
+func NewNotificationService(
+	notificationRepo domain.NotificationRepository,
+	settingsRepo domain.UserSettingsRepository,
+	userRepo domain.UserRepository,
+	userWorkoutRepo domain.UserWorkoutRepository,
+	movementRepo domain.MovementRepository,
+	wodRepo domain.WODRepository,
+	appURL string,
+	reminderInterval time.Duration,
+) *NotificationService {
+	return &NotificationService{
+		notificationRepo: notificationRepo,
+		settingsRepo:     settingsRepo,
+		userRepo:         userRepo,
+		userWorkoutRepo:  userWorkoutRepo,
+		movementRepo:     movementRepo,
+		wodRepo:          wodRepo,
+		appURL:           appURL,
+		reminderInterval: reminderInterval,
+		now:              time.Now,
+	}
+}
+
+// SetEmailService enables email delivery for users who opted in to it
+func (s *NotificationService) SetEmailService(emailService email.EmailService) {
+	s.emailService = emailService
+}
+
+// Notify delivers a notification on the channels the user enabled for its
+// type. If the notification has a dedupe key that was already used for the
+// user, nothing is sent. Returns false if nothing was delivered.
+//
+// The notification is recorded whenever any channel is enabled; if in-app
+// delivery is off it is stored already read, so deduplication still works for
+// email-only users.
+func (s *NotificationService) Notify(ctx context.Context, notification *domain.Notification) (bool, error) {
+	settings, err := s.settings(ctx, notification.UserID)
+	if err != nil {
+		return false, err
+	}
+	return s.notify(ctx, notification, settings.NotificationPreferences)
+}
+
+func (s *NotificationService) notify(ctx context.Context, notification *domain.Notification, prefs domain.NotificationPreferences) (bool, error) {
+	inApp := prefs.Enabled(notification.Type, domain.NotificationChannelInApp)
+	byEmail := prefs.Enabled(notification.Type, domain.NotificationChannelEmail) && s.emailService != nil
+	if !inApp && !byEmail {
+		return false, nil
+	}
+
+	if notification.DedupeKey != nil {
+		existing, err := s.notificationRepo.GetByDedupeKey(ctx, notification.UserID, *notification.DedupeKey)
+		if err != nil {
+			return false, err
+		}
+		if existing != nil {
+			return false, nil
+		}
+	}
+
+	if !inApp {
+		now := s.now()
+		notification.ReadAt = &now
+	}
+	if err := s.notificationRepo.Create(ctx, notification); err != nil {
+		return false, err
+	}
+
+	if byEmail {
+		if err := s.sendEmail(ctx, notification); err != nil {
+			return true, err
+		}
+	}
+
+	return true, nil
+}
+
+// sendEmail emails a notification using the generic notification template
+func (s *NotificationService) sendEmail(ctx context.Context, notification *domain.Notification) error {
+	user, err := s.userRepo.GetByID(ctx, notification.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil
+	}
+
+	data := map[string]interface{}{
+		"Title":       notification.Title,
+		"Body":        notification.Body,
+		"SettingsURL": s.appURL + "/settings",
+	}
+	if notification.Link != nil {
+		data["URL"] = s.appURL + *notification.Link
+	}
+
+	if err := s.emailService.SendTemplate(ctx, email.TemplateNotification, emailRecipient(user), data); err != nil {
+		return fmt.Errorf("failed to send notification email: %w", err)
+	}
+	return nil
+}
+
+// settings returns the user's settings, or the defaults if none are saved
+func (s *NotificationService) settings(ctx context.Context, userID int64) (*domain.UserSettings, error) {
+	settings, err := s.settingsRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user settings: %w", err)
+	}
+	if settings == nil {
+		settings = &domain.UserSettings{
+			UserID:                  userID,
+			NotificationPreferences: domain.DefaultNotificationPreferences(),
+			WeightUnit:              "lbs",
+		}
+	}
+	return settings, nil
+}
+
+// Publish implements WorkoutEventPublisher, turning PR events into notifications
+func (s *NotificationService) Publish(ctx context.Context, userID int64, event string, data interface{}) {
+	if event != domain.WebhookEventPRSet {
+		return
+	}
+	pr, ok := data.(PREvent)
+	if !ok {
+		return
+	}
+	// The notification outlives the request that set the PR
+	_ = s.notifyPR(context.WithoutCancel(ctx), userID, pr)
+}
+
+// notifyPR notifies a user about a personal record they just set
+func (s *NotificationService) notifyPR(ctx context.Context, userID int64, pr PREvent) error {
+	settings, err := s.settings(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	var name, score, dedupeKey string
+	switch {
+	case pr.Movement != nil:
+		name, err = s.movementName(ctx, pr.Movement)
+		score = movementScore(pr.Movement, settings.WeightUnit)
+		dedupeKey = fmt.Sprintf("pr:movement:%d", pr.Movement.ID)
+	case pr.WOD != nil:
+		name, err = s.wodName(ctx, pr.WOD)
+		if pr.WOD.ScoreValue != nil {
+			score = *pr.WOD.ScoreValue
+		}
+		dedupeKey = fmt.Sprintf("pr:wod:%d", pr.WOD.ID)
+	default:
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf("You set a personal record on %s.", pr.WorkoutDate.Format("Mon Jan 2"))
+	if score != "" {
+		body = fmt.Sprintf("You set a personal record of %s on %s.", score, pr.WorkoutDate.Format("Mon Jan 2"))
+	}
+
+	link := fmt.Sprintf("/workouts/%d", pr.UserWorkoutID)
+	notification := &domain.Notification{
+		UserID: userID,
+		Type:   domain.NotificationEventPR,
+		Title:  "New PR: " + name,
+		Body:   body,
+		Link:   &link,
+	}
+	if (pr.Movement != nil && pr.Movement.ID != 0) || (pr.WOD != nil && pr.WOD.ID != 0) {
+		notification.DedupeKey = &dedupeKey
+	}
+
+	_, err = s.notify(ctx, notification, settings.NotificationPreferences)
+	return err
+}
+
+func (s *NotificationService) movementName(ctx context.Context, m *domain.UserWorkoutMovement) (string, error) {
+	if m.Movement != nil && m.Movement.Name != "" {
+		return m.Movement.Name, nil
+	}
+	if m.MovementName != "" {
+		return m.MovementName, nil
+	}
+	movement, err := s.movementRepo.GetByID(ctx, m.MovementID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get movement: %w", err)
+	}
+	if movement == nil {
+		return "Movement", nil
+	}
+	return movement.Name, nil
+}
+
+func (s *NotificationService) wodName(ctx context.Context, w *domain.UserWorkoutWOD) (string, error) {
+	if w.WOD != nil && w.WOD.Name != "" {
+		return w.WOD.Name, nil
+	}
+	if w.WODName != "" {
+		return w.WODName, nil
+	}
+	wod, err := s.wodRepo.GetByID(ctx, w.WODID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get WOD: %w", err)
+	}
+	if wod == nil {
+		return "WOD", nil
+	}
+	return wod.Name, nil
+}
+
+// Run sends workout reminders every reminder interval until the context is
+// canceled. Checking repeatedly is safe because each reminder is only sent once.
+func (s *NotificationService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.reminderInterval)
+	defer ticker.Stop()
+
+	for {
+		_, _ = s.SendWorkoutReminders(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SendWorkoutReminders reminds users of workouts scheduled for today (UTC).
+// Only workouts logged ahead of time count as scheduled; workouts logged today
+// for today have already been done. Returns the number of reminders sent.
+func (s *NotificationService) SendWorkoutReminders(ctx context.Context) (int, error) {
+	now := s.now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	workouts, err := s.userWorkoutRepo.ListByDateRange(ctx, today, today.AddDate(0, 0, 1).Add(-time.Second))
+	if err != nil {
+		return 0, fmt.Errorf("failed to list scheduled workouts: %w", err)
+	}
+
+	sent := 0
+	var errs []error
+	for _, workout := range workouts {
+		if !workout.CreatedAt.Before(today) {
+			continue
+		}
+
+		name := "your workout"
+		details, err := s.userWorkoutRepo.GetByIDWithDetails(ctx, workout.ID, workout.UserID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("workout %d: %w", workout.ID, err))
+			continue
+		}
+		if details != nil && details.WorkoutName != "" {
+			name = details.WorkoutName
+		}
+
+		link := fmt.Sprintf("/workouts/%d", workout.ID)
+		dedupeKey := fmt.Sprintf("workout_reminder:%d", workout.ID)
+		ok, err := s.Notify(ctx, &domain.Notification{
+			UserID:    workout.UserID,
+			Type:      domain.NotificationEventWorkoutReminder,
+			Title:     "Scheduled today: " + name,
+			Body:      fmt.Sprintf("You have %s scheduled for today.", name),
+			Link:      &link,
+			DedupeKey: &dedupeKey,
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("workout %d: %w", workout.ID, err))
+			continue
+		}
+		if ok {
+			sent++
+		}
+	}
+
+	return sent, errors.Join(errs...)
+}
+
+// List retrieves a user's notifications, newest first
+func (s *NotificationService) List(ctx context.Context, userID int64, unreadOnly bool, limit, offset int) ([]*domain.Notification, error) {
+	if limit <= 0 || limit > maxNotificationPageSize {
+		limit = maxNotificationPageSize
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return s.notificationRepo.ListByUser(ctx, userID, unreadOnly, limit, offset)
+}
+
+// UnreadCount counts a user's unread notifications
+func (s *NotificationService) UnreadCount(ctx context.Context, userID int64) (int, error) {
+	return s.notificationRepo.CountUnread(ctx, userID)
+}
+
+// MarkRead marks one of the user's notifications read
+func (s *NotificationService) MarkRead(ctx context.Context, id, userID int64) error {
+	found, err := s.notificationRepo.MarkRead(ctx, id, userID, s.now())
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrNotificationNotFound
+	}
+	return nil
+}
+
+// MarkAllRead marks all of the user's notifications read and returns how many were unread
+func (s *NotificationService) MarkAllRead(ctx context.Context, userID int64) (int64, error) {
+	return s.notificationRepo.MarkAllRead(ctx, userID, s.now())
+}
@@ -0,0 +1,358 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/johnzastrow/actalog/internal/domain"
+	"github.com/johnzastrow/actalog/pkg/email"
+)
+
+type mockNotificationRepo struct {
+	notifications []*domain.Notification
+}
+
+func (m *mockNotificationRepo) Create(ctx context.Context, notification *domain.Notification) error {
+	notification.ID = int64(len(m.notifications) + 1)
+	notification.CreatedAt = time.Now()
+	m.notifications = append(m.notifications, notification)
+	return nil
+}
+
+func (m *mockNotificationRepo) GetByDedupeKey(ctx context.Context, userID int64, dedupeKey string) (*domain.Notification, error) {
+	for _, n := range m.notifications {
+		if n.UserID == userID && n.DedupeKey != nil && *n.DedupeKey == dedupeKey {
+			return n, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockNotificationRepo) ListByUser(ctx context.Context, userID int64, unreadOnly bool, limit, offset int) ([]*domain.Notification, error) {
+	result := []*domain.Notification{}
+	for i := len(m.notifications) - 1; i >= 0; i-- {
+		n := m.notifications[i]
+		if n.UserID == userID && (!unreadOnly || n.ReadAt == nil) {
+			result = append(result, n)
+		}
+	}
+	if offset >= len(result) {
+		return []*domain.Notification{}, nil
+	}
+	result = result[offset:]
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (m *mockNotificationRepo) CountUnread(ctx context.Context, userID int64) (int, error) {
+	count := 0
+	for _, n := range m.notifications {
+		if n.UserID == userID && n.ReadAt == nil {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *mockNotificationRepo) MarkRead(ctx context.Context, id, userID int64, at time.Time) (bool, error) {
+	for _, n := range m.notifications {
+		if n.ID == id && n.UserID == userID {
+			if n.ReadAt == nil {
+				n.ReadAt = &at
+			}
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *mockNotificationRepo) MarkAllRead(ctx context.Context, userID int64, at time.Time) (int64, error) {
+	var count int64
+	for _, n := range m.notifications {
+		if n.UserID == userID && n.ReadAt == nil {
+			n.ReadAt = &at
+			count++
+		}
+	}
+	return count, nil
+}
+
+type mockMovementRepo struct {
+	movements map[int64]*domain.Movement
+}
+
+func (m *mockMovementRepo) Create(ctx context.Context, movement *domain.Movement) error { return nil }
+func (m *mockMovementRepo) GetByID(ctx context.Context, id int64) (*domain.Movement, error) {
+	return m.movements[id], nil
+}
+func (m *mockMovementRepo) GetByName(ctx context.Context, name string) (*domain.Movement, error) {
+	return nil, nil
+}
+func (m *mockMovementRepo) ListAll(ctx context.Context) ([]*domain.Movement, error) { return nil, nil }
+func (m *mockMovementRepo) ListStandard(ctx context.Context) ([]*domain.Movement, error) {
+	return nil, nil
+}
+func (m *mockMovementRepo) ListByUser(ctx context.Context, userID int64) ([]*domain.Movement, error) {
+	return nil, nil
+}
+func (m *mockMovementRepo) Update(ctx context.Context, movement *domain.Movement) error { return nil }
+func (m *mockMovementRepo) Delete(ctx context.Context, id int64) error                  { return nil }
+func (m *mockMovementRepo) Search(ctx context.Context, query string, limit int) ([]*domain.Movement, error) {
+	return nil, nil
+}
+
+type notificationFixture struct {
+	service     *NotificationService
+	repo        *mockNotificationRepo
+	emails      *mockEmailService
+	workoutRepo *mockUserWorkoutRepo
+}
+
+// newNotificationFixture sets up user 1 with default preferences (in-app only)
+// and user 2 with PRs by email only
+func newNotificationFixture(t *testing.T) *notificationFixture {
+	t.Helper()
+
+	userRepo := &mockUserRepo{users: map[int64]*domain.User{
+		1: {ID: 1, Email: "ana@example.com", Name: "Ana", Locale: "es"},
+		2: {ID: 2, Email: "bob@example.com", Name: "Bob", Locale: "en"},
+	}}
+
+	emailOnly := domain.DefaultNotificationPreferences()
+	emailOnly.PR = domain.NotificationChannels{Email: true}
+	settingsRepo := &mockUserSettingsRepo{settings: []*domain.UserSettings{
+		{UserID: 2, NotificationPreferences: emailOnly, WeightUnit: "kg"},
+	}}
+
+	repo := &mockNotificationRepo{}
+	emails := &mockEmailService{}
+	workoutRepo := newMockUserWorkoutRepo()
+	movementRepo := &mockMovementRepo{movements: map[int64]*domain.Movement{
+		7: {ID: 7, Name: "Deadlift"},
+	}}
+
+	svc := NewNotificationService(repo, settingsRepo, userRepo, workoutRepo, movementRepo, newMockWODRepo(), "https://actalog.example.com", time.Minute)
+	svc.SetEmailService(emails)
+	svc.now = func() time.Time { return time.Date(2025, 11, 19, 10, 0, 0, 0, time.UTC) }
+
+	return &notificationFixture{service: svc, repo: repo, emails: emails, workoutRepo: workoutRepo}
+}
+
+func TestNotificationPreferences_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    domain.NotificationPreferences
+		wantErr bool
+	}{
+		{"empty object keeps defaults", `{}`, domain.DefaultNotificationPreferences(), false},
+		{"null keeps defaults", `null`, domain.DefaultNotificationPreferences(), false},
+		{
+			"partial channels merge with defaults",
+			`{"pr": {"email": true}, "weekly_digest": true}`,
+			domain.NotificationPreferences{
+				PR:              domain.NotificationChannels{InApp: true, Email: true},
+				CoachComment:    domain.NotificationChannels{InApp: true},
+				WorkoutReminder: domain.NotificationChannels{InApp: true},
+				WeeklyDigest:    true,
+			},
+			false,
+		},
+		{
+			"legacy string encoding",
+			`"{\"workout_reminder\": {\"in_app\": false}}"`,
+			domain.NotificationPreferences{
+				PR:           domain.NotificationChannels{InApp: true},
+				CoachComment: domain.NotificationChannels{InApp: true},
+			},
+			false,
+		},
+		{"unknown event", `{"comments": {"in_app": true}}`, domain.NotificationPreferences{}, true},
+		{"unknown channel", `{"pr": {"sms": true}}`, domain.NotificationPreferences{}, true},
+		{"wrong type", `{"pr": true}`, domain.NotificationPreferences{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got domain.NotificationPreferences
+			err := json.Unmarshal([]byte(tt.input), &got)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotificationService_PRNotification(t *testing.T) {
+	f := newNotificationFixture(t)
+	day := date(2025, 11, 18)
+
+	f.service.Publish(context.Background(), 1, domain.WebhookEventPRSet, PREvent{
+		UserWorkoutID: 12,
+		WorkoutDate:   day,
+		Movement:      &domain.UserWorkoutMovement{ID: 3, MovementID: 7, Weight: float64Ptr(225)},
+	})
+	f.service.Publish(context.Background(), 1, domain.WebhookEventPRSet, PREvent{
+		UserWorkoutID: 12,
+		WorkoutDate:   day,
+		WOD:           &domain.UserWorkoutWOD{ID: 4, WODName: "Fran", ScoreValue: stringPtr("4:12")},
+	})
+	// Other events and repeated PR events are ignored
+	f.service.Publish(context.Background(), 1, domain.WebhookEventWorkoutLogged, WorkoutEvent{})
+	f.service.Publish(context.Background(), 1, domain.WebhookEventPRSet, PREvent{
+		UserWorkoutID: 12,
+		WorkoutDate:   day,
+		Movement:      &domain.UserWorkoutMovement{ID: 3, MovementID: 7, Weight: float64Ptr(225)},
+	})
+
+	notifications, err := f.service.List(context.Background(), 1, false, 10, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(notifications) != 2 {
+		t.Fatalf("expected 2 notifications, got %d", len(notifications))
+	}
+	fran, deadlift := notifications[0], notifications[1]
+	if deadlift.Type != domain.NotificationEventPR || deadlift.Title != "New PR: Deadlift" ||
+		deadlift.Body != "You set a personal record of 225 lbs on Tue Nov 18." || *deadlift.Link != "/workouts/12" {
+		t.Errorf("unexpected notification %+v", deadlift)
+	}
+	if fran.Title != "New PR: Fran" || fran.Body != "You set a personal record of 4:12 on Tue Nov 18." {
+		t.Errorf("unexpected notification %+v", fran)
+	}
+
+	// Email is off by default
+	if len(f.emails.sentEmails) != 0 {
+		t.Errorf("expected no emails, got %d", len(f.emails.sentEmails))
+	}
+}
+
+func TestNotificationService_EmailOnly(t *testing.T) {
+	f := newNotificationFixture(t)
+
+	f.service.Publish(context.Background(), 2, domain.WebhookEventPRSet, PREvent{
+		UserWorkoutID: 5,
+		WorkoutDate:   date(2025, 11, 18),
+		Movement:      &domain.UserWorkoutMovement{ID: 9, MovementName: "Back Squat", Weight: float64Ptr(140)},
+	})
+
+	if len(f.emails.sentEmails) != 1 {
+		t.Fatalf("expected 1 email, got %d", len(f.emails.sentEmails))
+	}
+	sent := f.emails.sentEmails[0]
+	if sent.to != "bob@example.com" || sent.subject != email.TemplateNotification {
+		t.Errorf("unexpected email %+v", sent)
+	}
+
+	// Recorded for deduplication, but not shown as unread
+	if count, _ := f.service.UnreadCount(context.Background(), 2); count != 0 {
+		t.Errorf("expected no unread notifications, got %d", count)
+	}
+	if len(f.repo.notifications) != 1 || f.repo.notifications[0].ReadAt == nil {
+		t.Errorf("expected one read notification, got %+v", f.repo.notifications)
+	}
+
+	// Workout reminders are still in-app only for this user
+	link := "/workouts/5"
+	if ok, err := f.service.Notify(context.Background(), &domain.Notification{UserID: 2, Type: domain.NotificationEventWorkoutReminder, Title: "Reminder", Link: &link}); !ok || err != nil {
+		t.Fatalf("expected reminder to be delivered, got ok=%v err=%v", ok, err)
+	}
+	if len(f.emails.sentEmails) != 1 {
+		t.Errorf("expected reminder not to be emailed")
+	}
+}
+
+func TestNotificationService_DisabledEvent(t *testing.T) {
+	f := newNotificationFixture(t)
+
+	prefs := domain.DefaultNotificationPreferences()
+	prefs.CoachComment = domain.NotificationChannels{}
+	f.service.settingsRepo = &mockUserSettingsRepo{settings: []*domain.UserSettings{{UserID: 1, NotificationPreferences: prefs}}}
+
+	ok, err := f.service.Notify(context.Background(), &domain.Notification{UserID: 1, Type: domain.NotificationEventCoachComment, Title: "Coach commented"})
+	if err != nil || ok {
+		t.Errorf("expected disabled event to be skipped, got ok=%v err=%v", ok, err)
+	}
+	if len(f.repo.notifications) != 0 {
+		t.Errorf("expected no notifications, got %d", len(f.repo.notifications))
+	}
+}
+
+func TestNotificationService_SendWorkoutReminders(t *testing.T) {
+	f := newNotificationFixture(t)
+	today := date(2025, 11, 19)
+
+	schedule := func(userID int64, day, createdAt time.Time) {
+		uw := &domain.UserWorkout{UserID: userID, WorkoutDate: day}
+		if err := f.workoutRepo.Create(context.Background(), uw); err != nil {
+			t.Fatal(err)
+		}
+		uw.CreatedAt = createdAt
+	}
+	schedule(1, today, today.AddDate(0, 0, -2))                  // Scheduled in advance
+	schedule(1, today, today.Add(8*time.Hour))                   // Logged today, already done
+	schedule(1, today.AddDate(0, 0, 1), today.AddDate(0, 0, -1)) // Tomorrow
+
+	sent, err := f.service.SendWorkoutReminders(context.Background())
+	if err != nil || sent != 1 {
+		t.Fatalf("expected 1 reminder, got sent=%d err=%v", sent, err)
+	}
+	n := f.repo.notifications[0]
+	if n.Type != domain.NotificationEventWorkoutReminder || n.Title != "Scheduled today: Test Workout" {
+		t.Errorf("unexpected reminder %+v", n)
+	}
+
+	// Each workout is only reminded once
+	if sent, _ := f.service.SendWorkoutReminders(context.Background()); sent != 0 {
+		t.Errorf("expected no new reminders, got %d", sent)
+	}
+}
+
+func TestNotificationService_MarkRead(t *testing.T) {
+	f := newNotificationFixture(t)
+	for _, title := range []string{"one", "two", "three"} {
+		if _, err := f.service.Notify(context.Background(), &domain.Notification{UserID: 1, Type: domain.NotificationEventPR, Title: title}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := f.service.MarkRead(context.Background(), 1, 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := f.service.MarkRead(context.Background(), 1, 2); !errors.Is(err, ErrNotificationNotFound) {
+		t.Errorf("expected ErrNotificationNotFound for another user's notification, got %v", err)
+	}
+
+	unread, _ := f.service.List(context.Background(), 1, true, 10, 0)
+	titles := []string{}
+	for _, n := range unread {
+		titles = append(titles, n.Title)
+	}
+	sort.Strings(titles)
+	if len(titles) != 2 || titles[0] != "three" || titles[1] != "two" {
+		t.Errorf("unexpected unread notifications %v", titles)
+	}
+
+	count, err := f.service.MarkAllRead(context.Background(), 1)
+	if err != nil || count != 2 {
+		t.Errorf("expected 2 marked read, got %d (err=%v)", count, err)
+	}
+	if unread, _ := f.service.UnreadCount(context.Background(), 1); unread != 0 {
+		t.Errorf("expected no unread notifications, got %d", unread)
+	}
+}
@@ -0,0 +1,238 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/johnzastrow/actalog/internal/domain"
+	"github.com/johnzastrow/actalog/pkg/auth"
+	"github.com/johnzastrow/actalog/pkg/oidc"
+)
+
+var (
+	ErrOIDCUnavailable      = errors.New("single sign-on is not configured")
+	ErrInvalidOIDCState     = errors.New("invalid or expired single sign-on state; start again")
+	ErrOIDCLoginFailed      = errors.New("single sign-on failed")
+	ErrOIDCEmailNotVerified = errors.New("the identity provider has not verified this email address")
+	ErrOIDCDomainNotAllowed = errors.New("this email domain is not allowed to sign in")
+)
+
+const oidcLoginStateExpiration = 10 * time.Minute
+
+// OIDCService handles OpenID Connect single sign-on. Provider accounts are
+// linked to users by verified email the first time they log in; users who
+// don't exist yet are created if registration is open.
+type OIDCService struct {
+	provider          *oidc.Provider
+	oidcRepo          domain.OIDCRepository
+	userRepo          domain.UserRepository
+	allowRegistration bool
+	allowedDomains    []string // Empty allows any email domain
+	now               func() time.Time
+}
+
+// NewOIDCService creates a new OIDC service
+func NewOIDCService(
+	provider *oidc.Provider,
+	oidcRepo domain.OIDCRepository,
+	userRepo domain.UserRepository,
+	allowRegistration bool,
+	allowedDomains []string,
+) *OIDCService {
+	return &OIDCService{
+		provider:          provider,
+		oidcRepo:          oidcRepo,
+		userRepo:          userRepo,
+		allowRegistration: allowRegistration,
+		allowedDomains:    allowedDomains,
+		now:               time.Now,
+	}
+}
+
+// BeginLogin starts a login and returns the provider URL to send the user to.
+// The provider redirects back with a code and state for Authenticate.
+func (s *OIDCService) BeginLogin(ctx context.Context) (string, error) {
+	now := s.now()
+	if err := s.oidcRepo.DeleteExpiredLoginStates(ctx, now); err != nil {
+		return "", err
+	}
+
+	state := &domain.OIDCLoginState{ExpiresAt: now.Add(oidcLoginStateExpiration)}
+	for _, v := range []*string{&state.State, &state.Nonce, &state.CodeVerifier} {
+		value, err := oidc.RandomString()
+		if err != nil {
+			return "", fmt.Errorf("failed to generate login state: %w", err)
+		}
+		*v = value
+	}
+
+	authURL, err := s.provider.AuthCodeURL(ctx, state.State, state.Nonce, state.CodeVerifier)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrOIDCLoginFailed, err)
+	}
+
+	if err := s.oidcRepo.CreateLoginState(ctx, state); err != nil {
+		return "", err
+	}
+	return authURL, nil
+}
+
+// Authenticate completes a login with the code and state from the provider's
+// redirect and returns the linked, matched or newly created user
+func (s *OIDCService) Authenticate(ctx context.Context, code, stateValue string) (*domain.User, error) {
+	state, err := s.oidcRepo.ConsumeLoginState(ctx, stateValue, s.now())
+	if err != nil {
+		return nil, err
+	}
+	if state == nil {
+		return nil, ErrInvalidOIDCState
+	}
+
+	tokens, err := s.provider.Exchange(ctx, code, state.CodeVerifier)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOIDCLoginFailed, err)
+	}
+	claims, err := s.provider.VerifyIDToken(ctx, tokens.IDToken, state.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOIDCLoginFailed, err)
+	}
+
+	if !s.domainAllowed(claims.Email) {
+		return nil, ErrOIDCDomainNotAllowed
+	}
+
+	identity, err := s.oidcRepo.GetIdentity(ctx, claims.Issuer, claims.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if identity != nil {
+		user, err := s.userRepo.GetByID(ctx, identity.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user: %w", err)
+		}
+		if user == nil {
+			return nil, ErrOIDCLoginFailed
+		}
+		if err := s.oidcRepo.RecordIdentityLogin(ctx, identity.ID, s.now()); err != nil {
+			return nil, err
+		}
+		return user, nil
+	}
+
+	// First login with this provider account: link by verified email
+	if claims.Email == "" || !claims.EmailVerified {
+		return nil, ErrOIDCEmailNotVerified
+	}
+
+	user, err := s.userRepo.GetByEmail(ctx, claims.Email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		user, err = s.createUser(ctx, claims)
+		if err != nil {
+			return nil, err
+		}
+	} else if !user.EmailVerified {
+		// The provider has proven ownership of the address
+		verifiedAt := s.now()
+		user.EmailVerified = true
+		user.EmailVerifiedAt = &verifiedAt
+		if err := s.userRepo.Update(ctx, user); err != nil {
+			return nil, fmt.Errorf("failed to update user: %w", err)
+		}
+	}
+
+	loginAt := s.now()
+	identity = &domain.UserIdentity{
+		UserID:      user.ID,
+		Issuer:      claims.Issuer,
+		Subject:     claims.Subject,
+		Email:       claims.Email,
+		LastLoginAt: &loginAt,
+	}
+	if err := s.oidcRepo.CreateIdentity(ctx, identity); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// ListIdentities returns the provider accounts linked to a user
+func (s *OIDCService) ListIdentities(ctx context.Context, userID int64) ([]*domain.UserIdentity, error) {
+	return s.oidcRepo.ListIdentitiesByUser(ctx, userID)
+}
+
+// createUser registers a user for a provider account. Like Register, the
+// first user becomes admin and later users need registration to be open.
+// The random password can't be used; the user can set one with a password reset.
+func (s *OIDCService) createUser(ctx context.Context, claims *oidc.Claims) (*domain.User, error) {
+	count, err := s.userRepo.Count(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count users: %w", err)
+	}
+	if count > 0 && !s.allowRegistration {
+		return nil, ErrRegistrationClosed
+	}
+
+	password, err := generateVerificationToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate password: %w", err)
+	}
+	hashedPassword, err := auth.HashPassword(password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	name := strings.TrimSpace(claims.Name)
+	if name == "" {
+		name = strings.SplitN(claims.Email, "@", 2)[0]
+	}
+
+	now := s.now()
+	user := &domain.User{
+		Email:        claims.Email,
+		PasswordHash: hashedPassword,
+		Name:         name,
+		Role:         "user",
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if count == 0 {
+		user.Role = "admin"
+	}
+
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	user.EmailVerified = true
+	user.EmailVerifiedAt = &now
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+
+	return user, nil
+}
+
+// domainAllowed reports whether an email's domain may sign in
+func (s *OIDCService) domainAllowed(email string) bool {
+	if len(s.allowedDomains) == 0 {
+		return true
+	}
+
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+	domainPart := email[at+1:]
+	for _, allowed := range s.allowedDomains {
+		if strings.EqualFold(domainPart, strings.TrimSpace(allowed)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/johnzastrow/actalog/internal/domain"
+	"github.com/johnzastrow/actalog/internal/testhelpers"
+	"github.com/johnzastrow/actalog/pkg/oidc"
+)
+
+type mockOIDCRepo struct {
+	identities []*domain.UserIdentity
+	states     map[string]*domain.OIDCLoginState
+}
+
+func newMockOIDCRepo() *mockOIDCRepo {
+	return &mockOIDCRepo{states: make(map[string]*domain.OIDCLoginState)}
+}
+
+func (m *mockOIDCRepo) GetIdentity(ctx context.Context, issuer, subject string) (*domain.UserIdentity, error) {
+	for _, i := range m.identities {
+		if i.Issuer == issuer && i.Subject == subject {
+			return i, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockOIDCRepo) CreateIdentity(ctx context.Context, identity *domain.UserIdentity) error {
+	identity.ID = int64(len(m.identities) + 1)
+	m.identities = append(m.identities, identity)
+	return nil
+}
+
+func (m *mockOIDCRepo) ListIdentitiesByUser(ctx context.Context, userID int64) ([]*domain.UserIdentity, error) {
+	result := []*domain.UserIdentity{}
+	for _, i := range m.identities {
+		if i.UserID == userID {
+			result = append(result, i)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockOIDCRepo) RecordIdentityLogin(ctx context.Context, id int64, at time.Time) error {
+	for _, i := range m.identities {
+		if i.ID == id {
+			i.LastLoginAt = &at
+		}
+	}
+	return nil
+}
+
+func (m *mockOIDCRepo) CreateLoginState(ctx context.Context, state *domain.OIDCLoginState) error {
+	m.states[state.State] = state
+	return nil
+}
+
+func (m *mockOIDCRepo) ConsumeLoginState(ctx context.Context, state string, now time.Time) (*domain.OIDCLoginState, error) {
+	s, ok := m.states[state]
+	delete(m.states, state)
+	if !ok || !s.ExpiresAt.After(now) {
+		return nil, nil
+	}
+	return s, nil
+}
+
+func (m *mockOIDCRepo) DeleteExpiredLoginStates(ctx context.Context, before time.Time) error {
+	for k, s := range m.states {
+		if s.ExpiresAt.Before(before) {
+			delete(m.states, k)
+		}
+	}
+	return nil
+}
+
+func newTestOIDC(t *testing.T, userService *UserService, allowedDomains []string) (*testhelpers.MockOIDCProvider, *mockOIDCRepo) {
+	t.Helper()
+	mock, err := testhelpers.NewMockOIDCProvider("actalog", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(mock.Close)
+
+	provider := oidc.NewProvider(oidc.Config{
+		IssuerURL:    mock.URL,
+		ClientID:     "actalog",
+		ClientSecret: "secret",
+		RedirectURL:  "http://localhost:3000/auth/oidc/callback",
+	}, nil)
+	repo := newMockOIDCRepo()
+	userService.SetOIDCService(NewOIDCService(provider, repo, userService.userRepo, userService.allowRegistration, allowedDomains))
+	return mock, repo
+}
+
+// oidcLogin runs the browser side of the flow and completes the login
+func oidcLogin(t *testing.T, userService *UserService, mock *testhelpers.MockOIDCProvider) (*domain.User, string, error) {
+	t.Helper()
+	authURL, err := userService.BeginOIDCLogin(context.Background())
+	if err != nil {
+		t.Fatalf("failed to begin login: %v", err)
+	}
+	code, state, err := mock.Authorize(authURL)
+	if err != nil {
+		t.Fatalf("provider rejected authorization: %v", err)
+	}
+	return userService.LoginWithOIDC(context.Background(), code, state)
+}
+
+func TestOIDC_CreatesThenLinksByVerifiedEmail(t *testing.T) {
+	userService := newTestUserService(true)
+	existing, _, err := userService.Register(context.Background(), "Ana", "ana@gym.example", "Password123!")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mock, repo := newTestOIDC(t, userService, nil)
+
+	// An existing account is linked by its verified email
+	mock.SetUser(testhelpers.MockOIDCUser{Subject: "google-1", Email: "ana@gym.example", EmailVerified: true, Name: "Ana G"})
+	user, token, err := oidcLogin(t, userService, mock)
+	if err != nil || user.ID != existing.ID || token == "" {
+		t.Fatalf("expected login as the existing user, got user=%v err=%v", user, err)
+	}
+	if identities, _ := userService.ListIdentities(context.Background(), existing.ID); len(identities) != 1 || identities[0].Subject != "google-1" || identities[0].Issuer != mock.URL {
+		t.Errorf("expected one linked identity, got %+v", identities)
+	}
+
+	// Later logins use the link even if the provider's email changes
+	mock.SetUser(testhelpers.MockOIDCUser{Subject: "google-1", Email: "ana.g@gym.example", EmailVerified: true})
+	if user, _, err := oidcLogin(t, userService, mock); err != nil || user.ID != existing.ID {
+		t.Errorf("expected linked login, got user=%v err=%v", user, err)
+	}
+
+	// A new member gets an account, verified by the provider
+	mock.SetUser(testhelpers.MockOIDCUser{Subject: "google-2", Email: "ben@gym.example", EmailVerified: true, Name: "Ben"})
+	user, _, err = oidcLogin(t, userService, mock)
+	if err != nil {
+		t.Fatalf("expected account creation, got %v", err)
+	}
+	if user.Email != "ben@gym.example" || user.Name != "Ben" || user.Role != "user" || !user.EmailVerified {
+		t.Errorf("unexpected created user %+v", user)
+	}
+	if len(repo.identities) != 2 {
+		t.Errorf("expected 2 identities, got %d", len(repo.identities))
+	}
+}
+
+func TestOIDC_Rejections(t *testing.T) {
+	userService := newTestUserService(true)
+	if _, _, err := userService.Register(context.Background(), "Ana", "ana@gym.example", "Password123!"); err != nil {
+		t.Fatal(err)
+	}
+	mock, _ := newTestOIDC(t, userService, []string{"gym.example"})
+
+	// Unverified emails can't be used to link or create accounts
+	mock.SetUser(testhelpers.MockOIDCUser{Subject: "evil", Email: "ana@gym.example", EmailVerified: false})
+	if _, _, err := oidcLogin(t, userService, mock); !errors.Is(err, ErrOIDCEmailNotVerified) {
+		t.Errorf("expected ErrOIDCEmailNotVerified, got %v", err)
+	}
+
+	mock.SetUser(testhelpers.MockOIDCUser{Subject: "outsider", Email: "eve@elsewhere.example", EmailVerified: true})
+	if _, _, err := oidcLogin(t, userService, mock); !errors.Is(err, ErrOIDCDomainNotAllowed) {
+		t.Errorf("expected ErrOIDCDomainNotAllowed, got %v", err)
+	}
+
+	// States are single use
+	mock.SetUser(testhelpers.MockOIDCUser{Subject: "google-1", Email: "ana@gym.example", EmailVerified: true})
+	authURL, _ := userService.BeginOIDCLogin(context.Background())
+	code, state, _ := mock.Authorize(authURL)
+	if _, _, err := userService.LoginWithOIDC(context.Background(), code, state); err != nil {
+		t.Fatalf("expected login to succeed, got %v", err)
+	}
+	if _, _, err := userService.LoginWithOIDC(context.Background(), code, state); !errors.Is(err, ErrInvalidOIDCState) {
+		t.Errorf("expected ErrInvalidOIDCState on reuse, got %v", err)
+	}
+
+	// A code can't be redeemed under another login's state (PKCE/nonce binding)
+	first, _ := userService.BeginOIDCLogin(context.Background())
+	second, _ := userService.BeginOIDCLogin(context.Background())
+	code, _, _ = mock.Authorize(first)
+	_, otherState, _ := mock.Authorize(second)
+	if _, _, err := userService.LoginWithOIDC(context.Background(), code, otherState); !errors.Is(err, ErrOIDCLoginFailed) {
+		t.Errorf("expected ErrOIDCLoginFailed, got %v", err)
+	}
+
+	// Closed registration blocks new accounts but not existing ones
+	closed := newTestUserService(false)
+	if _, _, err := closed.Register(context.Background(), "Admin", "admin@gym.example", "Password123!"); err != nil {
+		t.Fatal(err)
+	}
+	mock, _ = newTestOIDC(t, closed, nil)
+	mock.SetUser(testhelpers.MockOIDCUser{Subject: "new", Email: "new@gym.example", EmailVerified: true})
+	if _, _, err := oidcLogin(t, closed, mock); !errors.Is(err, ErrRegistrationClosed) {
+		t.Errorf("expected ErrRegistrationClosed, got %v", err)
+	}
+	mock.SetUser(testhelpers.MockOIDCUser{Subject: "admin", Email: "admin@gym.example", EmailVerified: true})
+	if _, _, err := oidcLogin(t, closed, mock); err != nil {
+		t.Errorf("expected an existing user to log in with registration closed, got %v", err)
+	}
+}
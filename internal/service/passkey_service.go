@@ -0,0 +1,324 @@
+package service
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/johnzastrow/actalog/internal/domain"
+	"github.com/johnzastrow/actalog/pkg/webauthn"
+)
+
+var (
+	ErrPasskeyNotFound          = errors.New("passkey not found")
+	ErrPasskeyAlreadyRegistered = errors.New("this passkey is already registered")
+	ErrInvalidPasskey           = errors.New("passkey verification failed")
+	ErrInvalidPasskeyChallenge  = errors.New("invalid or expired passkey challenge; start again")
+	ErrInvalidPasskeyName       = errors.New("passkey name must be at most 100 characters")
+	ErrPasskeysUnavailable      = errors.New("passkey login is not available")
+)
+
+const (
+	passkeyChallengeExpiration = 5 * time.Minute
+	maxPasskeyNameLength       = 100
+	defaultPasskeyName         = "Passkey"
+)
+
+// PasskeyService handles WebAuthn passkey registration and login
+type PasskeyService struct {
+	passkeyRepo domain.PasskeyRepository
+	userRepo    domain.UserRepository
+	rp          webauthn.RelyingParty
+	now         func() time.Time
+}
+
+// NewPasskeyService creates a new passkey service
+func NewPasskeyService(passkeyRepo domain.PasskeyRepository, userRepo domain.UserRepository, rp webauthn.RelyingParty) *PasskeyService {
+	return &PasskeyService{
+		passkeyRepo: passkeyRepo,
+		userRepo:    userRepo,
+		rp:          rp,
+		now:         time.Now,
+	}
+}
+
+// List returns a user's passkeys
+func (s *PasskeyService) List(ctx context.Context, userID int64) ([]*domain.Passkey, error) {
+	return s.passkeyRepo.ListByUser(ctx, userID)
+}
+
+// BeginRegistration returns options for navigator.credentials.create() to add
+// a passkey to the user's account
+func (s *PasskeyService) BeginRegistration(ctx context.Context, userID int64) (*webauthn.CreationOptions, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	existing, err := s.passkeyRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	challenge, err := s.newChallenge(ctx, domain.PasskeyChallengeRegistration, &userID)
+	if err != nil {
+		return nil, err
+	}
+
+	params := make([]webauthn.CredentialParameter, len(webauthn.SupportedAlgorithms))
+	for i, alg := range webauthn.SupportedAlgorithms {
+		params[i] = webauthn.CredentialParameter{Type: "public-key", Alg: alg}
+	}
+
+	return &webauthn.CreationOptions{
+		Challenge: challenge,
+		RP:        webauthn.RelyingPartyEntity{ID: s.rp.ID, Name: s.rp.Name},
+		User: webauthn.UserEntity{
+			ID:          userHandle(userID),
+			Name:        user.Email,
+			DisplayName: user.Name,
+		},
+		PubKeyCredParams:   params,
+		Timeout:            passkeyChallengeExpiration.Milliseconds(),
+		ExcludeCredentials: credentialDescriptors(existing),
+		AuthenticatorSelection: webauthn.AuthenticatorSelection{
+			ResidentKey:      "preferred",
+			UserVerification: webauthn.UserVerificationPreferred,
+		},
+		Attestation: "none",
+	}, nil
+}
+
+// FinishRegistration verifies the authenticator's response and stores the
+// passkey under the given name
+func (s *PasskeyService) FinishRegistration(ctx context.Context, userID int64, name string, resp *webauthn.RegistrationResponse) (*domain.Passkey, error) {
+	name, err := normalizePasskeyName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := resp.Challenge()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPasskey, err)
+	}
+	challenge, err := s.passkeyRepo.ConsumeChallenge(ctx, value, domain.PasskeyChallengeRegistration, s.now())
+	if err != nil {
+		return nil, err
+	}
+	if challenge == nil || challenge.UserID == nil || *challenge.UserID != userID {
+		return nil, ErrInvalidPasskeyChallenge
+	}
+
+	credential, err := s.rp.VerifyRegistration(challenge.Challenge, resp, false)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPasskey, err)
+	}
+
+	credentialID := webauthn.EncodeBase64URL(credential.ID)
+	existing, err := s.passkeyRepo.GetByCredentialID(ctx, credentialID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrPasskeyAlreadyRegistered
+	}
+
+	passkey := &domain.Passkey{
+		UserID:       userID,
+		CredentialID: credentialID,
+		PublicKey:    webauthn.EncodeBase64URL(credential.PublicKey),
+		SignCount:    credential.SignCount,
+		Transports:   resp.Response.Transports,
+		Name:         name,
+	}
+	if err := s.passkeyRepo.Create(ctx, passkey); err != nil {
+		return nil, err
+	}
+
+	return passkey, nil
+}
+
+// BeginLogin returns options for navigator.credentials.get(). With an email,
+// the user's passkeys are listed so the browser can offer them; without one,
+// any discoverable passkey for this site may be used. Unknown emails get the
+// same response as no email so accounts can't be discovered.
+func (s *PasskeyService) BeginLogin(ctx context.Context, email string) (*webauthn.RequestOptions, error) {
+	var userID *int64
+	allow := []webauthn.CredentialDescriptor{}
+
+	if email != "" {
+		user, err := s.userRepo.GetByEmail(ctx, email)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user: %w", err)
+		}
+		if user != nil {
+			passkeys, err := s.passkeyRepo.ListByUser(ctx, user.ID)
+			if err != nil {
+				return nil, err
+			}
+			if len(passkeys) > 0 {
+				userID = &user.ID
+				allow = credentialDescriptors(passkeys)
+			}
+		}
+	}
+
+	challenge, err := s.newChallenge(ctx, domain.PasskeyChallengeAuthentication, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &webauthn.RequestOptions{
+		Challenge:        challenge,
+		Timeout:          passkeyChallengeExpiration.Milliseconds(),
+		RPID:             s.rp.ID,
+		AllowCredentials: allow,
+		UserVerification: webauthn.UserVerificationPreferred,
+	}, nil
+}
+
+// FinishLogin verifies a passkey assertion and returns the passkey's owner.
+// userVerified reports whether the authenticator checked a biometric or PIN,
+// which makes the passkey a second factor on its own.
+func (s *PasskeyService) FinishLogin(ctx context.Context, resp *webauthn.AuthenticationResponse) (user *domain.User, userVerified bool, err error) {
+	value, err := resp.Challenge()
+	if err != nil {
+		return nil, false, fmt.Errorf("%w: %v", ErrInvalidPasskey, err)
+	}
+	challenge, err := s.passkeyRepo.ConsumeChallenge(ctx, value, domain.PasskeyChallengeAuthentication, s.now())
+	if err != nil {
+		return nil, false, err
+	}
+	if challenge == nil {
+		return nil, false, ErrInvalidPasskeyChallenge
+	}
+
+	rawID, err := webauthn.DecodeBase64URL(resp.RawID)
+	if err != nil {
+		return nil, false, ErrInvalidPasskey
+	}
+	passkey, err := s.passkeyRepo.GetByCredentialID(ctx, webauthn.EncodeBase64URL(rawID))
+	if err != nil {
+		return nil, false, err
+	}
+	if passkey == nil {
+		return nil, false, ErrInvalidPasskey
+	}
+	if challenge.UserID != nil && *challenge.UserID != passkey.UserID {
+		return nil, false, ErrInvalidPasskey
+	}
+	if resp.Response.UserHandle != "" && strings.TrimRight(resp.Response.UserHandle, "=") != userHandle(passkey.UserID) {
+		return nil, false, ErrInvalidPasskey
+	}
+
+	publicKey, err := webauthn.DecodeBase64URL(passkey.PublicKey)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to decode passkey public key: %w", err)
+	}
+	assertion, err := s.rp.VerifyAssertion(challenge.Challenge, resp, publicKey, passkey.SignCount, false)
+	if err != nil {
+		return nil, false, fmt.Errorf("%w: %v", ErrInvalidPasskey, err)
+	}
+
+	if err := s.passkeyRepo.RecordUse(ctx, passkey.ID, assertion.SignCount, s.now()); err != nil {
+		return nil, false, err
+	}
+
+	user, err = s.userRepo.GetByID(ctx, passkey.UserID)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil, false, ErrInvalidPasskey
+	}
+
+	return user, assertion.UserVerified, nil
+}
+
+// Rename changes the name of one of the user's passkeys
+func (s *PasskeyService) Rename(ctx context.Context, id, userID int64, name string) error {
+	name, err := normalizePasskeyName(name)
+	if err != nil {
+		return err
+	}
+
+	ok, err := s.passkeyRepo.Rename(ctx, id, userID, name)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrPasskeyNotFound
+	}
+	return nil
+}
+
+// Delete removes one of the user's passkeys
+func (s *PasskeyService) Delete(ctx context.Context, id, userID int64) error {
+	ok, err := s.passkeyRepo.Delete(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrPasskeyNotFound
+	}
+	return nil
+}
+
+// newChallenge creates and stores a challenge, clearing out expired ones
+func (s *PasskeyService) newChallenge(ctx context.Context, purpose string, userID *int64) (string, error) {
+	now := s.now()
+	if err := s.passkeyRepo.DeleteExpiredChallenges(ctx, now); err != nil {
+		return "", err
+	}
+
+	value, err := webauthn.NewChallenge()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate challenge: %w", err)
+	}
+
+	challenge := &domain.PasskeyChallenge{
+		Challenge: value,
+		Purpose:   purpose,
+		UserID:    userID,
+		ExpiresAt: now.Add(passkeyChallengeExpiration),
+	}
+	if err := s.passkeyRepo.CreateChallenge(ctx, challenge); err != nil {
+		return "", err
+	}
+	return value, nil
+}
+
+// userHandle is the WebAuthn user handle for a user: the ID as 8 big-endian
+// bytes, so it contains no personal information
+func userHandle(userID int64) string {
+	return webauthn.EncodeBase64URL(binary.BigEndian.AppendUint64(nil, uint64(userID)))
+}
+
+func credentialDescriptors(passkeys []*domain.Passkey) []webauthn.CredentialDescriptor {
+	descriptors := make([]webauthn.CredentialDescriptor, len(passkeys))
+	for i, passkey := range passkeys {
+		descriptors[i] = webauthn.CredentialDescriptor{
+			Type:       "public-key",
+			ID:         passkey.CredentialID,
+			Transports: passkey.Transports,
+		}
+	}
+	return descriptors
+}
+
+func normalizePasskeyName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return defaultPasskeyName, nil
+	}
+	if len([]rune(name)) > maxPasskeyNameLength {
+		return "", ErrInvalidPasskeyName
+	}
+	return name, nil
+}
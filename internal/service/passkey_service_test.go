@@ -0,0 +1,284 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/johnzastrow/actalog/internal/domain"
+	"github.com/johnzastrow/actalog/internal/testhelpers"
+	"github.com/johnzastrow/actalog/pkg/webauthn"
+)
+
+type mockPasskeyRepo struct {
+	passkeys   []*domain.Passkey
+	challenges []*domain.PasskeyChallenge
+	nextID     int64
+}
+
+func (m *mockPasskeyRepo) Create(ctx context.Context, passkey *domain.Passkey) error {
+	m.nextID++
+	passkey.ID = m.nextID
+	passkey.CreatedAt = time.Now()
+	copied := *passkey
+	m.passkeys = append(m.passkeys, &copied)
+	return nil
+}
+
+func (m *mockPasskeyRepo) GetByCredentialID(ctx context.Context, credentialID string) (*domain.Passkey, error) {
+	for _, p := range m.passkeys {
+		if p.CredentialID == credentialID {
+			copied := *p
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockPasskeyRepo) ListByUser(ctx context.Context, userID int64) ([]*domain.Passkey, error) {
+	result := []*domain.Passkey{}
+	for _, p := range m.passkeys {
+		if p.UserID == userID {
+			copied := *p
+			result = append(result, &copied)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockPasskeyRepo) find(id, userID int64) int {
+	for i, p := range m.passkeys {
+		if p.ID == id && p.UserID == userID {
+			return i
+		}
+	}
+	return -1
+}
+
+func (m *mockPasskeyRepo) Rename(ctx context.Context, id, userID int64, name string) (bool, error) {
+	i := m.find(id, userID)
+	if i < 0 {
+		return false, nil
+	}
+	m.passkeys[i].Name = name
+	return true, nil
+}
+
+func (m *mockPasskeyRepo) RecordUse(ctx context.Context, id int64, signCount uint32, usedAt time.Time) error {
+	for _, p := range m.passkeys {
+		if p.ID == id {
+			p.SignCount = signCount
+			p.LastUsedAt = &usedAt
+		}
+	}
+	return nil
+}
+
+func (m *mockPasskeyRepo) Delete(ctx context.Context, id, userID int64) (bool, error) {
+	i := m.find(id, userID)
+	if i < 0 {
+		return false, nil
+	}
+	m.passkeys = append(m.passkeys[:i], m.passkeys[i+1:]...)
+	return true, nil
+}
+
+func (m *mockPasskeyRepo) CreateChallenge(ctx context.Context, challenge *domain.PasskeyChallenge) error {
+	m.challenges = append(m.challenges, challenge)
+	return nil
+}
+
+func (m *mockPasskeyRepo) ConsumeChallenge(ctx context.Context, value, purpose string, now time.Time) (*domain.PasskeyChallenge, error) {
+	for i, c := range m.challenges {
+		if c.Challenge == value && c.Purpose == purpose {
+			m.challenges = append(m.challenges[:i], m.challenges[i+1:]...)
+			if !c.ExpiresAt.After(now) {
+				return nil, nil
+			}
+			return c, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockPasskeyRepo) DeleteExpiredChallenges(ctx context.Context, before time.Time) error {
+	kept := []*domain.PasskeyChallenge{}
+	for _, c := range m.challenges {
+		if !c.ExpiresAt.Before(before) {
+			kept = append(kept, c)
+		}
+	}
+	m.challenges = kept
+	return nil
+}
+
+const passkeyTestOrigin = "https://actalog.example.com"
+
+func newTestPasskeyService(userService *UserService) (*PasskeyService, *mockPasskeyRepo) {
+	repo := &mockPasskeyRepo{}
+	rp := webauthn.RelyingParty{ID: "actalog.example.com", Name: "ActaLog", Origins: []string{passkeyTestOrigin}}
+	passkeys := NewPasskeyService(repo, userService.userRepo, rp)
+	userService.SetPasskeyService(passkeys)
+	return passkeys, repo
+}
+
+func registerPasskey(t *testing.T, passkeys *PasskeyService, authenticator *testhelpers.SoftwareAuthenticator, userID int64, name string) *domain.Passkey {
+	t.Helper()
+	options, err := passkeys.BeginRegistration(context.Background(), userID)
+	if err != nil {
+		t.Fatalf("failed to begin registration: %v", err)
+	}
+	resp, err := authenticator.Register(options)
+	if err != nil {
+		t.Fatalf("authenticator failed to register: %v", err)
+	}
+	passkey, err := passkeys.FinishRegistration(context.Background(), userID, name, resp)
+	if err != nil {
+		t.Fatalf("failed to finish registration: %v", err)
+	}
+	return passkey
+}
+
+func TestPasskey_RegisterAndLogin(t *testing.T) {
+	userService := newTestUserService(true)
+	user, _, err := userService.Register(context.Background(), "Ana", "ana@example.com", "Password123!")
+	if err != nil {
+		t.Fatalf("failed to register: %v", err)
+	}
+	passkeys, repo := newTestPasskeyService(userService)
+
+	phone := testhelpers.NewSoftwareAuthenticator(passkeyTestOrigin)
+	tablet := testhelpers.NewSoftwareAuthenticator(passkeyTestOrigin)
+	phonePasskey := registerPasskey(t, passkeys, phone, user.ID, "Phone")
+	registerPasskey(t, passkeys, tablet, user.ID, "  ")
+
+	list, _ := passkeys.List(context.Background(), user.ID)
+	if len(list) != 2 || list[0].Name != "Phone" || list[1].Name != defaultPasskeyName {
+		t.Fatalf("unexpected passkeys %+v", list)
+	}
+
+	// The same authenticator can't be registered twice
+	options, _ := passkeys.BeginRegistration(context.Background(), user.ID)
+	if len(options.ExcludeCredentials) != 2 {
+		t.Errorf("expected existing passkeys to be excluded, got %d", len(options.ExcludeCredentials))
+	}
+
+	// Login naming the account
+	loginOptions, err := userService.BeginPasskeyLogin(context.Background(), "ana@example.com")
+	if err != nil || len(loginOptions.AllowCredentials) != 2 {
+		t.Fatalf("unexpected login options %+v (err=%v)", loginOptions, err)
+	}
+	assertion, err := phone.Login(loginOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loggedIn, token, err := userService.LoginWithPasskey(context.Background(), assertion)
+	if err != nil || loggedIn.ID != user.ID || token == "" {
+		t.Fatalf("expected passkey login to succeed, got user=%v err=%v", loggedIn, err)
+	}
+	if loggedIn.PasswordHash != "" {
+		t.Error("expected password hash to be cleared")
+	}
+
+	stored, _ := repo.GetByCredentialID(context.Background(), phonePasskey.CredentialID)
+	if stored.LastUsedAt == nil || stored.SignCount != 1 {
+		t.Errorf("expected last used time and sign count to be recorded, got %+v", stored)
+	}
+
+	// Challenges are single use
+	if _, _, err := userService.LoginWithPasskey(context.Background(), assertion); !errors.Is(err, ErrInvalidPasskeyChallenge) {
+		t.Errorf("expected a replayed assertion to be rejected, got %v", err)
+	}
+
+	// Discoverable login without an email, and unknown emails look the same
+	unknown, _ := userService.BeginPasskeyLogin(context.Background(), "nobody@example.com")
+	if len(unknown.AllowCredentials) != 0 {
+		t.Error("expected no credentials for an unknown email")
+	}
+	assertion, _ = tablet.Login(unknown)
+	if loggedIn, _, err := userService.LoginWithPasskey(context.Background(), assertion); err != nil || loggedIn.ID != user.ID {
+		t.Errorf("expected discoverable passkey login to succeed, got err=%v", err)
+	}
+
+	if err := passkeys.Rename(context.Background(), phonePasskey.ID, user.ID, "Gym tablet"); err != nil {
+		t.Errorf("failed to rename: %v", err)
+	}
+	if err := passkeys.Delete(context.Background(), phonePasskey.ID, user.ID+1); !errors.Is(err, ErrPasskeyNotFound) {
+		t.Errorf("expected another user's passkey not to be found, got %v", err)
+	}
+	if err := passkeys.Delete(context.Background(), phonePasskey.ID, user.ID); err != nil {
+		t.Fatalf("failed to delete: %v", err)
+	}
+
+	// A deleted passkey no longer logs in
+	loginOptions, _ = userService.BeginPasskeyLogin(context.Background(), "")
+	loginOptions.AllowCredentials = []webauthn.CredentialDescriptor{{Type: "public-key", ID: phonePasskey.CredentialID}}
+	assertion, _ = phone.Login(loginOptions)
+	if _, _, err := userService.LoginWithPasskey(context.Background(), assertion); !errors.Is(err, ErrInvalidPasskey) {
+		t.Errorf("expected ErrInvalidPasskey for a deleted passkey, got %v", err)
+	}
+}
+
+func TestPasskey_ChallengeBoundToUser(t *testing.T) {
+	userService := newTestUserService(true)
+	ana, _, _ := userService.Register(context.Background(), "Ana", "ana@example.com", "Password123!")
+	ben, _, _ := userService.Register(context.Background(), "Ben", "ben@example.com", "Password123!")
+	passkeys, _ := newTestPasskeyService(userService)
+
+	authenticator := testhelpers.NewSoftwareAuthenticator(passkeyTestOrigin)
+	options, _ := passkeys.BeginRegistration(context.Background(), ana.ID)
+	resp, _ := authenticator.Register(options)
+	if _, err := passkeys.FinishRegistration(context.Background(), ben.ID, "Stolen", resp); !errors.Is(err, ErrInvalidPasskeyChallenge) {
+		t.Errorf("expected a challenge issued to another user to be rejected, got %v", err)
+	}
+
+	// Ben's passkey can't answer a login challenge issued for Ana
+	registerPasskey(t, passkeys, authenticator, ben.ID, "Ben's phone")
+	other := testhelpers.NewSoftwareAuthenticator(passkeyTestOrigin)
+	registerPasskey(t, passkeys, other, ana.ID, "Ana's phone")
+
+	loginOptions, _ := userService.BeginPasskeyLogin(context.Background(), "ana@example.com")
+	benOptions := *loginOptions
+	benOptions.AllowCredentials = nil
+	assertion, _ := authenticator.Login(&benOptions)
+	if _, _, err := userService.LoginWithPasskey(context.Background(), assertion); !errors.Is(err, ErrInvalidPasskey) {
+		t.Errorf("expected ErrInvalidPasskey, got %v", err)
+	}
+}
+
+func TestPasskey_UnverifiedPasskeyStillRequiresTOTP(t *testing.T) {
+	userService := newTestUserService(true)
+	user, _, _ := userService.Register(context.Background(), "Ana", "ana@example.com", "Password123!")
+	passkeys, _ := newTestPasskeyService(userService)
+
+	mfaRepo := newMockMFARepo()
+	now := time.Date(2025, 11, 19, 10, 0, 0, 0, time.UTC)
+	mfa := NewMFAService(mfaRepo, userService.userRepo, "ActaLog")
+	mfa.now = func() time.Time { return now }
+	userService.SetMFAService(mfa)
+	if _, err := mfa.BeginTOTPEnrollment(context.Background(), user.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mfa.ConfirmTOTPEnrollment(context.Background(), user.ID, totpCodeAt(t, mfaRepo, user.ID, now)); err != nil {
+		t.Fatal(err)
+	}
+
+	authenticator := testhelpers.NewSoftwareAuthenticator(passkeyTestOrigin)
+	authenticator.UserVerified = false
+	registerPasskey(t, passkeys, authenticator, user.ID, "Security key")
+
+	options, _ := userService.BeginPasskeyLogin(context.Background(), "ana@example.com")
+	assertion, _ := authenticator.Login(options)
+	var mfaErr *MFARequiredError
+	if _, _, err := userService.LoginWithPasskey(context.Background(), assertion); !errors.As(err, &mfaErr) {
+		t.Fatalf("expected MFARequiredError without user verification, got %v", err)
+	}
+
+	authenticator.UserVerified = true
+	options, _ = userService.BeginPasskeyLogin(context.Background(), "ana@example.com")
+	assertion, _ = authenticator.Login(options)
+	if _, token, err := userService.LoginWithPasskey(context.Background(), assertion); err != nil || token == "" {
+		t.Errorf("expected a user-verified passkey to skip TOTP, got err=%v", err)
+	}
+}
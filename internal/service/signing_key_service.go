@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/johnzastrow/actalog/internal/domain"
+	"github.com/johnzastrow/actalog/pkg/auth"
+)
+
+const (
+	// signingKeyCheckInterval is how often keys are reloaded and checked for rotation
+	signingKeyCheckInterval = 5 * time.Minute
+	// signingKeyPublishLead is how long a new key is published before it signs
+	// anything, so other instances and cached JWKS copies know it first
+	signingKeyPublishLead = 2 * signingKeyCheckInterval
+)
+
+// SigningKeyService manages the key pairs that sign access tokens. Keys are
+// stored in the database so every instance signs with the same key, rotated
+// on a schedule, and kept for verification until tokens they signed expire.
+// With a key cipher set, private keys are stored encrypted.
+type SigningKeyService struct {
+	keyRepo       domain.SigningKeyRepository
+	keys          *auth.KeySet
+	cipher        *auth.KeyCipher
+	algorithm     string
+	rotateEvery   time.Duration
+	tokenLifetime time.Duration
+	now           func() time.Time
+}
+
+// NewSigningKeyService creates a signing key service that loads keys into
+// keys. algorithm is auth.AlgorithmRS256 or auth.AlgorithmEdDSA; rotateEvery
+// of zero disables scheduled rotation. accessTokenLifetime is how long a
+// signed access token stays valid.
+func NewSigningKeyService(
+	keyRepo domain.SigningKeyRepository,
+	keys *auth.KeySet,
+	algorithm string,
+	rotateEvery time.Duration,
+	accessTokenLifetime time.Duration,
+) *SigningKeyService {
+	tokenLifetime := accessTokenLifetime
+	if tokenLifetime < mfaChallengeExpiration {
+		tokenLifetime = mfaChallengeExpiration
+	}
+	return &SigningKeyService{
+		keyRepo:       keyRepo,
+		keys:          keys,
+		algorithm:     algorithm,
+		rotateEvery:   rotateEvery,
+		tokenLifetime: tokenLifetime,
+		now:           time.Now,
+	}
+}
+
+// SetKeyCipher encrypts private keys at rest. Keys stored in plain PEM are
+// encrypted on the next Refresh.
+func (s *SigningKeyService) SetKeyCipher(cipher *auth.KeyCipher) {
+	s.cipher = cipher
+}
+
+// Run refreshes the keys periodically until ctx is cancelled
+func (s *SigningKeyService) Run(ctx context.Context) {
+	ticker := time.NewTicker(signingKeyCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := s.Refresh(ctx); err != nil {
+			fmt.Printf("warning: failed to refresh signing keys: %v\n", err)
+		}
+	}
+}
+
+// Refresh loads the stored keys into the key set, creating a new key when
+// there is none, the algorithm has changed or rotation is due, and deleting
+// keys that can no longer have signed a valid token
+func (s *SigningKeyService) Refresh(ctx context.Context) error {
+	stored, err := s.keyRepo.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := s.now()
+	if s.rotationDue(stored, now) {
+		// The first key signs straight away; later ones are published first
+		activatesAt := now
+		if len(stored) > 0 {
+			activatesAt = now.Add(signingKeyPublishLead)
+		}
+		key, err := s.createKey(ctx, now, activatesAt)
+		if err != nil {
+			return err
+		}
+		stored = append(stored, key)
+	}
+
+	var signing *auth.SigningKey
+	var verification []*auth.SigningKey
+	for i, key := range stored {
+		// A key stops signing when the next one activates, and stops
+		// verifying once the last tokens it signed have expired
+		if i+1 < len(stored) && now.After(stored[i+1].ActivatesAt.Add(s.tokenLifetime)) {
+			if err := s.keyRepo.Delete(ctx, key.ID); err != nil {
+				return err
+			}
+			continue
+		}
+
+		pemData, err := s.cipher.Open(key.PrivateKey)
+		if err != nil {
+			return fmt.Errorf("failed to load signing key %s: %w", key.KeyID, err)
+		}
+		if s.cipher != nil && !auth.IsEncryptedKey(key.PrivateKey) {
+			if err := s.storeEncrypted(ctx, key, pemData); err != nil {
+				return err
+			}
+		}
+
+		parsed, err := auth.ParseSigningKey(key.KeyID, key.Algorithm, pemData)
+		if err != nil {
+			return err
+		}
+		verification = append(verification, parsed)
+		if !key.ActivatesAt.After(now) {
+			signing = parsed
+		}
+	}
+
+	s.keys.SetKeys(signing, verification)
+	return nil
+}
+
+// rotationDue reports whether a new key should be created. Nothing is due
+// while a new key is waiting to activate.
+func (s *SigningKeyService) rotationDue(stored []*domain.SigningKey, now time.Time) bool {
+	if len(stored) == 0 {
+		return true
+	}
+	newest := stored[len(stored)-1]
+	if newest.Algorithm != s.algorithm {
+		return true
+	}
+	if newest.ActivatesAt.After(now) || s.rotateEvery <= 0 {
+		return false
+	}
+	return !now.Before(newest.ActivatesAt.Add(s.rotateEvery - signingKeyPublishLead))
+}
+
+// createKey generates and stores a new key
+func (s *SigningKeyService) createKey(ctx context.Context, now, activatesAt time.Time) (*domain.SigningKey, error) {
+	generated, err := auth.GenerateSigningKey(s.algorithm)
+	if err != nil {
+		return nil, err
+	}
+	privateKey, err := generated.MarshalPEM()
+	if err != nil {
+		return nil, err
+	}
+	if s.cipher != nil {
+		if privateKey, err = s.cipher.Seal(privateKey); err != nil {
+			return nil, err
+		}
+	}
+
+	key := &domain.SigningKey{
+		KeyID:       generated.ID,
+		Algorithm:   generated.Algorithm,
+		PrivateKey:  privateKey,
+		CreatedAt:   now,
+		ActivatesAt: activatesAt,
+	}
+	if err := s.keyRepo.Create(ctx, key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// storeEncrypted replaces a key stored in plain PEM with its encrypted form
+func (s *SigningKeyService) storeEncrypted(ctx context.Context, key *domain.SigningKey, pemData string) error {
+	sealed, err := s.cipher.Seal(pemData)
+	if err != nil {
+		return err
+	}
+	if err := s.keyRepo.UpdatePrivateKey(ctx, key.ID, sealed); err != nil {
+		return err
+	}
+	key.PrivateKey = sealed
+	return nil
+}
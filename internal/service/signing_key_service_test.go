@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/johnzastrow/actalog/internal/domain"
+	"github.com/johnzastrow/actalog/pkg/auth"
+)
+
+type mockSigningKeyRepo struct {
+	keys   []*domain.SigningKey
+	nextID int64
+}
+
+func (m *mockSigningKeyRepo) Create(ctx context.Context, key *domain.SigningKey) error {
+	m.nextID++
+	key.ID = m.nextID
+	m.keys = append(m.keys, key)
+	return nil
+}
+
+func (m *mockSigningKeyRepo) List(ctx context.Context) ([]*domain.SigningKey, error) {
+	return append([]*domain.SigningKey{}, m.keys...), nil
+}
+
+func (m *mockSigningKeyRepo) UpdatePrivateKey(ctx context.Context, id int64, privateKey string) error {
+	for _, key := range m.keys {
+		if key.ID == id {
+			key.PrivateKey = privateKey
+		}
+	}
+	return nil
+}
+
+func (m *mockSigningKeyRepo) Delete(ctx context.Context, id int64) error {
+	for i, key := range m.keys {
+		if key.ID == id {
+			m.keys = append(m.keys[:i], m.keys[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func TestSigningKeyService_Rotation(t *testing.T) {
+	repo := &mockSigningKeyRepo{}
+	keys := auth.NewHMACKeySet("")
+	keyService := NewSigningKeyService(repo, keys, auth.AlgorithmEdDSA, 24*time.Hour, time.Hour)
+	now := time.Now()
+	keyService.now = func() time.Time { return now }
+
+	sign := func() string {
+		t.Helper()
+		token, err := auth.GenerateToken(1, "ana@example.com", "user", keys, time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	// The first key signs straight away
+	if err := keyService.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(repo.keys) != 1 || len(keys.JWKS().Keys) != 1 {
+		t.Fatalf("expected one key, got %d stored", len(repo.keys))
+	}
+	first := repo.keys[0].KeyID
+	firstToken := sign()
+
+	// Refreshing before rotation is due changes nothing
+	now = now.Add(12 * time.Hour)
+	keyService.Refresh(context.Background())
+	if len(repo.keys) != 1 {
+		t.Fatalf("expected no rotation yet, got %d keys", len(repo.keys))
+	}
+
+	// A new key is published before it is due, but doesn't sign yet
+	now = now.Add(12*time.Hour - signingKeyPublishLead)
+	keyService.Refresh(context.Background())
+	if len(repo.keys) != 2 || len(keys.JWKS().Keys) != 2 {
+		t.Fatalf("expected the next key to be published, got %d keys", len(repo.keys))
+	}
+	second := repo.keys[1].KeyID
+	if jwks := keys.JWKS(); !hasKey(jwks, first) || !hasKey(jwks, second) {
+		t.Errorf("expected both keys in JWKS, got %+v", jwks)
+	}
+	assertSignedBy(t, sign(), first)
+
+	// Once active it signs, and the old key still verifies its tokens
+	now = now.Add(signingKeyPublishLead)
+	keyService.Refresh(context.Background())
+	assertSignedBy(t, sign(), second)
+	if _, err := auth.ValidateToken(firstToken, keys); err != nil {
+		t.Errorf("expected tokens signed with the previous key to stay valid, got %v", err)
+	}
+
+	// The old key is dropped after the longest token lifetime
+	now = now.Add(time.Hour + time.Minute)
+	keyService.Refresh(context.Background())
+	if len(repo.keys) != 1 || repo.keys[0].KeyID != second || hasKey(keys.JWKS(), first) {
+		t.Errorf("expected only the current key to remain, got %+v", repo.keys)
+	}
+
+	// Changing algorithm publishes a key of the new type
+	keyService.algorithm = auth.AlgorithmRS256
+	keyService.Refresh(context.Background())
+	if len(repo.keys) != 2 || repo.keys[1].Algorithm != auth.AlgorithmRS256 {
+		t.Errorf("expected an RS256 key after changing algorithm, got %+v", repo.keys)
+	}
+}
+
+func TestSigningKeyService_EncryptsKeys(t *testing.T) {
+	repo := &mockSigningKeyRepo{}
+	keys := auth.NewHMACKeySet("")
+	keyService := NewSigningKeyService(repo, keys, auth.AlgorithmEdDSA, 24*time.Hour, time.Hour)
+
+	// A key stored before encryption was configured
+	if err := keyService.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(repo.keys[0].PrivateKey, "PRIVATE KEY") {
+		t.Fatalf("expected a plain PEM key without a cipher, got %q", repo.keys[0].PrivateKey)
+	}
+	token, _ := auth.GenerateToken(1, "ana@example.com", "user", keys, time.Hour)
+
+	// is encrypted on the next refresh and keeps verifying its tokens
+	keyCipher, err := auth.NewKeyCipher("key-encryption-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyService.SetKeyCipher(keyCipher)
+	if err := keyService.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !auth.IsEncryptedKey(repo.keys[0].PrivateKey) {
+		t.Errorf("expected the stored key to be encrypted, got %q", repo.keys[0].PrivateKey)
+	}
+	if _, err := auth.ValidateToken(token, keys); err != nil {
+		t.Errorf("expected the key to keep verifying, got %v", err)
+	}
+
+	// New keys are stored encrypted, and can't be loaded without the cipher
+	keyService.algorithm = auth.AlgorithmRS256
+	if err := keyService.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(repo.keys) != 2 || !auth.IsEncryptedKey(repo.keys[1].PrivateKey) {
+		t.Errorf("expected a new encrypted key, got %+v", repo.keys)
+	}
+	keyService.SetKeyCipher(nil)
+	if err := keyService.Refresh(context.Background()); !errors.Is(err, auth.ErrKeyEncrypted) {
+		t.Errorf("expected ErrKeyEncrypted without the cipher, got %v", err)
+	}
+}
+
+func assertSignedBy(t *testing.T, token, kid string) {
+	t.Helper()
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, &auth.Claims{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Header["kid"] != kid {
+		t.Errorf("expected token signed by %s, got %v", kid, parsed.Header["kid"])
+	}
+}
+
+func hasKey(jwks auth.JWKS, kid string) bool {
+	for _, key := range jwks.Keys {
+		if key.KeyID == kid {
+			return true
+		}
+	}
+	return false
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"database/sql"
 	"time"
 
@@ -26,7 +27,7 @@ func newMockUserWorkoutRepo() *mockUserWorkoutRepo {
 	}
 }
 
-func (m *mockUserWorkoutRepo) Create(userWorkout *domain.UserWorkout) error {
+func (m *mockUserWorkoutRepo) Create(ctx context.Context, userWorkout *domain.UserWorkout) error {
 	if m.createError != nil {
 		return m.createError
 	}
@@ -38,7 +39,7 @@ func (m *mockUserWorkoutRepo) Create(userWorkout *domain.UserWorkout) error {
 	return nil
 }
 
-func (m *mockUserWorkoutRepo) GetByID(id int64) (*domain.UserWorkout, error) {
+func (m *mockUserWorkoutRepo) GetByID(ctx context.Context, id int64) (*domain.UserWorkout, error) {
 	if m.getByIDError != nil {
 		return nil, m.getByIDError
 	}
@@ -49,7 +50,7 @@ func (m *mockUserWorkoutRepo) GetByID(id int64) (*domain.UserWorkout, error) {
 	return uw, nil
 }
 
-func (m *mockUserWorkoutRepo) GetByIDWithDetails(id int64, userID int64) (*domain.UserWorkoutWithDetails, error) {
+func (m *mockUserWorkoutRepo) GetByIDWithDetails(ctx context.Context, id int64, userID int64) (*domain.UserWorkoutWithDetails, error) {
 	if m.getByIDError != nil {
 		return nil, m.getByIDError
 	}
@@ -77,7 +78,7 @@ func (m *mockUserWorkoutRepo) GetByIDWithDetails(id int64, userID int64) (*domai
 	return uw, nil
 }
 
-func (m *mockUserWorkoutRepo) ListByUser(userID int64, limit, offset int) ([]*domain.UserWorkout, error) {
+func (m *mockUserWorkoutRepo) ListByUser(ctx context.Context, userID int64, limit, offset int) ([]*domain.UserWorkout, error) {
 	var result []*domain.UserWorkout
 	for _, uw := range m.userWorkouts {
 		if uw.UserID == userID {
@@ -87,8 +88,8 @@ func (m *mockUserWorkoutRepo) ListByUser(userID int64, limit, offset int) ([]*do
 	return result, nil
 }
 
-func (m *mockUserWorkoutRepo) ListByUserWithDetails(userID int64, limit, offset int) ([]*domain.UserWorkoutWithDetails, error) {
-	var result []*domain.UserWorkoutWithDetails
+func (m *mockUserWorkoutRepo) ListWithDetails(ctx context.Context, userID int64, filter domain.WorkoutFilter) (*domain.WorkoutPage, error) {
+	result := []*domain.UserWorkoutWithDetails{}
 	for _, uw := range m.userWorkouts {
 		if uw.UserID == userID {
 			details := &domain.UserWorkoutWithDetails{
@@ -101,10 +102,10 @@ func (m *mockUserWorkoutRepo) ListByUserWithDetails(userID int64, limit, offset
 			result = append(result, details)
 		}
 	}
-	return result, nil
+	return &domain.WorkoutPage{Workouts: result}, nil
 }
 
-func (m *mockUserWorkoutRepo) ListByUserAndDateRange(userID int64, startDate, endDate time.Time) ([]*domain.UserWorkout, error) {
+func (m *mockUserWorkoutRepo) ListByUserAndDateRange(ctx context.Context, userID int64, startDate, endDate time.Time) ([]*domain.UserWorkout, error) {
 	var result []*domain.UserWorkout
 	for _, uw := range m.userWorkouts {
 		if uw.UserID == userID && !uw.WorkoutDate.Before(startDate) && !uw.WorkoutDate.After(endDate) {
@@ -114,7 +115,17 @@ func (m *mockUserWorkoutRepo) ListByUserAndDateRange(userID int64, startDate, en
 	return result, nil
 }
 
-func (m *mockUserWorkoutRepo) Update(userWorkout *domain.UserWorkout) error {
+func (m *mockUserWorkoutRepo) ListByDateRange(ctx context.Context, startDate, endDate time.Time) ([]*domain.UserWorkout, error) {
+	var result []*domain.UserWorkout
+	for _, uw := range m.userWorkouts {
+		if !uw.WorkoutDate.Before(startDate) && !uw.WorkoutDate.After(endDate) {
+			result = append(result, uw)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockUserWorkoutRepo) Update(ctx context.Context, userWorkout *domain.UserWorkout) error {
 	if m.updateError != nil {
 		return m.updateError
 	}
@@ -126,7 +137,7 @@ func (m *mockUserWorkoutRepo) Update(userWorkout *domain.UserWorkout) error {
 	return nil
 }
 
-func (m *mockUserWorkoutRepo) Delete(id int64, userID int64) error {
+func (m *mockUserWorkoutRepo) Delete(ctx context.Context, id int64, userID int64) error {
 	if m.deleteError != nil {
 		return m.deleteError
 	}
@@ -142,7 +153,7 @@ func (m *mockUserWorkoutRepo) Delete(id int64, userID int64) error {
 	return nil
 }
 
-func (m *mockUserWorkoutRepo) GetByUserWorkoutDate(userID, workoutID int64, date time.Time) (*domain.UserWorkout, error) {
+func (m *mockUserWorkoutRepo) GetByUserWorkoutDate(ctx context.Context, userID, workoutID int64, date time.Time) (*domain.UserWorkout, error) {
 	for _, uw := range m.userWorkouts {
 		if uw.UserID == userID && uw.WorkoutID != nil && *uw.WorkoutID == workoutID && uw.WorkoutDate.Equal(date) {
 			return uw, nil
@@ -165,7 +176,7 @@ func newMockWorkoutRepo() *mockWorkoutRepo {
 	}
 }
 
-func (m *mockWorkoutRepo) Create(workout *domain.Workout) error {
+func (m *mockWorkoutRepo) Create(ctx context.Context, workout *domain.Workout) error {
 	m.nextID++
 	workout.ID = m.nextID
 	workout.CreatedAt = time.Now()
@@ -174,7 +185,7 @@ func (m *mockWorkoutRepo) Create(workout *domain.Workout) error {
 	return nil
 }
 
-func (m *mockWorkoutRepo) GetByID(id int64) (*domain.Workout, error) {
+func (m *mockWorkoutRepo) GetByID(ctx context.Context, id int64) (*domain.Workout, error) {
 	if m.getByIDError != nil {
 		return nil, m.getByIDError
 	}
@@ -185,7 +196,7 @@ func (m *mockWorkoutRepo) GetByID(id int64) (*domain.Workout, error) {
 	return w, nil
 }
 
-func (m *mockWorkoutRepo) ListByUser(userID int64, limit, offset int) ([]*domain.Workout, error) {
+func (m *mockWorkoutRepo) ListByUser(ctx context.Context, userID int64, limit, offset int) ([]*domain.Workout, error) {
 	var result []*domain.Workout
 	for _, w := range m.workouts {
 		if w.CreatedBy != nil && *w.CreatedBy == userID {
@@ -195,7 +206,7 @@ func (m *mockWorkoutRepo) ListByUser(userID int64, limit, offset int) ([]*domain
 	return result, nil
 }
 
-func (m *mockWorkoutRepo) Update(workout *domain.Workout) error {
+func (m *mockWorkoutRepo) Update(ctx context.Context, workout *domain.Workout) error {
 	if _, ok := m.workouts[workout.ID]; !ok {
 		return sql.ErrNoRows
 	}
@@ -204,7 +215,7 @@ func (m *mockWorkoutRepo) Update(workout *domain.Workout) error {
 	return nil
 }
 
-func (m *mockWorkoutRepo) Delete(id int64) error {
+func (m *mockWorkoutRepo) Delete(ctx context.Context, id int64) error {
 	if _, ok := m.workouts[id]; !ok {
 		return sql.ErrNoRows
 	}
@@ -212,11 +223,11 @@ func (m *mockWorkoutRepo) Delete(id int64) error {
 	return nil
 }
 
-func (m *mockWorkoutRepo) GetUsageCount(templateID int64) (int, error) {
+func (m *mockWorkoutRepo) GetUsageCount(ctx context.Context, templateID int64) (int, error) {
 	return 0, nil
 }
 
-func (m *mockWorkoutRepo) Count(userID *int64) (int64, error) {
+func (m *mockWorkoutRepo) Count(ctx context.Context, userID *int64) (int64, error) {
 	count := int64(0)
 	for _, w := range m.workouts {
 		if userID == nil {
@@ -230,11 +241,11 @@ func (m *mockWorkoutRepo) Count(userID *int64) (int64, error) {
 	return count, nil
 }
 
-func (m *mockWorkoutRepo) GetByIDWithDetails(id int64) (*domain.Workout, error) {
-	return m.GetByID(id)
+func (m *mockWorkoutRepo) GetByIDWithDetails(ctx context.Context, id int64) (*domain.Workout, error) {
+	return m.GetByID(ctx, id)
 }
 
-func (m *mockWorkoutRepo) List(filters map[string]interface{}, limit, offset int) ([]*domain.Workout, error) {
+func (m *mockWorkoutRepo) List(ctx context.Context, filters map[string]interface{}, limit, offset int) ([]*domain.Workout, error) {
 	var result []*domain.Workout
 	for _, w := range m.workouts {
 		result = append(result, w)
@@ -242,7 +253,7 @@ func (m *mockWorkoutRepo) List(filters map[string]interface{}, limit, offset int
 	return result, nil
 }
 
-func (m *mockWorkoutRepo) ListStandard(limit, offset int) ([]*domain.Workout, error) {
+func (m *mockWorkoutRepo) ListStandard(ctx context.Context, limit, offset int) ([]*domain.Workout, error) {
 	var result []*domain.Workout
 	for _, w := range m.workouts {
 		if w.CreatedBy == nil {
@@ -252,12 +263,12 @@ func (m *mockWorkoutRepo) ListStandard(limit, offset int) ([]*domain.Workout, er
 	return result, nil
 }
 
-func (m *mockWorkoutRepo) Search(query string, limit int) ([]*domain.Workout, error) {
+func (m *mockWorkoutRepo) Search(ctx context.Context, query string, limit int) ([]*domain.Workout, error) {
 	return []*domain.Workout{}, nil
 }
 
-func (m *mockWorkoutRepo) GetUsageStats(workoutID int64) (*domain.WorkoutWithUsageStats, error) {
-	w, err := m.GetByID(workoutID)
+func (m *mockWorkoutRepo) GetUsageStats(ctx context.Context, workoutID int64) (*domain.WorkoutWithUsageStats, error) {
+	w, err := m.GetByID(ctx, workoutID)
 	if err != nil {
 		return nil, err
 	}
@@ -271,51 +282,51 @@ func (m *mockWorkoutRepo) GetUsageStats(workoutID int64) (*domain.WorkoutWithUsa
 // Mock WorkoutMovementRepository
 type mockWorkoutMovementRepo struct{}
 
-func (m *mockWorkoutMovementRepo) Create(workoutMovement *domain.WorkoutMovement) error {
+func (m *mockWorkoutMovementRepo) Create(ctx context.Context, workoutMovement *domain.WorkoutMovement) error {
 	return nil
 }
 
-func (m *mockWorkoutMovementRepo) GetByID(id int64) (*domain.WorkoutMovement, error) {
+func (m *mockWorkoutMovementRepo) GetByID(ctx context.Context, id int64) (*domain.WorkoutMovement, error) {
 	return nil, sql.ErrNoRows
 }
 
-func (m *mockWorkoutMovementRepo) GetByWorkoutID(workoutID int64) ([]*domain.WorkoutMovement, error) {
+func (m *mockWorkoutMovementRepo) GetByWorkoutID(ctx context.Context, workoutID int64) ([]*domain.WorkoutMovement, error) {
 	return []*domain.WorkoutMovement{}, nil
 }
 
-func (m *mockWorkoutMovementRepo) GetByUserIDAndMovementID(userID, movementID int64, limit int) ([]*domain.WorkoutMovement, error) {
+func (m *mockWorkoutMovementRepo) GetByUserIDAndMovementID(ctx context.Context, userID, movementID int64, limit int) ([]*domain.WorkoutMovement, error) {
 	return []*domain.WorkoutMovement{}, nil
 }
 
-func (m *mockWorkoutMovementRepo) Update(wm *domain.WorkoutMovement) error {
+func (m *mockWorkoutMovementRepo) Update(ctx context.Context, wm *domain.WorkoutMovement) error {
 	return nil
 }
 
-func (m *mockWorkoutMovementRepo) Delete(id int64) error {
+func (m *mockWorkoutMovementRepo) Delete(ctx context.Context, id int64) error {
 	return nil
 }
 
-func (m *mockWorkoutMovementRepo) DeleteByWorkoutID(workoutID int64) error {
+func (m *mockWorkoutMovementRepo) DeleteByWorkoutID(ctx context.Context, workoutID int64) error {
 	return nil
 }
 
-func (m *mockWorkoutMovementRepo) GetPersonalRecords(userID int64) ([]*domain.PersonalRecord, error) {
+func (m *mockWorkoutMovementRepo) GetPersonalRecords(ctx context.Context, userID int64) ([]*domain.PersonalRecord, error) {
 	return []*domain.PersonalRecord{}, nil
 }
 
-func (m *mockWorkoutMovementRepo) GetMaxWeightForMovement(userID, movementID int64) (*float64, error) {
+func (m *mockWorkoutMovementRepo) GetMaxWeightForMovement(ctx context.Context, userID, movementID int64) (*float64, error) {
 	return nil, nil
 }
 
-func (m *mockWorkoutMovementRepo) GetPRMovements(userID int64, limit int) ([]*domain.WorkoutMovement, error) {
+func (m *mockWorkoutMovementRepo) GetPRMovements(ctx context.Context, userID int64, limit int) ([]*domain.WorkoutMovement, error) {
 	return []*domain.WorkoutMovement{}, nil
 }
 
-func (m *mockWorkoutMovementRepo) ListByWorkout(workoutID int64) ([]*domain.WorkoutMovement, error) {
+func (m *mockWorkoutMovementRepo) ListByWorkout(ctx context.Context, workoutID int64) ([]*domain.WorkoutMovement, error) {
 	return []*domain.WorkoutMovement{}, nil
 }
 
-func (m *mockWorkoutMovementRepo) DeleteByWorkout(workoutID int64) error {
+func (m *mockWorkoutMovementRepo) DeleteByWorkout(ctx context.Context, workoutID int64) error {
 	return nil
 }
 
@@ -349,7 +360,7 @@ func newMockWODRepo() *mockWODRepo {
 	}
 }
 
-func (m *mockWODRepo) Create(wod *domain.WOD) error {
+func (m *mockWODRepo) Create(ctx context.Context, wod *domain.WOD) error {
 	if m.createError != nil {
 		return m.createError
 	}
@@ -359,27 +370,27 @@ func (m *mockWODRepo) Create(wod *domain.WOD) error {
 	return nil
 }
 
-func (m *mockWODRepo) GetByID(id int64) (*domain.WOD, error) {
+func (m *mockWODRepo) GetByID(ctx context.Context, id int64) (*domain.WOD, error) {
 	if m.getByIDError != nil {
 		return nil, m.getByIDError
 	}
 	wod, ok := m.wods[id]
 	if !ok {
-		return nil, sql.ErrNoRows
+		return nil, nil
 	}
 	return wod, nil
 }
 
-func (m *mockWODRepo) GetByName(name string) (*domain.WOD, error) {
+func (m *mockWODRepo) GetByName(ctx context.Context, name string) (*domain.WOD, error) {
 	for _, wod := range m.wods {
 		if wod.Name == name {
 			return wod, nil
 		}
 	}
-	return nil, sql.ErrNoRows
+	return nil, nil
 }
 
-func (m *mockWODRepo) List(filters map[string]interface{}) ([]*domain.WOD, error) {
+func (m *mockWODRepo) List(ctx context.Context, filters map[string]interface{}, limit, offset int) ([]*domain.WOD, error) {
 	var result []*domain.WOD
 	for _, wod := range m.wods {
 		result = append(result, wod)
@@ -387,7 +398,7 @@ func (m *mockWODRepo) List(filters map[string]interface{}) ([]*domain.WOD, error
 	return result, nil
 }
 
-func (m *mockWODRepo) ListStandard() ([]*domain.WOD, error) {
+func (m *mockWODRepo) ListStandard(ctx context.Context, limit, offset int) ([]*domain.WOD, error) {
 	var result []*domain.WOD
 	for _, wod := range m.wods {
 		if wod.IsStandard {
@@ -397,7 +408,7 @@ func (m *mockWODRepo) ListStandard() ([]*domain.WOD, error) {
 	return result, nil
 }
 
-func (m *mockWODRepo) ListByUser(userID int64) ([]*domain.WOD, error) {
+func (m *mockWODRepo) ListByUser(ctx context.Context, userID int64, limit, offset int) ([]*domain.WOD, error) {
 	var result []*domain.WOD
 	for _, wod := range m.wods {
 		if wod.CreatedBy != nil && *wod.CreatedBy == userID {
@@ -407,7 +418,7 @@ func (m *mockWODRepo) ListByUser(userID int64) ([]*domain.WOD, error) {
 	return result, nil
 }
 
-func (m *mockWODRepo) Update(wod *domain.WOD) error {
+func (m *mockWODRepo) Update(ctx context.Context, wod *domain.WOD) error {
 	if m.updateError != nil {
 		return m.updateError
 	}
@@ -418,7 +429,7 @@ func (m *mockWODRepo) Update(wod *domain.WOD) error {
 	return nil
 }
 
-func (m *mockWODRepo) Delete(id int64) error {
+func (m *mockWODRepo) Delete(ctx context.Context, id int64) error {
 	if m.deleteError != nil {
 		return m.deleteError
 	}
@@ -429,7 +440,7 @@ func (m *mockWODRepo) Delete(id int64) error {
 	return nil
 }
 
-func (m *mockWODRepo) Search(query string) ([]*domain.WOD, error) {
+func (m *mockWODRepo) Search(ctx context.Context, query string, limit int) ([]*domain.WOD, error) {
 	var result []*domain.WOD
 	for _, wod := range m.wods {
 		// Simple case-insensitive substring match
@@ -465,3 +476,169 @@ func toLower(s string) string {
 	}
 	return string(result)
 }
+
+// Mock UserWorkoutMovementRepository
+type mockUserWorkoutMovementRepo struct {
+	movements map[int64]*domain.UserWorkoutMovement
+	nextID    int64
+}
+
+func newMockUserWorkoutMovementRepo() *mockUserWorkoutMovementRepo {
+	return &mockUserWorkoutMovementRepo{
+		movements: make(map[int64]*domain.UserWorkoutMovement),
+		nextID:    1,
+	}
+}
+
+func (m *mockUserWorkoutMovementRepo) Create(ctx context.Context, uwm *domain.UserWorkoutMovement) error {
+	uwm.ID = m.nextID
+	m.nextID++
+	m.movements[uwm.ID] = uwm
+	return nil
+}
+
+func (m *mockUserWorkoutMovementRepo) CreateBatch(ctx context.Context, movements []*domain.UserWorkoutMovement) error {
+	for _, uwm := range movements {
+		if err := m.Create(ctx, uwm); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *mockUserWorkoutMovementRepo) GetByID(ctx context.Context, id int64) (*domain.UserWorkoutMovement, error) {
+	return m.movements[id], nil
+}
+
+func (m *mockUserWorkoutMovementRepo) GetByUserWorkoutID(ctx context.Context, userWorkoutID int64) ([]*domain.UserWorkoutMovement, error) {
+	var result []*domain.UserWorkoutMovement
+	for _, uwm := range m.movements {
+		if uwm.UserWorkoutID == userWorkoutID {
+			result = append(result, uwm)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockUserWorkoutMovementRepo) Update(ctx context.Context, uwm *domain.UserWorkoutMovement) error {
+	m.movements[uwm.ID] = uwm
+	return nil
+}
+
+func (m *mockUserWorkoutMovementRepo) Delete(ctx context.Context, id int64) error {
+	delete(m.movements, id)
+	return nil
+}
+
+func (m *mockUserWorkoutMovementRepo) DeleteByUserWorkoutID(ctx context.Context, userWorkoutID int64) error {
+	for id, uwm := range m.movements {
+		if uwm.UserWorkoutID == userWorkoutID {
+			delete(m.movements, id)
+		}
+	}
+	return nil
+}
+
+func (m *mockUserWorkoutMovementRepo) GetMaxWeightForMovement(ctx context.Context, userID, movementID int64) (*float64, error) {
+	return nil, nil
+}
+
+func (m *mockUserWorkoutMovementRepo) GetPRMovements(ctx context.Context, userID int64, limit int) ([]*domain.UserWorkoutMovement, error) {
+	return []*domain.UserWorkoutMovement{}, nil
+}
+
+func (m *mockUserWorkoutMovementRepo) UpdatePRFlag(ctx context.Context, id int64, isPR bool) error {
+	if uwm, ok := m.movements[id]; ok {
+		uwm.IsPR = isPR
+	}
+	return nil
+}
+
+// Mock UserWorkoutWODRepository
+type mockUserWorkoutWODRepo struct {
+	wods   map[int64]*domain.UserWorkoutWOD
+	nextID int64
+}
+
+func newMockUserWorkoutWODRepo() *mockUserWorkoutWODRepo {
+	return &mockUserWorkoutWODRepo{
+		wods:   make(map[int64]*domain.UserWorkoutWOD),
+		nextID: 1,
+	}
+}
+
+func (m *mockUserWorkoutWODRepo) Create(ctx context.Context, uww *domain.UserWorkoutWOD) error {
+	uww.ID = m.nextID
+	m.nextID++
+	m.wods[uww.ID] = uww
+	return nil
+}
+
+func (m *mockUserWorkoutWODRepo) CreateBatch(ctx context.Context, wods []*domain.UserWorkoutWOD) error {
+	for _, uww := range wods {
+		if err := m.Create(ctx, uww); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *mockUserWorkoutWODRepo) GetByID(ctx context.Context, id int64) (*domain.UserWorkoutWOD, error) {
+	return m.wods[id], nil
+}
+
+func (m *mockUserWorkoutWODRepo) GetByUserWorkoutID(ctx context.Context, userWorkoutID int64) ([]*domain.UserWorkoutWOD, error) {
+	var result []*domain.UserWorkoutWOD
+	for _, uww := range m.wods {
+		if uww.UserWorkoutID == userWorkoutID {
+			result = append(result, uww)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockUserWorkoutWODRepo) Update(ctx context.Context, uww *domain.UserWorkoutWOD) error {
+	m.wods[uww.ID] = uww
+	return nil
+}
+
+func (m *mockUserWorkoutWODRepo) Delete(ctx context.Context, id int64) error {
+	delete(m.wods, id)
+	return nil
+}
+
+func (m *mockUserWorkoutWODRepo) DeleteByUserWorkoutID(ctx context.Context, userWorkoutID int64) error {
+	for id, uww := range m.wods {
+		if uww.UserWorkoutID == userWorkoutID {
+			delete(m.wods, id)
+		}
+	}
+	return nil
+}
+
+func (m *mockUserWorkoutWODRepo) GetBestTimeForWOD(ctx context.Context, userID, wodID int64) (*int, error) {
+	return nil, nil
+}
+
+func (m *mockUserWorkoutWODRepo) GetBestRoundsRepsForWOD(ctx context.Context, userID, wodID int64) (rounds *int, reps *int, err error) {
+	return nil, nil, nil
+}
+
+func (m *mockUserWorkoutWODRepo) GetPRWODs(ctx context.Context, userID int64, limit int) ([]*domain.UserWorkoutWOD, error) {
+	return []*domain.UserWorkoutWOD{}, nil
+}
+
+func (m *mockUserWorkoutWODRepo) UpdatePRFlag(ctx context.Context, id int64, isPR bool) error {
+	if uww, ok := m.wods[id]; ok {
+		uww.IsPR = isPR
+	}
+	return nil
+}
+
+// mockTransactor runs functions directly, without a transaction. The
+// integration tests cover rolling back.
+type mockTransactor struct{}
+
+func (m *mockTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
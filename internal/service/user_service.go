@@ -1,15 +1,20 @@
 package service
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"regexp"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/johnzastrow/actalog/internal/domain"
 	"github.com/johnzastrow/actalog/pkg/auth"
 	"github.com/johnzastrow/actalog/pkg/email"
+	"github.com/johnzastrow/actalog/pkg/webauthn"
 )
 
 var (
@@ -23,8 +28,15 @@ var (
 	ErrVerificationTokenExpired = errors.New("verification token has expired")
 	ErrEmailAlreadyVerified     = errors.New("email is already verified")
 	ErrInvalidRefreshToken      = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused       = errors.New("refresh token was already used; session revoked")
+	ErrSessionNotFound          = errors.New("session not found")
+	ErrInvalidLocale            = errors.New("invalid locale")
+	ErrAccountDisabled          = errors.New("account is disabled")
 )
 
+// localePattern accepts simple BCP 47 language tags such as "en", "es" or "pt-BR"
+var localePattern = regexp.MustCompile(`^[a-zA-Z]{2,3}(-[a-zA-Z0-9]{2,8})*$`)
+
 // UserService handles user-related business logic
 type UserService struct {
 	userRepo             domain.UserRepository
@@ -34,9 +46,14 @@ type UserService struct {
 	refreshTokenDuration time.Duration
 	allowRegistration    bool
 	emailService         email.EmailService
-	jwtSecretKey         string
+	keys                 *auth.KeySet
 	appURL               string // Base URL for password reset links
 	requireVerification  bool   // Require email verification for new users
+	mfaService           *MFAService
+	passkeyService       *PasskeyService
+	oidcService          *OIDCService
+	lockoutService       *LockoutService
+	mfaChallenges        mfaChallengeFailures
 }
 
 // NewUserService creates a new user service
@@ -54,7 +71,7 @@ func NewUserService(
 	return &UserService{
 		userRepo:             userRepo,
 		refreshTokenRepo:     refreshTokenRepo,
-		jwtSecretKey:         jwtSecret,
+		keys:                 auth.NewHMACKeySet(jwtSecret),
 		jwtExpiration:        jwtExpiration,
 		refreshTokenDuration: refreshTokenDuration,
 		allowRegistration:    allowRegistration,
@@ -64,10 +81,80 @@ func NewUserService(
 	}
 }
 
+// mfaChallengeExpiration is how long a user has to enter their second factor
+// after entering a correct password
+const mfaChallengeExpiration = 5 * time.Minute
+
+// mfaChallengeMaxFailures is how many wrong codes an MFA challenge token
+// accepts before it is rejected and the user has to log in again
+const mfaChallengeMaxFailures = 5
+
+// mfaChallengeFailures counts wrong codes per MFA challenge (by its token ID)
+// until the challenge expires
+type mfaChallengeFailures struct {
+	mu       sync.Mutex
+	failures map[string]int
+	expires  map[string]time.Time
+}
+
+// exhausted reports whether a challenge has used up its attempts
+func (c *mfaChallengeFailures) exhausted(id string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.failures[id] >= mfaChallengeMaxFailures
+}
+
+// record counts a wrong code for a challenge and forgets expired challenges
+func (c *mfaChallengeFailures) record(id string, expiresAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.failures == nil {
+		c.failures = make(map[string]int)
+		c.expires = make(map[string]time.Time)
+	}
+
+	now := time.Now()
+	for key, expiry := range c.expires {
+		if now.After(expiry) {
+			delete(c.failures, key)
+			delete(c.expires, key)
+		}
+	}
+
+	c.failures[id]++
+	c.expires[id] = expiresAt
+}
+
+// SetMFAService enables two-factor authentication for users who enroll
+func (s *UserService) SetMFAService(mfaService *MFAService) {
+	s.mfaService = mfaService
+}
+
+// SetPasskeyService enables passkey (WebAuthn) login
+func (s *UserService) SetPasskeyService(passkeyService *PasskeyService) {
+	s.passkeyService = passkeyService
+}
+
+// SetOIDCService enables OpenID Connect single sign-on
+func (s *UserService) SetOIDCService(oidcService *OIDCService) {
+	s.oidcService = oidcService
+}
+
+// SetKeySet replaces the keys that sign and verify tokens, which default to
+// an HS256 key set using the JWT secret
+func (s *UserService) SetKeySet(keys *auth.KeySet) {
+	s.keys = keys
+}
+
+// SetLockoutService enables account lockout after repeated failed logins
+func (s *UserService) SetLockoutService(lockoutService *LockoutService) {
+	s.lockoutService = lockoutService
+}
+
 // Register creates a new user account
 // First user automatically becomes admin
 // After that, registration requires allowRegistration to be true
-func (s *UserService) Register(name, email, password string) (*domain.User, string, error) {
+func (s *UserService) Register(ctx context.Context, name, email, password string) (*domain.User, string, error) {
 	// Basic input validation
 	if name == "" {
 		return nil, "", fmt.Errorf("name is required")
@@ -82,7 +169,7 @@ func (s *UserService) Register(name, email, password string) (*domain.User, stri
 		return nil, "", fmt.Errorf("password must be at least 8 characters")
 	}
 	// Check if user already exists
-	existingUser, err := s.userRepo.GetByEmail(email)
+	existingUser, err := s.userRepo.GetByEmail(ctx, email)
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to check existing user: %w", err)
 	}
@@ -91,7 +178,7 @@ func (s *UserService) Register(name, email, password string) (*domain.User, stri
 	}
 
 	// Check if this is the first user
-	count, err := s.userRepo.Count()
+	count, err := s.userRepo.Count(ctx)
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to count users: %w", err)
 	}
@@ -123,7 +210,7 @@ func (s *UserService) Register(name, email, password string) (*domain.User, stri
 		user.Role = "admin"
 	}
 
-	err = s.userRepo.Create(user)
+	err = s.userRepo.Create(ctx, user)
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to create user: %w", err)
 	}
@@ -142,14 +229,14 @@ func (s *UserService) Register(name, email, password string) (*domain.User, stri
 		user.EmailVerified = false
 
 		// Update user with verification token
-		err = s.userRepo.Update(user)
+		err = s.userRepo.Update(ctx, user)
 		if err != nil {
 			return nil, "", fmt.Errorf("failed to save verification token: %w", err)
 		}
 
 		// Send verification email
 		verifyURL := fmt.Sprintf("%s/verify-email?token=%s", s.appURL, verificationToken)
-		err = s.emailService.SendVerificationEmail(user.Email, verifyURL)
+		err = s.emailService.SendVerificationEmail(ctx, emailRecipient(user), verifyURL)
 		if err != nil {
 			// Log error but don't fail registration
 			fmt.Printf("warning: failed to send verification email: %v\n", err)
@@ -159,14 +246,14 @@ func (s *UserService) Register(name, email, password string) (*domain.User, stri
 		user.EmailVerified = true
 		verifiedAt := time.Now()
 		user.EmailVerifiedAt = &verifiedAt
-		err = s.userRepo.Update(user)
+		err = s.userRepo.Update(ctx, user)
 		if err != nil {
 			return nil, "", fmt.Errorf("failed to update user: %w", err)
 		}
 	}
 
 	// Generate JWT token
-	token, err := auth.GenerateToken(user.ID, user.Email, user.Role, s.jwtSecretKey, s.jwtExpiration)
+	token, err := auth.GenerateToken(user.ID, user.Email, user.Role, s.keys, s.jwtExpiration)
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to generate token: %w", err)
 	}
@@ -176,10 +263,13 @@ func (s *UserService) Register(name, email, password string) (*domain.User, stri
 	return user, token, nil
 }
 
-// Login authenticates a user and returns a JWT token
-func (s *UserService) Login(email, password string) (*domain.User, string, error) {
+// Login authenticates a user and returns a JWT token. If the user has
+// two-factor authentication enabled, it returns an *MFARequiredError carrying
+// a challenge token instead; finish the login with CompleteMFALogin. While the
+// account is locked after repeated failures it returns an *AccountLockedError.
+func (s *UserService) Login(ctx context.Context, email, password string) (*domain.User, string, error) {
 	// Get user by email
-	user, err := s.userRepo.GetByEmail(email)
+	user, err := s.userRepo.GetByEmail(ctx, email)
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to get user: %w", err)
 	}
@@ -187,23 +277,208 @@ func (s *UserService) Login(email, password string) (*domain.User, string, error
 		return nil, "", ErrInvalidCredentials
 	}
 
+	if s.lockoutService != nil {
+		if err := s.lockoutService.Check(ctx, user.ID); err != nil {
+			return nil, "", err
+		}
+	}
+
 	// Check password
 	err = auth.CheckPassword(user.PasswordHash, password)
 	if err != nil {
+		if s.lockoutService != nil {
+			if err := s.lockoutService.RecordFailure(ctx, user); err != nil {
+				return nil, "", err
+			}
+		}
 		return nil, "", ErrInvalidCredentials
 	}
 
+	if user.Disabled {
+		return nil, "", ErrAccountDisabled
+	}
+	// Failures are only forgotten once the second factor is also right, so
+	// repeating the password step doesn't reset wrong codes
+	if err := s.checkMFA(ctx, user); err != nil {
+		return nil, "", err
+	}
+	if err := s.resetFailedLogins(ctx, user); err != nil {
+		return nil, "", err
+	}
+
+	return s.completeLogin(ctx, user)
+}
+
+// resetFailedLogins clears a user's failed login count after a successful login
+func (s *UserService) resetFailedLogins(ctx context.Context, user *domain.User) error {
+	if s.lockoutService == nil {
+		return nil
+	}
+	if err := s.lockoutService.Reset(ctx, user.ID); err != nil {
+		return fmt.Errorf("failed to reset failed logins: %w", err)
+	}
+	return nil
+}
+
+// BeginPasskeyLogin returns WebAuthn options for logging in with a passkey.
+// The email is optional; see PasskeyService.BeginLogin.
+func (s *UserService) BeginPasskeyLogin(ctx context.Context, email string) (*webauthn.RequestOptions, error) {
+	if s.passkeyService == nil {
+		return nil, ErrPasskeysUnavailable
+	}
+	return s.passkeyService.BeginLogin(ctx, email)
+}
+
+// LoginWithPasskey logs in with a passkey assertion. A passkey that verified
+// the user (biometric or PIN) satisfies two-factor authentication; otherwise
+// users with 2FA enabled get an MFARequiredError as with password login.
+func (s *UserService) LoginWithPasskey(ctx context.Context, resp *webauthn.AuthenticationResponse) (*domain.User, string, error) {
+	if s.passkeyService == nil {
+		return nil, "", ErrPasskeysUnavailable
+	}
+
+	user, userVerified, err := s.passkeyService.FinishLogin(ctx, resp)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if user.Disabled {
+		return nil, "", ErrAccountDisabled
+	}
+	if !userVerified {
+		if err := s.checkMFA(ctx, user); err != nil {
+			return nil, "", err
+		}
+	}
+
+	return s.completeLogin(ctx, user)
+}
+
+// BeginOIDCLogin starts a single sign-on login and returns the identity
+// provider URL to send the user to
+func (s *UserService) BeginOIDCLogin(ctx context.Context) (string, error) {
+	if s.oidcService == nil {
+		return "", ErrOIDCUnavailable
+	}
+	return s.oidcService.BeginLogin(ctx)
+}
+
+// LoginWithOIDC completes a single sign-on login with the code and state the
+// identity provider redirected back with. Users with 2FA enabled get an
+// MFARequiredError as with password login.
+func (s *UserService) LoginWithOIDC(ctx context.Context, code, state string) (*domain.User, string, error) {
+	if s.oidcService == nil {
+		return nil, "", ErrOIDCUnavailable
+	}
+
+	user, err := s.oidcService.Authenticate(ctx, code, state)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if user.Disabled {
+		return nil, "", ErrAccountDisabled
+	}
+	if err := s.checkMFA(ctx, user); err != nil {
+		return nil, "", err
+	}
+
+	return s.completeLogin(ctx, user)
+}
+
+// ListIdentities returns the identity provider accounts linked to a user
+func (s *UserService) ListIdentities(ctx context.Context, userID int64) ([]*domain.UserIdentity, error) {
+	if s.oidcService == nil {
+		return []*domain.UserIdentity{}, nil
+	}
+	return s.oidcService.ListIdentities(ctx, userID)
+}
+
+// checkMFA returns an MFARequiredError with a challenge token if the user has
+// two-factor authentication enabled
+func (s *UserService) checkMFA(ctx context.Context, user *domain.User) error {
+	if s.mfaService == nil {
+		return nil
+	}
+
+	enabled, err := s.mfaService.IsEnabled(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to check two-factor authentication: %w", err)
+	}
+	if !enabled {
+		return nil
+	}
+
+	challenge, err := auth.GenerateMFAToken(user.ID, user.Email, user.Role, s.keys, mfaChallengeExpiration)
+	if err != nil {
+		return fmt.Errorf("failed to generate MFA token: %w", err)
+	}
+	return &MFARequiredError{Token: challenge}
+}
+
+// CompleteMFALogin finishes a two-factor login with the challenge token from
+// Login and a TOTP or recovery code. Wrong codes count toward the account
+// lockout like wrong passwords, and a challenge token is rejected after
+// mfaChallengeMaxFailures wrong codes.
+func (s *UserService) CompleteMFALogin(ctx context.Context, mfaToken, code string) (*domain.User, string, error) {
+	if s.mfaService == nil {
+		return nil, "", ErrMFANotEnabled
+	}
+
+	claims, err := auth.ValidateMFAToken(mfaToken, s.keys)
+	if err != nil || claims.ID == "" || s.mfaChallenges.exhausted(claims.ID) {
+		return nil, "", ErrInvalidMFAToken
+	}
+
+	user, err := s.userRepo.GetByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil, "", ErrInvalidMFAToken
+	}
+
+	if s.lockoutService != nil {
+		if err := s.lockoutService.Check(ctx, user.ID); err != nil {
+			return nil, "", err
+		}
+	}
+
+	if err := s.mfaService.Verify(ctx, user.ID, code); err != nil {
+		if !errors.Is(err, ErrInvalidMFACode) {
+			return nil, "", err
+		}
+		s.mfaChallenges.record(claims.ID, claims.ExpiresAt.Time)
+		if s.lockoutService != nil {
+			if err := s.lockoutService.RecordFailure(ctx, user); err != nil {
+				return nil, "", err
+			}
+		}
+		return nil, "", err
+	}
+
+	if err := s.resetFailedLogins(ctx, user); err != nil {
+		return nil, "", err
+	}
+	return s.completeLogin(ctx, user)
+}
+
+// completeLogin records the login and issues an access token
+func (s *UserService) completeLogin(ctx context.Context, user *domain.User) (*domain.User, string, error) {
+	if user.Disabled {
+		return nil, "", ErrAccountDisabled
+	}
+
 	// Update last login time
 	now := time.Now()
 	user.LastLoginAt = &now
-	err = s.userRepo.Update(user)
-	if err != nil {
+	if err := s.userRepo.Update(ctx, user); err != nil {
 		// Log error but don't fail login
 		fmt.Printf("warning: failed to update last login: %v\n", err)
 	}
 
 	// Generate JWT token
-	token, err := auth.GenerateToken(user.ID, user.Email, user.Role, s.jwtSecretKey, s.jwtExpiration)
+	token, err := auth.GenerateToken(user.ID, user.Email, user.Role, s.keys, s.jwtExpiration)
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to generate token: %w", err)
 	}
@@ -215,8 +490,8 @@ func (s *UserService) Login(email, password string) (*domain.User, string, error
 }
 
 // GetByID retrieves a user by ID
-func (s *UserService) GetByID(id int64) (*domain.User, error) {
-	user, err := s.userRepo.GetByID(id)
+func (s *UserService) GetByID(ctx context.Context, id int64) (*domain.User, error) {
+	user, err := s.userRepo.GetByID(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
@@ -232,17 +507,30 @@ func (s *UserService) GetByID(id int64) (*domain.User, error) {
 
 // ValidateToken validates a JWT token and returns user info
 func (s *UserService) ValidateToken(tokenString string) (*auth.Claims, error) {
-	claims, err := auth.ValidateToken(tokenString, s.jwtSecretKey)
+	claims, err := auth.ValidateToken(tokenString, s.keys)
 	if err != nil {
 		return nil, err
 	}
 	return claims, nil
 }
 
+// AccountStatus reports whether a user still exists and isn't disabled, and
+// the user's current role. It implements middleware.AccountChecker.
+func (s *UserService) AccountStatus(ctx context.Context, userID int64) (active bool, role string, err error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return false, "", fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil || user.Disabled {
+		return false, "", nil
+	}
+	return true, user.Role, nil
+}
+
 // RequestPasswordReset generates a reset token and sends reset email
-func (s *UserService) RequestPasswordReset(email string) error {
+func (s *UserService) RequestPasswordReset(ctx context.Context, email string) error {
 	// Get user by email
-	user, err := s.userRepo.GetByEmail(email)
+	user, err := s.userRepo.GetByEmail(ctx, email)
 	if err != nil {
 		return fmt.Errorf("failed to get user: %w", err)
 	}
@@ -266,7 +554,7 @@ func (s *UserService) RequestPasswordReset(email string) error {
 	user.ResetToken = &token
 	user.ResetTokenExpiresAt = &expiresAt
 
-	err = s.userRepo.Update(user)
+	err = s.userRepo.Update(ctx, user)
 	if err != nil {
 		return fmt.Errorf("failed to save reset token: %w", err)
 	}
@@ -274,7 +562,7 @@ func (s *UserService) RequestPasswordReset(email string) error {
 	// Send password reset email
 	if s.emailService != nil {
 		resetURL := fmt.Sprintf("%s/reset-password/%s", s.appURL, token)
-		err = s.emailService.SendPasswordResetEmail(user.Email, resetURL)
+		err = s.emailService.SendPasswordResetEmail(ctx, emailRecipient(user), resetURL)
 		if err != nil {
 			return fmt.Errorf("failed to send reset email: %w", err)
 		}
@@ -283,26 +571,27 @@ func (s *UserService) RequestPasswordReset(email string) error {
 	return nil
 }
 
-// ResetPassword validates reset token and updates password
-func (s *UserService) ResetPassword(token, newPassword string) error {
+// ResetPassword validates reset token and updates password. It returns the
+// ID of the user whose password was reset.
+func (s *UserService) ResetPassword(ctx context.Context, token, newPassword string) (int64, error) {
 	// Get user by reset token
-	user, err := s.userRepo.GetByResetToken(token)
+	user, err := s.userRepo.GetByResetToken(ctx, token)
 	if err != nil {
-		return fmt.Errorf("failed to get user: %w", err)
+		return 0, fmt.Errorf("failed to get user: %w", err)
 	}
 	if user == nil {
-		return ErrInvalidResetToken
+		return 0, ErrInvalidResetToken
 	}
 
 	// Check if token is expired
 	if user.ResetTokenExpiresAt == nil || time.Now().After(*user.ResetTokenExpiresAt) {
-		return ErrResetTokenExpired
+		return 0, ErrResetTokenExpired
 	}
 
 	// Hash new password
 	hashedPassword, err := auth.HashPassword(newPassword)
 	if err != nil {
-		return fmt.Errorf("failed to hash password: %w", err)
+		return 0, fmt.Errorf("failed to hash password: %w", err)
 	}
 
 	// Update password and clear reset token
@@ -310,12 +599,19 @@ func (s *UserService) ResetPassword(token, newPassword string) error {
 	user.ResetToken = nil
 	user.ResetTokenExpiresAt = nil
 
-	err = s.userRepo.Update(user)
+	err = s.userRepo.Update(ctx, user)
 	if err != nil {
-		return fmt.Errorf("failed to update password: %w", err)
+		return 0, fmt.Errorf("failed to update password: %w", err)
 	}
 
-	return nil
+	// Proving control of the email address unlocks the account
+	if s.lockoutService != nil {
+		if err := s.lockoutService.Reset(ctx, user.ID); err != nil {
+			return 0, fmt.Errorf("failed to unlock account: %w", err)
+		}
+	}
+
+	return user.ID, nil
 }
 
 // generateResetToken generates a cryptographically secure random token
@@ -337,9 +633,9 @@ func generateVerificationToken() (string, error) {
 }
 
 // VerifyEmail validates verification token and marks email as verified
-func (s *UserService) VerifyEmail(token string) error {
+func (s *UserService) VerifyEmail(ctx context.Context, token string) error {
 	// Get user by verification token
-	user, err := s.userRepo.GetByVerificationToken(token)
+	user, err := s.userRepo.GetByVerificationToken(ctx, token)
 	if err != nil {
 		return fmt.Errorf("failed to get user: %w", err)
 	}
@@ -364,7 +660,7 @@ func (s *UserService) VerifyEmail(token string) error {
 	user.VerificationToken = nil
 	user.VerificationTokenExpiresAt = nil
 
-	err = s.userRepo.Update(user)
+	err = s.userRepo.Update(ctx, user)
 	if err != nil {
 		return fmt.Errorf("failed to update user: %w", err)
 	}
@@ -373,9 +669,9 @@ func (s *UserService) VerifyEmail(token string) error {
 }
 
 // ChangePassword changes a user's password after validating the old password
-func (s *UserService) ChangePassword(userID int64, oldPassword, newPassword string) error {
+func (s *UserService) ChangePassword(ctx context.Context, userID int64, oldPassword, newPassword string) error {
 	// Get user
-	user, err := s.userRepo.GetByID(userID)
+	user, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
 		return err
 	}
@@ -395,13 +691,13 @@ func (s *UserService) ChangePassword(userID int64, oldPassword, newPassword stri
 	}
 
 	// Update password
-	return s.userRepo.UpdatePassword(userID, hashedPassword)
+	return s.userRepo.UpdatePassword(ctx, userID, hashedPassword)
 }
 
 // ResendVerificationEmail resends verification email to a user
-func (s *UserService) ResendVerificationEmail(email string) error {
+func (s *UserService) ResendVerificationEmail(ctx context.Context, email string) error {
 	// Get user by email
-	user, err := s.userRepo.GetByEmail(email)
+	user, err := s.userRepo.GetByEmail(ctx, email)
 	if err != nil {
 		return fmt.Errorf("failed to get user: %w", err)
 	}
@@ -428,7 +724,7 @@ func (s *UserService) ResendVerificationEmail(email string) error {
 	user.VerificationTokenExpiresAt = &expiresAt
 
 	// Update user with new verification token
-	err = s.userRepo.Update(user)
+	err = s.userRepo.Update(ctx, user)
 	if err != nil {
 		return fmt.Errorf("failed to save verification token: %w", err)
 	}
@@ -436,7 +732,7 @@ func (s *UserService) ResendVerificationEmail(email string) error {
 	// Send verification email
 	if s.emailService != nil {
 		verifyURL := fmt.Sprintf("%s/verify-email?token=%s", s.appURL, verificationToken)
-		err = s.emailService.SendVerificationEmail(user.Email, verifyURL)
+		err = s.emailService.SendVerificationEmail(ctx, emailRecipient(user), verifyURL)
 		if err != nil {
 			return fmt.Errorf("failed to send verification email: %w", err)
 		}
@@ -445,8 +741,9 @@ func (s *UserService) ResendVerificationEmail(email string) error {
 	return nil
 }
 
-// CreateRefreshToken creates a new refresh token for a user
-func (s *UserService) CreateRefreshToken(userID int64, deviceInfo string) (string, error) {
+// CreateRefreshToken creates a new refresh token for a user, starting a
+// remembered session on the device
+func (s *UserService) CreateRefreshToken(ctx context.Context, userID int64, deviceInfo, ipAddress string) (string, error) {
 	// Generate secure random token
 	tokenStr, err := generateRefreshToken()
 	if err != nil {
@@ -454,15 +751,18 @@ func (s *UserService) CreateRefreshToken(userID int64, deviceInfo string) (strin
 	}
 
 	// Create refresh token record
+	now := time.Now()
 	refreshToken := &domain.RefreshToken{
 		UserID:     userID,
 		Token:      tokenStr,
-		ExpiresAt:  time.Now().Add(s.refreshTokenDuration),
-		CreatedAt:  time.Now(),
+		ExpiresAt:  now.Add(s.refreshTokenDuration),
+		CreatedAt:  now,
 		DeviceInfo: deviceInfo,
+		IPAddress:  ipAddress,
+		SignedInAt: now,
 	}
 
-	err = s.refreshTokenRepo.Create(refreshToken)
+	err = s.refreshTokenRepo.Create(ctx, refreshToken)
 	if err != nil {
 		return "", fmt.Errorf("failed to create refresh token: %w", err)
 	}
@@ -470,47 +770,91 @@ func (s *UserService) CreateRefreshToken(userID int64, deviceInfo string) (strin
 	return tokenStr, nil
 }
 
-// RefreshAccessToken validates refresh token and generates new access token
-func (s *UserService) RefreshAccessToken(refreshTokenStr string) (*domain.User, string, error) {
+// RefreshAccessToken validates a refresh token and generates a new access
+// token and a replacement refresh token. Each refresh token can be used once:
+// presenting one that was already used means it was copied, so the whole
+// session is revoked and ErrRefreshTokenReused is returned.
+func (s *UserService) RefreshAccessToken(ctx context.Context, refreshTokenStr, ipAddress string) (*domain.User, string, string, error) {
 	// Get refresh token from database
-	refreshToken, err := s.refreshTokenRepo.GetByToken(refreshTokenStr)
+	refreshToken, err := s.refreshTokenRepo.GetByTokenIncludingRevoked(ctx, refreshTokenStr)
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to get refresh token: %w", err)
+		return nil, "", "", fmt.Errorf("failed to get refresh token: %w", err)
 	}
 	if refreshToken == nil {
-		return nil, "", ErrInvalidRefreshToken
+		return nil, "", "", ErrInvalidRefreshToken
+	}
+	if refreshToken.RevokedAt != nil {
+		return nil, "", "", s.revokeReusedSession(ctx, refreshToken)
+	}
+	now := time.Now()
+	if !refreshToken.ExpiresAt.After(now) {
+		return nil, "", "", ErrInvalidRefreshToken
 	}
 
 	// Get user
-	user, err := s.userRepo.GetByID(refreshToken.UserID)
+	user, err := s.userRepo.GetByID(ctx, refreshToken.UserID)
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to get user: %w", err)
+		return nil, "", "", fmt.Errorf("failed to get user: %w", err)
 	}
 	if user == nil {
-		return nil, "", ErrUserNotFound
+		return nil, "", "", ErrUserNotFound
+	}
+	if user.Disabled {
+		return nil, "", "", ErrAccountDisabled
+	}
+
+	// Rotate the refresh token; the session keeps its original expiry
+	nextTokenStr, err := generateRefreshToken()
+	if err != nil {
+		return nil, "", "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	next := &domain.RefreshToken{
+		UserID:     refreshToken.UserID,
+		FamilyID:   refreshToken.FamilyID,
+		Token:      nextTokenStr,
+		ExpiresAt:  refreshToken.ExpiresAt,
+		CreatedAt:  now,
+		DeviceInfo: refreshToken.DeviceInfo,
+		IPAddress:  ipAddress,
+		SignedInAt: refreshToken.SignedInAt,
+	}
+	rotated, err := s.refreshTokenRepo.Rotate(ctx, refreshToken, next)
+	if err != nil {
+		return nil, "", "", fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+	if !rotated {
+		// Someone else used the same token first
+		return nil, "", "", s.revokeReusedSession(ctx, refreshToken)
 	}
 
 	// Generate new JWT access token
-	token, err := auth.GenerateToken(user.ID, user.Email, user.Role, s.jwtSecretKey, s.jwtExpiration)
+	token, err := auth.GenerateToken(user.ID, user.Email, user.Role, s.keys, s.jwtExpiration)
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to generate JWT: %w", err)
+		return nil, "", "", fmt.Errorf("failed to generate JWT: %w", err)
 	}
 
 	// Update last login
-	now := time.Now()
 	user.LastLoginAt = &now
-	err = s.userRepo.Update(user)
+	err = s.userRepo.Update(ctx, user)
 	if err != nil {
 		// Non-critical error, log but don't fail
 		fmt.Printf("Warning: failed to update last login: %v\n", err)
 	}
 
-	return user, token, nil
+	return user, token, nextTokenStr, nil
+}
+
+// revokeReusedSession revokes the session of a refresh token that was used twice
+func (s *UserService) revokeReusedSession(ctx context.Context, refreshToken *domain.RefreshToken) error {
+	if _, err := s.refreshTokenRepo.RevokeFamily(ctx, refreshToken.FamilyID, refreshToken.UserID); err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	return ErrRefreshTokenReused
 }
 
 // RevokeRefreshToken revokes a specific refresh token
-func (s *UserService) RevokeRefreshToken(tokenStr string) error {
-	refreshToken, err := s.refreshTokenRepo.GetByToken(tokenStr)
+func (s *UserService) RevokeRefreshToken(ctx context.Context, tokenStr string) error {
+	refreshToken, err := s.refreshTokenRepo.GetByToken(ctx, tokenStr)
 	if err != nil {
 		return fmt.Errorf("failed to get refresh token: %w", err)
 	}
@@ -518,7 +862,7 @@ func (s *UserService) RevokeRefreshToken(tokenStr string) error {
 		return ErrInvalidRefreshToken
 	}
 
-	err = s.refreshTokenRepo.Revoke(refreshToken.ID)
+	err = s.refreshTokenRepo.Revoke(ctx, refreshToken.ID)
 	if err != nil {
 		return fmt.Errorf("failed to revoke refresh token: %w", err)
 	}
@@ -527,8 +871,8 @@ func (s *UserService) RevokeRefreshToken(tokenStr string) error {
 }
 
 // RevokeAllRefreshTokens revokes all refresh tokens for a user (logout all devices)
-func (s *UserService) RevokeAllRefreshTokens(userID int64) error {
-	err := s.refreshTokenRepo.RevokeAllForUser(userID)
+func (s *UserService) RevokeAllRefreshTokens(ctx context.Context, userID int64) error {
+	err := s.refreshTokenRepo.RevokeAllForUser(ctx, userID)
 	if err != nil {
 		return fmt.Errorf("failed to revoke all refresh tokens: %w", err)
 	}
@@ -536,18 +880,63 @@ func (s *UserService) RevokeAllRefreshTokens(userID int64) error {
 }
 
 // GetUserRefreshTokens gets all active refresh tokens for a user
-func (s *UserService) GetUserRefreshTokens(userID int64) ([]*domain.RefreshToken, error) {
-	tokens, err := s.refreshTokenRepo.GetByUserID(userID)
+func (s *UserService) GetUserRefreshTokens(ctx context.Context, userID int64) ([]*domain.RefreshToken, error) {
+	tokens, err := s.refreshTokenRepo.GetByUserID(ctx, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user refresh tokens: %w", err)
 	}
 	return tokens, nil
 }
 
-// UpdateProfile updates user profile information
-func (s *UserService) UpdateProfile(userID int64, name, email string, birthday *time.Time) (*domain.User, error) {
+// ListSessions returns the user's remembered sessions, most recently used first
+func (s *UserService) ListSessions(ctx context.Context, userID int64) ([]*domain.Session, error) {
+	tokens, err := s.GetUserRefreshTokens(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	sessions := []*domain.Session{}
+	for _, token := range tokens {
+		if !token.ExpiresAt.After(now) {
+			continue
+		}
+		sessions = append(sessions, &domain.Session{
+			ID:         token.FamilyID,
+			DeviceInfo: token.DeviceInfo,
+			IPAddress:  token.IPAddress,
+			SignedInAt: token.SignedInAt,
+			LastUsedAt: token.CreatedAt,
+			ExpiresAt:  token.ExpiresAt,
+		})
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastUsedAt.After(sessions[j].LastUsedAt)
+	})
+	return sessions, nil
+}
+
+// RevokeSession signs a user out of one remembered session
+func (s *UserService) RevokeSession(ctx context.Context, userID, sessionID int64) error {
+	revoked, err := s.refreshTokenRepo.RevokeFamily(ctx, sessionID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	if !revoked {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+// UpdateProfile updates user profile information. An empty locale leaves the
+// current locale unchanged.
+func (s *UserService) UpdateProfile(ctx context.Context, userID int64, name, email string, birthday *time.Time, locale string) (*domain.User, error) {
+	if locale != "" && !localePattern.MatchString(locale) {
+		return nil, ErrInvalidLocale
+	}
+
 	// Get current user
-	user, err := s.userRepo.GetByID(userID)
+	user, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
@@ -558,7 +947,7 @@ func (s *UserService) UpdateProfile(userID int64, name, email string, birthday *
 	// Check if email is being changed
 	if email != "" && email != user.Email {
 		// Check if new email already exists
-		existingUser, err := s.userRepo.GetByEmail(email)
+		existingUser, err := s.userRepo.GetByEmail(ctx, email)
 		if err != nil {
 			return nil, fmt.Errorf("failed to check email: %w", err)
 		}
@@ -573,7 +962,7 @@ func (s *UserService) UpdateProfile(userID int64, name, email string, birthday *
 
 		// Send new verification email
 		if s.emailService != nil {
-			err = s.ResendVerificationEmail(email)
+			err = s.ResendVerificationEmail(ctx, email)
 			if err != nil {
 				// Log error but don't fail the update
 				fmt.Printf("Warning: failed to send verification email: %v\n", err)
@@ -589,11 +978,16 @@ func (s *UserService) UpdateProfile(userID int64, name, email string, birthday *
 	// Update birthday if provided
 	user.Birthday = birthday
 
+	// Update locale if provided
+	if locale != "" {
+		user.Locale = locale
+	}
+
 	// Update timestamp
 	user.UpdatedAt = time.Now()
 
 	// Save changes
-	err = s.userRepo.Update(user)
+	err = s.userRepo.Update(ctx, user)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update user: %w", err)
 	}
@@ -605,8 +999,8 @@ func (s *UserService) UpdateProfile(userID int64, name, email string, birthday *
 }
 
 // UpdateAvatar updates the user's avatar URL
-func (s *UserService) UpdateAvatar(userID int64, avatarURL string) error {
-	user, err := s.userRepo.GetByID(userID)
+func (s *UserService) UpdateAvatar(ctx context.Context, userID int64, avatarURL string) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
 		return err
 	}
@@ -621,7 +1015,7 @@ func (s *UserService) UpdateAvatar(userID int64, avatarURL string) error {
 	}
 
 	user.UpdatedAt = time.Now()
-	return s.userRepo.Update(user)
+	return s.userRepo.Update(ctx, user)
 }
 
 // generateRefreshToken generates a cryptographically secure random token
@@ -632,3 +1026,12 @@ func generateRefreshToken() (string, error) {
 	}
 	return hex.EncodeToString(bytes), nil
 }
+
+// emailRecipient addresses an email to the user in their preferred locale
+func emailRecipient(user *domain.User) email.Recipient {
+	return email.Recipient{
+		Email:  user.Email,
+		Name:   user.Name,
+		Locale: user.Locale,
+	}
+}
@@ -1,48 +1,57 @@
 package service
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"sort"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/johnzastrow/actalog/internal/domain"
 	"github.com/johnzastrow/actalog/pkg/auth"
+	"github.com/johnzastrow/actalog/pkg/email"
 )
 
 // Mock user repository
+// mockUserRepo copies users on create, read and update, like a database
+// would, so callers clearing fields (e.g. PasswordHash) don't change the stored user
 type mockUserRepo struct {
 	users  map[int64]*domain.User
 	nextID int64
 }
 
-func (m *mockUserRepo) Create(user *domain.User) error {
+func (m *mockUserRepo) Create(ctx context.Context, user *domain.User) error {
 	m.nextID++
 	user.ID = m.nextID
 	user.CreatedAt = time.Now()
 	user.UpdatedAt = time.Now()
-	m.users[user.ID] = user
+	copied := *user
+	m.users[user.ID] = &copied
 	return nil
 }
 
-func (m *mockUserRepo) GetByID(id int64) (*domain.User, error) {
+func (m *mockUserRepo) GetByID(ctx context.Context, id int64) (*domain.User, error) {
 	user, ok := m.users[id]
 	if !ok {
 		return nil, nil
 	}
-	return user, nil
+	copied := *user
+	return &copied, nil
 }
 
-func (m *mockUserRepo) GetByEmail(email string) (*domain.User, error) {
+func (m *mockUserRepo) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
 	for _, user := range m.users {
 		if user.Email == email {
-			return user, nil
+			copied := *user
+			return &copied, nil
 		}
 	}
 	return nil, nil
 }
 
-func (m *mockUserRepo) GetByResetToken(token string) (*domain.User, error) {
+func (m *mockUserRepo) GetByResetToken(ctx context.Context, token string) (*domain.User, error) {
 	for _, user := range m.users {
 		if user.ResetToken != nil && *user.ResetToken == token {
 			return user, nil
@@ -51,7 +60,7 @@ func (m *mockUserRepo) GetByResetToken(token string) (*domain.User, error) {
 	return nil, nil
 }
 
-func (m *mockUserRepo) GetByVerificationToken(token string) (*domain.User, error) {
+func (m *mockUserRepo) GetByVerificationToken(ctx context.Context, token string) (*domain.User, error) {
 	for _, user := range m.users {
 		if user.VerificationToken != nil && *user.VerificationToken == token {
 			return user, nil
@@ -60,16 +69,27 @@ func (m *mockUserRepo) GetByVerificationToken(token string) (*domain.User, error
 	return nil, nil
 }
 
-func (m *mockUserRepo) Update(user *domain.User) error {
+func (m *mockUserRepo) Update(ctx context.Context, user *domain.User) error {
 	if _, ok := m.users[user.ID]; !ok {
 		return sql.ErrNoRows
 	}
 	user.UpdatedAt = time.Now()
-	m.users[user.ID] = user
+	copied := *user
+	m.users[user.ID] = &copied
+	return nil
+}
+
+func (m *mockUserRepo) UpdatePassword(ctx context.Context, userID int64, hashedPassword string) error {
+	user, ok := m.users[userID]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	user.PasswordHash = hashedPassword
+	user.UpdatedAt = time.Now()
 	return nil
 }
 
-func (m *mockUserRepo) Delete(id int64) error {
+func (m *mockUserRepo) Delete(ctx context.Context, id int64) error {
 	if _, ok := m.users[id]; !ok {
 		return sql.ErrNoRows
 	}
@@ -77,7 +97,7 @@ func (m *mockUserRepo) Delete(id int64) error {
 	return nil
 }
 
-func (m *mockUserRepo) List(limit, offset int) ([]*domain.User, error) {
+func (m *mockUserRepo) List(ctx context.Context, limit, offset int) ([]*domain.User, error) {
 	var users []*domain.User
 	for _, user := range m.users {
 		users = append(users, user)
@@ -85,10 +105,38 @@ func (m *mockUserRepo) List(limit, offset int) ([]*domain.User, error) {
 	return users, nil
 }
 
-func (m *mockUserRepo) Count() (int64, error) {
+func (m *mockUserRepo) Count(ctx context.Context) (int64, error) {
 	return int64(len(m.users)), nil
 }
 
+func (m *mockUserRepo) Search(ctx context.Context, filter domain.UserFilter) ([]*domain.User, int64, error) {
+	var matched []*domain.User
+	for _, user := range m.users {
+		if filter.Query != "" && !strings.Contains(strings.ToLower(user.Email+" "+user.Name), strings.ToLower(filter.Query)) {
+			continue
+		}
+		if filter.Role != "" && user.Role != filter.Role {
+			continue
+		}
+		if filter.Disabled != nil && user.Disabled != *filter.Disabled {
+			continue
+		}
+		copied := *user
+		matched = append(matched, &copied)
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].ID > matched[j].ID })
+
+	total := int64(len(matched))
+	if filter.Offset >= len(matched) {
+		return nil, total, nil
+	}
+	matched = matched[filter.Offset:]
+	if filter.Limit > 0 && filter.Limit < len(matched) {
+		matched = matched[:filter.Limit]
+	}
+	return matched, total, nil
+}
+
 // Mock email service
 type mockEmailService struct {
 	sentEmails []mockEmail
@@ -96,22 +144,34 @@ type mockEmailService struct {
 
 type mockEmail struct {
 	to      string
+	locale  string
 	subject string
 	body    string
 }
 
-func (m *mockEmailService) SendPasswordResetEmail(to, resetURL string) error {
+func (m *mockEmailService) SendPasswordResetEmail(ctx context.Context, to email.Recipient, resetURL string) error {
 	m.sentEmails = append(m.sentEmails, mockEmail{
-		to:      to,
+		to:      to.Email,
+		locale:  to.Locale,
 		subject: "Password Reset",
 		body:    resetURL,
 	})
 	return nil
 }
-func (m *mockEmailService) SendVerificationEmail(to, verifyURL string) error {
+func (m *mockEmailService) SendTemplate(ctx context.Context, name string, to email.Recipient, data map[string]interface{}) error {
+	m.sentEmails = append(m.sentEmails, mockEmail{
+		to:      to.Email,
+		locale:  to.Locale,
+		subject: name,
+	})
+	return nil
+}
+
+func (m *mockEmailService) SendVerificationEmail(ctx context.Context, to email.Recipient, verifyURL string) error {
 	// For tests we just record as a sent email (reuse subject)
 	m.sentEmails = append(m.sentEmails, mockEmail{
-		to:      to,
+		to:      to.Email,
+		locale:  to.Locale,
 		subject: "Verify Email",
 		body:    verifyURL,
 	})
@@ -121,44 +181,86 @@ func (m *mockEmailService) SendVerificationEmail(to, verifyURL string) error {
 // Mock refresh token repository
 type mockRefreshTokenRepo struct {
 	tokens map[string]*domain.RefreshToken
+	nextID int64
 }
 
-func (m *mockRefreshTokenRepo) Create(token *domain.RefreshToken) error {
+func (m *mockRefreshTokenRepo) Create(ctx context.Context, token *domain.RefreshToken) error {
 	if m.tokens == nil {
 		m.tokens = make(map[string]*domain.RefreshToken)
 	}
-	m.tokens[token.Token] = token
+	m.nextID++
+	token.ID = m.nextID
+	if token.FamilyID == 0 {
+		token.FamilyID = token.ID
+	}
+	copied := *token
+	m.tokens[token.Token] = &copied
 	return nil
 }
 
-func (m *mockRefreshTokenRepo) GetByToken(token string) (*domain.RefreshToken, error) {
+func (m *mockRefreshTokenRepo) GetByToken(ctx context.Context, token string) (*domain.RefreshToken, error) {
+	if t, ok := m.tokens[token]; ok && t.RevokedAt == nil && t.ExpiresAt.After(time.Now()) {
+		copied := *t
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (m *mockRefreshTokenRepo) GetByTokenIncludingRevoked(ctx context.Context, token string) (*domain.RefreshToken, error) {
 	if t, ok := m.tokens[token]; ok {
-		return t, nil
+		copied := *t
+		return &copied, nil
 	}
-	return nil, sql.ErrNoRows
+	return nil, nil
 }
 
-func (m *mockRefreshTokenRepo) Revoke(tokenID int64) error {
-	// Find token by ID and remove
-	for k, t := range m.tokens {
+func (m *mockRefreshTokenRepo) Rotate(ctx context.Context, old, next *domain.RefreshToken) (bool, error) {
+	for _, t := range m.tokens {
+		if t.ID == old.ID {
+			if t.RevokedAt != nil {
+				return false, nil
+			}
+			now := time.Now()
+			t.RevokedAt = &now
+			return true, m.Create(ctx, next)
+		}
+	}
+	return false, nil
+}
+
+func (m *mockRefreshTokenRepo) Revoke(ctx context.Context, tokenID int64) error {
+	now := time.Now()
+	for _, t := range m.tokens {
 		if t.ID == tokenID {
-			delete(m.tokens, k)
-			return nil
+			t.RevokedAt = &now
 		}
 	}
 	return nil
 }
 
-func (m *mockRefreshTokenRepo) RevokeAllForUser(userID int64) error {
-	for k, t := range m.tokens {
-		if t.UserID == userID {
-			delete(m.tokens, k)
+func (m *mockRefreshTokenRepo) RevokeFamily(ctx context.Context, familyID, userID int64) (bool, error) {
+	now := time.Now()
+	revoked := false
+	for _, t := range m.tokens {
+		if t.FamilyID == familyID && t.UserID == userID && t.RevokedAt == nil {
+			t.RevokedAt = &now
+			revoked = true
+		}
+	}
+	return revoked, nil
+}
+
+func (m *mockRefreshTokenRepo) RevokeAllForUser(ctx context.Context, userID int64) error {
+	now := time.Now()
+	for _, t := range m.tokens {
+		if t.UserID == userID && t.RevokedAt == nil {
+			t.RevokedAt = &now
 		}
 	}
 	return nil
 }
 
-func (m *mockRefreshTokenRepo) DeleteExpired() error {
+func (m *mockRefreshTokenRepo) DeleteExpired(ctx context.Context) error {
 	now := time.Now()
 	for k, t := range m.tokens {
 		if t.ExpiresAt.Before(now) {
@@ -168,7 +270,7 @@ func (m *mockRefreshTokenRepo) DeleteExpired() error {
 	return nil
 }
 
-func (m *mockRefreshTokenRepo) Delete(tokenID int64) error {
+func (m *mockRefreshTokenRepo) Delete(ctx context.Context, tokenID int64) error {
 	for k, t := range m.tokens {
 		if t.ID == tokenID {
 			delete(m.tokens, k)
@@ -177,11 +279,13 @@ func (m *mockRefreshTokenRepo) Delete(tokenID int64) error {
 	}
 	return nil
 }
-func (m *mockRefreshTokenRepo) GetByUserID(userID int64) ([]*domain.RefreshToken, error) {
+
+func (m *mockRefreshTokenRepo) GetByUserID(ctx context.Context, userID int64) ([]*domain.RefreshToken, error) {
 	var out []*domain.RefreshToken
 	for _, t := range m.tokens {
-		if t.UserID == userID {
-			out = append(out, t)
+		if t.UserID == userID && t.RevokedAt == nil {
+			copied := *t
+			out = append(out, &copied)
 		}
 	}
 	return out, nil
@@ -257,7 +361,7 @@ func TestRegister(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			user, token, err := service.Register(tt.userName, tt.email, tt.password)
+			user, token, err := service.Register(context.Background(), tt.userName, tt.email, tt.password)
 
 			if tt.expectError {
 				if err == nil {
@@ -301,7 +405,7 @@ func TestFirstUserBecomesAdmin(t *testing.T) {
 	service := newTestUserService(true)
 
 	// Register first user
-	user1, _, err := service.Register("Admin User", "admin@example.com", "Password123")
+	user1, _, err := service.Register(context.Background(), "Admin User", "admin@example.com", "Password123")
 	if err != nil {
 		t.Fatalf("Failed to register first user: %v", err)
 	}
@@ -311,7 +415,7 @@ func TestFirstUserBecomesAdmin(t *testing.T) {
 	}
 
 	// Register second user
-	user2, _, err := service.Register("Regular User", "user@example.com", "Password123")
+	user2, _, err := service.Register(context.Background(), "Regular User", "user@example.com", "Password123")
 	if err != nil {
 		t.Fatalf("Failed to register second user: %v", err)
 	}
@@ -326,13 +430,13 @@ func TestDuplicateEmailRegistration(t *testing.T) {
 	service := newTestUserService(true)
 
 	// Register first user
-	_, _, err := service.Register("User One", "test@example.com", "Password123")
+	_, _, err := service.Register(context.Background(), "User One", "test@example.com", "Password123")
 	if err != nil {
 		t.Fatalf("Failed to register first user: %v", err)
 	}
 
 	// Try to register with same email
-	_, _, err = service.Register("User Two", "test@example.com", "Password123")
+	_, _, err = service.Register(context.Background(), "User Two", "test@example.com", "Password123")
 	if err != ErrEmailAlreadyExists {
 		t.Errorf("Expected ErrEmailAlreadyExists, got: %v", err)
 	}
@@ -343,7 +447,7 @@ func TestRegistrationClosed(t *testing.T) {
 	// First user (admin) can register
 	service := newTestUserService(false)
 
-	user1, _, err := service.Register("Admin", "admin@example.com", "Password123")
+	user1, _, err := service.Register(context.Background(), "Admin", "admin@example.com", "Password123")
 	if err != nil {
 		t.Fatalf("First user should be able to register: %v", err)
 	}
@@ -353,7 +457,7 @@ func TestRegistrationClosed(t *testing.T) {
 	}
 
 	// Second user cannot register when registration is closed
-	_, _, err = service.Register("User", "user@example.com", "Password123")
+	_, _, err = service.Register(context.Background(), "User", "user@example.com", "Password123")
 	if err != ErrRegistrationClosed {
 		t.Errorf("Expected ErrRegistrationClosed, got: %v", err)
 	}
@@ -366,7 +470,7 @@ func TestLogin(t *testing.T) {
 	// Register a user
 	email := "test@example.com"
 	password := "Password123"
-	_, _, err := service.Register("Test User", email, password)
+	_, _, err := service.Register(context.Background(), "Test User", email, password)
 	if err != nil {
 		t.Fatalf("Failed to register user: %v", err)
 	}
@@ -399,7 +503,7 @@ func TestLogin(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			user, token, err := service.Login(tt.email, tt.password)
+			user, token, err := service.Login(context.Background(), tt.email, tt.password)
 
 			if tt.expectError {
 				if err == nil {
@@ -433,7 +537,7 @@ func TestPasswordHashing(t *testing.T) {
 	service := newTestUserService(true)
 
 	password := "TestPassword123"
-	user, _, err := service.Register("Test User", "test@example.com", password)
+	user, _, err := service.Register(context.Background(), "Test User", "test@example.com", password)
 	if err != nil {
 		t.Fatalf("Failed to register user: %v", err)
 	}
@@ -463,13 +567,13 @@ func TestGeneratePasswordResetToken(t *testing.T) {
 
 	// Register a user
 	email := "test@example.com"
-	_, _, err := service.Register("Test User", email, "Password123")
+	_, _, err := service.Register(context.Background(), "Test User", email, "Password123")
 	if err != nil {
 		t.Fatalf("Failed to register user: %v", err)
 	}
 
 	// Request password reset
-	err = service.RequestPasswordReset(email)
+	err = service.RequestPasswordReset(context.Background(), email)
 	if err != nil {
 		t.Fatalf("Failed to generate reset token: %v", err)
 	}
@@ -480,7 +584,7 @@ func TestGeneratePasswordResetToken(t *testing.T) {
 	}
 
 	// Verify user has reset token
-	user, err := service.userRepo.GetByEmail(email)
+	user, err := service.userRepo.GetByEmail(context.Background(), email)
 	if err != nil {
 		t.Fatalf("Failed to get user: %v", err)
 	}
@@ -506,42 +610,42 @@ func TestResetPassword(t *testing.T) {
 	// Register a user
 	email := "test@example.com"
 	oldPassword := "OldPassword123"
-	_, _, err := service.Register("Test User", email, oldPassword)
+	_, _, err := service.Register(context.Background(), "Test User", email, oldPassword)
 	if err != nil {
 		t.Fatalf("Failed to register user: %v", err)
 	}
 
 	// Generate reset token
-	err = service.RequestPasswordReset(email)
+	err = service.RequestPasswordReset(context.Background(), email)
 	if err != nil {
 		t.Fatalf("Failed to generate reset token: %v", err)
 	}
 
 	// Get the token
-	user, _ := service.userRepo.GetByEmail(email)
+	user, _ := service.userRepo.GetByEmail(context.Background(), email)
 	token := *user.ResetToken
 
 	// Reset password
 	newPassword := "NewPassword123"
-	err = service.ResetPassword(token, newPassword)
+	_, err = service.ResetPassword(context.Background(), token, newPassword)
 	if err != nil {
 		t.Fatalf("Failed to reset password: %v", err)
 	}
 
 	// Try to login with new password
-	_, _, err = service.Login(email, newPassword)
+	_, _, err = service.Login(context.Background(), email, newPassword)
 	if err != nil {
 		t.Error("Should be able to login with new password")
 	}
 
 	// Old password should not work
-	_, _, err = service.Login(email, oldPassword)
+	_, _, err = service.Login(context.Background(), email, oldPassword)
 	if err == nil {
 		t.Error("Old password should not work after reset")
 	}
 
 	// Token should be cleared
-	user, _ = service.userRepo.GetByEmail(email)
+	user, _ = service.userRepo.GetByEmail(context.Background(), email)
 	if user.ResetToken != nil {
 		t.Error("Reset token should be cleared after use")
 	}
@@ -551,7 +655,7 @@ func TestResetPassword(t *testing.T) {
 func TestInvalidResetToken(t *testing.T) {
 	service := newTestUserService(true)
 
-	err := service.ResetPassword("invalid-token", "NewPassword123")
+	_, err := service.ResetPassword(context.Background(), "invalid-token", "NewPassword123")
 	if err != ErrInvalidResetToken {
 		t.Errorf("Expected ErrInvalidResetToken, got: %v", err)
 	}
@@ -563,32 +667,64 @@ func TestExpiredResetToken(t *testing.T) {
 
 	// Register a user
 	email := "test@example.com"
-	_, _, err := service.Register("Test User", email, "Password123")
+	_, _, err := service.Register(context.Background(), "Test User", email, "Password123")
 	if err != nil {
 		t.Fatalf("Failed to register user: %v", err)
 	}
 
 	// Generate reset token and immediately expire it
-	user, _ := service.userRepo.GetByEmail(email)
+	user, _ := service.userRepo.GetByEmail(context.Background(), email)
 	token := "test-token"
 	expiredTime := time.Now().Add(-1 * time.Hour)
 	user.ResetToken = &token
 	user.ResetTokenExpiresAt = &expiredTime
-	service.userRepo.Update(user)
+	service.userRepo.Update(context.Background(), user)
 
 	// Try to reset with expired token
-	err = service.ResetPassword(token, "NewPassword123")
+	_, err = service.ResetPassword(context.Background(), token, "NewPassword123")
 	if err != ErrResetTokenExpired {
 		t.Errorf("Expected ErrResetTokenExpired, got: %v", err)
 	}
 }
 
+// Test UpdateProfile locale handling
+func TestUpdateProfileLocale(t *testing.T) {
+	service := newTestUserService(true)
+	emailService := service.emailService.(*mockEmailService)
+
+	user, _, err := service.Register(context.Background(), "Test User", "test@example.com", "Password123")
+	if err != nil {
+		t.Fatalf("Failed to register user: %v", err)
+	}
+
+	if _, err := service.UpdateProfile(context.Background(), user.ID, "", "", nil, "not a locale!"); err != ErrInvalidLocale {
+		t.Errorf("Expected ErrInvalidLocale, got %v", err)
+	}
+
+	updated, err := service.UpdateProfile(context.Background(), user.ID, "", "", nil, "es-MX")
+	if err != nil {
+		t.Fatalf("Failed to update profile: %v", err)
+	}
+	if updated.Locale != "es-MX" {
+		t.Errorf("Expected locale es-MX, got %q", updated.Locale)
+	}
+
+	// Emails are addressed in the user's locale
+	if err := service.RequestPasswordReset(context.Background(), "test@example.com"); err != nil {
+		t.Fatalf("Failed to request password reset: %v", err)
+	}
+	last := emailService.sentEmails[len(emailService.sentEmails)-1]
+	if last.locale != "es-MX" {
+		t.Errorf("Expected reset email locale es-MX, got %q", last.locale)
+	}
+}
+
 // Test JWT Token Generation
 func TestJWTTokenGeneration(t *testing.T) {
 	service := newTestUserService(true)
 
 	// Register a user
-	user, token, err := service.Register("Test User", "test@example.com", "Password123")
+	user, token, err := service.Register(context.Background(), "Test User", "test@example.com", "Password123")
 	if err != nil {
 		t.Fatalf("Failed to register user: %v", err)
 	}
@@ -605,7 +741,7 @@ func TestJWTTokenGeneration(t *testing.T) {
 	}
 
 	// Verify we can validate the token (basic check)
-	claims, err := auth.ValidateToken(token, service.jwtSecretKey)
+	claims, err := auth.ValidateToken(token, service.keys)
 	if err != nil {
 		t.Fatalf("Failed to validate JWT token: %v", err)
 	}
@@ -623,3 +759,98 @@ func TestJWTTokenGeneration(t *testing.T) {
 		t.Errorf("Expected Role %s, got %s", user.Role, claims.Role)
 	}
 }
+
+// Test refresh token rotation and reuse detection
+func TestRefreshTokenRotation(t *testing.T) {
+	service := newTestUserService(true)
+	user, _, err := service.Register(context.Background(), "Test User", "rotate@example.com", "Password123!")
+	if err != nil {
+		t.Fatalf("Failed to register: %v", err)
+	}
+
+	first, err := service.CreateRefreshToken(context.Background(), user.ID, "Firefox", "203.0.113.5")
+	if err != nil {
+		t.Fatalf("Failed to create refresh token: %v", err)
+	}
+
+	_, access, second, err := service.RefreshAccessToken(context.Background(), first, "203.0.113.9")
+	if err != nil {
+		t.Fatalf("Failed to refresh: %v", err)
+	}
+	if access == "" || second == "" || second == first {
+		t.Fatalf("Expected a new access token and a rotated refresh token")
+	}
+
+	sessions, _ := service.ListSessions(context.Background(), user.ID)
+	if len(sessions) != 1 || sessions[0].DeviceInfo != "Firefox" || sessions[0].IPAddress != "203.0.113.9" {
+		t.Fatalf("Expected one session updated by the refresh, got %+v", sessions)
+	}
+
+	// Replaying the old token revokes the whole session, including the new token
+	if _, _, _, err := service.RefreshAccessToken(context.Background(), first, "198.51.100.1"); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Errorf("Expected ErrRefreshTokenReused, got %v", err)
+	}
+	if _, _, _, err := service.RefreshAccessToken(context.Background(), second, "203.0.113.9"); err == nil {
+		t.Error("Expected the rotated token to be revoked after reuse")
+	}
+	if sessions, _ := service.ListSessions(context.Background(), user.ID); len(sessions) != 0 {
+		t.Errorf("Expected no sessions after reuse, got %d", len(sessions))
+	}
+
+	if _, _, _, err := service.RefreshAccessToken(context.Background(), "unknown", ""); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Expected ErrInvalidRefreshToken, got %v", err)
+	}
+}
+
+// Test listing and revoking sessions
+func TestSessions(t *testing.T) {
+	service := newTestUserService(true)
+	user, _, _ := service.Register(context.Background(), "Test User", "sessions@example.com", "Password123!")
+	other, _, _ := service.Register(context.Background(), "Other User", "other@example.com", "Password123!")
+
+	phone, _ := service.CreateRefreshToken(context.Background(), user.ID, "Phone", "203.0.113.5")
+	if _, err := service.CreateRefreshToken(context.Background(), user.ID, "Laptop", "203.0.113.6"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.CreateRefreshToken(context.Background(), other.ID, "Tablet", "203.0.113.7"); err != nil {
+		t.Fatal(err)
+	}
+
+	sessions, err := service.ListSessions(context.Background(), user.ID)
+	if err != nil || len(sessions) != 2 {
+		t.Fatalf("Expected 2 sessions, got %d (%v)", len(sessions), err)
+	}
+
+	var phoneSession int64
+	for _, s := range sessions {
+		if s.DeviceInfo == "Phone" {
+			phoneSession = s.ID
+		}
+	}
+
+	otherSessions, _ := service.ListSessions(context.Background(), other.ID)
+	if err := service.RevokeSession(context.Background(), user.ID, otherSessions[0].ID); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("Expected ErrSessionNotFound for another user's session, got %v", err)
+	}
+
+	if err := service.RevokeSession(context.Background(), user.ID, phoneSession); err != nil {
+		t.Fatalf("Failed to revoke session: %v", err)
+	}
+	if _, _, _, err := service.RefreshAccessToken(context.Background(), phone, ""); err == nil {
+		t.Error("Expected the revoked session's token to stop working")
+	}
+	if sessions, _ := service.ListSessions(context.Background(), user.ID); len(sessions) != 1 || sessions[0].DeviceInfo != "Laptop" {
+		t.Errorf("Expected only the laptop session, got %+v", sessions)
+	}
+
+	// Sign out everywhere
+	if err := service.RevokeAllRefreshTokens(context.Background(), user.ID); err != nil {
+		t.Fatal(err)
+	}
+	if sessions, _ := service.ListSessions(context.Background(), user.ID); len(sessions) != 0 {
+		t.Errorf("Expected no sessions, got %d", len(sessions))
+	}
+	if sessions, _ := service.ListSessions(context.Background(), other.ID); len(sessions) != 1 {
+		t.Errorf("Expected the other user's session to remain, got %d", len(sessions))
+	}
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"github.com/johnzastrow/actalog/internal/domain"
 )
 
@@ -17,8 +18,8 @@ func NewUserSettingsService(settingsRepo domain.UserSettingsRepository) *UserSet
 }
 
 // GetSettings retrieves settings for a user, creating defaults if none exist
-func (s *UserSettingsService) GetSettings(userID int64) (*domain.UserSettings, error) {
-	settings, err := s.settingsRepo.GetByUserID(userID)
+func (s *UserSettingsService) GetSettings(ctx context.Context, userID int64) (*domain.UserSettings, error) {
+	settings, err := s.settingsRepo.GetByUserID(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -27,14 +28,14 @@ func (s *UserSettingsService) GetSettings(userID int64) (*domain.UserSettings, e
 	if settings == nil {
 		settings = &domain.UserSettings{
 			UserID:                  userID,
-			NotificationPreferences: "{}",
+			NotificationPreferences: domain.DefaultNotificationPreferences(),
 			DataExportFormat:        "JSON",
 			Theme:                   "light",
 			WeightUnit:              "lbs",
 			DistanceUnit:            "miles",
 		}
 
-		if err := s.settingsRepo.Create(settings); err != nil {
+		if err := s.settingsRepo.Create(ctx, settings); err != nil {
 			return nil, err
 		}
 	}
@@ -43,9 +44,9 @@ func (s *UserSettingsService) GetSettings(userID int64) (*domain.UserSettings, e
 }
 
 // UpdateSettings updates user settings
-func (s *UserSettingsService) UpdateSettings(userID int64, updates *domain.UserSettings) (*domain.UserSettings, error) {
+func (s *UserSettingsService) UpdateSettings(ctx context.Context, userID int64, updates *domain.UserSettings) (*domain.UserSettings, error) {
 	// Get existing settings or create defaults
-	existing, err := s.GetSettings(userID)
+	existing, err := s.GetSettings(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -57,7 +58,7 @@ func (s *UserSettingsService) UpdateSettings(userID int64, updates *domain.UserS
 	existing.WeightUnit = updates.WeightUnit
 	existing.DistanceUnit = updates.DistanceUnit
 
-	if err := s.settingsRepo.Update(existing); err != nil {
+	if err := s.settingsRepo.Update(ctx, existing); err != nil {
 		return nil, err
 	}
 
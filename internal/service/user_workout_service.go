@@ -1,18 +1,50 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"time"
 
 	"github.com/johnzastrow/actalog/internal/domain"
+	"github.com/johnzastrow/actalog/internal/policy"
 )
 
 var (
 	ErrUserWorkoutNotFound       = errors.New("user workout not found")
-	ErrUnauthorizedWorkoutAccess = errors.New("unauthorized workout access")
+	ErrUnauthorizedWorkoutAccess = policy.Denied("unauthorized workout access")
 )
 
+// WorkoutEventPublisher receives workout and PR events (e.g. for webhook delivery)
+type WorkoutEventPublisher interface {
+	Publish(ctx context.Context, userID int64, event string, data interface{})
+}
+
+// EventPublishers fans each event out to several publishers in order
+type EventPublishers []WorkoutEventPublisher
+
+// Publish sends the event to every publisher
+func (p EventPublishers) Publish(ctx context.Context, userID int64, event string, data interface{}) {
+	for _, publisher := range p {
+		publisher.Publish(ctx, userID, event, data)
+	}
+}
+
+// WorkoutEvent is the event data published when a logged workout changes
+type WorkoutEvent struct {
+	Workout   *domain.UserWorkout           `json:"workout"`
+	Movements []*domain.UserWorkoutMovement `json:"movements,omitempty"`
+	WODs      []*domain.UserWorkoutWOD      `json:"wods,omitempty"`
+}
+
+// PREvent is the event data published when a new personal record is set
+type PREvent struct {
+	UserWorkoutID int64                       `json:"user_workout_id"`
+	WorkoutDate   time.Time                   `json:"workout_date"`
+	Movement      *domain.UserWorkoutMovement `json:"movement,omitempty"`
+	WOD           *domain.UserWorkoutWOD      `json:"wod,omitempty"`
+}
+
 // UserWorkoutService handles logging workout instances (when users perform workouts)
 type UserWorkoutService struct {
 	userWorkoutRepo         domain.UserWorkoutRepository
@@ -21,6 +53,8 @@ type UserWorkoutService struct {
 	userWorkoutMovementRepo domain.UserWorkoutMovementRepository
 	userWorkoutWODRepo      domain.UserWorkoutWODRepository
 	wodRepo                 domain.WODRepository
+	transactor              domain.Transactor
+	events                  WorkoutEventPublisher
 }
 
 // NewUseroutService creates a new user workout service
@@ -31,6 +65,7 @@ func NewUserWorkoutService(
 	userWorkoutMovementRepo domain.UserWorkoutMovementRepository,
 	userWorkoutWODRepo domain.UserWorkoutWODRepository,
 	wodRepo domain.WODRepository,
+	transactor domain.Transactor,
 ) *UserWorkoutService {
 	return &UserWorkoutService{
 		userWorkoutRepo:         userWorkoutRepo,
@@ -39,14 +74,38 @@ func NewUserWorkoutService(
 		userWorkoutMovementRepo: userWorkoutMovementRepo,
 		userWorkoutWODRepo:      userWorkoutWODRepo,
 		wodRepo:                 wodRepo,
+		transactor:              transactor,
+	}
+}
+
+// SetEventPublisher sets the publisher notified when workouts are logged, updated or deleted and PRs are set
+func (s *UserWorkoutService) SetEventPublisher(events WorkoutEventPublisher) {
+	s.events = events
+}
+
+// publish sends an event to the configured publisher, if any
+func (s *UserWorkoutService) publish(ctx context.Context, userID int64, event string, data interface{}) {
+	if s.events != nil {
+		s.events.Publish(ctx, userID, event, data)
 	}
 }
 
 // LogWorkout logs that a user performed a workout (template-based or ad-hoc) on a specific date
-func (s *UserWorkoutService) LogWorkout(userID int64, templateID *int64, workoutName *string, date time.Time, notes *string, totalTime *int, workoutType *string) (*domain.UserWorkout, error) {
+func (s *UserWorkoutService) LogWorkout(ctx context.Context, userID int64, templateID *int64, workoutName *string, date time.Time, notes *string, totalTime *int, workoutType *string) (*domain.UserWorkout, error) {
+	userWorkout, err := s.createUserWorkout(ctx, userID, templateID, workoutName, date, notes, totalTime, workoutType)
+	if err != nil {
+		return nil, err
+	}
+
+	s.publish(ctx, userID, domain.WebhookEventWorkoutLogged, WorkoutEvent{Workout: userWorkout})
+	return userWorkout, nil
+}
+
+// createUserWorkout validates the template and creates the user workout record
+func (s *UserWorkoutService) createUserWorkout(ctx context.Context, userID int64, templateID *int64, workoutName *string, date time.Time, notes *string, totalTime *int, workoutType *string) (*domain.UserWorkout, error) {
 	// If template ID is provided, verify it exists and check authorization
 	if templateID != nil && *templateID != 0 {
-		workout, err := s.workoutRepo.GetByID(*templateID)
+		workout, err := s.workoutRepo.GetByID(ctx, *templateID)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get workout template: %w", err)
 		}
@@ -55,7 +114,7 @@ func (s *UserWorkoutService) LogWorkout(userID int64, templateID *int64, workout
 		}
 
 		// Check authorization: user can only log workouts they created or standard workouts (created_by = null)
-		if workout.CreatedBy != nil && *workout.CreatedBy != userID {
+		if err := policy.Authorize(policy.User(userID, policy.RoleUser), policy.ActionUse, policy.Template(workout)); err != nil {
 			return nil, ErrUnauthorizedWorkoutAccess
 		}
 	}
@@ -71,7 +130,7 @@ func (s *UserWorkoutService) LogWorkout(userID int64, templateID *int64, workout
 		Notes:       notes,
 	}
 
-	err := s.userWorkoutRepo.Create(userWorkout)
+	err := s.userWorkoutRepo.Create(ctx, userWorkout)
 	if err != nil {
 		return nil, fmt.Errorf("failed to log workout: %w", err)
 	}
@@ -81,6 +140,7 @@ func (s *UserWorkoutService) LogWorkout(userID int64, templateID *int64, workout
 
 // LogWorkoutWithPerformance logs a workout with full performance data for movements and WODs
 func (s *UserWorkoutService) LogWorkoutWithPerformance(
+	ctx context.Context,
 	userID int64,
 	templateID *int64,
 	workoutName *string,
@@ -91,62 +151,76 @@ func (s *UserWorkoutService) LogWorkoutWithPerformance(
 	movements []*domain.UserWorkoutMovement,
 	wods []*domain.UserWorkoutWOD,
 ) (*domain.UserWorkout, error) {
-	// First create the base user workout
-	userWorkout, err := s.LogWorkout(userID, templateID, workoutName, date, notes, totalTime, workoutType)
-	if err != nil {
-		return nil, err
-	}
+	// The workout and its performance data are saved together or not at all
+	var userWorkout *domain.UserWorkout
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		// First create the base user workout
+		var err error
+		userWorkout, err = s.createUserWorkout(ctx, userID, templateID, workoutName, date, notes, totalTime, workoutType)
+		if err != nil {
+			return err
+		}
 
-	// Set the user_workout_id for all movements
-	for _, m := range movements {
-		m.UserWorkoutID = userWorkout.ID
-	}
+		// Set the user_workout_id for all movements
+		for _, m := range movements {
+			m.UserWorkoutID = userWorkout.ID
+		}
 
-	// Set the user_workout_id for all WODs
-	for _, w := range wods {
-		w.UserWorkoutID = userWorkout.ID
-	}
+		// Set the user_workout_id for all WODs
+		for _, w := range wods {
+			w.UserWorkoutID = userWorkout.ID
+		}
 
-	// Detect and flag PRs for movements before saving
-	if len(movements) > 0 {
-		if err := s.DetectAndFlagMovementPRs(userID, movements); err != nil {
-			_ = s.userWorkoutRepo.Delete(userWorkout.ID, userID)
-			return nil, fmt.Errorf("failed to detect movement PRs: %w", err)
+		// Detect and flag PRs for movements before saving
+		if len(movements) > 0 {
+			if err := s.DetectAndFlagMovementPRs(ctx, userID, movements); err != nil {
+				return fmt.Errorf("failed to detect movement PRs: %w", err)
+			}
 		}
-	}
 
-	// Validate WOD score types before saving
-	if len(wods) > 0 {
-		if err := s.ValidateWODScoreTypes(wods); err != nil {
-			_ = s.userWorkoutRepo.Delete(userWorkout.ID, userID)
-			return nil, fmt.Errorf("WOD validation failed: %w", err)
+		// Validate WOD score types before saving
+		if len(wods) > 0 {
+			if err := s.ValidateWODScoreTypes(ctx, wods); err != nil {
+				return fmt.Errorf("WOD validation failed: %w", err)
+			}
 		}
-	}
 
-	// Detect and flag PRs for WODs before saving
-	if len(wods) > 0 {
-		if err := s.DetectAndFlagWODPRs(userID, wods); err != nil {
-			_ = s.userWorkoutRepo.Delete(userWorkout.ID, userID)
-			return nil, fmt.Errorf("failed to detect WOD PRs: %w", err)
+		// Detect and flag PRs for WODs before saving
+		if len(wods) > 0 {
+			if err := s.DetectAndFlagWODPRs(ctx, userID, wods); err != nil {
+				return fmt.Errorf("failed to detect WOD PRs: %w", err)
+			}
+		}
+
+		// Save movement performance data
+		if len(movements) > 0 {
+			if err := s.userWorkoutMovementRepo.CreateBatch(ctx, movements); err != nil {
+				return fmt.Errorf("failed to save movement performance data: %w", err)
+			}
 		}
-	}
 
-	// Save movement performance data
-	if len(movements) > 0 {
-		if err := s.userWorkoutMovementRepo.CreateBatch(movements); err != nil {
-			// Rollback: delete the user workout if performance data fails
-			_ = s.userWorkoutRepo.Delete(userWorkout.ID, userID)
-			return nil, fmt.Errorf("failed to save movement performance data: %w", err)
+		// Save WOD performance data
+		if len(wods) > 0 {
+			if err := s.userWorkoutWODRepo.CreateBatch(ctx, wods); err != nil {
+				return fmt.Errorf("failed to save WOD performance data: %w", err)
+			}
 		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// Save WOD performance data
-	if len(wods) > 0 {
-		if err := s.userWorkoutWODRepo.CreateBatch(wods); err != nil {
-			// Rollback: delete movement data and user workout
-			_ = s.userWorkoutMovementRepo.DeleteByUserWorkoutID(userWorkout.ID)
-			_ = s.userWorkoutRepo.Delete(userWorkout.ID, userID)
-			return nil, fmt.Errorf("failed to save WOD performance data: %w", err)
+	s.publish(ctx, userID, domain.WebhookEventWorkoutLogged, WorkoutEvent{Workout: userWorkout, Movements: movements, WODs: wods})
+	for _, m := range movements {
+		if m.IsPR {
+			s.publish(ctx, userID, domain.WebhookEventPRSet, PREvent{UserWorkoutID: userWorkout.ID, WorkoutDate: userWorkout.WorkoutDate, Movement: m})
+		}
+	}
+	for _, w := range wods {
+		if w.IsPR {
+			s.publish(ctx, userID, domain.WebhookEventPRSet, PREvent{UserWorkoutID: userWorkout.ID, WorkoutDate: userWorkout.WorkoutDate, WOD: w})
 		}
 	}
 
@@ -154,9 +228,9 @@ func (s *UserWorkoutService) LogWorkoutWithPerformance(
 }
 
 // GetLoggedWorkout retrieves a logged workout by ID with full details including performance data
-func (s *UserWorkoutService) GetLoggedWorkout(userWorkoutID, userID int64) (*domain.UserWorkoutWithDetails, error) {
+func (s *UserWorkoutService) GetLoggedWorkout(ctx context.Context, userWorkoutID, userID int64) (*domain.UserWorkoutWithDetails, error) {
 	// First check if workout exists (without user filtering)
-	basic, err := s.userWorkoutRepo.GetByID(userWorkoutID)
+	basic, err := s.userWorkoutRepo.GetByID(ctx, userWorkoutID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get logged workout: %w", err)
 	}
@@ -165,25 +239,25 @@ func (s *UserWorkoutService) GetLoggedWorkout(userWorkoutID, userID int64) (*dom
 	}
 
 	// Check authorization
-	if basic.UserID != userID {
-		return nil, ErrUnauthorizedWorkoutAccess
+	if err := authorizeLoggedWorkout(userID, policy.ActionRead, basic); err != nil {
+		return nil, err
 	}
 
 	// Get full details
-	userWorkout, err := s.userWorkoutRepo.GetByIDWithDetails(userWorkoutID, userID)
+	userWorkout, err := s.userWorkoutRepo.GetByIDWithDetails(ctx, userWorkoutID, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get logged workout details: %w", err)
 	}
 
 	// Load performance data for movements
-	performanceMovements, err := s.userWorkoutMovementRepo.GetByUserWorkoutID(userWorkoutID)
+	performanceMovements, err := s.userWorkoutMovementRepo.GetByUserWorkoutID(ctx, userWorkoutID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get movement performance data: %w", err)
 	}
 	userWorkout.PerformanceMovements = performanceMovements
 
 	// Load performance data for WODs
-	performanceWODs, err := s.userWorkoutWODRepo.GetByUserWorkoutID(userWorkoutID)
+	performanceWODs, err := s.userWorkoutWODRepo.GetByUserWorkoutID(ctx, userWorkoutID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get WOD performance data: %w", err)
 	}
@@ -192,167 +266,231 @@ func (s *UserWorkoutService) GetLoggedWorkout(userWorkoutID, userID int64) (*dom
 	return userWorkout, nil
 }
 
-// ListLoggedWorkouts retrieves all workouts logged by a user
-func (s *UserWorkoutService) ListLoggedWorkouts(userID int64, limit, offset int) ([]*domain.UserWorkoutWithDetails, error) {
-	workouts, err := s.userWorkoutRepo.ListByUserWithDetails(userID, limit, offset)
+// ListLoggedWorkouts retrieves a page of the workouts logged by a user that
+// match the filter
+func (s *UserWorkoutService) ListLoggedWorkouts(ctx context.Context, userID int64, filter domain.WorkoutFilter) (*domain.WorkoutPage, error) {
+	page, err := s.userWorkoutRepo.ListWithDetails(ctx, userID, filter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list logged workouts: %w", err)
 	}
 
-	return workouts, nil
+	return page, nil
 }
 
-// ListLoggedWorkoutsByDateRange retrieves workouts within a date range
-func (s *UserWorkoutService) ListLoggedWorkoutsByDateRange(userID int64, startDate, endDate time.Time) ([]*domain.UserWorkout, error) {
-	workouts, err := s.userWorkoutRepo.ListByUserAndDateRange(userID, startDate, endDate)
-	if err != nil {
-		return nil, fmt.Errorf("failed to list workouts by date range: %w", err)
-	}
-
-	return workouts, nil
+// UpdateLoggedWorkout updates a logged workout with authorization check
+func (s *UserWorkoutService) UpdateLoggedWorkout(ctx context.Context, userWorkoutID, userID int64, workoutName *string, notes *string, totalTime *int, workoutType *string) error {
+	_, err := s.UpdateLoggedWorkoutWithPerformance(ctx, userWorkoutID, userID, workoutName, notes, totalTime, workoutType, nil, nil)
+	return err
 }
 
-// UpdateLoggedWorkout updates a logged workout with authorization check
-func (s *UserWorkoutService) UpdateLoggedWorkout(userWorkoutID, userID int64, workoutName *string, notes *string, totalTime *int, workoutType *string) error {
-	// Get existing logged workout
-	existing, err := s.userWorkoutRepo.GetByID(userWorkoutID)
-	if err != nil {
-		return fmt.Errorf("failed to get logged workout: %w", err)
-	}
-	if existing == nil {
-		return ErrUserWorkoutNotFound
-	}
+// UpdateLoggedWorkoutWithPerformance updates a logged workout and replaces
+// its movement and WOD performance data, all together or not at all. Nil
+// fields are left as they are, and so are the movements or WODs when none
+// are given. New movements and WODs are flagged as PRs as when logging, and
+// once saved the whole workout is published, followed by the PRs the edit
+// set.
+func (s *UserWorkoutService) UpdateLoggedWorkoutWithPerformance(
+	ctx context.Context,
+	userWorkoutID int64,
+	userID int64,
+	workoutName *string,
+	notes *string,
+	totalTime *int,
+	workoutType *string,
+	movements []*domain.UserWorkoutMovement,
+	wods []*domain.UserWorkoutWOD,
+) (*domain.UserWorkout, error) {
+	var (
+		updated        *domain.UserWorkout
+		savedMovements []*domain.UserWorkoutMovement
+		savedWODs      []*domain.UserWorkoutWOD
+		movementPRs    []*domain.UserWorkoutMovement
+		wodPRs         []*domain.UserWorkoutWOD
+	)
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		// Get existing logged workout
+		existing, err := s.userWorkoutRepo.GetByID(ctx, userWorkoutID)
+		if err != nil {
+			return fmt.Errorf("failed to get logged workout: %w", err)
+		}
+		if existing == nil {
+			return ErrUserWorkoutNotFound
+		}
 
-	// Authorization check
-	if existing.UserID != userID {
-		return ErrUnauthorizedWorkoutAccess
-	}
+		// Authorization check
+		if err := authorizeLoggedWorkout(userID, policy.ActionUpdate, existing); err != nil {
+			return err
+		}
 
-	// Update fields
-	if workoutName != nil {
-		// Only allow updating workout_name for ad-hoc workouts (workout_id is null)
-		if existing.WorkoutID == nil {
-			existing.WorkoutName = workoutName
+		// Update fields
+		if workoutName != nil {
+			// Only allow updating workout_name for ad-hoc workouts (workout_id is null)
+			if existing.WorkoutID == nil {
+				existing.WorkoutName = workoutName
+			}
+		}
+		if notes != nil {
+			existing.Notes = notes
+		}
+		if totalTime != nil {
+			existing.TotalTime = totalTime
+		}
+		if workoutType != nil {
+			existing.WorkoutType = workoutType
 		}
-	}
-	if notes != nil {
-		existing.Notes = notes
-	}
-	if totalTime != nil {
-		existing.TotalTime = totalTime
-	}
-	if workoutType != nil {
-		existing.WorkoutType = workoutType
-	}
 
-	err = s.userWorkoutRepo.Update(existing)
-	if err != nil {
-		return fmt.Errorf("failed to update logged workout: %w", err)
-	}
-	return nil
-}
+		if err := s.userWorkoutRepo.Update(ctx, existing); err != nil {
+			return fmt.Errorf("failed to update logged workout: %w", err)
+		}
 
-// DeleteLoggedWorkout deletes a logged workout with authorization check
-func (s *UserWorkoutService) DeleteLoggedWorkout(userWorkoutID, userID int64) error {
-	// Get existing logged workout
-	existing, err := s.userWorkoutRepo.GetByID(userWorkoutID)
-	if err != nil {
-		return fmt.Errorf("failed to get logged workout: %w", err)
-	}
-	if existing == nil {
-		return ErrUserWorkoutNotFound
-	}
+		// Replace the existing movements, flagging PRs against the user's
+		// other workouts
+		if len(movements) > 0 {
+			previous, err := s.userWorkoutMovementRepo.GetByUserWorkoutID(ctx, userWorkoutID)
+			if err != nil {
+				return fmt.Errorf("failed to get existing movements: %w", err)
+			}
+			if err := s.userWorkoutMovementRepo.DeleteByUserWorkoutID(ctx, userWorkoutID); err != nil {
+				return fmt.Errorf("failed to delete existing movements: %w", err)
+			}
+			for _, m := range movements {
+				m.UserWorkoutID = userWorkoutID
+			}
+			if err := s.DetectAndFlagMovementPRs(ctx, userID, movements); err != nil {
+				return fmt.Errorf("failed to detect movement PRs: %w", err)
+			}
+			if err := s.userWorkoutMovementRepo.CreateBatch(ctx, movements); err != nil {
+				return fmt.Errorf("failed to save movement performance data: %w", err)
+			}
+			movementPRs = newMovementPRs(movements, previous)
+		}
 
-	// Authorization check
-	if existing.UserID != userID {
-		return ErrUnauthorizedWorkoutAccess
-	}
+		// Replace the existing WODs, once their score types are valid
+		if len(wods) > 0 {
+			if err := s.ValidateWODScoreTypes(ctx, wods); err != nil {
+				return fmt.Errorf("WOD validation failed: %w", err)
+			}
+			previous, err := s.userWorkoutWODRepo.GetByUserWorkoutID(ctx, userWorkoutID)
+			if err != nil {
+				return fmt.Errorf("failed to get existing WODs: %w", err)
+			}
+			if err := s.userWorkoutWODRepo.DeleteByUserWorkoutID(ctx, userWorkoutID); err != nil {
+				return fmt.Errorf("failed to delete existing WODs: %w", err)
+			}
+			for _, w := range wods {
+				w.UserWorkoutID = userWorkoutID
+			}
+			if err := s.DetectAndFlagWODPRs(ctx, userID, wods); err != nil {
+				return fmt.Errorf("failed to detect WOD PRs: %w", err)
+			}
+			if err := s.userWorkoutWODRepo.CreateBatch(ctx, wods); err != nil {
+				return fmt.Errorf("failed to save WOD performance data: %w", err)
+			}
+			wodPRs = newWODPRs(wods, previous)
+		}
 
-	// Delete logged workout
-	err = s.userWorkoutRepo.Delete(userWorkoutID, userID)
-	if err != nil {
-		return fmt.Errorf("failed to delete logged workout: %w", err)
-	}
-	return nil
-}
+		// The whole workout as saved, for the event
+		savedMovements, err = s.userWorkoutMovementRepo.GetByUserWorkoutID(ctx, userWorkoutID)
+		if err != nil {
+			return fmt.Errorf("failed to get movement performance data: %w", err)
+		}
+		savedWODs, err = s.userWorkoutWODRepo.GetByUserWorkoutID(ctx, userWorkoutID)
+		if err != nil {
+			return fmt.Errorf("failed to get WOD performance data: %w", err)
+		}
 
-// UpdateWorkoutMovements updates the movements for a logged workout
-func (s *UserWorkoutService) UpdateWorkoutMovements(userWorkoutID, userID int64, movements []domain.UserWorkoutMovement) error {
-	// Authorization check
-	existing, err := s.userWorkoutRepo.GetByID(userWorkoutID)
+		updated = existing
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("failed to get logged workout: %w", err)
+		return nil, err
 	}
-	if existing == nil {
-		return ErrUserWorkoutNotFound
+
+	s.publish(ctx, userID, domain.WebhookEventWorkoutUpdated, WorkoutEvent{Workout: updated, Movements: savedMovements, WODs: savedWODs})
+	for _, m := range movementPRs {
+		s.publish(ctx, userID, domain.WebhookEventPRSet, PREvent{UserWorkoutID: updated.ID, WorkoutDate: updated.WorkoutDate, Movement: m})
 	}
-	if existing.UserID != userID {
-		return ErrUnauthorizedWorkoutAccess
+	for _, w := range wodPRs {
+		s.publish(ctx, userID, domain.WebhookEventPRSet, PREvent{UserWorkoutID: updated.ID, WorkoutDate: updated.WorkoutDate, WOD: w})
 	}
+	return updated, nil
+}
 
-	// Delete existing movements
-	if err := s.userWorkoutMovementRepo.DeleteByUserWorkoutID(userWorkoutID); err != nil {
-		return fmt.Errorf("failed to delete existing movements: %w", err)
+// newMovementPRs returns the movements flagged as PRs that the workout
+// didn't already have as PRs, at the same weight, before an edit
+func newMovementPRs(movements, previous []*domain.UserWorkoutMovement) []*domain.UserWorkoutMovement {
+	var prs []*domain.UserWorkoutMovement
+	for _, m := range movements {
+		known := false
+		for _, p := range previous {
+			known = known || (p.IsPR && p.MovementID == m.MovementID && samePtr(p.Weight, m.Weight))
+		}
+		if m.IsPR && !known {
+			prs = append(prs, m)
+		}
 	}
+	return prs
+}
 
-	// Insert new movements
-	for _, movement := range movements {
-		movement.UserWorkoutID = userWorkoutID
-		if err := s.userWorkoutMovementRepo.Create(&movement); err != nil {
-			return fmt.Errorf("failed to create movement: %w", err)
+// newWODPRs returns the WODs flagged as PRs that the workout didn't already
+// have as PRs, with the same score, before an edit
+func newWODPRs(wods, previous []*domain.UserWorkoutWOD) []*domain.UserWorkoutWOD {
+	var prs []*domain.UserWorkoutWOD
+	for _, w := range wods {
+		known := false
+		for _, p := range previous {
+			known = known || (p.IsPR && p.WODID == w.WODID && samePtr(p.TimeSeconds, w.TimeSeconds) &&
+				samePtr(p.Rounds, w.Rounds) && samePtr(p.Reps, w.Reps) && samePtr(p.Weight, w.Weight))
+		}
+		if w.IsPR && !known {
+			prs = append(prs, w)
 		}
 	}
+	return prs
+}
 
-	return nil
+// samePtr reports whether two optional values are both unset or equal
+func samePtr[T comparable](a, b *T) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
 }
 
-// UpdateWorkoutWODs updates the WODs for a logged workout
-func (s *UserWorkoutService) UpdateWorkoutWODs(userWorkoutID, userID int64, wods []domain.UserWorkoutWOD) error {
-	// Authorization check
-	existing, err := s.userWorkoutRepo.GetByID(userWorkoutID)
+// DeleteLoggedWorkout deletes a logged workout with authorization check
+func (s *UserWorkoutService) DeleteLoggedWorkout(ctx context.Context, userWorkoutID, userID int64) error {
+	// Get existing logged workout
+	existing, err := s.userWorkoutRepo.GetByID(ctx, userWorkoutID)
 	if err != nil {
 		return fmt.Errorf("failed to get logged workout: %w", err)
 	}
 	if existing == nil {
 		return ErrUserWorkoutNotFound
 	}
-	if existing.UserID != userID {
-		return ErrUnauthorizedWorkoutAccess
-	}
-
-	// Validate WOD score types before updating
-	wodPointers := make([]*domain.UserWorkoutWOD, len(wods))
-	for i := range wods {
-		wodPointers[i] = &wods[i]
-	}
-	if err := s.ValidateWODScoreTypes(wodPointers); err != nil {
-		return fmt.Errorf("WOD validation failed: %w", err)
-	}
 
-	// Delete existing WODs
-	if err := s.userWorkoutWODRepo.DeleteByUserWorkoutID(userWorkoutID); err != nil {
-		return fmt.Errorf("failed to delete existing WODs: %w", err)
+	// Authorization check
+	if err := authorizeLoggedWorkout(userID, policy.ActionDelete, existing); err != nil {
+		return err
 	}
 
-	// Insert new WODs
-	for _, wod := range wods {
-		wod.UserWorkoutID = userWorkoutID
-		if err := s.userWorkoutWODRepo.Create(&wod); err != nil {
-			return fmt.Errorf("failed to create WOD: %w", err)
-		}
+	// Delete logged workout
+	err = s.userWorkoutRepo.Delete(ctx, userWorkoutID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete logged workout: %w", err)
 	}
 
+	s.publish(ctx, userID, domain.WebhookEventWorkoutDeleted, WorkoutEvent{Workout: existing})
 	return nil
 }
 
 // GetWorkoutStatsForMonth counts workouts logged in a specific month
-func (s *UserWorkoutService) GetWorkoutStatsForMonth(userID int64, year, month int) (int, error) {
+func (s *UserWorkoutService) GetWorkoutStatsForMonth(ctx context.Context, userID int64, year, month int) (int, error) {
 	// Calculate start and end dates for the month
 	startDate := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
 	endDate := startDate.AddDate(0, 1, 0).Add(-time.Second)
 
 	// Get workouts in range
-	workouts, err := s.userWorkoutRepo.ListByUserAndDateRange(userID, startDate, endDate)
+	workouts, err := s.userWorkoutRepo.ListByUserAndDateRange(ctx, userID, startDate, endDate)
 	if err != nil {
 		return 0, fmt.Errorf("failed to list workouts by month: %w", err)
 	}
@@ -360,7 +498,7 @@ func (s *UserWorkoutService) GetWorkoutStatsForMonth(userID int64, year, month i
 }
 
 // DetectAndFlagMovementPRs automatically detects personal records for movements with weight
-func (s *UserWorkoutService) DetectAndFlagMovementPRs(userID int64, movements []*domain.UserWorkoutMovement) error {
+func (s *UserWorkoutService) DetectAndFlagMovementPRs(ctx context.Context, userID int64, movements []*domain.UserWorkoutMovement) error {
 	for _, m := range movements {
 		// Only check for PRs on movements with weight
 		if m.Weight == nil {
@@ -368,7 +506,7 @@ func (s *UserWorkoutService) DetectAndFlagMovementPRs(userID int64, movements []
 		}
 
 		// Get max weight for this movement for this user
-		maxWeight, err := s.userWorkoutMovementRepo.GetMaxWeightForMovement(userID, m.MovementID)
+		maxWeight, err := s.userWorkoutMovementRepo.GetMaxWeightForMovement(ctx, userID, m.MovementID)
 		if err != nil {
 			return fmt.Errorf("failed to get max weight for movement %d: %w", m.MovementID, err)
 		}
@@ -382,11 +520,11 @@ func (s *UserWorkoutService) DetectAndFlagMovementPRs(userID int64, movements []
 }
 
 // DetectAndFlagWODPRs automatically detects personal records for WODs (time-based or rounds+reps)
-func (s *UserWorkoutService) DetectAndFlagWODPRs(userID int64, wods []*domain.UserWorkoutWOD) error {
+func (s *UserWorkoutService) DetectAndFlagWODPRs(ctx context.Context, userID int64, wods []*domain.UserWorkoutWOD) error {
 	for _, w := range wods {
 		// Check for time-based PRs (fastest time)
 		if w.TimeSeconds != nil {
-			bestTime, err := s.userWorkoutWODRepo.GetBestTimeForWOD(userID, w.WODID)
+			bestTime, err := s.userWorkoutWODRepo.GetBestTimeForWOD(ctx, userID, w.WODID)
 			if err != nil {
 				return fmt.Errorf("failed to get best time for WOD %d: %w", w.WODID, err)
 			}
@@ -400,7 +538,7 @@ func (s *UserWorkoutService) DetectAndFlagWODPRs(userID int64, wods []*domain.Us
 
 		// Check for rounds+reps PRs (most rounds, then most reps)
 		if w.Rounds != nil {
-			bestRounds, bestReps, err := s.userWorkoutWODRepo.GetBestRoundsRepsForWOD(userID, w.WODID)
+			bestRounds, bestReps, err := s.userWorkoutWODRepo.GetBestRoundsRepsForWOD(ctx, userID, w.WODID)
 			if err != nil {
 				return fmt.Errorf("failed to get best rounds+reps for WOD %d: %w", w.WODID, err)
 			}
@@ -428,8 +566,8 @@ func (s *UserWorkoutService) DetectAndFlagWODPRs(userID int64, wods []*domain.Us
 }
 
 // GetPRMovements retrieves recent PR-flagged movements for a user
-func (s *UserWorkoutService) GetPRMovements(userID int64, limit int) ([]*domain.UserWorkoutMovement, error) {
-	movements, err := s.userWorkoutMovementRepo.GetPRMovements(userID, limit)
+func (s *UserWorkoutService) GetPRMovements(ctx context.Context, userID int64, limit int) ([]*domain.UserWorkoutMovement, error) {
+	movements, err := s.userWorkoutMovementRepo.GetPRMovements(ctx, userID, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get PR movements: %w", err)
 	}
@@ -437,8 +575,8 @@ func (s *UserWorkoutService) GetPRMovements(userID int64, limit int) ([]*domain.
 }
 
 // GetPRWODs retrieves recent PR-flagged WODs for a user
-func (s *UserWorkoutService) GetPRWODs(userID int64, limit int) ([]*domain.UserWorkoutWOD, error) {
-	wods, err := s.userWorkoutWODRepo.GetPRWODs(userID, limit)
+func (s *UserWorkoutService) GetPRWODs(ctx context.Context, userID int64, limit int) ([]*domain.UserWorkoutWOD, error) {
+	wods, err := s.userWorkoutWODRepo.GetPRWODs(ctx, userID, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get PR WODs: %w", err)
 	}
@@ -446,12 +584,12 @@ func (s *UserWorkoutService) GetPRWODs(userID int64, limit int) ([]*domain.UserW
 }
 
 // RetroactivelyFlagPRs analyzes all existing workouts for a user and flags PRs based on historical max values
-func (s *UserWorkoutService) RetroactivelyFlagPRs(userID int64) (int, int, error) {
+func (s *UserWorkoutService) RetroactivelyFlagPRs(ctx context.Context, userID int64) (int, int, error) {
 	movementPRCount := 0
 	wodPRCount := 0
 
 	// Get all user workouts ordered by date (chronologically)
-	workouts, err := s.userWorkoutRepo.ListByUserAndDateRange(userID, time.Time{}, time.Now().AddDate(0, 0, 1))
+	workouts, err := s.userWorkoutRepo.ListByUserAndDateRange(ctx, userID, time.Time{}, time.Now().AddDate(0, 0, 1))
 	if err != nil {
 		return 0, 0, fmt.Errorf("failed to get user workouts: %w", err)
 	}
@@ -471,7 +609,7 @@ func (s *UserWorkoutService) RetroactivelyFlagPRs(userID int64) (int, int, error
 	// Process each workout chronologically
 	for _, workout := range workouts {
 		// Get movements for this workout
-		movements, err := s.userWorkoutMovementRepo.GetByUserWorkoutID(workout.ID)
+		movements, err := s.userWorkoutMovementRepo.GetByUserWorkoutID(ctx, workout.ID)
 		if err != nil {
 			return movementPRCount, wodPRCount, fmt.Errorf("failed to get movements for workout %d: %w", workout.ID, err)
 		}
@@ -502,7 +640,7 @@ func (s *UserWorkoutService) RetroactivelyFlagPRs(userID int64) (int, int, error
 
 			// Update PR flag if needed
 			if isPR != movement.IsPR {
-				if err := s.userWorkoutMovementRepo.UpdatePRFlag(movement.ID, isPR); err != nil {
+				if err := s.userWorkoutMovementRepo.UpdatePRFlag(ctx, movement.ID, isPR); err != nil {
 					return movementPRCount, wodPRCount, fmt.Errorf("failed to update PR flag for movement %d: %w", movement.ID, err)
 				}
 				if isPR {
@@ -512,7 +650,7 @@ func (s *UserWorkoutService) RetroactivelyFlagPRs(userID int64) (int, int, error
 		}
 
 		// Get WODs for this workout
-		wods, err := s.userWorkoutWODRepo.GetByUserWorkoutID(workout.ID)
+		wods, err := s.userWorkoutWODRepo.GetByUserWorkoutID(ctx, workout.ID)
 		if err != nil {
 			return movementPRCount, wodPRCount, fmt.Errorf("failed to get WODs for workout %d: %w", workout.ID, err)
 		}
@@ -568,7 +706,7 @@ func (s *UserWorkoutService) RetroactivelyFlagPRs(userID int64) (int, int, error
 
 			// Update PR flag if needed
 			if isPR != wod.IsPR {
-				if err := s.userWorkoutWODRepo.UpdatePRFlag(wod.ID, isPR); err != nil {
+				if err := s.userWorkoutWODRepo.UpdatePRFlag(ctx, wod.ID, isPR); err != nil {
 					return movementPRCount, wodPRCount, fmt.Errorf("failed to update PR flag for WOD %d: %w", wod.ID, err)
 				}
 				if isPR {
@@ -582,10 +720,10 @@ func (s *UserWorkoutService) RetroactivelyFlagPRs(userID int64) (int, int, error
 }
 
 // ValidateWODScoreTypes validates that WOD performance data matches each WOD's defined score_type
-func (s *UserWorkoutService) ValidateWODScoreTypes(wods []*domain.UserWorkoutWOD) error {
+func (s *UserWorkoutService) ValidateWODScoreTypes(ctx context.Context, wods []*domain.UserWorkoutWOD) error {
 	for _, w := range wods {
 		// Fetch the WOD definition
-		wod, err := s.wodRepo.GetByID(w.WODID)
+		wod, err := s.wodRepo.GetByID(ctx, w.WODID)
 		if err != nil {
 			return fmt.Errorf("failed to get WOD definition for WOD ID %d: %w", w.WODID, err)
 		}
@@ -632,3 +770,12 @@ func (s *UserWorkoutService) ValidateWODScoreTypes(wods []*domain.UserWorkoutWOD
 
 	return nil
 }
+
+// authorizeLoggedWorkout checks that a user may act on a logged workout. A
+// training log is private to the athlete whatever their role.
+func authorizeLoggedWorkout(userID int64, action policy.Action, workout *domain.UserWorkout) error {
+	if err := policy.Authorize(policy.User(userID, policy.RoleUser), action, policy.LoggedWorkout(workout)); err != nil {
+		return ErrUnauthorizedWorkoutAccess
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
@@ -98,11 +99,13 @@ func TestUserWorkoutService_LogWorkout(t *testing.T) {
 				tt.setupMock(workoutRepo)
 			}
 
-			service := NewUserWorkoutService(userWorkoutRepo, workoutRepo, workoutMovementRepo)
+			service := NewUserWorkoutService(userWorkoutRepo, workoutRepo, workoutMovementRepo, newMockUserWorkoutMovementRepo(), newMockUserWorkoutWODRepo(), newMockWODRepo(), &mockTransactor{})
 
 			userWorkout, err := service.LogWorkout(
+				context.Background(),
 				tt.userID,
-				tt.workoutID,
+				&tt.workoutID,
+				nil,
 				tt.workoutDate,
 				tt.notes,
 				tt.totalTime,
@@ -130,8 +133,8 @@ func TestUserWorkoutService_LogWorkout(t *testing.T) {
 				t.Errorf("expected user ID %d, got %d", tt.userID, userWorkout.UserID)
 			}
 
-			if userWorkout.WorkoutID != tt.workoutID {
-				t.Errorf("expected workout ID %d, got %d", tt.workoutID, userWorkout.WorkoutID)
+			if userWorkout.WorkoutID == nil || *userWorkout.WorkoutID != tt.workoutID {
+				t.Errorf("expected workout ID %d, got %v", tt.workoutID, userWorkout.WorkoutID)
 			}
 		})
 	}
@@ -153,7 +156,7 @@ func TestUserWorkoutService_GetLoggedWorkout(t *testing.T) {
 				m.userWorkouts[1] = &domain.UserWorkout{
 					ID:          1,
 					UserID:      1,
-					WorkoutID:   1,
+					WorkoutID:   int64Ptr(1),
 					WorkoutDate: time.Now(),
 				}
 			},
@@ -176,7 +179,7 @@ func TestUserWorkoutService_GetLoggedWorkout(t *testing.T) {
 				m.userWorkouts[2] = &domain.UserWorkout{
 					ID:          2,
 					UserID:      2, // Different user
-					WorkoutID:   1,
+					WorkoutID:   int64Ptr(1),
 					WorkoutDate: time.Now(),
 				}
 			},
@@ -194,9 +197,9 @@ func TestUserWorkoutService_GetLoggedWorkout(t *testing.T) {
 				tt.setupMock(userWorkoutRepo)
 			}
 
-			service := NewUserWorkoutService(userWorkoutRepo, workoutRepo, workoutMovementRepo)
+			service := NewUserWorkoutService(userWorkoutRepo, workoutRepo, workoutMovementRepo, newMockUserWorkoutMovementRepo(), newMockUserWorkoutWODRepo(), newMockWODRepo(), &mockTransactor{})
 
-			userWorkout, err := service.GetLoggedWorkout(tt.userWorkoutID, tt.userID)
+			userWorkout, err := service.GetLoggedWorkout(context.Background(), tt.userWorkoutID, tt.userID)
 
 			if tt.expectedError != nil {
 				if !errors.Is(err, tt.expectedError) {
@@ -244,7 +247,7 @@ func TestUserWorkoutService_UpdateLoggedWorkout(t *testing.T) {
 				m.userWorkouts[1] = &domain.UserWorkout{
 					ID:          1,
 					UserID:      1,
-					WorkoutID:   1,
+					WorkoutID:   int64Ptr(1),
 					WorkoutDate: time.Now(),
 				}
 			},
@@ -273,7 +276,7 @@ func TestUserWorkoutService_UpdateLoggedWorkout(t *testing.T) {
 				m.userWorkouts[2] = &domain.UserWorkout{
 					ID:          2,
 					UserID:      2, // Different user
-					WorkoutID:   1,
+					WorkoutID:   int64Ptr(1),
 					WorkoutDate: time.Now(),
 				}
 			},
@@ -291,11 +294,13 @@ func TestUserWorkoutService_UpdateLoggedWorkout(t *testing.T) {
 				tt.setupMock(userWorkoutRepo)
 			}
 
-			service := NewUserWorkoutService(userWorkoutRepo, workoutRepo, workoutMovementRepo)
+			service := NewUserWorkoutService(userWorkoutRepo, workoutRepo, workoutMovementRepo, newMockUserWorkoutMovementRepo(), newMockUserWorkoutWODRepo(), newMockWODRepo(), &mockTransactor{})
 
 			err := service.UpdateLoggedWorkout(
+				context.Background(),
 				tt.userWorkoutID,
 				tt.userID,
+				nil,
 				tt.notes,
 				tt.totalTime,
 				tt.workoutType,
@@ -341,7 +346,7 @@ func TestUserWorkoutService_DeleteLoggedWorkout(t *testing.T) {
 				m.userWorkouts[1] = &domain.UserWorkout{
 					ID:          1,
 					UserID:      1,
-					WorkoutID:   1,
+					WorkoutID:   int64Ptr(1),
 					WorkoutDate: time.Now(),
 				}
 			},
@@ -364,7 +369,7 @@ func TestUserWorkoutService_DeleteLoggedWorkout(t *testing.T) {
 				m.userWorkouts[2] = &domain.UserWorkout{
 					ID:          2,
 					UserID:      2, // Different user
-					WorkoutID:   1,
+					WorkoutID:   int64Ptr(1),
 					WorkoutDate: time.Now(),
 				}
 			},
@@ -382,9 +387,9 @@ func TestUserWorkoutService_DeleteLoggedWorkout(t *testing.T) {
 				tt.setupMock(userWorkoutRepo)
 			}
 
-			service := NewUserWorkoutService(userWorkoutRepo, workoutRepo, workoutMovementRepo)
+			service := NewUserWorkoutService(userWorkoutRepo, workoutRepo, workoutMovementRepo, newMockUserWorkoutMovementRepo(), newMockUserWorkoutWODRepo(), newMockWODRepo(), &mockTransactor{})
 
-			err := service.DeleteLoggedWorkout(tt.userWorkoutID, tt.userID)
+			err := service.DeleteLoggedWorkout(context.Background(), tt.userWorkoutID, tt.userID)
 
 			if tt.expectedError != nil {
 				if !errors.Is(err, tt.expectedError) {
@@ -424,19 +429,19 @@ func TestUserWorkoutService_GetWorkoutStatsForMonth(t *testing.T) {
 				m.userWorkouts[1] = &domain.UserWorkout{
 					ID:          1,
 					UserID:      1,
-					WorkoutID:   1,
+					WorkoutID:   int64Ptr(1),
 					WorkoutDate: time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC),
 				}
 				m.userWorkouts[2] = &domain.UserWorkout{
 					ID:          2,
 					UserID:      1,
-					WorkoutID:   1,
+					WorkoutID:   int64Ptr(1),
 					WorkoutDate: time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC),
 				}
 				m.userWorkouts[3] = &domain.UserWorkout{
 					ID:          3,
 					UserID:      1,
-					WorkoutID:   1,
+					WorkoutID:   int64Ptr(1),
 					WorkoutDate: time.Date(2025, 2, 5, 0, 0, 0, 0, time.UTC), // Different month
 				}
 			},
@@ -451,7 +456,7 @@ func TestUserWorkoutService_GetWorkoutStatsForMonth(t *testing.T) {
 				m.userWorkouts[1] = &domain.UserWorkout{
 					ID:          1,
 					UserID:      1,
-					WorkoutID:   1,
+					WorkoutID:   int64Ptr(1),
 					WorkoutDate: time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC),
 				}
 			},
@@ -469,9 +474,9 @@ func TestUserWorkoutService_GetWorkoutStatsForMonth(t *testing.T) {
 				tt.setupMock(userWorkoutRepo)
 			}
 
-			service := NewUserWorkoutService(userWorkoutRepo, workoutRepo, workoutMovementRepo)
+			service := NewUserWorkoutService(userWorkoutRepo, workoutRepo, workoutMovementRepo, newMockUserWorkoutMovementRepo(), newMockUserWorkoutWODRepo(), newMockWODRepo(), &mockTransactor{})
 
-			count, err := service.GetWorkoutStatsForMonth(tt.userID, tt.year, tt.month)
+			count, err := service.GetWorkoutStatsForMonth(context.Background(), tt.userID, tt.year, tt.month)
 
 			if err != nil {
 				t.Errorf("unexpected error: %v", err)
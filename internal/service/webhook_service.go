@@ -0,0 +1,567 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/johnzastrow/actalog/internal/domain"
+	"github.com/johnzastrow/actalog/internal/policy"
+)
+
+var (
+	ErrWebhookNotFound          = errors.New("webhook not found")
+	ErrWebhookUnauthorized      = policy.Denied("unauthorized webhook access")
+	ErrInvalidWebhookURL        = errors.New("webhook URL must be an absolute http or https URL")
+	ErrInvalidWebhookEvent      = errors.New("invalid webhook event type")
+	ErrWebhookAllUsersAdminOnly = policy.Denied("only admins can register webhooks for all users")
+	ErrWebhookAddressBlocked    = errors.New("webhook URL must not point to a private, loopback or link-local address")
+)
+
+// Webhook request headers sent with every delivery
+const (
+	WebhookSignatureHeader = "X-ActaLog-Signature"
+	WebhookEventHeader     = "X-ActaLog-Event"
+	WebhookDeliveryHeader  = "X-ActaLog-Delivery"
+)
+
+const (
+	defaultWebhookMaxAttempts  = 5
+	defaultWebhookBackoff      = 2 * time.Second
+	defaultWebhookPollInterval = 5 * time.Second
+	webhookTimeout             = 10 * time.Second
+	webhookBatchSize           = 50
+)
+
+// blockedWebhookPrefixes are special-purpose ranges that net/netip does not
+// classify as private but that must not be reachable from webhooks either
+var blockedWebhookPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),      // "this" network
+	netip.MustParsePrefix("100.64.0.0/10"),  // carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),   // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"),  // benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),    // reserved and broadcast
+	netip.MustParsePrefix("64:ff9b::/96"),   // NAT64
+	netip.MustParsePrefix("64:ff9b:1::/48"), // local-use NAT64
+}
+
+// WebhookPayload is the JSON body posted to webhook receivers
+type WebhookPayload struct {
+	Event     string      `json:"event"`
+	UserID    int64       `json:"user_id"`
+	Timestamp time.Time   `json:"timestamp"`
+	Data      interface{} `json:"data"`
+}
+
+// WebhookService manages webhook registrations and delivers signed event payloads.
+// Deliveries are queued in the database and attempted by a background worker
+// (see Run), so pending retries survive a restart.
+type WebhookService struct {
+	webhookRepo     domain.WebhookRepository
+	client          *http.Client
+	maxAttempts     int
+	baseBackoff     time.Duration
+	pollInterval    time.Duration
+	allowedNetworks []netip.Prefix
+	wake            chan struct{}
+	processing      sync.Mutex
+}
+
+// NewWebhookService creates a new webhook service. Receivers on private,
+// loopback and link-local addresses are refused unless allowed with
+// SetAllowedNetworks.
+func NewWebhookService(webhookRepo domain.WebhookRepository) *WebhookService {
+	s := &WebhookService{
+		webhookRepo:  webhookRepo,
+		maxAttempts:  defaultWebhookMaxAttempts,
+		baseBackoff:  defaultWebhookBackoff,
+		pollInterval: defaultWebhookPollInterval,
+		wake:         make(chan struct{}, 1),
+	}
+	dialer := &net.Dialer{Timeout: webhookTimeout, Control: s.dialControl}
+	s.client = &http.Client{
+		Timeout: webhookTimeout,
+		Transport: &http.Transport{
+			Proxy:               nil,
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: webhookTimeout,
+			MaxIdleConns:        10,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		// A redirect could point anywhere; receivers must answer directly
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	return s
+}
+
+// SetAllowedNetworks allows webhook receivers in the given CIDR ranges even
+// when they are private or loopback addresses, e.g. a receiver on the LAN
+func (s *WebhookService) SetAllowedNetworks(cidrs []string) error {
+	prefixes := make([]netip.Prefix, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		prefix, err := netip.ParsePrefix(strings.TrimSpace(cidr))
+		if err != nil {
+			return fmt.Errorf("invalid webhook allowed network %q: %w", cidr, err)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	s.allowedNetworks = prefixes
+	return nil
+}
+
+// addressAllowed reports whether webhooks may connect to an IP address:
+// public unicast addresses, plus anything inside the allowed networks
+func (s *WebhookService) addressAllowed(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range s.allowedNetworks {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() || addr.IsLoopback() || addr.IsLinkLocalUnicast() {
+		return false
+	}
+	for _, prefix := range blockedWebhookPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// dialControl runs after the receiver's host name is resolved and refuses
+// the connection if the address is not allowed, so DNS cannot be used to
+// reach internal services
+func (s *WebhookService) dialControl(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrWebhookAddressBlocked, address)
+	}
+	if !s.addressAllowed(addrPort.Addr()) {
+		return ErrWebhookAddressBlocked
+	}
+	return nil
+}
+
+// SignWebhookPayload returns the signature header value for a payload body.
+// Receivers verify deliveries by computing the same HMAC-SHA256 over the raw body.
+func SignWebhookPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Create registers a new webhook for a user and generates its signing secret
+func (s *WebhookService) Create(ctx context.Context, webhook *domain.Webhook, subject policy.Subject) error {
+	if err := s.validateWebhook(webhook); err != nil {
+		return err
+	}
+	webhook.UserID = subject.UserID
+	if err := policy.Authorize(subject, policy.ActionCreate, policy.Webhook(webhook)); err != nil {
+		return ErrWebhookAllUsersAdminOnly
+	}
+
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		return err
+	}
+
+	webhook.Secret = secret
+	webhook.IsActive = true
+
+	if err := s.webhookRepo.Create(ctx, webhook); err != nil {
+		return fmt.Errorf("failed to create webhook: %w", err)
+	}
+	return nil
+}
+
+// Get retrieves a webhook, checking that the caller owns it (admins may access any webhook)
+func (s *WebhookService) Get(ctx context.Context, id int64, subject policy.Subject) (*domain.Webhook, error) {
+	return s.authorizedWebhook(ctx, id, subject, policy.ActionRead)
+}
+
+// authorizedWebhook retrieves a webhook, checking that the caller may perform
+// the action on it
+func (s *WebhookService) authorizedWebhook(ctx context.Context, id int64, subject policy.Subject, action policy.Action) (*domain.Webhook, error) {
+	webhook, err := s.webhookRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook: %w", err)
+	}
+	if webhook == nil {
+		return nil, ErrWebhookNotFound
+	}
+	if err := policy.Authorize(subject, action, policy.Webhook(webhook)); err != nil {
+		return nil, ErrWebhookUnauthorized
+	}
+	return webhook, nil
+}
+
+// List retrieves all webhooks registered by a user
+func (s *WebhookService) List(ctx context.Context, userID int64) ([]*domain.Webhook, error) {
+	webhooks, err := s.webhookRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	return webhooks, nil
+}
+
+// Update updates a webhook's URL, events, description and active flag
+func (s *WebhookService) Update(ctx context.Context, webhook *domain.Webhook, subject policy.Subject) error {
+	existing, err := s.authorizedWebhook(ctx, webhook.ID, subject, policy.ActionUpdate)
+	if err != nil {
+		return err
+	}
+	if err := s.validateWebhook(webhook); err != nil {
+		return err
+	}
+	updated := &domain.Webhook{UserID: existing.UserID, AllUsers: webhook.AllUsers}
+	if err := policy.Authorize(subject, policy.ActionUpdate, policy.Webhook(updated)); err != nil {
+		return ErrWebhookAllUsersAdminOnly
+	}
+
+	existing.URL = webhook.URL
+	existing.Events = webhook.Events
+	existing.AllUsers = webhook.AllUsers
+	existing.Description = webhook.Description
+	existing.IsActive = webhook.IsActive
+
+	if err := s.webhookRepo.Update(ctx, existing); err != nil {
+		return fmt.Errorf("failed to update webhook: %w", err)
+	}
+	*webhook = *existing
+	return nil
+}
+
+// RotateSecret generates a new signing secret for a webhook
+func (s *WebhookService) RotateSecret(ctx context.Context, id int64, subject policy.Subject) (*domain.Webhook, error) {
+	webhook, err := s.authorizedWebhook(ctx, id, subject, policy.ActionUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+	webhook.Secret = secret
+
+	if err := s.webhookRepo.Update(ctx, webhook); err != nil {
+		return nil, fmt.Errorf("failed to update webhook: %w", err)
+	}
+	return webhook, nil
+}
+
+// Delete deletes a webhook and its delivery log
+func (s *WebhookService) Delete(ctx context.Context, id int64, subject policy.Subject) error {
+	if _, err := s.authorizedWebhook(ctx, id, subject, policy.ActionDelete); err != nil {
+		return err
+	}
+	if err := s.webhookRepo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	return nil
+}
+
+// ListDeliveries retrieves the delivery log for a webhook
+func (s *WebhookService) ListDeliveries(ctx context.Context, id int64, subject policy.Subject, limit, offset int) ([]*domain.WebhookDelivery, error) {
+	if _, err := s.Get(ctx, id, subject); err != nil {
+		return nil, err
+	}
+
+	deliveries, err := s.webhookRepo.ListDeliveries(ctx, id, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// SendTest synchronously delivers a test event to a webhook (single attempt) and
+// returns the result. The receiver's response body is not returned.
+func (s *WebhookService) SendTest(ctx context.Context, id int64, subject policy.Subject) (*domain.WebhookDelivery, error) {
+	webhook, err := s.authorizedWebhook(ctx, id, subject, policy.ActionUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	data := map[string]interface{}{
+		"webhook_id": webhook.ID,
+		"message":    "This is a test delivery from ActaLog",
+	}
+	delivery, err := s.newDelivery(ctx, webhook, subject.UserID, domain.WebhookEventTest, data, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.attempt(ctx, webhook, delivery, 1); err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// Publish queues an event for every active webhook subscribed to it for the given
+// user. The background worker delivers it, retrying with backoff; failures are
+// recorded in the delivery log.
+func (s *WebhookService) Publish(ctx context.Context, userID int64, event string, data interface{}) {
+	// The event outlives the request that published it
+	ctx = context.WithoutCancel(ctx)
+
+	webhooks, err := s.webhookRepo.ListActiveForUser(ctx, userID)
+	if err != nil {
+		return
+	}
+
+	queued := false
+	now := time.Now()
+	for _, webhook := range webhooks {
+		if !webhook.Subscribes(event) {
+			continue
+		}
+		if _, err := s.newDelivery(ctx, webhook, userID, event, data, &now); err == nil {
+			queued = true
+		}
+	}
+
+	if queued {
+		select {
+		case s.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Run delivers queued webhook events every poll interval, or as soon as an
+// event is published, until the context is canceled
+func (s *WebhookService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		_, _ = s.ProcessDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+// Wait blocks until an in-progress batch of deliveries has finished (used during shutdown)
+func (s *WebhookService) Wait() {
+	s.processing.Lock()
+	defer s.processing.Unlock()
+}
+
+// ProcessDue attempts every queued delivery whose next attempt is due. Failed
+// deliveries are rescheduled with exponential backoff on network errors, 5xx,
+// 408 and 429 responses until max attempts is reached. Returns the number delivered.
+func (s *WebhookService) ProcessDue(ctx context.Context) (int, error) {
+	s.processing.Lock()
+	defer s.processing.Unlock()
+
+	due, err := s.webhookRepo.ListDueDeliveries(ctx, time.Now(), webhookBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list due webhook deliveries: %w", err)
+	}
+
+	delivered := 0
+	webhooks := make(map[int64]*domain.Webhook)
+	for _, delivery := range due {
+		if ctx.Err() != nil {
+			break
+		}
+
+		webhook, ok := webhooks[delivery.WebhookID]
+		if !ok {
+			if webhook, err = s.webhookRepo.GetByID(ctx, delivery.WebhookID); err != nil {
+				return delivered, fmt.Errorf("failed to get webhook %d: %w", delivery.WebhookID, err)
+			}
+			webhooks[delivery.WebhookID] = webhook
+		}
+		if webhook == nil || !webhook.IsActive {
+			// The webhook was disabled after the event was queued
+			msg := "webhook is no longer active"
+			delivery.Error = &msg
+			delivery.NextAttemptAt = nil
+			if err := s.webhookRepo.UpdateDelivery(ctx, delivery); err != nil {
+				return delivered, fmt.Errorf("failed to update webhook delivery %d: %w", delivery.ID, err)
+			}
+			continue
+		}
+
+		if err := s.attempt(ctx, webhook, delivery, s.maxAttempts); err != nil {
+			return delivered, err
+		}
+		if delivery.Success {
+			delivered++
+		}
+	}
+
+	return delivered, nil
+}
+
+// backoff returns the delay before the next attempt (base * 2^(attempts-1))
+func (s *WebhookService) backoff(attempts int) time.Duration {
+	return s.baseBackoff * time.Duration(1<<(attempts-1))
+}
+
+// newDelivery builds the payload for an event and records a delivery, queued
+// for the worker when nextAttemptAt is set
+func (s *WebhookService) newDelivery(ctx context.Context, webhook *domain.Webhook, userID int64, event string, data interface{}, nextAttemptAt *time.Time) (*domain.WebhookDelivery, error) {
+	body, err := json.Marshal(WebhookPayload{
+		Event:     event,
+		UserID:    userID,
+		Timestamp: time.Now().UTC(),
+		Data:      data,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	delivery := &domain.WebhookDelivery{
+		WebhookID:     webhook.ID,
+		EventType:     event,
+		Payload:       string(body),
+		NextAttemptAt: nextAttemptAt,
+	}
+	if err := s.webhookRepo.CreateDelivery(ctx, delivery); err != nil {
+		return nil, fmt.Errorf("failed to record webhook delivery: %w", err)
+	}
+	return delivery, nil
+}
+
+// attempt posts the payload once and records the outcome, scheduling another
+// attempt if the failure is retryable and attempts remain. An attempt cut
+// short by ctx is not recorded, so the delivery stays due.
+func (s *WebhookService) attempt(ctx context.Context, webhook *domain.Webhook, delivery *domain.WebhookDelivery, maxAttempts int) error {
+	statusCode, err := s.post(ctx, webhook, delivery)
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	delivery.Attempts++
+	delivery.StatusCode = nil
+	delivery.Error = nil
+	delivery.NextAttemptAt = nil
+	if statusCode != 0 {
+		delivery.StatusCode = &statusCode
+	}
+
+	retryable := true
+	switch {
+	case err != nil:
+		msg := err.Error()
+		if errors.Is(err, ErrWebhookAddressBlocked) {
+			msg = ErrWebhookAddressBlocked.Error()
+			retryable = false
+		}
+		delivery.Error = &msg
+	case statusCode >= 200 && statusCode < 300:
+		now := time.Now()
+		delivery.Success = true
+		delivery.DeliveredAt = &now
+		retryable = false
+	default:
+		msg := fmt.Sprintf("receiver returned status %d", statusCode)
+		delivery.Error = &msg
+		retryable = statusCode >= 500 || statusCode == http.StatusRequestTimeout || statusCode == http.StatusTooManyRequests
+	}
+
+	if retryable && delivery.Attempts < maxAttempts {
+		next := time.Now().Add(s.backoff(delivery.Attempts))
+		delivery.NextAttemptAt = &next
+	}
+
+	if err := s.webhookRepo.UpdateDelivery(ctx, delivery); err != nil {
+		return fmt.Errorf("failed to update webhook delivery %d: %w", delivery.ID, err)
+	}
+	return nil
+}
+
+// post sends a single signed delivery attempt. The response body is discarded
+// so receivers cannot be used to read internal responses.
+func (s *WebhookService) post(ctx context.Context, webhook *domain.Webhook, delivery *domain.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "ActaLog-Webhook/1.0")
+	req.Header.Set(WebhookEventHeader, delivery.EventType)
+	req.Header.Set(WebhookDeliveryHeader, fmt.Sprintf("%d", delivery.ID))
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(webhook.Secret, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	return resp.StatusCode, nil
+}
+
+// validateWebhook checks the URL and event subscriptions of a webhook. Host
+// names are checked again when each delivery connects.
+func (s *WebhookService) validateWebhook(webhook *domain.Webhook) error {
+	u, err := url.Parse(strings.TrimSpace(webhook.URL))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.User != nil {
+		return ErrInvalidWebhookURL
+	}
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if !s.addressAllowed(addr) {
+			return ErrWebhookAddressBlocked
+		}
+	} else if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		if !s.addressAllowed(netip.MustParseAddr("127.0.0.1")) {
+			return ErrWebhookAddressBlocked
+		}
+	}
+	webhook.URL = u.String()
+
+	if len(webhook.Events) == 0 {
+		return ErrInvalidWebhookEvent
+	}
+	for _, event := range webhook.Events {
+		valid := false
+		for _, known := range domain.WebhookEvents {
+			if event == known {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("%w: %s", ErrInvalidWebhookEvent, event)
+		}
+	}
+	return nil
+}
+
+// generateWebhookSecret creates a random signing secret
+func generateWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,556 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/johnzastrow/actalog/internal/domain"
+	"github.com/johnzastrow/actalog/internal/policy"
+)
+
+// Mock WebhookRepository
+type mockWebhookRepo struct {
+	mu         sync.Mutex
+	webhooks   map[int64]*domain.Webhook
+	deliveries map[int64]*domain.WebhookDelivery
+	nextID     int64
+}
+
+func newMockWebhookRepo() *mockWebhookRepo {
+	return &mockWebhookRepo{
+		webhooks:   make(map[int64]*domain.Webhook),
+		deliveries: make(map[int64]*domain.WebhookDelivery),
+		nextID:     1,
+	}
+}
+
+func (m *mockWebhookRepo) Create(ctx context.Context, webhook *domain.Webhook) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	webhook.ID = m.nextID
+	m.nextID++
+	m.webhooks[webhook.ID] = webhook
+	return nil
+}
+
+func (m *mockWebhookRepo) GetByID(ctx context.Context, id int64) (*domain.Webhook, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	webhook, ok := m.webhooks[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *webhook
+	return &copied, nil
+}
+
+func (m *mockWebhookRepo) ListByUser(ctx context.Context, userID int64) ([]*domain.Webhook, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var result []*domain.Webhook
+	for _, webhook := range m.webhooks {
+		if webhook.UserID == userID {
+			result = append(result, webhook)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockWebhookRepo) ListActiveForUser(ctx context.Context, userID int64) ([]*domain.Webhook, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var result []*domain.Webhook
+	for _, webhook := range m.webhooks {
+		if webhook.IsActive && (webhook.UserID == userID || webhook.AllUsers) {
+			result = append(result, webhook)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockWebhookRepo) Update(ctx context.Context, webhook *domain.Webhook) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	copied := *webhook
+	m.webhooks[webhook.ID] = &copied
+	return nil
+}
+
+func (m *mockWebhookRepo) Delete(ctx context.Context, id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.webhooks, id)
+	return nil
+}
+
+func (m *mockWebhookRepo) CreateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delivery.ID = m.nextID
+	m.nextID++
+	copied := *delivery
+	m.deliveries[delivery.ID] = &copied
+	return nil
+}
+
+func (m *mockWebhookRepo) UpdateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	copied := *delivery
+	m.deliveries[delivery.ID] = &copied
+	return nil
+}
+
+func (m *mockWebhookRepo) ListDeliveries(ctx context.Context, webhookID int64, limit, offset int) ([]*domain.WebhookDelivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var result []*domain.WebhookDelivery
+	for _, d := range m.deliveries {
+		if d.WebhookID == webhookID {
+			result = append(result, d)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockWebhookRepo) ListDueDeliveries(ctx context.Context, now time.Time, limit int) ([]*domain.WebhookDelivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var result []*domain.WebhookDelivery
+	for _, d := range m.deliveries {
+		if d.NextAttemptAt != nil && !d.NextAttemptAt.After(now) {
+			copied := *d
+			result = append(result, &copied)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+// pending counts the deliveries still queued for another attempt
+func (m *mockWebhookRepo) pending() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	count := 0
+	for _, d := range m.deliveries {
+		if d.NextAttemptAt != nil {
+			count++
+		}
+	}
+	return count
+}
+
+// newTestWebhookService creates a service with fast retries that may deliver
+// to httptest servers on the loopback interface
+func newTestWebhookService(repo *mockWebhookRepo) *WebhookService {
+	s := NewWebhookService(repo)
+	s.baseBackoff = time.Millisecond
+	s.maxAttempts = 3
+	if err := s.SetAllowedNetworks([]string{"127.0.0.0/8"}); err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// processAll runs the delivery worker until no delivery is left queued
+func processAll(t *testing.T, s *WebhookService, repo *mockWebhookRepo) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for repo.pending() > 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("expected queued deliveries to finish")
+		}
+		if _, err := s.ProcessDue(context.Background()); err != nil {
+			t.Fatalf("ProcessDue failed: %v", err)
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+}
+
+func TestWebhookService_Create(t *testing.T) {
+	tests := []struct {
+		name          string
+		webhook       *domain.Webhook
+		role          string
+		expectedError error
+	}{
+		{
+			name:    "valid webhook",
+			webhook: &domain.Webhook{URL: "https://example.com/hook", Events: []string{domain.WebhookEventPRSet}},
+		},
+		{
+			name:          "invalid URL scheme",
+			webhook:       &domain.Webhook{URL: "ftp://example.com/hook", Events: []string{domain.WebhookEventPRSet}},
+			expectedError: ErrInvalidWebhookURL,
+		},
+		{
+			name:          "no events",
+			webhook:       &domain.Webhook{URL: "https://example.com/hook"},
+			expectedError: ErrInvalidWebhookEvent,
+		},
+		{
+			name:          "unknown event",
+			webhook:       &domain.Webhook{URL: "https://example.com/hook", Events: []string{"workout.exploded"}},
+			expectedError: ErrInvalidWebhookEvent,
+		},
+		{
+			name:          "all users requires admin",
+			webhook:       &domain.Webhook{URL: "https://example.com/hook", Events: []string{domain.WebhookEventPRSet}, AllUsers: true},
+			expectedError: ErrWebhookAllUsersAdminOnly,
+		},
+		{
+			name:    "admin all users webhook",
+			webhook: &domain.Webhook{URL: "https://example.com/hook", Events: []string{domain.WebhookEventPRSet}, AllUsers: true},
+			role:    policy.RoleAdmin,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestWebhookService(newMockWebhookRepo())
+
+			err := s.Create(context.Background(), tt.webhook, policy.User(1, tt.role))
+			if tt.expectedError != nil {
+				if !errors.Is(err, tt.expectedError) {
+					t.Errorf("expected error %v, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.webhook.Secret == "" {
+				t.Error("expected signing secret to be generated")
+			}
+			if !tt.webhook.IsActive {
+				t.Error("expected new webhook to be active")
+			}
+		})
+	}
+}
+
+func TestWebhookService_Ownership(t *testing.T) {
+	repo := newMockWebhookRepo()
+	s := newTestWebhookService(repo)
+
+	webhook := &domain.Webhook{URL: "https://example.com/hook", Events: []string{domain.WebhookEventPRSet}}
+	if err := s.Create(context.Background(), webhook, policy.User(1, policy.RoleUser)); err != nil {
+		t.Fatalf("failed to create webhook: %v", err)
+	}
+
+	if _, err := s.Get(context.Background(), webhook.ID, policy.User(2, policy.RoleUser)); !errors.Is(err, ErrWebhookUnauthorized) {
+		t.Errorf("expected ErrWebhookUnauthorized, got %v", err)
+	}
+	if err := s.Delete(context.Background(), webhook.ID, policy.User(2, policy.RoleUser)); !errors.Is(err, ErrWebhookUnauthorized) {
+		t.Errorf("expected ErrWebhookUnauthorized, got %v", err)
+	}
+	if _, err := s.Get(context.Background(), webhook.ID, policy.User(2, policy.RoleAdmin)); err != nil {
+		t.Errorf("expected admin access, got %v", err)
+	}
+	if _, err := s.Get(context.Background(), 999, policy.User(1, policy.RoleUser)); !errors.Is(err, ErrWebhookNotFound) {
+		t.Errorf("expected ErrWebhookNotFound, got %v", err)
+	}
+}
+
+func TestWebhookService_PublishSignsPayload(t *testing.T) {
+	type received struct {
+		event     string
+		signature string
+		body      []byte
+	}
+	receivedCh := make(chan received, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		receivedCh <- received{
+			event:     r.Header.Get(WebhookEventHeader),
+			signature: r.Header.Get(WebhookSignatureHeader),
+			body:      body,
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	repo := newMockWebhookRepo()
+	s := newTestWebhookService(repo)
+
+	webhook := &domain.Webhook{URL: server.URL, Events: []string{domain.WebhookEventPRSet}}
+	if err := s.Create(context.Background(), webhook, policy.User(1, policy.RoleUser)); err != nil {
+		t.Fatalf("failed to create webhook: %v", err)
+	}
+
+	// Not subscribed: should not be delivered
+	s.Publish(context.Background(), 1, domain.WebhookEventWorkoutLogged, map[string]string{"ignored": "true"})
+	// Other user's event: should not be delivered
+	s.Publish(context.Background(), 2, domain.WebhookEventPRSet, map[string]string{"ignored": "true"})
+	s.Publish(context.Background(), 1, domain.WebhookEventPRSet, map[string]int{"movement_id": 7})
+	processAll(t, s, repo)
+
+	got := <-receivedCh
+	select {
+	case extra := <-receivedCh:
+		t.Fatalf("unexpected extra delivery: %s", extra.body)
+	default:
+	}
+
+	if got.event != domain.WebhookEventPRSet {
+		t.Errorf("expected event header %q, got %q", domain.WebhookEventPRSet, got.event)
+	}
+	if expected := SignWebhookPayload(webhook.Secret, got.body); got.signature != expected {
+		t.Errorf("signature mismatch: expected %q, got %q", expected, got.signature)
+	}
+
+	var payload WebhookPayload
+	if err := json.Unmarshal(got.body, &payload); err != nil {
+		t.Fatalf("invalid payload JSON: %v", err)
+	}
+	if payload.Event != domain.WebhookEventPRSet || payload.UserID != 1 {
+		t.Errorf("unexpected payload: %+v", payload)
+	}
+}
+
+func TestWebhookService_Retries(t *testing.T) {
+	tests := []struct {
+		name             string
+		statuses         []int
+		expectedAttempts int
+		expectedSuccess  bool
+	}{
+		{name: "success on first attempt", statuses: []int{200}, expectedAttempts: 1, expectedSuccess: true},
+		{name: "retries server errors then succeeds", statuses: []int{500, 503, 204}, expectedAttempts: 3, expectedSuccess: true},
+		{name: "gives up after max attempts", statuses: []int{500, 500, 500, 500}, expectedAttempts: 3, expectedSuccess: false},
+		{name: "does not retry client errors", statuses: []int{400, 200}, expectedAttempts: 1, expectedSuccess: false},
+		{name: "retries rate limiting", statuses: []int{429, 200}, expectedAttempts: 2, expectedSuccess: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := atomic.AddInt32(&calls, 1)
+				w.WriteHeader(tt.statuses[n-1])
+			}))
+			defer server.Close()
+
+			repo := newMockWebhookRepo()
+			s := newTestWebhookService(repo)
+
+			webhook := &domain.Webhook{URL: server.URL, Events: []string{domain.WebhookEventWorkoutLogged}}
+			if err := s.Create(context.Background(), webhook, policy.User(1, policy.RoleUser)); err != nil {
+				t.Fatalf("failed to create webhook: %v", err)
+			}
+
+			s.Publish(context.Background(), 1, domain.WebhookEventWorkoutLogged, nil)
+			processAll(t, s, repo)
+
+			deliveries, _ := repo.ListDeliveries(context.Background(), webhook.ID, 10, 0)
+			if len(deliveries) != 1 {
+				t.Fatalf("expected 1 delivery, got %d", len(deliveries))
+			}
+			d := deliveries[0]
+			if d.Attempts != tt.expectedAttempts {
+				t.Errorf("expected %d attempts, got %d", tt.expectedAttempts, d.Attempts)
+			}
+			if d.Success != tt.expectedSuccess {
+				t.Errorf("expected success=%t, got %t", tt.expectedSuccess, d.Success)
+			}
+			if int(atomic.LoadInt32(&calls)) != tt.expectedAttempts {
+				t.Errorf("expected receiver to be called %d times, got %d", tt.expectedAttempts, calls)
+			}
+		})
+	}
+}
+
+func TestWebhookService_SendTest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("boom"))
+	}))
+	defer server.Close()
+
+	repo := newMockWebhookRepo()
+	s := newTestWebhookService(repo)
+
+	webhook := &domain.Webhook{URL: server.URL, Events: []string{domain.WebhookEventPRSet}}
+	if err := s.Create(context.Background(), webhook, policy.User(1, policy.RoleUser)); err != nil {
+		t.Fatalf("failed to create webhook: %v", err)
+	}
+
+	delivery, err := s.SendTest(context.Background(), webhook.ID, policy.User(1, policy.RoleUser))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if delivery.Success || delivery.Attempts != 1 {
+		t.Errorf("expected a single failed attempt, got success=%t attempts=%d", delivery.Success, delivery.Attempts)
+	}
+	if delivery.StatusCode == nil || *delivery.StatusCode != http.StatusInternalServerError {
+		t.Errorf("expected status 500, got %v", delivery.StatusCode)
+	}
+	if delivery.NextAttemptAt != nil || repo.pending() != 0 {
+		t.Error("expected a test delivery not to be retried")
+	}
+}
+
+func TestWebhookService_QueuedDeliveriesSurviveRestart(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	repo := newMockWebhookRepo()
+	s := newTestWebhookService(repo)
+	webhook := &domain.Webhook{URL: server.URL, Events: []string{domain.WebhookEventWorkoutLogged}}
+	if err := s.Create(context.Background(), webhook, policy.User(1, policy.RoleUser)); err != nil {
+		t.Fatalf("failed to create webhook: %v", err)
+	}
+
+	// The first process queues the event but stops before its worker runs
+	s.Publish(context.Background(), 1, domain.WebhookEventWorkoutLogged, nil)
+	if repo.pending() != 1 || atomic.LoadInt32(&calls) != 0 {
+		t.Fatalf("expected the delivery to be queued, got %d pending and %d calls", repo.pending(), calls)
+	}
+
+	processAll(t, newTestWebhookService(repo), repo)
+	deliveries, _ := repo.ListDeliveries(context.Background(), webhook.ID, 10, 0)
+	if len(deliveries) != 1 || !deliveries[0].Success || atomic.LoadInt32(&calls) != 1 {
+		t.Errorf("expected the queued delivery to be sent once after the restart, got %+v", deliveries)
+	}
+}
+
+func TestWebhookService_BlocksInternalAddresses(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	repo := newMockWebhookRepo()
+	s := NewWebhookService(repo)
+	subject := policy.User(1, policy.RoleUser)
+
+	for _, rawURL := range []string{
+		server.URL,
+		"http://localhost:8080/hook",
+		"http://169.254.169.254/latest/meta-data/",
+		"http://10.0.0.5/hook",
+		"http://[::1]/hook",
+		"http://[::ffff:192.168.1.1]/hook",
+		"http://100.64.0.1/hook",
+	} {
+		webhook := &domain.Webhook{URL: rawURL, Events: []string{domain.WebhookEventPRSet}}
+		if err := s.Create(context.Background(), webhook, subject); !errors.Is(err, ErrWebhookAddressBlocked) {
+			t.Errorf("%s: expected ErrWebhookAddressBlocked, got %v", rawURL, err)
+		}
+	}
+
+	// Host names are checked when the delivery connects, after resolution
+	webhook := &domain.Webhook{URL: "http://receiver.example.com/hook", Events: []string{domain.WebhookEventPRSet}}
+	if err := s.Create(context.Background(), webhook, subject); err != nil {
+		t.Fatalf("failed to create webhook: %v", err)
+	}
+	repo.webhooks[webhook.ID].URL = server.URL
+	delivery, err := s.SendTest(context.Background(), webhook.ID, subject)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if delivery.Success || delivery.Error == nil || *delivery.Error != ErrWebhookAddressBlocked.Error() || atomic.LoadInt32(&calls) != 0 {
+		t.Errorf("expected the loopback receiver to be refused, got %+v", delivery)
+	}
+
+	// Allowed networks open up private ranges
+	if err := s.SetAllowedNetworks([]string{"10.0.0.0/8"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Create(context.Background(), &domain.Webhook{URL: "http://10.0.0.5/hook", Events: []string{domain.WebhookEventPRSet}}, subject); err != nil {
+		t.Errorf("expected an allowed network to be accepted, got %v", err)
+	}
+	if err := s.SetAllowedNetworks([]string{"not a network"}); err == nil {
+		t.Error("expected an invalid network to be rejected")
+	}
+}
+
+// recordingPublisher captures published workout events
+type recordingPublisher struct {
+	events []string
+	data   []interface{}
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, userID int64, event string, data interface{}) {
+	p.events = append(p.events, event)
+	p.data = append(p.data, data)
+}
+
+func TestUserWorkoutService_PublishesEvents(t *testing.T) {
+	userWorkoutRepo := newMockUserWorkoutRepo()
+	workoutRepo := newMockWorkoutRepo()
+	workoutRepo.workouts[1] = &domain.Workout{ID: 1, Name: "Fran"}
+
+	svc := NewUserWorkoutService(userWorkoutRepo, workoutRepo, &mockWorkoutMovementRepo{}, newMockUserWorkoutMovementRepo(), newMockUserWorkoutWODRepo(), newMockWODRepo(), &mockTransactor{})
+	publisher := &recordingPublisher{}
+	svc.SetEventPublisher(publisher)
+
+	templateID := int64(1)
+	uw, err := svc.LogWorkoutWithPerformance(context.Background(), 1, &templateID, nil, time.Now(), nil, nil, nil,
+		[]*domain.UserWorkoutMovement{{MovementID: 3, Weight: float64Ptr(225)}}, nil)
+	if err != nil {
+		t.Fatalf("failed to log workout: %v", err)
+	}
+	if err := svc.UpdateLoggedWorkout(context.Background(), uw.ID, 1, nil, stringPtr("updated"), nil, nil); err != nil {
+		t.Fatalf("failed to update workout: %v", err)
+	}
+	// An edit keeps the squat PR it already had and sets a new one
+	if _, err := svc.UpdateLoggedWorkoutWithPerformance(context.Background(), uw.ID, 1, nil, nil, nil, nil,
+		[]*domain.UserWorkoutMovement{{MovementID: 3, Weight: float64Ptr(225)}, {MovementID: 4, Weight: float64Ptr(95)}}, nil); err != nil {
+		t.Fatalf("failed to update workout movements: %v", err)
+	}
+	if err := svc.DeleteLoggedWorkout(context.Background(), uw.ID, 1); err != nil {
+		t.Fatalf("failed to delete workout: %v", err)
+	}
+
+	expected := []string{
+		domain.WebhookEventWorkoutLogged,
+		domain.WebhookEventPRSet,
+		domain.WebhookEventWorkoutUpdated,
+		domain.WebhookEventWorkoutUpdated,
+		domain.WebhookEventPRSet,
+		domain.WebhookEventWorkoutDeleted,
+	}
+	if len(publisher.events) != len(expected) {
+		t.Fatalf("expected events %v, got %v", expected, publisher.events)
+	}
+	for i := range expected {
+		if publisher.events[i] != expected[i] {
+			t.Errorf("event %d: expected %q, got %q", i, expected[i], publisher.events[i])
+		}
+	}
+
+	// Updates carry the whole workout as saved
+	if event, ok := publisher.data[2].(WorkoutEvent); !ok || len(event.Movements) != 1 || event.Workout.Notes == nil || *event.Workout.Notes != "updated" {
+		t.Errorf("expected the notes update to carry the logged squat, got %+v", publisher.data[2])
+	}
+	if event, ok := publisher.data[3].(WorkoutEvent); !ok || len(event.Movements) != 2 {
+		t.Errorf("expected the movements update to carry both movements, got %+v", publisher.data[3])
+	}
+	if event, ok := publisher.data[4].(PREvent); !ok || event.Movement == nil || event.Movement.MovementID != 4 {
+		t.Errorf("expected the new PR to be published, got %+v", publisher.data[4])
+	}
+}
+
+func float64Ptr(f float64) *float64 {
+	return &f
+}
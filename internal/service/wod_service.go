@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"sort"
@@ -8,12 +9,13 @@ import (
 	"time"
 
 	"github.com/johnzastrow/actalog/internal/domain"
+	"github.com/johnzastrow/actalog/internal/policy"
 )
 
 var (
 	ErrWODNotFound       = errors.New("wod not found")
-	ErrWODUnauthorized   = errors.New("unauthorized: cannot modify standard WOD")
-	ErrWODOwnership      = errors.New("unauthorized: not the owner of this WOD")
+	ErrWODUnauthorized   = policy.Denied("unauthorized: cannot modify standard WOD")
+	ErrWODOwnership      = policy.Denied("unauthorized: not the owner of this WOD")
 	ErrWODNameRequired   = errors.New("wod name is required")
 	ErrWODSourceRequired = errors.New("wod source is required")
 	ErrWODTypeRequired   = errors.New("wod type is required")
@@ -33,14 +35,14 @@ func NewWODService(wodRepo domain.WODRepository) *WODService {
 }
 
 // Create creates a new custom WOD with validation
-func (s *WODService) Create(wod *domain.WOD, userID int64) error {
+func (s *WODService) Create(ctx context.Context, wod *domain.WOD, userID int64) error {
 	// Validate required fields
 	if err := s.validateWOD(wod); err != nil {
 		return err
 	}
 
 	// Check for duplicate name
-	existing, err := s.wodRepo.GetByName(wod.Name)
+	existing, err := s.wodRepo.GetByName(ctx, wod.Name)
 	if err != nil {
 		return fmt.Errorf("failed to check for duplicate WOD name: %w", err)
 	}
@@ -56,7 +58,7 @@ func (s *WODService) Create(wod *domain.WOD, userID int64) error {
 	wod.UpdatedAt = now
 
 	// Create WOD
-	err = s.wodRepo.Create(wod)
+	err = s.wodRepo.Create(ctx, wod)
 	if err != nil {
 		return fmt.Errorf("failed to create wod: %w", err)
 	}
@@ -65,8 +67,8 @@ func (s *WODService) Create(wod *domain.WOD, userID int64) error {
 }
 
 // GetByID retrieves a WOD by ID
-func (s *WODService) GetByID(id int64) (*domain.WOD, error) {
-	wod, err := s.wodRepo.GetByID(id)
+func (s *WODService) GetByID(ctx context.Context, id int64) (*domain.WOD, error) {
+	wod, err := s.wodRepo.GetByID(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get wod: %w", err)
 	}
@@ -78,12 +80,12 @@ func (s *WODService) GetByID(id int64) (*domain.WOD, error) {
 }
 
 // GetByName retrieves a WOD by name
-func (s *WODService) GetByName(name string) (*domain.WOD, error) {
+func (s *WODService) GetByName(ctx context.Context, name string) (*domain.WOD, error) {
 	if strings.TrimSpace(name) == "" {
 		return nil, ErrWODNameRequired
 	}
 
-	wod, err := s.wodRepo.GetByName(name)
+	wod, err := s.wodRepo.GetByName(ctx, name)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get wod by name: %w", err)
 	}
@@ -95,8 +97,8 @@ func (s *WODService) GetByName(name string) (*domain.WOD, error) {
 }
 
 // List retrieves WODs with optional filtering
-func (s *WODService) List(filters map[string]interface{}, limit, offset int) ([]*domain.WOD, error) {
-	wods, err := s.wodRepo.List(filters, limit, offset)
+func (s *WODService) List(ctx context.Context, filters map[string]interface{}, limit, offset int) ([]*domain.WOD, error) {
+	wods, err := s.wodRepo.List(ctx, filters, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list wods: %w", err)
 	}
@@ -105,8 +107,8 @@ func (s *WODService) List(filters map[string]interface{}, limit, offset int) ([]
 }
 
 // ListStandard retrieves all standard (pre-seeded) WODs
-func (s *WODService) ListStandard(limit, offset int) ([]*domain.WOD, error) {
-	wods, err := s.wodRepo.ListStandard(limit, offset)
+func (s *WODService) ListStandard(ctx context.Context, limit, offset int) ([]*domain.WOD, error) {
+	wods, err := s.wodRepo.ListStandard(ctx, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list standard wods: %w", err)
 	}
@@ -115,8 +117,8 @@ func (s *WODService) ListStandard(limit, offset int) ([]*domain.WOD, error) {
 }
 
 // ListByUser retrieves all custom WODs created by a specific user
-func (s *WODService) ListByUser(userID int64, limit, offset int) ([]*domain.WOD, error) {
-	wods, err := s.wodRepo.ListByUser(userID, limit, offset)
+func (s *WODService) ListByUser(ctx context.Context, userID int64, limit, offset int) ([]*domain.WOD, error) {
+	wods, err := s.wodRepo.ListByUser(ctx, userID, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list user wods: %w", err)
 	}
@@ -125,9 +127,9 @@ func (s *WODService) ListByUser(userID int64, limit, offset int) ([]*domain.WOD,
 }
 
 // ListAll retrieves all WODs (standard + user's custom) - convenience method
-func (s *WODService) ListAll(userID *int64, limit, offset int) ([]*domain.WOD, error) {
+func (s *WODService) ListAll(ctx context.Context, userID *int64, limit, offset int) ([]*domain.WOD, error) {
 	// Get standard WODs (with large limit to get all for combining)
-	standard, err := s.wodRepo.ListStandard(10000, 0)
+	standard, err := s.wodRepo.ListStandard(ctx, 10000, 0)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list standard wods: %w", err)
 	}
@@ -138,7 +140,7 @@ func (s *WODService) ListAll(userID *int64, limit, offset int) ([]*domain.WOD, e
 	}
 
 	// Get user's custom WODs (with large limit to get all for combining)
-	custom, err := s.wodRepo.ListByUser(*userID, 10000, 0)
+	custom, err := s.wodRepo.ListByUser(ctx, *userID, 10000, 0)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list user wods: %w", err)
 	}
@@ -152,14 +154,14 @@ func (s *WODService) ListAll(userID *int64, limit, offset int) ([]*domain.WOD, e
 }
 
 // Update updates an existing custom WOD with authorization checks
-func (s *WODService) Update(wod *domain.WOD, userID int64) error {
+func (s *WODService) Update(ctx context.Context, wod *domain.WOD, subject policy.Subject) error {
 	// Validate required fields
 	if err := s.validateWOD(wod); err != nil {
 		return err
 	}
 
 	// Get existing WOD
-	existing, err := s.wodRepo.GetByID(wod.ID)
+	existing, err := s.wodRepo.GetByID(ctx, wod.ID)
 	if err != nil {
 		return fmt.Errorf("failed to get wod: %w", err)
 	}
@@ -167,19 +169,13 @@ func (s *WODService) Update(wod *domain.WOD, userID int64) error {
 		return ErrWODNotFound
 	}
 
-	// Check if it's a standard WOD
-	if existing.IsStandard {
-		return ErrWODUnauthorized
-	}
-
-	// Check ownership
-	if existing.CreatedBy == nil || *existing.CreatedBy != userID {
-		return ErrWODOwnership
+	if err := authorizeWOD(subject, policy.ActionUpdate, existing); err != nil {
+		return err
 	}
 
 	// Check for duplicate name (if name changed)
 	if existing.Name != wod.Name {
-		duplicate, err := s.wodRepo.GetByName(wod.Name)
+		duplicate, err := s.wodRepo.GetByName(ctx, wod.Name)
 		if err != nil {
 			return fmt.Errorf("failed to check for duplicate WOD name: %w", err)
 		}
@@ -197,7 +193,7 @@ func (s *WODService) Update(wod *domain.WOD, userID int64) error {
 	wod.CreatedAt = existing.CreatedAt
 
 	// Update WOD
-	err = s.wodRepo.Update(wod)
+	err = s.wodRepo.Update(ctx, wod)
 	if err != nil {
 		return fmt.Errorf("failed to update wod: %w", err)
 	}
@@ -206,9 +202,9 @@ func (s *WODService) Update(wod *domain.WOD, userID int64) error {
 }
 
 // Delete deletes a custom WOD with authorization checks
-func (s *WODService) Delete(id int64, userID int64) error {
+func (s *WODService) Delete(ctx context.Context, id int64, subject policy.Subject) error {
 	// Get existing WOD
-	wod, err := s.wodRepo.GetByID(id)
+	wod, err := s.wodRepo.GetByID(ctx, id)
 	if err != nil {
 		return fmt.Errorf("failed to get wod: %w", err)
 	}
@@ -216,18 +212,12 @@ func (s *WODService) Delete(id int64, userID int64) error {
 		return ErrWODNotFound
 	}
 
-	// Check if it's a standard WOD
-	if wod.IsStandard {
-		return ErrWODUnauthorized
-	}
-
-	// Check ownership
-	if wod.CreatedBy == nil || *wod.CreatedBy != userID {
-		return ErrWODOwnership
+	if err := authorizeWOD(subject, policy.ActionDelete, wod); err != nil {
+		return err
 	}
 
 	// Delete WOD
-	err = s.wodRepo.Delete(id)
+	err = s.wodRepo.Delete(ctx, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete wod: %w", err)
 	}
@@ -236,13 +226,13 @@ func (s *WODService) Delete(id int64, userID int64) error {
 }
 
 // Search searches for WODs by name (partial match)
-func (s *WODService) Search(query string, limit int) ([]*domain.WOD, error) {
+func (s *WODService) Search(ctx context.Context, query string, limit int) ([]*domain.WOD, error) {
 	// Validate query
 	if strings.TrimSpace(query) == "" {
 		return []*domain.WOD{}, nil
 	}
 
-	wods, err := s.wodRepo.Search(query, limit)
+	wods, err := s.wodRepo.Search(ctx, query, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search wods: %w", err)
 	}
@@ -251,7 +241,7 @@ func (s *WODService) Search(query string, limit int) ([]*domain.WOD, error) {
 }
 
 // Count returns the total count of WODs
-func (s *WODService) Count(isStandard *bool) (int64, error) {
+func (s *WODService) Count(ctx context.Context, isStandard *bool) (int64, error) {
 	// Count all WODs or filtered by standard status
 	var filters map[string]interface{}
 	if isStandard != nil {
@@ -260,7 +250,7 @@ func (s *WODService) Count(isStandard *bool) (int64, error) {
 		}
 	}
 
-	wods, err := s.wodRepo.List(filters, 10000, 0)
+	wods, err := s.wodRepo.List(ctx, filters, 10000, 0)
 	if err != nil {
 		return 0, fmt.Errorf("failed to count wods: %w", err)
 	}
@@ -361,3 +351,17 @@ func (s *WODService) paginateWODs(wods []*domain.WOD, limit, offset int) []*doma
 
 	return wods[start:end]
 }
+
+// authorizeWOD checks the policy for a WOD, returning the WOD-specific error
+// for a denial
+func authorizeWOD(subject policy.Subject, action policy.Action, wod *domain.WOD) error {
+	err := policy.Authorize(subject, action, policy.WOD(wod))
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, policy.ErrSharedResource):
+		return ErrWODUnauthorized
+	default:
+		return ErrWODOwnership
+	}
+}
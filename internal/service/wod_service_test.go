@@ -1,11 +1,13 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
 
 	"github.com/johnzastrow/actalog/internal/domain"
+	"github.com/johnzastrow/actalog/internal/policy"
 )
 
 func TestWODService_CreateWOD(t *testing.T) {
@@ -41,7 +43,7 @@ func TestWODService_CreateWOD(t *testing.T) {
 
 			service := NewWODService(wodRepo)
 
-			err := service.CreateWOD(tt.userID, tt.wod)
+			err := service.Create(context.Background(), tt.wod, tt.userID)
 
 			if tt.expectedError != nil {
 				if !errors.Is(err, tt.expectedError) {
@@ -116,7 +118,7 @@ func TestWODService_GetWOD(t *testing.T) {
 
 			service := NewWODService(wodRepo)
 
-			wod, err := service.GetWOD(tt.wodID)
+			wod, err := service.GetByID(context.Background(), tt.wodID)
 
 			if tt.expectedError {
 				if err == nil {
@@ -181,7 +183,7 @@ func TestWODService_GetWODByName(t *testing.T) {
 
 			service := NewWODService(wodRepo)
 
-			wod, err := service.GetWODByName(tt.wodName)
+			wod, err := service.GetByName(context.Background(), tt.wodName)
 
 			if tt.expectedError {
 				if err == nil {
@@ -254,7 +256,7 @@ func TestWODService_ListStandardWODs(t *testing.T) {
 
 			service := NewWODService(wodRepo)
 
-			wods, err := service.ListStandardWODs()
+			wods, err := service.ListStandard(context.Background(), 100, 0)
 
 			if err != nil {
 				t.Errorf("unexpected error: %v", err)
@@ -333,7 +335,7 @@ func TestWODService_ListUserWODs(t *testing.T) {
 
 			service := NewWODService(wodRepo)
 
-			wods, err := service.ListUserWODs(tt.userID)
+			wods, err := service.ListByUser(context.Background(), tt.userID, 100, 0)
 
 			if err != nil {
 				t.Errorf("unexpected error: %v", err)
@@ -406,7 +408,7 @@ func TestWODService_ListAllWODs(t *testing.T) {
 
 			service := NewWODService(wodRepo)
 
-			wods, err := service.ListAllWODs(tt.userID)
+			wods, err := service.ListAll(context.Background(), &tt.userID, 100, 0)
 
 			if err != nil {
 				t.Errorf("unexpected error: %v", err)
@@ -450,7 +452,7 @@ func TestWODService_SearchWODs(t *testing.T) {
 			expectedCount: 2, // "Fran" and "Francesca"
 		},
 		{
-			name:  "empty query returns all",
+			name:  "empty query returns nothing",
 			query: "",
 			setupMock: func(m *mockWODRepo) {
 				m.wods[1] = &domain.WOD{
@@ -464,7 +466,7 @@ func TestWODService_SearchWODs(t *testing.T) {
 					IsStandard: true,
 				}
 			},
-			expectedCount: 2,
+			expectedCount: 0,
 		},
 	}
 
@@ -477,7 +479,7 @@ func TestWODService_SearchWODs(t *testing.T) {
 
 			service := NewWODService(wodRepo)
 
-			wods, err := service.SearchWODs(tt.query)
+			wods, err := service.Search(context.Background(), tt.query, 20)
 
 			if err != nil {
 				t.Errorf("unexpected error: %v", err)
@@ -506,6 +508,8 @@ func TestWODService_UpdateWOD(t *testing.T) {
 			userID: 1,
 			updates: &domain.WOD{
 				Name:        "Updated WOD",
+				Source:      "Self-recorded",
+				Type:        "Self-created",
 				Description: "Updated description",
 			},
 			setupMock: func(m *mockWODRepo) {
@@ -527,7 +531,9 @@ func TestWODService_UpdateWOD(t *testing.T) {
 			wodID:  1,
 			userID: 2,
 			updates: &domain.WOD{
-				Name: "Updated WOD",
+				Name:   "Updated WOD",
+				Source: "Self-recorded",
+				Type:   "Self-created",
 			},
 			setupMock: func(m *mockWODRepo) {
 				userID := int64(1)
@@ -538,14 +544,16 @@ func TestWODService_UpdateWOD(t *testing.T) {
 					CreatedBy:  &userID,
 				}
 			},
-			expectedError: ErrUnauthorized,
+			expectedError: ErrWODOwnership,
 		},
 		{
 			name:   "cannot update standard WOD",
 			wodID:  1,
 			userID: 1,
 			updates: &domain.WOD{
-				Name: "Updated WOD",
+				Name:   "Updated WOD",
+				Source: "Self-recorded",
+				Type:   "Self-created",
 			},
 			setupMock: func(m *mockWODRepo) {
 				userID := int64(1)
@@ -569,7 +577,8 @@ func TestWODService_UpdateWOD(t *testing.T) {
 
 			service := NewWODService(wodRepo)
 
-			err := service.UpdateWOD(tt.wodID, tt.userID, tt.updates)
+			tt.updates.ID = tt.wodID
+			err := service.Update(context.Background(), tt.updates, policy.User(tt.userID, policy.RoleUser))
 
 			if tt.expectedError != nil {
 				if !errors.Is(err, tt.expectedError) {
@@ -606,6 +615,7 @@ func TestWODService_DeleteWOD(t *testing.T) {
 		name          string
 		wodID         int64
 		userID        int64
+		role          string
 		setupMock     func(*mockWODRepo)
 		expectedError error
 	}{
@@ -637,7 +647,7 @@ func TestWODService_DeleteWOD(t *testing.T) {
 					CreatedBy:  &userID,
 				}
 			},
-			expectedError: ErrUnauthorized,
+			expectedError: ErrWODOwnership,
 		},
 		{
 			name:   "cannot delete standard WOD",
@@ -654,6 +664,36 @@ func TestWODService_DeleteWOD(t *testing.T) {
 			},
 			expectedError: nil, // Should get error about standard WODs
 		},
+		{
+			name:   "admin deletes another user's WOD",
+			wodID:  1,
+			userID: 2,
+			role:   policy.RoleAdmin,
+			setupMock: func(m *mockWODRepo) {
+				userID := int64(1)
+				m.wods[1] = &domain.WOD{
+					ID:         1,
+					Name:       "Custom WOD",
+					IsStandard: false,
+					CreatedBy:  &userID,
+				}
+			},
+			expectedError: nil,
+		},
+		{
+			name:   "admin cannot delete standard WOD",
+			wodID:  1,
+			userID: 2,
+			role:   policy.RoleAdmin,
+			setupMock: func(m *mockWODRepo) {
+				m.wods[1] = &domain.WOD{
+					ID:         1,
+					Name:       "Fran",
+					IsStandard: true,
+				}
+			},
+			expectedError: ErrWODUnauthorized,
+		},
 	}
 
 	for _, tt := range tests {
@@ -665,7 +705,7 @@ func TestWODService_DeleteWOD(t *testing.T) {
 
 			service := NewWODService(wodRepo)
 
-			err := service.DeleteWOD(tt.wodID, tt.userID)
+			err := service.Delete(context.Background(), tt.wodID, policy.User(tt.userID, tt.role))
 
 			if tt.expectedError != nil {
 				if !errors.Is(err, tt.expectedError) {
@@ -674,7 +714,7 @@ func TestWODService_DeleteWOD(t *testing.T) {
 				return
 			}
 
-			if tt.name == "successful deletion" {
+			if tt.name == "successful deletion" || tt.name == "admin deletes another user's WOD" {
 				if err != nil {
 					t.Errorf("unexpected error: %v", err)
 					return
@@ -1,16 +1,18 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"time"
 
 	"github.com/johnzastrow/actalog/internal/domain"
+	"github.com/johnzastrow/actalog/internal/policy"
 )
 
 var (
 	ErrWorkoutNotFound = errors.New("workout not found")
-	ErrUnauthorized    = errors.New("unauthorized access")
+	ErrUnauthorized    = policy.Denied("unauthorized access")
 )
 
 // WorkoutService handles workout template business logic
@@ -37,7 +39,7 @@ func NewWorkoutService(
 }
 
 // CreateTemplate creates a new workout template
-func (s *WorkoutService) CreateTemplate(userID int64, workout *domain.Workout) error {
+func (s *WorkoutService) CreateTemplate(ctx context.Context, userID int64, workout *domain.Workout) error {
 	// Set creator and timestamps
 	workout.CreatedBy = &userID
 	now := time.Now()
@@ -45,7 +47,7 @@ func (s *WorkoutService) CreateTemplate(userID int64, workout *domain.Workout) e
 	workout.UpdatedAt = now
 
 	// Create template
-	err := s.workoutRepo.Create(workout)
+	err := s.workoutRepo.Create(ctx, workout)
 	if err != nil {
 		return fmt.Errorf("failed to create workout template: %w", err)
 	}
@@ -58,7 +60,7 @@ func (s *WorkoutService) CreateTemplate(userID int64, workout *domain.Workout) e
 			movement.CreatedAt = now
 			movement.UpdatedAt = now
 
-			err = s.workoutMovementRepo.Create(movement)
+			err = s.workoutMovementRepo.Create(ctx, movement)
 			if err != nil {
 				return fmt.Errorf("failed to create workout movement: %w", err)
 			}
@@ -79,7 +81,7 @@ func (s *WorkoutService) CreateTemplate(userID int64, workout *domain.Workout) e
 				UpdatedAt:  now,
 			}
 
-			err = s.workoutWODRepo.Create(workoutWOD)
+			err = s.workoutWODRepo.Create(ctx, workoutWOD)
 			if err != nil {
 				return fmt.Errorf("failed to create workout WOD: %w", err)
 			}
@@ -90,8 +92,8 @@ func (s *WorkoutService) CreateTemplate(userID int64, workout *domain.Workout) e
 }
 
 // GetTemplate retrieves a workout template by ID with all details
-func (s *WorkoutService) GetTemplate(templateID int64) (*domain.Workout, error) {
-	workout, err := s.workoutRepo.GetByIDWithDetails(templateID)
+func (s *WorkoutService) GetTemplate(ctx context.Context, templateID int64) (*domain.Workout, error) {
+	workout, err := s.workoutRepo.GetByIDWithDetails(ctx, templateID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get workout template: %w", err)
 	}
@@ -105,9 +107,9 @@ func (s *WorkoutService) GetTemplate(templateID int64) (*domain.Workout, error)
 // ListTemplates retrieves workout templates (standard + user's own)
 // If userID is nil, returns only standard templates
 // If userID is provided, returns standard + user's templates
-func (s *WorkoutService) ListTemplates(userID *int64, limit, offset int) ([]*domain.Workout, error) {
+func (s *WorkoutService) ListTemplates(ctx context.Context, userID *int64, limit, offset int) ([]*domain.Workout, error) {
 	// Get standard templates
-	standard, err := s.workoutRepo.ListStandard(limit, offset)
+	standard, err := s.workoutRepo.ListStandard(ctx, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list standard templates: %w", err)
 	}
@@ -118,7 +120,7 @@ func (s *WorkoutService) ListTemplates(userID *int64, limit, offset int) ([]*dom
 	}
 
 	// Get user's custom templates
-	custom, err := s.workoutRepo.ListByUser(*userID, limit, offset)
+	custom, err := s.workoutRepo.ListByUser(ctx, *userID, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list user templates: %w", err)
 	}
@@ -129,9 +131,9 @@ func (s *WorkoutService) ListTemplates(userID *int64, limit, offset int) ([]*dom
 }
 
 // UpdateTemplate updates a workout template with authorization check
-func (s *WorkoutService) UpdateTemplate(templateID, userID int64, updates *domain.Workout) error {
+func (s *WorkoutService) UpdateTemplate(ctx context.Context, templateID int64, subject policy.Subject, updates *domain.Workout) error {
 	// Get existing template
-	workout, err := s.workoutRepo.GetByID(templateID)
+	workout, err := s.workoutRepo.GetByID(ctx, templateID)
 	if err != nil {
 		return fmt.Errorf("failed to get workout template: %w", err)
 	}
@@ -139,8 +141,8 @@ func (s *WorkoutService) UpdateTemplate(templateID, userID int64, updates *domai
 		return ErrWorkoutNotFound
 	}
 
-	// Authorization check: only creator can modify template
-	if workout.CreatedBy == nil || *workout.CreatedBy != userID {
+	// Authorization check: only the creator (or an admin) can modify template
+	if err := policy.Authorize(subject, policy.ActionUpdate, policy.Template(workout)); err != nil {
 		return ErrUnauthorized
 	}
 
@@ -149,7 +151,7 @@ func (s *WorkoutService) UpdateTemplate(templateID, userID int64, updates *domai
 	workout.Notes = updates.Notes
 	workout.UpdatedAt = time.Now()
 
-	err = s.workoutRepo.Update(workout)
+	err = s.workoutRepo.Update(ctx, workout)
 	if err != nil {
 		return fmt.Errorf("failed to update workout template: %w", err)
 	}
@@ -157,7 +159,7 @@ func (s *WorkoutService) UpdateTemplate(templateID, userID int64, updates *domai
 	// Update movements if provided
 	if updates.Movements != nil {
 		// Delete existing movements
-		err = s.workoutMovementRepo.DeleteByWorkoutID(templateID)
+		err = s.workoutMovementRepo.DeleteByWorkoutID(ctx, templateID)
 		if err != nil {
 			return fmt.Errorf("failed to delete workout movements: %w", err)
 		}
@@ -170,7 +172,7 @@ func (s *WorkoutService) UpdateTemplate(templateID, userID int64, updates *domai
 			movement.CreatedAt = now
 			movement.UpdatedAt = now
 
-			err = s.workoutMovementRepo.Create(movement)
+			err = s.workoutMovementRepo.Create(ctx, movement)
 			if err != nil {
 				return fmt.Errorf("failed to create workout movement: %w", err)
 			}
@@ -180,7 +182,7 @@ func (s *WorkoutService) UpdateTemplate(templateID, userID int64, updates *domai
 	// Update WODs if provided
 	if updates.WODs != nil {
 		// Delete existing WODs
-		err = s.workoutWODRepo.DeleteByWorkout(templateID)
+		err = s.workoutWODRepo.DeleteByWorkout(ctx, templateID)
 		if err != nil {
 			return fmt.Errorf("failed to delete workout WODs: %w", err)
 		}
@@ -199,7 +201,7 @@ func (s *WorkoutService) UpdateTemplate(templateID, userID int64, updates *domai
 				UpdatedAt:  now,
 			}
 
-			err = s.workoutWODRepo.Create(workoutWOD)
+			err = s.workoutWODRepo.Create(ctx, workoutWOD)
 			if err != nil {
 				return fmt.Errorf("failed to create workout WOD: %w", err)
 			}
@@ -210,9 +212,9 @@ func (s *WorkoutService) UpdateTemplate(templateID, userID int64, updates *domai
 }
 
 // DeleteTemplate deletes a workout template with authorization check
-func (s *WorkoutService) DeleteTemplate(templateID, userID int64) error {
+func (s *WorkoutService) DeleteTemplate(ctx context.Context, templateID int64, subject policy.Subject) error {
 	// Get existing template
-	workout, err := s.workoutRepo.GetByID(templateID)
+	workout, err := s.workoutRepo.GetByID(ctx, templateID)
 	if err != nil {
 		return fmt.Errorf("failed to get workout template: %w", err)
 	}
@@ -220,13 +222,13 @@ func (s *WorkoutService) DeleteTemplate(templateID, userID int64) error {
 		return ErrWorkoutNotFound
 	}
 
-	// Authorization check: only creator can delete template
-	if workout.CreatedBy == nil || *workout.CreatedBy != userID {
+	// Authorization check: only the creator (or an admin) can delete template
+	if err := policy.Authorize(subject, policy.ActionDelete, policy.Template(workout)); err != nil {
 		return ErrUnauthorized
 	}
 
 	// Delete template (movements and WODs will be cascade deleted)
-	err = s.workoutRepo.Delete(templateID)
+	err = s.workoutRepo.Delete(ctx, templateID)
 	if err != nil {
 		return fmt.Errorf("failed to delete workout template: %w", err)
 	}
@@ -235,8 +237,8 @@ func (s *WorkoutService) DeleteTemplate(templateID, userID int64) error {
 }
 
 // GetTemplateUsageStats retrieves usage statistics for a template
-func (s *WorkoutService) GetTemplateUsageStats(templateID int64) (*domain.WorkoutWithUsageStats, error) {
-	stats, err := s.workoutRepo.GetUsageStats(templateID)
+func (s *WorkoutService) GetTemplateUsageStats(ctx context.Context, templateID int64) (*domain.WorkoutWithUsageStats, error) {
+	stats, err := s.workoutRepo.GetUsageStats(ctx, templateID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get template usage stats: %w", err)
 	}
@@ -248,9 +250,9 @@ func (s *WorkoutService) GetTemplateUsageStats(templateID int64) (*domain.Workou
 }
 
 // AddMovementToTemplate adds a movement to a workout template
-func (s *WorkoutService) AddMovementToTemplate(templateID, movementID int64, userID int64, wm *domain.WorkoutMovement) error {
+func (s *WorkoutService) AddMovementToTemplate(ctx context.Context, templateID, movementID int64, subject policy.Subject, wm *domain.WorkoutMovement) error {
 	// Get existing template
-	workout, err := s.workoutRepo.GetByID(templateID)
+	workout, err := s.workoutRepo.GetByID(ctx, templateID)
 	if err != nil {
 		return fmt.Errorf("failed to get workout template: %w", err)
 	}
@@ -258,13 +260,13 @@ func (s *WorkoutService) AddMovementToTemplate(templateID, movementID int64, use
 		return ErrWorkoutNotFound
 	}
 
-	// Authorization check: only creator can modify template
-	if workout.CreatedBy == nil || *workout.CreatedBy != userID {
+	// Authorization check: only the creator (or an admin) can modify template
+	if err := policy.Authorize(subject, policy.ActionUpdate, policy.Template(workout)); err != nil {
 		return ErrUnauthorized
 	}
 
 	// Verify movement exists
-	movement, err := s.movementRepo.GetByID(movementID)
+	movement, err := s.movementRepo.GetByID(ctx, movementID)
 	if err != nil {
 		return fmt.Errorf("failed to get movement: %w", err)
 	}
@@ -280,7 +282,7 @@ func (s *WorkoutService) AddMovementToTemplate(templateID, movementID int64, use
 	wm.UpdatedAt = now
 
 	// Create movement
-	err = s.workoutMovementRepo.Create(wm)
+	err = s.workoutMovementRepo.Create(ctx, wm)
 	if err != nil {
 		return fmt.Errorf("failed to add movement to template: %w", err)
 	}
@@ -289,9 +291,9 @@ func (s *WorkoutService) AddMovementToTemplate(templateID, movementID int64, use
 }
 
 // AddWODToTemplate adds a WOD to a workout template
-func (s *WorkoutService) AddWODToTemplate(templateID, wodID int64, userID int64, wod *domain.WorkoutWOD) error {
+func (s *WorkoutService) AddWODToTemplate(ctx context.Context, templateID, wodID int64, subject policy.Subject, wod *domain.WorkoutWOD) error {
 	// Get existing template
-	workout, err := s.workoutRepo.GetByID(templateID)
+	workout, err := s.workoutRepo.GetByID(ctx, templateID)
 	if err != nil {
 		return fmt.Errorf("failed to get workout template: %w", err)
 	}
@@ -299,8 +301,8 @@ func (s *WorkoutService) AddWODToTemplate(templateID, wodID int64, userID int64,
 		return ErrWorkoutNotFound
 	}
 
-	// Authorization check: only creator can modify template
-	if workout.CreatedBy == nil || *workout.CreatedBy != userID {
+	// Authorization check: only the creator (or an admin) can modify template
+	if err := policy.Authorize(subject, policy.ActionUpdate, policy.Template(workout)); err != nil {
 		return ErrUnauthorized
 	}
 
@@ -312,7 +314,7 @@ func (s *WorkoutService) AddWODToTemplate(templateID, wodID int64, userID int64,
 	wod.UpdatedAt = now
 
 	// Create WOD association
-	err = s.workoutWODRepo.Create(wod)
+	err = s.workoutWODRepo.Create(ctx, wod)
 	if err != nil {
 		return fmt.Errorf("failed to add WOD to template: %w", err)
 	}
@@ -321,15 +323,15 @@ func (s *WorkoutService) AddWODToTemplate(templateID, wodID int64, userID int64,
 }
 
 // ListMovements retrieves all available movements (standard + user custom)
-func (s *WorkoutService) ListMovements(userID int64) ([]*domain.Movement, error) {
+func (s *WorkoutService) ListMovements(ctx context.Context, userID int64) ([]*domain.Movement, error) {
 	// Get standard movements
-	standard, err := s.movementRepo.ListStandard()
+	standard, err := s.movementRepo.ListStandard(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list standard movements: %w", err)
 	}
 
 	// Get user's custom movements
-	custom, err := s.movementRepo.ListByUser(userID)
+	custom, err := s.movementRepo.ListByUser(ctx, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list user movements: %w", err)
 	}
@@ -340,7 +342,7 @@ func (s *WorkoutService) ListMovements(userID int64) ([]*domain.Movement, error)
 }
 
 // DetectAndFlagPRs automatically detects personal records for movements with weight
-func (s *WorkoutService) DetectAndFlagPRs(userID int64, movements []*domain.WorkoutMovement) error {
+func (s *WorkoutService) DetectAndFlagPRs(ctx context.Context, userID int64, movements []*domain.WorkoutMovement) error {
 	for _, wm := range movements {
 		// Only check for PRs on movements with weight
 		if wm.Weight == nil {
@@ -348,7 +350,7 @@ func (s *WorkoutService) DetectAndFlagPRs(userID int64, movements []*domain.Work
 		}
 
 		// Get max weight for this movement for this user
-		maxWeight, err := s.workoutMovementRepo.GetMaxWeightForMovement(userID, wm.MovementID)
+		maxWeight, err := s.workoutMovementRepo.GetMaxWeightForMovement(ctx, userID, wm.MovementID)
 		if err != nil {
 			return fmt.Errorf("failed to get max weight: %w", err)
 		}
@@ -362,8 +364,8 @@ func (s *WorkoutService) DetectAndFlagPRs(userID int64, movements []*domain.Work
 }
 
 // GetPersonalRecords retrieves all personal records for a user
-func (s *WorkoutService) GetPersonalRecords(userID int64) ([]*domain.PersonalRecord, error) {
-	records, err := s.workoutMovementRepo.GetPersonalRecords(userID)
+func (s *WorkoutService) GetPersonalRecords(ctx context.Context, userID int64) ([]*domain.PersonalRecord, error) {
+	records, err := s.workoutMovementRepo.GetPersonalRecords(ctx, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get personal records: %w", err)
 	}
@@ -371,8 +373,8 @@ func (s *WorkoutService) GetPersonalRecords(userID int64) ([]*domain.PersonalRec
 }
 
 // GetPRMovements retrieves recent PR-flagged movements for a user
-func (s *WorkoutService) GetPRMovements(userID int64, limit int) ([]*domain.WorkoutMovement, error) {
-	movements, err := s.workoutMovementRepo.GetPRMovements(userID, limit)
+func (s *WorkoutService) GetPRMovements(ctx context.Context, userID int64, limit int) ([]*domain.WorkoutMovement, error) {
+	movements, err := s.workoutMovementRepo.GetPRMovements(ctx, userID, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get PR movements: %w", err)
 	}
@@ -380,9 +382,9 @@ func (s *WorkoutService) GetPRMovements(userID int64, limit int) ([]*domain.Work
 }
 
 // TogglePRFlag manually toggles the PR flag on a workout movement
-func (s *WorkoutService) TogglePRFlag(movementID, userID int64) error {
+func (s *WorkoutService) TogglePRFlag(ctx context.Context, movementID int64, subject policy.Subject) error {
 	// Get the workout movement
-	wm, err := s.workoutMovementRepo.GetByID(movementID)
+	wm, err := s.workoutMovementRepo.GetByID(ctx, movementID)
 	if err != nil {
 		return fmt.Errorf("failed to get workout movement: %w", err)
 	}
@@ -390,16 +392,24 @@ func (s *WorkoutService) TogglePRFlag(movementID, userID int64) error {
 		return errors.New("workout movement not found")
 	}
 
-	// Note: In v0.4.0, workout movements are on templates, not user workouts
-	// Authorization is more complex - need to check if user created the template
-	// For now, we'll allow toggling if the movement exists
-	// TODO: Add proper authorization through workout template ownership
+	// In v0.4.0, workout movements are on templates, not user workouts, so
+	// check the user may modify the template
+	workout, err := s.workoutRepo.GetByID(ctx, wm.WorkoutID)
+	if err != nil {
+		return fmt.Errorf("failed to get workout template: %w", err)
+	}
+	if workout == nil {
+		return ErrWorkoutNotFound
+	}
+	if err := policy.Authorize(subject, policy.ActionUpdate, policy.Template(workout)); err != nil {
+		return ErrUnauthorized
+	}
 
 	// Toggle the PR flag
 	wm.IsPR = !wm.IsPR
 
 	// Update the movement
-	err = s.workoutMovementRepo.Update(wm)
+	err = s.workoutMovementRepo.Update(ctx, wm)
 	if err != nil {
 		return fmt.Errorf("failed to update workout movement: %w", err)
 	}
@@ -1,30 +1,33 @@
 package service
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"time"
 
 	"github.com/johnzastrow/actalog/internal/domain"
+	"github.com/johnzastrow/actalog/internal/policy"
 )
 
 type WorkoutTemplateService struct {
 	workoutRepo         domain.WorkoutRepository
 	workoutMovementRepo domain.WorkoutMovementRepository
 	workoutWODRepo      domain.WorkoutWODRepository
+	transactor          domain.Transactor
 }
 
-func NewWorkoutTemplateService(workoutRepo domain.WorkoutRepository, workoutMovementRepo domain.WorkoutMovementRepository, workoutWODRepo domain.WorkoutWODRepository) *WorkoutTemplateService {
+func NewWorkoutTemplateService(workoutRepo domain.WorkoutRepository, workoutMovementRepo domain.WorkoutMovementRepository, workoutWODRepo domain.WorkoutWODRepository, transactor domain.Transactor) *WorkoutTemplateService {
 	return &WorkoutTemplateService{
 		workoutRepo:         workoutRepo,
 		workoutMovementRepo: workoutMovementRepo,
 		workoutWODRepo:      workoutWODRepo,
+		transactor:          transactor,
 	}
 }
 
 // Create creates a new workout template
-func (s *WorkoutTemplateService) Create(userID int64, name string, notes *string, movements []domain.WorkoutMovement, wods []domain.WorkoutWOD) (*domain.Workout, error) {
-	// Create the workout template
+func (s *WorkoutTemplateService) Create(ctx context.Context, userID int64, name string, notes *string, movements []domain.WorkoutMovement, wods []domain.WorkoutWOD) (*domain.Workout, error) {
 	workout := &domain.Workout{
 		Name:      name,
 		Notes:     notes,
@@ -33,53 +36,61 @@ func (s *WorkoutTemplateService) Create(userID int64, name string, notes *string
 		UpdatedAt: time.Now(),
 	}
 
-	if err := s.workoutRepo.Create(workout); err != nil {
-		return nil, fmt.Errorf("failed to create workout template: %w", err)
-	}
-
-	// Add movements if provided
-	if len(movements) > 0 {
-		for i, movement := range movements {
-			wm := &domain.WorkoutMovement{
-				WorkoutID:  workout.ID,
-				MovementID: movement.MovementID,
-				Sets:       movement.Sets,
-				Reps:       movement.Reps,
-				Weight:     movement.Weight,
-				Time:       movement.Time,
-				Distance:   movement.Distance,
-				Notes:      movement.Notes,
-				OrderIndex: i + 1,
-			}
-
-			if err := s.workoutMovementRepo.Create(wm); err != nil {
-				return nil, fmt.Errorf("failed to add movement: %w", err)
-			}
+	// Create the template with its movements and WODs together
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.workoutRepo.Create(ctx, workout); err != nil {
+			return fmt.Errorf("failed to create workout template: %w", err)
 		}
-	}
 
-	// Add WODs if provided
-	if len(wods) > 0 {
-		for i, wod := range wods {
-			ww := &domain.WorkoutWOD{
-				WorkoutID:  workout.ID,
-				WODID:      wod.WODID,
-				OrderIndex: i + 1,
+		// Add movements if provided
+		if len(movements) > 0 {
+			for i, movement := range movements {
+				wm := &domain.WorkoutMovement{
+					WorkoutID:  workout.ID,
+					MovementID: movement.MovementID,
+					Sets:       movement.Sets,
+					Reps:       movement.Reps,
+					Weight:     movement.Weight,
+					Time:       movement.Time,
+					Distance:   movement.Distance,
+					Notes:      movement.Notes,
+					OrderIndex: i + 1,
+				}
+
+				if err := s.workoutMovementRepo.Create(ctx, wm); err != nil {
+					return fmt.Errorf("failed to add movement: %w", err)
+				}
 			}
+		}
 
-			if err := s.workoutWODRepo.Create(ww); err != nil {
-				return nil, fmt.Errorf("failed to add WOD: %w", err)
+		// Add WODs if provided
+		if len(wods) > 0 {
+			for i, wod := range wods {
+				ww := &domain.WorkoutWOD{
+					WorkoutID:  workout.ID,
+					WODID:      wod.WODID,
+					OrderIndex: i + 1,
+				}
+
+				if err := s.workoutWODRepo.Create(ctx, ww); err != nil {
+					return fmt.Errorf("failed to add WOD: %w", err)
+				}
 			}
 		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// Reload with details
-	return s.GetByIDWithDetails(workout.ID)
+	return s.GetByIDWithDetails(ctx, workout.ID)
 }
 
 // GetByID retrieves a workout template by ID
-func (s *WorkoutTemplateService) GetByID(id int64) (*domain.Workout, error) {
-	workout, err := s.workoutRepo.GetByID(id)
+func (s *WorkoutTemplateService) GetByID(ctx context.Context, id int64) (*domain.Workout, error) {
+	workout, err := s.workoutRepo.GetByID(ctx, id)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("workout template not found")
@@ -90,8 +101,8 @@ func (s *WorkoutTemplateService) GetByID(id int64) (*domain.Workout, error) {
 }
 
 // GetByIDWithDetails retrieves a workout with movements and WODs
-func (s *WorkoutTemplateService) GetByIDWithDetails(id int64) (*domain.Workout, error) {
-	workout, err := s.workoutRepo.GetByIDWithDetails(id)
+func (s *WorkoutTemplateService) GetByIDWithDetails(ctx context.Context, id int64) (*domain.Workout, error) {
+	workout, err := s.workoutRepo.GetByIDWithDetails(ctx, id)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("workout template not found")
@@ -102,8 +113,8 @@ func (s *WorkoutTemplateService) GetByIDWithDetails(id int64) (*domain.Workout,
 }
 
 // ListByUser retrieves all workout templates created by a specific user
-func (s *WorkoutTemplateService) ListByUser(userID int64, limit, offset int) ([]*domain.Workout, error) {
-	templates, err := s.workoutRepo.ListByUser(userID, limit, offset)
+func (s *WorkoutTemplateService) ListByUser(ctx context.Context, userID int64, limit, offset int) ([]*domain.Workout, error) {
+	templates, err := s.workoutRepo.ListByUser(ctx, userID, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list user templates: %w", err)
 	}
@@ -111,8 +122,8 @@ func (s *WorkoutTemplateService) ListByUser(userID int64, limit, offset int) ([]
 }
 
 // ListStandard retrieves all standard (system) workout templates
-func (s *WorkoutTemplateService) ListStandard(limit, offset int) ([]*domain.Workout, error) {
-	templates, err := s.workoutRepo.ListStandard(limit, offset)
+func (s *WorkoutTemplateService) ListStandard(ctx context.Context, limit, offset int) ([]*domain.Workout, error) {
+	templates, err := s.workoutRepo.ListStandard(ctx, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list standard templates: %w", err)
 	}
@@ -120,9 +131,9 @@ func (s *WorkoutTemplateService) ListStandard(limit, offset int) ([]*domain.Work
 }
 
 // Update updates an existing workout template
-func (s *WorkoutTemplateService) Update(id, userID int64, name string, notes *string, movements []domain.WorkoutMovement, wods []domain.WorkoutWOD) (*domain.Workout, error) {
+func (s *WorkoutTemplateService) Update(ctx context.Context, id int64, subject policy.Subject, name string, notes *string, movements []domain.WorkoutMovement, wods []domain.WorkoutWOD) (*domain.Workout, error) {
 	// Get existing workout to verify ownership
-	existing, err := s.workoutRepo.GetByID(id)
+	existing, err := s.workoutRepo.GetByID(ctx, id)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("workout template not found")
@@ -130,74 +141,86 @@ func (s *WorkoutTemplateService) Update(id, userID int64, name string, notes *st
 		return nil, fmt.Errorf("failed to get workout template: %w", err)
 	}
 
-	// Verify user owns this template
-	if existing.CreatedBy == nil || *existing.CreatedBy != userID {
-		return nil, fmt.Errorf("you don't have permission to edit this template")
-	}
-
-	// Update the workout
-	existing.Name = name
-	existing.Notes = notes
-	existing.UpdatedAt = time.Now()
-
-	if err := s.workoutRepo.Update(existing); err != nil {
-		return nil, fmt.Errorf("failed to update workout template: %w", err)
+	if existing == nil {
+		return nil, fmt.Errorf("workout template not found")
 	}
 
-	// Delete existing movements
-	if err := s.workoutMovementRepo.DeleteByWorkoutID(id); err != nil {
-		return nil, fmt.Errorf("failed to delete existing movements: %w", err)
+	// Verify the user may edit this template
+	if err := policy.Authorize(subject, policy.ActionUpdate, policy.Template(existing)); err != nil {
+		return nil, fmt.Errorf("you don't have permission to edit this template: %w", err)
 	}
 
-	// Add new movements
-	if len(movements) > 0 {
-		for i, movement := range movements {
-			wm := &domain.WorkoutMovement{
-				WorkoutID:  id,
-				MovementID: movement.MovementID,
-				Sets:       movement.Sets,
-				Reps:       movement.Reps,
-				Weight:     movement.Weight,
-				Time:       movement.Time,
-				Distance:   movement.Distance,
-				Notes:      movement.Notes,
-				OrderIndex: i + 1,
-			}
+	// Save the template and replace its movements and WODs together
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		// Update the workout
+		existing.Name = name
+		existing.Notes = notes
+		existing.UpdatedAt = time.Now()
 
-			if err := s.workoutMovementRepo.Create(wm); err != nil {
-				return nil, fmt.Errorf("failed to add movement: %w", err)
-			}
+		if err := s.workoutRepo.Update(ctx, existing); err != nil {
+			return fmt.Errorf("failed to update workout template: %w", err)
 		}
-	}
 
-	// Delete existing WODs
-	if err := s.workoutWODRepo.DeleteByWorkout(id); err != nil {
-		return nil, fmt.Errorf("failed to delete existing WODs: %w", err)
-	}
+		// Delete existing movements
+		if err := s.workoutMovementRepo.DeleteByWorkoutID(ctx, id); err != nil {
+			return fmt.Errorf("failed to delete existing movements: %w", err)
+		}
 
-	// Add new WODs
-	if len(wods) > 0 {
-		for i, wod := range wods {
-			ww := &domain.WorkoutWOD{
-				WorkoutID:  id,
-				WODID:      wod.WODID,
-				OrderIndex: i + 1,
+		// Add new movements
+		if len(movements) > 0 {
+			for i, movement := range movements {
+				wm := &domain.WorkoutMovement{
+					WorkoutID:  id,
+					MovementID: movement.MovementID,
+					Sets:       movement.Sets,
+					Reps:       movement.Reps,
+					Weight:     movement.Weight,
+					Time:       movement.Time,
+					Distance:   movement.Distance,
+					Notes:      movement.Notes,
+					OrderIndex: i + 1,
+				}
+
+				if err := s.workoutMovementRepo.Create(ctx, wm); err != nil {
+					return fmt.Errorf("failed to add movement: %w", err)
+				}
 			}
+		}
+
+		// Delete existing WODs
+		if err := s.workoutWODRepo.DeleteByWorkout(ctx, id); err != nil {
+			return fmt.Errorf("failed to delete existing WODs: %w", err)
+		}
 
-			if err := s.workoutWODRepo.Create(ww); err != nil {
-				return nil, fmt.Errorf("failed to add WOD: %w", err)
+		// Add new WODs
+		if len(wods) > 0 {
+			for i, wod := range wods {
+				ww := &domain.WorkoutWOD{
+					WorkoutID:  id,
+					WODID:      wod.WODID,
+					OrderIndex: i + 1,
+				}
+
+				if err := s.workoutWODRepo.Create(ctx, ww); err != nil {
+					return fmt.Errorf("failed to add WOD: %w", err)
+				}
 			}
 		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// Reload with details
-	return s.GetByIDWithDetails(id)
+	return s.GetByIDWithDetails(ctx, id)
 }
 
 // Delete deletes a workout template
-func (s *WorkoutTemplateService) Delete(id, userID int64) error {
+func (s *WorkoutTemplateService) Delete(ctx context.Context, id int64, subject policy.Subject) error {
 	// Get existing workout to verify ownership
-	existing, err := s.workoutRepo.GetByID(id)
+	existing, err := s.workoutRepo.GetByID(ctx, id)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return fmt.Errorf("workout template not found")
@@ -205,20 +228,27 @@ func (s *WorkoutTemplateService) Delete(id, userID int64) error {
 		return fmt.Errorf("failed to get workout template: %w", err)
 	}
 
-	// Verify user owns this template
-	if existing.CreatedBy == nil || *existing.CreatedBy != userID {
-		return fmt.Errorf("you don't have permission to delete this template")
+	if existing == nil {
+		return fmt.Errorf("workout template not found")
 	}
 
-	// Delete movements first
-	if err := s.workoutMovementRepo.DeleteByWorkoutID(id); err != nil {
-		return fmt.Errorf("failed to delete movements: %w", err)
+	// Verify the user may delete this template
+	if err := policy.Authorize(subject, policy.ActionDelete, policy.Template(existing)); err != nil {
+		return fmt.Errorf("you don't have permission to delete this template: %w", err)
 	}
 
-	// Delete the workout
-	if err := s.workoutRepo.Delete(id); err != nil {
-		return fmt.Errorf("failed to delete workout template: %w", err)
-	}
+	// Delete the template and its movements together
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		// Delete movements first
+		if err := s.workoutMovementRepo.DeleteByWorkoutID(ctx, id); err != nil {
+			return fmt.Errorf("failed to delete movements: %w", err)
+		}
+
+		// Delete the workout
+		if err := s.workoutRepo.Delete(ctx, id); err != nil {
+			return fmt.Errorf("failed to delete workout template: %w", err)
+		}
 
-	return nil
+		return nil
+	})
 }
@@ -1,10 +1,12 @@
 package service
 
 import (
+	"context"
 	"fmt"
 	"time"
 
 	"github.com/johnzastrow/actalog/internal/domain"
+	"github.com/johnzastrow/actalog/internal/policy"
 )
 
 // WorkoutWODService handles linking WODs to workout templates
@@ -28,9 +30,9 @@ func NewWorkoutWODService(
 }
 
 // AddWODToWorkout adds a WOD to a workout template with authorization check
-func (s *WorkoutWODService) AddWODToWorkout(workoutID, wodID int64, userID int64, orderIndex int, division *string) (*domain.WorkoutWOD, error) {
+func (s *WorkoutWODService) AddWODToWorkout(ctx context.Context, workoutID, wodID int64, subject policy.Subject, orderIndex int, division *string) (*domain.WorkoutWOD, error) {
 	// Verify workout template exists and user has permission
-	workout, err := s.workoutRepo.GetByID(workoutID)
+	workout, err := s.workoutRepo.GetByID(ctx, workoutID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get workout template: %w", err)
 	}
@@ -38,11 +40,11 @@ func (s *WorkoutWODService) AddWODToWorkout(workoutID, wodID int64, userID int64
 		return nil, fmt.Errorf("workout template not found")
 	}
 
-	// Authorization check: only creator can modify template
-	if workout.CreatedBy == nil || *workout.CreatedBy != userID {
+	// Authorization check: only the creator (or an admin) can modify template
+	if err := policy.Authorize(subject, policy.ActionUpdate, policy.Template(workout)); err != nil {
 		return nil, ErrUnauthorized
 	}
-	wod, err := s.wodRepo.GetByID(wodID)
+	wod, err := s.wodRepo.GetByID(ctx, wodID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get WOD: %w", err)
 	}
@@ -61,7 +63,7 @@ func (s *WorkoutWODService) AddWODToWorkout(workoutID, wodID int64, userID int64
 		CreatedAt:  now,
 		UpdatedAt:  now,
 	}
-	err = s.workoutWODRepo.Create(workoutWOD)
+	err = s.workoutWODRepo.Create(ctx, workoutWOD)
 	if err != nil {
 		return nil, fmt.Errorf("failed to add WOD to workout: %w", err)
 	}
@@ -69,9 +71,9 @@ func (s *WorkoutWODService) AddWODToWorkout(workoutID, wodID int64, userID int64
 }
 
 // RemoveWODFromWorkout removes a WOD from a workout template with authorization check
-func (s *WorkoutWODService) RemoveWODFromWorkout(workoutWODID, userID int64) error {
+func (s *WorkoutWODService) RemoveWODFromWorkout(ctx context.Context, workoutWODID int64, subject policy.Subject) error {
 	// Get the workout WOD
-	workoutWOD, err := s.workoutWODRepo.GetByID(workoutWODID)
+	workoutWOD, err := s.workoutWODRepo.GetByID(ctx, workoutWODID)
 	if err != nil {
 		return fmt.Errorf("failed to get workout WOD: %w", err)
 	}
@@ -80,7 +82,7 @@ func (s *WorkoutWODService) RemoveWODFromWorkout(workoutWODID, userID int64) err
 	}
 
 	// Get the workout to check authorization
-	workout, err := s.workoutRepo.GetByID(workoutWOD.WorkoutID)
+	workout, err := s.workoutRepo.GetByID(ctx, workoutWOD.WorkoutID)
 	if err != nil {
 		return fmt.Errorf("failed to get workout template: %w", err)
 	}
@@ -88,13 +90,13 @@ func (s *WorkoutWODService) RemoveWODFromWorkout(workoutWODID, userID int64) err
 		return fmt.Errorf("workout template not found")
 	}
 
-	// Authorization check: only creator can modify template
-	if workout.CreatedBy == nil || *workout.CreatedBy != userID {
+	// Authorization check: only the creator (or an admin) can modify template
+	if err := policy.Authorize(subject, policy.ActionUpdate, policy.Template(workout)); err != nil {
 		return ErrUnauthorized
 	}
 
 	// Delete the association
-	err = s.workoutWODRepo.Delete(workoutWODID)
+	err = s.workoutWODRepo.Delete(ctx, workoutWODID)
 	if err != nil {
 		return fmt.Errorf("failed to remove WOD from workout: %w", err)
 	}
@@ -102,9 +104,9 @@ func (s *WorkoutWODService) RemoveWODFromWorkout(workoutWODID, userID int64) err
 }
 
 // UpdateWorkoutWOD updates a WOD in a workout with authorization check
-func (s *WorkoutWODService) UpdateWorkoutWOD(workoutWODID, userID int64, scoreValue *string, division *string) error {
+func (s *WorkoutWODService) UpdateWorkoutWOD(ctx context.Context, workoutWODID int64, subject policy.Subject, scoreValue *string, division *string) error {
 	// Get the workout WOD
-	workoutWOD, err := s.workoutWODRepo.GetByID(workoutWODID)
+	workoutWOD, err := s.workoutWODRepo.GetByID(ctx, workoutWODID)
 	if err != nil {
 		return fmt.Errorf("failed to get workout WOD: %w", err)
 	}
@@ -113,7 +115,7 @@ func (s *WorkoutWODService) UpdateWorkoutWOD(workoutWODID, userID int64, scoreVa
 	}
 
 	// Get the workout to check authorization
-	workout, err := s.workoutRepo.GetByID(workoutWOD.WorkoutID)
+	workout, err := s.workoutRepo.GetByID(ctx, workoutWOD.WorkoutID)
 	if err != nil {
 		return fmt.Errorf("failed to get workout template: %w", err)
 	}
@@ -121,8 +123,8 @@ func (s *WorkoutWODService) UpdateWorkoutWOD(workoutWODID, userID int64, scoreVa
 		return fmt.Errorf("workout template not found")
 	}
 
-	// Authorization check: only creator can modify template
-	if workout.CreatedBy == nil || *workout.CreatedBy != userID {
+	// Authorization check: only the creator (or an admin) can modify template
+	if err := policy.Authorize(subject, policy.ActionUpdate, policy.Template(workout)); err != nil {
 		return ErrUnauthorized
 	}
 	if scoreValue != nil {
@@ -132,7 +134,7 @@ func (s *WorkoutWODService) UpdateWorkoutWOD(workoutWODID, userID int64, scoreVa
 		workoutWOD.Division = division
 	}
 	workoutWOD.UpdatedAt = time.Now()
-	err = s.workoutWODRepo.Update(workoutWOD)
+	err = s.workoutWODRepo.Update(ctx, workoutWOD)
 	if err != nil {
 		return fmt.Errorf("failed to update workout WOD: %w", err)
 	}
@@ -140,9 +142,9 @@ func (s *WorkoutWODService) UpdateWorkoutWOD(workoutWODID, userID int64, scoreVa
 }
 
 // ToggleWODPR toggles the PR flag on a WOD in a workout with authorization check
-func (s *WorkoutWODService) ToggleWODPR(workoutWODID, userID int64) error {
+func (s *WorkoutWODService) ToggleWODPR(ctx context.Context, workoutWODID int64, subject policy.Subject) error {
 	// Get the workout WOD
-	workoutWOD, err := s.workoutWODRepo.GetByID(workoutWODID)
+	workoutWOD, err := s.workoutWODRepo.GetByID(ctx, workoutWODID)
 	if err != nil {
 		return fmt.Errorf("failed to get workout WOD: %w", err)
 	}
@@ -151,7 +153,7 @@ func (s *WorkoutWODService) ToggleWODPR(workoutWODID, userID int64) error {
 	}
 
 	// Get the workout to check authorization
-	workout, err := s.workoutRepo.GetByID(workoutWOD.WorkoutID)
+	workout, err := s.workoutRepo.GetByID(ctx, workoutWOD.WorkoutID)
 	if err != nil {
 		return fmt.Errorf("failed to get workout template: %w", err)
 	}
@@ -159,11 +161,11 @@ func (s *WorkoutWODService) ToggleWODPR(workoutWODID, userID int64) error {
 		return fmt.Errorf("workout template not found")
 	}
 
-	// Authorization check: only creator can modify template
-	if workout.CreatedBy == nil || *workout.CreatedBy != userID {
+	// Authorization check: only the creator (or an admin) can modify template
+	if err := policy.Authorize(subject, policy.ActionUpdate, policy.Template(workout)); err != nil {
 		return ErrUnauthorized
 	}
-	err = s.workoutWODRepo.TogglePR(workoutWODID)
+	err = s.workoutWODRepo.TogglePR(ctx, workoutWODID)
 	if err != nil {
 		return fmt.Errorf("failed to toggle WOD PR: %w", err)
 	}
@@ -171,8 +173,8 @@ func (s *WorkoutWODService) ToggleWODPR(workoutWODID, userID int64) error {
 }
 
 // ListWODsForWorkout retrieves all WODs associated with a workout template
-func (s *WorkoutWODService) ListWODsForWorkout(workoutID int64) ([]*domain.WorkoutWODWithDetails, error) {
-	wods, err := s.workoutWODRepo.ListByWorkoutWithDetails(workoutID)
+func (s *WorkoutWODService) ListWODsForWorkout(ctx context.Context, workoutID int64) ([]*domain.WorkoutWODWithDetails, error) {
+	wods, err := s.workoutWODRepo.ListByWorkoutWithDetails(ctx, workoutID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list WODs for workout: %w", err)
 	}
@@ -0,0 +1,163 @@
+package testhelpers
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+)
+
+// SMTPMessage is a message captured by the fake SMTP server
+type SMTPMessage struct {
+	From string
+	To   []string
+	Data string
+}
+
+// FakeSMTPServer is a minimal in-process SMTP server for tests. It accepts
+// plain (non-TLS) connections and any AUTH PLAIN credentials, and records
+// every message it receives. Use FailNext to make deliveries fail temporarily.
+type FakeSMTPServer struct {
+	Host string
+	Port int
+
+	listener net.Listener
+	mu       sync.Mutex
+	messages []SMTPMessage
+	failNext int
+	wg       sync.WaitGroup
+}
+
+// NewFakeSMTPServer starts a fake SMTP server on a random localhost port
+func NewFakeSMTPServer() (*FakeSMTPServer, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	addr := ln.Addr().(*net.TCPAddr)
+	s := &FakeSMTPServer{
+		Host:     "127.0.0.1",
+		Port:     addr.Port,
+		listener: ln,
+	}
+
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+// Close stops the server
+func (s *FakeSMTPServer) Close() {
+	s.listener.Close()
+	s.wg.Wait()
+}
+
+// Messages returns a copy of the messages received so far
+func (s *FakeSMTPServer) Messages() []SMTPMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]SMTPMessage(nil), s.messages...)
+}
+
+// FailNext makes the next n messages fail with a 451 response
+func (s *FakeSMTPServer) FailNext(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failNext = n
+}
+
+func (s *FakeSMTPServer) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.handle(conn)
+		}()
+	}
+}
+
+func (s *FakeSMTPServer) handle(conn net.Conn) {
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	reply := func(format string, args ...interface{}) {
+		fmt.Fprintf(conn, format+"\r\n", args...)
+	}
+
+	reply("220 localhost fake SMTP ready")
+
+	var msg SMTPMessage
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		cmd := strings.ToUpper(line)
+
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250-localhost")
+			reply("250 AUTH PLAIN")
+		case strings.HasPrefix(cmd, "AUTH"):
+			reply("235 Authentication successful")
+		case strings.HasPrefix(cmd, "MAIL FROM:"):
+			msg = SMTPMessage{From: trimAddress(line[len("MAIL FROM:"):])}
+			reply("250 OK")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			msg.To = append(msg.To, trimAddress(line[len("RCPT TO:"):]))
+			reply("250 OK")
+		case cmd == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				dl, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if dl == ".\r\n" || dl == ".\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(dl, "."))
+			}
+			msg.Data = data.String()
+
+			s.mu.Lock()
+			fail := s.failNext > 0
+			if fail {
+				s.failNext--
+			} else {
+				s.messages = append(s.messages, msg)
+			}
+			s.mu.Unlock()
+
+			if fail {
+				reply("451 Temporary failure, try again later")
+			} else {
+				reply("250 OK: queued")
+			}
+		case cmd == "RSET", cmd == "NOOP":
+			reply("250 OK")
+		case cmd == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+func trimAddress(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.Index(s, " "); i >= 0 {
+		s = s[:i]
+	}
+	return strings.Trim(s, "<>")
+}
@@ -0,0 +1,217 @@
+package testhelpers
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"sort"
+
+	"github.com/johnzastrow/actalog/pkg/webauthn"
+)
+
+// SoftwareAuthenticator is an in-memory WebAuthn authenticator for tests. It
+// holds one ES256 credential per registration and signs like a platform
+// authenticator with "none" attestation.
+type SoftwareAuthenticator struct {
+	Origin       string
+	UserVerified bool // Report user verification (biometric/PIN) in responses
+
+	credentials map[string]*softwareCredential
+}
+
+type softwareCredential struct {
+	id         []byte
+	key        *ecdsa.PrivateKey
+	rpID       string
+	userHandle string
+	signCount  uint32
+}
+
+// NewSoftwareAuthenticator creates an authenticator that reports the given origin
+func NewSoftwareAuthenticator(origin string) *SoftwareAuthenticator {
+	return &SoftwareAuthenticator{
+		Origin:       origin,
+		UserVerified: true,
+		credentials:  make(map[string]*softwareCredential),
+	}
+}
+
+// Register creates a credential for the options, like navigator.credentials.create()
+func (a *SoftwareAuthenticator) Register(options *webauthn.CreationOptions) (*webauthn.RegistrationResponse, error) {
+	for _, excluded := range options.ExcludeCredentials {
+		if _, ok := a.credentials[excluded.ID]; ok {
+			return nil, errors.New("authenticator already registered")
+		}
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	cred := &softwareCredential{id: id, key: key, rpID: options.RP.ID, userHandle: options.User.ID}
+	credID := webauthn.EncodeBase64URL(id)
+	a.credentials[credID] = cred
+
+	x := make([]byte, 32)
+	y := make([]byte, 32)
+	key.PublicKey.X.FillBytes(x)
+	key.PublicKey.Y.FillBytes(y)
+	coseKey := cborMap(map[interface{}][]byte{
+		int64(1):  cborUint(2),                // kty: EC2
+		int64(3):  cborInt(webauthn.AlgES256), // alg
+		int64(-1): cborUint(1),                // crv: P-256
+		int64(-2): cborBytes(x),
+		int64(-3): cborBytes(y),
+	})
+
+	attested := make([]byte, 16, 16+2+len(id)+len(coseKey)) // Zero AAGUID
+	attested = binary.BigEndian.AppendUint16(attested, uint16(len(id)))
+	attested = append(attested, id...)
+	attested = append(attested, coseKey...)
+	authData := a.authenticatorData(cred, 0x40, attested)
+
+	attestationObject := cborMap(map[interface{}][]byte{
+		"fmt":      cborText("none"),
+		"attStmt":  cborMap(nil),
+		"authData": cborBytes(authData),
+	})
+
+	clientDataJSON, err := a.clientData("webauthn.create", options.Challenge)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &webauthn.RegistrationResponse{ID: credID, RawID: credID, Type: "public-key"}
+	resp.Response.ClientDataJSON = webauthn.EncodeBase64URL(clientDataJSON)
+	resp.Response.AttestationObject = webauthn.EncodeBase64URL(attestationObject)
+	resp.Response.Transports = []string{"internal"}
+	return resp, nil
+}
+
+// Login signs the options' challenge, like navigator.credentials.get(). With an
+// empty allow list it picks any credential for the relying party, as with a
+// discoverable passkey.
+func (a *SoftwareAuthenticator) Login(options *webauthn.RequestOptions) (*webauthn.AuthenticationResponse, error) {
+	cred := a.findCredential(options)
+	if cred == nil {
+		return nil, errors.New("no matching credential")
+	}
+
+	cred.signCount++
+	authData := a.authenticatorData(cred, 0, nil)
+	clientDataJSON, err := a.clientData("webauthn.get", options.Challenge)
+	if err != nil {
+		return nil, err
+	}
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	digest := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, cred.key, digest[:])
+	if err != nil {
+		return nil, err
+	}
+
+	credID := webauthn.EncodeBase64URL(cred.id)
+	resp := &webauthn.AuthenticationResponse{ID: credID, RawID: credID, Type: "public-key"}
+	resp.Response.ClientDataJSON = webauthn.EncodeBase64URL(clientDataJSON)
+	resp.Response.AuthenticatorData = webauthn.EncodeBase64URL(authData)
+	resp.Response.Signature = webauthn.EncodeBase64URL(signature)
+	resp.Response.UserHandle = cred.userHandle
+	return resp, nil
+}
+
+func (a *SoftwareAuthenticator) findCredential(options *webauthn.RequestOptions) *softwareCredential {
+	if len(options.AllowCredentials) > 0 {
+		for _, allowed := range options.AllowCredentials {
+			if cred, ok := a.credentials[allowed.ID]; ok && cred.rpID == options.RPID {
+				return cred
+			}
+		}
+		return nil
+	}
+
+	// Deterministic choice among discoverable credentials
+	ids := make([]string, 0, len(a.credentials))
+	for id := range a.credentials {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if a.credentials[id].rpID == options.RPID {
+			return a.credentials[id]
+		}
+	}
+	return nil
+}
+
+func (a *SoftwareAuthenticator) authenticatorData(cred *softwareCredential, flags byte, attested []byte) []byte {
+	rpIDHash := sha256.Sum256([]byte(cred.rpID))
+	flags |= 0x01 // User present
+	if a.UserVerified {
+		flags |= 0x04
+	}
+
+	data := append(rpIDHash[:], flags)
+	data = binary.BigEndian.AppendUint32(data, cred.signCount)
+	return append(data, attested...)
+}
+
+func (a *SoftwareAuthenticator) clientData(typ, challenge string) ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"type":        typ,
+		"challenge":   challenge,
+		"origin":      a.Origin,
+		"crossOrigin": false,
+	})
+}
+
+// Minimal CBOR encoding for the structures above
+
+func cborHeader(major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return []byte{major<<5 | byte(n)}
+	case n < 1<<8:
+		return []byte{major<<5 | 24, byte(n)}
+	case n < 1<<16:
+		return binary.BigEndian.AppendUint16([]byte{major<<5 | 25}, uint16(n))
+	default:
+		return binary.BigEndian.AppendUint32([]byte{major<<5 | 26}, uint32(n))
+	}
+}
+
+func cborUint(n uint64) []byte { return cborHeader(0, n) }
+
+func cborInt(n int64) []byte {
+	if n < 0 {
+		return cborHeader(1, uint64(-1-n))
+	}
+	return cborHeader(0, uint64(n))
+}
+
+func cborBytes(b []byte) []byte { return append(cborHeader(2, uint64(len(b))), b...) }
+
+func cborText(s string) []byte { return append(cborHeader(3, uint64(len(s))), s...) }
+
+// cborMap encodes a map whose keys are int64 or string and whose values are
+// already encoded
+func cborMap(m map[interface{}][]byte) []byte {
+	out := cborHeader(5, uint64(len(m)))
+	for key, value := range m {
+		switch k := key.(type) {
+		case int64:
+			out = append(out, cborInt(k)...)
+		case string:
+			out = append(out, cborText(k)...)
+		}
+		out = append(out, value...)
+	}
+	return out
+}
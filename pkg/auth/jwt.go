@@ -2,6 +2,8 @@
 package auth
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"time"
 
@@ -13,46 +15,100 @@ var (
 	ErrExpiredToken = errors.New("token has expired")
 )
 
+// TokenPurposeMFA marks a short-lived token that only proves the password step
+// of a two-factor login. It is not accepted as an access token.
+const TokenPurposeMFA = "mfa"
+
+// DefaultIssuer is the iss claim of issued tokens unless JWT_ISSUER is set
+const DefaultIssuer = "actalog"
+
+// Audiences (aud claim) and typ headers of issued tokens. Services verifying
+// ActaLog tokens through the JWKS must check iss, aud, typ and exp, or they
+// will also accept MFA challenges, which only prove the password step.
+const (
+	AccessTokenAudience = "actalog-api"
+	AccessTokenType     = "at+jwt"
+	MFATokenAudience    = "actalog-mfa"
+	MFATokenType        = "mfa+jwt"
+)
+
 // Claims represents the JWT claims
 type Claims struct {
-	UserID int64  `json:"user_id"`
-	Email  string `json:"email"`
-	Role   string `json:"role"`
+	UserID  int64  `json:"user_id"`
+	Email   string `json:"email"`
+	Role    string `json:"role"`
+	Purpose string `json:"purpose,omitempty"` // Empty for access tokens
 	jwt.RegisteredClaims
 }
 
 // GenerateToken generates a new JWT token for a user
-func GenerateToken(userID int64, email, role, secret string, expiration time.Duration) (string, error) {
+func GenerateToken(userID int64, email, role string, keys *KeySet, expiration time.Duration) (string, error) {
+	return generateToken(userID, email, role, "", "", keys, expiration)
+}
+
+// GenerateMFAToken generates an MFA challenge token, exchanged for an access
+// token once the user supplies a second factor. Each challenge has a unique
+// ID (jti) so attempts can be counted per challenge.
+func GenerateMFAToken(userID int64, email, role string, keys *KeySet, expiration time.Duration) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return generateToken(userID, email, role, TokenPurposeMFA, hex.EncodeToString(b), keys, expiration)
+}
+
+func generateToken(userID int64, email, role, purpose, id string, keys *KeySet, expiration time.Duration) (string, error) {
+	audience := AccessTokenAudience
+	if purpose == TokenPurposeMFA {
+		audience = MFATokenAudience
+	}
+
 	claims := Claims{
-		UserID: userID,
-		Email:  email,
-		Role:   role,
+		UserID:  userID,
+		Email:   email,
+		Role:    role,
+		Purpose: purpose,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        id,
+			Issuer:    keys.Issuer(),
+			Audience:  jwt.ClaimStrings{audience},
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiration)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(secret))
+	if purpose == TokenPurposeMFA {
+		return keys.signChallenge(claims, MFATokenType)
+	}
+	return keys.sign(claims, AccessTokenType)
+}
+
+// ValidateToken validates a JWT access token and returns the claims
+func ValidateToken(tokenString string, keys *KeySet) (*Claims, error) {
+	return validateToken(tokenString, "", keys)
 }
 
-// ValidateToken validates a JWT token and returns the claims
-func ValidateToken(tokenString, secret string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, ErrInvalidToken
-		}
-		return []byte(secret), nil
-	})
+// ValidateMFAToken validates an MFA challenge token and returns the claims
+func ValidateMFAToken(tokenString string, keys *KeySet) (*Claims, error) {
+	return validateToken(tokenString, TokenPurposeMFA, keys)
+}
+
+func validateToken(tokenString, purpose string, keys *KeySet) (*Claims, error) {
+	audience, typ, keyFunc := AccessTokenAudience, AccessTokenType, keys.verificationKey
+	if purpose == TokenPurposeMFA {
+		audience, typ, keyFunc = MFATokenAudience, MFATokenType, keys.challengeVerificationKey
+	}
+
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, keyFunc,
+		jwt.WithIssuer(keys.Issuer()), jwt.WithAudience(audience))
 
 	if err != nil {
 		return nil, err
 	}
 
 	claims, ok := token.Claims.(*Claims)
-	if !ok || !token.Valid {
+	if !ok || !token.Valid || claims.Purpose != purpose || token.Header["typ"] != typ {
 		return nil, ErrInvalidToken
 	}
 
@@ -0,0 +1,306 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Token signing algorithms
+const (
+	AlgorithmHS256 = "HS256" // Shared secret (JWT_SECRET)
+	AlgorithmRS256 = "RS256" // RSA key pair
+	AlgorithmEdDSA = "EdDSA" // Ed25519 key pair
+)
+
+// rsaKeyBits is the size of generated RSA keys
+const rsaKeyBits = 2048
+
+// ErrUnsupportedAlgorithm is returned for algorithms other than RS256 and EdDSA
+var ErrUnsupportedAlgorithm = errors.New("unsupported signing algorithm")
+
+// SigningKey is a private key that signs tokens. Tokens carry its ID in the
+// kid header so verifiers know which public key to check them with.
+type SigningKey struct {
+	ID        string
+	Algorithm string
+	Private   crypto.Signer
+}
+
+// GenerateSigningKey creates a new RS256 or EdDSA key with a random ID
+func GenerateSigningKey(algorithm string) (*SigningKey, error) {
+	var private crypto.Signer
+	var err error
+	switch algorithm {
+	case AlgorithmRS256:
+		private, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	case AlgorithmEdDSA:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, algorithm)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate signing key: %w", err)
+	}
+
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, fmt.Errorf("failed to generate key ID: %w", err)
+	}
+
+	return &SigningKey{ID: hex.EncodeToString(id), Algorithm: algorithm, Private: private}, nil
+}
+
+// ParseSigningKey parses a key saved with MarshalPEM
+func ParseSigningKey(id, algorithm, pemData string) (*SigningKey, error) {
+	block, _ := pem.Decode([]byte(pemData))
+	if block == nil {
+		return nil, fmt.Errorf("signing key %s is not PEM encoded", id)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing key %s: %w", id, err)
+	}
+
+	key := &SigningKey{ID: id, Algorithm: algorithm}
+	switch private := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.Private = private
+	case ed25519.PrivateKey:
+		key.Private = private
+	}
+	if key.Private == nil || key.method() == nil {
+		return nil, fmt.Errorf("%w: signing key %s is not a %s key", ErrUnsupportedAlgorithm, id, algorithm)
+	}
+	return key, nil
+}
+
+// MarshalPEM encodes the private key as PKCS #8 PEM for storage
+func (k *SigningKey) MarshalPEM() (string, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(k.Private)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode signing key: %w", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})), nil
+}
+
+// method returns the JWT signing method, or nil if the key doesn't match its algorithm
+func (k *SigningKey) method() jwt.SigningMethod {
+	switch k.Private.(type) {
+	case *rsa.PrivateKey:
+		if k.Algorithm == AlgorithmRS256 {
+			return jwt.SigningMethodRS256
+		}
+	case ed25519.PrivateKey:
+		if k.Algorithm == AlgorithmEdDSA {
+			return jwt.SigningMethodEdDSA
+		}
+	}
+	return nil
+}
+
+// JWK is a public key in JSON Web Key format (RFC 7517)
+type JWK struct {
+	KeyType   string `json:"kty"`
+	Use       string `json:"use"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n,omitempty"`   // RSA modulus
+	E         string `json:"e,omitempty"`   // RSA exponent
+	Curve     string `json:"crv,omitempty"` // OKP curve
+	X         string `json:"x,omitempty"`   // OKP public key
+}
+
+// JWKS is a JSON Web Key Set, served so other services can verify tokens
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWK returns the key's public half
+func (k *SigningKey) JWK() JWK {
+	jwk := JWK{Use: "sig", KeyID: k.ID, Algorithm: k.Algorithm}
+	switch public := k.Private.Public().(type) {
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.KeyType = "OKP"
+		jwk.Curve = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(public)
+	}
+	return jwk
+}
+
+// KeySet holds the keys that sign and verify tokens. Without a signing key,
+// tokens are signed with the HS256 secret. The secret also verifies tokens
+// that have no kid, so tokens issued before switching to key pairs stay valid
+// until the end date set with SetSecretExpiry.
+// Keys can be replaced at any time with SetKeys, e.g. on rotation.
+//
+// MFA challenges are signed with a key derived from the secret, which is
+// never published and never verifies access tokens. Without a secret they
+// fall back to the signing key and only their audience and typ header tell
+// them apart.
+type KeySet struct {
+	mu           sync.RWMutex
+	issuer       string
+	secret       []byte
+	secretUntil  time.Time
+	challenge    []byte
+	signing      *SigningKey
+	verification map[string]*SigningKey
+}
+
+// challengeKeyLabel derives the MFA challenge key from the secret
+const challengeKeyLabel = "actalog mfa challenge"
+
+// NewHMACKeySet creates a key set that signs with a shared secret
+func NewHMACKeySet(secret string) *KeySet {
+	keys := &KeySet{issuer: DefaultIssuer, secret: []byte(secret)}
+	if secret != "" {
+		mac := hmac.New(sha256.New, keys.secret)
+		mac.Write([]byte(challengeKeyLabel))
+		keys.challenge = mac.Sum(nil)
+	}
+	return keys
+}
+
+// SetIssuer sets the iss claim of issued tokens, which tokens must carry to
+// validate. An empty issuer keeps DefaultIssuer.
+func (s *KeySet) SetIssuer(issuer string) {
+	if issuer == "" {
+		issuer = DefaultIssuer
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.issuer = issuer
+}
+
+// Issuer returns the iss claim of issued tokens
+func (s *KeySet) Issuer() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.issuer
+}
+
+// SetKeys replaces the signing key and the keys accepted for verification.
+// The signing key is always accepted; a nil signing key reverts to the secret.
+func (s *KeySet) SetKeys(signing *SigningKey, verification []*SigningKey) {
+	keys := make(map[string]*SigningKey, len(verification)+1)
+	for _, key := range verification {
+		keys[key.ID] = key
+	}
+	if signing != nil {
+		keys[signing.ID] = signing
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.signing = signing
+	s.verification = keys
+}
+
+// SetSecretExpiry sets when the secret stops verifying tokens without a kid
+// while key pairs are in use. A zero time keeps accepting them.
+func (s *KeySet) SetSecretExpiry(until time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.secretUntil = until
+}
+
+// JWKS returns the public keys accepted for verification. The HS256 secret
+// is never published.
+func (s *KeySet) JWKS() JWKS {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	jwks := JWKS{Keys: []JWK{}}
+	for _, key := range s.verification {
+		jwks.Keys = append(jwks.Keys, key.JWK())
+	}
+	return jwks
+}
+
+// sign signs claims with the current signing key and sets the typ header
+func (s *KeySet) sign(claims jwt.Claims, typ string) (string, error) {
+	s.mu.RLock()
+	signing := s.signing
+	s.mu.RUnlock()
+
+	if signing == nil {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		token.Header["typ"] = typ
+		return token.SignedString(s.secret)
+	}
+
+	token := jwt.NewWithClaims(signing.method(), claims)
+	token.Header["typ"] = typ
+	token.Header["kid"] = signing.ID
+	return token.SignedString(signing.Private)
+}
+
+// signChallenge signs an MFA challenge with the challenge key, or with the
+// signing key when there is no secret to derive it from
+func (s *KeySet) signChallenge(claims jwt.Claims, typ string) (string, error) {
+	if len(s.challenge) == 0 {
+		return s.sign(claims, typ)
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token.Header["typ"] = typ
+	return token.SignedString(s.challenge)
+}
+
+// verificationKey returns the key to check a token's signature with. The
+// token's algorithm must match the key, so a public key can never be used as
+// an HMAC secret.
+func (s *KeySet) verificationKey(token *jwt.Token) (interface{}, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, ErrInvalidToken
+		}
+		// Once key pairs are in use, an empty or expired secret must not
+		// verify anything
+		if s.signing != nil && (len(s.secret) == 0 || (!s.secretUntil.IsZero() && time.Now().After(s.secretUntil))) {
+			return nil, ErrInvalidToken
+		}
+		return s.secret, nil
+	}
+
+	key, ok := s.verification[kid]
+	if !ok || token.Method != key.method() {
+		return nil, ErrInvalidToken
+	}
+	return key.Private.Public(), nil
+}
+
+// challengeVerificationKey returns the key to check an MFA challenge's
+// signature with: the challenge key for tokens without a kid, otherwise the
+// same key pairs as access tokens.
+func (s *KeySet) challengeVerificationKey(token *jwt.Token) (interface{}, error) {
+	if kid, _ := token.Header["kid"].(string); kid != "" {
+		return s.verificationKey(token)
+	}
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok || len(s.challenge) == 0 {
+		return nil, ErrInvalidToken
+	}
+	return s.challenge, nil
+}
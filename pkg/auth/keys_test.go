@@ -0,0 +1,249 @@
+package auth
+
+import (
+	"crypto/x509"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestKeySet_SignAndVerify(t *testing.T) {
+	for _, algorithm := range []string{AlgorithmRS256, AlgorithmEdDSA} {
+		t.Run(algorithm, func(t *testing.T) {
+			key, err := GenerateSigningKey(algorithm)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Keys survive a round trip through storage
+			pemData, err := key.MarshalPEM()
+			if err != nil {
+				t.Fatal(err)
+			}
+			stored, err := ParseSigningKey(key.ID, algorithm, pemData)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			keys := NewHMACKeySet("legacy-secret")
+			keys.SetKeys(stored, nil)
+
+			token, err := GenerateToken(7, "ana@example.com", "user", keys, time.Minute)
+			if err != nil {
+				t.Fatal(err)
+			}
+			parsed, _, err := jwt.NewParser().ParseUnverified(token, &Claims{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if parsed.Header["kid"] != key.ID || parsed.Header["alg"] != algorithm {
+				t.Errorf("unexpected header %v", parsed.Header)
+			}
+
+			claims, err := ValidateToken(token, keys)
+			if err != nil || claims.UserID != 7 {
+				t.Fatalf("expected token to validate, got claims=%+v err=%v", claims, err)
+			}
+
+			jwks := keys.JWKS()
+			if len(jwks.Keys) != 1 || jwks.Keys[0].KeyID != key.ID || jwks.Keys[0].Algorithm != algorithm || jwks.Keys[0].Use != "sig" {
+				t.Errorf("unexpected JWKS %+v", jwks)
+			}
+
+			// Another key set that only knows a different key rejects it
+			other, _ := GenerateSigningKey(algorithm)
+			otherKeys := NewHMACKeySet("legacy-secret")
+			otherKeys.SetKeys(other, nil)
+			if _, err := ValidateToken(token, otherKeys); err == nil {
+				t.Error("expected a token signed with an unknown key to be rejected")
+			}
+		})
+	}
+
+	if _, err := GenerateSigningKey("none"); !errors.Is(err, ErrUnsupportedAlgorithm) {
+		t.Errorf("expected ErrUnsupportedAlgorithm, got %v", err)
+	}
+}
+
+func TestKeySet_Rotation(t *testing.T) {
+	oldKey, _ := GenerateSigningKey(AlgorithmRS256)
+	newKey, _ := GenerateSigningKey(AlgorithmEdDSA)
+
+	keys := NewHMACKeySet("legacy-secret")
+	legacyToken, _ := GenerateToken(1, "ana@example.com", "user", keys, time.Minute)
+
+	keys.SetKeys(oldKey, nil)
+	oldToken, _ := GenerateToken(1, "ana@example.com", "user", keys, time.Minute)
+
+	keys.SetKeys(newKey, []*SigningKey{oldKey})
+	newToken, _ := GenerateToken(1, "ana@example.com", "user", keys, time.Minute)
+
+	for name, token := range map[string]string{"legacy": legacyToken, "old": oldToken, "new": newToken} {
+		if _, err := ValidateToken(token, keys); err != nil {
+			t.Errorf("expected %s token to validate, got %v", name, err)
+		}
+	}
+	if len(keys.JWKS().Keys) != 2 {
+		t.Errorf("expected both keys to be published, got %+v", keys.JWKS())
+	}
+
+	// Dropping the old key invalidates its tokens
+	keys.SetKeys(newKey, nil)
+	if _, err := ValidateToken(oldToken, keys); err == nil {
+		t.Error("expected token signed with a dropped key to be rejected")
+	}
+
+	// Without a secret, tokens without a kid aren't accepted
+	noSecret := NewHMACKeySet("")
+	noSecret.SetKeys(newKey, nil)
+	forged, _ := GenerateToken(1, "ana@example.com", "admin", NewHMACKeySet(""), time.Minute)
+	if _, err := ValidateToken(forged, noSecret); err == nil {
+		t.Error("expected a token signed with an empty secret to be rejected")
+	}
+}
+
+func TestKeySet_RejectsAlgorithmConfusion(t *testing.T) {
+	key, _ := GenerateSigningKey(AlgorithmRS256)
+	keys := NewHMACKeySet("legacy-secret")
+	keys.SetKeys(key, nil)
+
+	// An HS256 token "signed" with the published public key must not verify
+	publicDER, _ := x509.MarshalPKIXPublicKey(key.Private.Public())
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{
+		UserID: 1,
+		Role:   "admin",
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    DefaultIssuer,
+			Audience:  jwt.ClaimStrings{AccessTokenAudience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	})
+	token.Header["typ"] = AccessTokenType
+	token.Header["kid"] = key.ID
+	forged, err := token.SignedString(publicDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ValidateToken(forged, keys); err == nil {
+		t.Error("expected an HS256 token with an RS256 kid to be rejected")
+	}
+}
+
+func TestKeySet_MFAChallengesAreNotAccessTokens(t *testing.T) {
+	key, _ := GenerateSigningKey(AlgorithmEdDSA)
+	keys := NewHMACKeySet("legacy-secret")
+	keys.SetKeys(key, nil)
+
+	access, _ := GenerateToken(1, "ana@example.com", "user", keys, time.Minute)
+	parsed, _, _ := jwt.NewParser().ParseUnverified(access, &Claims{})
+	claims := parsed.Claims.(*Claims)
+	if parsed.Header["typ"] != AccessTokenType || claims.Issuer != DefaultIssuer || len(claims.Audience) != 1 || claims.Audience[0] != AccessTokenAudience {
+		t.Errorf("unexpected access token header %v, claims %+v", parsed.Header, claims.RegisteredClaims)
+	}
+
+	// Challenges are signed with a key that isn't published
+	challenge, _ := GenerateMFAToken(1, "ana@example.com", "user", keys, time.Minute)
+	parsed, _, _ = jwt.NewParser().ParseUnverified(challenge, &Claims{})
+	if parsed.Header["kid"] != nil || parsed.Header["typ"] != MFATokenType {
+		t.Errorf("unexpected challenge header %v", parsed.Header)
+	}
+	if _, err := ValidateMFAToken(challenge, keys); err != nil {
+		t.Errorf("expected challenge to validate, got %v", err)
+	}
+	if _, err := ValidateToken(challenge, keys); err == nil {
+		t.Error("expected a challenge to be rejected as an access token")
+	}
+	if _, err := ValidateMFAToken(access, keys); err == nil {
+		t.Error("expected an access token to be rejected as a challenge")
+	}
+	// The secret alone doesn't verify a challenge either
+	if _, err := ValidateToken(challenge, NewHMACKeySet("legacy-secret")); err == nil {
+		t.Error("expected the secret not to verify a challenge")
+	}
+
+	// Without a secret, challenges fall back to the signing key and the
+	// audience keeps them apart
+	noSecret := NewHMACKeySet("")
+	noSecret.SetKeys(key, nil)
+	challenge, _ = GenerateMFAToken(1, "ana@example.com", "user", noSecret, time.Minute)
+	if _, err := ValidateMFAToken(challenge, noSecret); err != nil {
+		t.Errorf("expected challenge to validate, got %v", err)
+	}
+	if _, err := ValidateToken(challenge, noSecret); err == nil {
+		t.Error("expected a challenge to be rejected as an access token")
+	}
+
+	// Tokens from another issuer are rejected
+	other := NewHMACKeySet("legacy-secret")
+	other.SetKeys(key, nil)
+	other.SetIssuer("https://other.example.com")
+	if _, err := ValidateToken(access, other); err == nil {
+		t.Error("expected a token from another issuer to be rejected")
+	}
+}
+
+func TestKeySet_SecretExpiry(t *testing.T) {
+	key, _ := GenerateSigningKey(AlgorithmEdDSA)
+	keys := NewHMACKeySet("legacy-secret")
+	legacyToken, _ := GenerateToken(1, "ana@example.com", "user", keys, time.Hour)
+	keys.SetKeys(key, nil)
+
+	keys.SetSecretExpiry(time.Now().Add(time.Minute))
+	if _, err := ValidateToken(legacyToken, keys); err != nil {
+		t.Errorf("expected a token without a kid to validate before the end date, got %v", err)
+	}
+
+	keys.SetSecretExpiry(time.Now().Add(-time.Minute))
+	if _, err := ValidateToken(legacyToken, keys); err == nil {
+		t.Error("expected a token without a kid to be rejected after the end date")
+	}
+	signed, _ := GenerateToken(1, "ana@example.com", "user", keys, time.Hour)
+	if _, err := ValidateToken(signed, keys); err != nil {
+		t.Errorf("expected tokens signed with the key pair to validate, got %v", err)
+	}
+
+	// Without key pairs the secret is the signing key and never expires
+	hmacOnly := NewHMACKeySet("legacy-secret")
+	hmacOnly.SetSecretExpiry(time.Now().Add(-time.Minute))
+	if _, err := ValidateToken(legacyToken, hmacOnly); err != nil {
+		t.Errorf("expected HS256-only key sets to ignore the end date, got %v", err)
+	}
+}
+
+func TestKeyCipher(t *testing.T) {
+	key, _ := GenerateSigningKey(AlgorithmRS256)
+	pemData, _ := key.MarshalPEM()
+
+	keyCipher, err := NewKeyCipher("a-long-random-key-encryption-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := keyCipher.Seal(pemData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsEncryptedKey(sealed) || strings.Contains(sealed, "PRIVATE KEY") {
+		t.Fatalf("expected an encrypted key, got %q", sealed)
+	}
+
+	opened, err := keyCipher.Open(sealed)
+	if err != nil || opened != pemData {
+		t.Fatalf("expected the key back, got err=%v", err)
+	}
+	if opened, err := keyCipher.Open(pemData); err != nil || opened != pemData {
+		t.Errorf("expected plain PEM to pass through, got err=%v", err)
+	}
+
+	wrongKey, _ := NewKeyCipher("some-other-key")
+	if _, err := wrongKey.Open(sealed); !errors.Is(err, ErrKeyDecryptFailed) {
+		t.Errorf("expected ErrKeyDecryptFailed with the wrong key, got %v", err)
+	}
+	var noCipher *KeyCipher
+	if _, err := noCipher.Open(sealed); !errors.Is(err, ErrKeyEncrypted) {
+		t.Errorf("expected ErrKeyEncrypted without a key, got %v", err)
+	}
+}
@@ -0,0 +1,109 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA-1 test key from RFC 6238 appendix B ("12345678901234567890")
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCode_RFC6238Vectors(t *testing.T) {
+	// The RFC lists 8-digit codes; 6-digit codes are their last six digits
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, tt := range tests {
+		got, err := TOTPCode(rfc6238Secret, TOTPStep(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("TOTPCode at %d = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	step := TOTPStep(now)
+
+	for _, offset := range []int64{-1, 0, 1} {
+		code, _ := TOTPCode(rfc6238Secret, step+offset)
+		got, ok := ValidateTOTP(rfc6238Secret, code, now)
+		if !ok || got != step+offset {
+			t.Errorf("expected code for step offset %d to validate, got step=%d ok=%v", offset, got, ok)
+		}
+	}
+
+	stale, _ := TOTPCode(rfc6238Secret, step-2)
+	if _, ok := ValidateTOTP(rfc6238Secret, stale, now); ok {
+		t.Error("expected a code two periods old to be rejected")
+	}
+	if _, ok := ValidateTOTP(rfc6238Secret, "050 471", now); !ok {
+		t.Error("expected spaces in the code to be ignored")
+	}
+	if _, ok := ValidateTOTP("not base32!", "050471", now); ok {
+		t.Error("expected an invalid secret to be rejected")
+	}
+}
+
+func TestTOTPProvisioningURI(t *testing.T) {
+	uri := TOTPProvisioningURI("ActaLog", "ana@example.com", "ABCDEF")
+	want := "otpauth://totp/ActaLog:ana@example.com?algorithm=SHA1&digits=6&issuer=ActaLog&period=30&secret=ABCDEF"
+	if uri != want {
+		t.Errorf("got %s, want %s", uri, want)
+	}
+}
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes(10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	seen := map[string]bool{}
+	for _, code := range codes {
+		if len(code) != 11 || code[5] != '-' || strings.ContainsAny(code, "01ilo") {
+			t.Errorf("unexpected recovery code format %q", code)
+		}
+		if seen[code] {
+			t.Errorf("duplicate recovery code %q", code)
+		}
+		seen[code] = true
+
+		if got := NormalizeRecoveryCode(strings.ToUpper(strings.ReplaceAll(code, "-", ""))); got != code {
+			t.Errorf("NormalizeRecoveryCode = %q, want %q", got, code)
+		}
+	}
+}
+
+func TestMFATokenIsNotAnAccessToken(t *testing.T) {
+	secret := NewHMACKeySet("test-secret")
+
+	mfaToken, err := GenerateMFAToken(1, "ana@example.com", "user", secret, time.Minute)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := ValidateToken(mfaToken, secret); err == nil {
+		t.Error("expected an MFA token to be rejected as an access token")
+	}
+	if claims, err := ValidateMFAToken(mfaToken, secret); err != nil || claims.UserID != 1 {
+		t.Errorf("expected MFA token to validate, got claims=%+v err=%v", claims, err)
+	}
+
+	accessToken, _ := GenerateToken(1, "ana@example.com", "user", secret, time.Minute)
+	if _, err := ValidateMFAToken(accessToken, secret); err == nil {
+		t.Error("expected an access token to be rejected as an MFA token")
+	}
+}
@@ -1,12 +1,23 @@
 package email
 
 import (
+	"bytes"
+	"context"
+	"crypto/rand"
 	"crypto/tls"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"log"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
 	"net/smtp"
+	"net/textproto"
 	"regexp"
 	"strings"
+	"time"
 )
 
 // Config holds email configuration
@@ -21,31 +32,45 @@ type Config struct {
 
 // Service handles email sending
 type Service struct {
-	config Config
-	logger *log.Logger
+	config   Config
+	renderer *Renderer
+	logger   *log.Logger
 }
 
 // EmailService defines the methods used by other packages so tests can provide fakes.
 // The concrete *Service type implements this interface.
 type EmailService interface {
-	SendPasswordResetEmail(to, resetURL string) error
-	SendVerificationEmail(to, verifyURL string) error
+	SendPasswordResetEmail(ctx context.Context, to Recipient, resetURL string) error
+	SendVerificationEmail(ctx context.Context, to Recipient, verifyURL string) error
+	SendTemplate(ctx context.Context, name string, to Recipient, data map[string]interface{}) error
 }
 
-// NewService creates a new email service
-func NewService(config Config, logger *log.Logger) *Service {
+// Sender delivers a fully built message. The concrete *Service type implements this interface.
+type Sender interface {
+	Send(msg Message) error
+}
+
+// NewService creates a new email service using the given template renderer
+// (DefaultRenderer if nil)
+func NewService(config Config, renderer *Renderer, logger *log.Logger) *Service {
+	if renderer == nil {
+		renderer = DefaultRenderer()
+	}
 	return &Service{
-		config: config,
-		logger: logger,
+		config:   config,
+		renderer: renderer,
+		logger:   logger,
 	}
 }
 
-// Message represents an email message
+// Message represents an email message. When IsHTML is set and TextBody is
+// non-empty the message is sent as multipart/alternative with both bodies.
 type Message struct {
-	To      []string
-	Subject string
-	Body    string
-	IsHTML  bool
+	To       []string // Addresses, optionally with display names ("Name <addr>")
+	Subject  string
+	Body     string
+	TextBody string // Plain-text alternative for HTML messages
+	IsHTML   bool
 }
 
 // extractEmailAddress extracts the email address from a string that may contain a display name
@@ -74,28 +99,24 @@ func (s *Service) Send(msg Message) error {
 	from := s.config.FromAddress
 	if s.config.FromName != "" && !strings.Contains(s.config.FromAddress, "<") {
 		// Only add display name if FromAddress doesn't already include it
-		from = fmt.Sprintf("%s <%s>", s.config.FromName, fromEmail)
+		from = (&mail.Address{Name: s.config.FromName, Address: fromEmail}).String()
 	}
 
-	// Build headers
-	headers := make(map[string]string)
-	headers["From"] = from
-	headers["To"] = strings.Join(msg.To, ", ")
-	headers["Subject"] = msg.Subject
-	headers["MIME-Version"] = "1.0"
-
-	if msg.IsHTML {
-		headers["Content-Type"] = "text/html; charset=UTF-8"
-	} else {
-		headers["Content-Type"] = "text/plain; charset=UTF-8"
+	// SMTP envelope recipients are bare addresses
+	recipients := make([]string, len(msg.To))
+	for i, to := range msg.To {
+		if addr, err := mail.ParseAddress(to); err == nil {
+			recipients[i] = addr.Address
+		} else {
+			recipients[i] = extractEmailAddress(to)
+		}
 	}
 
-	// Build message
-	message := ""
-	for k, v := range headers {
-		message += fmt.Sprintf("%s: %s\r\n", k, v)
+	message, err := buildMessage(from, fromEmail, msg, time.Now())
+	if err != nil {
+		s.logger.Printf("[ERROR] Failed to build email to %v: %v", msg.To, err)
+		return err
 	}
-	message += "\r\n" + msg.Body
 
 	// Connect to SMTP server
 	addr := fmt.Sprintf("%s:%d", s.config.SMTPHost, s.config.SMTPPort)
@@ -104,17 +125,16 @@ func (s *Service) Send(msg Message) error {
 	// Setup authentication
 	auth := smtp.PlainAuth("", s.config.SMTPUser, s.config.SMTPPassword, s.config.SMTPHost)
 
-	var err error
 	// For TLS connections (port 465)
 	if s.config.SMTPPort == 465 {
 		s.logger.Printf("[INFO] Using TLS connection (port 465)")
 		// Pass the extracted email address (not the display name version)
-		err = s.sendWithTLS(addr, auth, fromEmail, msg.To, []byte(message))
+		err = s.sendWithTLS(addr, auth, fromEmail, recipients, message)
 	} else {
 		// For STARTTLS connections (port 587) or plain (port 25)
 		s.logger.Printf("[INFO] Using STARTTLS connection (port %d)", s.config.SMTPPort)
 		// Use extracted email address for SMTP envelope
-		err = smtp.SendMail(addr, auth, fromEmail, msg.To, []byte(message))
+		err = smtp.SendMail(addr, auth, fromEmail, recipients, message)
 	}
 
 	if err != nil {
@@ -126,6 +146,107 @@ func (s *Service) Send(msg Message) error {
 	return nil
 }
 
+// buildMessage renders the message headers and MIME body. Messages with both an
+// HTML and a text body are sent as multipart/alternative; bodies are
+// quoted-printable and non-ASCII header values are RFC 2047 encoded.
+func buildMessage(from, fromEmail string, msg Message, date time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+	writeHeader := func(key, value string) {
+		fmt.Fprintf(&buf, "%s: %s\r\n", key, value)
+	}
+
+	to := make([]string, len(msg.To))
+	for i, addr := range msg.To {
+		to[i] = encodeAddress(addr)
+	}
+
+	messageID, err := newMessageID(fromEmail)
+	if err != nil {
+		return nil, err
+	}
+
+	writeHeader("From", encodeAddress(from))
+	writeHeader("To", strings.Join(to, ", "))
+	writeHeader("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	writeHeader("Date", date.Format(time.RFC1123Z))
+	writeHeader("Message-ID", messageID)
+	writeHeader("MIME-Version", "1.0")
+
+	if msg.IsHTML && msg.TextBody != "" {
+		mw := multipart.NewWriter(&buf)
+		writeHeader("Content-Type", mime.FormatMediaType("multipart/alternative", map[string]string{"boundary": mw.Boundary()}))
+		buf.WriteString("\r\n")
+
+		for _, part := range []struct{ contentType, body string }{
+			{"text/plain", msg.TextBody},
+			{"text/html", msg.Body},
+		} {
+			pw, err := mw.CreatePart(textproto.MIMEHeader{
+				"Content-Type":              {part.contentType + "; charset=UTF-8"},
+				"Content-Transfer-Encoding": {"quoted-printable"},
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to create MIME part: %w", err)
+			}
+			if err := writeQuotedPrintable(pw, part.body); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := mw.Close(); err != nil {
+			return nil, fmt.Errorf("failed to close MIME writer: %w", err)
+		}
+		return buf.Bytes(), nil
+	}
+
+	contentType := "text/plain; charset=UTF-8"
+	if msg.IsHTML {
+		contentType = "text/html; charset=UTF-8"
+	}
+	writeHeader("Content-Type", contentType)
+	writeHeader("Content-Transfer-Encoding", "quoted-printable")
+	buf.WriteString("\r\n")
+	if err := writeQuotedPrintable(&buf, msg.Body); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// encodeAddress re-formats an address so non-ASCII display names are RFC 2047
+// encoded. Unparseable addresses are returned unchanged.
+func encodeAddress(addr string) string {
+	parsed, err := mail.ParseAddress(addr)
+	if err != nil {
+		return addr
+	}
+	return parsed.String()
+}
+
+func writeQuotedPrintable(w io.Writer, body string) error {
+	qw := quotedprintable.NewWriter(w)
+	if _, err := qw.Write([]byte(body)); err != nil {
+		return fmt.Errorf("failed to encode message body: %w", err)
+	}
+	if err := qw.Close(); err != nil {
+		return fmt.Errorf("failed to encode message body: %w", err)
+	}
+	return nil
+}
+
+// newMessageID generates a unique Message-ID using the sender's domain
+func newMessageID(fromEmail string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate message id: %w", err)
+	}
+
+	domain := "actalog.local"
+	if i := strings.LastIndex(fromEmail, "@"); i >= 0 && i < len(fromEmail)-1 {
+		domain = fromEmail[i+1:]
+	}
+	return fmt.Sprintf("<%s@%s>", hex.EncodeToString(b), domain), nil
+}
+
 // sendWithTLS sends email using TLS (for port 465)
 func (s *Service) sendWithTLS(addr string, auth smtp.Auth, fromEmail string, to []string, msg []byte) error {
 	s.logger.Printf("[INFO] Starting TLS connection to %s", addr)
@@ -204,103 +325,31 @@ func (s *Service) sendWithTLS(addr string, auth smtp.Auth, fromEmail string, to
 }
 
 // SendPasswordResetEmail sends a password reset email
-func (s *Service) SendPasswordResetEmail(to, resetURL string) error {
-	s.logger.Printf("[INFO] Preparing password reset email for %s with URL: %s", to, resetURL)
-	subject := "ActaLog - Password Reset Request"
-
-	body := fmt.Sprintf(`
-<!DOCTYPE html>
-<html>
-<head>
-    <style>
-        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
-        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
-        .header { background-color: #00bcd4; color: white; padding: 20px; text-align: center; }
-        .content { padding: 20px; background-color: #f5f7fa; }
-        .button { display: inline-block; padding: 12px 24px; background-color: #ffc107; color: #1a1a1a; text-decoration: none; border-radius: 4px; font-weight: bold; }
-        .footer { padding: 20px; text-align: center; font-size: 12px; color: #666; }
-    </style>
-</head>
-<body>
-    <div class="container">
-        <div class="header">
-            <h1>ActaLog</h1>
-        </div>
-        <div class="content">
-            <h2>Password Reset Request</h2>
-            <p>You requested to reset your password for your ActaLog account.</p>
-            <p>Click the button below to reset your password. This link will expire in 1 hour.</p>
-            <p style="text-align: center; margin: 30px 0;">
-                <a href="%s" class="button">Reset Password</a>
-            </p>
-            <p>Or copy and paste this URL into your browser:</p>
-            <p style="word-break: break-all; background-color: white; padding: 10px; border-radius: 4px;">%s</p>
-            <p><strong>If you didn't request this password reset, you can safely ignore this email.</strong></p>
-        </div>
-        <div class="footer">
-            <p>&copy; 2024 ActaLog. All rights reserved.</p>
-            <p>This is an automated email. Please do not reply.</p>
-        </div>
-    </div>
-</body>
-</html>
-`, resetURL, resetURL)
-
-	return s.Send(Message{
-		To:      []string{to},
-		Subject: subject,
-		Body:    body,
-		IsHTML:  true,
-	})
+func (s *Service) SendPasswordResetEmail(ctx context.Context, to Recipient, resetURL string) error {
+	s.logger.Printf("[INFO] Preparing password reset email for %s", to.Email)
+	msg, err := s.renderer.PasswordResetMessage(to, resetURL)
+	if err != nil {
+		return err
+	}
+	return s.Send(msg)
 }
 
 // SendVerificationEmail sends an email verification email
-func (s *Service) SendVerificationEmail(to, verifyURL string) error {
-	s.logger.Printf("[INFO] Preparing verification email for %s with URL: %s", to, verifyURL)
-	subject := "ActaLog - Verify Your Email Address"
-
-	body := fmt.Sprintf(`
-<!DOCTYPE html>
-<html>
-<head>
-    <style>
-        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
-        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
-        .header { background-color: #00bcd4; color: white; padding: 20px; text-align: center; }
-        .content { padding: 20px; background-color: #f5f7fa; }
-        .button { display: inline-block; padding: 12px 24px; background-color: #ffc107; color: #1a1a1a; text-decoration: none; border-radius: 4px; font-weight: bold; }
-        .footer { padding: 20px; text-align: center; font-size: 12px; color: #666; }
-    </style>
-</head>
-<body>
-    <div class="container">
-        <div class="header">
-            <h1>ActaLog</h1>
-        </div>
-        <div class="content">
-            <h2>Welcome to ActaLog!</h2>
-            <p>Thanks for signing up! Please verify your email address to get started.</p>
-            <p>Click the button below to verify your email. This link will expire in 24 hours.</p>
-            <p style="text-align: center; margin: 30px 0;">
-                <a href="%s" class="button">Verify Email</a>
-            </p>
-            <p>Or copy and paste this URL into your browser:</p>
-            <p style="word-break: break-all; background-color: white; padding: 10px; border-radius: 4px;">%s</p>
-            <p><strong>If you didn't create an ActaLog account, you can safely ignore this email.</strong></p>
-        </div>
-        <div class="footer">
-            <p>&copy; 2024 ActaLog. All rights reserved.</p>
-            <p>This is an automated email. Please do not reply.</p>
-        </div>
-    </div>
-</body>
-</html>
-`, verifyURL, verifyURL)
-
-	return s.Send(Message{
-		To:      []string{to},
-		Subject: subject,
-		Body:    body,
-		IsHTML:  true,
-	})
+func (s *Service) SendVerificationEmail(ctx context.Context, to Recipient, verifyURL string) error {
+	s.logger.Printf("[INFO] Preparing verification email for %s", to.Email)
+	msg, err := s.renderer.VerificationMessage(to, verifyURL)
+	if err != nil {
+		return err
+	}
+	return s.Send(msg)
+}
+
+// SendTemplate renders the named template for the recipient and sends it
+func (s *Service) SendTemplate(ctx context.Context, name string, to Recipient, data map[string]interface{}) error {
+	s.logger.Printf("[INFO] Preparing %s email for %s", name, to.Email)
+	msg, err := s.renderer.Message(name, to, data)
+	if err != nil {
+		return err
+	}
+	return s.Send(msg)
 }
@@ -0,0 +1,72 @@
+package email
+
+import (
+	"context"
+	"io"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/johnzastrow/actalog/internal/testhelpers"
+)
+
+func TestService_Send(t *testing.T) {
+	server, err := testhelpers.NewFakeSMTPServer()
+	if err != nil {
+		t.Fatalf("failed to start fake SMTP server: %v", err)
+	}
+	defer server.Close()
+
+	svc := NewService(Config{
+		SMTPHost:     server.Host,
+		SMTPPort:     server.Port,
+		SMTPUser:     "user",
+		SMTPPassword: "pass",
+		FromAddress:  "noreply@example.com",
+		FromName:     "ActaLog",
+	}, nil, log.New(io.Discard, "", 0))
+
+	if err := svc.SendPasswordResetEmail(context.Background(), Recipient{Email: "athlete@example.com"}, "https://example.com/reset/abc"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	messages := server.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(messages))
+	}
+	msg := messages[0]
+	if msg.From != "noreply@example.com" {
+		t.Errorf("expected envelope sender noreply@example.com, got %q", msg.From)
+	}
+	if len(msg.To) != 1 || msg.To[0] != "athlete@example.com" {
+		t.Errorf("unexpected recipients: %v", msg.To)
+	}
+	if !strings.Contains(msg.Data, "Subject: ActaLog - Password Reset Request") {
+		t.Error("expected subject header in message")
+	}
+	if !strings.Contains(msg.Data, "https://example.com/reset/abc") {
+		t.Error("expected reset URL in message body")
+	}
+}
+
+func TestService_SendTemporaryFailure(t *testing.T) {
+	server, err := testhelpers.NewFakeSMTPServer()
+	if err != nil {
+		t.Fatalf("failed to start fake SMTP server: %v", err)
+	}
+	defer server.Close()
+	server.FailNext(1)
+
+	svc := NewService(Config{
+		SMTPHost:    server.Host,
+		SMTPPort:    server.Port,
+		FromAddress: "noreply@example.com",
+	}, nil, log.New(io.Discard, "", 0))
+
+	if err := svc.Send(Message{To: []string{"a@example.com"}, Subject: "Hi", Body: "Hello"}); err == nil {
+		t.Fatal("expected error from temporary SMTP failure")
+	}
+	if len(server.Messages()) != 0 {
+		t.Error("expected no messages to be accepted")
+	}
+}
@@ -0,0 +1,384 @@
+package email
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"net/mail"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+// Built-in template names
+const (
+	TemplatePasswordReset = "password_reset"
+	TemplateVerification  = "verification"
+	TemplateWeeklyDigest  = "weekly_digest"
+	TemplateNotification  = "notification"
+	TemplateAccountLocked = "account_locked"
+)
+
+// DefaultLocale is the locale used when a recipient's locale has no templates
+const DefaultLocale = "en"
+
+// ErrTemplateNotFound is returned when no locale provides the requested template
+var ErrTemplateNotFound = errors.New("email template not found")
+
+// Templates live in templates/<locale>/<name>.txt (required) and
+// templates/<locale>/<name>.html (optional). The .txt file defines a
+// "subject" template; the rest of the file is the plain-text body. The .html
+// file defines a "content" template that is rendered inside layout.html, and
+// may also redefine the layout's "rights" and "automated" footer strings.
+//
+//go:embed templates
+var embeddedTemplates embed.FS
+
+var templateNamePattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// sampleData is the data used to render admin previews of each template
+var sampleData = map[string]map[string]interface{}{
+	TemplatePasswordReset: {"URL": "https://actalog.example.com/reset-password?token=preview"},
+	TemplateVerification:  {"URL": "https://actalog.example.com/verify-email?token=preview"},
+	TemplateWeeklyDigest: {
+		"WeekStart":  time.Date(2025, 11, 10, 0, 0, 0, 0, time.UTC),
+		"WeekEnd":    time.Date(2025, 11, 16, 0, 0, 0, 0, time.UTC),
+		"Workouts":   4,
+		"Volume":     "18,450",
+		"WeightUnit": "lbs",
+		"WeekStreak": 6,
+		"PRs": []map[string]interface{}{
+			{"Name": "Back Squat", "Score": "275 lbs", "Date": time.Date(2025, 11, 12, 0, 0, 0, 0, time.UTC)},
+			{"Name": "Fran", "Score": "4:12", "Date": time.Date(2025, 11, 14, 0, 0, 0, 0, time.UTC)},
+		},
+		"Upcoming": []map[string]interface{}{
+			{"Name": "Murph", "Date": time.Date(2025, 11, 17, 0, 0, 0, 0, time.UTC)},
+		},
+		"SettingsURL": "https://actalog.example.com/settings",
+	},
+	TemplateNotification: {
+		"Title":       "New PR: Back Squat",
+		"Body":        "You set a personal record of 275 lbs on Nov 12.",
+		"URL":         "https://actalog.example.com/workouts/12",
+		"SettingsURL": "https://actalog.example.com/settings",
+	},
+	TemplateAccountLocked: {
+		"Attempts": 5,
+		"Minutes":  15,
+		"URL":      "https://actalog.example.com/forgot-password",
+	},
+}
+
+// Recipient is the addressee of a templated email
+type Recipient struct {
+	Email  string
+	Name   string
+	Locale string
+}
+
+// Address formats the recipient as an RFC 5322 address, encoding non-ASCII names
+func (r Recipient) Address() string {
+	if r.Name == "" {
+		return r.Email
+	}
+	return (&mail.Address{Name: r.Name, Address: r.Email}).String()
+}
+
+// Rendered is the output of rendering a template
+type Rendered struct {
+	Template string `json:"template"`
+	Locale   string `json:"locale"`
+	Subject  string `json:"subject"`
+	Text     string `json:"text"`
+	HTML     string `json:"html,omitempty"`
+}
+
+// TemplateInfo describes an available template and the locales that provide it
+type TemplateInfo struct {
+	Name    string   `json:"name"`
+	Locales []string `json:"locales"`
+}
+
+// Renderer renders email templates from the embedded defaults, optionally
+// overridden file-by-file from a directory on disk
+type Renderer struct {
+	fsys fs.FS
+}
+
+// NewRenderer creates a renderer. If overrideDir is non-empty, any file found
+// there (same layout as the embedded templates directory) takes precedence.
+func NewRenderer(overrideDir string) (*Renderer, error) {
+	embedded, err := fs.Sub(embeddedTemplates, "templates")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load embedded email templates: %w", err)
+	}
+	if overrideDir == "" {
+		return &Renderer{fsys: embedded}, nil
+	}
+
+	info, err := os.Stat(overrideDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open email template directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("email template path %s is not a directory", overrideDir)
+	}
+
+	return &Renderer{fsys: layeredFS{os.DirFS(overrideDir), embedded}}, nil
+}
+
+// DefaultRenderer returns a renderer that uses only the embedded templates
+func DefaultRenderer() *Renderer {
+	r, err := NewRenderer("")
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// Render renders a template for the given locale. The locale falls back to its
+// base language (e.g. "es-MX" to "es") and then to DefaultLocale. "Locale" and
+// "Year" are added to the data if not already set.
+func (r *Renderer) Render(name, locale string, data map[string]interface{}) (*Rendered, error) {
+	if !templateNamePattern.MatchString(name) {
+		return nil, ErrTemplateNotFound
+	}
+
+	locale = r.resolveLocale(name, locale)
+	if locale == "" {
+		return nil, ErrTemplateNotFound
+	}
+
+	values := map[string]interface{}{
+		"Locale": locale,
+		"Year":   time.Now().Year(),
+	}
+	for k, v := range data {
+		values[k] = v
+	}
+
+	rendered := &Rendered{Template: name, Locale: locale}
+
+	textSrc, err := fs.ReadFile(r.fsys, locale+"/"+name+".txt")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template %s/%s.txt: %w", locale, name, err)
+	}
+	textTmpl, err := texttemplate.New(name).Option("missingkey=zero").Parse(string(textSrc))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template %s/%s.txt: %w", locale, name, err)
+	}
+
+	var buf bytes.Buffer
+	if err := textTmpl.ExecuteTemplate(&buf, "subject", values); err != nil {
+		return nil, fmt.Errorf("failed to render subject for %s: %w", name, err)
+	}
+	// Collapse whitespace so a template can never inject extra header lines
+	rendered.Subject = strings.Join(strings.Fields(buf.String()), " ")
+
+	buf.Reset()
+	if err := textTmpl.Execute(&buf, values); err != nil {
+		return nil, fmt.Errorf("failed to render text body for %s: %w", name, err)
+	}
+	rendered.Text = strings.TrimLeft(buf.String(), "\r\n")
+
+	htmlSrc, err := fs.ReadFile(r.fsys, locale+"/"+name+".html")
+	if errors.Is(err, fs.ErrNotExist) {
+		return rendered, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template %s/%s.html: %w", locale, name, err)
+	}
+	layoutSrc, err := fs.ReadFile(r.fsys, "layout.html")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read email layout: %w", err)
+	}
+
+	htmlTmpl, err := htmltemplate.New("layout").Option("missingkey=zero").Parse(string(layoutSrc))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse email layout: %w", err)
+	}
+	if _, err := htmlTmpl.Parse(string(htmlSrc)); err != nil {
+		return nil, fmt.Errorf("failed to parse template %s/%s.html: %w", locale, name, err)
+	}
+
+	buf.Reset()
+	if err := htmlTmpl.ExecuteTemplate(&buf, "layout", values); err != nil {
+		return nil, fmt.Errorf("failed to render HTML body for %s: %w", name, err)
+	}
+	rendered.HTML = buf.String()
+
+	return rendered, nil
+}
+
+// Preview renders a template with built-in sample data
+func (r *Renderer) Preview(name, locale string) (*Rendered, error) {
+	data := map[string]interface{}{"Name": "Alex Athlete"}
+	for k, v := range sampleData[name] {
+		data[k] = v
+	}
+	return r.Render(name, locale, data)
+}
+
+// Templates lists the available templates (those present in DefaultLocale)
+// along with every locale that provides them
+func (r *Renderer) Templates() ([]TemplateInfo, error) {
+	entries, err := fs.ReadDir(r.fsys, DefaultLocale)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list email templates: %w", err)
+	}
+
+	locales, err := r.locales()
+	if err != nil {
+		return nil, err
+	}
+
+	templates := []TemplateInfo{}
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".txt")
+		if entry.IsDir() || !ok {
+			continue
+		}
+
+		info := TemplateInfo{Name: name, Locales: []string{}}
+		for _, locale := range locales {
+			if r.exists(locale, name) {
+				info.Locales = append(info.Locales, locale)
+			}
+		}
+		templates = append(templates, info)
+	}
+
+	return templates, nil
+}
+
+// Message renders a template into a message addressed to the recipient. The
+// recipient's name is available to templates as .Name unless data sets it.
+func (r *Renderer) Message(name string, to Recipient, data map[string]interface{}) (Message, error) {
+	values := map[string]interface{}{"Name": to.Name}
+	for k, v := range data {
+		values[k] = v
+	}
+
+	rendered, err := r.Render(name, to.Locale, values)
+	if err != nil {
+		return Message{}, err
+	}
+
+	msg := Message{
+		To:      []string{to.Address()},
+		Subject: rendered.Subject,
+		Body:    rendered.Text,
+	}
+	if rendered.HTML != "" {
+		msg.Body = rendered.HTML
+		msg.TextBody = rendered.Text
+		msg.IsHTML = true
+	}
+	return msg, nil
+}
+
+// PasswordResetMessage builds the password reset email
+func (r *Renderer) PasswordResetMessage(to Recipient, resetURL string) (Message, error) {
+	return r.Message(TemplatePasswordReset, to, map[string]interface{}{"URL": resetURL})
+}
+
+// VerificationMessage builds the email verification email
+func (r *Renderer) VerificationMessage(to Recipient, verifyURL string) (Message, error) {
+	return r.Message(TemplateVerification, to, map[string]interface{}{"URL": verifyURL})
+}
+
+// resolveLocale returns the first of locale, its base language and
+// DefaultLocale that provides the template, or "" if none does
+func (r *Renderer) resolveLocale(name, locale string) string {
+	locale = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+
+	candidates := []string{}
+	if locale != "" && templateNamePattern.MatchString(strings.ReplaceAll(locale, "-", "_")) {
+		candidates = append(candidates, locale)
+		if base, _, ok := strings.Cut(locale, "-"); ok {
+			candidates = append(candidates, base)
+		}
+	}
+	candidates = append(candidates, DefaultLocale)
+
+	for _, candidate := range candidates {
+		if r.exists(candidate, name) {
+			return candidate
+		}
+	}
+	return ""
+}
+
+func (r *Renderer) exists(locale, name string) bool {
+	_, err := fs.Stat(r.fsys, locale+"/"+name+".txt")
+	return err == nil
+}
+
+func (r *Renderer) locales() ([]string, error) {
+	entries, err := fs.ReadDir(r.fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list email template locales: %w", err)
+	}
+
+	locales := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			locales = append(locales, entry.Name())
+		}
+	}
+	return locales, nil
+}
+
+// layeredFS resolves each path against its layers in order, so files in
+// earlier layers shadow files with the same path in later ones
+type layeredFS []fs.FS
+
+func (l layeredFS) Open(name string) (fs.File, error) {
+	for _, layer := range l {
+		f, err := layer.Open(name)
+		if err == nil {
+			return f, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+// ReadDir merges the directory listings of every layer
+func (l layeredFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	seen := map[string]fs.DirEntry{}
+	found := false
+	for _, layer := range l {
+		entries, err := fs.ReadDir(layer, name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		found = true
+		for _, entry := range entries {
+			if _, ok := seen[entry.Name()]; !ok {
+				seen[entry.Name()] = entry
+			}
+		}
+	}
+	if !found {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+
+	entries := make([]fs.DirEntry, 0, len(seen))
+	for _, entry := range seen {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
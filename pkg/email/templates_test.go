@@ -0,0 +1,250 @@
+package email
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderer_Render(t *testing.T) {
+	r := DefaultRenderer()
+
+	rendered, err := r.Render(TemplatePasswordReset, "en", map[string]interface{}{
+		"Name": "Jane <Doe>",
+		"URL":  "https://example.com/reset?token=abc&x=1",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if rendered.Subject != "ActaLog - Password Reset Request" {
+		t.Errorf("unexpected subject %q", rendered.Subject)
+	}
+	if !strings.HasPrefix(rendered.Text, "Hi Jane <Doe>,") {
+		t.Errorf("expected text body to greet the user unescaped, got %q", rendered.Text)
+	}
+	if !strings.Contains(rendered.Text, "https://example.com/reset?token=abc&x=1") {
+		t.Error("expected reset URL in text body")
+	}
+	if !strings.Contains(rendered.HTML, "Hi Jane &lt;Doe&gt;") {
+		t.Error("expected HTML body to escape the user's name")
+	}
+	if !strings.Contains(rendered.HTML, `href="https://example.com/reset?token=abc&amp;x=1"`) {
+		t.Error("expected reset URL in HTML link")
+	}
+	if !strings.Contains(rendered.HTML, "All rights reserved.") {
+		t.Error("expected HTML body to be wrapped in the layout")
+	}
+}
+
+func TestRenderer_LocaleFallback(t *testing.T) {
+	r := DefaultRenderer()
+
+	tests := []struct {
+		locale string
+		want   string
+	}{
+		{"es", "es"},
+		{"es-MX", "es"},
+		{"es_mx", "es"},
+		{"fr", "en"},
+		{"", "en"},
+		{"../../etc", "en"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.locale, func(t *testing.T) {
+			rendered, err := r.Render(TemplateVerification, tt.locale, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if rendered.Locale != tt.want {
+				t.Errorf("locale %q resolved to %q, want %q", tt.locale, rendered.Locale, tt.want)
+			}
+		})
+	}
+
+	rendered, err := r.Render(TemplateVerification, "es", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(rendered.HTML, "Todos los derechos reservados.") {
+		t.Error("expected Spanish template to override the layout footer")
+	}
+}
+
+func TestRenderer_UnknownTemplate(t *testing.T) {
+	r := DefaultRenderer()
+
+	for _, name := range []string{"nope", "../layout", ""} {
+		if _, err := r.Render(name, "en", nil); !errors.Is(err, ErrTemplateNotFound) {
+			t.Errorf("Render(%q) error = %v, want ErrTemplateNotFound", name, err)
+		}
+	}
+}
+
+func TestRenderer_DiskOverride(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "en"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "de"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	override := "{{define \"subject\"}}Custom reset{{end}}\nReset here: {{.URL}}\n"
+	if err := os.WriteFile(filepath.Join(dir, "en", "password_reset.txt"), []byte(override), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	german := "{{define \"subject\"}}Passwort zurücksetzen{{end}}\n{{.URL}}\n"
+	if err := os.WriteFile(filepath.Join(dir, "de", "password_reset.txt"), []byte(german), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewRenderer(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rendered, err := r.Render(TemplatePasswordReset, "en", map[string]interface{}{"URL": "https://example.com/r"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rendered.Subject != "Custom reset" || rendered.Text != "Reset here: https://example.com/r\n" {
+		t.Errorf("expected overridden text template, got %+v", rendered)
+	}
+	// The HTML template isn't overridden, so the embedded one is still used
+	if !strings.Contains(rendered.HTML, "Reset Password") {
+		t.Error("expected embedded HTML template to be used")
+	}
+
+	// New locales can be added from disk
+	rendered, err = r.Render(TemplatePasswordReset, "de", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rendered.Locale != "de" || rendered.HTML != "" {
+		t.Errorf("expected text-only German template, got %+v", rendered)
+	}
+
+	templates, err := r.Templates()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, tmpl := range templates {
+		if tmpl.Name == TemplatePasswordReset && strings.Join(tmpl.Locales, ",") != "de,en,es" {
+			t.Errorf("unexpected locales for %s: %v", tmpl.Name, tmpl.Locales)
+		}
+	}
+
+	if _, err := NewRenderer(filepath.Join(dir, "missing")); err == nil {
+		t.Error("expected error for missing template directory")
+	}
+}
+
+func TestBuildMessage_Multipart(t *testing.T) {
+	msg, err := DefaultRenderer().PasswordResetMessage(Recipient{
+		Email:  "jose@example.com",
+		Name:   "José Müller",
+		Locale: "es",
+	}, "https://example.com/reset?token=abc")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	raw, err := buildMessage(`"ActaLog Café" <noreply@example.com>`, "noreply@example.com", msg, time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	parsed, err := mail.ReadMessage(strings.NewReader(string(raw)))
+	if err != nil {
+		t.Fatalf("failed to parse message: %v", err)
+	}
+
+	// Headers must be 7-bit; non-ASCII is RFC 2047 encoded
+	for key, values := range parsed.Header {
+		for _, v := range values {
+			for _, c := range v {
+				if c > 127 {
+					t.Errorf("header %s contains non-ASCII value %q", key, v)
+					break
+				}
+			}
+		}
+	}
+
+	to, err := parsed.Header.AddressList("To")
+	if err != nil || len(to) != 1 || to[0].Name != "José Müller" || to[0].Address != "jose@example.com" {
+		t.Errorf("unexpected To header %q (%v)", parsed.Header.Get("To"), err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	if err != nil || subject != "ActaLog - Restablecimiento de contraseña" {
+		t.Errorf("unexpected subject %q (%v)", subject, err)
+	}
+	if !strings.HasSuffix(parsed.Header.Get("Message-ID"), "@example.com>") {
+		t.Errorf("unexpected Message-ID %q", parsed.Header.Get("Message-ID"))
+	}
+	if _, err := parsed.Header.Date(); err != nil {
+		t.Errorf("invalid Date header: %v", err)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("expected multipart/alternative, got %q (%v)", parsed.Header.Get("Content-Type"), err)
+	}
+
+	mr := multipart.NewReader(parsed.Body, params["boundary"])
+	var types, bodies []string
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("failed to read part: %v", err)
+		}
+		// NextPart transparently decodes quoted-printable
+		body, _ := io.ReadAll(part)
+		types = append(types, strings.Split(part.Header.Get("Content-Type"), ";")[0])
+		bodies = append(bodies, string(body))
+	}
+
+	if strings.Join(types, ",") != "text/plain,text/html" {
+		t.Fatalf("unexpected parts %v", types)
+	}
+	if !strings.Contains(bodies[0], "Hola José Müller,") || !strings.Contains(bodies[0], "https://example.com/reset?token=abc") {
+		t.Errorf("unexpected text part %q", bodies[0])
+	}
+	if !strings.Contains(bodies[1], `<a href="https://example.com/reset?token=abc"`) {
+		t.Errorf("unexpected HTML part %q", bodies[1])
+	}
+}
+
+func TestBuildMessage_SinglePart(t *testing.T) {
+	raw, err := buildMessage("noreply@example.com", "noreply@example.com", Message{
+		To:      []string{"athlete@example.com"},
+		Subject: "Plain",
+		Body:    "Hello, world",
+	}, time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	parsed, err := mail.ReadMessage(strings.NewReader(string(raw)))
+	if err != nil {
+		t.Fatalf("failed to parse message: %v", err)
+	}
+	if ct := parsed.Header.Get("Content-Type"); ct != "text/plain; charset=UTF-8" {
+		t.Errorf("unexpected Content-Type %q", ct)
+	}
+	if cte := parsed.Header.Get("Content-Transfer-Encoding"); cte != "quoted-printable" {
+		t.Errorf("unexpected Content-Transfer-Encoding %q", cte)
+	}
+}
@@ -18,10 +18,34 @@ const (
 	UserEmailKey ContextKey = "userEmail"
 	// UserRoleKey is the context key for user role
 	UserRoleKey ContextKey = "userRole"
+	// APITokenScopesKey is the context key for the scopes of a personal API token.
+	// It is only set when the request was authenticated with an API token.
+	APITokenScopesKey ContextKey = "apiTokenScopes"
 )
 
-// Auth is a middleware that validates JWT tokens
-func Auth(jwtSecret string) func(http.Handler) http.Handler {
+// APITokenValidator validates personal API tokens
+type APITokenValidator interface {
+	ValidateAPIToken(ctx context.Context, token string) (*auth.APITokenClaims, error)
+}
+
+// AccountChecker reports whether a user's account may still be used, and
+// its current role. Access tokens stay valid until they expire, so Auth asks
+// it on every request to shut out disabled and deleted accounts straight
+// away and to apply role changes from the next request on.
+type AccountChecker interface {
+	AccountStatus(ctx context.Context, userID int64) (active bool, role string, err error)
+}
+
+// Auth is a middleware that validates JWT tokens. accounts may be nil, in
+// which case any valid token is accepted with the role it was issued with.
+func Auth(keys *auth.KeySet, accounts AccountChecker) func(http.Handler) http.Handler {
+	return AuthWithAPITokens(keys, accounts, nil)
+}
+
+// AuthWithAPITokens is like Auth but also accepts personal API tokens.
+// Routes behind it should use RequireScope to limit what API tokens can do.
+// The API token validator is responsible for refusing disabled accounts.
+func AuthWithAPITokens(keys *auth.KeySet, accounts AccountChecker, apiTokens APITokenValidator) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Get token from Authorization header
@@ -40,17 +64,47 @@ func Auth(jwtSecret string) func(http.Handler) http.Handler {
 
 			tokenString := parts[1]
 
+			if apiTokens != nil && auth.IsAPIToken(tokenString) {
+				claims, err := apiTokens.ValidateAPIToken(r.Context(), tokenString)
+				if err != nil {
+					http.Error(w, `{"message":"Invalid or expired token"}`, http.StatusUnauthorized)
+					return
+				}
+
+				ctx := context.WithValue(r.Context(), UserIDKey, claims.UserID)
+				ctx = context.WithValue(ctx, UserEmailKey, claims.Email)
+				ctx = context.WithValue(ctx, UserRoleKey, claims.Role)
+				ctx = context.WithValue(ctx, APITokenScopesKey, claims.Scopes)
+
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+
 			// Validate token
-			claims, err := auth.ValidateToken(tokenString, jwtSecret)
+			claims, err := auth.ValidateToken(tokenString, keys)
 			if err != nil {
 				http.Error(w, `{"message":"Invalid or expired token"}`, http.StatusUnauthorized)
 				return
 			}
 
+			role := claims.Role
+			if accounts != nil {
+				var active bool
+				active, role, err = accounts.AccountStatus(r.Context(), claims.UserID)
+				if err != nil {
+					http.Error(w, `{"message":"Failed to check account"}`, http.StatusInternalServerError)
+					return
+				}
+				if !active {
+					http.Error(w, `{"message":"Account is disabled"}`, http.StatusUnauthorized)
+					return
+				}
+			}
+
 			// Add user info to context
 			ctx := context.WithValue(r.Context(), UserIDKey, claims.UserID)
 			ctx = context.WithValue(ctx, UserEmailKey, claims.Email)
-			ctx = context.WithValue(ctx, UserRoleKey, claims.Role)
+			ctx = context.WithValue(ctx, UserRoleKey, role)
 
 			// Call next handler with updated context
 			next.ServeHTTP(w, r.WithContext(ctx))
@@ -76,6 +130,38 @@ func GetUserRole(ctx context.Context) (string, bool) {
 	return role, ok
 }
 
+// GetAPITokenScopes extracts the API token scopes from context. ok is false
+// when the request was authenticated with a login session instead.
+func GetAPITokenScopes(ctx context.Context) ([]string, bool) {
+	scopes, ok := ctx.Value(APITokenScopesKey).([]string)
+	return scopes, ok
+}
+
+// RequireScope is a middleware that rejects API tokens without the given scope.
+// Requests authenticated with a login session are not restricted.
+func RequireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			scopes, ok := GetAPITokenScopes(r.Context())
+			if ok {
+				granted := false
+				for _, s := range scopes {
+					if s == scope {
+						granted = true
+						break
+					}
+				}
+				if !granted {
+					http.Error(w, `{"message":"Forbidden: token is missing the `+scope+` scope"}`, http.StatusForbidden)
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 // AdminOnly is a middleware that restricts access to admin users only
 func AdminOnly(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -2,6 +2,9 @@ package middleware
 
 import (
 	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"io"
 	"net/http"
@@ -26,6 +29,11 @@ func (rw *loggingResponseWriter) WriteHeader(code int) {
 	rw.ResponseWriter.WriteHeader(code)
 }
 
+// Unwrap lets http.ResponseController reach the underlying writer
+func (rw *loggingResponseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 func (rw *loggingResponseWriter) Write(b []byte) (int, error) {
 	size, err := rw.ResponseWriter.Write(b)
 	rw.size += size
@@ -168,12 +176,20 @@ func LoggingMiddleware(log *logger.Logger) func(next http.Handler) http.Handler
 	}
 }
 
-// RequestIDMiddleware adds a unique request ID to each request
+// RequestIDKey is the context key for the request ID
+const RequestIDKey ContextKey = "requestID"
+
+// maxRequestIDLength bounds request IDs accepted from the X-Request-ID header
+const maxRequestIDLength = 64
+
+// RequestIDMiddleware adds a unique request ID to each request. A client or
+// proxy supplied X-Request-ID is kept if it is short and printable, so
+// requests can be traced across services. log may be nil.
 func RequestIDMiddleware(log *logger.Logger) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			requestID := r.Header.Get("X-Request-ID")
-			if requestID == "" {
+			if !validRequestID(requestID) {
 				requestID = generateRequestID()
 			}
 
@@ -181,14 +197,39 @@ func RequestIDMiddleware(log *logger.Logger) func(next http.Handler) http.Handle
 			w.Header().Set("X-Request-ID", requestID)
 
 			// Log request ID for correlation
-			log.Debug("request_id=%s %s %s", requestID, r.Method, r.URL.Path)
+			if log != nil {
+				log.Debug("request_id=%s %s %s", requestID, r.Method, r.URL.Path)
+			}
 
-			next.ServeHTTP(w, r)
+			ctx := context.WithValue(r.Context(), RequestIDKey, requestID)
+			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
 
-// generateRequestID generates a simple request ID
+// GetRequestID extracts the request ID from context
+func GetRequestID(ctx context.Context) (string, bool) {
+	requestID, ok := ctx.Value(RequestIDKey).(string)
+	return requestID, ok
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		if c < '!' || c > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// generateRequestID generates a random request ID
 func generateRequestID() string {
-	return time.Now().Format("20060102150405.000000")
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return time.Now().Format("20060102150405.000000")
+	}
+	return hex.EncodeToString(b)
 }
@@ -0,0 +1,192 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/johnzastrow/actalog/pkg/auth"
+)
+
+// Rate is a token bucket: up to Requests can be made at once, and the bucket
+// refills completely over Per
+type Rate struct {
+	Requests int
+	Per      time.Duration
+}
+
+// RateLimitStore holds token buckets. MemoryRateLimitStore keeps them in
+// process; a shared store is needed to limit across several server instances.
+type RateLimitStore interface {
+	// Take removes a token from key's bucket. If the bucket is empty it
+	// returns false and how long until a token will be available.
+	Take(key string, rate Rate, now time.Time) (bool, time.Duration, error)
+}
+
+// RateLimitKeyFunc returns the bucket key for a request, or "" to not limit it.
+// Keys should say what they are (e.g. "ip:" + address) so different key
+// functions sharing a store don't collide.
+type RateLimitKeyFunc func(r *http.Request) string
+
+// maxKeyedBodySize is how much of a request body is read to find a key
+const maxKeyedBodySize = 64 << 10
+
+// RateLimit limits requests to each route with a token bucket per key. A
+// request is rejected with 429 if the bucket for any of its keys is empty. Store errors let the request through rather than locking users out.
+// A rate with no requests disables limiting.
+func RateLimit(store RateLimitStore, rate Rate, keyFuncs ...RateLimitKeyFunc) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if rate.Requests <= 0 || rate.Per <= 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			now := time.Now()
+			for _, keyFunc := range keyFuncs {
+				key := keyFunc(r)
+				if key == "" {
+					continue
+				}
+
+				allowed, retryAfter, err := store.Take(r.URL.Path+"|"+key, rate, now)
+				if err != nil || allowed {
+					continue
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+				w.WriteHeader(http.StatusTooManyRequests)
+				w.Write([]byte(`{"message":"Too many requests, please try again later"}`))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RateLimitByIP keys requests by client IP address
+func RateLimitByIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return "ip:" + r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// RateLimitByJSONField keys requests by a string field of a JSON request body,
+// e.g. "email" to limit attempts against one account from any address. The
+// value is trimmed and lowercased. The body is left intact for the handler.
+func RateLimitByJSONField(field string) RateLimitKeyFunc {
+	return func(r *http.Request) string {
+		value := strings.ToLower(jsonBodyField(r, field))
+		if value == "" {
+			return ""
+		}
+		return field + ":" + value
+	}
+}
+
+// RateLimitByMFAToken keys two-factor login requests by the user the MFA
+// challenge token in a JSON body field was issued to, so fetching a new
+// challenge doesn't reset the limit. Invalid tokens aren't limited here; the
+// handler rejects them.
+func RateLimitByMFAToken(keys *auth.KeySet, field string) RateLimitKeyFunc {
+	return func(r *http.Request) string {
+		token := jsonBodyField(r, field)
+		if token == "" {
+			return ""
+		}
+		claims, err := auth.ValidateMFAToken(token, keys)
+		if err != nil {
+			return ""
+		}
+		return "user:" + strconv.FormatInt(claims.UserID, 10)
+	}
+}
+
+// jsonBodyField returns a trimmed string field of a JSON request body, leaving
+// the body intact for the handler
+func jsonBodyField(r *http.Request, field string) string {
+	if r.Body == nil {
+		return ""
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxKeyedBodySize))
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+	if err != nil {
+		return ""
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return ""
+	}
+	value, _ := fields[field].(string)
+	return strings.TrimSpace(value)
+}
+
+// MemoryRateLimitStore is an in-memory RateLimitStore. Buckets that have
+// refilled are dropped periodically so memory use stays bounded.
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+	per     time.Duration
+}
+
+// memoryStoreSweepInterval is how often full buckets are dropped
+const memoryStoreSweepInterval = time.Minute
+
+// NewMemoryRateLimitStore creates an empty in-memory store
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{buckets: make(map[string]*tokenBucket)}
+}
+
+// Take removes a token from key's bucket
+func (s *MemoryRateLimitStore) Take(key string, rate Rate, now time.Time) (bool, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.lastSweep) >= memoryStoreSweepInterval {
+		for k, b := range s.buckets {
+			if now.Sub(b.updated) >= b.per {
+				delete(s.buckets, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	capacity := float64(rate.Requests)
+	perToken := rate.Per / time.Duration(rate.Requests)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: capacity, updated: now, per: rate.Per}
+		s.buckets[key] = b
+	} else {
+		refilled := float64(now.Sub(b.updated)) / float64(perToken)
+		b.tokens = math.Min(capacity, b.tokens+refilled)
+		b.updated = now
+	}
+
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) * float64(perToken)), nil
+	}
+	b.tokens--
+	return true, 0, nil
+}
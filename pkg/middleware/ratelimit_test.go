@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/johnzastrow/actalog/pkg/auth"
+)
+
+func TestMemoryRateLimitStore_Take(t *testing.T) {
+	store := NewMemoryRateLimitStore()
+	rate := Rate{Requests: 2, Per: time.Minute}
+	now := time.Now()
+
+	for i := 0; i < 2; i++ {
+		if ok, _, _ := store.Take("k", rate, now); !ok {
+			t.Fatalf("expected request %d to be allowed", i+1)
+		}
+	}
+	ok, retryAfter, _ := store.Take("k", rate, now)
+	if ok || retryAfter != 30*time.Second {
+		t.Errorf("expected third request to wait 30s, got %t, %s", ok, retryAfter)
+	}
+	if ok, _, _ := store.Take("other", rate, now); !ok {
+		t.Error("expected other keys to have their own bucket")
+	}
+
+	// One token refills every 30 seconds
+	if ok, _, _ := store.Take("k", rate, now.Add(30*time.Second)); !ok {
+		t.Error("expected a token after 30s")
+	}
+	if ok, _, _ := store.Take("k", rate, now.Add(30*time.Second)); ok {
+		t.Error("expected only one token after 30s")
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	var bodies []string
+	handler := RateLimit(NewMemoryRateLimitStore(), Rate{Requests: 2, Per: time.Minute}, RateLimitByIP, RateLimitByJSONField("email"))(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			bodies = append(bodies, string(body))
+		}))
+
+	login := func(ip, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/auth/login", strings.NewReader(body))
+		req.RemoteAddr = ip + ":1234"
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	tests := []struct {
+		name string
+		ip   string
+		body string
+		want int
+	}{
+		{"first", "10.0.0.1", `{"email":"ana@gym.example","password":"x"}`, http.StatusOK},
+		{"same account, other IP", "10.0.0.2", `{"email":" ANA@gym.example ","password":"x"}`, http.StatusOK},
+		{"account exhausted", "10.0.0.3", `{"email":"ana@gym.example","password":"x"}`, http.StatusTooManyRequests},
+		{"other account, first IP", "10.0.0.1", `{"email":"bo@gym.example","password":"x"}`, http.StatusOK},
+		{"IP exhausted", "10.0.0.1", `{"email":"cy@gym.example","password":"x"}`, http.StatusTooManyRequests},
+		{"no email", "10.0.0.4", `not json`, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := login(tt.ip, tt.body)
+			if w.Code != tt.want {
+				t.Errorf("expected status %d, got %d", tt.want, w.Code)
+			}
+			if w.Code == http.StatusTooManyRequests && w.Header().Get("Retry-After") != "30" {
+				t.Errorf("expected Retry-After 30, got %q", w.Header().Get("Retry-After"))
+			}
+		})
+	}
+
+	if len(bodies) != 4 || bodies[0] != tests[0].body || bodies[3] != "not json" {
+		t.Errorf("expected handlers to receive the full request bodies, got %q", bodies)
+	}
+}
+
+func TestRateLimitByMFAToken(t *testing.T) {
+	keys := auth.NewHMACKeySet("test-secret-key")
+	keyFunc := RateLimitByMFAToken(keys, "mfa_token")
+
+	key := func(body string) string {
+		req := httptest.NewRequest("POST", "/api/auth/login/mfa", strings.NewReader(body))
+		return keyFunc(req)
+	}
+
+	first, _ := auth.GenerateMFAToken(7, "ana@gym.example", "user", keys, time.Minute)
+	second, _ := auth.GenerateMFAToken(7, "ana@gym.example", "user", keys, 2*time.Minute)
+	access, _ := auth.GenerateToken(7, "ana@gym.example", "user", keys, time.Minute)
+
+	if got := key(`{"mfa_token":"` + first + `","code":"123456"}`); got != "user:7" {
+		t.Errorf("expected the challenge's user, got %q", got)
+	}
+	if key(`{"mfa_token":"`+second+`"}`) != key(`{"mfa_token":"`+first+`"}`) {
+		t.Error("expected a new challenge for the same user to share the key")
+	}
+	if got := key(`{"mfa_token":"` + access + `"}`); got != "" {
+		t.Errorf("expected an access token not to be a key, got %q", got)
+	}
+	if got := key(`{"code":"123456"}`); got != "" {
+		t.Errorf("expected no key without a token, got %q", got)
+	}
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRealIP(t *testing.T) {
+	trusted, err := ParseTrustedProxies([]string{"10.0.0.0/8", "192.168.1.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		trusted    bool
+		remoteAddr string
+		forwarded  []string
+		realIP     string
+		want       string
+	}{
+		{name: "direct client", trusted: true, remoteAddr: "203.0.113.7:5000", forwarded: []string{"198.51.100.1"}, want: "ip:203.0.113.7"},
+		{name: "trusted proxy", trusted: true, remoteAddr: "10.0.0.2:5000", forwarded: []string{"198.51.100.1"}, want: "ip:198.51.100.1"},
+		{name: "spoofed entries left of the client", trusted: true, remoteAddr: "10.0.0.2:5000", forwarded: []string{"1.1.1.1, 198.51.100.1"}, want: "ip:198.51.100.1"},
+		{name: "chain of trusted proxies", trusted: true, remoteAddr: "10.0.0.2:5000", forwarded: []string{"198.51.100.1, 192.168.1.1", "10.0.0.9"}, want: "ip:198.51.100.1"},
+		{name: "X-Real-IP", trusted: true, remoteAddr: "192.168.1.1:5000", realIP: "198.51.100.2", want: "ip:198.51.100.2"},
+		{name: "malformed header", trusted: true, remoteAddr: "10.0.0.2:5000", forwarded: []string{"not-an-ip"}, want: "ip:10.0.0.2"},
+		{name: "no trusted proxies configured", remoteAddr: "10.0.0.2:5000", forwarded: []string{"198.51.100.1"}, want: "ip:10.0.0.2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proxies := trusted
+			if !tt.trusted {
+				proxies = nil
+			}
+			var got string
+			handler := RealIP(proxies)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = RateLimitByIP(r)
+			}))
+
+			req := httptest.NewRequest("POST", "/api/auth/login", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for _, value := range tt.forwarded {
+				req.Header.Add("X-Forwarded-For", value)
+			}
+			if tt.realIP != "" {
+				req.Header.Set("X-Real-IP", tt.realIP)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			if got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+
+	if _, err := ParseTrustedProxies([]string{"10.0.0.0/33"}); err == nil {
+		t.Error("expected an invalid range to be rejected")
+	}
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Timeout gives each request a deadline. Database queries run with the
+// request's context are canceled once it passes, and handlers report them as
+// failed, so one slow query can't hold a connection indefinitely. A timeout
+// of zero or less disables the deadline.
+func Timeout(timeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if timeout <= 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(context.WithValue(r.Context(), untimedContextKey, r.Context()), timeout)
+			defer cancel()
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// WithoutTimeout lifts the deadline set by Timeout, and the server's write
+// deadline, for long-running routes such as backups. The request is still
+// canceled if the client goes away.
+func WithoutTimeout(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+		ctx := r.Context()
+		if parent, ok := ctx.Value(untimedContextKey).(context.Context); ok {
+			ctx = untimedContext{Context: ctx, parent: parent}
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// untimedContextKey holds the request's context from before Timeout
+const untimedContextKey ContextKey = "untimedContext"
+
+// untimedContext keeps a request's values but takes its cancellation from
+// the context it had before Timeout
+type untimedContext struct {
+	context.Context
+	parent context.Context
+}
+
+func (c untimedContext) Deadline() (time.Time, bool) { return c.parent.Deadline() }
+func (c untimedContext) Done() <-chan struct{}       { return c.parent.Done() }
+func (c untimedContext) Err() error                  { return c.parent.Err() }
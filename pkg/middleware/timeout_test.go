@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTimeout(t *testing.T) {
+	var err error
+	slow := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+			err = r.Context().Err()
+		case <-time.After(time.Second):
+			err = nil
+		}
+	})
+
+	Timeout(10*time.Millisecond)(slow).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/api/workouts", nil))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the request's context to pass its deadline, got %v", err)
+	}
+
+	var hasDeadline bool
+	check := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, hasDeadline = r.Context().Deadline()
+	})
+	Timeout(0)(check).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/api/workouts", nil))
+	if hasDeadline {
+		t.Error("expected a zero timeout not to set a deadline")
+	}
+
+	// Routes without the timeout keep the request's values and still end
+	// when the client goes away
+	var values, canceled bool
+	client, cancel := context.WithCancel(context.Background())
+	untimed := Timeout(10 * time.Millisecond)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), UserIDKey, int64(1))
+		WithoutTimeout(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, hasDeadline = r.Context().Deadline()
+			_, values = GetUserID(r.Context())
+			time.Sleep(30 * time.Millisecond)
+			if r.Context().Err() != nil {
+				return
+			}
+			cancel()
+			<-r.Context().Done()
+			canceled = true
+		})).ServeHTTP(w, r.WithContext(ctx))
+	}))
+	untimed.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/api/admin/backups", nil).WithContext(client))
+	if hasDeadline || !values || !canceled {
+		t.Errorf("expected no deadline (got %v), the request's values (got %v) and cancellation by the client (got %v)", hasDeadline, values, canceled)
+	}
+}
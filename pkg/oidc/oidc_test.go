@@ -0,0 +1,94 @@
+package oidc_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/johnzastrow/actalog/internal/testhelpers"
+	"github.com/johnzastrow/actalog/pkg/oidc"
+)
+
+func TestAuthorizationCodeFlow(t *testing.T) {
+	mock, err := testhelpers.NewMockOIDCProvider("actalog", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+	mock.SetUser(testhelpers.MockOIDCUser{Subject: "123", Email: "ana@gym.example", EmailVerified: true, Name: "Ana"})
+
+	provider := oidc.NewProvider(oidc.Config{
+		IssuerURL:    mock.URL,
+		ClientID:     "actalog",
+		ClientSecret: "secret",
+		RedirectURL:  "http://localhost:3000/auth/oidc/callback",
+	}, nil)
+	ctx := context.Background()
+
+	verifier, _ := oidc.RandomString()
+	authURL, err := provider.AuthCodeURL(ctx, "state-1", "nonce-1", verifier)
+	if err != nil {
+		t.Fatalf("failed to build auth URL: %v", err)
+	}
+	parsed, _ := url.Parse(authURL)
+	if !strings.HasPrefix(authURL, mock.URL+"/authorize?") || parsed.Query().Get("code_challenge") != oidc.PKCEChallenge(verifier) {
+		t.Errorf("unexpected auth URL %s", authURL)
+	}
+
+	code, state, err := mock.Authorize(authURL)
+	if err != nil || state != "state-1" {
+		t.Fatalf("authorize failed: state=%s err=%v", state, err)
+	}
+
+	// The wrong PKCE verifier is rejected by the provider
+	if _, err := provider.Exchange(ctx, code, "wrong-verifier"); !errors.Is(err, oidc.ErrExchange) {
+		t.Errorf("expected ErrExchange for a bad verifier, got %v", err)
+	}
+
+	code, _, _ = mock.Authorize(authURL)
+	tokens, err := provider.Exchange(ctx, code, verifier)
+	if err != nil {
+		t.Fatalf("exchange failed: %v", err)
+	}
+
+	if _, err := provider.VerifyIDToken(ctx, tokens.IDToken, "other-nonce"); !errors.Is(err, oidc.ErrNonceMismatch) {
+		t.Errorf("expected ErrNonceMismatch, got %v", err)
+	}
+
+	claims, err := provider.VerifyIDToken(ctx, tokens.IDToken, "nonce-1")
+	if err != nil {
+		t.Fatalf("failed to verify ID token: %v", err)
+	}
+	if claims.Subject != "123" || claims.Email != "ana@gym.example" || !claims.EmailVerified || claims.Issuer != mock.URL {
+		t.Errorf("unexpected claims %+v", claims)
+	}
+
+	// A token for another client is rejected
+	other := oidc.NewProvider(oidc.Config{IssuerURL: mock.URL, ClientID: "someone-else"}, nil)
+	if _, err := other.VerifyIDToken(ctx, tokens.IDToken, "nonce-1"); !errors.Is(err, oidc.ErrInvalidIDToken) {
+		t.Errorf("expected ErrInvalidIDToken for the wrong audience, got %v", err)
+	}
+
+	// Tampering breaks the signature
+	parts := strings.Split(tokens.IDToken, ".")
+	tampered := parts[0] + "." + parts[1] + "x." + parts[2]
+	if _, err := provider.VerifyIDToken(ctx, tampered, "nonce-1"); !errors.Is(err, oidc.ErrInvalidIDToken) {
+		t.Errorf("expected ErrInvalidIDToken for a tampered token, got %v", err)
+	}
+}
+
+func TestDiscoveryIssuerMismatch(t *testing.T) {
+	mock, err := testhelpers.NewMockOIDCProvider("actalog", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	// Reaching the provider under a different name than it reports is refused
+	provider := oidc.NewProvider(oidc.Config{IssuerURL: strings.Replace(mock.URL, "127.0.0.1", "localhost", 1), ClientID: "actalog"}, nil)
+	if _, err := provider.AuthCodeURL(context.Background(), "s", "n", "v"); !errors.Is(err, oidc.ErrIssuerMismatch) {
+		t.Errorf("expected ErrIssuerMismatch, got %v", err)
+	}
+}
@@ -0,0 +1,157 @@
+package webauthn
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+var errMalformedCBOR = errors.New("malformed CBOR")
+
+// maxCBORDepth bounds nesting so hostile input can't exhaust the stack
+const maxCBORDepth = 16
+
+// decodeCBOR decodes the first CBOR data item in data and returns it with the
+// bytes that follow it. It supports the subset authenticators emit: integers
+// (int64), byte strings ([]byte), text strings, arrays ([]interface{}), maps
+// (map[interface{}]interface{} keyed by int64 or string), booleans and null.
+// Tags are skipped; indefinite lengths and floats are rejected.
+func decodeCBOR(data []byte) (interface{}, []byte, error) {
+	return decodeCBORItem(data, 0)
+}
+
+func decodeCBORItem(data []byte, depth int) (interface{}, []byte, error) {
+	if depth > maxCBORDepth || len(data) == 0 {
+		return nil, nil, errMalformedCBOR
+	}
+
+	major := data[0] >> 5
+	info := data[0] & 0x1f
+	data = data[1:]
+
+	if major == 7 {
+		switch info {
+		case 20:
+			return false, data, nil
+		case 21:
+			return true, data, nil
+		case 22, 23:
+			return nil, data, nil
+		}
+		return nil, nil, errMalformedCBOR
+	}
+
+	n, data, err := readCBORArgument(info, data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch major {
+	case 0:
+		if n > math.MaxInt64 {
+			return nil, nil, errMalformedCBOR
+		}
+		return int64(n), data, nil
+
+	case 1:
+		if n > math.MaxInt64 {
+			return nil, nil, errMalformedCBOR
+		}
+		return -1 - int64(n), data, nil
+
+	case 2, 3:
+		if n > uint64(len(data)) {
+			return nil, nil, errMalformedCBOR
+		}
+		if major == 3 {
+			return string(data[:n]), data[n:], nil
+		}
+		value := make([]byte, n)
+		copy(value, data[:n])
+		return value, data[n:], nil
+
+	case 4:
+		// Every item takes at least one byte
+		if n > uint64(len(data)) {
+			return nil, nil, errMalformedCBOR
+		}
+		items := make([]interface{}, 0, n)
+		for i := uint64(0); i < n; i++ {
+			var item interface{}
+			item, data, err = decodeCBORItem(data, depth+1)
+			if err != nil {
+				return nil, nil, err
+			}
+			items = append(items, item)
+		}
+		return items, data, nil
+
+	case 5:
+		if n > uint64(len(data))/2 {
+			return nil, nil, errMalformedCBOR
+		}
+		m := make(map[interface{}]interface{}, n)
+		for i := uint64(0); i < n; i++ {
+			var key, value interface{}
+			key, data, err = decodeCBORItem(data, depth+1)
+			if err != nil {
+				return nil, nil, err
+			}
+			switch key.(type) {
+			case int64, string:
+			default:
+				return nil, nil, errMalformedCBOR
+			}
+			if _, dup := m[key]; dup {
+				return nil, nil, errMalformedCBOR
+			}
+			value, data, err = decodeCBORItem(data, depth+1)
+			if err != nil {
+				return nil, nil, err
+			}
+			m[key] = value
+		}
+		return m, data, nil
+
+	case 6:
+		return decodeCBORItem(data, depth+1)
+	}
+
+	return nil, nil, errMalformedCBOR
+}
+
+// readCBORArgument reads the length or value that follows an initial byte
+func readCBORArgument(info byte, data []byte) (uint64, []byte, error) {
+	var size int
+	switch {
+	case info < 24:
+		return uint64(info), data, nil
+	case info == 24:
+		size = 1
+	case info == 25:
+		size = 2
+	case info == 26:
+		size = 4
+	case info == 27:
+		size = 8
+	default:
+		return 0, nil, errMalformedCBOR
+	}
+
+	if len(data) < size {
+		return 0, nil, errMalformedCBOR
+	}
+
+	var n uint64
+	switch size {
+	case 1:
+		n = uint64(data[0])
+	case 2:
+		n = uint64(binary.BigEndian.Uint16(data))
+	case 4:
+		n = uint64(binary.BigEndian.Uint32(data))
+	case 8:
+		n = binary.BigEndian.Uint64(data)
+	}
+	return n, data[size:], nil
+}
@@ -0,0 +1,117 @@
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"math/big"
+)
+
+// COSE algorithm identifiers for the supported credential key types
+const (
+	AlgES256 int64 = -7   // ECDSA P-256 with SHA-256
+	AlgEdDSA int64 = -8   // Ed25519
+	AlgRS256 int64 = -257 // RSASSA-PKCS1-v1_5 with SHA-256
+)
+
+// SupportedAlgorithms lists accepted algorithms in order of preference
+var SupportedAlgorithms = []int64{AlgES256, AlgEdDSA, AlgRS256}
+
+// COSE key parameters (RFC 9053)
+const (
+	coseKeyType  int64 = 1
+	coseKeyAlg   int64 = 3
+	coseCurve    int64 = -1 // Also RSA modulus n
+	coseX        int64 = -2 // Also RSA exponent e
+	coseY        int64 = -3
+	coseKTYOKP   int64 = 1
+	coseKTYEC2   int64 = 2
+	coseKTYRSA   int64 = 3
+	coseCrvP256  int64 = 1
+	coseCrvEd255 int64 = 6
+)
+
+const minRSAKeyBits = 2048
+
+// publicKey is a parsed COSE credential public key
+type publicKey struct {
+	alg int64
+	key crypto.PublicKey
+}
+
+// parsePublicKey parses a COSE_Key into a public key for a supported algorithm
+func parsePublicKey(coseKey []byte) (*publicKey, error) {
+	item, rest, err := decodeCBOR(coseKey)
+	if err != nil || len(rest) != 0 {
+		return nil, ErrUnsupportedKey
+	}
+	m, ok := item.(map[interface{}]interface{})
+	if !ok {
+		return nil, ErrUnsupportedKey
+	}
+
+	kty, _ := m[coseKeyType].(int64)
+	alg, _ := m[coseKeyAlg].(int64)
+
+	switch {
+	case kty == coseKTYEC2 && alg == AlgES256:
+		crv, _ := m[coseCurve].(int64)
+		x, _ := m[coseX].([]byte)
+		y, _ := m[coseY].([]byte)
+		if crv != coseCrvP256 || len(x) != 32 || len(y) != 32 {
+			return nil, ErrUnsupportedKey
+		}
+		// Reject points that aren't on the curve
+		point := append(append([]byte{4}, x...), y...)
+		if _, err := ecdh.P256().NewPublicKey(point); err != nil {
+			return nil, ErrUnsupportedKey
+		}
+		return &publicKey{alg: alg, key: &ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}}, nil
+
+	case kty == coseKTYOKP && alg == AlgEdDSA:
+		crv, _ := m[coseCurve].(int64)
+		x, _ := m[coseX].([]byte)
+		if crv != coseCrvEd255 || len(x) != ed25519.PublicKeySize {
+			return nil, ErrUnsupportedKey
+		}
+		return &publicKey{alg: alg, key: ed25519.PublicKey(x)}, nil
+
+	case kty == coseKTYRSA && alg == AlgRS256:
+		n, _ := m[coseCurve].([]byte)
+		e, _ := m[coseX].([]byte)
+		if len(e) == 0 || len(e) > 4 {
+			return nil, ErrUnsupportedKey
+		}
+		modulus := new(big.Int).SetBytes(n)
+		exponent := int(new(big.Int).SetBytes(e).Int64())
+		if modulus.BitLen() < minRSAKeyBits || exponent < 3 || exponent%2 == 0 {
+			return nil, ErrUnsupportedKey
+		}
+		return &publicKey{alg: alg, key: &rsa.PublicKey{N: modulus, E: exponent}}, nil
+	}
+
+	return nil, ErrUnsupportedKey
+}
+
+// verify checks sig over data
+func (k *publicKey) verify(data, sig []byte) bool {
+	switch key := k.key.(type) {
+	case *ecdsa.PublicKey:
+		digest := sha256.Sum256(data)
+		return ecdsa.VerifyASN1(key, digest[:], sig)
+	case ed25519.PublicKey:
+		return ed25519.Verify(key, data, sig)
+	case *rsa.PublicKey:
+		digest := sha256.Sum256(data)
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], sig) == nil
+	}
+	return false
+}
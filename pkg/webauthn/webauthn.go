@@ -0,0 +1,415 @@
+// Package webauthn implements the relying party side of WebAuthn (passkey)
+// registration and authentication.
+//
+// Attestation statements are not verified: registration requests "none"
+// attestation, so the server trusts the authenticator's public key but not its
+// make or model. Options and responses use the JSON forms produced by
+// PublicKeyCredential.parseCreationOptionsFromJSON and toJSON in browsers.
+package webauthn
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+var (
+	ErrInvalidResponse      = errors.New("malformed WebAuthn response")
+	ErrChallengeMismatch    = errors.New("WebAuthn challenge does not match")
+	ErrOriginMismatch       = errors.New("WebAuthn origin is not allowed")
+	ErrRPIDMismatch         = errors.New("WebAuthn relying party ID does not match")
+	ErrUserNotPresent       = errors.New("authenticator did not confirm user presence")
+	ErrUserNotVerified      = errors.New("authenticator did not verify the user")
+	ErrUnsupportedKey       = errors.New("unsupported credential public key")
+	ErrInvalidSignature     = errors.New("invalid WebAuthn signature")
+	ErrSignCountNotIncrease = errors.New("authenticator signature counter did not increase; it may be cloned")
+)
+
+// Client data types
+const (
+	typeCreate = "webauthn.create"
+	typeGet    = "webauthn.get"
+)
+
+// Authenticator data flags
+const (
+	flagUserPresent  byte = 0x01
+	flagUserVerified byte = 0x04
+	flagAttestedData byte = 0x40
+	flagExtensions   byte = 0x80
+)
+
+const (
+	challengeBytes        = 32
+	maxCredentialIDLength = 1023
+	credentialType        = "public-key"
+)
+
+// User verification requirements
+const (
+	UserVerificationRequired  = "required"
+	UserVerificationPreferred = "preferred"
+)
+
+// RelyingParty identifies this server to authenticators
+type RelyingParty struct {
+	ID      string   // Effective domain, e.g. "actalog.example.com"
+	Name    string   // Shown by some authenticators
+	Origins []string // Accepted origins, e.g. "https://actalog.example.com"
+}
+
+// Credential is a newly registered public key credential
+type Credential struct {
+	ID           []byte
+	PublicKey    []byte // COSE_Key
+	SignCount    uint32
+	UserVerified bool
+}
+
+// Assertion is the result of a successful authentication
+type Assertion struct {
+	SignCount    uint32
+	UserVerified bool
+}
+
+// RelyingPartyEntity is the "rp" member of creation options
+type RelyingPartyEntity struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// UserEntity is the "user" member of creation options
+type UserEntity struct {
+	ID          string `json:"id"` // base64url user handle
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+// CredentialParameter names an accepted credential algorithm
+type CredentialParameter struct {
+	Type string `json:"type"`
+	Alg  int64  `json:"alg"`
+}
+
+// CredentialDescriptor identifies an existing credential
+type CredentialDescriptor struct {
+	Type       string   `json:"type"`
+	ID         string   `json:"id"` // base64url credential ID
+	Transports []string `json:"transports,omitempty"`
+}
+
+// AuthenticatorSelection states requirements for the authenticator
+type AuthenticatorSelection struct {
+	ResidentKey      string `json:"residentKey"`
+	UserVerification string `json:"userVerification"`
+}
+
+// CreationOptions are passed to navigator.credentials.create()
+type CreationOptions struct {
+	Challenge              string                 `json:"challenge"`
+	RP                     RelyingPartyEntity     `json:"rp"`
+	User                   UserEntity             `json:"user"`
+	PubKeyCredParams       []CredentialParameter  `json:"pubKeyCredParams"`
+	Timeout                int64                  `json:"timeout"` // Milliseconds
+	ExcludeCredentials     []CredentialDescriptor `json:"excludeCredentials"`
+	AuthenticatorSelection AuthenticatorSelection `json:"authenticatorSelection"`
+	Attestation            string                 `json:"attestation"`
+}
+
+// RequestOptions are passed to navigator.credentials.get()
+type RequestOptions struct {
+	Challenge        string                 `json:"challenge"`
+	Timeout          int64                  `json:"timeout"` // Milliseconds
+	RPID             string                 `json:"rpId"`
+	AllowCredentials []CredentialDescriptor `json:"allowCredentials"`
+	UserVerification string                 `json:"userVerification"`
+}
+
+// RegistrationResponse is the JSON form of the credential returned by
+// navigator.credentials.create()
+type RegistrationResponse struct {
+	ID       string `json:"id"`
+	RawID    string `json:"rawId"`
+	Type     string `json:"type"`
+	Response struct {
+		ClientDataJSON    string   `json:"clientDataJSON"`
+		AttestationObject string   `json:"attestationObject"`
+		Transports        []string `json:"transports,omitempty"`
+	} `json:"response"`
+}
+
+// AuthenticationResponse is the JSON form of the credential returned by
+// navigator.credentials.get()
+type AuthenticationResponse struct {
+	ID       string `json:"id"`
+	RawID    string `json:"rawId"`
+	Type     string `json:"type"`
+	Response struct {
+		ClientDataJSON    string `json:"clientDataJSON"`
+		AuthenticatorData string `json:"authenticatorData"`
+		Signature         string `json:"signature"`
+		UserHandle        string `json:"userHandle,omitempty"`
+	} `json:"response"`
+}
+
+// NewChallenge returns a random base64url challenge
+func NewChallenge() (string, error) {
+	b := make([]byte, challengeBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return EncodeBase64URL(b), nil
+}
+
+// EncodeBase64URL encodes b as unpadded base64url, as WebAuthn JSON does
+func EncodeBase64URL(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeBase64URL decodes base64url with or without padding
+func DecodeBase64URL(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
+
+// Challenge returns the challenge the client signed, for looking up the
+// server-side copy. It is not verified until VerifyRegistration.
+func (resp *RegistrationResponse) Challenge() (string, error) {
+	return clientDataChallenge(resp.Response.ClientDataJSON)
+}
+
+// Challenge returns the challenge the client signed, for looking up the
+// server-side copy. It is not verified until VerifyAssertion.
+func (resp *AuthenticationResponse) Challenge() (string, error) {
+	return clientDataChallenge(resp.Response.ClientDataJSON)
+}
+
+func clientDataChallenge(encoded string) (string, error) {
+	raw, err := DecodeBase64URL(encoded)
+	if err != nil {
+		return "", ErrInvalidResponse
+	}
+	var cd clientData
+	if err := json.Unmarshal(raw, &cd); err != nil || cd.Challenge == "" {
+		return "", ErrInvalidResponse
+	}
+	return strings.TrimRight(cd.Challenge, "="), nil
+}
+
+// VerifyRegistration checks a response to CreationOptions with the given
+// challenge and returns the new credential
+func (rp *RelyingParty) VerifyRegistration(challenge string, resp *RegistrationResponse, requireUV bool) (*Credential, error) {
+	if resp.Type != credentialType {
+		return nil, ErrInvalidResponse
+	}
+
+	clientDataJSON, err := DecodeBase64URL(resp.Response.ClientDataJSON)
+	if err != nil {
+		return nil, ErrInvalidResponse
+	}
+	if err := rp.verifyClientData(clientDataJSON, typeCreate, challenge); err != nil {
+		return nil, err
+	}
+
+	attestationObject, err := DecodeBase64URL(resp.Response.AttestationObject)
+	if err != nil {
+		return nil, ErrInvalidResponse
+	}
+	item, rest, err := decodeCBOR(attestationObject)
+	if err != nil || len(rest) != 0 {
+		return nil, ErrInvalidResponse
+	}
+	attestation, ok := item.(map[interface{}]interface{})
+	if !ok {
+		return nil, ErrInvalidResponse
+	}
+	rawAuthData, ok := attestation["authData"].([]byte)
+	if !ok {
+		return nil, ErrInvalidResponse
+	}
+
+	authData, err := parseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return nil, err
+	}
+	if err := rp.verifyAuthenticatorData(authData, requireUV); err != nil {
+		return nil, err
+	}
+	if authData.credentialID == nil {
+		return nil, ErrInvalidResponse
+	}
+
+	rawID, err := DecodeBase64URL(resp.RawID)
+	if err != nil || !bytes.Equal(rawID, authData.credentialID) {
+		return nil, ErrInvalidResponse
+	}
+
+	if _, err := parsePublicKey(authData.publicKey); err != nil {
+		return nil, err
+	}
+
+	return &Credential{
+		ID:           authData.credentialID,
+		PublicKey:    authData.publicKey,
+		SignCount:    authData.signCount,
+		UserVerified: authData.flags&flagUserVerified != 0,
+	}, nil
+}
+
+// VerifyAssertion checks a response to RequestOptions with the given challenge
+// against a stored credential's public key and signature counter
+func (rp *RelyingParty) VerifyAssertion(challenge string, resp *AuthenticationResponse, coseKey []byte, storedSignCount uint32, requireUV bool) (*Assertion, error) {
+	if resp.Type != credentialType {
+		return nil, ErrInvalidResponse
+	}
+
+	clientDataJSON, err := DecodeBase64URL(resp.Response.ClientDataJSON)
+	if err != nil {
+		return nil, ErrInvalidResponse
+	}
+	if err := rp.verifyClientData(clientDataJSON, typeGet, challenge); err != nil {
+		return nil, err
+	}
+
+	rawAuthData, err := DecodeBase64URL(resp.Response.AuthenticatorData)
+	if err != nil {
+		return nil, ErrInvalidResponse
+	}
+	authData, err := parseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return nil, err
+	}
+	if err := rp.verifyAuthenticatorData(authData, requireUV); err != nil {
+		return nil, err
+	}
+
+	signature, err := DecodeBase64URL(resp.Response.Signature)
+	if err != nil {
+		return nil, ErrInvalidResponse
+	}
+	key, err := parsePublicKey(coseKey)
+	if err != nil {
+		return nil, err
+	}
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	signed := append(append([]byte{}, rawAuthData...), clientDataHash[:]...)
+	if !key.verify(signed, signature) {
+		return nil, ErrInvalidSignature
+	}
+
+	// Authenticators that don't keep a counter (most synced passkeys) always send 0
+	if (authData.signCount != 0 || storedSignCount != 0) && authData.signCount <= storedSignCount {
+		return nil, ErrSignCountNotIncrease
+	}
+
+	return &Assertion{
+		SignCount:    authData.signCount,
+		UserVerified: authData.flags&flagUserVerified != 0,
+	}, nil
+}
+
+// clientData is the subset of CollectedClientData that is checked
+type clientData struct {
+	Type        string `json:"type"`
+	Challenge   string `json:"challenge"`
+	Origin      string `json:"origin"`
+	CrossOrigin bool   `json:"crossOrigin"`
+}
+
+func (rp *RelyingParty) verifyClientData(raw []byte, wantType, challenge string) error {
+	var cd clientData
+	if err := json.Unmarshal(raw, &cd); err != nil {
+		return ErrInvalidResponse
+	}
+	if cd.Type != wantType {
+		return ErrInvalidResponse
+	}
+	if challenge == "" || subtle.ConstantTimeCompare([]byte(strings.TrimRight(cd.Challenge, "=")), []byte(challenge)) != 1 {
+		return ErrChallengeMismatch
+	}
+	if cd.CrossOrigin {
+		return ErrOriginMismatch
+	}
+	for _, origin := range rp.Origins {
+		if cd.Origin == origin {
+			return nil
+		}
+	}
+	return ErrOriginMismatch
+}
+
+// authenticatorData is parsed authenticator data; credentialID and publicKey
+// are only set during registration
+type authenticatorData struct {
+	rpIDHash     []byte
+	flags        byte
+	signCount    uint32
+	credentialID []byte
+	publicKey    []byte
+}
+
+func parseAuthenticatorData(data []byte) (*authenticatorData, error) {
+	if len(data) < 37 {
+		return nil, ErrInvalidResponse
+	}
+
+	ad := &authenticatorData{
+		rpIDHash:  data[:32],
+		flags:     data[32],
+		signCount: binary.BigEndian.Uint32(data[33:37]),
+	}
+	rest := data[37:]
+
+	if ad.flags&flagAttestedData != 0 {
+		// AAGUID (16 bytes), credential ID length (2 bytes), credential ID, COSE key
+		if len(rest) < 18 {
+			return nil, ErrInvalidResponse
+		}
+		idLen := int(binary.BigEndian.Uint16(rest[16:18]))
+		rest = rest[18:]
+		if idLen == 0 || idLen > maxCredentialIDLength || len(rest) < idLen {
+			return nil, ErrInvalidResponse
+		}
+		ad.credentialID = append([]byte{}, rest[:idLen]...)
+		rest = rest[idLen:]
+
+		_, after, err := decodeCBOR(rest)
+		if err != nil {
+			return nil, ErrInvalidResponse
+		}
+		ad.publicKey = append([]byte{}, rest[:len(rest)-len(after)]...)
+		rest = after
+	}
+
+	if ad.flags&flagExtensions != 0 {
+		_, after, err := decodeCBOR(rest)
+		if err != nil {
+			return nil, ErrInvalidResponse
+		}
+		rest = after
+	}
+	if len(rest) != 0 {
+		return nil, ErrInvalidResponse
+	}
+
+	return ad, nil
+}
+
+func (rp *RelyingParty) verifyAuthenticatorData(ad *authenticatorData, requireUV bool) error {
+	rpIDHash := sha256.Sum256([]byte(rp.ID))
+	if subtle.ConstantTimeCompare(ad.rpIDHash, rpIDHash[:]) != 1 {
+		return ErrRPIDMismatch
+	}
+	if ad.flags&flagUserPresent == 0 {
+		return ErrUserNotPresent
+	}
+	if requireUV && ad.flags&flagUserVerified == 0 {
+		return ErrUserNotVerified
+	}
+	return nil
+}
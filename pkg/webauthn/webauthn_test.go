@@ -0,0 +1,139 @@
+package webauthn_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/johnzastrow/actalog/internal/testhelpers"
+	"github.com/johnzastrow/actalog/pkg/webauthn"
+)
+
+var rp = &webauthn.RelyingParty{ID: "localhost", Name: "ActaLog", Origins: []string{"http://localhost:8080"}}
+
+func creationOptions(t *testing.T) *webauthn.CreationOptions {
+	t.Helper()
+	challenge, err := webauthn.NewChallenge()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &webauthn.CreationOptions{
+		Challenge: challenge,
+		RP:        webauthn.RelyingPartyEntity{ID: rp.ID, Name: rp.Name},
+		User:      webauthn.UserEntity{ID: "AQ", Name: "ana@example.com", DisplayName: "Ana"},
+	}
+}
+
+func requestOptions(t *testing.T) *webauthn.RequestOptions {
+	t.Helper()
+	challenge, err := webauthn.NewChallenge()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &webauthn.RequestOptions{Challenge: challenge, RPID: rp.ID}
+}
+
+func TestRegistrationAndAssertion(t *testing.T) {
+	authenticator := testhelpers.NewSoftwareAuthenticator("http://localhost:8080")
+
+	options := creationOptions(t)
+	resp, err := authenticator.Register(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cred, err := rp.VerifyRegistration(options.Challenge, resp, true)
+	if err != nil {
+		t.Fatalf("expected registration to verify, got %v", err)
+	}
+	if webauthn.EncodeBase64URL(cred.ID) != resp.ID || !cred.UserVerified {
+		t.Errorf("unexpected credential %+v", cred)
+	}
+
+	login := requestOptions(t)
+	assertion, err := authenticator.Login(login)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := rp.VerifyAssertion(login.Challenge, assertion, cred.PublicKey, cred.SignCount, true)
+	if err != nil {
+		t.Fatalf("expected assertion to verify, got %v", err)
+	}
+	if result.SignCount != 1 {
+		t.Errorf("expected sign count 1, got %d", result.SignCount)
+	}
+
+	// Replaying the same assertion fails the counter check
+	if _, err := rp.VerifyAssertion(login.Challenge, assertion, cred.PublicKey, result.SignCount, true); !errors.Is(err, webauthn.ErrSignCountNotIncrease) {
+		t.Errorf("expected ErrSignCountNotIncrease, got %v", err)
+	}
+}
+
+func TestVerifyRegistration_Rejections(t *testing.T) {
+	authenticator := testhelpers.NewSoftwareAuthenticator("http://localhost:8080")
+	options := creationOptions(t)
+	resp, err := authenticator.Register(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := rp.VerifyRegistration("other-challenge", resp, false); !errors.Is(err, webauthn.ErrChallengeMismatch) {
+		t.Errorf("expected ErrChallengeMismatch, got %v", err)
+	}
+
+	otherRP := &webauthn.RelyingParty{ID: "example.com", Origins: rp.Origins}
+	if _, err := otherRP.VerifyRegistration(options.Challenge, resp, false); !errors.Is(err, webauthn.ErrRPIDMismatch) {
+		t.Errorf("expected ErrRPIDMismatch, got %v", err)
+	}
+
+	phishing := testhelpers.NewSoftwareAuthenticator("https://actalog.example.net")
+	phished, _ := phishing.Register(options)
+	if _, err := rp.VerifyRegistration(options.Challenge, phished, false); !errors.Is(err, webauthn.ErrOriginMismatch) {
+		t.Errorf("expected ErrOriginMismatch, got %v", err)
+	}
+
+	unverified := testhelpers.NewSoftwareAuthenticator("http://localhost:8080")
+	unverified.UserVerified = false
+	noUV, _ := unverified.Register(options)
+	if _, err := rp.VerifyRegistration(options.Challenge, noUV, true); !errors.Is(err, webauthn.ErrUserNotVerified) {
+		t.Errorf("expected ErrUserNotVerified, got %v", err)
+	}
+	if _, err := rp.VerifyRegistration(options.Challenge, noUV, false); err != nil {
+		t.Errorf("expected registration without UV to verify when not required, got %v", err)
+	}
+
+	resp.Response.AttestationObject = resp.Response.AttestationObject[:20]
+	if _, err := rp.VerifyRegistration(options.Challenge, resp, false); !errors.Is(err, webauthn.ErrInvalidResponse) {
+		t.Errorf("expected ErrInvalidResponse for a truncated attestation, got %v", err)
+	}
+}
+
+func TestVerifyAssertion_WrongKey(t *testing.T) {
+	authenticator := testhelpers.NewSoftwareAuthenticator("http://localhost:8080")
+	options := creationOptions(t)
+
+	first, _ := authenticator.Register(options)
+	firstCred, err := rp.VerifyRegistration(options.Challenge, first, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other := testhelpers.NewSoftwareAuthenticator("http://localhost:8080")
+	second, _ := other.Register(options)
+	secondCred, err := rp.VerifyRegistration(options.Challenge, second, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	login := requestOptions(t)
+	login.AllowCredentials = []webauthn.CredentialDescriptor{{Type: "public-key", ID: first.ID}}
+	assertion, err := authenticator.Login(login)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := rp.VerifyAssertion(login.Challenge, assertion, secondCred.PublicKey, 0, false); !errors.Is(err, webauthn.ErrInvalidSignature) {
+		t.Errorf("expected ErrInvalidSignature, got %v", err)
+	}
+	if _, err := rp.VerifyAssertion(login.Challenge, assertion, firstCred.PublicKey, 0, false); err != nil {
+		t.Errorf("expected assertion to verify with its own key, got %v", err)
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
@@ -24,6 +25,7 @@ func main() {
 	workoutMovementRepo := repository.NewWorkoutMovementRepository(db)
 	userWorkoutMovementRepo := repository.NewUserWorkoutMovementRepository(db)
 	userWorkoutWODRepo := repository.NewUserWorkoutWODRepository(db)
+	wodRepo := repository.NewWODRepository(db)
 
 	// Initialize service
 	userWorkoutService := service.NewUserWorkoutService(
@@ -32,13 +34,15 @@ func main() {
 		workoutMovementRepo,
 		userWorkoutMovementRepo,
 		userWorkoutWODRepo,
+		wodRepo,
+		repository.NewTransactor(db),
 	)
 
 	// Run retroactive PR flagging for user ID 1
 	userID := int64(1)
 	fmt.Printf("Running retroactive PR flagging for user ID %d...\n", userID)
 
-	movementPRCount, wodPRCount, err := userWorkoutService.RetroactivelyFlagPRs(userID)
+	movementPRCount, wodPRCount, err := userWorkoutService.RetroactivelyFlagPRs(context.Background(), userID)
 	if err != nil {
 		log.Fatalf("Failed to retroactively flag PRs: %v", err)
 	}
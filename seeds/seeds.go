@@ -0,0 +1,10 @@
+// Package seeds embeds the standard seed pack: the movements, benchmark
+// WODs and workout templates every ActaLog database is loaded with.
+package seeds
+
+import "embed"
+
+// Standard is the standard seed pack, laid out like any other seed pack
+//
+//go:embed movements.csv wods.csv templates.csv
+var Standard embed.FS
@@ -3,6 +3,7 @@ package integration
 import (
 	// standard library
 	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
 	"flag"
@@ -19,13 +20,14 @@ import (
 	"github.com/johnzastrow/actalog/internal/repository"
 	"github.com/johnzastrow/actalog/internal/service"
 	"github.com/johnzastrow/actalog/internal/testhelpers"
+	"github.com/johnzastrow/actalog/pkg/auth"
 	"github.com/johnzastrow/actalog/pkg/logger"
 	"github.com/johnzastrow/actalog/pkg/middleware"
 )
 
 var (
 	testDBDriver = flag.String("db", "sqlite3", "database driver for integration tests (sqlite3|postgres|mysql)")
-	testDSN      = flag.String("dsn", ":memory:", "database DSN for integration tests")
+	testDSN      = flag.String("dsn", ":memory:", "database DSN for integration tests; for postgres and mysql, a server each test creates its own database on")
 )
 
 func TestMain(m *testing.M) {
@@ -39,32 +41,35 @@ func TestMain(m *testing.M) {
 		*testDSN = envDSN
 	}
 
-	// Setup a temporary DB for postgres/mysql to isolate CI runs
-	var teardown func()
-	if *testDBDriver == "postgres" || *testDBDriver == "mysql" {
-		td, tdFn, err := testhelpers.SetupTempDB(*testDBDriver, *testDSN)
-		if err == nil {
-			*testDSN = td
-			teardown = tdFn
-			// export env for other helpers
-			_ = os.Setenv("DB_DRIVER", *testDBDriver)
-			_ = os.Setenv("DB_DSN", *testDSN)
-		}
-	}
+	os.Exit(m.Run())
+}
 
-	code := m.Run()
+// openTestDB connects to a fresh, migrated and seeded database for a test.
+// SQLite tests get their own in-memory database; PostgreSQL and MySQL tests
+// get a temporary database on the -dsn server, dropped when the test ends.
+func openTestDB(t *testing.T) (*sql.DB, error) {
+	t.Helper()
+	dsn, teardown, err := testhelpers.SetupTempDB(*testDBDriver, *testDSN)
+	if err != nil {
+		return nil, err
+	}
 
-	if teardown != nil {
+	db, err := repository.InitDatabase(*testDBDriver, dsn)
+	if err != nil {
 		teardown()
+		return nil, err
 	}
-
-	os.Exit(code)
+	t.Cleanup(func() {
+		db.Close()
+		teardown()
+	})
+	return db, nil
 }
 
 // Test helper to set up test router with dependencies
 func setupTestRouter(t *testing.T) (*chi.Mux, *repository.SQLiteUserRepository, *sql.DB, int64, error) {
 	// Initialize using the configured test DB driver and DSN (defaults to sqlite in-memory)
-	db, err := repository.InitDatabase(*testDBDriver, *testDSN)
+	db, err := openTestDB(t)
 	if err != nil {
 		return nil, nil, nil, 0, err
 	}
@@ -75,63 +80,13 @@ func setupTestRouter(t *testing.T) (*chi.Mux, *repository.SQLiteUserRepository,
 	workoutMovementRepo := repository.NewWorkoutMovementRepository(db)
 
 	// Create a minimal workout template so tests can log a workout referencing it
-	// Schema may differ depending on migrations. Inspect table columns and insert accordingly.
-	cols := map[string]bool{}
-	rows, err := db.Query("PRAGMA table_info(workouts)")
-	if err == nil {
-		defer rows.Close()
-		for rows.Next() {
-			var cid int
-			var name string
-			var ctype string
-			var notnull int
-			var dflt sql.NullString
-			var pk int
-			_ = rows.Scan(&cid, &name, &ctype, &notnull, &dflt, &pk)
-			cols[name] = true
-		}
+	defaultWorkout := &domain.Workout{
+		Name: "Test Template",
 	}
-
-	var templateID int64
-	if cols["user_id"] {
-		// Old schema: workouts are user-specific and require a user_id. Create a user and insert accordingly.
-		u := &domain.User{
-			Email:        "template-owner@example.com",
-			PasswordHash: "",
-			Name:         "Template Owner",
-			Role:         "user",
-			CreatedAt:    time.Now(),
-			UpdatedAt:    time.Now(),
-		}
-		if err := userRepo.Create(u); err != nil {
-			return nil, nil, nil, 0, err
-		}
-
-		// If the migrated schema added a `name` column (templates), insert into `name` to keep WorkoutRepository happy.
-		var res sql.Result
-		if cols["name"] {
-			// include workout_date because older schema columns may still be present and NOT NULL
-			insertQuery := `INSERT INTO workouts (name, workout_date, workout_type, created_at, updated_at, user_id) VALUES (?, ?, ?, ?, ?, ?)`
-			res, err = db.Exec(insertQuery, "Test Template", time.Now().Format("2006-01-02"), "strength", time.Now(), time.Now(), u.ID)
-		} else {
-			insertQuery := `INSERT INTO workouts (user_id, workout_date, workout_type, workout_name, notes, total_time, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
-			res, err = db.Exec(insertQuery, u.ID, time.Now().Format("2006-01-02"), "strength", "Test Template", nil, nil, time.Now(), time.Now())
-		}
-		if err != nil {
-			return nil, nil, nil, 0, err
-		}
-		id, _ := res.LastInsertId()
-		templateID = id
-	} else {
-		// New schema: workouts are templates
-		defaultWorkout := &domain.Workout{
-			Name: "Test Template",
-		}
-		if err := workoutRepo.Create(defaultWorkout); err != nil {
-			return nil, nil, nil, 0, err
-		}
-		templateID = defaultWorkout.ID
+	if err := workoutRepo.Create(context.Background(), defaultWorkout); err != nil {
+		return nil, nil, nil, 0, err
 	}
+	templateID := defaultWorkout.ID
 
 	// Add a movement to the template if strength movements exist (movement_id 1 seeded)
 	wm := &domain.WorkoutMovement{
@@ -139,7 +94,7 @@ func setupTestRouter(t *testing.T) (*chi.Mux, *repository.SQLiteUserRepository,
 		MovementID: 1,
 		OrderIndex: 1,
 	}
-	if err := workoutMovementRepo.Create(wm); err != nil {
+	if err := workoutMovementRepo.Create(context.Background(), wm); err != nil {
 		return nil, nil, nil, 0, err
 	}
 
@@ -161,12 +116,16 @@ func setupTestRouter(t *testing.T) (*chi.Mux, *repository.SQLiteUserRepository,
 	// Initialize handlers
 	// Create a test logger (stdout only) for handlers
 	testLogger, _ := logger.New(logger.Config{Level: "debug", EnableFile: false})
-	authHandler := handler.NewAuthHandler(userService, testLogger)
+	authHandler := handler.NewAuthHandler(userService, nil, testLogger)
 	// Create user workout handler
 	userWorkoutService := service.NewUserWorkoutService(
 		repository.NewUserWorkoutRepository(db),
 		workoutRepo,
 		workoutMovementRepo,
+		repository.NewUserWorkoutMovementRepository(db),
+		repository.NewUserWorkoutWODRepository(db),
+		repository.NewWODRepository(db),
+		repository.NewTransactor(db),
 	)
 	userWorkoutHandler := handler.NewUserWorkoutHandler(userWorkoutService, testLogger)
 
@@ -184,7 +143,7 @@ func setupTestRouter(t *testing.T) (*chi.Mux, *repository.SQLiteUserRepository,
 
 	// Protected routes
 	r.Group(func(r chi.Router) {
-		r.Use(middleware.Auth("test-secret-key"))
+		r.Use(middleware.Auth(auth.NewHMACKeySet("test-secret-key"), userService))
 		r.Post("/api/workouts", userWorkoutHandler.LogWorkout)
 		r.Get("/api/workouts", userWorkoutHandler.ListLoggedWorkouts)
 		r.Get("/api/workouts/{id}", userWorkoutHandler.GetLoggedWorkout)
@@ -198,7 +157,7 @@ func setupTestRouter(t *testing.T) (*chi.Mux, *repository.SQLiteUserRepository,
 				w.WriteHeader(http.StatusUnauthorized)
 				return
 			}
-			prs, err := workoutService.GetPersonalRecords(userID)
+			prs, err := workoutService.GetPersonalRecords(context.Background(), userID)
 			if err != nil {
 				w.WriteHeader(http.StatusInternalServerError)
 				json.NewEncoder(w).Encode(map[string]string{"message": err.Error()})
@@ -214,7 +173,7 @@ func setupTestRouter(t *testing.T) (*chi.Mux, *repository.SQLiteUserRepository,
 				w.WriteHeader(http.StatusUnauthorized)
 				return
 			}
-			movs, err := workoutService.GetPRMovements(userID, 10)
+			movs, err := workoutService.GetPRMovements(context.Background(), userID, 10)
 			if err != nil {
 				w.WriteHeader(http.StatusInternalServerError)
 				json.NewEncoder(w).Encode(map[string]string{"message": err.Error()})
@@ -395,6 +354,26 @@ func TestWorkoutAndPRFlow(t *testing.T) {
 		}
 	})
 
+	// Offsets still page the history, but not together with a cursor
+	t.Run("List Workouts With Offset", func(t *testing.T) {
+		for query, want := range map[string]int{
+			"?offset=1":            http.StatusOK,
+			"?offset=-1":           http.StatusBadRequest,
+			"?offset=1&cursor=abc": http.StatusBadRequest,
+			"?start_date=2020-01-01&end_date=2099-12-31&offset=0": http.StatusOK,
+		} {
+			req := httptest.NewRequest("GET", "/api/workouts"+query, nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != want {
+				t.Errorf("%s: expected status %d, got %d", query, want, w.Code)
+			}
+		}
+	})
+
 	// Test Getting Personal Records
 	t.Run("Get Personal Records", func(t *testing.T) {
 		req := httptest.NewRequest("GET", "/api/workouts/prs", nil)
@@ -0,0 +1,118 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/johnzastrow/actalog/internal/domain"
+	"github.com/johnzastrow/actalog/internal/handler"
+	"github.com/johnzastrow/actalog/internal/repository"
+	"github.com/johnzastrow/actalog/internal/service"
+	"github.com/johnzastrow/actalog/pkg/auth"
+	"github.com/johnzastrow/actalog/pkg/middleware"
+)
+
+// Test that personal API tokens are accepted only where their scopes allow
+func TestAPITokenScopes(t *testing.T) {
+	_, userRepo, db, _, err := setupTestRouter(t)
+	if err != nil {
+		t.Fatalf("Failed to setup router: %v", err)
+	}
+
+	user := &domain.User{Email: "script@example.com", Name: "Script Owner", Role: "user", CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	if err := userRepo.Create(context.Background(), user); err != nil {
+		t.Fatal(err)
+	}
+	keys := auth.NewHMACKeySet("test-secret-key")
+	session, err := auth.GenerateToken(user.ID, user.Email, user.Role, keys, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tokenService := service.NewAPITokenService(repository.NewAPITokenRepository(db), userRepo)
+	tokenHandler := handler.NewAPITokenHandler(tokenService, nil)
+
+	whoami := func(w http.ResponseWriter, r *http.Request) {
+		userID, _ := middleware.GetUserID(r.Context())
+		json.NewEncoder(w).Encode(map[string]int64{"user_id": userID})
+	}
+
+	r := chi.NewRouter()
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.Auth(keys, nil))
+		r.Get("/api/users/tokens", tokenHandler.ListAPITokens)
+		r.Post("/api/users/tokens", tokenHandler.CreateAPIToken)
+		r.Delete("/api/users/tokens/{id}", tokenHandler.DeleteAPIToken)
+	})
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.AuthWithAPITokens(keys, nil, tokenService))
+		r.With(middleware.RequireScope(domain.ScopeWorkoutsRead)).Get("/api/workouts", whoami)
+		r.With(middleware.RequireScope(domain.ScopeWorkoutsWrite)).Post("/api/workouts", whoami)
+	})
+
+	do := func(method, path, bearer string, body interface{}) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		if body != nil {
+			json.NewEncoder(&buf).Encode(body)
+		}
+		req := httptest.NewRequest(method, path, &buf)
+		req.Header.Set("Authorization", "Bearer "+bearer)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do("POST", "/api/users/tokens", session, map[string]interface{}{"name": "Spreadsheet", "scopes": []string{"workouts:read"}})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+	var created struct {
+		ID    int64  `json:"id"`
+		Token string `json:"token"`
+	}
+	json.NewDecoder(w.Body).Decode(&created)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		bearer string
+		want   int
+	}{
+		{"Session can read", "GET", "/api/workouts", session, http.StatusOK},
+		{"Session can write", "POST", "/api/workouts", session, http.StatusOK},
+		{"Token can read", "GET", "/api/workouts", created.Token, http.StatusOK},
+		{"Token without write scope", "POST", "/api/workouts", created.Token, http.StatusForbidden},
+		{"Token can't manage tokens", "GET", "/api/users/tokens", created.Token, http.StatusUnauthorized},
+		{"Unknown token", "GET", "/api/workouts", auth.APITokenPrefix + "unknown", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := do(tt.method, tt.path, tt.bearer, nil); w.Code != tt.want {
+				t.Errorf("Expected status %d, got %d: %s", tt.want, w.Code, w.Body.String())
+			}
+		})
+	}
+
+	var who map[string]int64
+	json.NewDecoder(do("GET", "/api/workouts", created.Token, nil).Body).Decode(&who)
+	if who["user_id"] != user.ID {
+		t.Errorf("Expected token to authenticate user %d, got %d", user.ID, who["user_id"])
+	}
+
+	// Revoked tokens stop working
+	if w := do("DELETE", "/api/users/tokens/"+strconv.FormatInt(created.ID, 10), session, nil); w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if w := do("GET", "/api/workouts", created.Token, nil); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status %d after revoke, got %d", http.StatusUnauthorized, w.Code)
+	}
+}
@@ -0,0 +1,129 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/johnzastrow/actalog/internal/domain"
+	"github.com/johnzastrow/actalog/internal/handler"
+	"github.com/johnzastrow/actalog/internal/repository"
+	"github.com/johnzastrow/actalog/internal/service"
+	"github.com/johnzastrow/actalog/pkg/auth"
+	"github.com/johnzastrow/actalog/pkg/middleware"
+)
+
+// Test that logins and admin actions are recorded in the audit log
+func TestAuditLog(t *testing.T) {
+	_, userRepo, db, _, err := setupTestRouter(t)
+	if err != nil {
+		t.Fatalf("Failed to setup router: %v", err)
+	}
+
+	keys := auth.NewHMACKeySet("test-secret-key")
+	userService := service.NewUserService(userRepo, repository.NewSQLiteRefreshTokenRepository(db), "test-secret-key", time.Hour, 24*time.Hour, true, nil, "http://localhost:3000", false)
+	auditService := service.NewAuditService(repository.NewAuditLogRepository(db))
+	authHandler := handler.NewAuthHandler(userService, auditService, nil)
+	adminUserHandler := handler.NewAdminUserHandler(service.NewAdminUserService(userRepo, userService), nil, auditService, nil)
+	auditHandler := handler.NewAuditHandler(auditService, nil)
+
+	r := chi.NewRouter()
+	r.Use(middleware.RequestIDMiddleware(nil))
+	r.Post("/api/auth/login", authHandler.Login)
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.Auth(keys, userService))
+		r.Use(middleware.AdminOnly)
+		r.Put("/api/admin/users/{id}/role", adminUserHandler.SetRole)
+		r.Get("/api/admin/audit", auditHandler.ListAuditLog)
+	})
+
+	admin, _, err := userService.Register(context.Background(), "Audit Admin", "audit-admin@example.com", "password123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if admin.Role != "admin" {
+		stored, _ := userRepo.GetByID(context.Background(), admin.ID)
+		stored.Role = "admin"
+		if err := userRepo.Update(context.Background(), stored); err != nil {
+			t.Fatal(err)
+		}
+	}
+	user, _, err := userService.Register(context.Background(), "Audited", "audited@example.com", "password123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	adminToken, _ := auth.GenerateToken(admin.ID, admin.Email, "admin", keys, time.Hour)
+
+	do := func(method, path, bearer, requestID string, body interface{}) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		if body != nil {
+			json.NewEncoder(&buf).Encode(body)
+		}
+		req := httptest.NewRequest(method, path, &buf)
+		if bearer != "" {
+			req.Header.Set("Authorization", "Bearer "+bearer)
+		}
+		if requestID != "" {
+			req.Header.Set("X-Request-ID", requestID)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	do("POST", "/api/auth/login", "", "req-failed", map[string]string{"email": "audited@example.com", "password": "wrong-password"})
+	if w := do("POST", "/api/auth/login", "", "req-login", map[string]string{"email": "audited@example.com", "password": "password123"}); w.Code != http.StatusOK {
+		t.Fatalf("Expected login to succeed, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := do("PUT", "/api/admin/users/"+strconv.FormatInt(user.ID, 10)+"/role", adminToken, "", map[string]string{"role": "admin"}); w.Code != http.StatusOK {
+		t.Fatalf("Expected role change to succeed, got %d: %s", w.Code, w.Body.String())
+	}
+
+	list := func(query string) []domain.AuditEntry {
+		t.Helper()
+		w := do("GET", "/api/admin/audit?"+query, adminToken, "", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		var resp struct {
+			Entries []domain.AuditEntry `json:"entries"`
+			Total   int64               `json:"total"`
+		}
+		json.NewDecoder(w.Body).Decode(&resp)
+		if int64(len(resp.Entries)) != resp.Total {
+			t.Errorf("Expected total %d to match %d entries", resp.Total, len(resp.Entries))
+		}
+		return resp.Entries
+	}
+
+	failed := list("action=login_failed")
+	if len(failed) != 1 || failed[0].RequestID != "req-failed" || failed[0].ActorID != nil || failed[0].IPAddress == "" {
+		t.Errorf("Expected one anonymous failed login with its request ID, got %+v", failed)
+	}
+
+	logins := list("action=login&actor_id=" + strconv.FormatInt(user.ID, 10))
+	if len(logins) != 1 || logins[0].RequestID != "req-login" || *logins[0].TargetID != user.ID {
+		t.Errorf("Expected one login by the user, got %+v", logins)
+	}
+
+	changes := list("target_type=user&target_id=" + strconv.FormatInt(user.ID, 10) + "&action=role_changed")
+	if len(changes) != 1 || changes[0].ActorID == nil || *changes[0].ActorID != admin.ID || changes[0].RequestID == "" {
+		t.Fatalf("Expected one role change by the admin, got %+v", changes)
+	}
+	var before, after domain.User
+	json.Unmarshal(changes[0].Before, &before)
+	json.Unmarshal(changes[0].After, &after)
+	if before.Role != "user" || after.Role != "admin" {
+		t.Errorf("Expected role to change from user to admin, got %q to %q", before.Role, after.Role)
+	}
+
+	if w := do("GET", "/api/admin/audit?since=yesterday", adminToken, "", nil); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for a bad timestamp, got %d", http.StatusBadRequest, w.Code)
+	}
+}
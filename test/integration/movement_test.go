@@ -0,0 +1,115 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/johnzastrow/actalog/internal/domain"
+	"github.com/johnzastrow/actalog/internal/handler"
+	"github.com/johnzastrow/actalog/internal/repository"
+	"github.com/johnzastrow/actalog/internal/service"
+	"github.com/johnzastrow/actalog/pkg/auth"
+	"github.com/johnzastrow/actalog/pkg/middleware"
+)
+
+// Test that only a custom movement's creator (or an admin) can change it,
+// and that standard movements can't be changed
+func TestMovementOwnership(t *testing.T) {
+	_, userRepo, db, _, err := setupTestRouter(t)
+	if err != nil {
+		t.Fatalf("Failed to setup router: %v", err)
+	}
+
+	keys := auth.NewHMACKeySet("test-secret-key")
+	userService := service.NewUserService(userRepo, repository.NewSQLiteRefreshTokenRepository(db), "test-secret-key", time.Hour, 24*time.Hour, true, nil, "http://localhost:3000", false)
+	movementRepo := repository.NewMovementRepository(db)
+	movementHandler := handler.NewMovementHandler(movementRepo, nil)
+
+	r := chi.NewRouter()
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.Auth(keys, userService))
+		r.Post("/api/movements", movementHandler.Create)
+		r.Put("/api/movements/{id}", movementHandler.Update)
+		r.Delete("/api/movements/{id}", movementHandler.Delete)
+	})
+
+	owner, _, err := userService.Register(context.Background(), "Owner", "movement-owner@example.com", "password123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _, err := userService.Register(context.Background(), "Other", "movement-other@example.com", "password123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	admin, _, err := userService.Register(context.Background(), "Admin", "movement-admin@example.com", "password123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	admin.Role = "admin"
+	if err := userRepo.Update(context.Background(), admin); err != nil {
+		t.Fatal(err)
+	}
+	ownerToken, _ := auth.GenerateToken(owner.ID, owner.Email, "user", keys, time.Hour)
+	otherToken, _ := auth.GenerateToken(other.ID, other.Email, "user", keys, time.Hour)
+	adminToken, _ := auth.GenerateToken(admin.ID, admin.Email, "admin", keys, time.Hour)
+
+	do := func(method, path, bearer string, body interface{}) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		if body != nil {
+			json.NewEncoder(&buf).Encode(body)
+		}
+		req := httptest.NewRequest(method, path, &buf)
+		req.Header.Set("Authorization", "Bearer "+bearer)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	update := map[string]string{"name": "Renamed", "type": "weightlifting"}
+
+	w := do("POST", "/api/movements", ownerToken, map[string]string{"name": "Zercher Squat", "type": "weightlifting"})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+	var created domain.Movement
+	json.NewDecoder(w.Body).Decode(&created)
+	if created.CreatedBy == nil || *created.CreatedBy != owner.ID {
+		t.Fatalf("Expected the movement to belong to its creator, got %v", created.CreatedBy)
+	}
+	path := "/api/movements/" + strconv.FormatInt(created.ID, 10)
+
+	if w := do("PUT", path, otherToken, update); w.Code != http.StatusForbidden {
+		t.Errorf("Expected status %d when another user updates, got %d", http.StatusForbidden, w.Code)
+	}
+	if w := do("DELETE", path, otherToken, nil); w.Code != http.StatusForbidden {
+		t.Errorf("Expected status %d when another user deletes, got %d", http.StatusForbidden, w.Code)
+	}
+	if w := do("PUT", path, ownerToken, update); w.Code != http.StatusOK {
+		t.Errorf("Expected the owner to update, got %d: %s", w.Code, w.Body.String())
+	}
+	stored, _ := movementRepo.GetByID(context.Background(), created.ID)
+	if stored.Name != "Renamed" || stored.CreatedBy == nil || *stored.CreatedBy != owner.ID {
+		t.Errorf("Expected the update to keep the creator, got %+v", stored)
+	}
+	if w := do("DELETE", path, adminToken, nil); w.Code != http.StatusOK {
+		t.Errorf("Expected an admin to delete, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := do("DELETE", path, ownerToken, nil); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d for a deleted movement, got %d", http.StatusNotFound, w.Code)
+	}
+
+	standard, err := movementRepo.ListStandard(context.Background())
+	if err != nil || len(standard) == 0 {
+		t.Fatalf("Expected seeded standard movements, got %v", err)
+	}
+	standardPath := "/api/movements/" + strconv.FormatInt(standard[0].ID, 10)
+	if w := do("DELETE", standardPath, adminToken, nil); w.Code != http.StatusForbidden {
+		t.Errorf("Expected status %d when deleting a standard movement, got %d", http.StatusForbidden, w.Code)
+	}
+}
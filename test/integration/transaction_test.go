@@ -0,0 +1,174 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/johnzastrow/actalog/internal/domain"
+	"github.com/johnzastrow/actalog/internal/repository"
+	"github.com/johnzastrow/actalog/internal/service"
+)
+
+var errWriteFailed = errors.New("write failed")
+
+// failingMovementRepo fails to create movements after the first failAfter
+type failingMovementRepo struct {
+	domain.UserWorkoutMovementRepository
+	failAfter int
+	created   int
+}
+
+func (r *failingMovementRepo) Create(ctx context.Context, uwm *domain.UserWorkoutMovement) error {
+	if r.created >= r.failAfter {
+		return errWriteFailed
+	}
+	r.created++
+	return r.UserWorkoutMovementRepository.Create(ctx, uwm)
+}
+
+func (r *failingMovementRepo) CreateBatch(ctx context.Context, movements []*domain.UserWorkoutMovement) error {
+	for _, uwm := range movements {
+		if err := r.Create(ctx, uwm); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// failingWODRepo fails to save WOD results
+type failingWODRepo struct {
+	domain.UserWorkoutWODRepository
+}
+
+func (r *failingWODRepo) CreateBatch(ctx context.Context, wods []*domain.UserWorkoutWOD) error {
+	return errWriteFailed
+}
+
+// Test that multi-table workout writes are saved together or not at all
+func TestWorkoutWritesAreAtomic(t *testing.T) {
+	db, err := openTestDB(t)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	ctx := context.Background()
+
+	userWorkoutRepo := repository.NewUserWorkoutRepository(db)
+	workoutRepo := repository.NewWorkoutRepository(db)
+	movementRepo := repository.NewUserWorkoutMovementRepository(db)
+	wodRepo := repository.NewWODRepository(db)
+	failingMovements := &failingMovementRepo{UserWorkoutMovementRepository: movementRepo, failAfter: 1}
+	newService := func(movements domain.UserWorkoutMovementRepository, wods domain.UserWorkoutWODRepository) *service.UserWorkoutService {
+		return service.NewUserWorkoutService(userWorkoutRepo, workoutRepo, repository.NewWorkoutMovementRepository(db), movements, wods, wodRepo, repository.NewTransactor(db))
+	}
+
+	template := &domain.Workout{Name: "Transaction Test Template"}
+	if err := workoutRepo.Create(ctx, template); err != nil {
+		t.Fatal(err)
+	}
+	standard, err := repository.NewMovementRepository(db).ListStandard(ctx)
+	if err != nil || len(standard) < 2 {
+		t.Fatalf("Expected seeded movements, got %v", err)
+	}
+	wods, err := wodRepo.ListStandard(ctx, 1, 0)
+	if err != nil || len(wods) == 0 {
+		t.Fatalf("Expected seeded WODs, got %v", err)
+	}
+
+	// A result that passes validation, whatever the WOD's score type
+	result := &domain.UserWorkoutWOD{WODID: wods[0].ID}
+	score := 1
+	switch wods[0].ScoreType {
+	case "Time (HH:MM:SS)":
+		result.TimeSeconds = &score
+	case "Rounds+Reps":
+		result.Rounds = &score
+	case "Max Weight":
+		weight := float64(score)
+		result.Weight = &weight
+	}
+
+	count := func(table string) int {
+		var n int
+		if err := db.QueryRow(`SELECT COUNT(*) FROM ` + table).Scan(&n); err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+
+	// A WOD result that can't be saved leaves no workout or movements behind
+	svc := newService(movementRepo, &failingWODRepo{repository.NewUserWorkoutWODRepository(db)})
+	_, err = svc.LogWorkoutWithPerformance(ctx, 1, &template.ID, nil, time.Now(), nil, nil, nil,
+		[]*domain.UserWorkoutMovement{{MovementID: standard[0].ID}},
+		[]*domain.UserWorkoutWOD{result},
+	)
+	if !errors.Is(err, errWriteFailed) {
+		t.Fatalf("Expected the WOD write to fail, got %v", err)
+	}
+	if count("user_workouts") != 0 || count("user_workout_movements") != 0 {
+		t.Errorf("Expected the failed workout to be rolled back, got %d workouts and %d movements", count("user_workouts"), count("user_workout_movements"))
+	}
+
+	// Replacing movements keeps the old ones if a new one can't be saved
+	svc = newService(movementRepo, repository.NewUserWorkoutWODRepository(db))
+	logged, err := svc.LogWorkoutWithPerformance(ctx, 1, &template.ID, nil, time.Now(), nil, nil, nil,
+		[]*domain.UserWorkoutMovement{{MovementID: standard[0].ID}},
+		nil,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	svc = newService(failingMovements, repository.NewUserWorkoutWODRepository(db))
+	_, err = svc.UpdateLoggedWorkoutWithPerformance(ctx, logged.ID, 1, nil, nil, nil, nil,
+		[]*domain.UserWorkoutMovement{{MovementID: standard[1].ID}, {MovementID: standard[1].ID}},
+		nil,
+	)
+	if !errors.Is(err, errWriteFailed) {
+		t.Fatalf("Expected the second movement write to fail, got %v", err)
+	}
+	kept, err := movementRepo.GetByUserWorkoutID(ctx, logged.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(kept) != 1 || kept[0].MovementID != standard[0].ID {
+		t.Errorf("Expected the original movement to be kept, got %d movements", len(kept))
+	}
+
+	// An edit whose WOD result can't be saved leaves the workout's fields and
+	// movements as they were
+	name, notes := "Before", "Notes before"
+	svc = newService(movementRepo, repository.NewUserWorkoutWODRepository(db))
+	adHoc, err := svc.LogWorkoutWithPerformance(ctx, 1, nil, &name, time.Now(), &notes, nil, nil,
+		[]*domain.UserWorkoutMovement{{MovementID: standard[0].ID}},
+		nil,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	svc = newService(movementRepo, &failingWODRepo{repository.NewUserWorkoutWODRepository(db)})
+	newName, newNotes := "After", "Notes after"
+	_, err = svc.UpdateLoggedWorkoutWithPerformance(ctx, adHoc.ID, 1, &newName, &newNotes, nil, nil,
+		[]*domain.UserWorkoutMovement{{MovementID: standard[1].ID}},
+		[]*domain.UserWorkoutWOD{{WODID: result.WODID, TimeSeconds: result.TimeSeconds, Rounds: result.Rounds, Weight: result.Weight}},
+	)
+	if !errors.Is(err, errWriteFailed) {
+		t.Fatalf("Expected the WOD write to fail, got %v", err)
+	}
+	unchanged, err := userWorkoutRepo.GetByID(ctx, adHoc.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unchanged.WorkoutName == nil || *unchanged.WorkoutName != name || unchanged.Notes == nil || *unchanged.Notes != notes {
+		t.Errorf("Expected the name and notes to be unchanged, got %v and %v", unchanged.WorkoutName, unchanged.Notes)
+	}
+	kept, err = movementRepo.GetByUserWorkoutID(ctx, adHoc.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(kept) != 1 || kept[0].MovementID != standard[0].ID {
+		t.Errorf("Expected the original movement to be kept, got %d movements", len(kept))
+	}
+}
@@ -141,10 +141,13 @@ export const useAuthStore = defineStore('auth', () => {
 
       token.value = response.data.token
       user.value = response.data.user
+      // Refresh tokens are single use; keep the replacement
+      refreshToken.value = response.data.refresh_token
 
       // Update localStorage
       localStorage.setItem('token', token.value)
       localStorage.setItem('user', JSON.stringify(user.value))
+      localStorage.setItem('refreshToken', refreshToken.value)
 
       // Set default authorization header
       axios.defaults.headers.common['Authorization'] = `Bearer ${token.value}`
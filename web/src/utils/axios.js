@@ -93,9 +93,10 @@ instance.interceptors.response.use(
         const newToken = response.data.token
         const newUser = response.data.user
 
-        // Update localStorage
+        // Update localStorage (refresh tokens are single use; keep the replacement)
         localStorage.setItem('token', newToken)
         localStorage.setItem('user', JSON.stringify(newUser))
+        localStorage.setItem('refreshToken', response.data.refresh_token)
 
         // Update the authorization header
         instance.defaults.headers.common['Authorization'] = `Bearer ${newToken}`